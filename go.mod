@@ -9,20 +9,20 @@ require github.com/gorilla/mux v1.8.1
 require (
 	github.com/allegro/bigcache/v3 v3.1.0
 	github.com/clerk/clerk-sdk-go/v2 v2.3.1
+	github.com/go-jose/go-jose/v3 v3.0.5
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.9.2
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/supabase-community/storage-go v0.7.0
+	golang.org/x/text v0.39.0
 	golang.org/x/time v0.13.0
 )
 
 require (
-	github.com/go-jose/go-jose/v3 v3.0.5 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	golang.org/x/crypto v0.45.0 // indirect
 	golang.org/x/sync v0.21.0 // indirect
-	golang.org/x/text v0.39.0 // indirect
 )
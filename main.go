@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"crypto/rsa"
+	"fmt"
 	"glossias/src/admin"
 	"glossias/src/apis"
 	"glossias/src/auth"
+	"glossias/src/auth/lti"
+	"glossias/src/auth/lti/mockplatform"
 	"glossias/src/logging"
+	"glossias/src/pkg/cache"
 	"glossias/src/pkg/database"
 	"glossias/src/pkg/models"
+	"glossias/src/pkg/storage"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -35,6 +44,11 @@ func main() {
 		err = nil
 	}
 
+	// `glossias migrate up|down [n]|status` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(logger, os.Args[2:]))
+	}
+
 	// Initialize database with automatic reconnection support
 	// USE_POOL=true uses pgxpool, USE_POOL=false uses database/sql, no DATABASE_URL uses mock
 	dbPath := "" // Not used for PostgreSQL
@@ -43,21 +57,96 @@ func main() {
 		log.Fatal(err)
 	}
 	defer db.Close()
-	// Set the DB for the models package
-	models.SetDB(db.RawConn())
-	// Set the storage client for the models package
-	storageUrl := os.Getenv("STORAGE_URL")
-	storageKey := os.Getenv("STORAGE_API_KEY")
-	if storageUrl == "" || storageKey == "" {
-		logger.Warn("STORAGE_URL or STORAGE_API_KEY environment variable not set, storage operations will fail")
-	}
-	models.SetStorageClient(storageUrl, storageKey)
+	// Select the storage backend
+	// STORAGE_BACKEND=local keeps files on disk and serves them from this server
+	var fileStorage storage.Storage
+	var localStorage *storage.Local
+	if os.Getenv("STORAGE_BACKEND") == "local" {
+		storageDir := os.Getenv("LOCAL_STORAGE_DIR")
+		if storageDir == "" {
+			storageDir = "storage"
+		}
+		storageBaseURL := os.Getenv("LOCAL_STORAGE_URL")
+		if storageBaseURL == "" {
+			storageBaseURL = "http://localhost:" + os.Getenv("PORT")
+		}
+		localStorage, err = storage.NewLocal(storageDir, storageBaseURL, os.Getenv("LOCAL_STORAGE_SECRET"))
+		if err != nil {
+			logger.Error("Failed to initialize local storage", "error", err)
+			os.Exit(1)
+		}
+		fileStorage = localStorage
+		logger.Info("using local storage", "dir", storageDir, "url", storageBaseURL)
+	} else {
+		storageUrl := os.Getenv("STORAGE_URL")
+		storageKey := os.Getenv("STORAGE_API_KEY")
+		if storageUrl == "" || storageKey == "" {
+			logger.Warn("STORAGE_URL or STORAGE_API_KEY environment variable not set, storage operations will fail")
+		} else {
+			supabaseStorage, err := storage.NewSupabase(storageUrl, storageKey)
+			if err != nil {
+				logger.Error("API keys provided, but failed to connect to storage", "error", err)
+				os.Exit(1)
+			}
+			fileStorage = supabaseStorage
+			logger.Info("storage client initialized", "url", storageUrl)
+		}
+	}
 	// Initialize cache
-	if err := models.SetCache(); err != nil {
+	appCache, err := cache.New(cache.DefaultConfig())
+	if err != nil {
 		logger.Error("Failed to initialize cache", "error", err)
 		os.Exit(1)
 	}
 
+	// One model service is shared by every handler. It also backs the
+	// package-level models functions that have not been migrated yet.
+	svc := models.NewService(db.RawConn(), fileStorage, appCache, models.SystemClock{})
+	models.SetDefault(svc)
+
+	// Select the Produce grader
+	// GRADER_BACKEND=llm grades with an OpenAI-compatible API, otherwise answers are graded locally
+	if os.Getenv("GRADER_BACKEND") == "llm" {
+		grader, err := models.NewLLMGrader(os.Getenv("LLM_API_URL"), os.Getenv("LLM_API_KEY"), os.Getenv("LLM_MODEL"))
+		if err != nil {
+			logger.Error("Failed to initialize LLM grader", "error", err)
+			os.Exit(1)
+		}
+		svc.SetGrader(grader)
+		logger.Info("using LLM grader", "url", os.Getenv("LLM_API_URL"), "model", os.Getenv("LLM_MODEL"))
+	}
+	// Submissions whose grading failed are retried in the background
+	svc.StartProduceRegrader(context.Background(), 5*time.Minute, 50)
+
+	// Daily at-risk digests for the courses that enable them
+	// RISK_NOTIFIER=none turns them off; otherwise they are printed to the log
+	if os.Getenv("RISK_NOTIFIER") != "none" {
+		digestHour := 7
+		if hour := os.Getenv("RISK_DIGEST_HOUR"); hour != "" {
+			digestHour, err = strconv.Atoi(hour)
+			if err != nil || digestHour < 0 || digestHour > 23 {
+				logger.Error("RISK_DIGEST_HOUR must be an hour from 0 to 23", "value", hour)
+				os.Exit(1)
+			}
+		}
+		svc.SetRiskNotifier(models.LogRiskNotifier{})
+		svc.StartRiskDigest(context.Background(), digestHour)
+	}
+
+	// Deleted stories and courses are purged, files included, after TRASH_RETENTION_DAYS
+	// (30 by default); 0 keeps them in the trash until restored
+	retentionDays := 30
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		retentionDays, err = strconv.Atoi(days)
+		if err != nil || retentionDays < 0 {
+			logger.Error("TRASH_RETENTION_DAYS must be a number of days", "value", days)
+			os.Exit(1)
+		}
+	}
+	if retentionDays > 0 {
+		svc.StartTrashPurge(context.Background(), time.Hour, time.Duration(retentionDays)*24*time.Hour)
+	}
+
 	// Clerk stuff
 	clerk_key := os.Getenv("CLERK_SECRET_KEY")
 	if clerk_key == "" {
@@ -65,12 +154,40 @@ func main() {
 	}
 	clerk.SetKey(clerk_key)
 
+	// LTI 1.3 tool, a second identity source for users launching from an LMS
+	// LTI_BASE_URL is the public URL of this server and enables it
+	var ltiTool *lti.Tool
+	var sessions auth.SessionVerifier
+	if baseURL := os.Getenv("LTI_BASE_URL"); baseURL != "" {
+		var privateKey *rsa.PrivateKey
+		if pemKey := os.Getenv("LTI_PRIVATE_KEY"); pemKey != "" {
+			privateKey, err = lti.LoadPrivateKey(pemKey)
+			if err != nil {
+				logger.Error("Failed to load LTI private key", "error", err)
+				os.Exit(1)
+			}
+		}
+		ltiTool, err = lti.NewTool(logger, svc, lti.Config{
+			BaseURL:     baseURL,
+			FrontendURL: os.Getenv("LTI_FRONTEND_URL"),
+			PrivateKey:  privateKey,
+		})
+		if err != nil {
+			logger.Error("Failed to initialize LTI tool", "error", err)
+			os.Exit(1)
+		}
+		sessions = ltiTool
+		// New score snapshots are posted to the LMS gradebooks of LTI courses
+		svc.SetScorePublisher(ltiTool)
+		logger.Info("LTI tool enabled", "login_url", ltiTool.LoginURL(), "launch_url", ltiTool.LaunchURL(), "jwks_url", ltiTool.JWKSURL())
+	}
+
 	// All routing below here.
 	r := mux.NewRouter()
 
 	// Setup middleware if needed
 	r.Use(auth.RateLimitMiddleware(logger))
-	r.Use(auth.Middleware(logger))
+	r.Use(auth.Middleware(logger, svc, sessions))
 	r.Use(loggingMiddleware(logger))
 
 	// Health check endpoint (no auth required)
@@ -81,16 +198,34 @@ func main() {
 	}).Methods("GET", "OPTIONS")
 
 	// Database health check endpoint (no auth required, rate-limited to 1 request per 5 minutes)
-	r.HandleFunc("/api/db-health", apis.DBHealthHandler(logger)).Methods("GET", "OPTIONS")
+	r.HandleFunc("/api/db-health", apis.DBHealthHandler(logger, svc)).Methods("GET", "OPTIONS")
+
+	// Signed local storage URLs (no auth required, the signature is the auth)
+	if localStorage != nil {
+		r.PathPrefix(storage.LocalRoutePrefix).Handler(localStorage).Methods("GET", "HEAD", "PUT", "POST", "OPTIONS")
+	}
+
+	// LTI endpoints (no auth required, platforms sign their messages)
+	if ltiTool != nil {
+		// LTI_MOCK_PLATFORM=true serves a mock LMS at /lti/mock for local testing
+		if os.Getenv("LTI_MOCK_PLATFORM") == "true" {
+			if err := mountMockPlatform(r, ltiTool, svc); err != nil {
+				logger.Error("Failed to start mock LTI platform", "error", err)
+				os.Exit(1)
+			}
+			logger.Warn("mock LTI platform enabled, do not use in production", "start_url", os.Getenv("LTI_BASE_URL")+"/lti/mock/start")
+		}
+		ltiTool.RegisterRoutes(r.PathPrefix("/lti").Subrouter())
+	}
 
 	// Time tracking API (no auth required)
-	timeTrackingHandler := apis.NewTimeTrackingHandler(logger)
+	timeTrackingHandler := apis.NewTimeTrackingHandler(logger, svc)
 	timeTrackingRouter := r.PathPrefix("/api").Subrouter()
 	timeTrackingRouter.Use(jsonMiddleware())
 	timeTrackingHandler.RegisterRoutes(timeTrackingRouter)
 
 	// API handlers
-	apiHandler := apis.NewHandler(logger)
+	apiHandler := apis.NewHandler(logger, svc)
 	apiRouter := r.PathPrefix("/api").Subrouter()
 
 	// Clerk: require Authorization: Bearer <token> on every request (unless dev auth bypass)
@@ -102,18 +237,22 @@ func main() {
 		if authorizedParty == "" {
 			logger.Warn("AUTHORIZED_PARTY environment variable not set")
 			// It's not actually needed, but can cause problems if missing.
-			apiRouter.Use(clerkhttp.RequireHeaderAuthorization())
+			apiRouter.Use(auth.UnlessSession(clerkhttp.RequireHeaderAuthorization()))
 		} else {
-			apiRouter.Use(clerkhttp.RequireHeaderAuthorization(
+			apiRouter.Use(auth.UnlessSession(clerkhttp.RequireHeaderAuthorization(
 				clerkhttp.AuthorizedPartyMatches(authorizedParty),
-			))
+			)))
 		}
 	}
 	apiRouter.Use(jsonMiddleware())
 	apiHandler.RegisterRoutes(apiRouter)
+	if ltiTool != nil {
+		// Linking an LMS account to the signed-in user
+		ltiTool.RegisterAPIRoutes(apiRouter)
+	}
 
 	// Admin API mounted under /api/admin/*
-	adminHandler := admin.NewHandler(logger)
+	adminHandler := admin.NewHandler(logger, svc)
 	adminApiRouter := apiRouter.PathPrefix("/admin").Subrouter()
 	adminHandler.RegisterRoutes(adminApiRouter)
 
@@ -138,6 +277,109 @@ func main() {
 	}
 }
 
+// mountMockPlatform serves a mock LMS under /lti/mock and registers it as a platform
+func mountMockPlatform(r *mux.Router, tool *lti.Tool, svc *models.Service) error {
+	issuer := strings.TrimSuffix(tool.LoginURL(), "/login") + "/mock"
+	platform, err := mockplatform.New(issuer, "glossias-mock", "mock-deployment", mockplatform.Tool{
+		LoginURL:  tool.LoginURL(),
+		LaunchURL: tool.LaunchURL(),
+		JWKSURL:   tool.JWKSURL(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = svc.SaveLTIPlatform(context.Background(), models.LTIPlatform{
+		Name:         "Mock LMS",
+		Issuer:       platform.Issuer,
+		ClientID:     platform.ClientID,
+		DeploymentID: platform.DeploymentID,
+		AuthLoginURL: platform.AuthLoginURL(),
+		AuthTokenURL: platform.AuthTokenURL(),
+		JWKSURL:      platform.JWKSURL(),
+	})
+	if err != nil {
+		return err
+	}
+	r.PathPrefix("/lti/mock/").Handler(http.StripPrefix("/lti/mock", platform.Handler()))
+	return nil
+}
+
+const migrateUsage = "usage: glossias migrate up | down [n] | status"
+
+// runMigrate handles the migrate subcommand and returns the process exit code
+func runMigrate(logger *slog.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	connStr := os.Getenv("DATABASE_URL")
+	if connStr == "" {
+		logger.Error("DATABASE_URL environment variable not set")
+		return 1
+	}
+
+	ctx := context.Background()
+	migrator, err := database.NewMigrator(ctx, connStr)
+	if err != nil {
+		logger.Error("failed to start migrator", "error", err)
+		return 1
+	}
+	defer migrator.Close(ctx)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			logger.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			logger.Error("migrate up failed", "error", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			logger.Info("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			logger.Info("rolled back migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			logger.Error("migrate down failed", "error", err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("migrate status failed", "error", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		tw.Flush()
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
+
 func loggingMiddleware(logger *slog.Logger) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
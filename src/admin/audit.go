@@ -0,0 +1,239 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAuditBody is the largest request or response body kept in an audit event
+const maxAuditBody = 64 << 10
+
+// routeVariable matches a route variable with its pattern, e.g. {id:[0-9]+}
+var routeVariable = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// auditMiddleware records every request that changes something under /api/admin, and
+// tags every response with X-Request-ID (the client's, or a new one)
+func (h *Handler) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > 128 {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, _ := auth.GetUserIDWithOk(r)
+		action, targetType, targetID := auditAction(r)
+		event := models.AuditEvent{
+			RequestID:  requestID,
+			ActorID:    userID,
+			Action:     action,
+			TargetType: targetType,
+			TargetID:   targetID,
+			Request:    readAuditBody(r),
+		}
+		snapshot := models.HasAuditSnapshot(targetType) && targetID != ""
+		if snapshot {
+			event.Before = h.auditSnapshot(r, targetType, targetID)
+		}
+
+		recorder := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		event.Status = recorder.status
+		if recorder.status < http.StatusBadRequest {
+			if snapshot {
+				event.After = h.auditSnapshot(r, targetType, targetID)
+			} else if recorder.body.Len() <= maxAuditBody && json.Valid(recorder.body.Bytes()) {
+				event.After = recorder.body.Bytes()
+			}
+		}
+
+		// Recorded even if the client has gone, since the change was made
+		if err := h.svc.RecordAuditEvent(context.WithoutCancel(r.Context()), event); err != nil {
+			h.log.Error("failed to record audit event", "error", err, "action", action, "request_id", requestID)
+		}
+	})
+}
+
+// auditSnapshot returns the target's state, logging and leaving it out if it cannot be read
+func (h *Handler) auditSnapshot(r *http.Request, targetType, targetID string) json.RawMessage {
+	snapshot, err := h.svc.AuditSnapshot(r.Context(), targetType, targetID)
+	if err != nil {
+		h.log.Error("failed to snapshot audit target", "error", err, "target_type", targetType, "target_id", targetID)
+	}
+	return snapshot
+}
+
+// auditAction names a request by method and route, e.g. "PUT /stories/{id}/metadata",
+// and finds its target from the route
+func auditAction(r *http.Request) (action, targetType, targetID string) {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			path = routeVariable.ReplaceAllString(template, "{$1}")
+		}
+	}
+	path = strings.TrimPrefix(path, "/api/admin")
+	targetType, targetID = auditTarget(path, mux.Vars(r))
+	return r.Method + " " + path, targetType, targetID
+}
+
+// auditTarget maps a route below /api/admin to the entity it changes
+func auditTarget(path string, vars map[string]string) (string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch segments[0] {
+	case "stories":
+		return models.AuditTargetStory, vars["id"]
+	case "courses":
+		return models.AuditTargetCourse, vars["id"]
+	case "course-users":
+		return models.AuditTargetCourse, vars["courseId"]
+	case "trash":
+		if len(segments) > 1 && segments[1] == "courses" {
+			return models.AuditTargetCourse, vars["id"]
+		}
+		return models.AuditTargetStory, vars["id"]
+	case "lti":
+		return "lti_platform", vars["id"]
+	}
+	return segments[0], ""
+}
+
+// readAuditBody returns a JSON request body for the audit log and leaves it readable
+// for the handler. Other bodies, e.g. story bundles, and large ones are not kept.
+func readAuditBody(r *http.Request) json.RawMessage {
+	if r.Body == nil || !strings.Contains(r.Header.Get("Content-Type"), "json") {
+		return nil
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil || len(head) > maxAuditBody || !json.Valid(head) {
+		return nil
+	}
+	return head
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// auditRecorder passes a response through, keeping its status and the start of its body
+type auditRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (a *auditRecorder) WriteHeader(status int) {
+	if !a.wroteHeader {
+		a.status = status
+		a.wroteHeader = true
+	}
+	a.ResponseWriter.WriteHeader(status)
+}
+
+func (a *auditRecorder) Write(p []byte) (int, error) {
+	a.wroteHeader = true
+	if a.body.Len() <= maxAuditBody {
+		a.body.Write(p[:min(len(p), maxAuditBody+1-a.body.Len())])
+	}
+	return a.ResponseWriter.Write(p)
+}
+
+// audit lists audit events, newest first (super admin only). Filters: actor_id, action
+// (any part), target_type, target_id, request_id, since and until (RFC 3339 or
+// YYYY-MM-DD), before_id to page back, and limit.
+func (h *Handler) audit(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireSuperAdmin(w, r, "audit log"); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		RequestID:  query.Get("request_id"),
+	}
+	var ok bool
+	if filter.Since, ok = auditTime(w, query.Get("since"), "since"); !ok {
+		return
+	}
+	if filter.Until, ok = auditTime(w, query.Get("until"), "until"); !ok {
+		return
+	}
+	if filter.BeforeID, ok = auditNumber(w, query.Get("before_id"), "before_id"); !ok {
+		return
+	}
+	limit, ok := auditNumber(w, query.Get("limit"), "limit")
+	if !ok {
+		return
+	}
+	filter.Limit = int(min(limit, models.MaxAuditLimit))
+
+	events, err := h.svc.ListAuditEvents(r.Context(), filter)
+	if err != nil {
+		h.log.Error("failed to list audit events", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"events": events})
+}
+
+// auditTime parses an audit filter time, answering 400 if it is invalid
+func auditTime(w http.ResponseWriter, value, name string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		http.Error(w, name+" must be an RFC 3339 time or YYYY-MM-DD", http.StatusBadRequest)
+		return nil, false
+	}
+	return &t, true
+}
+
+// auditNumber parses a positive audit filter number, answering 400 if it is invalid
+func auditNumber(w http.ResponseWriter, value, name string) (int64, bool) {
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 1 {
+		http.Error(w, name+" must be a positive number", http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
@@ -30,12 +30,12 @@ func (h *Handler) handleCourseAdminsList(w http.ResponseWriter, r *http.Request,
 	}
 
 	// Check if user has access to this course
-	if !auth.IsCourseOrSuperAdmin(r.Context(), userID, courseID) {
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, courseID) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
-	admins, err := models.GetCourseAdmins(r.Context(), courseID)
+	admins, err := h.svc.GetCourseAdmins(r.Context(), courseID)
 	if err != nil {
 		h.log.Error("failed to get course admins", "error", err, "course_id", courseID)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -57,7 +57,7 @@ func (h *Handler) handleCourseAdminAdd(w http.ResponseWriter, r *http.Request, c
 	}
 
 	// Only super admins can assign course admins
-	if !models.IsUserSuperAdmin(r.Context(), userID) {
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		http.Error(w, "Super admin access required", http.StatusForbidden)
 		return
 	}
@@ -74,7 +74,7 @@ func (h *Handler) handleCourseAdminAdd(w http.ResponseWriter, r *http.Request, c
 	}
 
 	// Check if user exists
-	_, err := models.GetUser(r.Context(), req.UserID)
+	_, err := h.svc.GetUser(r.Context(), req.UserID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "User not found", http.StatusNotFound)
@@ -86,7 +86,7 @@ func (h *Handler) handleCourseAdminAdd(w http.ResponseWriter, r *http.Request, c
 	}
 
 	// Check if course exists
-	_, err = models.GetCourse(r.Context(), courseID)
+	_, err = h.svc.GetCourse(r.Context(), courseID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Course not found", http.StatusNotFound)
@@ -98,7 +98,7 @@ func (h *Handler) handleCourseAdminAdd(w http.ResponseWriter, r *http.Request, c
 	}
 
 	// Add course admin assignment
-	assignment, err := models.AddCourseAdmin(r.Context(), courseID, req.UserID)
+	assignment, err := h.svc.AddCourseAdmin(r.Context(), courseID, req.UserID)
 	if err != nil {
 		h.log.Error("failed to add course admin", "error", err, "course_id", courseID, "user_id", req.UserID)
 		http.Error(w, "Failed to add course admin", http.StatusInternalServerError)
@@ -119,13 +119,13 @@ func (h *Handler) handleCourseAdminRemove(w http.ResponseWriter, r *http.Request
 	}
 
 	// Only super admins can remove course admins
-	if !models.IsUserSuperAdmin(r.Context(), userID) {
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		http.Error(w, "Super admin access required", http.StatusForbidden)
 		return
 	}
 
 	// Check if course exists
-	_, err := models.GetCourse(r.Context(), courseID)
+	_, err := h.svc.GetCourse(r.Context(), courseID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Course not found", http.StatusNotFound)
@@ -137,13 +137,13 @@ func (h *Handler) handleCourseAdminRemove(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check if user is actually a course admin
-	if !models.IsUserOnlyCourseAdmin(r.Context(), targetUserID, courseID) {
+	if !h.svc.IsUserOnlyCourseAdmin(r.Context(), targetUserID, courseID) {
 		http.Error(w, "User is not an admin of this course", http.StatusNotFound)
 		return
 	}
 
 	// Remove course admin assignment
-	err = models.RemoveCourseAdmin(r.Context(), courseID, targetUserID)
+	err = h.svc.RemoveCourseAdmin(r.Context(), courseID, targetUserID)
 	if err != nil {
 		h.log.Error("failed to remove course admin", "error", err, "course_id", courseID, "user_id", targetUserID)
 		http.Error(w, "Failed to remove course admin", http.StatusInternalServerError)
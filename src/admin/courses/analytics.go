@@ -0,0 +1,83 @@
+package courses
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// analyticsSections picks one part of the course analytics for /analytics/{section}
+var analyticsSections = map[string]func(*models.CourseAnalytics) any{
+	"funnel":   func(a *models.CourseAnalytics) any { return a.Funnel },
+	"time":     func(a *models.CourseAnalytics) any { return a.PhaseTimes },
+	"accuracy": func(a *models.CourseAnalytics) any { return a.Accuracy },
+	"vocab":    func(a *models.CourseAnalytics) any { return a.MissedVocab },
+	"grammar":  func(a *models.CourseAnalytics) any { return a.MissedGrammar },
+}
+
+// analyticsHandler handles GET /courses/{id}/analytics[/{section}]?status=
+func (h *Handler) analyticsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	section, hasSection := vars["section"]
+	pick, ok := analyticsSections[section]
+	if hasSection && !ok {
+		http.Error(w, "Invalid section. Must be: funnel, time, accuracy, vocab, or grammar", http.StatusNotFound)
+		return
+	}
+
+	// Same filter as the student performance endpoint
+	status := r.URL.Query().Get("status")
+	if !slices.Contains([]string{"", "active", "future", "past"}, status) {
+		http.Error(w, "Invalid status parameter. Must be: active, future, past, or empty", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(courseID)) {
+		h.log.Warn("course analytics access denied", "user_id", userID, "course_id", courseID)
+		http.Error(w, "Forbidden - course admin access required", http.StatusForbidden)
+		return
+	}
+
+	if _, err := h.svc.GetCourse(r.Context(), int32(courseID)); err == models.ErrNotFound {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error("failed to get course", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	analytics, err := h.svc.GetCourseAnalytics(r.Context(), int32(courseID), status)
+	if err != nil {
+		h.log.Error("failed to compute course analytics", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var data any = analytics
+	if hasSection {
+		data = pick(analytics)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.APIResponse{Success: true, Data: data}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package courses
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// atRiskHandler handles GET /courses/{id}/at-risk
+func (h *Handler) atRiskHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := h.adminCourseID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.svc.GetCourse(r.Context(), courseID); err == models.ErrNotFound {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error("failed to get course", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	atRisk, err := h.svc.GetCourseAtRisk(r.Context(), courseID)
+	if err != nil {
+		h.log.Error("failed to detect at-risk students", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.APIResponse{Success: true, Data: atRisk}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
+
+// atRiskSettingsHandler handles GET/PUT /courses/{id}/at-risk/settings
+func (h *Handler) atRiskSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := h.adminCourseID(w, r)
+	if !ok {
+		return
+	}
+
+	var thresholds *models.RiskThresholds
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		thresholds, err = h.svc.GetCourseRiskThresholds(r.Context(), courseID)
+	case http.MethodPut:
+		var req models.RiskThresholds
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		thresholds, err = h.svc.SetCourseRiskThresholds(r.Context(), courseID, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err == models.ErrInvalidRiskThresholds {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.log.Error("failed to handle course risk thresholds", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.APIResponse{Success: true, Data: thresholds}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
+
+// adminCourseID parses the course ID and checks the user administers the course
+func (h *Handler) adminCourseID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return 0, false
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(courseID)) {
+		h.log.Warn("course admin access denied", "user_id", userID, "course_id", courseID, "path", r.URL.Path)
+		http.Error(w, "Forbidden - course admin access required", http.StatusForbidden)
+		return 0, false
+	}
+	return int32(courseID), true
+}
@@ -0,0 +1,76 @@
+package courses
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CloneCourseRequest represents the request body for cloning a course
+type CloneCourseRequest struct {
+	CourseNumber string  `json:"course_number"`
+	Name         string  `json:"name"`        // Optional, defaults to the original's
+	Description  *string `json:"description"` // Optional, defaults to the original's
+	CopyAdmins   bool    `json:"copy_admins"`
+}
+
+// cloneCourseHandler handles POST /courses/{id}/clone, copying a course with all of its
+// stories into a new course (super admin only, like creating a course)
+func (h *Handler) cloneCourseHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
+		http.Error(w, "Super admin access required", http.StatusForbidden)
+		return
+	}
+
+	var req CloneCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	clone, err := h.svc.CloneCourse(r.Context(), int32(courseID), models.CourseCloneOptions{
+		CourseNumber: req.CourseNumber,
+		Name:         req.Name,
+		Description:  req.Description,
+		CopyAdmins:   req.CopyAdmins,
+	})
+	switch err {
+	case nil:
+	case models.ErrNotFound:
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case models.ErrMissingCourseNumber:
+		http.Error(w, "Course number is required", http.StatusBadRequest)
+		return
+	case models.ErrCourseNumberTaken:
+		http.Error(w, "Course number already in use", http.StatusConflict)
+		return
+	default:
+		h.log.Error("failed to clone course", "error", err, "course_id", courseID, "course_number", req.CourseNumber)
+		http.Error(w, "Failed to clone course", http.StatusInternalServerError)
+		return
+	}
+	h.log.Info("cloned course", "course_id", courseID, "clone_id", clone.Course.CourseID, "stories", len(clone.Stories), "user_id", userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(types.APIResponse{Success: true, Data: clone}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
@@ -41,12 +41,12 @@ func (h *Handler) handleCoursesList(w http.ResponseWriter, r *http.Request) {
 	var courses []models.Course
 	var err error
 
-	if models.IsUserSuperAdmin(r.Context(), userID) {
+	if h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		// Super admins can list all courses
-		courses, err = models.ListAllCourses(r.Context())
+		courses, err = h.svc.ListAllCourses(r.Context())
 	} else {
 		// Regular admins can only see courses they admin
-		courses, err = models.GetAdminCoursesForUser(r.Context(), userID)
+		courses, err = h.svc.GetAdminCoursesForUser(r.Context(), userID)
 	}
 
 	if err != nil {
@@ -70,7 +70,7 @@ func (h *Handler) handleCourseCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Only super admins can create courses
-	if !models.IsUserSuperAdmin(r.Context(), userID) {
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		http.Error(w, "Super admin access required", http.StatusForbidden)
 		return
 	}
@@ -86,7 +86,7 @@ func (h *Handler) handleCourseCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	course, err := models.CreateCourse(r.Context(), req.CourseNumber, req.Name, req.Description)
+	course, err := h.svc.CreateCourse(r.Context(), req.CourseNumber, req.Name, req.Description)
 	if err != nil {
 		h.log.Error("failed to create course", "error", err, "course_number", req.CourseNumber)
 		http.Error(w, "Failed to create course", http.StatusInternalServerError)
@@ -107,12 +107,12 @@ func (h *Handler) handleCourseGet(w http.ResponseWriter, r *http.Request, course
 	}
 
 	// Check if user has access to this course
-	if !auth.HasReadPermission(r.Context(), userID, courseID) {
+	if !h.svc.CanUserAccessCourse(r.Context(), userID, courseID) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
-	course, err := models.GetCourse(r.Context(), courseID)
+	course, err := h.svc.GetCourse(r.Context(), courseID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Course not found", http.StatusNotFound)
@@ -136,7 +136,7 @@ func (h *Handler) handleCourseUpdate(w http.ResponseWriter, r *http.Request, cou
 	}
 
 	// Only super admins can update courses
-	if !models.IsUserSuperAdmin(r.Context(), userID) {
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		http.Error(w, "Super admin access required", http.StatusForbidden)
 		return
 	}
@@ -152,7 +152,7 @@ func (h *Handler) handleCourseUpdate(w http.ResponseWriter, r *http.Request, cou
 		return
 	}
 
-	course, err := models.UpdateCourse(r.Context(), courseID, req.CourseNumber, req.Name, req.Description)
+	course, err := h.svc.UpdateCourse(r.Context(), courseID, req.CourseNumber, req.Name, req.Description)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Course not found", http.StatusNotFound)
@@ -167,7 +167,7 @@ func (h *Handler) handleCourseUpdate(w http.ResponseWriter, r *http.Request, cou
 	json.NewEncoder(w).Encode(course)
 }
 
-// handleCourseDelete deletes a course (super admin only)
+// handleCourseDelete moves a course and its stories to the trash (super admin only)
 func (h *Handler) handleCourseDelete(w http.ResponseWriter, r *http.Request, courseID int32) {
 	userID, ok := auth.GetUserIDWithOk(r)
 	if !ok {
@@ -176,12 +176,12 @@ func (h *Handler) handleCourseDelete(w http.ResponseWriter, r *http.Request, cou
 	}
 
 	// Only super admins can delete courses
-	if !models.IsUserSuperAdmin(r.Context(), userID) {
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		http.Error(w, "Super admin access required", http.StatusForbidden)
 		return
 	}
 
-	err := models.DeleteCourse(r.Context(), courseID)
+	err := h.svc.DeleteCourse(r.Context(), courseID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Course not found", http.StatusNotFound)
@@ -0,0 +1,133 @@
+package courses
+
+import (
+	"fmt"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"glossias/src/pkg/spreadsheet"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// gradebookColumns are the per-story columns of the gradebook
+var gradebookColumns = []string{
+	"Vocab %", "Grammar %", "Translation", "Vocab time (s)",
+	"Grammar time (s)", "Translation time (s)", "Video time (s)", "Total time (s)", "Late",
+}
+
+// gradebookHandler handles GET /courses/{id}/gradebook?format=csv|xlsx&status=
+func (h *Handler) gradebookHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		http.Error(w, "Invalid format parameter. Must be: csv or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	// Same filter as the student performance endpoint
+	status := r.URL.Query().Get("status")
+	if !slices.Contains([]string{"", "active", "future", "past"}, status) {
+		http.Error(w, "Invalid status parameter. Must be: active, future, past, or empty", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(courseID)) {
+		h.log.Warn("gradebook access denied", "user_id", userID, "course_id", courseID)
+		http.Error(w, "Forbidden - course admin access required", http.StatusForbidden)
+		return
+	}
+
+	course, err := h.svc.GetCourse(r.Context(), int32(courseID))
+	if err == models.ErrNotFound {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("failed to get course", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	gradebook, err := h.svc.GetCourseGradebook(r.Context(), int32(courseID), status)
+	if err != nil {
+		h.log.Error("failed to build gradebook", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	rows := gradebookRows(gradebook)
+	filename := fmt.Sprintf("gradebook-%s.%s", course.CourseNumber, format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = spreadsheet.WriteXLSX(w, "Gradebook", rows)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = spreadsheet.WriteCSV(w, rows)
+	}
+	if err != nil {
+		h.log.Error("failed to write gradebook", "error", err, "course_id", courseID, "format", format)
+	}
+}
+
+// gradebookRows lays the gradebook out with one row per student and a group of columns per story
+func gradebookRows(gradebook *models.Gradebook) [][]any {
+	header := []any{"Name", "Email"}
+	for _, story := range gradebook.Stories {
+		label := fmt.Sprintf("W%d%s %s", story.Metadata.WeekNumber, story.Metadata.DayLetter, story.Metadata.Title["en"])
+		for _, column := range gradebookColumns {
+			header = append(header, label+" - "+column)
+		}
+	}
+
+	rows := [][]any{header}
+	for _, student := range gradebook.Students {
+		row := []any{student.UserName, student.Email}
+		for i, perf := range student.Stories {
+			if perf.UserID == "" {
+				// Not in the story's performance rows: no attempt, not a zero
+				row = append(row, make([]any, len(gradebookColumns))...)
+				continue
+			}
+			row = append(row,
+				accuracyCell(perf.VocabAccuracy, perf.VocabCorrect+perf.VocabIncorrect),
+				accuracyCell(perf.GrammarAccuracy, perf.GrammarCorrect+perf.GrammarIncorrect),
+				perf.TranslationCompleted,
+				perf.VocabTimeSeconds,
+				perf.GrammarTimeSeconds,
+				perf.TranslationTimeSeconds,
+				perf.VideoTimeSeconds,
+				perf.TotalTimeSeconds,
+				student.Late[i],
+			)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// accuracyCell rounds a percentage to one decimal place, or leaves the cell empty when
+// the student has not answered anything
+func accuracyCell(accuracy float64, answers int64) any {
+	if answers == 0 {
+		return nil
+	}
+	return math.Round(accuracy*10) / 10
+}
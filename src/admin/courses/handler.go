@@ -6,16 +6,20 @@ import (
 	"net/http"
 	"strconv"
 
+	"glossias/src/pkg/models"
+
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
 	log *slog.Logger
+	svc *models.Service
 }
 
-func NewHandler(log *slog.Logger) *Handler {
+func NewHandler(log *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
 		log: log,
+		svc: svc,
 	}
 }
 
@@ -30,6 +34,9 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 	courses.HandleFunc("/{id:[0-9]+}", h.updateCourseHandler).Methods("PUT", "OPTIONS")
 	courses.HandleFunc("/{id:[0-9]+}", h.deleteCourseHandler).Methods("DELETE", "OPTIONS")
 
+	// Copy a course with its stories, e.g. for a new semester
+	courses.HandleFunc("/{id:[0-9]+}/clone", h.cloneCourseHandler).Methods("POST", "OPTIONS")
+
 	// User-course assignment endpoints
 	courses.HandleFunc("/{id:[0-9]+}/admins", h.listCourseAdminsHandler).Methods("GET", "OPTIONS")
 	courses.HandleFunc("/{id:[0-9]+}/admins", h.addCourseAdminHandler).Methods("POST", "OPTIONS")
@@ -37,6 +44,27 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 
 	// Student performance endpoint
 	courses.HandleFunc("/{id:[0-9]+}/student-performance", h.studentPerformanceHandler).Methods("GET", "OPTIONS")
+
+	// Gradebook export
+	courses.HandleFunc("/{id:[0-9]+}/gradebook", h.gradebookHandler).Methods("GET", "OPTIONS")
+
+	// Course-wide analytics, whole or one section at a time
+	courses.HandleFunc("/{id:[0-9]+}/analytics", h.analyticsHandler).Methods("GET", "OPTIONS")
+	courses.HandleFunc("/{id:[0-9]+}/analytics/{section}", h.analyticsHandler).Methods("GET", "OPTIONS")
+
+	// Which lexical forms students confuse with which, across the course
+	courses.HandleFunc("/{id:[0-9]+}/vocab-confusions", h.vocabConfusionsHandler).Methods("GET", "OPTIONS")
+
+	// Students at risk of falling behind, and the thresholds that decide it
+	courses.HandleFunc("/{id:[0-9]+}/at-risk", h.atRiskHandler).Methods("GET", "OPTIONS")
+	courses.HandleFunc("/{id:[0-9]+}/at-risk/settings", h.atRiskSettingsHandler).Methods("GET", "PUT", "OPTIONS")
+
+	// Release schedules of the course's stories, and shifting them all for a new semester
+	courses.HandleFunc("/{id:[0-9]+}/schedule", h.scheduleHandler).Methods("GET", "OPTIONS")
+	courses.HandleFunc("/{id:[0-9]+}/schedule/shift", h.shiftScheduleHandler).Methods("POST", "OPTIONS")
+
+	// Phase flow endpoint
+	courses.HandleFunc("/{id:[0-9]+}/phase-flow", h.phaseFlowHandler).Methods("GET", "PUT", "OPTIONS")
 }
 
 // Course CRUD handlers
@@ -0,0 +1,67 @@
+package courses
+
+import (
+	"encoding/json"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// PhaseFlowRequest represents the request body for setting a course's phase flow
+type PhaseFlowRequest struct {
+	Phases []string `json:"phases"` // Empty restores the default flow
+}
+
+// phaseFlowHandler handles GET/PUT /courses/{id}/phase-flow
+func (h *Handler) phaseFlowHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(courseID)) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	var flow *models.PhaseFlow
+	switch r.Method {
+	case http.MethodGet:
+		flow, err = h.svc.GetCoursePhaseFlow(r.Context(), courseID)
+	case http.MethodPut:
+		var req PhaseFlowRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		flow, err = h.svc.SetCoursePhaseFlow(r.Context(), courseID, req.Phases)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err == models.ErrInvalidPhaseFlow {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.log.Error("failed to handle course phase flow", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"phases":    flow.Phases,
+		"source":    flow.Source,
+		"available": models.KnownPhases,
+	})
+}
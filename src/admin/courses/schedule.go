@@ -0,0 +1,80 @@
+package courses
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/pkg/models"
+	"net/http"
+)
+
+// maxScheduleShiftDays bounds a shift to ten years either way
+const maxScheduleShiftDays = 3650
+
+type ShiftScheduleRequest struct {
+	Days int `json:"days"` // Negative moves the schedule earlier
+}
+
+// scheduleHandler handles GET /courses/{id}/schedule, listing every story of the course
+// with its release schedule
+func (h *Handler) scheduleHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := h.adminCourseID(w, r)
+	if !ok {
+		return
+	}
+
+	stories, err := h.svc.GetStoriesForCourse(r.Context(), int(courseID))
+	if err != nil {
+		h.log.Error("failed to get course stories", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.APIResponse{Success: true, Data: stories}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
+
+// shiftScheduleHandler handles POST /courses/{id}/schedule/shift, moving every scheduled
+// time of the course by the given number of days, e.g. for a new semester
+func (h *Handler) shiftScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := h.adminCourseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ShiftScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Days == 0 || req.Days > maxScheduleShiftDays || req.Days < -maxScheduleShiftDays {
+		http.Error(w, "days must be a non-zero number of days within ten years", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.svc.GetCourse(r.Context(), courseID); err == models.ErrNotFound {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error("failed to get course", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	shifted, err := h.svc.ShiftCourseSchedule(r.Context(), courseID, req.Days)
+	if err != nil {
+		h.log.Error("failed to shift course schedule", "error", err, "course_id", courseID, "days", req.Days)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.log.Info("shifted course schedule", "course_id", courseID, "days", req.Days, "stories", shifted)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.APIResponse{
+		Success: true,
+		Data:    map[string]int{"days": req.Days, "shifted_stories": shifted},
+	}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"glossias/src/apis/types"
 	"glossias/src/auth"
-	"glossias/src/pkg/models"
 	"net/http"
 	"slices"
 	"strconv"
@@ -38,7 +37,7 @@ func (h *Handler) studentPerformanceHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Get story course ID for permission check
-	courseID, err := models.GetStoryCourseID(r.Context(), int32(storyID))
+	courseID, err := h.svc.GetStoryCourseID(r.Context(), int32(storyID))
 	if err != nil {
 		h.log.Error("Failed to get story course ID", "error", err, "story_id", storyID)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -46,14 +45,14 @@ func (h *Handler) studentPerformanceHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Check if user is course admin for this specific course
-	if !auth.IsCourseOrSuperAdmin(r.Context(), userID, courseID) {
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, courseID) {
 		h.log.Warn("student performance access denied", "user_id", userID, "story_id", storyID, "course_id", courseID)
 		http.Error(w, "Forbidden - course admin access required", http.StatusForbidden)
 		return
 	}
 
 	// Get student performance data
-	performanceData, err := models.GetStoryStudentPerformance(r.Context(), int32(storyID), status)
+	performanceData, err := h.svc.GetStoryStudentPerformance(r.Context(), int32(storyID), status)
 	if err != nil {
 		h.log.Error("Failed to fetch story student performance", "error", err, "story_id", storyID)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
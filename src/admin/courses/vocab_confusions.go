@@ -0,0 +1,60 @@
+package courses
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// vocabConfusionsHandler handles GET /courses/{id}/vocab-confusions?status=
+func (h *Handler) vocabConfusionsHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if !slices.Contains([]string{"", "active", "future", "past"}, status) {
+		http.Error(w, "Invalid status parameter. Must be: active, future, past, or empty", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(courseID)) {
+		h.log.Warn("vocab confusions access denied", "user_id", userID, "course_id", courseID)
+		http.Error(w, "Forbidden - course admin access required", http.StatusForbidden)
+		return
+	}
+
+	if _, err := h.svc.GetCourse(r.Context(), int32(courseID)); err == models.ErrNotFound {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error("failed to get course", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	matrix, err := h.svc.GetCourseVocabConfusions(r.Context(), int32(courseID), status)
+	if err != nil {
+		h.log.Error("failed to build vocab confusion matrix", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.APIResponse{Success: true, Data: matrix}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
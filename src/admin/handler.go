@@ -2,6 +2,7 @@
 package admin
 
 import (
+	"encoding/json"
 	"glossias/src/auth"
 	"glossias/src/pkg/models"
 	"log/slog"
@@ -17,17 +18,19 @@ import (
 
 type Handler struct {
 	log     *slog.Logger
+	svc     *models.Service
 	stories *stories.Handler
 	courses *courses.Handler
 	users   *adminusers.Handler
 }
 
-func NewHandler(log *slog.Logger) *Handler {
+func NewHandler(log *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
 		log:     log,
-		stories: stories.NewHandler(log),
-		courses: courses.NewHandler(log),
-		users:   adminusers.NewHandler(log),
+		svc:     svc,
+		stories: stories.NewHandler(log, svc),
+		courses: courses.NewHandler(log, svc),
+		users:   adminusers.NewHandler(log, svc),
 	}
 }
 
@@ -35,6 +38,7 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 	// Admin routes are now mounted by the caller under /api/admin
 	// Apply admin-specific middleware at this level
 	r.Use(h.adminAuthMiddleware)
+	r.Use(h.auditMiddleware)
 
 	// Register all admin routes beneath the provided base router
 	h.stories.RegisterRoutes(r)
@@ -43,6 +47,21 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 
 	// Cache management endpoint (super admin only)
 	r.HandleFunc("/cache/clear", h.clearCache).Methods("POST")
+
+	// Story score recompute job (super admin only)
+	r.HandleFunc("/scores/recompute", h.scoreRecompute).Methods("GET", "POST")
+
+	// Audit log of admin changes (super admin only)
+	r.HandleFunc("/audit", h.audit).Methods("GET")
+
+	// Deleted stories and courses, until they are purged
+	r.HandleFunc("/trash", h.trash).Methods("GET")
+	r.HandleFunc("/trash/stories/{id:[0-9]+}/restore", h.restoreStory).Methods("POST")
+	r.HandleFunc("/trash/courses/{id:[0-9]+}/restore", h.restoreCourse).Methods("POST")
+
+	// LTI platform registrations (super admin only)
+	r.HandleFunc("/lti/platforms", h.ltiPlatforms).Methods("GET", "POST")
+	r.HandleFunc("/lti/platforms/{id}", h.deleteLTIPlatform).Methods("DELETE")
 }
 
 func (h *Handler) adminAuthMiddleware(next http.Handler) http.Handler {
@@ -56,7 +75,7 @@ func (h *Handler) adminAuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Check if user is admin (super admin or course admin)
-		if !auth.IsAnyAdmin(r.Context(), userID) {
+		if !h.svc.IsUserAnyAdmin(r.Context(), userID) {
 			h.log.Warn("admin access denied", "user_id", userID, "path", r.URL.Path)
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
@@ -74,7 +93,7 @@ func (h *Handler) adminAuthMiddleware(next http.Handler) http.Handler {
 			}
 
 			// Check if user has access to this specific course
-			if !auth.IsCourseAdmin(r.Context(), userID, int32(courseID)) {
+			if !h.svc.IsUserOnlyCourseAdmin(r.Context(), userID, int32(courseID)) {
 				h.log.Warn("course access denied", "user_id", userID, "course_id", courseID, "path", r.URL.Path)
 				http.Error(w, "Course access forbidden", http.StatusForbidden)
 				return
@@ -93,13 +112,13 @@ func (h *Handler) clearCache(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Restrict to super admins only
-	if !models.IsUserSuperAdmin(r.Context(), userID) {
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
 		h.log.Warn("cache clear denied - not super admin", "user_id", userID)
 		http.Error(w, "Forbidden - super admin required", http.StatusForbidden)
 		return
 	}
 
-	if err := models.ClearAllCache(); err != nil {
+	if err := h.svc.ClearAllCache(); err != nil {
 		h.log.Error("failed to clear cache", "error", err, "user_id", userID)
 		http.Error(w, "Failed to clear cache", http.StatusInternalServerError)
 		return
@@ -109,3 +128,37 @@ func (h *Handler) clearCache(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Cache cleared successfully"))
 }
+
+// scoreRecompute starts (POST) or reports on (GET) recomputing every story score snapshot,
+// for use after the score formula changes
+func (h *Handler) scoreRecompute(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Restrict to super admins only
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
+		h.log.Warn("score recompute denied - not super admin", "user_id", userID)
+		http.Error(w, "Forbidden - super admin required", http.StatusForbidden)
+		return
+	}
+
+	status := h.svc.GetScoreRecomputeStatus()
+	code := http.StatusOK
+	if r.Method == http.MethodPost {
+		var started bool
+		status, started = h.svc.StartScoreRecompute()
+		if !started {
+			code = http.StatusConflict
+		} else {
+			h.log.Info("score recompute started by admin", "user_id", userID, "formula_version", status.FormulaVersion)
+			code = http.StatusAccepted
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
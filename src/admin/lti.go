@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"encoding/json"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// LTIPlatformRequest represents the request body for registering an LTI platform
+type LTIPlatformRequest struct {
+	Name         string `json:"name"`
+	Issuer       string `json:"issuer"`
+	ClientID     string `json:"client_id"`
+	DeploymentID string `json:"deployment_id"` // Empty accepts any deployment
+	AuthLoginURL string `json:"auth_login_url"`
+	AuthTokenURL string `json:"auth_token_url"`
+	JWKSURL      string `json:"jwks_url"`
+}
+
+// ltiPlatforms lists (GET) or registers (POST) LTI platforms. Registering an issuer and
+// client id that already exist updates that registration.
+func (h *Handler) ltiPlatforms(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireSuperAdmin(w, r, "lti platforms")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		platforms, err := h.svc.ListLTIPlatforms(r.Context())
+		if err != nil {
+			h.log.Error("failed to list LTI platforms", "error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(platforms)
+	case http.MethodPost:
+		var req LTIPlatformRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" || req.Issuer == "" || req.ClientID == "" ||
+			req.AuthLoginURL == "" || req.AuthTokenURL == "" || req.JWKSURL == "" {
+			http.Error(w, "name, issuer, client_id, auth_login_url, auth_token_url and jwks_url are required", http.StatusBadRequest)
+			return
+		}
+
+		platform, err := h.svc.SaveLTIPlatform(r.Context(), models.LTIPlatform{
+			Name:         req.Name,
+			Issuer:       req.Issuer,
+			ClientID:     req.ClientID,
+			DeploymentID: req.DeploymentID,
+			AuthLoginURL: req.AuthLoginURL,
+			AuthTokenURL: req.AuthTokenURL,
+			JWKSURL:      req.JWKSURL,
+		})
+		if err != nil {
+			h.log.Error("failed to save LTI platform", "error", err, "issuer", req.Issuer)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		h.log.Info("LTI platform registered by admin", "user_id", userID, "platform_id", platform.ID, "issuer", platform.Issuer)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(platform)
+	}
+}
+
+// deleteLTIPlatform removes a platform registration. Courses and users it created are kept.
+func (h *Handler) deleteLTIPlatform(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireSuperAdmin(w, r, "lti platform delete")
+	if !ok {
+		return
+	}
+
+	platformID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid platform ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.DeleteLTIPlatform(r.Context(), int32(platformID)); err != nil {
+		h.log.Error("failed to delete LTI platform", "error", err, "platform_id", platformID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("LTI platform deleted by admin", "user_id", userID, "platform_id", platformID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireSuperAdmin writes an error response and returns false unless the user is a super admin
+func (h *Handler) requireSuperAdmin(w http.ResponseWriter, r *http.Request, action string) (string, bool) {
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	if !h.svc.IsUserSuperAdmin(r.Context(), userID) {
+		h.log.Warn(action+" denied - not super admin", "user_id", userID)
+		http.Error(w, "Forbidden - super admin required", http.StatusForbidden)
+		return "", false
+	}
+	return userID, true
+}
@@ -50,12 +50,12 @@ func (h *Handler) addStoryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate course access
-	if !auth.IsCourseOrSuperAdmin(ctx, auth.GetUserID(r), int32(req.CourseID)) {
+	if !h.svc.IsUserCourseOrSuperAdmin(ctx, auth.GetUserID(r), int32(req.CourseID)) {
 		http.Error(w, "Forbidden: not a course admin", http.StatusForbidden)
 		return
 	}
 	// Check course exists
-	_, err := models.GetCourse(ctx, int32(req.CourseID))
+	_, err := h.svc.GetCourse(ctx, int32(req.CourseID))
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Course not found", http.StatusBadRequest)
@@ -75,7 +75,7 @@ func (h *Handler) addStoryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save the story
-	if err := models.SaveNewStory(ctx, story); err != nil {
+	if err := h.svc.SaveNewStory(ctx, story); err != nil {
 		h.log.Error("Failed to save story", "error", err)
 		http.Error(w, "Failed to save story", http.StatusInternalServerError)
 		return
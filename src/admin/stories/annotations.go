@@ -56,7 +56,7 @@ func (h *Handler) handleGetStoryAnnotations(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
-		line, err := models.GetLineAnnotations(ctx, storyID, lineNumber)
+		line, err := h.svc.GetLineAnnotations(ctx, storyID, lineNumber)
 		if err != nil {
 			if err == models.ErrNotFound {
 				writeJSONError(w, "Story not found", http.StatusNotFound)
@@ -72,7 +72,7 @@ func (h *Handler) handleGetStoryAnnotations(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Get all annotations for story
-	annotations, err := models.GetStoryAnnotations(ctx, storyID)
+	annotations, err := h.svc.GetStoryAnnotations(ctx, storyID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			writeJSONError(w, "Story not found", http.StatusNotFound)
@@ -96,7 +96,7 @@ func (h *Handler) handleAddAnnotations(w http.ResponseWriter, r *http.Request, s
 	}
 
 	// Get line text for validation
-	lineText, err := models.GetLineText(ctx, storyID, req.LineNumber)
+	lineText, err := h.svc.GetLineText(ctx, storyID, req.LineNumber)
 	if err != nil {
 		if err == models.ErrInvalidLineNumber {
 			writeJSONError(w, "Line not found", http.StatusNotFound)
@@ -129,7 +129,7 @@ func (h *Handler) handleAddAnnotations(w http.ResponseWriter, r *http.Request, s
 		}
 		// Validate grammar point exists and belongs to this story if specified
 		if req.Grammar.GrammarPointID != nil {
-			grammarPoint, err := models.GetGrammarPoint(ctx, *req.Grammar.GrammarPointID)
+			grammarPoint, err := h.svc.GetGrammarPoint(ctx, *req.Grammar.GrammarPointID)
 			if err != nil {
 				if err == models.ErrNotFound {
 					writeJSONError(w, "Grammar point not found", http.StatusBadRequest)
@@ -153,7 +153,7 @@ func (h *Handler) handleAddAnnotations(w http.ResponseWriter, r *http.Request, s
 	}
 
 	// Update in database (editline just adds)
-	if err := models.AddLineAnnotations(ctx, storyID, req.LineNumber, line); err != nil {
+	if err := h.svc.AddLineAnnotations(ctx, storyID, req.LineNumber, line); err != nil {
 		h.log.Error("Failed to update annotations",
 			"error", err,
 			"storyID", storyID,
@@ -179,25 +179,25 @@ func (h *Handler) handleEditAnnotations(w http.ResponseWriter, r *http.Request,
 	// Validate that we have both the annotation data and the identifier
 	switch {
 	case req.Grammar != nil && req.GrammarPosition != nil:
-		if err := models.UpdateGrammarAnnotation(ctx, storyID, req.LineNumber, *req.GrammarPosition, *req.Grammar); err != nil {
+		if err := h.svc.UpdateGrammarAnnotation(ctx, storyID, req.LineNumber, *req.GrammarPosition, *req.Grammar); err != nil {
 			h.log.Error("Failed to update grammar annotation", "error", err, "storyID", storyID, "lineNumber", req.LineNumber)
 			writeJSONError(w, "Failed to update grammar annotation", http.StatusInternalServerError)
 			return
 		}
 	case req.Footnote != nil && req.FootnoteID != nil:
-		if err := models.UpdateFootnoteAnnotation(ctx, storyID, *req.FootnoteID, *req.Footnote); err != nil {
+		if err := h.svc.UpdateFootnoteAnnotation(ctx, storyID, *req.FootnoteID, *req.Footnote); err != nil {
 			h.log.Error("Failed to update footnote annotation", "error", err, "storyID", storyID, "footnoteID", *req.FootnoteID)
 			writeJSONError(w, "Failed to update footnote annotation", http.StatusInternalServerError)
 			return
 		}
 	case req.Vocabulary != nil && req.VocabularyPosition != nil:
-		if err := models.UpdateVocabularyAnnotation(ctx, storyID, req.LineNumber, *req.VocabularyPosition, *req.Vocabulary); err != nil {
+		if err := h.svc.UpdateVocabularyAnnotation(ctx, storyID, req.LineNumber, *req.VocabularyPosition, *req.Vocabulary); err != nil {
 			h.log.Error("Failed to update vocabulary annotation", "error", err, "storyID", storyID, "lineNumber", req.LineNumber)
 			writeJSONError(w, "Failed to update vocabulary annotation", http.StatusInternalServerError)
 			return
 		}
 	case req.Vocabulary != nil:
-		if err := models.UpdateVocabularyByWord(ctx, storyID, req.LineNumber, req.Vocabulary.Word, req.Vocabulary.LexicalForm); err != nil {
+		if err := h.svc.UpdateVocabularyByWord(ctx, storyID, req.LineNumber, req.Vocabulary.Word, req.Vocabulary.LexicalForm); err != nil {
 			h.log.Error("Failed to update vocabulary lexical form", "error", err, "storyID", storyID, "lineNumber", req.LineNumber, "word", req.Vocabulary.Word)
 			writeJSONError(w, "Failed to update vocabulary lexical form", http.StatusInternalServerError)
 			return
@@ -225,14 +225,14 @@ func (h *Handler) handleClearAnnotations(w http.ResponseWriter, r *http.Request,
 			return
 		}
 
-		if err := models.ClearLineAnnotations(ctx, storyID, lineNumber); err != nil {
+		if err := h.svc.ClearLineAnnotations(ctx, storyID, lineNumber); err != nil {
 			h.log.Error("Failed to clear line annotations", "error", err, "storyID", storyID, "lineNumber", lineNumber)
 			writeJSONError(w, "Failed to clear line annotations", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Clear all annotations for story
-		if err := models.ClearStoryAnnotations(ctx, storyID); err != nil {
+		if err := h.svc.ClearStoryAnnotations(ctx, storyID); err != nil {
 			h.log.Error("Failed to clear annotations", "error", err, "storyID", storyID)
 			writeJSONError(w, "Failed to clear annotations", http.StatusInternalServerError)
 			return
@@ -46,7 +46,7 @@ func (h *Handler) requestAudioUploadURL(w http.ResponseWriter, r *http.Request)
 
 	// Admin authentication check
 	userID, ok := auth.GetUserIDWithOk(r)
-	if !ok || !models.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -58,7 +58,7 @@ func (h *Handler) requestAudioUploadURL(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check if story exists
-	exists, err := models.StoryExists(r.Context(), int32(req.StoryID))
+	exists, err := h.svc.StoryExists(r.Context(), int32(req.StoryID))
 	if err != nil {
 		h.log.Error("Failed to check story existence", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -70,7 +70,7 @@ func (h *Handler) requestAudioUploadURL(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check if line exists
-	lineExists, err := models.LineExists(r.Context(), req.StoryID, req.LineNumber)
+	lineExists, err := h.svc.LineExists(r.Context(), req.StoryID, req.LineNumber)
 	if err != nil {
 		h.log.Error("Failed to check line existence", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -82,7 +82,7 @@ func (h *Handler) requestAudioUploadURL(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check if audio file already exists for this line and label
-	existingAudioFiles, err := models.GetLineAudioFiles(r.Context(), req.StoryID, req.LineNumber)
+	existingAudioFiles, err := h.svc.GetLineAudioFiles(r.Context(), req.StoryID, req.LineNumber)
 	if err != nil {
 		h.log.Error("Failed to check existing audio files", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -106,7 +106,7 @@ func (h *Handler) requestAudioUploadURL(w http.ResponseWriter, r *http.Request)
 		strconv.FormatInt(timestamp, 10) + "_" + sanitizedFilename
 
 	// Generate signed upload URL
-	signedURL, err := models.GenerateSignedUploadURL(r.Context(), bucket, filePath)
+	signedURL, err := h.svc.GenerateSignedUploadURL(r.Context(), bucket, filePath)
 	if err != nil {
 		h.log.Error("Failed to generate signed upload URL", "error", err)
 		http.Error(w, "Failed to generate upload URL", http.StatusInternalServerError)
@@ -142,7 +142,7 @@ func (h *Handler) audioDeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Admin authentication check
 	userID, ok := auth.GetUserIDWithOk(r)
-	if !ok || !models.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -154,7 +154,7 @@ func (h *Handler) audioDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete all audio files for the line
-	err := models.DeleteLineAudioFiles(r.Context(), req.StoryID, req.LineNumber)
+	err := h.svc.DeleteLineAudioFiles(r.Context(), req.StoryID, req.LineNumber)
 	if err != nil {
 		h.log.Error("Failed to delete line audio files", "error", err)
 		http.Error(w, "Failed to delete audio files", http.StatusInternalServerError)
@@ -186,7 +186,7 @@ func (h *Handler) confirmAudioUploadHandler(w http.ResponseWriter, r *http.Reque
 
 	// Admin authentication check for confirm step
 	userID, ok := auth.GetUserIDWithOk(r)
-	if !ok || !models.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -199,7 +199,7 @@ func (h *Handler) confirmAudioUploadHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Create audio file record in database
-	audioFile, err := models.CreateAudioFile(r.Context(), req.StoryID, req.LineNumber,
+	audioFile, err := h.svc.CreateAudioFile(r.Context(), req.StoryID, req.LineNumber,
 		req.FilePath, req.FileBucket, req.Label)
 	if err != nil {
 		if err == models.ErrAudioFileExists {
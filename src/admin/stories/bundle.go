@@ -0,0 +1,87 @@
+package stories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+)
+
+// exportStoryHandler handles GET /stories/{id}/export, downloading the story as a .glossias bundle
+func (h *Handler) exportStoryHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	bundle, err := h.svc.ExportStoryBundle(r.Context(), storyID, auth.GetUserID(r))
+	if err == models.ErrNotFound {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to export story", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to export story", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("story-%d%s", storyID, models.StoryBundleExtension)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(bundle)
+}
+
+// importStoryHandler handles POST /stories/import?courseId={id}. The body is a .glossias
+// bundle, which becomes a new story in the course.
+func (h *Handler) importStoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	courseID, err := strconv.Atoi(r.URL.Query().Get("courseId"))
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	userID := auth.GetUserID(r)
+	if !h.svc.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
+		http.Error(w, "Forbidden: not a course admin", http.StatusForbidden)
+		return
+	}
+	if _, err := h.svc.GetCourse(ctx, int32(courseID)); err == models.ErrNotFound {
+		http.Error(w, "Course not found", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		h.log.Error("failed to verify course existence", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	bundle, err := io.ReadAll(http.MaxBytesReader(w, r.Body, models.MaxStoryBundleBytes))
+	if err != nil {
+		http.Error(w, "Story bundle is too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	storyID, err := h.svc.ImportStoryBundle(ctx, courseID, userID, bundle)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidStoryBundle), err == models.ErrUnsupportedBundleVersion:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		h.log.Error("Failed to import story", "error", err, "course_id", courseID)
+		http.Error(w, "Failed to import story", http.StatusInternalServerError)
+		return
+	}
+	h.log.Info("Imported story bundle", "storyID", storyID, "course_id", courseID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": true,
+		"storyId": storyID,
+	})
+}
@@ -49,7 +49,7 @@ func (h *Handler) editStoryHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) handleGetStory(w http.ResponseWriter, r *http.Request, storyID int, userID string) {
 	// Fetch story from database
-	story, err := models.GetStoryData(r.Context(), storyID, userID)
+	story, err := h.svc.GetStoryData(r.Context(), storyID, userID)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Story not found", http.StatusNotFound)
@@ -75,7 +75,7 @@ func (h *Handler) handleUpdateStory(w http.ResponseWriter, r *http.Request, stor
 	}
 
 	// Validate user permissions
-	if !models.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(*story.Metadata.CourseID)) {
+	if !h.svc.IsUserCourseOrSuperAdmin(r.Context(), userID, int32(*story.Metadata.CourseID)) {
 		http.Error(w, "Forbidden: not a course admin", http.StatusForbidden)
 		return
 	}
@@ -93,7 +93,7 @@ func (h *Handler) handleUpdateStory(w http.ResponseWriter, r *http.Request, stor
 	}
 
 	// Update story in database
-	if err := models.SaveStoryData(r.Context(), storyID, &story); err != nil {
+	if err := h.svc.SaveStoryData(r.Context(), storyID, &story); err != nil {
 		h.log.Error("Failed to update story", "error", err, "storyID", storyID)
 		http.Error(w, "Failed to update story", http.StatusInternalServerError)
 		return
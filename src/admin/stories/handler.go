@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"glossias/src/auth"
 	"glossias/src/pkg/models"
 
 	"github.com/gorilla/mux"
@@ -14,11 +15,13 @@ import (
 
 type Handler struct {
 	log *slog.Logger
+	svc *models.Service
 }
 
-func NewHandler(log *slog.Logger) *Handler {
+func NewHandler(log *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
 		log: log,
+		svc: svc,
 	}
 }
 
@@ -38,7 +41,7 @@ func (h *Handler) validateStoryID(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		exists, err = models.StoryExists(r.Context(), int32(storyID))
+		exists, err = h.svc.StoryExists(r.Context(), int32(storyID))
 		if err != nil {
 			h.log.Error("Failed to check if story exists", "error", err, "storyID", storyID)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -54,9 +57,20 @@ func (h *Handler) validateStoryID(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withEditor passes the signed-in admin to the service, which names them in story revisions
+func withEditor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := auth.GetUserIDWithOk(r); ok {
+			r = r.WithContext(models.WithEditor(r.Context(), userID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (h *Handler) RegisterRoutes(r *mux.Router) {
 	// Base: /api/admin/stories
 	stories := r.PathPrefix("/stories").Subrouter()
+	stories.Use(withEditor)
 
 	// Basic hello test route
 	stories.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +80,9 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 
 	// Individual story endpoints
 	stories.HandleFunc("", h.addStoryHandler).Methods("POST", "OPTIONS")
+	stories.HandleFunc("/import", h.importStoryHandler).Methods("POST", "OPTIONS")
 	stories.HandleFunc("/{id:[0-9]+}", h.validateStoryID(h.editStoryHandler)).Methods("GET", "PUT", "DELETE", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/text", h.validateStoryID(h.textHandler)).Methods("PUT", "OPTIONS")
 	stories.HandleFunc("/{id:[0-9]+}/metadata", h.validateStoryID(h.metadataHandler)).Methods("GET", "PUT", "OPTIONS")
 	stories.HandleFunc("/{id:[0-9]+}/annotations", h.validateStoryID(h.annotationsHandler)).
 		Methods("GET", "POST", "PUT", "DELETE", "OPTIONS")
@@ -76,8 +92,59 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 	stories.HandleFunc("/{id:[0-9]+}/translations/line", h.validateStoryID(h.lineTranslationHandler)).Methods("GET", "PUT", "DELETE", "OPTIONS")
 	stories.HandleFunc("/{id:[0-9]+}/translations/lang/{lang}", h.validateStoryID(h.translationsByLanguageHandler)).Methods("GET", "OPTIONS")
 
+	// Target vocabulary endpoints
+	stories.HandleFunc("/{id:[0-9]+}/target-vocab", h.validateStoryID(h.targetVocabHandler)).Methods("GET", "POST", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/target-vocab/{targetId:[0-9]+}", h.validateStoryID(h.targetVocabItemHandler)).Methods("PUT", "DELETE", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/target-vocab/{targetId:[0-9]+}/{asset:audio|image}/upload", h.validateStoryID(h.targetVocabUploadHandler)).Methods("POST", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/target-vocab/{targetId:[0-9]+}/{asset:audio|image}/confirm", h.validateStoryID(h.confirmTargetVocabUploadHandler)).Methods("POST", "OPTIONS")
+
+	// Recall sentence endpoints
+	stories.HandleFunc("/{id:[0-9]+}/recall", h.validateStoryID(h.recallHandler)).Methods("GET", "POST", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/recall/order", h.validateStoryID(h.recallOrderHandler)).Methods("PUT", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/recall/{sentenceId:[0-9]+}", h.validateStoryID(h.recallItemHandler)).Methods("PUT", "DELETE", "OPTIONS")
+
+	// Produce segment endpoints
+	stories.HandleFunc("/{id:[0-9]+}/produce", h.validateStoryID(h.produceHandler)).Methods("GET", "POST", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/produce/explanation", h.validateStoryID(h.produceExplanationHandler)).Methods("GET", "PUT", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/produce/{segmentId:[0-9]+}", h.validateStoryID(h.produceItemHandler)).Methods("PUT", "DELETE", "OPTIONS")
+
+	// Phase flow override
+	stories.HandleFunc("/{id:[0-9]+}/phase-flow", h.validateStoryID(h.phaseFlowHandler)).Methods("GET", "PUT", "OPTIONS")
+
+	// Portable .glossias bundle of the story and its files
+	stories.HandleFunc("/{id:[0-9]+}/export", h.validateStoryID(h.exportStoryHandler)).Methods("GET", "OPTIONS")
+
+	// Revision history
+	stories.HandleFunc("/{id:[0-9]+}/revisions", h.validateStoryID(h.revisionsHandler)).Methods("GET", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/revisions/diff", h.validateStoryID(h.revisionDiffHandler)).Methods("GET", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/revisions/{revision:[0-9]+}", h.validateStoryID(h.revisionHandler)).Methods("GET", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/revisions/{revision:[0-9]+}/rollback", h.validateStoryID(h.rollbackHandler)).Methods("POST", "OPTIONS")
+
+	// Release schedule
+	stories.HandleFunc("/{id:[0-9]+}/schedule", h.validateStoryID(h.scheduleHandler)).Methods("GET", "PUT", "OPTIONS")
+
+	// Score snapshots
+	stories.HandleFunc("/{id:[0-9]+}/scores", h.validateStoryID(h.storyScoresHandler)).Methods("GET", "OPTIONS")
+
+	// Grammar click heatmaps as JSON, HTML or SVG
+	stories.HandleFunc("/{id:[0-9]+}/heatmap", h.validateStoryID(h.grammarHeatmapHandler)).Methods("GET", "OPTIONS")
+
+	// Vocabulary confusions and intentional contrast pairs
+	stories.HandleFunc("/{id:[0-9]+}/vocab-confusions", h.validateStoryID(h.vocabConfusionsHandler)).Methods("GET", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/vocab-contrasts", h.validateStoryID(h.vocabContrastsHandler)).Methods("GET", "POST", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/vocab-contrasts/{pairId:[0-9]+}", h.validateStoryID(h.vocabContrastItemHandler)).Methods("DELETE", "OPTIONS")
+
+	// Vocabulary annotations proposed from the lexicon, accepted or rejected in bulk
+	stories.HandleFunc("/{id:[0-9]+}/vocab-suggestions", h.validateStoryID(h.vocabSuggestionsHandler)).Methods("GET", "POST", "OPTIONS")
+
 	// Audio upload endpoints
 	stories.HandleFunc("/audio/upload", h.audioUploadHandler).Methods("POST", "OPTIONS")
 	stories.HandleFunc("/audio/confirm", h.confirmAudioUploadHandler).Methods("POST", "OPTIONS")
 	stories.HandleFunc("/audio/delete", h.audioDeleteHandler).Methods("DELETE", "OPTIONS")
+
+	// Image upload endpoints
+	stories.HandleFunc("/images/upload", h.imageUploadHandler).Methods("POST", "OPTIONS")
+	stories.HandleFunc("/images/confirm", h.confirmImageUploadHandler).Methods("POST", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/images", h.validateStoryID(h.storyImagesHandler)).Methods("GET", "OPTIONS")
+	stories.HandleFunc("/{id:[0-9]+}/images/{imageId:[0-9]+}", h.validateStoryID(h.imageDeleteHandler)).Methods("DELETE", "OPTIONS")
 }
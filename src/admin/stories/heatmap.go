@@ -0,0 +1,256 @@
+package stories
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"unicode"
+
+	"glossias/src/pkg/models"
+)
+
+// Layout of the SVG heatmap, in pixels
+const (
+	svgCellWidth  = 18
+	svgRowHeight  = 30
+	svgPadding    = 20
+	svgHeader     = 70
+	svgRowColumns = 60 // Long lines wrap at the last space before this many characters
+)
+
+var heatmapHTMLTemplate = template.Must(template.New("heatmap").Funcs(template.FuncMap{
+	"color": heatmapColor,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Grammar heatmap, story {{.StoryID}}</title>
+<style>
+body { font-family: Arial, sans-serif; max-width: 900px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; color: #333; }
+section, .legend { background: #fff; padding: 20px 30px; border-radius: 8px; margin-bottom: 20px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+h1 { font-size: 24px; }
+h2 { font-size: 20px; margin: 0 0 5px; }
+.subtitle { color: #666; font-size: 14px; margin-bottom: 15px; }
+.swatch { display: inline-block; width: 16px; height: 16px; vertical-align: middle; margin: 0 5px 0 15px; border: 1px solid #ccc; }
+.line { font-size: 20px; line-height: 2; margin-bottom: 5px; white-space: pre-wrap; }
+.line span:hover { outline: 2px solid #333; }
+</style>
+</head>
+<body>
+<h1>Grammar click heatmap, story {{.StoryID}}</h1>
+<div class="legend">
+<span class="swatch" style="background-color: rgba(0, 200, 0, 0.7)"></span>Correct clicks
+<span class="swatch" style="background-color: rgba(255, 0, 0, 0.7)"></span>Incorrect clicks
+<span class="swatch" style="background-color: rgba(255, 200, 0, 0.7)"></span>As many correct as incorrect
+<p class="subtitle">Hover over a character to see its clicks. Color intensity is relative to the most clicked character of each grammar point.</p>
+</div>
+{{range .Heatmaps}}
+<section>
+<h2>{{.GrammarPointName}}</h2>
+<div class="subtitle">{{.CorrectClicks}} correct answers, {{.IncorrectClicks}} wrong selections, intensity capped at {{.MaxClicks}} clicks</div>
+{{range .Lines}}<div class="line" dir="{{if .RTL}}rtl{{else}}ltr{{end}}">{{range .Characters}}<span style="background-color: {{color .}}" title="Correct: {{.Correct}}, Incorrect: {{.Incorrect}}">{{.Char}}</span>{{end}}</div>
+{{end}}
+</section>
+{{else}}
+<section><p>This story has no grammar points yet.</p></section>
+{{end}}
+</body>
+</html>
+`))
+
+var heatmapSVGTemplate = template.Must(template.New("heatmap-svg").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" font-family="Arial, sans-serif">
+<rect width="100%" height="100%" fill="#ffffff"/>
+<text x="{{.Padding}}" y="30" font-size="18" fill="#333333">{{.Heatmap.GrammarPointName}}</text>
+<text x="{{.Padding}}" y="52" font-size="12" fill="#666666">Story {{.Heatmap.StoryID}}: {{.Heatmap.CorrectClicks}} correct answers, {{.Heatmap.IncorrectClicks}} wrong selections, intensity capped at {{.Heatmap.MaxClicks}} clicks</text>
+{{range .Cells}}<g><title>Correct: {{.Correct}}, Incorrect: {{.Incorrect}}</title><rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" fill="{{.Fill}}"/><text x="{{.TextX}}" y="{{.TextY}}" font-size="18" text-anchor="middle" fill="#222222">{{.Text}}</text></g>
+{{end}}</svg>
+`))
+
+type heatmapSVGPage struct {
+	Heatmap       *models.GrammarHeatmap
+	Width, Height int
+	Padding       int
+	Cells         []heatmapSVGCell
+}
+
+type heatmapSVGCell struct {
+	X, Y, Width, Height int
+	TextX, TextY        int
+	Fill                string
+	Text                string
+	Correct, Incorrect  int
+}
+
+// grammarHeatmapHandler handles GET /stories/{id}/heatmap?grammar_point_id=&format=json|html|svg.
+// Without a grammar point it covers every grammar point of the story, except as SVG.
+func (h *Handler) grammarHeatmapHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "html" && format != "svg" {
+		http.Error(w, "Invalid format parameter. Must be: json, html or svg", http.StatusBadRequest)
+		return
+	}
+
+	var heatmaps []models.GrammarHeatmap
+	if raw := r.URL.Query().Get("grammar_point_id"); raw != "" {
+		grammarPointID, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid grammar point ID", http.StatusBadRequest)
+			return
+		}
+		heatmap, err := h.svc.GetGrammarHeatmap(r.Context(), storyID, grammarPointID)
+		if err == models.ErrNotFound {
+			http.Error(w, "Grammar point not found in this story", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to build grammar heatmap", "error", err, "storyID", storyID, "grammarPointID", grammarPointID)
+			http.Error(w, "Failed to build grammar heatmap", http.StatusInternalServerError)
+			return
+		}
+		heatmaps = []models.GrammarHeatmap{*heatmap}
+	} else if format == "svg" {
+		http.Error(w, "grammar_point_id is required for SVG heatmaps", http.StatusBadRequest)
+		return
+	} else {
+		var err error
+		heatmaps, err = h.svc.GetStoryGrammarHeatmaps(r.Context(), storyID)
+		if err != nil {
+			h.log.Error("Failed to build grammar heatmaps", "error", err, "storyID", storyID)
+			http.Error(w, "Failed to build grammar heatmaps", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var err error
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Has("grammar_point_id") {
+			err = json.NewEncoder(w).Encode(map[string]any{"heatmap": heatmaps[0]})
+		} else {
+			err = json.NewEncoder(w).Encode(map[string]any{"heatmaps": heatmaps})
+		}
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = heatmapHTMLTemplate.Execute(w, struct {
+			StoryID  int
+			Heatmaps []models.GrammarHeatmap
+		}{storyID, heatmaps})
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		err = heatmapSVGTemplate.Execute(w, layoutHeatmapSVG(&heatmaps[0]))
+	}
+	if err != nil {
+		h.log.Error("Failed to write grammar heatmap", "error", err, "storyID", storyID, "format", format)
+	}
+}
+
+// heatmapColor colors a character like the old heatmap script did: green when mostly
+// clicked correctly, red when mostly clicked wrongly, yellow when tied
+func heatmapColor(character models.HeatmapCharacter) template.CSS {
+	if character.Correct == 0 && character.Incorrect == 0 {
+		return "transparent"
+	}
+	opacity := character.Intensity * 0.7
+	switch {
+	case character.Incorrect > character.Correct:
+		return template.CSS(fmt.Sprintf("rgba(255, 0, 0, %.2f)", opacity))
+	case character.Correct > character.Incorrect:
+		return template.CSS(fmt.Sprintf("rgba(0, 200, 0, %.2f)", opacity))
+	default:
+		return template.CSS(fmt.Sprintf("rgba(255, 200, 0, %.2f)", opacity))
+	}
+}
+
+// layoutHeatmapSVG places one cell per character on a grid. Combining marks share the
+// cell of the letter they follow so pointed Hebrew renders correctly; right-to-left
+// lines fill their rows from the right.
+func layoutHeatmapSVG(heatmap *models.GrammarHeatmap) heatmapSVGPage {
+	page := heatmapSVGPage{
+		Heatmap: heatmap,
+		Width:   2*svgPadding + svgRowColumns*svgCellWidth,
+		Padding: svgPadding,
+	}
+
+	row := 0
+	for _, line := range heatmap.Lines {
+		for _, cells := range wrapHeatmapCells(clusterHeatmapCharacters(line.Characters)) {
+			y := svgHeader + row*svgRowHeight
+			for i, cell := range cells {
+				column := i
+				if line.RTL {
+					column = svgRowColumns - 1 - i
+				}
+				x := svgPadding + column*svgCellWidth
+				page.Cells = append(page.Cells, heatmapSVGCell{
+					X:         x,
+					Y:         y,
+					Width:     svgCellWidth,
+					Height:    svgRowHeight - 4,
+					TextX:     x + svgCellWidth/2,
+					TextY:     y + svgRowHeight - 10,
+					Fill:      string(heatmapColor(cell)),
+					Text:      cell.Char,
+					Correct:   cell.Correct,
+					Incorrect: cell.Incorrect,
+				})
+			}
+			row++
+		}
+		row++ // Blank row between story lines
+	}
+	page.Height = svgHeader + row*svgRowHeight + svgPadding
+	return page
+}
+
+// clusterHeatmapCharacters merges combining marks into the preceding character,
+// keeping the larger click counts of the two
+func clusterHeatmapCharacters(characters []models.HeatmapCharacter) []models.HeatmapCharacter {
+	clusters := make([]models.HeatmapCharacter, 0, len(characters))
+	for _, character := range characters {
+		r := []rune(character.Char)
+		if len(clusters) > 0 && len(r) == 1 && unicode.Is(unicode.Mn, r[0]) {
+			last := &clusters[len(clusters)-1]
+			last.Char += character.Char
+			last.Correct = max(last.Correct, character.Correct)
+			last.Incorrect = max(last.Incorrect, character.Incorrect)
+			last.Intensity = max(last.Intensity, character.Intensity)
+			continue
+		}
+		clusters = append(clusters, character)
+	}
+	return clusters
+}
+
+// wrapHeatmapCells splits a line into rows of at most svgRowColumns cells, breaking
+// after the last space of a row when there is one
+func wrapHeatmapCells(cells []models.HeatmapCharacter) [][]models.HeatmapCharacter {
+	var rows [][]models.HeatmapCharacter
+	for len(cells) > svgRowColumns {
+		end := svgRowColumns
+		for i := svgRowColumns - 1; i > 0; i-- {
+			if cells[i].Char == " " {
+				end = i + 1
+				break
+			}
+		}
+		rows = append(rows, cells[:end])
+		cells = cells[end:]
+	}
+	return append(rows, cells)
+}
@@ -0,0 +1,246 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+type ImageUploadRequest struct {
+	StoryID     int    `json:"storyId"`
+	Label       string `json:"label"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+type ConfirmImageUploadRequest struct {
+	StoryID     int    `json:"storyId"`
+	Label       string `json:"label"`
+	FilePath    string `json:"filePath"`
+	FileBucket  string `json:"fileBucket"`
+	ContentType string `json:"contentType"`
+}
+
+// storyImagesHandler handles GET /stories/{id}/images
+func (h *Handler) storyImagesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(storyID)) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	images, err := h.svc.GetStoryImages(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to get story images", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to get images", http.StatusInternalServerError)
+		return
+	}
+
+	// Signed URLs let the editor preview images; one hour matches the audio endpoints
+	signedURLs, err := h.svc.GetSignedImageURLsForStory(r.Context(), storyID, userID, 3600)
+	if err != nil {
+		h.log.Error("Failed to sign image URLs", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to get images", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"images":     images,
+		"signedUrls": signedURLs,
+	})
+}
+
+func (h *Handler) imageUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ImageUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Admin authentication check
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Validate request data
+	if req.StoryID <= 0 || req.FileName == "" {
+		http.Error(w, "Invalid Request: Data is outside bounds. Ensure all data is present and try again.", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateImageLabel(req.Label); err != nil {
+		h.writeImageError(w, err)
+		return
+	}
+	if err := models.ValidateImageUpload(req.FileName, req.ContentType, req.Size); err != nil {
+		h.writeImageError(w, err)
+		return
+	}
+
+	// Check if story exists
+	exists, err := h.svc.StoryExists(r.Context(), int32(req.StoryID))
+	if err != nil {
+		h.log.Error("Failed to check story existence", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	// Generate file path: stories/{storyID}/image_{label}_{timestamp}_{filename}
+	timestamp := time.Now().Unix()
+	// Sanitize filename to prevent path traversal
+	sanitizedFilename := strings.ReplaceAll(req.FileName, "/", "")
+	sanitizedFilename = strings.ReplaceAll(sanitizedFilename, "\\", "")
+	sanitizedFilename = strings.ReplaceAll(sanitizedFilename, "..", "")
+	filePath := imagePathPrefix(req.StoryID, req.Label) +
+		strconv.FormatInt(timestamp, 10) + "_" + sanitizedFilename
+
+	// Generate signed upload URL
+	signedURL, err := h.svc.GenerateSignedUploadURL(r.Context(), models.ImageBucket, filePath)
+	if err != nil {
+		h.log.Error("Failed to generate signed upload URL", "error", err)
+		http.Error(w, "Failed to generate upload URL", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AudioUploadResponse{
+		UploadURL:  signedURL,
+		FilePath:   filePath,
+		FileBucket: models.ImageBucket,
+	})
+}
+
+func (h *Handler) confirmImageUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ConfirmImageUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Admin authentication check for confirm step
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(req.StoryID)) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := models.ValidateImageLabel(req.Label); err != nil {
+		h.writeImageError(w, err)
+		return
+	}
+	// Verify file path matches expected pattern to prevent path manipulation
+	prefix := imagePathPrefix(req.StoryID, req.Label)
+	if !strings.HasPrefix(req.FilePath, prefix) || strings.ContainsAny(req.FilePath[len(prefix):], `/\`) ||
+		req.FileBucket != models.ImageBucket {
+		http.Error(w, "Invalid file path", http.StatusBadRequest)
+		return
+	}
+	// The content type is re-checked so the record never disagrees with the upload rules
+	if err := models.ValidateImageUpload(req.FilePath, req.ContentType, 1); err != nil {
+		h.writeImageError(w, err)
+		return
+	}
+
+	image, err := h.svc.CreateStoryImage(r.Context(), req.StoryID, req.FilePath, req.FileBucket, req.Label, req.ContentType)
+	if err != nil {
+		h.writeImageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(image)
+}
+
+// imageDeleteHandler handles DELETE /stories/{id}/images/{imageId}
+func (h *Handler) imageDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+	storyID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+	imageID, err := strconv.Atoi(vars["imageId"])
+	if err != nil {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(storyID)) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	image, err := h.svc.GetStoryImage(r.Context(), imageID)
+	if err != nil || image.StoryID != storyID {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.svc.DeleteStoryImage(r.Context(), imageID); err != nil {
+		h.log.Error("Failed to delete image", "error", err, "imageID", imageID)
+		http.Error(w, "Failed to delete image", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeImageError maps image validation errors to HTTP responses
+func (h *Handler) writeImageError(w http.ResponseWriter, err error) {
+	switch err {
+	case models.ErrInvalidImageType:
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case models.ErrImageTooLarge:
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case models.ErrImageNotUploaded, models.ErrInvalidImageLabel:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Image operation failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func imagePathPrefix(storyID int, label string) string {
+	return "stories/" + strconv.Itoa(storyID) + "/image_" + label + "_"
+}
@@ -35,7 +35,7 @@ func (h *Handler) metadataHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleGetMetadata(w http.ResponseWriter, r *http.Request, storyID int) {
-	story, err := models.GetStoryData(r.Context(), storyID, auth.GetUserID(r))
+	story, err := h.svc.GetStoryData(r.Context(), storyID, auth.GetUserID(r))
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Story not found", http.StatusNotFound)
@@ -60,7 +60,7 @@ func (h *Handler) handleUpdateMetadata(w http.ResponseWriter, r *http.Request, s
 		return
 	}
 
-	if err := models.EditStoryMetadata(r.Context(), storyID, metadata); err != nil {
+	if err := h.svc.EditStoryMetadata(r.Context(), storyID, metadata); err != nil {
 		h.log.Error("Failed to update metadata", "error", err, "storyID", storyID)
 		http.Error(w, "Failed to update metadata", http.StatusInternalServerError)
 		return
@@ -0,0 +1,57 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"glossias/src/pkg/models"
+)
+
+type PhaseFlowRequest struct {
+	Phases []string `json:"phases"` // Empty removes the story's override
+}
+
+// phaseFlowHandler handles GET/PUT /stories/{id}/phase-flow
+func (h *Handler) phaseFlowHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	var flow *models.PhaseFlow
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		flow, err = h.svc.GetStoryPhaseFlow(r.Context(), storyID)
+	case http.MethodPut:
+		var req PhaseFlowRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		flow, err = h.svc.SetStoryPhaseFlow(r.Context(), storyID, req.Phases)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch err {
+	case nil:
+	case models.ErrNotFound:
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	case models.ErrInvalidPhaseFlow:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		h.log.Error("Phase flow operation failed", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"phases":    flow.Phases,
+		"source":    flow.Source,
+		"available": models.KnownPhases,
+	})
+}
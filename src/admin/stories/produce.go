@@ -0,0 +1,175 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"glossias/src/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+type ProduceSegmentRequest struct {
+	StartLine      int    `json:"startLine"` // 1-indexed, inclusive
+	EndLine        int    `json:"endLine"`
+	EnglishPrompt  string `json:"englishPrompt"`
+	ReferenceText  string `json:"referenceText"`
+	GrammarPointID int    `json:"grammarPointId"`
+}
+
+type ProduceExplanationRequest struct {
+	Explanation string `json:"explanation"` // Empty removes the explanation
+}
+
+// produceHandler handles GET/POST /stories/{id}/produce
+func (h *Handler) produceHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getProduceSegments(w, r, storyID)
+	case http.MethodPost:
+		h.createProduceSegment(w, r, storyID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// produceItemHandler handles PUT/DELETE /stories/{id}/produce/{segmentId}
+func (h *Handler) produceItemHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	segmentID, err := strconv.Atoi(mux.Vars(r)["segmentId"])
+	if err != nil {
+		http.Error(w, "Invalid produce segment ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		h.updateProduceSegment(w, r, storyID, segmentID)
+	case http.MethodDelete:
+		if err := h.svc.DeleteProduceSegment(r.Context(), storyID, segmentID); err != nil {
+			h.writeProduceError(w, err, storyID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// produceExplanationHandler handles GET/PUT /stories/{id}/produce/explanation
+func (h *Handler) produceExplanationHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	var explanation string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		explanation, err = h.svc.GetProduceExplanation(r.Context(), storyID)
+	case http.MethodPut:
+		var req ProduceExplanationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		explanation, err = h.svc.SetProduceExplanation(r.Context(), storyID, req.Explanation)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		h.writeProduceError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"explanation": explanation,
+	})
+}
+
+func (h *Handler) getProduceSegments(w http.ResponseWriter, r *http.Request, storyID int) {
+	segments, err := h.svc.GetStoryProduceSegments(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to get produce segments", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to get produce segments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"segments": segments,
+		"max":      models.MaxProduceSegments,
+	})
+}
+
+func (h *Handler) createProduceSegment(w http.ResponseWriter, r *http.Request, storyID int) {
+	var req ProduceSegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	segment, err := h.svc.CreateProduceSegment(r.Context(), storyID, req.toModel())
+	if err != nil {
+		h.writeProduceError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(segment)
+}
+
+func (h *Handler) updateProduceSegment(w http.ResponseWriter, r *http.Request, storyID, segmentID int) {
+	var req ProduceSegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	segment, err := h.svc.UpdateProduceSegment(r.Context(), storyID, segmentID, req.toModel())
+	if err != nil {
+		h.writeProduceError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(segment)
+}
+
+func (req ProduceSegmentRequest) toModel() models.ProduceSegment {
+	return models.ProduceSegment{
+		StartLine:      req.StartLine,
+		EndLine:        req.EndLine,
+		EnglishPrompt:  req.EnglishPrompt,
+		ReferenceText:  req.ReferenceText,
+		GrammarPointID: req.GrammarPointID,
+	}
+}
+
+// writeProduceError maps model errors to HTTP responses
+func (h *Handler) writeProduceError(w http.ResponseWriter, err error, storyID int) {
+	switch err {
+	case models.ErrNotFound:
+		http.Error(w, "Produce segment not found", http.StatusNotFound)
+	case models.ErrEmptyProduceSegment, models.ErrInvalidProduceLines, models.ErrInvalidProduceGrammarPoint:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case models.ErrProduceSegmentLimit:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Produce operation failed", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,159 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"glossias/src/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+type RecallSentenceRequest struct {
+	Text          string `json:"text"`
+	TargetVocabID int    `json:"targetVocabId"`
+	ImageID       int    `json:"imageId"`
+}
+
+type RecallOrderRequest struct {
+	SentenceIDs []int `json:"sentenceIds"` // Every sentence of the story, first to last
+}
+
+// recallHandler handles GET/POST /stories/{id}/recall
+func (h *Handler) recallHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getRecallSentences(w, r, storyID)
+	case http.MethodPost:
+		h.createRecallSentence(w, r, storyID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// recallItemHandler handles PUT/DELETE /stories/{id}/recall/{sentenceId}
+func (h *Handler) recallItemHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	sentenceID, err := strconv.Atoi(mux.Vars(r)["sentenceId"])
+	if err != nil {
+		http.Error(w, "Invalid recall sentence ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		h.updateRecallSentence(w, r, storyID, sentenceID)
+	case http.MethodDelete:
+		if err := h.svc.DeleteRecallSentence(r.Context(), storyID, sentenceID); err != nil {
+			h.writeRecallError(w, err, storyID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// recallOrderHandler handles PUT /stories/{id}/recall/order
+func (h *Handler) recallOrderHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RecallOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sentences, err := h.svc.ReorderRecallSentences(r.Context(), storyID, req.SentenceIDs)
+	if err != nil {
+		h.writeRecallError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"sentences": sentences,
+	})
+}
+
+func (h *Handler) getRecallSentences(w http.ResponseWriter, r *http.Request, storyID int) {
+	sentences, err := h.svc.GetStoryRecallSentences(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to get recall sentences", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to get recall sentences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"sentences": sentences,
+		"max":       models.MaxRecallSentences,
+	})
+}
+
+func (h *Handler) createRecallSentence(w http.ResponseWriter, r *http.Request, storyID int) {
+	var req RecallSentenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sentence, err := h.svc.CreateRecallSentence(r.Context(), storyID, req.Text, req.TargetVocabID, req.ImageID)
+	if err != nil {
+		h.writeRecallError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sentence)
+}
+
+func (h *Handler) updateRecallSentence(w http.ResponseWriter, r *http.Request, storyID, sentenceID int) {
+	var req RecallSentenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sentence, err := h.svc.UpdateRecallSentence(r.Context(), storyID, sentenceID, req.Text, req.TargetVocabID, req.ImageID)
+	if err != nil {
+		h.writeRecallError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sentence)
+}
+
+// writeRecallError maps model errors to HTTP responses
+func (h *Handler) writeRecallError(w http.ResponseWriter, err error, storyID int) {
+	switch err {
+	case models.ErrNotFound:
+		http.Error(w, "Recall sentence not found", http.StatusNotFound)
+	case models.ErrEmptyRecallSentence, models.ErrInvalidRecallTarget, models.ErrInvalidRecallImage, models.ErrInvalidRecallOrder:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case models.ErrRecallSentenceLimit:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Recall sentence operation failed", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,118 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"glossias/src/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+// revisionsHandler handles GET /stories/{id}/revisions, newest first
+func (h *Handler) revisionsHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.svc.GetStoryRevisions(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to list story revisions", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"revisions": revisions})
+}
+
+// revisionHandler handles GET /stories/{id}/revisions/{revision}, including the story snapshot
+func (h *Handler) revisionHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.svc.GetStoryRevision(r.Context(), storyID, number)
+	if err == models.ErrNotFound {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to get story revision", "error", err, "storyID", storyID, "revision", number)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"revision": revision})
+}
+
+// revisionDiffHandler handles GET /stories/{id}/revisions/diff?from={n}&to={m}
+func (h *Handler) revisionDiffHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	from, fromErr := strconv.Atoi(r.URL.Query().Get("from"))
+	to, toErr := strconv.Atoi(r.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		http.Error(w, "from and to must be revision numbers", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.svc.DiffStoryRevisions(r.Context(), storyID, from, to)
+	if err == models.ErrNotFound {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to diff story revisions", "error", err, "storyID", storyID, "from", from, "to", to)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"diff": diff})
+}
+
+// rollbackHandler handles POST /stories/{id}/revisions/{revision}/rollback
+func (h *Handler) rollbackHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	switch err := h.svc.RollbackStory(r.Context(), storyID, number); err {
+	case nil:
+	case models.ErrNotFound:
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	case models.ErrRollbackOrphansAnswers:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		h.log.Error("Failed to roll back story", "error", err, "storyID", storyID, "revision", number)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.log.Info("Rolled back story", "storyID", storyID, "revision", number)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"success":  true,
+		"revision": number,
+	})
+}
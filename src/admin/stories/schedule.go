@@ -0,0 +1,47 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"glossias/src/pkg/models"
+)
+
+// scheduleHandler handles GET/PUT /stories/{id}/schedule. PUT replaces the whole
+// schedule; omitted times are cleared.
+func (h *Handler) scheduleHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	var schedule *models.StorySchedule
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		schedule, err = h.svc.GetStorySchedule(r.Context(), storyID)
+	case http.MethodPut:
+		var req models.StorySchedule
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		schedule, err = h.svc.SetStorySchedule(r.Context(), storyID, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch err {
+	case nil:
+	case models.ErrInvalidStorySchedule:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		h.log.Error("Schedule operation failed", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"schedule": schedule})
+}
@@ -0,0 +1,41 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"glossias/src/pkg/models"
+)
+
+// storyScoresHandler handles GET /stories/{id}/scores. It returns the latest score
+// snapshot of every student, or the full history of one student with ?user_id=.
+func (h *Handler) storyScoresHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	var scores []models.StoryScore
+	var err error
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		scores, err = h.svc.GetStoryScoreHistory(r.Context(), userID, storyID)
+	} else {
+		scores, err = h.svc.GetStoryLatestScores(r.Context(), storyID)
+	}
+	if err != nil {
+		h.log.Error("Failed to get story scores", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to get story scores", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"scores":         scores,
+		"formulaVersion": models.ScoreFormulaVersion,
+	})
+}
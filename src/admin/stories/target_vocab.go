@@ -0,0 +1,291 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	targetVocabAudio = "audio"
+	targetVocabImage = "image"
+)
+
+type TargetVocabRequest struct {
+	LexicalForm string `json:"lexicalForm"`
+}
+
+type TargetVocabUploadRequest struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"` // Required for images
+	Size        int64  `json:"size"`        // Required for images
+}
+
+type TargetVocabConfirmRequest struct {
+	FilePath   string `json:"filePath"`
+	FileBucket string `json:"fileBucket"`
+}
+
+// targetVocabHandler handles GET/POST /stories/{id}/target-vocab
+func (h *Handler) targetVocabHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getTargetVocab(w, r, storyID)
+	case http.MethodPost:
+		h.createTargetVocab(w, r, storyID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// targetVocabItemHandler handles PUT/DELETE /stories/{id}/target-vocab/{targetId}
+func (h *Handler) targetVocabItemHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	targetID, err := strconv.Atoi(mux.Vars(r)["targetId"])
+	if err != nil {
+		http.Error(w, "Invalid target vocabulary ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		h.updateTargetVocab(w, r, storyID, targetID)
+	case http.MethodDelete:
+		h.deleteTargetVocab(w, r, storyID, targetID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// editableStoryID parses the story ID and checks the caller may edit the story
+func (h *Handler) editableStoryID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	storyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return 0, false
+	}
+
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok || !h.svc.CanUserEditStory(r.Context(), userID, int32(storyID)) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	return storyID, true
+}
+
+func (h *Handler) getTargetVocab(w http.ResponseWriter, r *http.Request, storyID int) {
+	words, err := h.svc.GetStoryTargetVocab(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to get target vocabulary", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to get target vocabulary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"targetVocab": words,
+		"max":         models.MaxTargetVocab,
+	})
+}
+
+func (h *Handler) createTargetVocab(w http.ResponseWriter, r *http.Request, storyID int) {
+	var req TargetVocabRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	word, err := h.svc.CreateTargetVocab(r.Context(), storyID, req.LexicalForm)
+	if err != nil {
+		h.writeTargetVocabError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(word)
+}
+
+func (h *Handler) updateTargetVocab(w http.ResponseWriter, r *http.Request, storyID, targetID int) {
+	var req TargetVocabRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	word, err := h.svc.UpdateTargetVocabLexicalForm(r.Context(), storyID, targetID, req.LexicalForm)
+	if err != nil {
+		h.writeTargetVocabError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(word)
+}
+
+func (h *Handler) deleteTargetVocab(w http.ResponseWriter, r *http.Request, storyID, targetID int) {
+	if err := h.svc.DeleteTargetVocab(r.Context(), storyID, targetID); err != nil {
+		h.writeTargetVocabError(w, err, storyID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// targetVocabUploadHandler handles POST /stories/{id}/target-vocab/{targetId}/{asset}/upload
+func (h *Handler) targetVocabUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	targetID, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		http.Error(w, "Invalid target vocabulary ID", http.StatusBadRequest)
+		return
+	}
+	asset := vars["asset"]
+
+	var req TargetVocabUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.FileName == "" {
+		http.Error(w, "Invalid Request: fileName is required", http.StatusBadRequest)
+		return
+	}
+	if asset == targetVocabImage {
+		if err := models.ValidateImageUpload(req.FileName, req.ContentType, req.Size); err != nil {
+			h.writeImageError(w, err)
+			return
+		}
+	}
+
+	// The word must exist before assets can be attached to it
+	if _, err := h.svc.GetTargetVocab(r.Context(), storyID, targetID); err != nil {
+		h.writeTargetVocabError(w, err, storyID)
+		return
+	}
+
+	// Generate file path: stories/{storyID}/target_{targetID}_{asset}_{timestamp}_{filename}
+	timestamp := time.Now().Unix()
+	// Sanitize filename to prevent path traversal
+	sanitizedFilename := strings.ReplaceAll(req.FileName, "/", "")
+	sanitizedFilename = strings.ReplaceAll(sanitizedFilename, "\\", "")
+	sanitizedFilename = strings.ReplaceAll(sanitizedFilename, "..", "")
+	filePath := targetVocabPathPrefix(storyID, targetID, asset) +
+		strconv.FormatInt(timestamp, 10) + "_" + sanitizedFilename
+
+	fileBucket := targetVocabBucket(asset)
+	signedURL, err := h.svc.GenerateSignedUploadURL(r.Context(), fileBucket, filePath)
+	if err != nil {
+		h.log.Error("Failed to generate signed upload URL", "error", err)
+		http.Error(w, "Failed to generate upload URL", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AudioUploadResponse{
+		UploadURL:  signedURL,
+		FilePath:   filePath,
+		FileBucket: fileBucket,
+	})
+}
+
+// confirmTargetVocabUploadHandler handles POST /stories/{id}/target-vocab/{targetId}/{asset}/confirm
+func (h *Handler) confirmTargetVocabUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	targetID, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		http.Error(w, "Invalid target vocabulary ID", http.StatusBadRequest)
+		return
+	}
+	asset := vars["asset"]
+
+	var req TargetVocabConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Verify file path and bucket match what the upload step issued to prevent path manipulation
+	if !strings.HasPrefix(req.FilePath, targetVocabPathPrefix(storyID, targetID, asset)) ||
+		req.FileBucket != targetVocabBucket(asset) {
+		http.Error(w, "Invalid file path", http.StatusBadRequest)
+		return
+	}
+
+	var word *models.TargetVocab
+	if asset == targetVocabAudio {
+		word, err = h.svc.SetTargetVocabAudio(r.Context(), storyID, targetID, req.FilePath, req.FileBucket)
+	} else {
+		if _, err := h.svc.VerifyUploadedImage(req.FileBucket, req.FilePath); err != nil {
+			h.writeImageError(w, err)
+			return
+		}
+		word, err = h.svc.SetTargetVocabImage(r.Context(), storyID, targetID, req.FilePath, req.FileBucket)
+	}
+	if err != nil {
+		h.writeTargetVocabError(w, err, storyID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(word)
+}
+
+// writeTargetVocabError maps model errors to HTTP responses
+func (h *Handler) writeTargetVocabError(w http.ResponseWriter, err error, storyID int) {
+	switch err {
+	case models.ErrNotFound:
+		http.Error(w, "Target vocabulary word not found", http.StatusNotFound)
+	case models.ErrEmptyLexicalForm, models.ErrTargetVocabTooFewOccurrences:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case models.ErrTargetVocabExists, models.ErrTargetVocabLimit:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Target vocabulary operation failed", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func targetVocabPathPrefix(storyID, targetID int, asset string) string {
+	return "stories/" + strconv.Itoa(storyID) + "/target_" + strconv.Itoa(targetID) + "_" + asset + "_"
+}
+
+func targetVocabBucket(asset string) string {
+	if asset == targetVocabImage {
+		return models.ImageBucket
+	}
+	return bucket
+}
@@ -0,0 +1,57 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"glossias/src/pkg/models"
+)
+
+// textHandler handles PUT /stories/{id}/text. Lines are matched to the stored ones, so
+// annotations survive the edit; the response reports the ones that could not be kept.
+// Deleting vocabulary that students have answered takes "dropAnswered": true.
+func (h *Handler) textHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Lines []struct {
+			LineNumber int    `json:"lineNumber"`
+			Text       string `json:"text"`
+		} `json:"lines"`
+		DropAnswered bool `json:"dropAnswered"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	lines := make([]models.StoryLine, len(req.Lines))
+	for i, line := range req.Lines {
+		if strings.TrimSpace(line.Text) == "" {
+			http.Error(w, "Lines cannot be empty", http.StatusBadRequest)
+			return
+		}
+		lines[i] = models.StoryLine{LineNumber: line.LineNumber, Text: line.Text}
+	}
+
+	report, err := h.svc.EditStoryText(r.Context(), storyID, lines, req.DropAnswered)
+	switch err {
+	case nil:
+	case models.ErrInvalidLineNumber:
+		http.Error(w, "Lines must be numbered from 1 in order", http.StatusBadRequest)
+		return
+	case models.ErrEditOrphansAnswers:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		h.log.Error("Failed to edit story text", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"success": true, "report": report})
+}
@@ -89,7 +89,7 @@ func (h *Handler) getLineTranslation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	translation, err := models.GetLineTranslation(r.Context(), storyID, lineNumber, languageCode)
+	translation, err := h.svc.GetLineTranslation(r.Context(), storyID, lineNumber, languageCode)
 	if err != nil {
 		if err == models.ErrNotFound {
 			http.Error(w, "Translation not found", http.StatusNotFound)
@@ -118,7 +118,7 @@ func (h *Handler) upsertLineTranslation(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = models.UpsertLineTranslation(r.Context(), storyID, req.LineNumber, req.LanguageCode, req.Translation)
+	err = h.svc.UpsertLineTranslation(r.Context(), storyID, req.LineNumber, req.LanguageCode, req.Translation)
 	if err != nil {
 		h.log.Error("Failed to upsert line translation", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -138,7 +138,7 @@ func (h *Handler) getAllTranslations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	translations, err := models.GetAllTranslationsForStory(r.Context(), storyID)
+	translations, err := h.svc.GetAllTranslationsForStory(r.Context(), storyID)
 	if err != nil {
 		h.log.Error("Failed to get story translations", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -163,7 +163,7 @@ func (h *Handler) getTranslationsByLanguage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	translations, err := models.GetTranslationsByLanguage(r.Context(), storyID, languageCode)
+	translations, err := h.svc.GetTranslationsByLanguage(r.Context(), storyID, languageCode)
 	if err != nil {
 		h.log.Error("Failed to get translations by language", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -194,7 +194,7 @@ func (h *Handler) deleteLineTranslation(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = models.DeleteLineTranslation(r.Context(), storyID, lineNumber, languageCode)
+	err = h.svc.DeleteLineTranslation(r.Context(), storyID, lineNumber, languageCode)
 	if err != nil {
 		h.log.Error("Failed to delete line translation", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -214,7 +214,7 @@ func (h *Handler) deleteStoryTranslations(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = models.DeleteStoryTranslations(r.Context(), storyID)
+	err = h.svc.DeleteStoryTranslations(r.Context(), storyID)
 	if err != nil {
 		h.log.Error("Failed to delete story translations", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -240,14 +240,14 @@ func (h *Handler) bulkUpdateTranslations(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Update each translation
+	translations := make(map[int]string, len(req.Translations))
 	for _, translation := range req.Translations {
-		err = models.UpsertLineTranslation(r.Context(), storyID, translation.LineNumber, req.LanguageCode, translation.Translation)
-		if err != nil {
-			h.log.Error("Failed to upsert line translation", "error", err, "storyID", storyID, "lineNumber", translation.LineNumber)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-			return
-		}
+		translations[translation.LineNumber] = translation.Translation
+	}
+	if err := h.svc.UpsertLineTranslations(r.Context(), storyID, req.LanguageCode, translations); err != nil {
+		h.log.Error("Failed to upsert line translations", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
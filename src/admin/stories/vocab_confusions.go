@@ -0,0 +1,113 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"glossias/src/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+type VocabContrastRequest struct {
+	LexicalForm  string `json:"lexicalForm"`
+	ContrastForm string `json:"contrastForm"`
+}
+
+// vocabConfusionsHandler handles GET /stories/{id}/vocab-confusions
+func (h *Handler) vocabConfusionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	matrix, err := h.svc.GetStoryVocabConfusions(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to build vocab confusion matrix", "error", err, "storyID", storyID)
+		http.Error(w, "Failed to build vocab confusion matrix", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matrix)
+}
+
+// vocabContrastsHandler handles GET/POST /stories/{id}/vocab-contrasts
+func (h *Handler) vocabContrastsHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		contrasts, err := h.svc.GetStoryVocabContrasts(r.Context(), storyID)
+		if err != nil {
+			h.writeVocabContrastError(w, err, storyID)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"contrasts": contrasts,
+		})
+	case http.MethodPost:
+		var req VocabContrastRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		pair, err := h.svc.CreateVocabContrast(r.Context(), storyID, req.LexicalForm, req.ContrastForm)
+		if err != nil {
+			h.writeVocabContrastError(w, err, storyID)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(pair)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// vocabContrastItemHandler handles DELETE /stories/{id}/vocab-contrasts/{pairId}
+func (h *Handler) vocabContrastItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+	pairID, err := strconv.Atoi(mux.Vars(r)["pairId"])
+	if err != nil {
+		http.Error(w, "Invalid contrast pair ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeleteVocabContrast(r.Context(), storyID, pairID); err != nil {
+		h.writeVocabContrastError(w, err, storyID)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeVocabContrastError maps model errors to HTTP responses
+func (h *Handler) writeVocabContrastError(w http.ResponseWriter, err error, storyID int) {
+	switch err {
+	case models.ErrNotFound:
+		http.Error(w, "Contrast pair not found", http.StatusNotFound)
+	case models.ErrInvalidVocabContrast, models.ErrVocabContrastNotInStory:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Vocab contrast operation failed", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
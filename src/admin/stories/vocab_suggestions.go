@@ -0,0 +1,68 @@
+package stories
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"glossias/src/pkg/models"
+)
+
+type VocabSuggestionReview struct {
+	Accept []models.VocabSuggestion `json:"accept"`
+	Reject []models.VocabSuggestion `json:"reject"`
+}
+
+// vocabSuggestionsHandler handles GET/POST /stories/{id}/vocab-suggestions. GET proposes
+// vocabulary annotations from the lexicon; POST accepts and rejects them in bulk.
+func (h *Handler) vocabSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, ok := h.editableStoryID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		suggestions, err := h.svc.SuggestVocabulary(r.Context(), storyID)
+		if err != nil {
+			h.writeVocabSuggestionError(w, err, storyID)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"suggestions": suggestions,
+		})
+	case http.MethodPost:
+		var req VocabSuggestionReview
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		added, err := h.svc.ReviewVocabSuggestions(r.Context(), storyID, req.Accept, req.Reject)
+		if err != nil {
+			h.writeVocabSuggestionError(w, err, storyID)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"success":  true,
+			"added":    added,
+			"rejected": len(req.Reject),
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) writeVocabSuggestionError(w http.ResponseWriter, err error, storyID int) {
+	switch err {
+	case models.ErrNotFound:
+		http.Error(w, "Story not found", http.StatusNotFound)
+	case models.ErrSuggestionMismatch:
+		http.Error(w, "Suggestion does not match the story text; fetch the suggestions again", http.StatusBadRequest)
+	case models.ErrAmbiguousStoryLanguage:
+		http.Error(w, "Story has descriptions in more than one language; keep one to get suggestions", http.StatusConflict)
+	default:
+		h.log.Error("Failed to handle vocabulary suggestions", "error", err, "storyID", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"encoding/json"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// trash lists the deleted stories and courses the admin may restore
+func (h *Handler) trash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	trash, err := h.svc.GetTrash(r.Context(), userID)
+	if err != nil {
+		h.log.Error("failed to list trash", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trash)
+}
+
+// restoreStory takes a story out of the trash (admins of its course)
+func (h *Handler) restoreStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	storyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+	if !h.svc.CanUserRestoreStory(r.Context(), userID, int32(storyID)) {
+		http.Error(w, "Forbidden: not a course admin", http.StatusForbidden)
+		return
+	}
+
+	switch err := h.svc.RestoreStory(r.Context(), storyID); err {
+	case nil:
+	case models.ErrNotFound:
+		http.Error(w, "Story not found in the trash", http.StatusNotFound)
+		return
+	case models.ErrCourseInTrash:
+		http.Error(w, "The story's course is in the trash; restore the course first", http.StatusConflict)
+		return
+	default:
+		h.log.Error("failed to restore story", "error", err, "story_id", storyID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("story restored from trash", "user_id", userID, "story_id", storyID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// restoreCourse takes a course out of the trash with the stories deleted along with it
+func (h *Handler) restoreCourse(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireSuperAdmin(w, r, "course restore")
+	if !ok {
+		return
+	}
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.RestoreCourse(r.Context(), int32(courseID)); err != nil {
+		if err == models.ErrNotFound {
+			http.Error(w, "Course not found in the trash", http.StatusNotFound)
+			return
+		}
+		if err == models.ErrCourseNumberTaken {
+			http.Error(w, "Another course now uses this course's number; change that course's number first", http.StatusConflict)
+			return
+		}
+		h.log.Error("failed to restore course", "error", err, "course_id", courseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("course restored from trash", "user_id", userID, "course_id", courseID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
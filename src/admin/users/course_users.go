@@ -14,11 +14,13 @@ import (
 
 type Handler struct {
 	log *slog.Logger
+	svc *models.Service
 }
 
-func NewHandler(log *slog.Logger) *Handler {
+func NewHandler(log *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
 		log: log,
+		svc: svc,
 	}
 }
 
@@ -74,13 +76,13 @@ func (h *Handler) GetUsersForCourse(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Check if user is admin of this course or super admin
-	if !models.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
+	if !h.svc.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
 
 	// Get users for course
-	courseUsers, err := models.GetUsersForCourse(ctx, courseID)
+	courseUsers, err := h.svc.GetUsersForCourse(ctx, courseID)
 	if err != nil {
 		h.log.Error("failed to get users for course", "error", err, "course_id", courseID)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -91,9 +93,9 @@ func (h *Handler) GetUsersForCourse(w http.ResponseWriter, r *http.Request) {
 	users := make([]UserResponse, len(courseUsers))
 	for i, user := range courseUsers {
 		role := "student"
-		if models.IsUserSuperAdmin(r.Context(), user.UserID) {
+		if h.svc.IsUserSuperAdmin(r.Context(), user.UserID) {
 			role = "super_admin"
-		} else if models.IsUserOnlyCourseAdmin(r.Context(), user.UserID, int32(courseID)) {
+		} else if h.svc.IsUserOnlyCourseAdmin(r.Context(), user.UserID, int32(courseID)) {
 			role = "course_admin"
 		}
 
@@ -131,7 +133,7 @@ func (h *Handler) AddUsersToCourse(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Check if user is admin of this course or super admin
-	if !models.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
+	if !h.svc.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
@@ -148,7 +150,7 @@ func (h *Handler) AddUsersToCourse(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add users to course by emails
-	notFound, err := models.MassImportUsersToCourse(ctx, courseID, req.Emails)
+	notFound, err := h.svc.MassImportUsersToCourse(ctx, courseID, req.Emails)
 	if err != nil {
 		// Check if it's a some users not found error
 		if err == models.ErrSomeUsersNotFound {
@@ -202,7 +204,7 @@ func (h *Handler) SetUserStatusInCourse(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 
 	// Check if user is admin of this course or super admin
-	if !models.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
+	if !h.svc.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
@@ -224,7 +226,7 @@ func (h *Handler) SetUserStatusInCourse(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Bulk update user status in course
-	err = models.BulkUpdateUserStatusInCourse(ctx, courseID, req.UserIDs, status)
+	err = h.svc.BulkUpdateUserStatusInCourse(ctx, courseID, req.UserIDs, status)
 	if err != nil {
 		h.log.Error("failed to update user status in course", "error", err, "course_id", courseID)
 
@@ -264,13 +266,13 @@ func (h *Handler) RemoveUserFromCourse(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Check if user is admin of this course or super admin
-	if !models.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
+	if !h.svc.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID)) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
 
 	// Remove user from course
-	err = models.RemoveUserFromCourse(ctx, courseID, targetUserID)
+	err = h.svc.RemoveUserFromCourse(ctx, courseID, targetUserID)
 	if err != nil {
 		h.log.Error("failed to remove user from course", "error", err, "user_id", targetUserID, "course_id", courseID)
 
@@ -32,7 +32,7 @@ func canMakeDBHealthRequest(ip string) bool {
 }
 
 // DBHealthHandler checks database connectivity
-func DBHealthHandler(logger *slog.Logger) http.HandlerFunc {
+func DBHealthHandler(logger *slog.Logger, svc *models.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		clientIP := r.Header.Get("X-Forwarded-For")
 		if clientIP == "" {
@@ -60,7 +60,7 @@ func DBHealthHandler(logger *slog.Logger) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		err := models.TestDBConnection(ctx)
+		err := svc.TestDBConnection(ctx)
 		if err != nil {
 			logger.Error("database health check failed", "error", err)
 			w.Header().Set("Content-Type", "application/json")
@@ -16,12 +16,14 @@ const (
 // Handler contains shared dependencies for all API handlers
 type Handler struct {
 	log *slog.Logger
+	svc *models.Service
 }
 
-// NewHandler creates a new API handler with the given logger
-func NewHandler(logger *slog.Logger) *Handler {
+// NewHandler creates a new API handler with the given logger and model service
+func NewHandler(logger *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
 		log: logger,
+		svc: svc,
 	}
 }
 
@@ -35,6 +37,22 @@ func (h *Handler) sendError(w http.ResponseWriter, message string, status int) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// storyClosed reports whether a story stopped accepting answers from the user, after
+// sending the error response if so
+func (h *Handler) storyClosed(w http.ResponseWriter, r *http.Request, userID string, storyID int) bool {
+	err := h.svc.CheckStoryOpen(r.Context(), userID, storyID)
+	if err == nil {
+		return false
+	}
+	if err == models.ErrStoryClosed {
+		h.sendError(w, err.Error(), http.StatusForbidden)
+	} else {
+		h.log.Error("Failed to check story schedule", "error", err, "storyID", storyID)
+		h.sendError(w, "Failed to check story schedule", http.StatusInternalServerError)
+	}
+	return true
+}
+
 // sendValidationError sends a validation error with expected answer counts
 func (h *Handler) sendValidationError(w http.ResponseWriter, message string, expectedAnswers map[int]int) {
 	w.WriteHeader(http.StatusBadRequest)
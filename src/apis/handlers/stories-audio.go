@@ -20,7 +20,7 @@ func (h *Handler) GetAudioPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	story, err := models.GetStoryData(r.Context(), id, auth.GetUserID(r))
+	story, err := h.svc.GetStoryData(r.Context(), id, auth.GetUserID(r))
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -88,7 +88,7 @@ func (h *Handler) GetSignedAudioURLs(w http.ResponseWriter, r *http.Request) {
 	label := r.URL.Query().Get("label")
 
 	// Generate signed URLs (expires in 4 hours)
-	signedURLs, err := models.GetSignedAudioURLsForStory(r.Context(), id, auth.GetUserID(r), label, expiresInSeconds)
+	signedURLs, err := h.svc.GetSignedAudioURLsForStory(r.Context(), id, auth.GetUserID(r), label, expiresInSeconds)
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story or audio files not found.", http.StatusNotFound)
 		return
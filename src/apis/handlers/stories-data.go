@@ -21,7 +21,7 @@ func (h *Handler) GetStoryMetadata(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get story data from database
-	story, err := models.GetStoryData(r.Context(), id, auth.GetUserID(r))
+	story, err := h.svc.GetStoryData(r.Context(), id, auth.GetUserID(r))
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -32,7 +32,7 @@ func (h *Handler) GetGrammarPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	story, err := models.GetStoryData(r.Context(), id, auth.GetUserID(r))
+	story, err := h.svc.GetStoryData(r.Context(), id, auth.GetUserID(r))
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -44,7 +44,7 @@ func (h *Handler) GetGrammarPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get grammar points for the story
-	grammarPoints, err := models.GetStoryGrammarPoints(r.Context(), id)
+	grammarPoints, err := h.svc.GetStoryGrammarPoints(r.Context(), id)
 	if err != nil {
 		h.log.Error("Failed to fetch grammar points", "error", err)
 		h.sendError(w, "Failed to fetch grammar points", http.StatusInternalServerError)
@@ -98,7 +98,7 @@ func (h *Handler) GetGrammarPage(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		// Get user's correct answers for this grammar point
-		correctAnswers, err := models.GetUserGrammarScoresByGrammarPoint(r.Context(), userID, id, selectedPoint.ID)
+		correctAnswers, err := h.svc.GetUserGrammarScoresByGrammarPoint(r.Context(), userID, id, selectedPoint.ID)
 		if err != nil {
 			h.log.Warn("Failed to get user grammar scores", "error", err)
 		} else {
@@ -117,7 +117,7 @@ func (h *Handler) GetGrammarPage(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Get user's incorrect answers for this grammar point
-		incorrectAnswers, err := models.GetUserGrammarIncorrectAnswers(r.Context(), userID, id, selectedPoint.ID)
+		incorrectAnswers, err := h.svc.GetUserGrammarIncorrectAnswers(r.Context(), userID, id, selectedPoint.ID)
 		if err != nil {
 			h.log.Warn("Failed to get user incorrect answers", "error", err)
 		} else {
@@ -144,7 +144,7 @@ func (h *Handler) GetGrammarPage(w http.ResponseWriter, r *http.Request) {
 
 		// Check if all instances found and get next grammar point
 		if len(foundInstances) >= instancesCount {
-			nextGrammarPointID, _ = findNextGrammarPoint(r.Context(), id, selectedPoint.ID)
+			nextGrammarPointID, _ = h.findNextGrammarPoint(r.Context(), id, selectedPoint.ID)
 		}
 	}
 
@@ -212,8 +212,8 @@ func findNextGrammarPointFromSlice(grammarPoints []models.GrammarPoint, currentG
 }
 
 // findNextGrammarPoint finds the next grammar point ID in sequence (legacy function)
-func findNextGrammarPoint(ctx context.Context, storyID, currentGrammarPointID int) (*int, error) {
-	grammarPoints, err := models.GetStoryGrammarPoints(ctx, storyID)
+func (h *Handler) findNextGrammarPoint(ctx context.Context, storyID, currentGrammarPointID int) (*int, error) {
+	grammarPoints, err := h.svc.GetStoryGrammarPoints(ctx, storyID)
 	if err != nil {
 		return nil, err
 	}
@@ -245,12 +245,15 @@ func (h *Handler) CheckGrammar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get story data - cached, fastest call
-	story, err := models.GetStoryData(ctx, id, userID)
+	story, err := h.svc.GetStoryData(ctx, id, userID)
 	if err != nil {
 		h.log.Error("Failed to fetch story data", "error", err, "storyID", id)
 		h.sendError(w, "Failed to fetch story data", http.StatusInternalServerError)
 		return
 	}
+	if h.storyClosed(w, r, userID, id) {
+		return
+	}
 
 	// Build grammar map for this grammar point - O(n) on story lines
 	grammarItemsMap, totalInstances := buildCorrectGrammarMapOptimized(story, req.GrammarPointID)
@@ -275,12 +278,12 @@ func (h *Handler) CheckGrammar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save the score - cannot avoid this DB call
-	if err := models.SaveSingleGrammarSelection(ctx, userID, id, req.GrammarPointID, req.LineNumber, req.Position, isCorrect); err != nil {
+	if err := h.svc.SaveSingleGrammarSelection(ctx, userID, id, req.GrammarPointID, req.LineNumber, req.Position, isCorrect); err != nil {
 		h.log.Error("Failed to save grammar selection", "error", err, "userID", userID, "storyID", id)
 	}
 
 	// Get current found count and check completion - single DB call
-	foundCount, err := models.CountFoundGrammarInstances(ctx, userID, id, req.GrammarPointID)
+	foundCount, err := h.svc.CountFoundGrammarInstances(ctx, userID, id, req.GrammarPointID)
 	if err != nil {
 		h.log.Error("Failed to count found instances", "error", err, "userID", userID, "storyID", id)
 		// Continue without count rather than failing
@@ -292,7 +295,7 @@ func (h *Handler) CheckGrammar(w http.ResponseWriter, r *http.Request) {
 	// Find next grammar point if all complete - optimized to avoid extra DB call
 	var nextGrammarPointID *int
 	if allComplete {
-		grammarPoints, err := models.GetStoryGrammarPoints(ctx, id)
+		grammarPoints, err := h.svc.GetStoryGrammarPoints(ctx, id)
 		if err != nil {
 			h.log.Error("Failed to get grammar points for next lookup", "error", err, "storyID", id)
 		} else {
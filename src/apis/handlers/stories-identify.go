@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetIdentifyPage returns JSON data for the Identify phase: lines with target words
+// marked, per-line narration, the target words' audio, and their pictures as options
+// with opaque IDs. Each load starts a new attempt with new option IDs.
+func (h *Handler) GetIdentifyPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	storyID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(storyID)
+	if err != nil {
+		h.sendError(w, "Invalid story ID format", http.StatusBadRequest)
+		return
+	}
+	userID := auth.GetUserID(r)
+	if userID == "" {
+		h.sendError(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	story, err := h.svc.GetStoryData(ctx, id, userID)
+	if err == models.ErrNotFound {
+		h.sendError(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch story data", "error", err)
+		h.sendError(w, "Failed to fetch story data", http.StatusInternalServerError)
+		return
+	}
+
+	targets, err := h.svc.GetSignedStoryTargetVocab(ctx, id, userID, expiresInSeconds)
+	if err != nil {
+		h.log.Error("Failed to fetch target vocabulary", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch target vocabulary", http.StatusInternalServerError)
+		return
+	}
+
+	identified, err := h.svc.GetUserIdentifyScores(ctx, userID, id)
+	if err != nil {
+		h.log.Error("Failed to fetch identify scores", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch identify scores", http.StatusInternalServerError)
+		return
+	}
+
+	audioURLs, err := h.svc.GetSignedAudioURLsForStory(ctx, id, userID, "complete", expiresInSeconds)
+	if err != nil {
+		h.log.Error("Failed to generate signed audio URLs", "error", err, "storyID", id)
+		h.sendError(w, "Failed to generate signed URLs", http.StatusInternalServerError)
+		return
+	}
+
+	attempt, err := h.svc.StartIdentifyAttempt(ctx, userID, id, targets)
+	if err != nil {
+		h.log.Error("Failed to start identify attempt", "error", err, "storyID", id)
+		h.sendError(w, "Failed to start identify attempt", http.StatusInternalServerError)
+		return
+	}
+
+	targetIDs := make(map[string]int, len(targets))
+	words := make([]types.IdentifyTarget, 0, len(targets))
+	for _, target := range targets {
+		targetIDs[target.LexicalForm] = target.ID
+		words = append(words, types.IdentifyTarget{
+			ID:          target.ID,
+			LexicalForm: target.LexicalForm,
+			AudioURL:    target.AudioURL,
+		})
+	}
+	options := make([]types.IdentifyOption, 0, len(attempt))
+	for _, option := range attempt {
+		options = append(options, types.IdentifyOption{
+			ID:       option.ID,
+			ImageURL: option.ImageURL,
+		})
+	}
+	// The pictures must not follow the order of the target words
+	rand.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+
+	lines := h.generateIdentifyLines(*story, targetIDs, identified)
+	for i := range lines {
+		lines[i].SignedAudioURL = audioURLs[story.Content.Lines[i].LineNumber]
+	}
+
+	data := types.IdentifyPageData{
+		PageData: types.PageData{
+			StoryID:    storyID,
+			StoryTitle: story.Metadata.Title["en"],
+			Language:   story.Metadata.Language,
+		},
+		Lines:   lines,
+		Targets: words,
+		Options: options,
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// generateIdentifyLines splits lines into text and "target" segments. targetIDs maps
+// lexical form to target vocabulary ID; identified is map[lineNumber]map[targetID]bool.
+func (h *Handler) generateIdentifyLines(story models.Story, targetIDs map[string]int, identified map[int]map[int]bool) []types.IdentifyLine {
+	lines := make([]types.IdentifyLine, len(story.Content.Lines))
+
+	for i, line := range story.Content.Lines {
+		segments := []types.TextSegment{}
+		runes := []rune(line.Text)
+		lastEnd := 0
+		hasTarget := false
+
+		// Sort vocab words by position
+		slices.SortFunc(line.Vocabulary, h.sortVocab)
+
+		for _, vocab := range line.Vocabulary {
+			targetID, isTarget := targetIDs[vocab.LexicalForm]
+			start, end := vocab.Position[0], vocab.Position[1]
+			// Overlapping or out-of-range annotations stay plain text
+			if !isTarget || start < lastEnd || end > len(runes) || start >= end {
+				continue
+			}
+
+			if start > lastEnd {
+				segments = append(segments, types.TextSegment{
+					Text: string(runes[lastEnd:start]),
+					Type: "text",
+				})
+			}
+			segments = append(segments, types.TextSegment{
+				Text:      string(runes[start:end]),
+				Type:      "target",
+				TargetID:  targetID,
+				Completed: identified[line.LineNumber][targetID],
+			})
+			hasTarget = true
+			lastEnd = end
+		}
+		if lastEnd < len(runes) {
+			segments = append(segments, types.TextSegment{
+				Text: string(runes[lastEnd:]),
+				Type: "text",
+			})
+		}
+
+		lines[i] = types.IdentifyLine{
+			Text:      segments,
+			HasTarget: hasTarget,
+		}
+	}
+
+	return lines
+}
+
+// CheckIdentify handles a picture pick in the Identify quiz
+func (h *Handler) CheckIdentify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req types.CheckIdentifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body in CheckIdentify", "error", err, "ip", r.RemoteAddr)
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	storyID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(storyID)
+	if err != nil {
+		h.log.Warn("Invalid story ID in CheckIdentify", "storyID", storyID, "ip", r.RemoteAddr)
+		h.sendError(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	userID := auth.GetUserID(r)
+	if userID == "" {
+		h.sendError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	story, err := h.svc.GetStoryData(ctx, id, userID)
+	if err == models.ErrNotFound {
+		h.sendError(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch story in CheckIdentify", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch story", http.StatusInternalServerError)
+		return
+	}
+
+	if h.storyClosed(w, r, userID, id) {
+		return
+	}
+
+	// Validate line number
+	if req.LineIndex < 0 || req.LineIndex >= len(story.Content.Lines) {
+		h.log.Warn("Invalid line number in CheckIdentify", "lineIndex", req.LineIndex, "maxLines", len(story.Content.Lines), "ip", r.RemoteAddr)
+		h.sendError(w, "Invalid line index", http.StatusBadRequest)
+		return
+	}
+	line := story.Content.Lines[req.LineIndex]
+
+	targets, err := h.svc.GetStoryTargetVocab(ctx, id)
+	if err != nil {
+		h.log.Error("Failed to fetch target vocabulary in CheckIdentify", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch target vocabulary", http.StatusInternalServerError)
+		return
+	}
+	targetIDs := make(map[string]int, len(targets))
+	for _, target := range targets {
+		targetIDs[target.LexicalForm] = target.ID
+	}
+	selectedID, err := h.svc.ResolveIdentifyOption(ctx, userID, id, req.SelectedOption)
+	if err == models.ErrIdentifyOptionExpired {
+		h.sendError(w, "This picture is no longer offered, reload the page", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to resolve identify option", "error", err, "storyID", id)
+		h.sendError(w, "Failed to check identify answer", http.StatusInternalServerError)
+		return
+	}
+	isTarget := func(targetID int) bool {
+		return slices.ContainsFunc(targets, func(t models.TargetVocab) bool { return t.ID == targetID })
+	}
+	if !isTarget(req.TargetID) || !isTarget(selectedID) {
+		h.sendError(w, "Invalid target word", http.StatusBadRequest)
+		return
+	}
+
+	// The target word must actually appear on the line that prompted the quiz
+	if !slices.ContainsFunc(line.Vocabulary, func(v models.VocabularyItem) bool {
+		return targetIDs[v.LexicalForm] == req.TargetID
+	}) {
+		h.sendError(w, "Target word does not appear on this line", http.StatusBadRequest)
+		return
+	}
+
+	isCorrect := selectedID == req.TargetID
+	if err := h.svc.SaveIdentifyScore(ctx, userID, id, line.LineNumber, req.TargetID, isCorrect, selectedID); err != nil {
+		h.log.Error("Failed to save identify score", "error", err, "userID", userID, "storyID", id, "line", line.LineNumber)
+		h.sendError(w, "Failed to save identify score", http.StatusInternalServerError)
+		return
+	}
+
+	// The line is complete once every target word on it has been identified
+	lineComplete := false
+	if isCorrect {
+		identified, err := h.svc.GetUserIdentifyScores(ctx, userID, id)
+		if err != nil {
+			h.log.Error("Failed to fetch identify scores", "error", err, "userID", userID, "storyID", id)
+		} else {
+			lineComplete = !slices.ContainsFunc(line.Vocabulary, func(v models.VocabularyItem) bool {
+				targetID, ok := targetIDs[v.LexicalForm]
+				return ok && !identified[line.LineNumber][targetID]
+			})
+		}
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data: types.CheckIdentifyResponse{
+			Correct:      isCorrect,
+			LineComplete: lineComplete,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
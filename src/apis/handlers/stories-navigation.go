@@ -19,20 +19,32 @@ type PageType struct {
 }
 
 var (
-	PageTypeVideo     = PageType{Path: "video", DisplayName: "Video"}
-	PageTypeVocab     = PageType{Path: "vocab", DisplayName: "Vocabulary"}
-	PageTypeTranslate = PageType{Path: "translate", DisplayName: "Translation"}
-	PageTypeGrammar   = PageType{Path: "grammar", DisplayName: "Grammar"}
-	PageTypeScore     = PageType{Path: "score", DisplayName: "Score"}
+	PageTypeVideo     = PageType{Path: models.PhaseVideo, DisplayName: "Video"}
+	PageTypeVocab     = PageType{Path: models.PhaseVocab, DisplayName: "Vocabulary"}
+	PageTypeIdentify  = PageType{Path: models.PhaseIdentify, DisplayName: "Identify"}
+	PageTypeTranslate = PageType{Path: models.PhaseTranslate, DisplayName: "Translation"}
+	PageTypeGrammar   = PageType{Path: models.PhaseGrammar, DisplayName: "Grammar"}
+	PageTypeRecall    = PageType{Path: models.PhaseRecall, DisplayName: "Recall"}
+	PageTypeProduce   = PageType{Path: models.PhaseProduce, DisplayName: "Produce"}
+	PageTypeScore     = PageType{Path: models.PhaseScore, DisplayName: "Score"}
 )
 
-// Default page order for MVP
-var defaultPageOrder = []PageType{
-	PageTypeVideo,
-	PageTypeVocab,
-	PageTypeTranslate,
-	PageTypeGrammar,
-	PageTypeScore,
+// pageTypes maps a phase name to its page
+var pageTypes = map[string]PageType{
+	PageTypeVideo.Path:     PageTypeVideo,
+	PageTypeVocab.Path:     PageTypeVocab,
+	PageTypeIdentify.Path:  PageTypeIdentify,
+	PageTypeTranslate.Path: PageTypeTranslate,
+	PageTypeGrammar.Path:   PageTypeGrammar,
+	PageTypeRecall.Path:    PageTypeRecall,
+	PageTypeProduce.Path:   PageTypeProduce,
+	PageTypeScore.Path:     PageTypeScore,
+}
+
+// pageStatus says whether a story has anything to show on a page and whether the user has finished it
+type pageStatus struct {
+	HasContent bool
+	Completed  bool
 }
 
 const minTimeSeconds = 0 // Minimum time in seconds to consider a page "completed" (unused)
@@ -73,7 +85,7 @@ func (h *Handler) Navigate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate story exists
-	_, err = models.GetStoryData(r.Context(), storyID, userID)
+	story, err := h.svc.GetStoryData(r.Context(), storyID, userID)
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -84,8 +96,22 @@ func (h *Handler) Navigate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get completion status for all pages
-	completionStatus, err := h.getPageCompletionStatus(r.Context(), userID, int32(storyID))
+	// The story's own flow, else its course's flow, else the default
+	flow, err := h.svc.GetStoryPhaseFlow(r.Context(), storyID)
+	if err != nil {
+		h.log.Error("Failed to get phase flow", "error", err, "storyID", storyID)
+		h.sendError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	pageOrder := make([]PageType, 0, len(flow.Phases))
+	for _, phase := range flow.Phases {
+		if page, ok := pageTypes[phase]; ok {
+			pageOrder = append(pageOrder, page)
+		}
+	}
+
+	// Get content and completion status for the pages of the flow
+	status, err := h.getPageStatus(r.Context(), userID, story, pageOrder)
 	if err != nil {
 		h.log.Error("Failed to get completion status", "error", err, "storyID", storyID, "userID", userID)
 		h.sendError(w, "Internal server error", http.StatusInternalServerError)
@@ -93,7 +119,7 @@ func (h *Handler) Navigate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Determine next page
-	nextPage := h.determineNextPage(req.CurrentPage, completionStatus)
+	nextPage := determineNextPage(pageOrder, req.CurrentPage, status)
 
 	response := types.APIResponse{
 		Success: true,
@@ -109,44 +135,137 @@ func (h *Handler) Navigate(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// getPageCompletionStatus returns completion status for all page types
-func (h *Handler) getPageCompletionStatus(ctx context.Context, userID string, storyID int32) (map[PageType]bool, error) {
-	status := make(map[PageType]bool)
+// getPageStatus returns content and completion status for each page of pageOrder
+func (h *Handler) getPageStatus(ctx context.Context, userID string, story *models.Story, pageOrder []PageType) (map[PageType]pageStatus, error) {
+	storyID := int32(story.Metadata.StoryID)
+	status := make(map[PageType]pageStatus, len(pageOrder))
+
+	for _, page := range pageOrder {
+		var st pageStatus
+		var err error
+		switch page {
+		case PageTypeVideo:
+			// Video is never considered "complete" for skipping purposes
+			st.HasContent = story.Metadata.VideoURL != ""
+		case PageTypeVocab:
+			st.HasContent = hasVocab(*story)
+			st.Completed, err = h.isVocabCompleted(ctx, userID, storyID)
+		case PageTypeIdentify:
+			st, err = h.getIdentifyStatus(ctx, userID, *story)
+		case PageTypeTranslate:
+			st.HasContent = len(story.Content.Lines) > 0
+			st.Completed, err = h.isTranslateCompleted(ctx, userID, storyID)
+		case PageTypeGrammar:
+			st.HasContent = hasGrammar(*story)
+			st.Completed, err = h.isGrammarCompleted(ctx, userID, storyID)
+		case PageTypeRecall:
+			st, err = h.getRecallStatus(ctx, userID, storyID)
+		case PageTypeProduce:
+			st, err = h.getProduceStatus(ctx, userID, int(storyID))
+		case PageTypeScore:
+			// Score is never considered "complete" for skipping
+			st.HasContent = true
+		}
+		if err != nil {
+			return nil, err
+		}
+		status[page] = st
+	}
 
-	// Video is never considered "complete" for skipping purposes
-	status[PageTypeVideo] = false
+	return status, nil
+}
 
-	// Get vocab completion status
-	vocabComplete, err := h.isVocabCompleted(ctx, userID, storyID)
-	if err != nil {
-		return nil, err
+func hasVocab(story models.Story) bool {
+	for _, line := range story.Content.Lines {
+		if len(line.Vocabulary) > 0 {
+			return true
+		}
 	}
-	status[PageTypeVocab] = vocabComplete
+	return false
+}
 
-	// Get grammar completion status
-	grammarComplete, err := h.isGrammarCompleted(ctx, userID, storyID)
+func hasGrammar(story models.Story) bool {
+	for _, line := range story.Content.Lines {
+		if len(line.Grammar) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getIdentifyStatus checks the story has target words in its text and whether the user identified all of them
+func (h *Handler) getIdentifyStatus(ctx context.Context, userID string, story models.Story) (pageStatus, error) {
+	targets, err := h.svc.GetStoryTargetVocab(ctx, story.Metadata.StoryID)
+	if err != nil || len(targets) == 0 {
+		return pageStatus{}, err
+	}
+	targetIDs := make(map[string]int, len(targets))
+	for _, target := range targets {
+		targetIDs[target.LexicalForm] = target.ID
+	}
+
+	identified, err := h.svc.GetUserIdentifyScores(ctx, userID, story.Metadata.StoryID)
 	if err != nil {
-		return nil, err
+		return pageStatus{}, err
+	}
+
+	st := pageStatus{Completed: true}
+	for _, line := range story.Content.Lines {
+		for _, vocab := range line.Vocabulary {
+			targetID, ok := targetIDs[vocab.LexicalForm]
+			if !ok {
+				continue
+			}
+			st.HasContent = true
+			if !identified[line.LineNumber][targetID] {
+				st.Completed = false
+			}
+		}
+	}
+	return st, nil
+}
+
+// getRecallStatus checks the story has recall sentences and whether the user placed all of them
+func (h *Handler) getRecallStatus(ctx context.Context, userID string, storyID int32) (pageStatus, error) {
+	sentences, err := h.svc.GetStoryRecallSentences(ctx, int(storyID))
+	if err != nil || len(sentences) == 0 {
+		return pageStatus{}, err
 	}
-	status[PageTypeGrammar] = grammarComplete
 
-	// Get translate completion status
-	translateComplete, err := h.isTranslateCompleted(ctx, userID, storyID)
+	summary, err := h.svc.GetUserStoryRecallSummary(ctx, userID, storyID)
 	if err != nil {
-		return nil, err
+		return pageStatus{}, err
 	}
-	status[PageTypeTranslate] = translateComplete
+	return pageStatus{
+		HasContent: true,
+		Completed:  summary.CorrectCount >= int64(len(sentences)),
+	}, nil
+}
 
-	// Score is never considered "complete" for skipping
-	status[PageTypeScore] = false
+// getProduceStatus checks the story has produce segments and whether the user answered all of them
+func (h *Handler) getProduceStatus(ctx context.Context, userID string, storyID int) (pageStatus, error) {
+	segments, err := h.svc.GetStoryProduceSegments(ctx, storyID)
+	if err != nil || len(segments) == 0 {
+		return pageStatus{}, err
+	}
 
-	return status, nil
+	submissions, err := h.svc.GetUserProduceSubmissions(ctx, userID, storyID)
+	if err != nil {
+		return pageStatus{}, err
+	}
+	st := pageStatus{HasContent: true, Completed: true}
+	for _, segment := range segments {
+		if _, ok := submissions[segment.ID]; !ok {
+			st.Completed = false
+		}
+	}
+	return st, nil
 }
 
 // isVocabCompleted checks if user has completed vocab (correct answers = total vocab items)
 func (h *Handler) isVocabCompleted(ctx context.Context, userID string, storyID int32) (bool, error) {
 	// Get total vocabulary items in story
-	totalVocabItems, err := models.CountStoryVocabItems(ctx, storyID)
+	totalVocabItems, err := h.svc.CountStoryVocabItems(ctx, storyID)
 	if err != nil {
 		return false, err
 	}
@@ -156,7 +275,7 @@ func (h *Handler) isVocabCompleted(ctx context.Context, userID string, storyID i
 	}
 
 	// Check user's correct answers
-	vocabSummary, err := models.GetUserStoryVocabSummary(ctx, userID, storyID)
+	vocabSummary, err := h.svc.GetUserStoryVocabSummary(ctx, userID, storyID)
 	if err != nil {
 		return false, err
 	}
@@ -167,7 +286,7 @@ func (h *Handler) isVocabCompleted(ctx context.Context, userID string, storyID i
 // isGrammarCompleted checks if user has completed grammar (correct answers == total instances AND sufficient time)
 func (h *Handler) isGrammarCompleted(ctx context.Context, userID string, storyID int32) (bool, error) {
 	// Get total grammar instances in story
-	story, err := models.GetStoryData(ctx, int(storyID), userID)
+	story, err := h.svc.GetStoryData(ctx, int(storyID), userID)
 	if err != nil {
 		return false, err
 	}
@@ -183,7 +302,7 @@ func (h *Handler) isGrammarCompleted(ctx context.Context, userID string, storyID
 	}
 
 	// Check if user has found all instances
-	grammarSummary, err := models.GetUserStoryGrammarSummary(ctx, userID, storyID)
+	grammarSummary, err := h.svc.GetUserStoryGrammarSummary(ctx, userID, storyID)
 	if err != nil {
 		return false, err
 	}
@@ -193,7 +312,7 @@ func (h *Handler) isGrammarCompleted(ctx context.Context, userID string, storyID
 	}
 
 	// Check time spent
-	timeData, err := models.GetUserStoryTimeTracking(ctx, userID, storyID)
+	timeData, err := h.svc.GetUserStoryTimeTracking(ctx, userID, storyID)
 	if err != nil {
 		return false, err
 	}
@@ -203,13 +322,13 @@ func (h *Handler) isGrammarCompleted(ctx context.Context, userID string, storyID
 
 // isTranslateCompleted checks if user has spent sufficient time on translation
 func (h *Handler) isTranslateCompleted(ctx context.Context, userID string, storyID int32) (bool, error) {
-	timeData, err := models.GetUserStoryTimeTracking(ctx, userID, storyID)
+	timeData, err := h.svc.GetUserStoryTimeTracking(ctx, userID, storyID)
 	if err != nil {
 		return false, err
 	}
 
 	// Check if translation request exists for this user and story
-	exists, err := models.TranslationRequestExists(ctx, userID, int(storyID))
+	exists, err := h.svc.TranslationRequestExists(ctx, userID, int(storyID))
 	if err != nil {
 		return false, err
 	}
@@ -217,28 +336,28 @@ func (h *Handler) isTranslateCompleted(ctx context.Context, userID string, story
 	return timeData.TranslationTimeSeconds >= minTimeSeconds && exists, nil
 }
 
-// determineNextPage finds the next page to visit based on current page and completion status
-func (h *Handler) determineNextPage(currentPage string, completionStatus map[PageType]bool) PageType {
+// determineNextPage finds the next page to visit based on the page order, current page and page status.
+// Pages without content are skipped.
+func determineNextPage(pageOrder []PageType, currentPage string, status map[PageType]pageStatus) PageType {
 	// Find current page index in the order
 	currentIndex := -1
-	for i, page := range defaultPageOrder {
+	for i, page := range pageOrder {
 		if page.Path == currentPage {
 			currentIndex = i
 			break
 		}
 	}
 
-	// If current page not found in order, start from beginning
-	if currentIndex == -1 {
-		return PageTypeVideo
-	}
-
-	// Starting from next page, find first incomplete page
-	for i := currentIndex + 1; i < len(defaultPageOrder); i++ {
-		page := defaultPageOrder[i]
+	// Starting from next page (or the beginning if the current page is not in the order),
+	// find the first page with content that is incomplete
+	for i := currentIndex + 1; i < len(pageOrder); i++ {
+		page := pageOrder[i]
+		if !status[page].HasContent {
+			continue
+		}
 
-		// Video is always visited, others check completion status
-		if page.Path == "video" || !completionStatus[page] {
+		// Video and score are always visited, others check completion status
+		if page == PageTypeVideo || page == PageTypeScore || !status[page].Completed {
 			return page
 		}
 	}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ProduceHandler handles GET/POST /api/stories/{id}/produce
+func (h *Handler) ProduceHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getProducePage(w, r)
+	case http.MethodPost:
+		h.submitProduce(w, r)
+	default:
+		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getProducePage returns the Produce segments with the lines around them
+func (h *Handler) getProducePage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	storyID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(storyID)
+	if err != nil {
+		h.sendError(w, "Invalid story ID format", http.StatusBadRequest)
+		return
+	}
+	userID := auth.GetUserID(r)
+	if userID == "" {
+		h.sendError(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	story, err := h.svc.GetStoryData(ctx, id, userID)
+	if err == models.ErrNotFound {
+		h.sendError(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch story data", "error", err)
+		h.sendError(w, "Failed to fetch story data", http.StatusInternalServerError)
+		return
+	}
+
+	segments, err := h.svc.GetStoryProduceSegments(ctx, id)
+	if err != nil {
+		h.log.Error("Failed to fetch produce segments", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch produce segments", http.StatusInternalServerError)
+		return
+	}
+
+	explanation, err := h.svc.GetProduceExplanation(ctx, id)
+	if err != nil {
+		h.log.Error("Failed to fetch produce explanation", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch produce explanation", http.StatusInternalServerError)
+		return
+	}
+
+	submissions, err := h.svc.GetUserProduceSubmissions(ctx, userID, id)
+	if err != nil {
+		h.log.Error("Failed to fetch produce submissions", "error", err, "storyID", id, "userID", userID)
+		h.sendError(w, "Failed to fetch produce submissions", http.StatusInternalServerError)
+		return
+	}
+
+	data := types.ProducePageData{
+		PageData: types.PageData{
+			StoryID:    storyID,
+			StoryTitle: story.Metadata.Title["en"],
+			Language:   story.Metadata.Language,
+		},
+		Explanation: explanation,
+		Segments:    h.generateProduceSegments(*story, segments, submissions),
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// generateProduceSegments builds the student view of each segment: the lines just
+// outside it as context, and the reference text only once the student has answered
+func (h *Handler) generateProduceSegments(story models.Story, segments []models.ProduceSegment, submissions map[int]models.ProduceSubmission) []types.ProduceSegment {
+	lineText := make(map[int]string, len(story.Content.Lines))
+	for _, line := range story.Content.Lines {
+		lineText[line.LineNumber] = line.Text
+	}
+	grammarPoints := make(map[int]string, len(story.Metadata.GrammarPoints))
+	for _, gp := range story.Metadata.GrammarPoints {
+		grammarPoints[gp.ID] = gp.Name
+	}
+
+	result := make([]types.ProduceSegment, len(segments))
+	for i, segment := range segments {
+		result[i] = types.ProduceSegment{
+			ID:            segment.ID,
+			EnglishPrompt: segment.EnglishPrompt,
+			GrammarPoint:  grammarPoints[segment.GrammarPointID],
+			ContextBefore: lineText[segment.StartLine-1],
+			ContextAfter:  lineText[segment.EndLine+1],
+		}
+		if submission, ok := submissions[segment.ID]; ok {
+			result[i].StudentText = submission.StudentText
+			result[i].ReferenceText = segment.ReferenceText
+			result[i].AIScore = submission.AIScore
+			result[i].AIFeedback = submission.AIFeedback
+			result[i].GradingStatus = submission.GradingStatus
+		}
+	}
+	return result
+}
+
+// submitProduce saves and grades a student's answer to a segment. Resubmitting replaces the earlier answer.
+func (h *Handler) submitProduce(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req types.ProduceSubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body in submitProduce", "error", err, "ip", r.RemoteAddr)
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	storyID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(storyID)
+	if err != nil {
+		h.sendError(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	userID := auth.GetUserID(r)
+	if userID == "" {
+		h.sendError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	// Access check
+	if _, err := h.svc.GetStoryData(ctx, id, userID); err == models.ErrNotFound {
+		h.sendError(w, "Story not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error("Failed to fetch story in submitProduce", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch story", http.StatusInternalServerError)
+		return
+	}
+
+	if h.storyClosed(w, r, userID, id) {
+		return
+	}
+
+	submission, err := h.svc.SaveProduceSubmission(ctx, userID, id, req.SegmentID, req.StudentText)
+	switch err {
+	case nil:
+	case models.ErrEmptyProduceSubmission, models.ErrProduceSegmentWrongStory:
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		h.log.Error("Failed to save produce submission", "error", err, "userID", userID, "storyID", id, "segmentID", req.SegmentID)
+		h.sendError(w, "Failed to save submission", http.StatusInternalServerError)
+		return
+	}
+
+	segment, err := h.svc.GetProduceSegment(ctx, id, req.SegmentID)
+	if err != nil {
+		h.log.Error("Failed to fetch produce segment", "error", err, "storyID", id, "segmentID", req.SegmentID)
+		h.sendError(w, "Failed to fetch produce segment", http.StatusInternalServerError)
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data: types.ProduceSegment{
+			ID:            segment.ID,
+			EnglishPrompt: segment.EnglishPrompt,
+			StudentText:   submission.StudentText,
+			ReferenceText: segment.ReferenceText,
+			AIScore:       submission.AIScore,
+			AIFeedback:    submission.AIFeedback,
+			GradingStatus: submission.GradingStatus,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"glossias/src/apis/types"
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetRecallPage returns the Recall sentences in a shuffled order, without their positions
+func (h *Handler) GetRecallPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	storyID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(storyID)
+	if err != nil {
+		h.sendError(w, "Invalid story ID format", http.StatusBadRequest)
+		return
+	}
+	userID := auth.GetUserID(r)
+	if userID == "" {
+		h.sendError(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	story, err := h.svc.GetStoryData(ctx, id, userID)
+	if err == models.ErrNotFound {
+		h.sendError(w, "Story not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch story data", "error", err)
+		h.sendError(w, "Failed to fetch story data", http.StatusInternalServerError)
+		return
+	}
+
+	sentences, err := h.svc.GetStoryRecallSentences(ctx, id)
+	if err != nil {
+		h.log.Error("Failed to fetch recall sentences", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch recall sentences", http.StatusInternalServerError)
+		return
+	}
+
+	imageURLs, err := h.svc.GetSignedImageURLsForStory(ctx, id, userID, expiresInSeconds)
+	if err != nil {
+		h.log.Error("Failed to generate signed image URLs", "error", err, "storyID", id)
+		h.sendError(w, "Failed to generate signed URLs", http.StatusInternalServerError)
+		return
+	}
+
+	shuffled := make([]types.RecallSentence, len(sentences))
+	for i, sentence := range sentences {
+		shuffled[i] = types.RecallSentence{
+			ID:       sentence.ID,
+			Text:     sentence.Text,
+			ImageURL: imageURLs[sentence.ImageID],
+		}
+	}
+	shuffleRecallSentences(shuffled)
+
+	data := types.RecallPageData{
+		PageData: types.PageData{
+			StoryID:    storyID,
+			StoryTitle: story.Metadata.Title["en"],
+			Language:   story.Metadata.Language,
+		},
+		Sentences: shuffled,
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// shuffleRecallSentences shuffles sentences in place, never leaving them in their
+// original (correct) order when there is more than one
+func shuffleRecallSentences(sentences []types.RecallSentence) {
+	if len(sentences) < 2 {
+		return
+	}
+	original := slices.Clone(sentences)
+	for slices.Equal(sentences, original) {
+		rand.Shuffle(len(sentences), func(i, j int) {
+			sentences[i], sentences[j] = sentences[j], sentences[i]
+		})
+	}
+}
+
+// CheckRecall checks a submitted Recall ordering and logs the attempt
+func (h *Handler) CheckRecall(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req types.CheckRecallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid request body in CheckRecall", "error", err, "ip", r.RemoteAddr)
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	storyID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(storyID)
+	if err != nil {
+		h.log.Warn("Invalid story ID in CheckRecall", "storyID", storyID, "ip", r.RemoteAddr)
+		h.sendError(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	userID := auth.GetUserID(r)
+	if userID == "" {
+		h.sendError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	// Access check
+	if _, err := h.svc.GetStoryData(ctx, id, userID); err == models.ErrNotFound {
+		h.sendError(w, "Story not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.log.Error("Failed to fetch story in CheckRecall", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch story", http.StatusInternalServerError)
+		return
+	}
+
+	if h.storyClosed(w, r, userID, id) {
+		return
+	}
+
+	sentences, err := h.svc.GetStoryRecallSentences(ctx, id)
+	if err != nil {
+		h.log.Error("Failed to fetch recall sentences", "error", err, "storyID", id)
+		h.sendError(w, "Failed to fetch recall sentences", http.StatusInternalServerError)
+		return
+	}
+
+	results, err := models.CheckRecallOrder(sentences, req.Order)
+	if err == models.ErrInvalidRecallOrder {
+		h.log.Warn("Invalid order in CheckRecall", "order", req.Order, "storyID", id, "ip", r.RemoteAddr)
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.SaveRecallAttempt(ctx, userID, id, req.Order, results); err != nil {
+		h.log.Error("Failed to save recall attempt", "error", err, "userID", userID, "storyID", id)
+		h.sendError(w, "Failed to save recall attempt", http.StatusInternalServerError)
+		return
+	}
+
+	response := types.APIResponse{
+		Success: true,
+		Data: types.CheckRecallResponse{
+			Results:  results,
+			Complete: !slices.Contains(results, false),
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
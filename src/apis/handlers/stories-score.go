@@ -24,15 +24,19 @@ type ScoreData struct {
 	GrammarCorrectCount    int32   `json:"grammar_correct_count"`
 	GrammarIncorrectCount  int32   `json:"grammar_incorrect_count"`
 	GrammarTimeSeconds     int     `json:"grammar_time_seconds"`
+	RecallAccuracy         float64 `json:"recall_accuracy"` // Percentage (0-100)
+	RecallCorrectCount     int32   `json:"recall_correct_count"`
+	RecallIncorrectCount   int32   `json:"recall_incorrect_count"`
 	TranslationTimeSeconds int     `json:"translation_time_seconds"`
 	VideoTimeSeconds       int     `json:"video_time_seconds"`
+	Late                   bool    `json:"late"` // The score was reached after the story was due
 }
 
 // MissingActivity represents an incomplete activity
 type MissingActivity struct {
-	Activity    string `json:"activity"`     // "vocab", "grammar", "translation"
-	DisplayName string `json:"display_name"` // "Vocabulary", "Grammar", "Translation"
-	Route       string `json:"route"`        // "vocab", "grammar", "translate"
+	Activity    string `json:"activity"`     // "vocab", "grammar", "recall", "translation"
+	DisplayName string `json:"display_name"` // "Vocabulary", "Grammar", "Recall", "Translation"
+	Route       string `json:"route"`        // "vocab", "grammar", "recall", "translate"
 	Reason      string `json:"reason"`       // "no_data" or "insufficient_time"
 }
 
@@ -55,7 +59,7 @@ func (h *Handler) GetScoresData(w http.ResponseWriter, r *http.Request) {
 	userID := auth.GetUserID(r)
 
 	// Get story data for title
-	story, err := models.GetStoryData(r.Context(), id, userID)
+	story, err := h.svc.GetStoryData(r.Context(), id, userID)
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -65,41 +69,11 @@ func (h *Handler) GetScoresData(w http.ResponseWriter, r *http.Request) {
 		h.sendError(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	// Get total vocab and grammar counts in story
-	totalCounts := getVocabAndGrammarCount(*story)
 
-	// Get vocab accuracy
-	vocabSummary, err := models.GetUserStoryVocabSummary(r.Context(), userID, int32(id))
+	// Compute the score from the answer logs
+	score, err := h.svc.ComputeStoryScore(r.Context(), userID, id)
 	if err != nil {
-		h.log.Error("Failed to fetch vocab summary", "error", err, "storyID", id, "userID", userID)
-		h.sendError(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	var vocabAccuracy float64
-	vocabTotal := totalCounts.VocabCount
-	if vocabTotal > 0 {
-		vocabAccuracy = models.CalculateScoreWithRetriesAllowed(vocabSummary.CorrectCount, vocabSummary.IncorrectCount, vocabTotal)
-	}
-
-	// Get grammar accuracy
-	grammarSummary, err := models.GetUserStoryGrammarSummary(r.Context(), userID, int32(id))
-	if err != nil {
-		h.log.Error("Failed to fetch grammar summary", "error", err, "storyID", id, "userID", userID)
-		h.sendError(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	var grammarAccuracy float64
-	grammarTotal := totalCounts.GrammarCount
-	if grammarTotal > 0 {
-		grammarAccuracy = models.CalculateScoreWithRetriesAllowed(grammarSummary.CorrectCount, grammarSummary.IncorrectCount, grammarTotal)
-	}
-
-	// Get time tracking data
-	timeData, err := models.GetUserStoryTimeTracking(r.Context(), userID, int32(id))
-	if err != nil {
-		h.log.Error("Failed to fetch time tracking data", "error", err, "storyID", id, "userID", userID)
+		h.log.Error("Failed to compute story score", "error", err, "storyID", id, "userID", userID)
 		h.sendError(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -107,29 +81,10 @@ func (h *Handler) GetScoresData(w http.ResponseWriter, r *http.Request) {
 	// Check for missing data
 	var missingActivities []MissingActivity
 
-	// Count total vocab items in story
-	totalVocabItems := 0
-	for _, line := range story.Content.Lines {
-		totalVocabItems += len(line.Vocabulary)
-	}
-
-	// Count total grammar instances in story
-	totalGrammarInstances := 0
-	for _, line := range story.Content.Lines {
-		totalGrammarInstances += len(line.Grammar)
-	}
-
 	// Check vocab completion
-	if totalVocabItems == 0 {
+	if score.VocabTotal == 0 {
 		// Story has no vocab items - automatically complete
-	} else if vocabTotal == 0 {
-		missingActivities = append(missingActivities, MissingActivity{
-			Activity:    "vocab",
-			DisplayName: "Vocabulary",
-			Route:       "vocab",
-			Reason:      "no_data",
-		})
-	} else if int(vocabSummary.CorrectCount) < totalVocabItems {
+	} else if score.VocabCorrect < score.VocabTotal {
 		missingActivities = append(missingActivities, MissingActivity{
 			Activity:    "vocab",
 			DisplayName: "Vocabulary",
@@ -139,32 +94,35 @@ func (h *Handler) GetScoresData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check grammar completion
-	if totalGrammarInstances == 0 {
+	if score.GrammarTotal == 0 {
 		// Story has no grammar instances - automatically complete
-	} else if grammarTotal == 0 {
+	} else if score.GrammarCorrect < score.GrammarTotal {
 		missingActivities = append(missingActivities, MissingActivity{
 			Activity:    "grammar",
 			DisplayName: "Grammar",
 			Route:       "grammar",
-			Reason:      "no_data",
+			Reason:      "incomplete",
 		})
-	} else if int(grammarSummary.CorrectCount) < totalGrammarInstances {
+	}
+
+	// Check recall completion
+	if score.RecallTotal == 0 {
+		// Story has no recall sentences - automatically complete
+	} else if score.RecallCorrect < score.RecallTotal {
+		reason := "incomplete"
+		if score.RecallCorrect == 0 && score.RecallIncorrect == 0 {
+			reason = "no_data"
+		}
 		missingActivities = append(missingActivities, MissingActivity{
-			Activity:    "grammar",
-			DisplayName: "Grammar",
-			Route:       "grammar",
-			Reason:      "incomplete",
+			Activity:    "recall",
+			DisplayName: "Recall",
+			Route:       "recall",
+			Reason:      reason,
 		})
 	}
 
 	// Check translation completion
-	translationCompleted, err := models.TranslationRequestExists(r.Context(), userID, id)
-	if err != nil {
-		h.log.Error("Failed to check translation completion", "error", err, "storyID", id, "userID", userID)
-		h.sendError(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-	if !translationCompleted {
+	if !score.TranslationCompleted {
 		missingActivities = append(missingActivities, MissingActivity{
 			Activity:    "translation",
 			DisplayName: "Translation",
@@ -192,31 +150,32 @@ func (h *Handler) GetScoresData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calculate total time
-	totalTime := timeData.VocabTimeSeconds + timeData.GrammarTimeSeconds +
-		timeData.TranslationTimeSeconds + timeData.VideoTimeSeconds
-
-	// Calculate overall Accuracy:
-	overallAccuracy := models.CalculateScoreWithRetriesAllowed(
-		vocabSummary.CorrectCount+grammarSummary.CorrectCount,
-		vocabSummary.IncorrectCount+grammarSummary.IncorrectCount,
-		vocabTotal+grammarTotal,
-	)
+	// The student reached the score page: keep a snapshot for grading
+	late := false
+	if snapshot, _, err := h.svc.SaveStoryScoreSnapshot(r.Context(), score, models.ScoreReasonScorePage); err != nil {
+		h.log.Error("Failed to save story score snapshot", "error", err, "storyID", id, "userID", userID)
+	} else {
+		late = snapshot.Late
+	}
 
 	scoreData := ScoreData{
 		StoryTitle:             story.Metadata.Title["en"],
-		TotalTimeSeconds:       totalTime,
-		OverallAccuracy:        overallAccuracy,
-		VocabAccuracy:          vocabAccuracy,
-		VocabCorrectCount:      int32(vocabSummary.CorrectCount),
-		VocabIncorrectCount:    int32(vocabSummary.IncorrectCount),
-		VocabTimeSeconds:       timeData.VocabTimeSeconds,
-		GrammarAccuracy:        grammarAccuracy,
-		GrammarCorrectCount:    int32(grammarSummary.CorrectCount),
-		GrammarIncorrectCount:  int32(grammarSummary.IncorrectCount),
-		GrammarTimeSeconds:     timeData.GrammarTimeSeconds,
-		TranslationTimeSeconds: timeData.TranslationTimeSeconds,
-		VideoTimeSeconds:       timeData.VideoTimeSeconds,
+		TotalTimeSeconds:       score.TotalTimeSeconds,
+		OverallAccuracy:        score.OverallAccuracy,
+		VocabAccuracy:          score.VocabAccuracy,
+		VocabCorrectCount:      int32(score.VocabCorrect),
+		VocabIncorrectCount:    int32(score.VocabIncorrect),
+		VocabTimeSeconds:       score.VocabTimeSeconds,
+		GrammarAccuracy:        score.GrammarAccuracy,
+		GrammarCorrectCount:    int32(score.GrammarCorrect),
+		GrammarIncorrectCount:  int32(score.GrammarIncorrect),
+		GrammarTimeSeconds:     score.GrammarTimeSeconds,
+		RecallAccuracy:         score.RecallAccuracy,
+		RecallCorrectCount:     int32(score.RecallCorrect),
+		RecallIncorrectCount:   int32(score.RecallIncorrect),
+		TranslationTimeSeconds: score.TranslationTimeSeconds,
+		VideoTimeSeconds:       score.VideoTimeSeconds,
+		Late:                   late,
 	}
 
 	response := types.APIResponse{
@@ -227,12 +186,3 @@ func (h *Handler) GetScoresData(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
-
-func getVocabAndGrammarCount(story models.Story) struct{ VocabCount, GrammarCount int64 } {
-	counts := struct{ VocabCount, GrammarCount int64 }{}
-	for _, line := range story.Content.Lines {
-		counts.VocabCount += int64(len(line.Vocabulary))
-		counts.GrammarCount += int64(len(line.Grammar))
-	}
-	return counts
-}
@@ -45,7 +45,7 @@ func (h *Handler) GetTranslateData(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) getTranslationRequest(w http.ResponseWriter, r *http.Request, userID string, storyID int) {
 	ctx := r.Context()
-	story, err := models.GetStoryData(ctx, storyID, userID)
+	story, err := h.svc.GetStoryData(ctx, storyID, userID)
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -63,7 +63,7 @@ func (h *Handler) getTranslationRequest(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	hasTranslated, err := models.TranslationRequestExists(ctx, userID, storyID)
+	hasTranslated, err := h.svc.TranslationRequestExists(ctx, userID, storyID)
 	if err != nil {
 		// This could be logged and ignored
 		h.log.Error("Failed to check for translation request", "error", err)
@@ -91,9 +91,12 @@ func (h *Handler) getTranslationRequest(w http.ResponseWriter, r *http.Request,
 // saveTranslationRequest saves which lines the student translated
 func (h *Handler) saveTranslationRequest(w http.ResponseWriter, r *http.Request, userID string, storyID int) {
 	ctx := r.Context()
+	if h.storyClosed(w, r, userID, storyID) {
+		return
+	}
 
 	// Get the previous request, if any
-	prevTranslationRequest, err := models.GetTranslationRequest(ctx, userID, storyID)
+	prevTranslationRequest, err := h.svc.GetTranslationRequest(ctx, userID, storyID)
 	if err != nil && err != models.ErrNotFound {
 		h.log.Error("Failed to save translation request", "error", err)
 		h.sendError(w, "Failed to save translation request", http.StatusInternalServerError)
@@ -116,7 +119,7 @@ func (h *Handler) saveTranslationRequest(w http.ResponseWriter, r *http.Request,
 	// Combine the two requests if present
 	if prevTranslationRequest == nil {
 		// Create translation request to show this has been done
-		_, err = models.CreateTranslationRequest(ctx, userID, storyID, lineNumbers)
+		_, err = h.svc.CreateTranslationRequest(ctx, userID, storyID, lineNumbers)
 		if err != nil {
 			h.log.Error("Failed to create translation request", "error", err)
 			h.sendError(w, "Failed to create translation request", http.StatusInternalServerError)
@@ -143,7 +146,7 @@ func (h *Handler) saveTranslationRequest(w http.ResponseWriter, r *http.Request,
 		slices.Sort(combinedLines)
 
 		// Save in DB
-		err := models.UpdateTranslationRequest(ctx, userID, storyID, combinedLines)
+		err := h.svc.UpdateTranslationRequest(ctx, userID, storyID, combinedLines)
 		if err != nil {
 			h.log.Error("Failed to update translation request", "error", err)
 			h.sendError(w, "Failed to update translation request", http.StatusInternalServerError)
@@ -157,7 +160,7 @@ func (h *Handler) saveTranslationRequest(w http.ResponseWriter, r *http.Request,
 func (h *Handler) processLinesForTranslation(ctx context.Context, story models.Story, id int) (lines []types.LineTranslation, err error) {
 	lines = make([]types.LineTranslation, 0, len(story.Content.Lines))
 
-	translations, err := models.GetTranslationsByLanguage(ctx, id, "en")
+	translations, err := h.svc.GetTranslationsByLanguage(ctx, id, "en")
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +30,7 @@ func (h *Handler) GetVocabPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	story, err := models.GetStoryData(ctx, id, userID)
+	story, err := h.svc.GetStoryData(ctx, id, userID)
 	if err == models.ErrNotFound {
 		h.sendError(w, "Story not found", http.StatusNotFound)
 		return
@@ -69,7 +69,7 @@ func (h *Handler) GetVocabPage(w http.ResponseWriter, r *http.Request) {
 // generateVocabLines prepares lines with vocabulary blanks and vocab bank
 func (h *Handler) generateVocabLines(ctx context.Context, story models.Story, userID string) ([]types.VocabLine, []string, error) {
 	// Get incomplete vocab items for this user
-	incompleteVocab, err := models.GetLinesWithoutVocabForUser(ctx, userID, story.Metadata.StoryID)
+	incompleteVocab, err := h.svc.GetLinesWithoutVocabForUser(ctx, userID, story.Metadata.StoryID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -167,9 +167,11 @@ func (h *Handler) generateVocabLines(ctx context.Context, story models.Story, us
 		}
 	}
 
-	// Sort and dedupe vocab bank
-	slices.Sort(vocabBank)
-	vocabBank = slices.Compact(vocabBank)
+	// Dedupe the vocab bank, putting commonly confused and contrasted forms first
+	vocabBank, err = h.svc.OrderVocabBank(ctx, story.Metadata.StoryID, vocabBank)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	return lines, vocabBank, nil
 }
@@ -214,7 +216,7 @@ func (h *Handler) CheckVocab(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	story, err := models.GetStoryData(r.Context(), id, auth.GetUserID(r))
+	story, err := h.svc.GetStoryData(r.Context(), id, auth.GetUserID(r))
 	if err != nil {
 		h.log.Error("Failed to fetch story in CheckVocab", "error", err, "storyID", id)
 		h.sendError(w, "Failed to fetch story", http.StatusInternalServerError)
@@ -252,11 +254,14 @@ func (h *Handler) CheckVocab(w http.ResponseWriter, r *http.Request) {
 		h.sendError(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
+	if h.storyClosed(w, r, userID, id) {
+		return
+	}
 	incorrectAnswer := ""
 	if !isCorrect {
 		incorrectAnswer = req.Answer
 	}
-	if err := models.SaveVocabScore(ctx, userID, id, lineIndex, vocabIndex, isCorrect, incorrectAnswer); err != nil {
+	if err := h.svc.SaveVocabScore(ctx, userID, id, lineIndex, vocabIndex, isCorrect, incorrectAnswer); err != nil {
 		h.log.Error("Failed to save vocab score", "error", err, "userID", userID, "storyID", id, "line", lineIndex)
 		h.sendError(w, "Failed to save vocab score", http.StatusInternalServerError)
 		return
@@ -267,7 +272,7 @@ func (h *Handler) CheckVocab(w http.ResponseWriter, r *http.Request) {
 	allLineComplete := false
 	if isCorrect {
 		// Check if all vocab on line is completed by user
-		allLineComplete, err = models.CheckAllVocabCompleteForLine(r.Context(), userID, id, lineIndex)
+		allLineComplete, err = h.svc.CheckAllVocabCompleteForLine(r.Context(), userID, id, lineIndex)
 		if err == nil && allLineComplete {
 			originalLine = &line.Text
 		}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"glossias/src/apis/types"
 	"glossias/src/auth"
-	"glossias/src/pkg/models"
 	"net/http"
 	"strconv"
 
@@ -30,6 +29,9 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/{id}/vocab", h.GetVocabPage).Methods("GET", "OPTIONS")
 	router.HandleFunc("/{id}/grammar", h.GetGrammarPage).Methods("GET", "OPTIONS")
 	router.HandleFunc("/{id}/translate", h.GetTranslateData).Methods("GET", "PUT", "OPTIONS")
+	router.HandleFunc("/{id}/identify", h.GetIdentifyPage).Methods("GET", "OPTIONS")
+	router.HandleFunc("/{id}/recall", h.GetRecallPage).Methods("GET", "OPTIONS")
+	router.HandleFunc("/{id}/produce", h.ProduceHandler).Methods("GET", "POST", "OPTIONS")
 	router.HandleFunc("/{id}/scores", h.GetScoresData).Methods("GET", "OPTIONS")
 
 	// Audio endpoints
@@ -39,6 +41,10 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/{id}/check-vocab", h.CheckVocab).Methods("POST", "OPTIONS")
 	// Grammar checking endpoint
 	router.HandleFunc("/{id}/check-grammar", h.CheckGrammar).Methods("POST", "OPTIONS")
+	// Identify picture checking endpoint
+	router.HandleFunc("/{id}/check-identify", h.CheckIdentify).Methods("POST", "OPTIONS")
+	// Recall ordering checking endpoint
+	router.HandleFunc("/{id}/check-recall", h.CheckRecall).Methods("POST", "OPTIONS")
 
 	// Navigation endpoint
 	router.HandleFunc("/{id}/next", h.Navigate).Methods("POST", "OPTIONS")
@@ -54,7 +60,7 @@ func (h *Handler) GetStories(w http.ResponseWriter, r *http.Request) {
 	lang := r.URL.Query().Get("lang")
 
 	// Fetch stories from database
-	dbStories, err := models.GetAllStories(r.Context(), lang, auth.GetUserID(r))
+	dbStories, err := h.svc.GetAllStories(r.Context(), lang, auth.GetUserID(r))
 	if err != nil {
 		h.log.Error("Failed to fetch stories from database", "error", err)
 		h.sendError(w, "Failed to fetch stories", http.StatusInternalServerError)
@@ -90,12 +96,12 @@ func (h *Handler) GetCourseStories(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if user has read access to this course
-	if !auth.HasReadPermission(r.Context(), userID, int32(courseID)) {
+	if !h.svc.CanUserAccessCourse(r.Context(), userID, int32(courseID)) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
-	stories, err := models.GetStoriesForCourse(r.Context(), courseID)
+	stories, err := h.svc.GetAvailableStoriesForCourse(r.Context(), courseID, userID)
 	if err != nil {
 		h.log.Error("Failed to get stories for course", "error", err, "course_id", courseID)
 		json.NewEncoder(w).Encode(types.APIResponse{
@@ -16,9 +16,7 @@ import (
 )
 
 func TestGetCourseStories(t *testing.T) {
-	// Initialize Handler with a discard logger
 	logger := slog.New(slog.DiscardHandler)
-	h := NewHandler(logger)
 
 	tests := []struct {
 		name           string
@@ -79,10 +77,8 @@ func TestGetCourseStories(t *testing.T) {
 				}, nil)
 			}
 
-			models.SetDB(mockDB)
-			defer func() {
-				models.SetDB(struct{}{})
-			}()
+			// Handler gets its own service, so no package state is shared between cases
+			h := NewHandler(logger, models.NewService(mockDB, nil, nil, nil))
 
 			// Build request
 			req := httptest.NewRequest("GET", "/api/stories/by-course/"+tt.courseIDStr, nil)
@@ -119,3 +115,110 @@ func TestGetCourseStories(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateIdentifyLines(t *testing.T) {
+	h := &Handler{log: slog.New(slog.DiscardHandler)}
+	story := models.Story{}
+	story.Content.Lines = []models.StoryLine{
+		{
+			LineNumber: 1,
+			Text:       "שָׁלוֹם כֶּלֶב טוֹב",
+			Vocabulary: []models.VocabularyItem{
+				{Word: "טוֹב", LexicalForm: "טוב", Position: [2]int{15, 19}},
+				{Word: "כֶּלֶב", LexicalForm: "כלב", Position: [2]int{8, 14}},
+			},
+		},
+		{LineNumber: 2, Text: "no targets here"},
+	}
+
+	lines := h.generateIdentifyLines(story, map[string]int{"כלב": 7}, map[int]map[int]bool{1: {7: true}})
+
+	if !lines[0].HasTarget || lines[1].HasTarget {
+		t.Fatalf("Expected only the first line to have a target, got %+v", lines)
+	}
+	segments := lines[0].Text
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d: %+v", len(segments), segments)
+	}
+	target := segments[1]
+	if target.Type != "target" || target.Text != "כֶּלֶב" || target.TargetID != 7 || !target.Completed {
+		t.Errorf("Unexpected target segment: %+v", target)
+	}
+	if segments[2].Type != "text" || segments[2].Text != " טוֹב" {
+		t.Errorf("Expected non-target vocab to stay text, got %+v", segments[2])
+	}
+}
+
+func TestGenerateProduceSegments(t *testing.T) {
+	h := &Handler{log: slog.New(slog.DiscardHandler)}
+	story := models.Story{}
+	story.Metadata.GrammarPoints = []models.GrammarPoint{{ID: 3, Name: "Past tense"}}
+	story.Content.Lines = []models.StoryLine{
+		{LineNumber: 1, Text: "one"},
+		{LineNumber: 2, Text: "two"},
+		{LineNumber: 3, Text: "three"},
+		{LineNumber: 4, Text: "four"},
+	}
+	segments := []models.ProduceSegment{
+		{ID: 1, StartLine: 1, EndLine: 1, EnglishPrompt: "first", ReferenceText: "ref one", GrammarPointID: 3},
+		{ID: 2, StartLine: 2, EndLine: 3, EnglishPrompt: "middle", ReferenceText: "ref two"},
+	}
+	submissions := map[int]models.ProduceSubmission{2: {SegmentID: 2, StudentText: "my answer"}}
+
+	result := h.generateProduceSegments(story, segments, submissions)
+
+	first := result[0]
+	if first.ContextBefore != "" || first.ContextAfter != "two" || first.GrammarPoint != "Past tense" {
+		t.Errorf("Unexpected first segment: %+v", first)
+	}
+	if first.ReferenceText != "" {
+		t.Errorf("Expected reference text to be withheld before submitting, got %q", first.ReferenceText)
+	}
+	second := result[1]
+	if second.ContextBefore != "one" || second.ContextAfter != "four" {
+		t.Errorf("Unexpected context for second segment: %+v", second)
+	}
+	if second.StudentText != "my answer" || second.ReferenceText != "ref two" {
+		t.Errorf("Expected submission and reference text, got %+v", second)
+	}
+}
+
+func TestDetermineNextPage(t *testing.T) {
+	summerFlow := []PageType{PageTypeVideo, PageTypeVocab, PageTypeIdentify, PageTypeRecall, PageTypeProduce, PageTypeScore}
+	status := map[PageType]pageStatus{
+		PageTypeVideo:    {HasContent: true},
+		PageTypeVocab:    {HasContent: true, Completed: true},
+		PageTypeIdentify: {HasContent: false},
+		PageTypeRecall:   {HasContent: true},
+		PageTypeProduce:  {HasContent: false},
+		PageTypeScore:    {HasContent: true},
+	}
+
+	tests := []struct {
+		name    string
+		order   []PageType
+		current string
+		status  map[PageType]pageStatus
+		want    PageType
+	}{
+		{"unknown page starts at video", summerFlow, "", status, PageTypeVideo},
+		{"skips completed and empty pages", summerFlow, "video", status, PageTypeRecall},
+		{"skips empty produce", summerFlow, "recall", status, PageTypeScore},
+		{"page outside flow starts over", summerFlow, "grammar", status, PageTypeVideo},
+		{
+			"skips video without content",
+			[]PageType{PageTypeVideo, PageTypeTranslate, PageTypeScore},
+			"",
+			map[PageType]pageStatus{PageTypeTranslate: {HasContent: true}, PageTypeScore: {HasContent: true}},
+			PageTypeTranslate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := determineNextPage(tt.order, tt.current, tt.status); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want.Path, got.Path)
+			}
+		})
+	}
+}
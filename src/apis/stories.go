@@ -3,6 +3,7 @@ package apis
 import (
 	"glossias/src/apis/handlers"
 	"glossias/src/apis/users"
+	"glossias/src/pkg/models"
 	"log/slog"
 
 	"github.com/gorilla/mux"
@@ -16,10 +17,10 @@ type Handler struct {
 }
 
 // NewHandler creates a new API handler
-func NewHandler(logger *slog.Logger) *Handler {
+func NewHandler(logger *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
-		Handler: handlers.NewHandler(logger),
-		users:   users.NewHandler(logger),
+		Handler: handlers.NewHandler(logger, svc),
+		users:   users.NewHandler(logger, svc),
 		logger:  logger,
 	}
 }
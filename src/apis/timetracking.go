@@ -14,12 +14,14 @@ import (
 // TimeTrackingHandler handles time tracking API endpoints
 type TimeTrackingHandler struct {
 	logger *slog.Logger
+	svc    *models.Service
 }
 
 // NewTimeTrackingHandler creates a new time tracking handler
-func NewTimeTrackingHandler(logger *slog.Logger) *TimeTrackingHandler {
+func NewTimeTrackingHandler(logger *slog.Logger, svc *models.Service) *TimeTrackingHandler {
 	return &TimeTrackingHandler{
 		logger: logger,
+		svc:    svc,
 	}
 }
 
@@ -72,7 +74,7 @@ func (h *TimeTrackingHandler) startTimeTracking(w http.ResponseWriter, r *http.R
 		clientIP = r.RemoteAddr
 	}
 
-	trackingID, err := models.MakeTimeTrackingSession(r.Context(), userID, req.Route, req.StoryID)
+	trackingID, err := h.svc.MakeTimeTrackingSession(r.Context(), userID, req.Route, req.StoryID)
 	if err != nil {
 		h.logger.Error("failed to start time tracking", "error", err, "user_id", userID)
 		http.Error(w, "Failed to start time tracking", http.StatusInternalServerError)
@@ -121,7 +123,7 @@ func (h *TimeTrackingHandler) recordTimeTracking(w http.ResponseWriter, r *http.
 		req.ElapsedMs = elapsed
 	}
 
-	session, err := models.GetTimeTrackingBySessionID(r.Context(), req.TrackingID)
+	session, err := h.svc.GetTimeTrackingBySessionID(r.Context(), req.TrackingID)
 	if err != nil {
 		h.logger.Error("failed to get time tracking session", "error", err, "tracking_id", req.TrackingID)
 		http.Error(w, "Invalid tracking ID", http.StatusBadRequest)
@@ -142,7 +144,7 @@ func (h *TimeTrackingHandler) recordTimeTracking(w http.ResponseWriter, r *http.
 
 	fmt.Println("Session: ", session)
 
-	err = models.RecordTimeTracking(r.Context(), session.UserID, session.Route, session.StoryID, req.ElapsedMs)
+	err = h.svc.RecordTimeTracking(r.Context(), session.UserID, session.Route, session.StoryID, req.ElapsedMs)
 	if err != nil {
 		h.logger.Error("failed to record time tracking", "error", err, "user_id", session.UserID)
 		http.Error(w, "Failed to record time tracking", http.StatusInternalServerError)
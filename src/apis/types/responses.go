@@ -35,9 +35,11 @@ type AudioFile struct {
 
 // TextSegment represents a segment of text in a line
 type TextSegment struct {
-	Text     string `json:"text"`
-	Type     string `json:"type"`                // "text", "blank", "completed"
-	VocabKey string `json:"vocab_key,omitempty"` // For blanks: "lineIndex-vocabIndex"
+	Text      string `json:"text"`
+	Type      string `json:"type"`                // "text", "blank", "completed", "target"
+	VocabKey  string `json:"vocab_key,omitempty"` // For blanks: "lineIndex-vocabIndex"
+	TargetID  int    `json:"target_id,omitempty"` // For targets: target vocabulary ID
+	Completed bool   `json:"completed,omitempty"` // For targets: already identified by the user
 }
 
 // Line represents a story line in API responses
@@ -54,6 +56,49 @@ type VocabLine struct {
 	SignedAudioURLs map[int]string `json:"signed_audio_urls,omitempty"`
 }
 
+// IdentifyLine represents a story line with target word segments
+type IdentifyLine struct {
+	Text           []TextSegment `json:"text"`
+	SignedAudioURL string        `json:"signed_audio_url,omitempty"`
+	HasTarget      bool          `json:"has_target"` // Whether the quiz opens after this line plays
+}
+
+// IdentifyTarget represents a target word asked about in the Identify picture quiz
+type IdentifyTarget struct {
+	ID          int    `json:"id"`
+	LexicalForm string `json:"lexical_form"`
+	AudioURL    string `json:"audio_url,omitempty"`
+}
+
+// IdentifyOption represents a picture offered in the Identify picture quiz. The ID is
+// opaque and changes with every attempt, so it does not tell which target word is shown.
+type IdentifyOption struct {
+	ID       string `json:"id"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// RecallSentence represents a Recall sentence as shown to students, without its position
+type RecallSentence struct {
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// ProduceSegment represents a Produce segment as shown to students. The segment's own
+// lines are withheld; only the lines around it are given as context.
+type ProduceSegment struct {
+	ID            int    `json:"id"`
+	EnglishPrompt string `json:"english_prompt"`
+	GrammarPoint  string `json:"grammar_point,omitempty"`
+	ContextBefore string `json:"context_before,omitempty"` // Line just before the segment
+	ContextAfter  string `json:"context_after,omitempty"`  // Line just after the segment
+	StudentText   string `json:"student_text,omitempty"`   // Latest submission
+	ReferenceText string `json:"reference_text,omitempty"` // Only once the student has submitted
+	AIScore       *int   `json:"ai_score,omitempty"`       // 0-100, absent until graded
+	AIFeedback    string `json:"ai_feedback,omitempty"`
+	GradingStatus string `json:"grading_status,omitempty"` // "pending" or "graded" once submitted
+}
+
 // LineText represents line text without anything else
 type LineText struct {
 	Text string `json:"text"`
@@ -79,6 +124,27 @@ type VocabPageData struct {
 	VocabBank []string    `json:"vocab_bank"`
 }
 
+// IdentifyPageData extends PageData with target-marked lines and the picture options
+type IdentifyPageData struct {
+	PageData
+	Lines   []IdentifyLine   `json:"lines"`
+	Targets []IdentifyTarget `json:"targets"`
+	Options []IdentifyOption `json:"options"` // Randomized order
+}
+
+// RecallPageData extends PageData with the Recall sentences
+type RecallPageData struct {
+	PageData
+	Sentences []RecallSentence `json:"sentences"` // Shuffled server-side
+}
+
+// ProducePageData extends PageData with the Produce segments
+type ProducePageData struct {
+	PageData
+	Explanation string           `json:"explanation,omitempty"`
+	Segments    []ProduceSegment `json:"segments"`
+}
+
 // GrammarPageData extends PageData with grammar point
 type GrammarPageData struct {
 	PageData
@@ -111,6 +177,24 @@ type CheckVocabRequest struct {
 	Answer   string `json:"answer"`
 }
 
+// CheckIdentifyRequest represents the request body for an Identify picture pick
+type CheckIdentifyRequest struct {
+	LineIndex      int    `json:"line_index"`      // 0-indexed line whose target word prompted the quiz
+	TargetID       int    `json:"target_id"`       // Target word being identified
+	SelectedOption string `json:"selected_option"` // ID of the picked picture option
+}
+
+// CheckRecallRequest represents a submitted Recall ordering
+type CheckRecallRequest struct {
+	Order []int `json:"order"` // Sentence IDs, first to last
+}
+
+// ProduceSubmitRequest represents a student's answer to a Produce segment
+type ProduceSubmitRequest struct {
+	SegmentID   int    `json:"segment_id"`
+	StudentText string `json:"student_text"`
+}
+
 // GrammarAnswer represents grammar answer from client
 type GrammarAnswer struct {
 	LineNumber int   `json:"line_number"`
@@ -137,6 +221,18 @@ type CheckVocabResponse struct {
 	OriginalLine *string `json:"original_line,omitempty"` // Original line text when the line is complete
 }
 
+// CheckIdentifyResponse represents the response for an Identify picture pick
+type CheckIdentifyResponse struct {
+	Correct      bool `json:"correct"`
+	LineComplete bool `json:"line_complete"` // Whether every target word on the line has been identified
+}
+
+// CheckRecallResponse represents the response for a Recall ordering
+type CheckRecallResponse struct {
+	Results  []bool `json:"results"`  // Per submitted position, whether the sentence belongs there
+	Complete bool   `json:"complete"` // Whether every sentence is in place
+}
+
 // GrammarInstance represents a grammar point instance in the story
 type GrammarInstance struct {
 	LineNumber int    `json:"line_number"`
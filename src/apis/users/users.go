@@ -12,11 +12,13 @@ import (
 
 type Handler struct {
 	log *slog.Logger
+	svc *models.Service
 }
 
-func NewHandler(logger *slog.Logger) *Handler {
+func NewHandler(logger *slog.Logger, svc *models.Service) *Handler {
 	return &Handler{
 		log: logger,
+		svc: svc,
 	}
 }
 
@@ -49,7 +51,7 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user from database
-	user, err := models.GetUser(r.Context(), userID)
+	user, err := h.svc.GetUser(r.Context(), userID)
 	if err != nil {
 		h.log.Error("Failed to fetch user from database", "user_id", userID, "error", err)
 		h.sendError(w, "Failed to fetch user information", http.StatusInternalServerError)
@@ -57,7 +59,7 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user's course admin rights
-	courseRights, err := models.GetUserCourseAdminRights(r.Context(), userID)
+	courseRights, err := h.svc.GetUserCourseAdminRights(r.Context(), userID)
 	if err != nil {
 		h.log.Error("Failed to fetch user course rights", "user_id", userID, "error", err)
 		// Continue without course rights rather than failing completely
@@ -65,7 +67,7 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user's enrolled courses
-	enrolledCourses, err := models.GetCoursesForUser(r.Context(), userID)
+	enrolledCourses, err := h.svc.GetCoursesForUser(r.Context(), userID)
 	if err != nil {
 		h.log.Error("Failed to fetch user enrolled courses", "user_id", userID, "error", err)
 		// Continue without enrolled courses rather than failing completely
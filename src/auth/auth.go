@@ -20,14 +20,24 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// sessionKey marks requests authenticated by a SessionVerifier rather than Clerk
+const sessionKey contextKey = "session"
+
+// SessionVerifier is a second identity source next to Clerk, such as LTI launches.
+// VerifySession returns the user a bearer token belongs to, or an error if it is not one of its tokens.
+type SessionVerifier interface {
+	VerifySession(token string) (string, error)
+}
+
 var byPassURLS = []string{
 	"/api/health",
 	"/api/db-health",
 	"/api/time-tracking/record",
 }
 
-// Middleware combines CORS and Clerk authentication
-func Middleware(logger *slog.Logger) mux.MiddlewareFunc {
+// Middleware combines CORS and authentication. Bearer tokens are checked with sessions
+// first, when it is not nil, and then with Clerk.
+func Middleware(logger *slog.Logger, svc *models.Service, sessions SessionVerifier) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// CORS headers
@@ -44,14 +54,21 @@ func Middleware(logger *slog.Logger) mux.MiddlewareFunc {
 
 			// Extract and validate JWT token for API routes (except health and time tracking)
 			if strings.HasPrefix(r.URL.Path, "/api/") && !slices.Contains(byPassURLS, r.URL.Path) {
-				userID, err := extractAndValidateUser(r, logger)
-				if err != nil {
-					logger.Error("auth failed", "error", err, "path", r.URL.Path)
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
-					return
+				ctx := r.Context()
+				userID, ok := verifySession(r, sessions)
+				if ok {
+					ctx = context.WithValue(ctx, sessionKey, true)
+				} else {
+					var err error
+					userID, err = extractAndValidateUser(r, logger, svc)
+					if err != nil {
+						logger.Error("auth failed", "error", err, "path", r.URL.Path)
+						http.Error(w, "Unauthorized", http.StatusUnauthorized)
+						return
+					}
 				}
 				// Add user ID to request context
-				ctx := context.WithValue(r.Context(), UserIDKey, userID)
+				ctx = context.WithValue(ctx, UserIDKey, userID)
 				r = r.WithContext(ctx)
 			}
 
@@ -60,8 +77,46 @@ func Middleware(logger *slog.Logger) mux.MiddlewareFunc {
 	}
 }
 
+// verifySession checks the bearer token with sessions. Users of these sessions are synced
+// to the database by the identity source when it issues the token.
+func verifySession(r *http.Request, sessions SessionVerifier) (string, bool) {
+	if sessions == nil {
+		return "", false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	userID, err := sessions.VerifySession(token)
+	if err != nil || userID == "" {
+		return "", false
+	}
+	return userID, true
+}
+
+// IsSession reports whether Middleware authenticated r with the SessionVerifier rather than Clerk
+func IsSession(r *http.Request) bool {
+	session, _ := r.Context().Value(sessionKey).(bool)
+	return session
+}
+
+// UnlessSession applies mw only to requests that Middleware did not authenticate with a
+// SessionVerifier, so Clerk's own header check does not turn those sessions away
+func UnlessSession(mw func(http.Handler) http.Handler) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsSession(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
 // extractAndValidateUser extracts user info from JWT and syncs with database
-func extractAndValidateUser(r *http.Request, logger *slog.Logger) (string, error) {
+func extractAndValidateUser(r *http.Request, logger *slog.Logger, svc *models.Service) (string, error) {
 	ctx := r.Context()
 
 	// Dev auth bypass - only when DEV_USER is set
@@ -89,7 +144,7 @@ func extractAndValidateUser(r *http.Request, logger *slog.Logger) (string, error
 			name = *clerkUser.LastName
 		}
 
-		_, err = models.UpsertUser(ctx, devUser, email, name)
+		_, err = svc.UpsertUser(ctx, devUser, email, name)
 		if err != nil {
 			logger.Warn("failed to sync dev user to database", "error", err, "dev_user", devUser)
 		}
@@ -129,7 +184,7 @@ func extractAndValidateUser(r *http.Request, logger *slog.Logger) (string, error
 	if err != nil {
 		logger.Warn("failed to fetch user details from Clerk", "error", err, "user_id", userID)
 		// Fallback to basic user sync without email/name
-		_, syncErr := models.UpsertUser(ctx, userID, "", "")
+		_, syncErr := svc.UpsertUser(ctx, userID, "", "")
 		if syncErr != nil {
 			logger.Warn("failed basic user sync to database", "error", syncErr, "user_id", userID)
 		}
@@ -153,7 +208,7 @@ func extractAndValidateUser(r *http.Request, logger *slog.Logger) (string, error
 	}
 
 	// Sync user data with database
-	_, err = models.UpsertUser(ctx, userID, email, name)
+	_, err = svc.UpsertUser(ctx, userID, email, name)
 	if err != nil {
 		logger.Warn("failed to sync user to database", "error", err, "user_id", userID, "email", email, "name", name)
 		// Don't fail the request if database sync fails
@@ -176,22 +231,22 @@ func GetUserID(r *http.Request) string {
 	return userID
 }
 
-// HasPermission checks if user has permission to access a course (non-admin)
-func HasReadPermission(ctx context.Context, userID string, courseID int32) bool {
-	return models.CanUserAccessCourse(ctx, userID, courseID)
+// HasReadPermission checks if user has permission to access a course (non-admin)
+func HasReadPermission(ctx context.Context, svc *models.Service, userID string, courseID int32) bool {
+	return svc.CanUserAccessCourse(ctx, userID, courseID)
 }
 
 // IsAnyAdmin checks if user is admin of any course or super admin
-func IsAnyAdmin(ctx context.Context, userID string) bool {
-	return models.IsUserAnyAdmin(ctx, userID)
+func IsAnyAdmin(ctx context.Context, svc *models.Service, userID string) bool {
+	return svc.IsUserAnyAdmin(ctx, userID)
 }
 
 // IsCourseOrSuperAdmin checks if user is admin of one single course or super admin
-func IsCourseOrSuperAdmin(ctx context.Context, userID string, courseID int32) bool {
-	return models.IsUserCourseOrSuperAdmin(ctx, userID, courseID)
+func IsCourseOrSuperAdmin(ctx context.Context, svc *models.Service, userID string, courseID int32) bool {
+	return svc.IsUserCourseOrSuperAdmin(ctx, userID, courseID)
 }
 
-// IsCourseAddmin checks if user is admin of one single course (no super admin override)
-func IsCourseAdmin(ctx context.Context, userID string, courseID int32) bool {
-	return models.IsUserOnlyCourseAdmin(ctx, userID, courseID)
+// IsCourseAdmin checks if user is admin of one single course (no super admin override)
+func IsCourseAdmin(ctx context.Context, svc *models.Service, userID string, courseID int32) bool {
+	return svc.IsUserOnlyCourseAdmin(ctx, userID, courseID)
 }
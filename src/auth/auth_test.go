@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeSessions map[string]string
+
+func (f fakeSessions) VerifySession(token string) (string, error) {
+	if userID, ok := f[token]; ok {
+		return userID, nil
+	}
+	return "", errors.New("unknown session")
+}
+
+func TestMiddlewareAcceptsSessions(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	clerkCalled := false
+	clerkOnly := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clerkCalled = true
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+
+	var gotUser string
+	handler := Middleware(logger, nil, fakeSessions{"lti-token": "lti_1_student"})(
+		UnlessSession(clerkOnly)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotUser = GetUserID(r)
+		})),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stories", nil)
+	req.Header.Set("Authorization", "Bearer lti-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || gotUser != "lti_1_student" {
+		t.Fatalf("status = %d, user = %q; want 200 for lti_1_student", rec.Code, gotUser)
+	}
+	if clerkCalled {
+		t.Error("Clerk middleware ran for a session request")
+	}
+}
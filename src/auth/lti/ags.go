@@ -0,0 +1,291 @@
+package lti
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"glossias/src/pkg/models"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// AGS scopes the tool asks for, and the media types of its services
+const (
+	scopeLineItem        = "https://purl.imsglobal.org/spec/lti-ags/scope/lineitem"
+	scopeScore           = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	mediaLineItem        = "application/vnd.ims.lis.v2.lineitem+json"
+	mediaLineItemList    = "application/vnd.ims.lis.v2.lineitemcontainer+json"
+	mediaScore           = "application/vnd.ims.lis.v1.score+json"
+	clientAssertionType  = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	lineItemTag          = "glossias"
+	scoreMaximum         = 100 // Scores are posted as overall accuracy, a percentage
+	tokenExpiryMargin    = time.Minute
+	clientAssertionValid = 5 * time.Minute
+)
+
+// lineItem is an AGS line item, a gradebook column
+type lineItem struct {
+	ID           string  `json:"id,omitempty"`
+	ScoreMaximum float64 `json:"scoreMaximum"`
+	Label        string  `json:"label"`
+	ResourceID   string  `json:"resourceId,omitempty"`
+	Tag          string  `json:"tag,omitempty"`
+}
+
+// agsScore is a result posted to a line item
+type agsScore struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+type cachedToken struct {
+	token   string
+	expires time.Time
+}
+
+type lineItemKey struct {
+	ltiContextID int32
+	storyID      int
+}
+
+// PublishScore posts a story score to every LMS gradebook the student launched the story's
+// course from. It makes the Tool a models.ScorePublisher.
+func (t *Tool) PublishScore(ctx context.Context, score models.StoryScore) error {
+	targets, err := t.store.GetLTIScoreTargets(ctx, score.UserID, score.StoryID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := t.publishScore(ctx, target, score); err != nil {
+			errs = append(errs, fmt.Errorf("lti context %d: %w", target.LTIContextID, err))
+			continue
+		}
+		t.log.Info("score posted to LMS", "user_id", score.UserID, "story_id", score.StoryID, "lti_context_id", target.LTIContextID)
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Tool) publishScore(ctx context.Context, target models.LTIScoreTarget, score models.StoryScore) error {
+	platform, err := t.store.GetLTIPlatform(ctx, target.PlatformID)
+	if err != nil {
+		return err
+	}
+	token, err := t.accessToken(ctx, platform)
+	if err != nil {
+		return err
+	}
+	lineItemURL, err := t.lineItemURL(ctx, token, target, score.StoryID)
+	if err != nil {
+		return err
+	}
+
+	body := agsScore{
+		UserID:           target.Subject,
+		ScoreGiven:       score.OverallAccuracy,
+		ScoreMaximum:     scoreMaximum,
+		Timestamp:        score.ComputedAt.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		ActivityProgress: "Completed",
+		GradingProgress:  "FullyGraded",
+	}
+	return t.agsRequest(ctx, token, http.MethodPost, scoresURL(lineItemURL), mediaScore, "", body, nil)
+}
+
+// lineItemURL finds the line item of a story in a context: the one the platform named at
+// launch or created from deep linking, else one tagged with the story, else a new one
+func (t *Tool) lineItemURL(ctx context.Context, token string, target models.LTIScoreTarget, storyID int) (string, error) {
+	// Two scores for the same story must not both create a line item
+	key := lineItemKey{ltiContextID: target.LTIContextID, storyID: storyID}
+	t.mu.Lock()
+	lock, ok := t.lineItems[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		t.lineItems[key] = lock
+	}
+	t.mu.Unlock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	known, err := t.store.GetLTILineItem(ctx, target.LTIContextID, storyID)
+	if err == nil {
+		return known, nil
+	}
+	if err != models.ErrNotFound {
+		return "", err
+	}
+
+	resourceID := strconv.Itoa(storyID)
+	var existing []lineItem
+	query := url.Values{"resource_id": {resourceID}}
+	err = t.agsRequest(ctx, token, http.MethodGet, withQuery(target.LineItemsURL, query), "", mediaLineItemList, nil, &existing)
+	if err != nil {
+		return "", err
+	}
+
+	// Platforms that ignore the filter return every line item of the context
+	var found string
+	if index := slices.IndexFunc(existing, func(item lineItem) bool { return item.ResourceID == resourceID }); index >= 0 {
+		found = existing[index].ID
+	} else {
+		created := lineItem{
+			ScoreMaximum: scoreMaximum,
+			Label:        t.storyLineItemLabel(ctx, target.CourseID, storyID),
+			ResourceID:   resourceID,
+			Tag:          lineItemTag,
+		}
+		err = t.agsRequest(ctx, token, http.MethodPost, target.LineItemsURL, mediaLineItem, mediaLineItem, created, &created)
+		if err != nil {
+			return "", err
+		}
+		found = created.ID
+	}
+	if found == "" {
+		return "", errors.New("platform returned a line item without an id")
+	}
+
+	if err := t.store.SaveLTILineItem(ctx, target.LTIContextID, storyID, found); err != nil {
+		return "", err
+	}
+	return found, nil
+}
+
+// storyLineItemLabel labels a new line item like deep linking does
+func (t *Tool) storyLineItemLabel(ctx context.Context, courseID int32, storyID int) string {
+	stories, err := t.store.GetStoriesForCourse(ctx, int(courseID))
+	if err == nil {
+		for _, story := range stories {
+			if story.Metadata.StoryID == storyID {
+				return storyLabel(story)
+			}
+		}
+	}
+	return fmt.Sprintf("Story %d", storyID)
+}
+
+// accessToken returns an AGS access token for the platform, using the OAuth 2 client
+// credentials grant with a JWT signed by the tool as the client assertion
+func (t *Tool) accessToken(ctx context.Context, platform *models.LTIPlatform) (string, error) {
+	t.mu.Lock()
+	cached, ok := t.tokens[platform.ID]
+	t.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	now := time.Now()
+	assertion, err := t.sign(jwt.Claims{
+		Issuer:   platform.ClientID,
+		Subject:  platform.ClientID,
+		Audience: jwt.Audience{platform.AuthTokenURL},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(clientAssertionValid)),
+		ID:       randomString(16),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {assertion},
+		"scope":                 {scopeLineItem + " " + scopeScore},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, platform.AuthTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("request access token: status %d: %s", resp.StatusCode, body)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode access token: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("platform returned an empty access token")
+	}
+
+	expires := now.Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryMargin)
+	t.mu.Lock()
+	t.tokens[platform.ID] = cachedToken{token: result.AccessToken, expires: expires}
+	t.mu.Unlock()
+	return result.AccessToken, nil
+}
+
+// agsRequest calls an AGS service. body is sent as contentType JSON when not nil and the
+// response is decoded into out when not nil.
+func (t *Tool) agsRequest(ctx context.Context, token, method, target, contentType, accept string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: status %d: %s", method, target, resp.StatusCode, detail)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// scoresURL is the scores service of a line item: its path with /scores appended
+func scoresURL(lineItemURL string) string {
+	parsed, err := url.Parse(lineItemURL)
+	if err != nil {
+		return strings.TrimRight(lineItemURL, "/") + "/scores"
+	}
+	parsed.Path = strings.TrimRight(parsed.Path, "/") + "/scores"
+	return parsed.String()
+}
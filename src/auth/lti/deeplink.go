@@ -0,0 +1,227 @@
+package lti
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"glossias/src/pkg/models"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// deepLinkTTL is how long an instructor has to pick stories
+const deepLinkTTL = time.Hour
+
+// deepLinkSettings carry a deep linking request from the launch to the picker's submission
+type deepLinkSettings struct {
+	PlatformID     int32  `json:"platform_id"`
+	DeploymentID   string `json:"deployment_id"`
+	CourseID       int32  `json:"course_id"`
+	ReturnURL      string `json:"return_url"`
+	Data           string `json:"data,omitempty"`
+	AcceptMultiple bool   `json:"accept_multiple"`
+}
+
+// contentItem is an LTI resource link the platform adds to the course
+type contentItem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	URL      string            `json:"url"`
+	Custom   map[string]string `json:"custom"`
+	LineItem *lineItem         `json:"lineItem,omitempty"`
+}
+
+type storyPickerPage struct {
+	Title          string
+	Action         string
+	Settings       string
+	AcceptMultiple bool
+	Stories        []storyChoice
+}
+
+type storyChoice struct {
+	ID    int
+	Label string
+}
+
+var storyPickerTemplate = template.Must(template.New("picker").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Stories}}
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="settings" value="{{.Settings}}">
+{{range .Stories}}<label><input type="{{if $.AcceptMultiple}}checkbox{{else}}radio{{end}}" name="story_id" value="{{.ID}}"> {{.Label}}</label><br>
+{{end}}
+<button type="submit">Add to course</button>
+</form>
+{{else}}
+<p>This course has no stories yet. Add stories in Glossias, then try again.</p>
+{{end}}
+</body>
+</html>
+`))
+
+var autoPostTemplate = template.Must(template.New("post").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Returning to the LMS</title></head>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="JWT" value="{{.JWT}}">
+<noscript><button type="submit">Continue</button></noscript>
+</form>
+</body>
+</html>
+`))
+
+// showStoryPicker lets an instructor choose the stories a deep linking request adds to the LMS course
+func (t *Tool) showStoryPicker(w http.ResponseWriter, r *http.Request, platform *models.LTIPlatform, claims *launchClaims, link *models.LTIContext) {
+	settings := claims.DeepLinking
+	if len(settings.AcceptTypes) > 0 && !slices.Contains(settings.AcceptTypes, "ltiResourceLink") {
+		http.Error(w, "The LMS does not accept links to Glossias stories here", http.StatusBadRequest)
+		return
+	}
+
+	stories, err := t.store.GetStoriesForCourse(r.Context(), int(link.CourseID))
+	if err != nil {
+		t.log.Error("failed to load course stories", "error", err, "course_id", link.CourseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := t.sign(t.ownClaims(audienceDeepLink, claims.Subject, deepLinkTTL), deepLinkSettings{
+		PlatformID:     platform.ID,
+		DeploymentID:   claims.DeploymentID,
+		CourseID:       link.CourseID,
+		ReturnURL:      settings.ReturnURL,
+		Data:           settings.Data,
+		AcceptMultiple: settings.AcceptMultiple,
+	})
+	if err != nil {
+		t.log.Error("failed to sign deep linking settings", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	page := storyPickerPage{
+		Title:          "Add Glossias stories",
+		Action:         t.baseURL + "/lti/deep-link",
+		Settings:       token,
+		AcceptMultiple: settings.AcceptMultiple,
+	}
+	if settings.Title != "" {
+		page.Title = settings.Title
+	}
+	for _, story := range stories {
+		page.Stories = append(page.Stories, storyChoice{ID: story.Metadata.StoryID, Label: storyLabel(story)})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := storyPickerTemplate.Execute(w, page); err != nil {
+		t.log.Error("failed to render story picker", "error", err)
+	}
+}
+
+// deepLink answers a deep linking request with the stories picked in the story picker.
+// Each story becomes a resource link that launches it and carries a line item for its score.
+func (t *Tool) deepLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	var settings deepLinkSettings
+	if err := t.verifyOwn(r.Form.Get("settings"), audienceDeepLink, &settings); err != nil {
+		t.log.Warn("deep linking with invalid settings", "error", err)
+		http.Error(w, "Story selection expired, please start again from the LMS", http.StatusUnauthorized)
+		return
+	}
+	platform, err := t.store.GetLTIPlatform(ctx, settings.PlatformID)
+	if err != nil {
+		t.log.Error("failed to load LTI platform", "error", err, "platform_id", settings.PlatformID)
+		http.Error(w, "Unknown platform", http.StatusUnauthorized)
+		return
+	}
+
+	chosen := r.Form["story_id"]
+	if len(chosen) > 1 && !settings.AcceptMultiple {
+		http.Error(w, "Only one story can be added here", http.StatusBadRequest)
+		return
+	}
+	stories, err := t.store.GetStoriesForCourse(ctx, int(settings.CourseID))
+	if err != nil {
+		t.log.Error("failed to load course stories", "error", err, "course_id", settings.CourseID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	items := []contentItem{}
+	for _, raw := range chosen {
+		storyID, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid story ID", http.StatusBadRequest)
+			return
+		}
+		index := slices.IndexFunc(stories, func(story models.Story) bool {
+			return story.Metadata.StoryID == storyID
+		})
+		if index < 0 {
+			http.Error(w, "Story is not part of the course", http.StatusNotFound)
+			return
+		}
+		label := storyLabel(stories[index])
+		items = append(items, contentItem{
+			Type:   "ltiResourceLink",
+			Title:  label,
+			URL:    t.LaunchURL(),
+			Custom: map[string]string{"story_id": strconv.Itoa(storyID)},
+			LineItem: &lineItem{
+				ScoreMaximum: scoreMaximum,
+				Label:        label,
+				ResourceID:   strconv.Itoa(storyID),
+				Tag:          lineItemTag,
+			},
+		})
+	}
+
+	now := time.Now()
+	response := map[string]any{
+		claimMessageType:  messageDeepLinkResult,
+		claimVersion:      ltiVersion,
+		claimDeploymentID: settings.DeploymentID,
+		claimContentItems: items,
+		"nonce":           randomString(16),
+	}
+	if settings.Data != "" {
+		response[claimDeepLinkingData] = settings.Data
+	}
+	token, err := t.sign(jwt.Claims{
+		Issuer:   platform.ClientID,
+		Audience: jwt.Audience{platform.Issuer},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	}, response)
+	if err != nil {
+		t.log.Error("failed to sign deep linking response", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	t.log.Info("LTI deep linking", "platform_id", platform.ID, "course_id", settings.CourseID, "stories", len(items))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = autoPostTemplate.Execute(w, struct{ Action, JWT string }{settings.ReturnURL, token})
+	if err != nil {
+		t.log.Error("failed to render deep linking response", "error", err)
+	}
+}
+
+// storyLabel names a story the way the gradebook export does
+func storyLabel(story models.Story) string {
+	return fmt.Sprintf("W%d%s %s", story.Metadata.WeekNumber, story.Metadata.DayLetter, story.Metadata.Title["en"])
+}
@@ -0,0 +1,479 @@
+package lti
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"glossias/src/pkg/models"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+const (
+	stateTTL      = 10 * time.Minute // Time allowed between login and launch
+	stateCookie   = "lti_state"
+	keySetTTL     = time.Hour
+	keySetRefetch = time.Minute // Minimum age before an unknown key id refetches a key set
+	clockLeeway   = time.Minute
+)
+
+var errLaunch = errors.New("invalid launch")
+
+// Role prefixes from the LIS vocabulary that make a launch an instructor launch
+var instructorRoles = []string{
+	"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor",
+	"http://purl.imsglobal.org/vocab/lis/v2/membership#Administrator",
+	"http://purl.imsglobal.org/vocab/lis/v2/membership#ContentDeveloper",
+	"http://purl.imsglobal.org/vocab/lis/v2/institution/person#Administrator",
+	"http://purl.imsglobal.org/vocab/lis/v2/system/person#Administrator",
+}
+
+// launchClaims is the id_token of a launch
+type launchClaims struct {
+	jwt.Claims
+	Nonce           string         `json:"nonce"`
+	AuthorizedParty string         `json:"azp"`
+	Email           string         `json:"email"`
+	Name            string         `json:"name"`
+	MessageType     string         `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version         string         `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID    string         `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	Roles           []string       `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	Context         launchContext  `json:"https://purl.imsglobal.org/spec/lti/claim/context"`
+	ResourceLink    resourceLink   `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link"`
+	Custom          map[string]any `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+	Endpoint        *agsEndpoint   `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+	DeepLinking     *deepLinking   `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"`
+}
+
+type launchContext struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Title string `json:"title"`
+}
+
+type resourceLink struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// agsEndpoint tells the tool where grades for the launch's context go
+type agsEndpoint struct {
+	Scope     []string `json:"scope"`
+	LineItems string   `json:"lineitems"`
+	LineItem  string   `json:"lineitem"` // Set when the resource link has its own line item
+}
+
+type deepLinking struct {
+	ReturnURL      string   `json:"deep_link_return_url"`
+	AcceptTypes    []string `json:"accept_types"`
+	AcceptMultiple bool     `json:"accept_multiple"`
+	Title          string   `json:"title"`
+	Data           string   `json:"data"`
+}
+
+// stateClaims tie a launch to the login that started it
+type stateClaims struct {
+	PlatformID int32  `json:"platform_id"`
+	Nonce      string `json:"nonce"`
+}
+
+type cachedKeySet struct {
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// login starts an OIDC third-party initiated login: it finds the platform and sends the
+// browser back to it for an id_token, remembering the nonce in a signed state.
+func (t *Tool) login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid login request", http.StatusBadRequest)
+		return
+	}
+	issuer := r.Form.Get("iss")
+	loginHint := r.Form.Get("login_hint")
+	targetLinkURI := r.Form.Get("target_link_uri")
+	if issuer == "" || loginHint == "" || targetLinkURI == "" {
+		http.Error(w, "iss, login_hint and target_link_uri are required", http.StatusBadRequest)
+		return
+	}
+
+	platform, err := t.store.FindLTIPlatform(r.Context(), issuer, r.Form.Get("client_id"))
+	if err == models.ErrNotFound || err == models.ErrAmbiguousLTIPlatform {
+		t.log.Warn("LTI login from unknown platform", "issuer", issuer, "client_id", r.Form.Get("client_id"), "error", err)
+		http.Error(w, "Unknown platform", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		t.log.Error("failed to find LTI platform", "error", err, "issuer", issuer)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	nonce := randomString(24)
+	state, err := t.sign(t.ownClaims(audienceState, "", stateTTL), stateClaims{PlatformID: platform.ID, Nonce: nonce})
+	if err != nil {
+		t.log.Error("failed to sign LTI state", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Launches usually run in an iframe, so the cookie has to be sent cross-site. Browsers that
+	// block it still launch; the signed state and single-use nonce protect those launches.
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    nonce,
+		Path:     "/lti",
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	query := url.Values{
+		"scope":         {"openid"},
+		"response_type": {"id_token"},
+		"response_mode": {"form_post"},
+		"prompt":        {"none"},
+		"client_id":     {platform.ClientID},
+		"redirect_uri":  {t.LaunchURL()},
+		"login_hint":    {loginHint},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	if hint := r.Form.Get("lti_message_hint"); hint != "" {
+		query.Set("lti_message_hint", hint)
+	}
+	http.Redirect(w, r, withQuery(platform.AuthLoginURL, query), http.StatusFound)
+}
+
+// launch receives the id_token posted by the platform, maps its user and context, and either
+// shows the deep linking picker or sends the browser to the frontend with a session token
+func (t *Tool) launch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid launch", http.StatusBadRequest)
+		return
+	}
+	if errMsg := r.Form.Get("error"); errMsg != "" {
+		t.log.Warn("LTI platform refused login", "error", errMsg, "description", r.Form.Get("error_description"))
+		http.Error(w, "The LMS refused the launch: "+errMsg, http.StatusUnauthorized)
+		return
+	}
+
+	var state stateClaims
+	if err := t.verifyOwn(r.Form.Get("state"), audienceState, &state); err != nil {
+		t.log.Warn("LTI launch with invalid state", "error", err)
+		http.Error(w, "Launch expired, please open it again from the LMS", http.StatusUnauthorized)
+		return
+	}
+	if cookie, err := r.Cookie(stateCookie); err == nil && cookie.Value != state.Nonce {
+		t.log.Warn("LTI launch state does not match the login cookie")
+		http.Error(w, "Launch does not match this browser", http.StatusUnauthorized)
+		return
+	}
+
+	platform, err := t.store.GetLTIPlatform(ctx, state.PlatformID)
+	if err != nil {
+		t.log.Error("failed to load LTI platform", "error", err, "platform_id", state.PlatformID)
+		http.Error(w, "Unknown platform", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := t.validateIDToken(ctx, platform, r.Form.Get("id_token"), state.Nonce)
+	if err != nil {
+		t.log.Warn("LTI launch rejected", "error", err, "platform_id", platform.ID)
+		http.Error(w, "Invalid launch", http.StatusUnauthorized)
+		return
+	}
+
+	instructor := isInstructor(claims.Roles)
+	user, err := t.store.LinkLTIUser(ctx, platform.ID, claims.Subject, claims.Email, claims.Name)
+	if err != nil {
+		t.log.Error("failed to link LTI user", "error", err, "platform_id", platform.ID, "subject", claims.Subject)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if claims.Context.ID == "" {
+		http.Error(w, "Glossias must be launched from within a course", http.StatusBadRequest)
+		return
+	}
+	lineItemsURL := ""
+	if claims.Endpoint != nil {
+		lineItemsURL = claims.Endpoint.LineItems
+	}
+	title := claims.Context.Title
+	if title == "" {
+		title = claims.Context.Label
+	}
+	link, err := t.store.LinkLTIContext(ctx, platform.ID, claims.Context.ID, title, lineItemsURL, instructor)
+	if err == models.ErrNotFound {
+		http.Error(w, "This course is not set up in Glossias yet. Ask your instructor to open Glossias from the course first.", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		t.log.Error("failed to link LTI context", "error", err, "platform_id", platform.ID, "context_id", claims.Context.ID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := t.store.RecordLTIUserContext(ctx, platform.ID, claims.Subject, link.ID); err != nil {
+		t.log.Error("failed to record LTI user context", "error", err, "platform_id", platform.ID, "lti_context_id", link.ID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := t.store.EnrollLTIUser(ctx, link.CourseID, user.UserID, instructor); err != nil {
+		t.log.Error("failed to enroll LTI user", "error", err, "course_id", link.CourseID, "user_id", user.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if claims.MessageType == messageDeepLinking {
+		if !instructor {
+			http.Error(w, "Only instructors can add Glossias stories to a course", http.StatusForbidden)
+			return
+		}
+		t.showStoryPicker(w, r, platform, claims, link)
+		return
+	}
+
+	storyID, err := customStoryID(claims.Custom)
+	if err != nil {
+		http.Error(w, "Invalid story_id custom parameter", http.StatusBadRequest)
+		return
+	}
+	path := "/"
+	if instructor {
+		path = "/admin"
+	}
+	if storyID != 0 {
+		if !t.storyInCourse(ctx, storyID, link.CourseID) {
+			http.Error(w, "This story is not part of the course", http.StatusNotFound)
+			return
+		}
+		if claims.Endpoint != nil && claims.Endpoint.LineItem != "" {
+			if err := t.store.SaveLTILineItem(ctx, link.ID, storyID, claims.Endpoint.LineItem); err != nil {
+				t.log.Error("failed to save LTI line item", "error", err, "lti_context_id", link.ID, "story_id", storyID)
+			}
+		}
+		path = t.storyStartPath(ctx, storyID)
+	}
+
+	session, err := t.issueSession(user.UserID)
+	if err != nil {
+		t.log.Error("failed to issue LTI session", "error", err, "user_id", user.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	t.log.Info("LTI launch", "platform_id", platform.ID, "user_id", user.UserID, "course_id", link.CourseID, "story_id", storyID, "instructor", instructor)
+	// The token travels in the fragment so it stays out of server logs and referrers
+	http.Redirect(w, r, t.frontendURL+path+"#lti_token="+url.QueryEscape(session), http.StatusSeeOther)
+}
+
+// validateIDToken checks a launch id_token against the platform's keys and registration
+func (t *Tool) validateIDToken(ctx context.Context, platform *models.LTIPlatform, idToken, nonce string) (*launchClaims, error) {
+	if idToken == "" {
+		return nil, fmt.Errorf("%w: no id_token", errLaunch)
+	}
+	token, err := jwt.ParseSigned(idToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(token.Headers) != 1 || token.Headers[0].Algorithm != string(jose.RS256) {
+		return nil, fmt.Errorf("%w: id_token must be signed with RS256", errLaunch)
+	}
+	key, err := t.platformKey(ctx, platform.JWKSURL, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims launchClaims
+	if err := token.Claims(key, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Expiry == nil || claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: id_token needs exp and iat", errLaunch)
+	}
+	err = claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   platform.Issuer,
+		Audience: jwt.Audience{platform.ClientID},
+		Time:     time.Now(),
+	}, clockLeeway)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != platform.ClientID {
+		return nil, fmt.Errorf("%w: azp does not match client id", errLaunch)
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", errLaunch)
+	}
+	if !t.useNonce(nonce, claims.Expiry.Time()) {
+		return nil, fmt.Errorf("%w: nonce already used", errLaunch)
+	}
+	if claims.Version != ltiVersion {
+		return nil, fmt.Errorf("%w: unsupported LTI version %q", errLaunch, claims.Version)
+	}
+	if platform.DeploymentID != "" && claims.DeploymentID != platform.DeploymentID {
+		return nil, fmt.Errorf("%w: unknown deployment %q", errLaunch, claims.DeploymentID)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: anonymous launches are not supported", errLaunch)
+	}
+	switch claims.MessageType {
+	case messageResourceLink:
+	case messageDeepLinking:
+		if claims.DeepLinking == nil || claims.DeepLinking.ReturnURL == "" {
+			return nil, fmt.Errorf("%w: deep linking request without settings", errLaunch)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported message type %q", errLaunch, claims.MessageType)
+	}
+	return &claims, nil
+}
+
+// useNonce records a launch nonce and reports whether it was unused
+func (t *Tool) useNonce(nonce string, expiry time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for n, exp := range t.nonces {
+		if now.After(exp.Add(clockLeeway)) {
+			delete(t.nonces, n)
+		}
+	}
+	if _, used := t.nonces[nonce]; used {
+		return false
+	}
+	t.nonces[nonce] = expiry
+	return true
+}
+
+// platformKey returns the platform's signing key with keyID, fetching its key set when
+// it is not cached, is stale, or lacks the key because the platform rotated keys
+func (t *Tool) platformKey(ctx context.Context, jwksURL, keyID string) (*jose.JSONWebKey, error) {
+	t.mu.Lock()
+	cached, ok := t.keySets[jwksURL]
+	t.mu.Unlock()
+
+	age := time.Since(cached.fetchedAt)
+	if ok && age < keySetTTL {
+		if key := findKey(cached.keys, keyID); key != nil {
+			return key, nil
+		}
+		if age < keySetRefetch {
+			return nil, fmt.Errorf("%w: unknown key %q", errLaunch, keyID)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch platform keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch platform keys: status %d", resp.StatusCode)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("decode platform keys: %w", err)
+	}
+
+	t.mu.Lock()
+	t.keySets[jwksURL] = cachedKeySet{keys: keys, fetchedAt: time.Now()}
+	t.mu.Unlock()
+
+	if key := findKey(keys, keyID); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", errLaunch, keyID)
+}
+
+// findKey returns the key with keyID, or the only key of a set when the token names none
+func findKey(set jose.JSONWebKeySet, keyID string) *jose.JSONWebKey {
+	if keyID == "" {
+		if len(set.Keys) == 1 {
+			return &set.Keys[0]
+		}
+		return nil
+	}
+	if keys := set.Key(keyID); len(keys) > 0 {
+		return &keys[0]
+	}
+	return nil
+}
+
+func isInstructor(roles []string) bool {
+	for _, role := range roles {
+		if slices.Contains(instructorRoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// customStoryID reads the story_id custom parameter set by deep linking. Platforms send
+// custom values as strings, but some pass numbers through unchanged.
+func customStoryID(custom map[string]any) (int, error) {
+	switch v := custom["story_id"].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(v)
+	case float64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("story_id has type %T", v)
+	}
+}
+
+// storyInCourse reports whether storyID belongs to courseID
+func (t *Tool) storyInCourse(ctx context.Context, storyID int, courseID int32) bool {
+	stories, err := t.store.GetStoriesForCourse(ctx, int(courseID))
+	if err != nil {
+		t.log.Error("failed to load course stories", "error", err, "course_id", courseID)
+		return false
+	}
+	return slices.ContainsFunc(stories, func(story models.Story) bool {
+		return story.Metadata.StoryID == storyID
+	})
+}
+
+// storyStartPath is the frontend path of the first phase of a story
+func (t *Tool) storyStartPath(ctx context.Context, storyID int) string {
+	phase := models.PhaseVideo
+	flow, err := t.store.GetStoryPhaseFlow(ctx, storyID)
+	if err != nil {
+		t.log.Warn("failed to load phase flow, starting with video", "error", err, "story_id", storyID)
+	} else if len(flow.Phases) > 0 {
+		phase = flow.Phases[0]
+	}
+	return fmt.Sprintf("/stories/%d/%s", storyID, phase)
+}
+
+// withQuery adds query to rawURL, keeping any query it already has
+func withQuery(rawURL string, query url.Values) string {
+	sep := "?"
+	if strings.Contains(rawURL, "?") {
+		sep = "&"
+	}
+	return rawURL + sep + query.Encode()
+}
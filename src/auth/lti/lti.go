@@ -0,0 +1,248 @@
+// Package lti makes Glossias an LTI 1.3 tool. LMS platforms launch it through an OIDC login,
+// instructors pick stories for their LMS course with deep linking, and story scores are posted
+// back to the LMS gradebook through the Assignment and Grade Services (AGS).
+// A launch maps the LMS course to a course and the LMS user to a user, then hands the browser
+// a session token that auth.Middleware accepts in place of a Clerk token.
+package lti
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"glossias/src/pkg/models"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/gorilla/mux"
+)
+
+// Claim names and message types from the LTI 1.3 and Deep Linking 2.0 specifications
+const (
+	claimMessageType      = "https://purl.imsglobal.org/spec/lti/claim/message_type"
+	claimVersion          = "https://purl.imsglobal.org/spec/lti/claim/version"
+	claimDeploymentID     = "https://purl.imsglobal.org/spec/lti/claim/deployment_id"
+	claimContentItems     = "https://purl.imsglobal.org/spec/lti-dl/claim/content_items"
+	claimDeepLinkingData  = "https://purl.imsglobal.org/spec/lti-dl/claim/data"
+	messageResourceLink   = "LtiResourceLinkRequest"
+	messageDeepLinking    = "LtiDeepLinkingRequest"
+	messageDeepLinkResult = "LtiDeepLinkingResponse"
+	ltiVersion            = "1.3.0"
+)
+
+// Audiences of the tokens the tool issues to itself
+const (
+	audienceState    = "glossias-lti-state"
+	audienceSession  = "glossias-lti-session"
+	audienceDeepLink = "glossias-lti-deep-link"
+)
+
+// store is the part of models.Service the tool uses
+type store interface {
+	FindLTIPlatform(ctx context.Context, issuer, clientID string) (*models.LTIPlatform, error)
+	GetLTIPlatform(ctx context.Context, id int32) (*models.LTIPlatform, error)
+	LinkLTIUser(ctx context.Context, platformID int32, subject, email, name string) (*models.User, error)
+	LinkLTIAccount(ctx context.Context, ltiUser, userID string) error
+	RecordLTIUserContext(ctx context.Context, platformID int32, subject string, ltiContextID int32) error
+	LinkLTIContext(ctx context.Context, platformID int32, contextID, title, lineItemsURL string, instructor bool) (*models.LTIContext, error)
+	EnrollLTIUser(ctx context.Context, courseID int32, userID string, instructor bool) error
+	GetLTILineItem(ctx context.Context, ltiContextID int32, storyID int) (string, error)
+	SaveLTILineItem(ctx context.Context, ltiContextID int32, storyID int, lineItemURL string) error
+	GetLTIScoreTargets(ctx context.Context, userID string, storyID int) ([]models.LTIScoreTarget, error)
+	GetStoriesForCourse(ctx context.Context, courseID int) ([]models.Story, error)
+	GetStoryPhaseFlow(ctx context.Context, storyID int) (*models.PhaseFlow, error)
+}
+
+// Config configures a Tool
+type Config struct {
+	BaseURL     string          // Public URL of this server, without a trailing slash
+	FrontendURL string          // Where launches send the browser, without a trailing slash
+	PrivateKey  *rsa.PrivateKey // Signs tool tokens; nil generates a key that lasts until restart
+}
+
+// Tool is the LTI 1.3 tool provider
+type Tool struct {
+	log         *slog.Logger
+	store       store
+	key         *rsa.PrivateKey
+	keyID       string
+	baseURL     string
+	frontendURL string
+	client      *http.Client
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time        // Launch nonces already used, until they expire
+	keySets   map[string]cachedKeySet     // Platform key sets by JWKS URL
+	tokens    map[int32]cachedToken       // AGS access tokens by platform
+	lineItems map[lineItemKey]*sync.Mutex // Serializes line item creation per context and story
+}
+
+// NewTool builds a Tool backed by svc
+func NewTool(log *slog.Logger, svc *models.Service, cfg Config) (*Tool, error) {
+	return newTool(log, svc, cfg)
+}
+
+func newTool(log *slog.Logger, st store, cfg Config) (*Tool, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("lti: base URL is required")
+	}
+	key := cfg.PrivateKey
+	if key == nil {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		log.Warn("LTI private key not configured, using a temporary key; platforms must refetch the JWKS after every restart")
+	}
+	keyID, err := thumbprint(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tool{
+		log:         log,
+		store:       st,
+		key:         key,
+		keyID:       keyID,
+		baseURL:     strings.TrimRight(cfg.BaseURL, "/"),
+		frontendURL: strings.TrimRight(cfg.FrontendURL, "/"),
+		client:      &http.Client{Timeout: 15 * time.Second},
+		nonces:      make(map[string]time.Time),
+		keySets:     make(map[string]cachedKeySet),
+		tokens:      make(map[int32]cachedToken),
+		lineItems:   make(map[lineItemKey]*sync.Mutex),
+	}, nil
+}
+
+// RegisterRoutes mounts the tool endpoints. The caller mounts r at /lti, which is the
+// prefix of the URLs given to platforms.
+func (t *Tool) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/jwks", t.jwks).Methods("GET")
+	r.HandleFunc("/login", t.login).Methods("GET", "POST")
+	r.HandleFunc("/launch", t.launch).Methods("POST")
+	r.HandleFunc("/deep-link", t.deepLink).Methods("POST")
+}
+
+// RegisterAPIRoutes mounts the endpoints for signed-in users. The caller mounts r at /api
+// behind auth.Middleware.
+func (t *Tool) RegisterAPIRoutes(r *mux.Router) {
+	r.HandleFunc("/lti/link", t.linkAccount).Methods("POST")
+}
+
+// LoginURL is the OIDC login initiation URL platforms are configured with
+func (t *Tool) LoginURL() string { return t.baseURL + "/lti/login" }
+
+// LaunchURL is the redirect URL platforms post launches to
+func (t *Tool) LaunchURL() string { return t.baseURL + "/lti/launch" }
+
+// JWKSURL is where platforms fetch the tool's public key
+func (t *Tool) JWKSURL() string { return t.baseURL + "/lti/jwks" }
+
+// LoadPrivateKey parses a PEM encoded RSA private key in PKCS #1 or PKCS #8 form
+func LoadPrivateKey(pemData string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, errors.New("lti: no PEM block in private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("lti: parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("lti: private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// jwks publishes the tool's public key so platforms can check its messages and client assertions
+func (t *Tool) jwks(w http.ResponseWriter, r *http.Request) {
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &t.key.PublicKey,
+		KeyID:     t.keyID,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(set)
+}
+
+// sign serializes claims as a JWT signed with the tool key
+func (t *Tool) sign(claims ...any) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: t.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", t.keyID),
+	)
+	if err != nil {
+		return "", err
+	}
+	builder := jwt.Signed(signer)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+	return builder.CompactSerialize()
+}
+
+// verifyOwn checks a token the tool issued to itself for audience and fills dest
+func (t *Tool) verifyOwn(token, audience string, dest ...any) error {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return err
+	}
+	var claims jwt.Claims
+	if err := parsed.Claims(&t.key.PublicKey, append([]any{&claims}, dest...)...); err != nil {
+		return err
+	}
+	if claims.Expiry == nil {
+		return errors.New("lti: token has no expiry")
+	}
+	return claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   t.baseURL,
+		Audience: jwt.Audience{audience},
+		Time:     time.Now(),
+	}, 0)
+}
+
+// ownClaims are the registered claims of a token the tool issues to itself
+func (t *Tool) ownClaims(audience, subject string, ttl time.Duration) jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:   t.baseURL,
+		Subject:  subject,
+		Audience: jwt.Audience{audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+func thumbprint(key *rsa.PublicKey) (string, error) {
+	sum, err := (&jose.JSONWebKey{Key: key}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sum), nil
+}
+
+// randomString returns n random bytes, URL-safe encoded
+func randomString(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,526 @@
+package lti
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"glossias/src/auth"
+	"glossias/src/auth/lti/mockplatform"
+	"glossias/src/pkg/models"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/gorilla/mux"
+)
+
+// fakeStore keeps the LTI mappings in memory for one platform and one course
+type fakeStore struct {
+	mu        sync.Mutex
+	platform  models.LTIPlatform
+	stories   []models.Story
+	users     map[string]string         // Platform subject to user ID
+	launched  map[string]map[int32]bool // Platform subject to the contexts launched from
+	contexts  map[string]*models.LTIContext
+	admins    map[string]bool
+	students  map[string]bool
+	lineItems map[[2]int]string
+}
+
+func newFakeStore(platform models.LTIPlatform, stories ...models.Story) *fakeStore {
+	return &fakeStore{
+		platform:  platform,
+		stories:   stories,
+		users:     make(map[string]string),
+		launched:  make(map[string]map[int32]bool),
+		contexts:  make(map[string]*models.LTIContext),
+		admins:    make(map[string]bool),
+		students:  make(map[string]bool),
+		lineItems: make(map[[2]int]string),
+	}
+}
+
+func (f *fakeStore) FindLTIPlatform(ctx context.Context, issuer, clientID string) (*models.LTIPlatform, error) {
+	if issuer != f.platform.Issuer || (clientID != "" && clientID != f.platform.ClientID) {
+		return nil, models.ErrNotFound
+	}
+	return &f.platform, nil
+}
+
+func (f *fakeStore) GetLTIPlatform(ctx context.Context, id int32) (*models.LTIPlatform, error) {
+	if id != f.platform.ID {
+		return nil, models.ErrNotFound
+	}
+	return &f.platform, nil
+}
+
+func (f *fakeStore) LinkLTIUser(ctx context.Context, platformID int32, subject, email, name string) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[subject] = "user-" + subject
+	return &models.User{UserID: "user-" + subject, Email: email, Name: name}, nil
+}
+
+func (f *fakeStore) LinkLTIAccount(ctx context.Context, ltiUser, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	linked := false
+	for subject, id := range f.users {
+		if id == ltiUser {
+			f.users[subject] = userID
+			linked = true
+		}
+	}
+	if !linked {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (f *fakeStore) RecordLTIUserContext(ctx context.Context, platformID int32, subject string, ltiContextID int32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.launched[subject] == nil {
+		f.launched[subject] = make(map[int32]bool)
+	}
+	f.launched[subject][ltiContextID] = true
+	return nil
+}
+
+func (f *fakeStore) LinkLTIContext(ctx context.Context, platformID int32, contextID, title, lineItemsURL string, instructor bool) (*models.LTIContext, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	link, ok := f.contexts[contextID]
+	if !ok {
+		if !instructor {
+			return nil, models.ErrNotFound
+		}
+		link = &models.LTIContext{ID: int32(len(f.contexts) + 1), PlatformID: platformID, ContextID: contextID, CourseID: 1}
+		f.contexts[contextID] = link
+	}
+	link.Title = title
+	if lineItemsURL != "" {
+		link.LineItemsURL = lineItemsURL
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (f *fakeStore) EnrollLTIUser(ctx context.Context, courseID int32, userID string, instructor bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if instructor {
+		f.admins[userID] = true
+	} else {
+		f.students[userID] = true
+	}
+	return nil
+}
+
+func (f *fakeStore) GetLTILineItem(ctx context.Context, ltiContextID int32, storyID int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.lineItems[[2]int{int(ltiContextID), storyID}]
+	if !ok {
+		return "", models.ErrNotFound
+	}
+	return item, nil
+}
+
+func (f *fakeStore) SaveLTILineItem(ctx context.Context, ltiContextID int32, storyID int, lineItemURL string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lineItems[[2]int{int(ltiContextID), storyID}] = lineItemURL
+	return nil
+}
+
+func (f *fakeStore) GetLTIScoreTargets(ctx context.Context, userID string, storyID int) ([]models.LTIScoreTarget, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var targets []models.LTIScoreTarget
+	for subject, id := range f.users {
+		if id != userID {
+			continue
+		}
+		for _, link := range f.contexts {
+			if link.LineItemsURL != "" && f.launched[subject][link.ID] {
+				targets = append(targets, models.LTIScoreTarget{
+					PlatformID:   link.PlatformID,
+					Subject:      subject,
+					LTIContextID: link.ID,
+					CourseID:     link.CourseID,
+					LineItemsURL: link.LineItemsURL,
+				})
+			}
+		}
+	}
+	return targets, nil
+}
+
+func (f *fakeStore) GetStoriesForCourse(ctx context.Context, courseID int) ([]models.Story, error) {
+	return f.stories, nil
+}
+
+func (f *fakeStore) GetStoryPhaseFlow(ctx context.Context, storyID int) (*models.PhaseFlow, error) {
+	return &models.PhaseFlow{Phases: models.DefaultPhaseFlow, Source: models.PhaseFlowSourceDefault}, nil
+}
+
+func testStory(id, week int, day, title string) models.Story {
+	return models.Story{Metadata: models.StoryMetadata{
+		StoryID:    id,
+		WeekNumber: week,
+		DayLetter:  day,
+		Title:      map[string]string{"en": title},
+	}}
+}
+
+// ltiTestEnv runs the tool and the mock platform on test servers
+type ltiTestEnv struct {
+	tool     *Tool
+	store    *fakeStore
+	platform *mockplatform.Platform
+	frontend string
+}
+
+func newLTITestEnv(t *testing.T) *ltiTestEnv {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+
+	// The platform needs the tool's URLs and the tool's store needs the platform's,
+	// so both servers start before either handler exists
+	var toolHandler, platformHandler http.Handler
+	toolServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { toolHandler.ServeHTTP(w, r) }))
+	t.Cleanup(toolServer.Close)
+	platformServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { platformHandler.ServeHTTP(w, r) }))
+	t.Cleanup(platformServer.Close)
+
+	env := &ltiTestEnv{frontend: "https://app.glossias.test"}
+	var err error
+	env.platform, err = mockplatform.New(platformServer.URL, "client-1", "deployment-1", mockplatform.Tool{
+		LoginURL:  toolServer.URL + "/lti/login",
+		LaunchURL: toolServer.URL + "/lti/launch",
+		JWKSURL:   toolServer.URL + "/lti/jwks",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	platformHandler = env.platform.Handler()
+
+	env.store = newFakeStore(models.LTIPlatform{
+		ID:           1,
+		Name:         "Mock LMS",
+		Issuer:       env.platform.Issuer,
+		ClientID:     env.platform.ClientID,
+		DeploymentID: env.platform.DeploymentID,
+		AuthLoginURL: env.platform.AuthLoginURL(),
+		AuthTokenURL: env.platform.AuthTokenURL(),
+		JWKSURL:      env.platform.JWKSURL(),
+	}, testStory(7, 1, "A", "La casa"), testStory(8, 1, "B", "El perro"))
+
+	env.tool, err = newTool(logger, env.store, Config{BaseURL: toolServer.URL, FrontendURL: env.frontend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := mux.NewRouter()
+	env.tool.RegisterRoutes(r.PathPrefix("/lti").Subrouter())
+	toolHandler = r
+	return env
+}
+
+// browser follows redirects and submits forms like a browser with scripts enabled,
+// stopping at the frontend
+func (env *ltiTestEnv) browser(t *testing.T) *http.Client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if strings.HasPrefix(req.URL.String(), env.frontend) {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+var (
+	formAction  = regexp.MustCompile(`<form method="post" action="([^"]+)">`)
+	hiddenInput = regexp.MustCompile(`<input type="hidden" name="([^"]+)" value="([^"]*)">`)
+)
+
+// submitForm posts the first form of an HTML page, adding extra fields
+func submitForm(t *testing.T, client *http.Client, page string, extra url.Values) *http.Response {
+	t.Helper()
+	action := formAction.FindStringSubmatch(page)
+	if action == nil {
+		t.Fatalf("no form in page:\n%s", page)
+	}
+	form := url.Values{}
+	for _, input := range hiddenInput.FindAllStringSubmatch(page, -1) {
+		form.Set(input[1], input[2])
+	}
+	for k, v := range extra {
+		form[k] = v
+	}
+	resp, err := client.PostForm(action[1], form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func readPage(t *testing.T, resp *http.Response, wantStatus int) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, wantStatus, body)
+	}
+	return string(body)
+}
+
+// launch runs a launch up to the tool's response
+func (env *ltiTestEnv) launch(t *testing.T, client *http.Client, launch mockplatform.Launch) *http.Response {
+	t.Helper()
+	resp, err := client.Get(env.platform.StartURL(launch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authPage := readPage(t, resp, http.StatusOK)
+	return submitForm(t, client, authPage, nil)
+}
+
+// sessionFromRedirect checks a launch sent the browser to path and returns its session token
+func (env *ltiTestEnv) sessionFromRedirect(t *testing.T, resp *http.Response, path string) string {
+	t.Helper()
+	readPage(t, resp, http.StatusSeeOther)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != env.frontend+path {
+		t.Fatalf("redirected to %s, want %s", got, env.frontend+path)
+	}
+	fragment, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fragment.Get("lti_token")
+}
+
+func TestLaunchDeepLinkingAndPassback(t *testing.T) {
+	env := newLTITestEnv(t)
+	instructor := env.browser(t)
+	student := env.browser(t)
+
+	// A student cannot be the first to launch from a course
+	resp := env.launch(t, student, mockplatform.Launch{User: mockplatform.Student, ContextID: "spanish-101"})
+	readPage(t, resp, http.StatusForbidden)
+
+	// The instructor adds story 7 through deep linking
+	resp = env.launch(t, instructor, mockplatform.Launch{User: mockplatform.Instructor, ContextID: "spanish-101", ContextTitle: "Spanish 101", DeepLinking: true})
+	picker := readPage(t, resp, http.StatusOK)
+	if !strings.Contains(picker, "W1A La casa") || !strings.Contains(picker, "W1B El perro") {
+		t.Fatalf("picker does not list the course stories:\n%s", picker)
+	}
+	resp = submitForm(t, instructor, picker, url.Values{"story_id": {"7"}})
+	returnPage := readPage(t, resp, http.StatusOK)
+	resp = submitForm(t, instructor, returnPage, nil)
+	readPage(t, resp, http.StatusOK)
+
+	items := env.platform.ContentItems()
+	if len(items) != 1 || items[0].Custom["story_id"] != "7" || items[0].URL != env.tool.LaunchURL() {
+		t.Fatalf("content items = %+v", items)
+	}
+	lineItems := env.platform.LineItems()
+	if len(lineItems) != 1 || lineItems[0].ResourceID != "7" || lineItems[0].Label != "W1A La casa" {
+		t.Fatalf("line items = %+v", lineItems)
+	}
+	if !env.store.admins["user-instructor-1"] {
+		t.Error("instructor was not made a course admin")
+	}
+
+	// The student launches story 7 and gets a session for the API
+	resp = env.launch(t, student, mockplatform.Launch{User: mockplatform.Student, ContextID: "spanish-101", StoryID: 7})
+	session := env.sessionFromRedirect(t, resp, "/stories/7/video")
+	userID, err := env.tool.VerifySession(session)
+	if err != nil || userID != "user-student-1" {
+		t.Fatalf("VerifySession = %q, %v", userID, err)
+	}
+	if !env.store.students["user-student-1"] {
+		t.Error("student was not enrolled")
+	}
+
+	// Scores go to the line item from deep linking, and a story without one gets a new line item
+	ctx := context.Background()
+	computedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, score := range []models.StoryScore{
+		{UserID: "user-student-1", StoryID: 7, OverallAccuracy: 85.5, ComputedAt: computedAt},
+		{UserID: "user-student-1", StoryID: 8, OverallAccuracy: 40, ComputedAt: computedAt},
+		{UserID: "user-student-1", StoryID: 8, OverallAccuracy: 60, ComputedAt: computedAt.Add(time.Hour)},
+	} {
+		if err := env.tool.PublishScore(ctx, score); err != nil {
+			t.Fatalf("PublishScore(story %d): %v", score.StoryID, err)
+		}
+	}
+
+	lineItems = env.platform.LineItems()
+	if len(lineItems) != 2 || lineItems[1].ResourceID != "8" || lineItems[1].Label != "W1B El perro" {
+		t.Fatalf("line items = %+v", lineItems)
+	}
+	scores := env.platform.Scores()
+	if len(scores) != 3 {
+		t.Fatalf("got %d scores, want 3", len(scores))
+	}
+	want := []struct {
+		lineItem string
+		given    float64
+	}{{lineItems[0].ID, 85.5}, {lineItems[1].ID, 40}, {lineItems[1].ID, 60}}
+	for i, score := range scores {
+		if score.LineItemID != want[i].lineItem || score.ScoreGiven != want[i].given || score.UserID != "student-1" ||
+			score.ScoreMaximum != 100 || score.GradingProgress != "FullyGraded" {
+			t.Errorf("score %d = %+v, want %.1f on %s", i, score, want[i].given, want[i].lineItem)
+		}
+	}
+}
+
+func TestLaunchRejectsReplayAndForgery(t *testing.T) {
+	env := newLTITestEnv(t)
+	instructor := env.browser(t)
+
+	resp, err := instructor.Get(env.platform.StartURL(mockplatform.Launch{User: mockplatform.Instructor, ContextID: "c1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authPage := readPage(t, resp, http.StatusOK)
+	resp = submitForm(t, instructor, authPage, nil)
+	env.sessionFromRedirect(t, resp, "/admin")
+
+	// The same id_token cannot launch twice
+	resp = submitForm(t, instructor, authPage, nil)
+	readPage(t, resp, http.StatusUnauthorized)
+
+	// A token signed by another platform key is rejected
+	forger, err := mockplatform.New(env.platform.Issuer, env.platform.ClientID, env.platform.DeploymentID, mockplatform.Tool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = instructor.Get(env.platform.StartURL(mockplatform.Launch{User: mockplatform.Instructor, ContextID: "c1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authPage = readPage(t, resp, http.StatusOK)
+	state := hiddenInput.FindAllStringSubmatch(authPage, -1)[1][2]
+	parsed, err := jwt.ParseSigned(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims stateClaims
+	if err := parsed.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		t.Fatal(err)
+	}
+	forged, err := forger.IDToken(mockplatform.Launch{User: mockplatform.Instructor, ContextID: "c1"}, claims.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = instructor.PostForm(env.tool.LaunchURL(), url.Values{"id_token": {forged}, "state": {state}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readPage(t, resp, http.StatusUnauthorized)
+}
+
+func TestVerifySessionRejectsOtherTokens(t *testing.T) {
+	env := newLTITestEnv(t)
+	state, err := env.tool.sign(env.tool.ownClaims(audienceState, "user-1", time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := env.tool.sign(env.tool.ownClaims(audienceSession, "user-1", -time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"state token": state, "expired": expired, "garbage": "not-a-jwt"} {
+		if userID, err := env.tool.VerifySession(token); err == nil {
+			t.Errorf("%s: VerifySession = %q, want error", name, userID)
+		}
+	}
+}
+
+func TestLinkAccountNeedsBothIdentities(t *testing.T) {
+	env := newLTITestEnv(t)
+	env.store.users["sub-1"] = "lti_1_sub-1"
+	session, err := env.tool.issueSession("lti_1_sub-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := func(userID, token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/lti/link", strings.NewReader(`{"lti_token":"`+token+`"}`))
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, userID))
+		}
+		rec := httptest.NewRecorder()
+		env.tool.linkAccount(rec, req)
+		return rec.Code
+	}
+
+	if code := link("", session); code != http.StatusUnauthorized {
+		t.Errorf("without a signed-in user: status %d, want 401", code)
+	}
+	if code := link("user_clerk", "not-a-jwt"); code != http.StatusUnauthorized {
+		t.Errorf("without a launch session: status %d, want 401", code)
+	}
+	if code := link("user_clerk", session); code != http.StatusOK {
+		t.Fatalf("status %d, want 200", code)
+	}
+	if got := env.store.users["sub-1"]; got != "user_clerk" {
+		t.Errorf("platform user maps to %q, want user_clerk", got)
+	}
+}
+
+func TestScoresURL(t *testing.T) {
+	tests := map[string]string{
+		"https://lms.test/lineitems/3":            "https://lms.test/lineitems/3/scores",
+		"https://lms.test/lineitems/3/":           "https://lms.test/lineitems/3/scores",
+		"https://lms.test/lineitems/3?type=story": "https://lms.test/lineitems/3/scores?type=story",
+	}
+	for in, want := range tests {
+		if got := scoresURL(in); got != want {
+			t.Errorf("scoresURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCustomStoryID(t *testing.T) {
+	tests := []struct {
+		custom  map[string]any
+		want    int
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{map[string]any{"story_id": "12"}, 12, false},
+		{map[string]any{"story_id": float64(5)}, 5, false},
+		{map[string]any{"story_id": ""}, 0, false},
+		{map[string]any{"story_id": "abc"}, 0, true},
+		{map[string]any{"story_id": true}, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := customStoryID(tt.custom)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("customStoryID(%v) = %d, %v", tt.custom, got, err)
+		}
+	}
+}
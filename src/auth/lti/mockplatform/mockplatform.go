@@ -0,0 +1,551 @@
+// Package mockplatform is a small LTI 1.3 platform for trying out and testing the Glossias tool
+// without an LMS. It launches the tool as preset users, receives deep linking responses, and
+// runs the AGS token, line items and scores services, keeping everything in memory.
+package mockplatform
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// LIS roles used by the preset users
+const (
+	RoleInstructor = "http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"
+	RoleLearner    = "http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"
+)
+
+// Tool describes the tool the platform launches
+type Tool struct {
+	LoginURL  string
+	LaunchURL string
+	JWKSURL   string
+}
+
+// User is a platform user
+type User struct {
+	Subject string
+	Name    string
+	Email   string
+	Roles   []string
+}
+
+// Launch describes one launch of the tool
+type Launch struct {
+	User         User
+	ContextID    string
+	ContextTitle string
+	StoryID      int  // Sent as the story_id custom parameter, like a link made by deep linking
+	DeepLinking  bool // Ask the tool for links instead of launching it
+}
+
+// LineItem is a gradebook column
+type LineItem struct {
+	ID           string  `json:"id"`
+	ScoreMaximum float64 `json:"scoreMaximum"`
+	Label        string  `json:"label"`
+	ResourceID   string  `json:"resourceId,omitempty"`
+	Tag          string  `json:"tag,omitempty"`
+	ContextID    string  `json:"-"`
+}
+
+// Score is a result the tool posted to a line item
+type Score struct {
+	LineItemID       string  `json:"-"`
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+// ContentItem is a link the tool returned from deep linking
+type ContentItem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	URL      string            `json:"url"`
+	Custom   map[string]string `json:"custom"`
+	LineItem *LineItem         `json:"lineItem"`
+}
+
+// Preset users for launching from a browser through /start
+var (
+	Instructor = User{Subject: "instructor-1", Name: "Ivy Instructor", Email: "instructor@mock.lms", Roles: []string{RoleInstructor}}
+	Student    = User{Subject: "student-1", Name: "Sam Student", Email: "student@mock.lms", Roles: []string{RoleLearner}}
+)
+
+// Platform is the mock LMS
+type Platform struct {
+	Issuer       string // URL the platform handler is served at, without a trailing slash
+	ClientID     string
+	DeploymentID string
+	tool         Tool
+
+	key    *rsa.PrivateKey
+	keyID  string
+	client *http.Client
+
+	mu           sync.Mutex
+	launches     map[string]Launch // Pending launches by lti_message_hint
+	tokens       map[string]time.Time
+	lineItems    []LineItem
+	scores       []Score
+	contentItems []ContentItem
+}
+
+// New builds a platform served at issuer that launches tool
+func New(issuer, clientID, deploymentID string, tool Tool) (*Platform, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := (&jose.JSONWebKey{Key: &key.PublicKey}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &Platform{
+		Issuer:       strings.TrimRight(issuer, "/"),
+		ClientID:     clientID,
+		DeploymentID: deploymentID,
+		tool:         tool,
+		key:          key,
+		keyID:        base64.RawURLEncoding.EncodeToString(sum),
+		client:       &http.Client{Timeout: 10 * time.Second},
+		launches:     make(map[string]Launch),
+		tokens:       make(map[string]time.Time),
+	}, nil
+}
+
+// AuthLoginURL is the OIDC authorization endpoint the tool registers the platform with
+func (p *Platform) AuthLoginURL() string { return p.Issuer + "/auth" }
+
+// AuthTokenURL is the OAuth 2 token endpoint for AGS access tokens
+func (p *Platform) AuthTokenURL() string { return p.Issuer + "/token" }
+
+// JWKSURL is where the tool fetches the platform's public key
+func (p *Platform) JWKSURL() string { return p.Issuer + "/jwks" }
+
+// Handler serves the platform. Mount it so that it is reachable at Issuer.
+func (p *Platform) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /start", p.start)
+	mux.HandleFunc("GET /auth", p.auth)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("POST /deep-link-return", p.deepLinkReturn)
+	mux.HandleFunc("GET /contexts/{context}/lineitems", p.listLineItems)
+	mux.HandleFunc("POST /contexts/{context}/lineitems", p.createLineItem)
+	mux.HandleFunc("POST /contexts/{context}/lineitems/{item}/scores", p.postScore)
+	return mux
+}
+
+// StartURL returns the tool login URL that begins launch, like following a link in the LMS
+func (p *Platform) StartURL(launch Launch) string {
+	hint := randomString()
+	p.mu.Lock()
+	p.launches[hint] = launch
+	p.mu.Unlock()
+
+	query := url.Values{
+		"iss":               {p.Issuer},
+		"login_hint":        {launch.User.Subject},
+		"target_link_uri":   {p.tool.LaunchURL},
+		"lti_message_hint":  {hint},
+		"client_id":         {p.ClientID},
+		"lti_deployment_id": {p.DeploymentID},
+	}
+	return p.tool.LoginURL + "?" + query.Encode()
+}
+
+// LineItems returns the line items created so far
+func (p *Platform) LineItems() []LineItem {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.lineItems)
+}
+
+// Scores returns the scores posted so far, oldest first
+func (p *Platform) Scores() []Score {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.scores)
+}
+
+// ContentItems returns the links received through deep linking
+func (p *Platform) ContentItems() []ContentItem {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.contentItems)
+}
+
+// start launches the tool from a browser: /start?user=instructor|student&context=c1&story=3&deep_linking=1
+func (p *Platform) start(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	user := Student
+	if q.Get("user") == "instructor" {
+		user = Instructor
+	}
+	storyID, _ := strconv.Atoi(q.Get("story"))
+	contextID := q.Get("context")
+	if contextID == "" {
+		contextID = "mock-course"
+	}
+	http.Redirect(w, r, p.StartURL(Launch{
+		User:         user,
+		ContextID:    contextID,
+		ContextTitle: "Mock course " + contextID,
+		StoryID:      storyID,
+		DeepLinking:  q.Get("deep_linking") != "",
+	}), http.StatusFound)
+}
+
+// auth is the OIDC authorization endpoint: it answers the tool's login redirect by
+// posting an id_token for the pending launch to the tool
+func (p *Platform) auth(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.ClientID || q.Get("response_type") != "id_token" || q.Get("scope") != "openid" {
+		http.Error(w, "invalid authentication request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("redirect_uri") != p.tool.LaunchURL {
+		http.Error(w, "unregistered redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	launch, ok := p.launches[q.Get("lti_message_hint")]
+	delete(p.launches, q.Get("lti_message_hint"))
+	p.mu.Unlock()
+	if !ok || launch.User.Subject != q.Get("login_hint") {
+		http.Error(w, "unknown launch", http.StatusBadRequest)
+		return
+	}
+
+	idToken, err := p.IDToken(launch, q.Get("nonce"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	formPostTemplate.Execute(w, map[string]string{
+		"Action":  p.tool.LaunchURL,
+		"IDToken": idToken,
+		"State":   q.Get("state"),
+	})
+}
+
+// IDToken signs the id_token of a launch
+func (p *Platform) IDToken(launch Launch, nonce string) (string, error) {
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   p.Issuer,
+		"sub":   launch.User.Subject,
+		"aud":   p.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+		"name":  launch.User.Name,
+		"email": launch.User.Email,
+		"https://purl.imsglobal.org/spec/lti/claim/version":       "1.3.0",
+		"https://purl.imsglobal.org/spec/lti/claim/deployment_id": p.DeploymentID,
+		"https://purl.imsglobal.org/spec/lti/claim/roles":         launch.User.Roles,
+		"https://purl.imsglobal.org/spec/lti/claim/context": map[string]any{
+			"id":    launch.ContextID,
+			"title": launch.ContextTitle,
+		},
+	}
+
+	if launch.DeepLinking {
+		claims["https://purl.imsglobal.org/spec/lti/claim/message_type"] = "LtiDeepLinkingRequest"
+		claims["https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"] = map[string]any{
+			"deep_link_return_url": p.Issuer + "/deep-link-return",
+			"accept_types":         []string{"ltiResourceLink"},
+			"accept_multiple":      true,
+			"data":                 launch.ContextID,
+		}
+	} else {
+		claims["https://purl.imsglobal.org/spec/lti/claim/message_type"] = "LtiResourceLinkRequest"
+		claims["https://purl.imsglobal.org/spec/lti/claim/resource_link"] = map[string]any{
+			"id": fmt.Sprintf("%s-story-%d", launch.ContextID, launch.StoryID),
+		}
+		endpoint := map[string]any{
+			"scope": []string{
+				"https://purl.imsglobal.org/spec/lti-ags/scope/lineitem",
+				"https://purl.imsglobal.org/spec/lti-ags/scope/score",
+			},
+			"lineitems": p.lineItemsURL(launch.ContextID),
+		}
+		if launch.StoryID != 0 {
+			claims["https://purl.imsglobal.org/spec/lti/claim/custom"] = map[string]string{
+				"story_id": strconv.Itoa(launch.StoryID),
+			}
+			if item := p.findLineItem(launch.ContextID, strconv.Itoa(launch.StoryID)); item != nil {
+				endpoint["lineitem"] = item.ID
+			}
+		}
+		claims["https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"] = endpoint
+	}
+	return p.sign(claims)
+}
+
+func (p *Platform) jwks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &p.key.PublicKey,
+		KeyID:     p.keyID,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+// token grants AGS access tokens for client assertions signed with the tool's key
+func (p *Platform) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, "invalid_request")
+		return
+	}
+	if r.Form.Get("grant_type") != "client_credentials" ||
+		r.Form.Get("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" {
+		oauthError(w, "unsupported_grant_type")
+		return
+	}
+
+	var claims jwt.Claims
+	if err := p.verifyToolToken(r.Form.Get("client_assertion"), &claims); err != nil {
+		oauthError(w, "invalid_client")
+		return
+	}
+	err := claims.Validate(jwt.Expected{
+		Issuer:   p.ClientID,
+		Subject:  p.ClientID,
+		Audience: jwt.Audience{p.AuthTokenURL()},
+	})
+	if err != nil || claims.Expiry == nil {
+		oauthError(w, "invalid_client")
+		return
+	}
+
+	token := randomString()
+	p.mu.Lock()
+	p.tokens[token] = time.Now().Add(time.Hour)
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"scope":        r.Form.Get("scope"),
+	})
+}
+
+// deepLinkReturn receives the tool's deep linking response and adds its links to the course,
+// creating the line items they ask for
+func (p *Platform) deepLinkReturn(w http.ResponseWriter, r *http.Request) {
+	var response struct {
+		jwt.Claims
+		MessageType  string        `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+		ContentItems []ContentItem `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+		Data         string        `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data"`
+	}
+	if err := p.verifyToolToken(r.FormValue("JWT"), &response); err != nil {
+		http.Error(w, "invalid deep linking response: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	err := response.Validate(jwt.Expected{Issuer: p.ClientID, Audience: jwt.Audience{p.Issuer}})
+	if err != nil || response.MessageType != "LtiDeepLinkingResponse" {
+		http.Error(w, "invalid deep linking response", http.StatusBadRequest)
+		return
+	}
+
+	contextID := response.Data
+	p.mu.Lock()
+	for _, item := range response.ContentItems {
+		p.contentItems = append(p.contentItems, item)
+		if item.LineItem != nil {
+			p.addLineItem(contextID, *item.LineItem)
+		}
+	}
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<p>Added %d links to %s.</p>", len(response.ContentItems), template.HTMLEscapeString(contextID))
+}
+
+func (p *Platform) listLineItems(w http.ResponseWriter, r *http.Request) {
+	if !p.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	contextID := r.PathValue("context")
+	resourceID := r.URL.Query().Get("resource_id")
+
+	p.mu.Lock()
+	items := []LineItem{}
+	for _, item := range p.lineItems {
+		if item.ContextID == contextID && (resourceID == "" || item.ResourceID == resourceID) {
+			items = append(items, item)
+		}
+	}
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/vnd.ims.lis.v2.lineitemcontainer+json")
+	json.NewEncoder(w).Encode(items)
+}
+
+func (p *Platform) createLineItem(w http.ResponseWriter, r *http.Request) {
+	if !p.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var item LineItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil || item.Label == "" || item.ScoreMaximum <= 0 {
+		http.Error(w, "invalid line item", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	item = p.addLineItem(r.PathValue("context"), item)
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/vnd.ims.lis.v2.lineitem+json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+func (p *Platform) postScore(w http.ResponseWriter, r *http.Request) {
+	if !p.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("Content-Type") != "application/vnd.ims.lis.v1.score+json" {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	var score Score
+	if err := json.NewDecoder(r.Body).Decode(&score); err != nil || score.UserID == "" || score.Timestamp == "" {
+		http.Error(w, "invalid score", http.StatusBadRequest)
+		return
+	}
+	score.LineItemID = p.lineItemsURL(r.PathValue("context")) + "/" + r.PathValue("item")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !slices.ContainsFunc(p.lineItems, func(item LineItem) bool { return item.ID == score.LineItemID }) {
+		http.Error(w, "unknown line item", http.StatusNotFound)
+		return
+	}
+	p.scores = append(p.scores, score)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addLineItem must be called with the lock held
+func (p *Platform) addLineItem(contextID string, item LineItem) LineItem {
+	item.ContextID = contextID
+	item.ID = fmt.Sprintf("%s/%d", p.lineItemsURL(contextID), len(p.lineItems)+1)
+	p.lineItems = append(p.lineItems, item)
+	return item
+}
+
+func (p *Platform) findLineItem(contextID, resourceID string) *LineItem {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range p.lineItems {
+		if item.ContextID == contextID && item.ResourceID == resourceID {
+			return &item
+		}
+	}
+	return nil
+}
+
+func (p *Platform) lineItemsURL(contextID string) string {
+	return p.Issuer + "/contexts/" + url.PathEscape(contextID) + "/lineitems"
+}
+
+// authorized checks the request carries an access token from the token endpoint
+func (p *Platform) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	expires, ok := p.tokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+// verifyToolToken checks a JWT against the tool's published keys
+func (p *Platform) verifyToolToken(token string, dest ...any) error {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Get(p.tool.JWKSURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var keys jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return err
+	}
+	if len(parsed.Headers) != 1 {
+		return fmt.Errorf("expected one signature")
+	}
+	matches := keys.Key(parsed.Headers[0].KeyID)
+	if len(matches) == 0 {
+		return fmt.Errorf("unknown key %q", parsed.Headers[0].KeyID)
+	}
+	return parsed.Claims(matches[0].Key, dest...)
+}
+
+func (p *Platform) sign(claims any) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: p.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", p.keyID),
+	)
+	if err != nil {
+		return "", err
+	}
+	return jwt.Signed(signer).Claims(claims).CompactSerialize()
+}
+
+func oauthError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func randomString() string {
+	b := make([]byte, 18)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+var formPostTemplate = template.Must(template.New("post").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Launching</title></head>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="id_token" value="{{.IDToken}}">
+<input type="hidden" name="state" value="{{.State}}">
+<noscript><button type="submit">Continue</button></noscript>
+</form>
+</body>
+</html>
+`))
@@ -0,0 +1,72 @@
+package lti
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"glossias/src/auth"
+	"glossias/src/pkg/models"
+)
+
+// sessionTTL bounds how long a launch keeps the user signed in. The LMS launches
+// again whenever the user comes back through it.
+const sessionTTL = 8 * time.Hour
+
+// issueSession returns a bearer token that identifies userID to the API
+func (t *Tool) issueSession(userID string) (string, error) {
+	return t.sign(t.ownClaims(audienceSession, userID, sessionTTL))
+}
+
+// VerifySession returns the user of a session token issued by a launch.
+// It makes the Tool an auth.SessionVerifier.
+func (t *Tool) VerifySession(token string) (string, error) {
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := t.verifyOwn(token, audienceSession, &claims); err != nil {
+		return "", err
+	}
+	if claims.Subject == "" {
+		return "", errors.New("lti: session has no user")
+	}
+	return claims.Subject, nil
+}
+
+// linkAccount moves the platform user of a launch session to the signed-in Clerk user,
+// so later launches sign in as that user. Both sides have to prove who they are: the
+// request is authenticated with Clerk and carries the session token of the launch.
+func (t *Tool) linkAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDWithOk(r)
+	if !ok || userID == "" || auth.IsSession(r) {
+		http.Error(w, "Sign in to Glossias to link your LMS account", http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		LTIToken string `json:"lti_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.LTIToken == "" {
+		http.Error(w, "lti_token is required", http.StatusBadRequest)
+		return
+	}
+	ltiUser, err := t.VerifySession(req.LTIToken)
+	if err != nil {
+		http.Error(w, "Invalid or expired LMS session, open Glossias from the LMS again", http.StatusUnauthorized)
+		return
+	}
+
+	err = t.store.LinkLTIAccount(r.Context(), ltiUser, userID)
+	if err == models.ErrNotFound {
+		http.Error(w, "This LMS account cannot be linked", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		t.log.Error("failed to link LTI account", "error", err, "lti_user", ltiUser, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	t.log.Info("LTI account linked", "lti_user", ltiUser, "user_id", userID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"success": true})
+}
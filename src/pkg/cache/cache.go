@@ -291,3 +291,18 @@ func (kb *KeyBuilder) ActiveTimeTrackingSession(userID, route string, storyID *i
 func (kb *KeyBuilder) GrammarInstances(storyID int, grammarPointID int) string {
 	return fmt.Sprintf("grammar_instances:story:%d:gp:%d", storyID, grammarPointID)
 }
+
+// CourseAnalytics builds a cache key for a course's analytics, filtered by enrollment status
+func (kb *KeyBuilder) CourseAnalytics(courseID int, status string) string {
+	return fmt.Sprintf("course_analytics:%d:%s", courseID, status)
+}
+
+// VocabBankOrder builds a cache key for the order of a story's vocab bank
+func (kb *KeyBuilder) VocabBankOrder(storyID int) string {
+	return fmt.Sprintf("vocab_bank_order:%d", storyID)
+}
+
+// StoryCourse builds a cache key for the course a story belongs to
+func (kb *KeyBuilder) StoryCourse(storyID int) string {
+	return fmt.Sprintf("story_course:%d", storyID)
+}
@@ -237,6 +237,13 @@ func TestKeyBuilder(t *testing.T) {
 	if vocabKey != expected {
 		t.Errorf("Expected %s, got %s", expected, vocabKey)
 	}
+
+	// Test course analytics key
+	analyticsKey := kb.CourseAnalytics(7, "active")
+	expected = "course_analytics:7:active"
+	if analyticsKey != expected {
+		t.Errorf("Expected %s, got %s", expected, analyticsKey)
+	}
 }
 
 func TestCacheStats(t *testing.T) {
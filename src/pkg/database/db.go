@@ -4,7 +4,6 @@ package database
 import (
 	"context"
 	"database/sql"
-	"embed"
 	"errors"
 	"os"
 
@@ -12,9 +11,6 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-//go:embed schema.sql
-var schemaFS embed.FS
-
 // InitDB selects PostgreSQL implementation based on USE_POOL environment variable
 // USE_POOL=false uses database/sql, otherwise defaults to pgxpool for SQLC compatibility
 func InitDB(dbPath string) (Store, error) {
@@ -25,9 +21,8 @@ func InitDB(dbPath string) (Store, error) {
 		return &mockStore{}, nil
 	}
 
-	// Initialize schema
-	schema, err := schemaFS.ReadFile("schema.sql")
-	if err != nil {
+	// Bring the schema up to date before handing out connections
+	if err := migrateOnStartup(connStr); err != nil {
 		return nil, err
 	}
 
@@ -50,10 +45,6 @@ func InitDB(dbPath string) (Store, error) {
 			return nil, err
 		}
 
-		if _, err := pool.Exec(context.Background(), string(schema)); err != nil {
-			return nil, err
-		}
-
 		return &poolStore{pool: pool}, nil
 	}
 
@@ -67,10 +58,6 @@ func InitDB(dbPath string) (Store, error) {
 		return nil, err
 	}
 
-	if _, err := db.Exec(string(schema)); err != nil {
-		return nil, err
-	}
-
 	return &sqlStore{db: &sqlDB{db}}, nil
 }
 
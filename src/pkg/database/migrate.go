@@ -0,0 +1,373 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID is the key for the session-level advisory lock held while migrating.
+// It is arbitrary but must never change, or old and new binaries would not exclude each other.
+const migrationLockID int64 = 0x676c6f7373696173 // "glossias"
+
+// Migration files are named NNNN_name.up.sql and NNNN_name.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a single numbered schema change
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// MigrationStatus reports whether a known migration has been applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// appliedMigration is a row of the schema_migrations bookkeeping table
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// LoadMigrations reads the up/down pairs in dir and returns them sorted by version.
+// Every version needs an up file; down files are optional but required to roll back.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		if version <= 0 {
+			return nil, fmt.Errorf("migration version must be positive in %q", entry.Name())
+		}
+
+		contents, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.UpSQL = string(contents)
+		} else {
+			m.DownSQL = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// pendingMigrations returns the migrations not yet recorded as applied, in version order
+func pendingMigrations(migrations []Migration, applied []appliedMigration) []Migration {
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// rollbackMigrations returns the last `steps` applied migrations, newest first.
+// It fails if any of them is unknown to this binary or has no down file.
+func rollbackMigrations(migrations []Migration, applied []appliedMigration, steps int) ([]Migration, error) {
+	known := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	sorted := append([]appliedMigration(nil), applied...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version > sorted[j].Version
+	})
+	if steps > len(sorted) {
+		steps = len(sorted)
+	}
+
+	targets := make([]Migration, 0, steps)
+	for _, a := range sorted[:steps] {
+		m, ok := known[a.Version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %d_%s is not known to this build", a.Version, a.Name)
+		}
+		if m.DownSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+		targets = append(targets, m)
+	}
+	return targets, nil
+}
+
+// Migrator applies migrations over one dedicated connection.
+// A single session is required so the advisory lock covers the whole run.
+type Migrator struct {
+	conn       *pgx.Conn
+	migrations []Migration
+}
+
+// NewMigrator connects to connStr and loads the embedded migrations
+func NewMigrator(ctx context.Context, connStr string) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	config, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	// Match the pools: no prepared statements, so poolers in transaction mode are happy
+	config.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	return &Migrator{conn: conn, migrations: migrations}, nil
+}
+
+// Close closes the migrator's connection
+func (m *Migrator) Close(ctx context.Context) error {
+	return m.conn.Close(ctx)
+}
+
+// Up applies every pending migration in order and returns the ones applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mig := range pendingMigrations(m.migrations, applied) {
+			if err := m.run(ctx, mig.UpSQL, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx,
+					"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+					mig.Version, mig.Name)
+				return err
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s up failed: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last `steps` applied migrations and returns the ones rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		targets, err := rollbackMigrations(m.migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+		for _, mig := range targets {
+			if err := m.run(ctx, mig.DownSQL, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s down failed: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists every known migration and whether it has been applied.
+// Applied versions this build does not know about are listed too, by name only.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]appliedMigration, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := byVersion[mig.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+			delete(byVersion, mig.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range byVersion {
+		statuses = append(statuses, MigrationStatus{
+			Version:   a.Version,
+			Name:      a.Name,
+			Applied:   true,
+			AppliedAt: a.AppliedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// withLock runs fn while holding the migration advisory lock.
+// pg_advisory_lock blocks, so a second instance waits and then finds nothing pending.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if _, err := m.conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so a cancelled ctx still releases the lock
+		if _, err := m.conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			fmt.Printf("Failed to release migration lock: %v\n", err)
+		}
+	}()
+
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// ensureTable creates the schema_migrations bookkeeping table if needed
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// applied returns the rows of schema_migrations in version order
+func (m *Migrator) applied(ctx context.Context) ([]appliedMigration, error) {
+	rows, err := m.conn.Query(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []appliedMigration
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+// run executes a migration script and its bookkeeping change in one transaction
+func (m *Migrator) run(ctx context.Context, script string, record func(tx pgx.Tx) error) error {
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// migrateOnStartup applies pending migrations when the server connects.
+// Set AUTO_MIGRATE=false to leave migrations to `glossias migrate up`.
+func migrateOnStartup(connStr string) error {
+	if os.Getenv("AUTO_MIGRATE") == "false" {
+		return nil
+	}
+
+	ctx := context.Background()
+	migrator, err := NewMigrator(ctx, connStr)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close(ctx)
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mig := range applied {
+		fmt.Printf("Applied migration %04d_%s\n", mig.Version, mig.Name)
+	}
+	return nil
+}
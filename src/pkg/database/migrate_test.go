@@ -0,0 +1,95 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrationsSortsAndPairs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_thing.up.sql":   {Data: []byte("CREATE TABLE thing ();")},
+		"m/0002_add_thing.down.sql": {Data: []byte("DROP TABLE thing;")},
+		"m/0001_baseline.up.sql":    {Data: []byte("CREATE TABLE base ();")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("LoadMigrations failed: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "baseline" || migrations[0].DownSQL != "" {
+		t.Errorf("Unexpected first migration: %+v", migrations[0])
+	}
+	if migrations[1].Version != 2 || migrations[1].DownSQL != "DROP TABLE thing;" {
+		t.Errorf("Unexpected second migration: %+v", migrations[1])
+	}
+}
+
+func TestLoadMigrationsRejectsBadFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":     {"m/baseline.sql": {Data: []byte("")}},
+		"missing up":   {"m/0001_baseline.down.sql": {Data: []byte("DROP TABLE x;")}},
+		"name clash":   {"m/0001_a.up.sql": {Data: []byte("x")}, "m/0001_b.down.sql": {Data: []byte("y")}},
+		"zero version": {"m/0000_zero.up.sql": {Data: []byte("x")}},
+	}
+
+	for name, fsys := range tests {
+		if _, err := LoadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := LoadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		t.Fatalf("Embedded migrations failed to load: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("Expected baseline migration first, got %+v", migrations)
+	}
+	if !strings.Contains(migrations[0].UpSQL, "CREATE TABLE IF NOT EXISTS users") {
+		t.Error("Baseline migration must stay idempotent so existing databases are adopted")
+	}
+	for _, m := range migrations {
+		if m.DownSQL == "" {
+			t.Errorf("Migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	applied := []appliedMigration{{Version: 1}, {Version: 3}}
+
+	pending := pendingMigrations(migrations, applied)
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("Expected only version 2 pending, got %+v", pending)
+	}
+}
+
+func TestRollbackMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "a", DownSQL: "down 1"},
+		{Version: 2, Name: "b", DownSQL: "down 2"},
+		{Version: 3, Name: "c"},
+	}
+
+	targets, err := rollbackMigrations(migrations, []appliedMigration{{Version: 1}, {Version: 2}}, 5)
+	if err != nil {
+		t.Fatalf("rollbackMigrations failed: %v", err)
+	}
+	if len(targets) != 2 || targets[0].Version != 2 || targets[1].Version != 1 {
+		t.Errorf("Expected newest-first rollback of 2 then 1, got %+v", targets)
+	}
+
+	if _, err := rollbackMigrations(migrations, []appliedMigration{{Version: 3}}, 1); err == nil {
+		t.Error("Expected error rolling back a migration without a down file")
+	}
+	if _, err := rollbackMigrations(migrations, []appliedMigration{{Version: 9, Name: "future"}}, 1); err == nil {
+		t.Error("Expected error rolling back a migration unknown to this build")
+	}
+}
@@ -0,0 +1,26 @@
+-- 0001_baseline.down.sql
+-- Drops every table created by the baseline, in reverse dependency order.
+-- This destroys all data; it exists so a fresh database can be rebuilt.
+
+DROP TABLE IF EXISTS translation_requests;
+DROP TABLE IF EXISTS grammar_incorrect_answers;
+DROP TABLE IF EXISTS vocab_incorrect_answers;
+DROP TABLE IF EXISTS grammar_correct_answers;
+DROP TABLE IF EXISTS vocab_correct_answers;
+DROP TABLE IF EXISTS anonymous_time_tracking;
+DROP TABLE IF EXISTS user_time_tracking;
+DROP TABLE IF EXISTS footnote_references;
+DROP TABLE IF EXISTS footnotes;
+DROP TABLE IF EXISTS grammar_items;
+DROP TABLE IF EXISTS vocabulary_items;
+DROP TABLE IF EXISTS line_audio_files;
+DROP TABLE IF EXISTS line_translations;
+DROP TABLE IF EXISTS story_lines;
+DROP TABLE IF EXISTS story_descriptions;
+DROP TABLE IF EXISTS story_titles;
+DROP TABLE IF EXISTS grammar_points;
+DROP TABLE IF EXISTS stories;
+DROP TABLE IF EXISTS course_users;
+DROP TABLE IF EXISTS course_admins;
+DROP TABLE IF EXISTS courses;
+DROP TABLE IF EXISTS users;
@@ -1,6 +1,6 @@
--- schema.sql
--- Remove SQLite-specific PRAGMA
--- Replace AUTOINCREMENT with SERIAL
+-- 0001_baseline.up.sql
+-- Baseline schema, formerly schema.sql. Every statement is idempotent so that
+-- databases created before the migration runner existed are adopted in place.
 
 -- Users table for authentication and authorization
 CREATE TABLE IF NOT EXISTS users (
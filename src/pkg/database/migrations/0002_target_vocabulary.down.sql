@@ -0,0 +1,2 @@
+-- 0002_target_vocabulary.down.sql
+DROP TABLE IF EXISTS target_vocabulary;
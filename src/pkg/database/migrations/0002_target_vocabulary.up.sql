@@ -0,0 +1,14 @@
+-- 0002_target_vocabulary.up.sql
+-- The five target lexical forms per story used by the Identify and Recall phases.
+-- Occurrences are found by joining on vocabulary_items.lexical_form.
+CREATE TABLE target_vocabulary (
+    id SERIAL PRIMARY KEY,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    lexical_form TEXT NOT NULL,
+    audio_path TEXT, -- word pronunciation, audio-files bucket
+    audio_bucket TEXT,
+    image_path TEXT, -- the matching picture, images bucket
+    image_bucket TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (story_id, lexical_form)
+);
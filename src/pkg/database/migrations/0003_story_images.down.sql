@@ -0,0 +1,2 @@
+-- 0003_story_images.down.sql
+DROP TABLE IF EXISTS story_images;
@@ -0,0 +1,16 @@
+-- 0003_story_images.up.sql
+-- Image assets attached to a story, stored in the images bucket.
+-- Mirrors line_audio_files; label says what the image is for (e.g. "recall").
+CREATE TABLE story_images (
+    image_id SERIAL PRIMARY KEY,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    file_path TEXT NOT NULL,
+    file_bucket TEXT NOT NULL,
+    label TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (file_bucket, file_path)
+);
+
+CREATE INDEX idx_story_images_story ON story_images (story_id, label);
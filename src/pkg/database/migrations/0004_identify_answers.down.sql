@@ -0,0 +1,4 @@
+-- 0004_identify_answers.down.sql
+DROP TABLE IF EXISTS identify_options;
+DROP TABLE IF EXISTS identify_incorrect_answers;
+DROP TABLE IF EXISTS identify_correct_answers;
@@ -0,0 +1,39 @@
+-- 0004_identify_answers.up.sql
+-- Append-only answer logs for the Identify picture quiz, shaped like the vocab answer tables.
+-- line_number is the line whose target word prompted the quiz.
+CREATE TABLE identify_correct_answers (
+    score_id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    target_vocab_id INTEGER NOT NULL REFERENCES target_vocabulary (id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (story_id, line_number) REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE
+);
+
+CREATE TABLE identify_incorrect_answers (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    target_vocab_id INTEGER NOT NULL REFERENCES target_vocabulary (id) ON DELETE CASCADE,
+    selected_target_vocab_id INTEGER NOT NULL REFERENCES target_vocabulary (id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (story_id, line_number) REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_identify_correct_user_story ON identify_correct_answers (user_id, story_id);
+CREATE INDEX idx_identify_incorrect_user_story ON identify_incorrect_answers (user_id, story_id);
+
+-- The pictures offered in a user's current Identify attempt. Students only see option_id,
+-- so the payload does not tell which picture belongs to which target word. A new attempt
+-- replaces the user's options for the story.
+CREATE TABLE identify_options (
+    option_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    target_vocab_id INTEGER NOT NULL REFERENCES target_vocabulary (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_identify_options_user_story ON identify_options (user_id, story_id);
@@ -0,0 +1,4 @@
+-- 0005_recall.down.sql
+DROP TABLE IF EXISTS recall_incorrect_answers;
+DROP TABLE IF EXISTS recall_correct_answers;
+DROP TABLE IF EXISTS recall_sentences;
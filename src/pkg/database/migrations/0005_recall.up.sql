@@ -0,0 +1,35 @@
+-- 0005_recall.up.sql
+-- Recall phase: ordered sentences students put back in sequence, and their answer logs.
+-- A sentence is placed correctly at most once per user, so correct counts stay bounded by the sentence count.
+CREATE TABLE recall_sentences (
+    id SERIAL PRIMARY KEY,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    sequence_order INTEGER NOT NULL, -- 1-indexed
+    sentence_text TEXT NOT NULL,
+    target_vocab_id INTEGER REFERENCES target_vocabulary (id) ON DELETE SET NULL,
+    image_id INTEGER REFERENCES story_images (image_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Deferred so sentences can be reordered inside one transaction
+    UNIQUE (story_id, sequence_order) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE TABLE recall_correct_answers (
+    score_id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    recall_sentence_id INTEGER NOT NULL REFERENCES recall_sentences (id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, recall_sentence_id)
+);
+
+CREATE TABLE recall_incorrect_answers (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    recall_sentence_id INTEGER NOT NULL REFERENCES recall_sentences (id) ON DELETE CASCADE,
+    submitted_position INTEGER NOT NULL, -- 1-indexed position the student put the sentence in
+    attempted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recall_correct_user_story ON recall_correct_answers (user_id, story_id);
+CREATE INDEX idx_recall_incorrect_user_story ON recall_incorrect_answers (user_id, story_id);
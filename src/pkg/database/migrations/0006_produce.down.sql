@@ -0,0 +1,4 @@
+-- 0006_produce.down.sql
+DROP TABLE IF EXISTS produce_submissions;
+DROP TABLE IF EXISTS produce_explanations;
+DROP TABLE IF EXISTS produce_segments;
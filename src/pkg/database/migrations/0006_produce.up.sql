@@ -0,0 +1,38 @@
+-- 0006_produce.up.sql
+-- Produce phase: students retell short stretches of the story from an English prompt.
+-- Line numbers are not foreign keys: a segment spans a range of lines, and EditStoryText
+-- renumbers segments along with the lines they cover.
+CREATE TABLE produce_segments (
+    id SERIAL PRIMARY KEY,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    start_line INTEGER NOT NULL, -- 1-indexed, inclusive
+    end_line INTEGER NOT NULL,
+    english_prompt TEXT NOT NULL,
+    reference_text TEXT NOT NULL, -- target-language model answer
+    grammar_point_id INTEGER REFERENCES grammar_points (grammar_point_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_line >= 1 AND end_line >= start_line)
+);
+
+CREATE INDEX idx_produce_segments_story ON produce_segments (story_id);
+
+-- One contrastive explanation per story, shown alongside the segments
+CREATE TABLE produce_explanations (
+    story_id INTEGER PRIMARY KEY REFERENCES stories (story_id) ON DELETE CASCADE,
+    explanation TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A student's latest answer per segment; resubmitting replaces it
+CREATE TABLE produce_submissions (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    segment_id INTEGER NOT NULL REFERENCES produce_segments (id) ON DELETE CASCADE,
+    student_text TEXT NOT NULL,
+    submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, segment_id)
+);
+
+CREATE INDEX idx_produce_submissions_user_story ON produce_submissions (user_id, story_id);
@@ -0,0 +1,8 @@
+-- 0007_produce_grading.down.sql
+DROP INDEX IF EXISTS idx_produce_submissions_pending;
+ALTER TABLE produce_submissions
+    DROP COLUMN IF EXISTS graded_at,
+    DROP COLUMN IF EXISTS grading_attempts,
+    DROP COLUMN IF EXISTS grading_status,
+    DROP COLUMN IF EXISTS ai_feedback,
+    DROP COLUMN IF EXISTS ai_score;
@@ -0,0 +1,12 @@
+-- 0007_produce_grading.up.sql
+-- Automatic grading of produce submissions. ai_score stays NULL while a submission is
+-- pending; the background re-grader retries pending rows.
+ALTER TABLE produce_submissions
+    ADD COLUMN ai_score INTEGER CHECK (ai_score BETWEEN 0 AND 100),
+    ADD COLUMN ai_feedback TEXT,
+    ADD COLUMN grading_status TEXT NOT NULL DEFAULT 'pending' CHECK (grading_status IN ('pending', 'graded')),
+    ADD COLUMN grading_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN graded_at TIMESTAMP;
+
+CREATE INDEX idx_produce_submissions_pending ON produce_submissions (updated_at)
+WHERE grading_status = 'pending';
@@ -0,0 +1,3 @@
+-- 0008_phase_flows.down.sql
+DROP TABLE IF EXISTS story_phase_flows;
+DROP TABLE IF EXISTS course_phase_flows;
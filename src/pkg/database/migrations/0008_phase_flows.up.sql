@@ -0,0 +1,14 @@
+-- 0008_phase_flows.up.sql
+-- Order of the learning phases a student is guided through. A story's own flow
+-- overrides its course's flow; with neither, the default flow is used.
+CREATE TABLE course_phase_flows (
+    course_id INTEGER PRIMARY KEY REFERENCES courses (course_id) ON DELETE CASCADE,
+    phases TEXT[] NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE story_phase_flows (
+    story_id INTEGER PRIMARY KEY REFERENCES stories (story_id) ON DELETE CASCADE,
+    phases TEXT[] NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- 0009_story_scores.down.sql
+DROP TABLE IF EXISTS story_scores;
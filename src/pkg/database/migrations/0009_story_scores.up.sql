@@ -0,0 +1,33 @@
+-- 0009_story_scores.up.sql
+-- Snapshots of a student's score on a story. Rows are only ever added, so the history
+-- shows how each number was derived; the newest row per user and story is the current score.
+CREATE TABLE story_scores (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    formula_version INTEGER NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('score_page', 'recompute')),
+    vocab_correct INTEGER NOT NULL,
+    vocab_incorrect INTEGER NOT NULL,
+    vocab_total INTEGER NOT NULL,
+    vocab_accuracy DOUBLE PRECISION NOT NULL, -- 0-100
+    grammar_correct INTEGER NOT NULL,
+    grammar_incorrect INTEGER NOT NULL,
+    grammar_total INTEGER NOT NULL,
+    grammar_accuracy DOUBLE PRECISION NOT NULL,
+    recall_correct INTEGER NOT NULL,
+    recall_incorrect INTEGER NOT NULL,
+    recall_total INTEGER NOT NULL,
+    recall_accuracy DOUBLE PRECISION NOT NULL,
+    translation_completed BOOLEAN NOT NULL,
+    vocab_time_seconds INTEGER NOT NULL,
+    grammar_time_seconds INTEGER NOT NULL,
+    translation_time_seconds INTEGER NOT NULL,
+    video_time_seconds INTEGER NOT NULL,
+    total_time_seconds INTEGER NOT NULL,
+    overall_accuracy DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_story_scores_user_story ON story_scores (user_id, story_id, computed_at DESC);
+CREATE INDEX idx_story_scores_story ON story_scores (story_id);
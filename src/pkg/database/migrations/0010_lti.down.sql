@@ -0,0 +1,5 @@
+-- 0010_lti.down.sql
+DROP TABLE IF EXISTS lti_line_items;
+DROP TABLE IF EXISTS lti_users;
+DROP TABLE IF EXISTS lti_contexts;
+DROP TABLE IF EXISTS lti_platforms;
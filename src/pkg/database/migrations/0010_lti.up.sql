@@ -0,0 +1,43 @@
+-- 0010_lti.up.sql
+-- LTI 1.3 tool registration. A platform is one LMS registration; its contexts map to
+-- courses, its users to users, and line items record where each story's grade is posted.
+CREATE TABLE lti_platforms (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    deployment_id TEXT NOT NULL DEFAULT '', -- empty accepts any deployment
+    auth_login_url TEXT NOT NULL,
+    auth_token_url TEXT NOT NULL,
+    jwks_url TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (issuer, client_id)
+);
+
+CREATE TABLE lti_contexts (
+    id SERIAL PRIMARY KEY,
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms (id) ON DELETE CASCADE,
+    context_id TEXT NOT NULL,
+    course_id INTEGER NOT NULL REFERENCES courses (course_id) ON DELETE CASCADE,
+    title TEXT NOT NULL DEFAULT '',
+    lineitems_url TEXT NOT NULL DEFAULT '', -- AGS line items service, empty if the platform offers none
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (platform_id, context_id)
+);
+
+CREATE TABLE lti_users (
+    platform_id INTEGER NOT NULL REFERENCES lti_platforms (id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (platform_id, subject)
+);
+
+CREATE INDEX idx_lti_users_user ON lti_users (user_id);
+
+CREATE TABLE lti_line_items (
+    lti_context_id INTEGER NOT NULL REFERENCES lti_contexts (id) ON DELETE CASCADE,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    line_item_url TEXT NOT NULL,
+    PRIMARY KEY (lti_context_id, story_id)
+);
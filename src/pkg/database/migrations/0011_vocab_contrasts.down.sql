@@ -0,0 +1,3 @@
+-- 0011_vocab_contrasts.down.sql
+DROP INDEX IF EXISTS idx_vocab_incorrect_answers_story;
+DROP TABLE IF EXISTS vocab_contrast_pairs;
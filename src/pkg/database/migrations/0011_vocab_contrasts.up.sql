@@ -0,0 +1,15 @@
+-- 0011_vocab_contrasts.up.sql
+-- Pairs of lexical forms an admin wants students to tell apart in a story. The vocab bank
+-- always offers both forms of a pair, so a form the story does not use becomes a distractor.
+-- form_a sorts before form_b so each pair is stored once whichever way round it was entered.
+CREATE TABLE vocab_contrast_pairs (
+    id SERIAL PRIMARY KEY,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    form_a TEXT NOT NULL,
+    form_b TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (story_id, form_a, form_b)
+);
+
+-- Confusion matrices aggregate wrong answers over a whole story
+CREATE INDEX idx_vocab_incorrect_answers_story ON vocab_incorrect_answers (story_id);
@@ -0,0 +1,2 @@
+-- 0012_at_risk.down.sql
+DROP TABLE IF EXISTS course_risk_settings;
@@ -0,0 +1,14 @@
+-- 0012_at_risk.up.sql
+-- Per-course thresholds for flagging students at risk. Courses without a row use
+-- models.DefaultRiskThresholds. starts_on is the first day of week 1; without it no
+-- story is ever overdue.
+CREATE TABLE course_risk_settings (
+    course_id INTEGER PRIMARY KEY REFERENCES courses (course_id) ON DELETE CASCADE,
+    starts_on DATE,
+    grace_days INTEGER NOT NULL,
+    max_incorrect_ratio DOUBLE PRECISION NOT NULL, -- 0-1
+    min_answers INTEGER NOT NULL,
+    min_phase_seconds INTEGER NOT NULL,
+    digest_enabled BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
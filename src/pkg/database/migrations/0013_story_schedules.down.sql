@@ -0,0 +1,3 @@
+-- 0013_story_schedules.down.sql
+ALTER TABLE story_scores DROP COLUMN IF EXISTS late;
+DROP TABLE IF EXISTS story_schedules;
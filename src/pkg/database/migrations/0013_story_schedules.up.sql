@@ -0,0 +1,17 @@
+-- 0013_story_schedules.up.sql
+-- Release window of a story in its course. Students cannot see a story before
+-- available_from, answers after due_at are late and none are accepted after
+-- late_until. NULL leaves that end of the window open.
+CREATE TABLE story_schedules (
+    story_id INTEGER PRIMARY KEY REFERENCES stories (story_id) ON DELETE CASCADE,
+    available_from TIMESTAMP,
+    due_at TIMESTAMP,
+    late_until TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (available_from IS NULL OR due_at IS NULL OR available_from <= due_at),
+    CHECK (due_at IS NULL OR late_until IS NULL OR due_at <= late_until),
+    CHECK (available_from IS NULL OR late_until IS NULL OR available_from <= late_until)
+);
+
+-- Whether the score was reached after the story was due
+ALTER TABLE story_scores ADD COLUMN late BOOLEAN NOT NULL DEFAULT false;
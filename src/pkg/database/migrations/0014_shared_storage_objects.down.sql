@@ -0,0 +1,8 @@
+-- 0014_shared_storage_objects.down.sql
+-- Fails while cloned stories still share images
+DROP INDEX IF EXISTS idx_target_vocabulary_image;
+DROP INDEX IF EXISTS idx_target_vocabulary_audio;
+DROP INDEX IF EXISTS idx_line_audio_files_file;
+DROP INDEX IF EXISTS idx_story_images_file;
+
+ALTER TABLE story_images ADD CONSTRAINT story_images_file_bucket_file_path_key UNIQUE (file_bucket, file_path);
@@ -0,0 +1,10 @@
+-- 0014_shared_storage_objects.up.sql
+-- Cloned courses point their audio and image rows at the files of the original
+-- course instead of copying them, so one stored file can back rows of several
+-- stories. Deletes look files up by path to only remove ones nothing else uses.
+ALTER TABLE story_images DROP CONSTRAINT story_images_file_bucket_file_path_key;
+
+CREATE INDEX idx_story_images_file ON story_images (file_bucket, file_path);
+CREATE INDEX idx_line_audio_files_file ON line_audio_files (file_bucket, file_path);
+CREATE INDEX idx_target_vocabulary_audio ON target_vocabulary (audio_bucket, audio_path);
+CREATE INDEX idx_target_vocabulary_image ON target_vocabulary (image_bucket, image_path);
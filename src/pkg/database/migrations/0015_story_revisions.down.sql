@@ -0,0 +1,4 @@
+-- 0015_story_revisions.down.sql
+DROP INDEX IF EXISTS idx_vocab_incorrect_answers_item;
+DROP INDEX IF EXISTS idx_vocab_correct_answers_item;
+DROP TABLE IF EXISTS story_revisions;
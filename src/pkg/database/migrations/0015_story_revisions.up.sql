@@ -0,0 +1,17 @@
+-- 0015_story_revisions.up.sql
+-- Full Story JSON after every admin write, numbered per story from 1, so edits can be
+-- compared and rolled back. created_by is the admin who made the change, when known.
+CREATE TABLE story_revisions (
+    revision_id SERIAL PRIMARY KEY,
+    story_id INTEGER NOT NULL REFERENCES stories (story_id) ON DELETE CASCADE,
+    revision_number INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_by TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (story_id, revision_number)
+);
+
+-- Rollbacks check whether students answered the vocabulary items they would delete
+CREATE INDEX idx_vocab_correct_answers_item ON vocab_correct_answers (vocab_item_id);
+CREATE INDEX idx_vocab_incorrect_answers_item ON vocab_incorrect_answers (vocab_item_id);
@@ -0,0 +1,46 @@
+-- 0016_line_renumbering.up.sql
+-- Text edits renumber story lines in place, so everything attached to a line, from
+-- annotations and audio to student answers, follows the line to its new number.
+ALTER TABLE line_translations DROP CONSTRAINT line_translations_story_id_line_number_fkey,
+    ADD CONSTRAINT line_translations_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE line_audio_files DROP CONSTRAINT line_audio_files_story_id_line_number_fkey,
+    ADD CONSTRAINT line_audio_files_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE vocabulary_items DROP CONSTRAINT vocabulary_items_story_id_line_number_fkey,
+    ADD CONSTRAINT vocabulary_items_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE grammar_items DROP CONSTRAINT grammar_items_story_id_line_number_fkey,
+    ADD CONSTRAINT grammar_items_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE footnotes DROP CONSTRAINT footnotes_story_id_line_number_fkey,
+    ADD CONSTRAINT footnotes_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE vocab_correct_answers DROP CONSTRAINT vocab_correct_answers_story_id_line_number_fkey,
+    ADD CONSTRAINT vocab_correct_answers_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE grammar_correct_answers DROP CONSTRAINT grammar_correct_answers_story_id_line_number_fkey,
+    ADD CONSTRAINT grammar_correct_answers_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE vocab_incorrect_answers DROP CONSTRAINT vocab_incorrect_answers_story_id_line_number_fkey,
+    ADD CONSTRAINT vocab_incorrect_answers_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE grammar_incorrect_answers DROP CONSTRAINT grammar_incorrect_answers_story_id_line_number_fkey,
+    ADD CONSTRAINT grammar_incorrect_answers_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE identify_correct_answers DROP CONSTRAINT identify_correct_answers_story_id_line_number_fkey,
+    ADD CONSTRAINT identify_correct_answers_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE identify_incorrect_answers DROP CONSTRAINT identify_incorrect_answers_story_id_line_number_fkey,
+    ADD CONSTRAINT identify_incorrect_answers_story_id_line_number_fkey FOREIGN KEY (story_id, line_number)
+    REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE;
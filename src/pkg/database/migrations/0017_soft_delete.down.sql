@@ -0,0 +1,7 @@
+-- 0017_soft_delete.down.sql
+DROP INDEX IF EXISTS idx_courses_course_number;
+ALTER TABLE courses ADD CONSTRAINT courses_course_number_key UNIQUE (course_number);
+DROP INDEX IF EXISTS idx_courses_deleted_at;
+DROP INDEX IF EXISTS idx_stories_deleted_at;
+ALTER TABLE courses DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS deleted_at;
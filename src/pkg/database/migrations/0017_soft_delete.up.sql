@@ -0,0 +1,12 @@
+-- 0017_soft_delete.up.sql
+-- Deleted stories and courses stay in the trash, hidden from everyone, until they are
+-- restored or purged. Trashing a course trashes its stories at the same time.
+ALTER TABLE stories ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE courses ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_stories_deleted_at ON stories (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_courses_deleted_at ON courses (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A trashed course gives up its number, so a new course can take it
+ALTER TABLE courses DROP CONSTRAINT courses_course_number_key;
+CREATE UNIQUE INDEX idx_courses_course_number ON courses (course_number) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- 0018_audit_events.down.sql
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,21 @@
+-- 0018_audit_events.up.sql
+-- Every request that changes something under /api/admin, with the state of its target
+-- before and after. actor_id has no foreign key so events outlive their users.
+CREATE TABLE audit_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    request_id TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL DEFAULT '',
+    status INTEGER NOT NULL,
+    request_body JSONB,
+    before_state JSONB,
+    after_state JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor_id, event_id);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id, event_id);
+CREATE INDEX idx_audit_events_request ON audit_events (request_id);
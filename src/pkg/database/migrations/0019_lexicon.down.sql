@@ -0,0 +1,3 @@
+-- 0019_lexicon.down.sql
+DROP TABLE IF EXISTS vocab_suggestion_rejections;
+DROP TABLE IF EXISTS lexicon_entries;
@@ -0,0 +1,40 @@
+-- 0019_lexicon.up.sql
+-- Per-language lexicon mapping a word as it appears in story text (lower case) to the
+-- lexical forms it has been annotated with, used to suggest vocabulary annotations.
+-- A story's language is the language code of its description.
+CREATE TABLE lexicon_entries (
+    language_code TEXT NOT NULL,
+    surface_form TEXT NOT NULL,
+    lexical_form TEXT NOT NULL,
+    occurrences INTEGER NOT NULL DEFAULT 1, -- accepted annotations with this mapping
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (language_code, surface_form, lexical_form)
+);
+
+-- Seeded from every single-word annotation made so far. Stories without a description,
+-- or with descriptions in several languages, have no clear language and are left out.
+INSERT INTO lexicon_entries (language_code, surface_form, lexical_form, occurrences)
+SELECT sl.language_code, lower(btrim(v.word)), btrim(v.lexical_form), COUNT(*)
+FROM vocabulary_items v
+JOIN (
+    SELECT story_id, MIN(language_code) AS language_code
+    FROM story_descriptions
+    WHERE language_code <> ''
+    GROUP BY story_id
+    HAVING COUNT(DISTINCT language_code) = 1
+) sl ON sl.story_id = v.story_id
+WHERE btrim(v.word) <> '' AND btrim(v.word) !~ '\s' AND btrim(v.lexical_form) <> ''
+GROUP BY 1, 2, 3;
+
+-- Suggestions an admin rejected, so they are not proposed again for the same word
+CREATE TABLE vocab_suggestion_rejections (
+    story_id INTEGER NOT NULL,
+    line_number INTEGER NOT NULL,
+    position_start INTEGER NOT NULL,
+    position_end INTEGER NOT NULL,
+    word TEXT NOT NULL,
+    lexical_form TEXT NOT NULL,
+    rejected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (story_id, line_number, position_start, position_end, lexical_form),
+    FOREIGN KEY (story_id, line_number) REFERENCES story_lines (story_id, line_number) ON DELETE CASCADE ON UPDATE CASCADE
+);
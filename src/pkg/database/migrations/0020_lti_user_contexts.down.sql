@@ -0,0 +1,2 @@
+-- 0020_lti_user_contexts.down.sql
+DROP TABLE IF EXISTS lti_user_contexts;
@@ -0,0 +1,20 @@
+-- 0020_lti_user_contexts.up.sql
+-- The platform contexts each platform user has launched from. Scores are only posted to
+-- the gradebooks of these contexts, not to every context of the platform.
+CREATE TABLE lti_user_contexts (
+    platform_id INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    lti_context_id INTEGER NOT NULL REFERENCES lti_contexts (id) ON DELETE CASCADE,
+    launched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (platform_id, subject, lti_context_id),
+    FOREIGN KEY (platform_id, subject) REFERENCES lti_users (platform_id, subject) ON DELETE CASCADE
+);
+
+-- Users who launched before are assumed to have launched from the contexts of the
+-- platform whose course they belong to
+INSERT INTO lti_user_contexts (platform_id, subject, lti_context_id)
+SELECT lu.platform_id, lu.subject, lc.id
+FROM lti_users lu
+JOIN lti_contexts lc ON lc.platform_id = lu.platform_id
+WHERE EXISTS (SELECT 1 FROM course_users cu WHERE cu.course_id = lc.course_id AND cu.user_id = lu.user_id)
+   OR EXISTS (SELECT 1 FROM course_admins ca WHERE ca.course_id = lc.course_id AND ca.user_id = lu.user_id);
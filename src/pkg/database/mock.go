@@ -115,15 +115,17 @@ type MockQueryResult struct {
 
 // MockDBTX implements db.DBTX for testing with query stubbing
 type MockDBTX struct {
-	queries map[string]MockQueryResult
-	execs   map[string]error
+	queries  map[string]MockQueryResult
+	execs    map[string]error
+	execRows map[string]int64
 }
 
 // NewMockDBTX creates a new mock DBTX connection with query stubbing capabilities
 func NewMockDBTX() *MockDBTX {
 	return &MockDBTX{
-		queries: make(map[string]MockQueryResult),
-		execs:   make(map[string]error),
+		queries:  make(map[string]MockQueryResult),
+		execs:    make(map[string]error),
+		execRows: make(map[string]int64),
 	}
 }
 
@@ -137,12 +139,22 @@ func (m *MockDBTX) StubExec(querySubstr string, err error) {
 	m.execs[querySubstr] = err
 }
 
+// StubExecRows registers how many rows exec queries matching substring report as affected
+func (m *MockDBTX) StubExecRows(querySubstr string, rows int64) {
+	m.execRows[querySubstr] = rows
+}
+
 func (m *MockDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
 	for k, err := range m.execs {
 		if strings.Contains(sql, k) {
 			return pgconn.CommandTag{}, err
 		}
 	}
+	for k, rows := range m.execRows {
+		if strings.Contains(sql, k) {
+			return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", rows)), nil
+		}
+	}
 	return pgconn.CommandTag{}, nil
 }
 
@@ -0,0 +1,80 @@
+-- Course analytics queries
+-- Students are those enrolled in the course, optionally filtered by enrollment status
+
+-- name: CountCourseStudents :one
+SELECT COUNT(*)
+FROM course_users
+WHERE course_id = sqlc.arg(course_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: GetCoursePhaseActivity :many
+SELECT DISTINCT a.user_id, s.story_id, a.phase
+FROM (
+    SELECT user_id, story_id, 'video' AS phase FROM user_time_tracking
+    WHERE route LIKE '%video%' OR route LIKE '%audio%'
+    UNION ALL SELECT user_id, story_id, 'vocab' FROM vocab_correct_answers
+    UNION ALL SELECT user_id, story_id, 'vocab' FROM vocab_incorrect_answers
+    UNION ALL SELECT user_id, story_id, 'identify' FROM identify_correct_answers
+    UNION ALL SELECT user_id, story_id, 'identify' FROM identify_incorrect_answers
+    UNION ALL SELECT user_id, story_id, 'translate' FROM translation_requests
+    UNION ALL SELECT user_id, story_id, 'grammar' FROM grammar_correct_answers
+    UNION ALL SELECT user_id, story_id, 'grammar' FROM grammar_incorrect_answers
+    UNION ALL SELECT user_id, story_id, 'recall' FROM recall_correct_answers
+    UNION ALL SELECT user_id, story_id, 'recall' FROM recall_incorrect_answers
+    UNION ALL SELECT user_id, story_id, 'produce' FROM produce_submissions
+    UNION ALL SELECT user_id, story_id, 'score' FROM story_scores
+) a
+JOIN stories s ON s.story_id = a.story_id
+JOIN course_users cu ON cu.course_id = s.course_id AND cu.user_id = a.user_id
+WHERE s.course_id = sqlc.arg(course_id)
+  AND s.deleted_at IS NULL
+  AND (sqlc.arg(status)::text = '' OR cu.status = sqlc.arg(status)::text);
+
+-- name: GetCourseRouteTimes :many
+SELECT t.user_id, s.story_id, t.route, SUM(t.total_time_seconds)::bigint AS total_seconds
+FROM user_time_tracking t
+JOIN stories s ON s.story_id = t.story_id
+JOIN course_users cu ON cu.course_id = s.course_id AND cu.user_id = t.user_id
+WHERE s.course_id = sqlc.arg(course_id)
+  AND s.deleted_at IS NULL
+  AND (sqlc.arg(status)::text = '' OR cu.status = sqlc.arg(status)::text)
+  AND t.ended_at IS NOT NULL
+GROUP BY t.user_id, s.story_id, t.route;
+
+-- name: GetCourseMissedVocab :many
+SELECT vi.lexical_form, COUNT(*) AS misses, COUNT(DISTINCT via.user_id) AS students
+FROM vocab_incorrect_answers via
+JOIN vocabulary_items vi ON vi.id = via.vocab_item_id
+JOIN stories s ON s.story_id = via.story_id
+JOIN course_users cu ON cu.course_id = s.course_id AND cu.user_id = via.user_id
+WHERE s.course_id = sqlc.arg(course_id)
+  AND s.deleted_at IS NULL
+  AND (sqlc.arg(status)::text = '' OR cu.status = sqlc.arg(status)::text)
+GROUP BY vi.lexical_form
+ORDER BY misses DESC, vi.lexical_form
+LIMIT sqlc.arg(max_rows);
+
+-- name: GetCourseVocabWrongAnswers :many
+SELECT vi.lexical_form, via.incorrect_answer, COUNT(*) AS times
+FROM vocab_incorrect_answers via
+JOIN vocabulary_items vi ON vi.id = via.vocab_item_id
+JOIN stories s ON s.story_id = via.story_id
+JOIN course_users cu ON cu.course_id = s.course_id AND cu.user_id = via.user_id
+WHERE s.course_id = sqlc.arg(course_id)
+  AND s.deleted_at IS NULL
+  AND (sqlc.arg(status)::text = '' OR cu.status = sqlc.arg(status)::text)
+GROUP BY vi.lexical_form, via.incorrect_answer
+ORDER BY vi.lexical_form, times DESC, via.incorrect_answer;
+
+-- name: GetCourseMissedGrammar :many
+SELECT gp.grammar_point_id, gp.name, gp.story_id, COUNT(*) AS misses, COUNT(DISTINCT gia.user_id) AS students
+FROM grammar_incorrect_answers gia
+JOIN grammar_points gp ON gp.grammar_point_id = gia.grammar_point_id
+JOIN stories s ON s.story_id = gia.story_id
+JOIN course_users cu ON cu.course_id = s.course_id AND cu.user_id = gia.user_id
+WHERE s.course_id = sqlc.arg(course_id)
+  AND s.deleted_at IS NULL
+  AND (sqlc.arg(status)::text = '' OR cu.status = sqlc.arg(status)::text)
+GROUP BY gp.grammar_point_id, gp.name, gp.story_id
+ORDER BY misses DESC, gp.grammar_point_id
+LIMIT sqlc.arg(max_rows);
@@ -36,12 +36,24 @@ FROM vocabulary_items
 WHERE story_id = $1
 ORDER BY line_number, position_start;
 
+-- name: GetStoryVocabularyItems :many
+SELECT id, story_id, line_number, word, lexical_form, position_start, position_end
+FROM vocabulary_items
+WHERE story_id = $1
+ORDER BY line_number, position_start;
+
 -- name: GetGrammarItems :many
 SELECT id, story_id, line_number, grammar_point_id, text, position_start, position_end
 FROM grammar_items
 WHERE story_id = $1 AND line_number = $2
 ORDER BY position_start;
 
+-- name: GetStoryGrammarItems :many
+SELECT id, story_id, line_number, grammar_point_id, text, position_start, position_end
+FROM grammar_items
+WHERE story_id = $1
+ORDER BY line_number, position_start;
+
 -- name: CreateGrammarItem :one
 INSERT INTO grammar_items (story_id, line_number, grammar_point_id, text, position_start, position_end)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -52,6 +64,11 @@ UPDATE grammar_items
 SET grammar_point_id = $5, text = $6, position_start = $7, position_end = $8
 WHERE story_id = $1 AND line_number = $2 AND position_start = $3 AND position_end = $4;
 
+-- name: UpdateGrammarItemPosition :exec
+UPDATE grammar_items
+SET position_start = $2, position_end = $3
+WHERE id = $1;
+
 -- name: DeleteGrammarItems :exec
 DELETE FROM grammar_items WHERE story_id = $1 AND line_number = $2;
 
@@ -0,0 +1,32 @@
+-- At-risk detection queries
+
+-- name: GetCourseRiskSettings :one
+SELECT course_id, starts_on, grace_days, max_incorrect_ratio, min_answers, min_phase_seconds, digest_enabled, updated_at
+FROM course_risk_settings
+WHERE course_id = $1;
+
+-- name: UpsertCourseRiskSettings :one
+INSERT INTO course_risk_settings (course_id, starts_on, grace_days, max_incorrect_ratio, min_answers, min_phase_seconds, digest_enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (course_id) DO UPDATE
+SET starts_on = EXCLUDED.starts_on,
+    grace_days = EXCLUDED.grace_days,
+    max_incorrect_ratio = EXCLUDED.max_incorrect_ratio,
+    min_answers = EXCLUDED.min_answers,
+    min_phase_seconds = EXCLUDED.min_phase_seconds,
+    digest_enabled = EXCLUDED.digest_enabled,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING course_id, starts_on, grace_days, max_incorrect_ratio, min_answers, min_phase_seconds, digest_enabled, updated_at;
+
+-- name: ShiftCourseRiskStart :exec
+-- Moves the term start along with ShiftCourseSchedule
+UPDATE course_risk_settings
+SET starts_on = starts_on + sqlc.arg(days)::int, updated_at = CURRENT_TIMESTAMP
+WHERE course_id = sqlc.arg(course_id) AND starts_on IS NOT NULL;
+
+-- name: GetRiskDigestCourseIDs :many
+SELECT crs.course_id
+FROM course_risk_settings crs
+JOIN courses c ON c.course_id = crs.course_id
+WHERE crs.digest_enabled AND c.deleted_at IS NULL
+ORDER BY crs.course_id;
@@ -0,0 +1,20 @@
+-- Audit log queries
+-- One event per admin request that changes something; filters left NULL match everything
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (request_id, actor_id, action, target_type, target_id, status, request_body, before_state, after_state)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListAuditEvents :many
+SELECT event_id, request_id, actor_id, action, target_type, target_id, status, request_body, before_state, after_state, created_at
+FROM audit_events
+WHERE (sqlc.narg(actor_id)::text IS NULL OR actor_id = sqlc.narg(actor_id))
+  AND (sqlc.narg(action)::text IS NULL OR action ILIKE '%' || sqlc.narg(action) || '%')
+  AND (sqlc.narg(target_type)::text IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::text IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(request_id)::text IS NULL OR request_id = sqlc.narg(request_id))
+  AND (sqlc.narg(since)::timestamp IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamp IS NULL OR created_at < sqlc.narg(until))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR event_id < sqlc.narg(before_id))
+ORDER BY event_id DESC
+LIMIT sqlc.arg(max_events);
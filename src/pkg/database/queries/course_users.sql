@@ -30,14 +30,14 @@ WHERE course_id = $1;
 SELECT c.course_id, c.course_number, c.name, c.description, cu.enrolled_at, cu.status
 FROM courses c
 JOIN course_users cu ON c.course_id = cu.course_id
-WHERE cu.user_id = $1
+WHERE cu.user_id = $1 AND c.deleted_at IS NULL
 ORDER BY c.course_number;
 
 -- name: GetCoursesForUserByStatus :many
 SELECT c.course_id, c.course_number, c.name, c.description, cu.enrolled_at, cu.status
 FROM courses c
 JOIN course_users cu ON c.course_id = cu.course_id
-WHERE cu.user_id = $1 AND cu.status = $2
+WHERE cu.user_id = $1 AND cu.status = $2 AND c.deleted_at IS NULL
 ORDER BY c.course_number;
 
 -- name: GetUsersForCourse :many
@@ -54,4 +54,5 @@ SELECT EXISTS(
     LEFT JOIN course_users cu ON u.user_id = cu.user_id
     WHERE u.user_id = $1
     AND (u.is_super_admin = true OR ca.course_id = $2 OR cu.course_id = $2)
+    AND NOT EXISTS (SELECT 1 FROM courses c WHERE c.course_id = $2 AND c.deleted_at IS NOT NULL)
 ) as can_access;
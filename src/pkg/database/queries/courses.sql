@@ -3,35 +3,36 @@
 -- name: CreateCourse :one
 INSERT INTO courses (course_number, name, description)
 VALUES ($1, $2, $3)
-RETURNING course_id, course_number, name, description, created_at, updated_at;
+RETURNING course_id, course_number, name, description, created_at, updated_at, deleted_at;
 
 -- name: GetCourse :one
-SELECT course_id, course_number, name, description, created_at, updated_at
+SELECT course_id, course_number, name, description, created_at, updated_at, deleted_at
 FROM courses
-WHERE course_id = $1;
+WHERE course_id = $1 AND deleted_at IS NULL;
 
 -- name: GetCourseByNumber :one
-SELECT course_id, course_number, name, description, created_at, updated_at
+SELECT course_id, course_number, name, description, created_at, updated_at, deleted_at
 FROM courses
-WHERE course_number = $1;
+WHERE course_number = $1 AND deleted_at IS NULL;
 
 -- name: ListCourses :many
-SELECT course_id, course_number, name, description, created_at, updated_at
+SELECT course_id, course_number, name, description, created_at, updated_at, deleted_at
 FROM courses
+WHERE deleted_at IS NULL
 ORDER BY course_number;
 
 -- name: UpdateCourse :one
 UPDATE courses
 SET course_number = $2, name = $3, description = $4, updated_at = CURRENT_TIMESTAMP
-WHERE course_id = $1
-RETURNING course_id, course_number, name, description, created_at, updated_at;
+WHERE course_id = $1 AND deleted_at IS NULL
+RETURNING course_id, course_number, name, description, created_at, updated_at, deleted_at;
 
 -- name: DeleteCourse :exec
 DELETE FROM courses WHERE course_id = $1;
 
 -- name: GetAdminCoursesForUser :many
-SELECT c.course_id, c.course_number, c.name, c.description, c.created_at, c.updated_at
+SELECT c.course_id, c.course_number, c.name, c.description, c.created_at, c.updated_at, c.deleted_at
 FROM courses c
 JOIN course_admins ca ON c.course_id = ca.course_id
-WHERE ca.user_id = $1
+WHERE ca.user_id = $1 AND c.deleted_at IS NULL
 ORDER BY c.course_number;
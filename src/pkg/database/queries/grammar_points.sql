@@ -36,10 +36,10 @@ WHERE story_id = $1
 ORDER BY grammar_point_id;
 
 -- name: GetStoriesWithGrammarPoint :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
 JOIN grammar_points gp ON s.story_id = gp.story_id
-WHERE gp.grammar_point_id = $1
+WHERE gp.grammar_point_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
 
 -- name: ClearStoryGrammarPoints :exec
@@ -0,0 +1,13 @@
+-- Grammar click heatmap queries
+
+-- name: GetGrammarPointCorrectClicks :many
+SELECT line_number, COUNT(*) AS clicks
+FROM grammar_correct_answers
+WHERE story_id = $1 AND grammar_point_id = $2
+GROUP BY line_number;
+
+-- name: GetGrammarPointIncorrectClicks :many
+SELECT gia.line_number, selected.position::integer AS position, COUNT(*) AS clicks
+FROM grammar_incorrect_answers gia, unnest(gia.selected_positions) AS selected(position)
+WHERE gia.story_id = $1 AND gia.grammar_point_id = $2
+GROUP BY gia.line_number, selected.position;
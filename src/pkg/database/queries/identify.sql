@@ -0,0 +1,28 @@
+-- Identify phase answer queries
+
+-- name: SaveIdentifyScore :exec
+INSERT INTO identify_correct_answers (user_id, story_id, line_number, target_vocab_id)
+VALUES ($1, $2, $3, $4);
+
+-- name: SaveIdentifyIncorrectAnswer :exec
+INSERT INTO identify_incorrect_answers (user_id, story_id, line_number, target_vocab_id, selected_target_vocab_id)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetUserIdentifyScores :many
+SELECT DISTINCT line_number, target_vocab_id
+FROM identify_correct_answers
+WHERE user_id = $1 AND story_id = $2
+ORDER BY line_number, target_vocab_id;
+
+-- name: DeleteIdentifyOptions :exec
+DELETE FROM identify_options
+WHERE user_id = $1 AND story_id = $2;
+
+-- name: CreateIdentifyOption :exec
+INSERT INTO identify_options (option_id, user_id, story_id, target_vocab_id)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetIdentifyOptionTarget :one
+SELECT target_vocab_id
+FROM identify_options
+WHERE option_id = $1 AND user_id = $2 AND story_id = $3;
@@ -0,0 +1,43 @@
+-- Lexicon queries
+-- Surface forms are lower case; a story's language is that of its description. A story
+-- without a description has no lexicon, and one with descriptions in several languages
+-- is ambiguous.
+
+-- name: GetStoryLanguages :many
+SELECT DISTINCT language_code
+FROM story_descriptions
+WHERE story_id = $1 AND language_code <> ''
+ORDER BY language_code;
+
+-- name: GetLexiconEntries :many
+SELECT language_code, surface_form, lexical_form, occurrences, updated_at
+FROM lexicon_entries
+WHERE language_code = sqlc.arg(language_code) AND surface_form = ANY(sqlc.arg(surface_forms)::text[])
+ORDER BY surface_form, occurrences DESC, lexical_form;
+
+-- name: LearnLexiconEntry :exec
+INSERT INTO lexicon_entries (language_code, surface_form, lexical_form)
+VALUES ($1, $2, $3)
+ON CONFLICT (language_code, surface_form, lexical_form)
+DO UPDATE SET occurrences = lexicon_entries.occurrences + 1, updated_at = CURRENT_TIMESTAMP;
+
+-- name: UnlearnLexiconEntry :exec
+-- Takes back one annotation of a mapping, dropping it once none are left
+WITH forgotten AS (
+    DELETE FROM lexicon_entries
+    WHERE language_code = $1 AND surface_form = $2 AND lexical_form = $3 AND occurrences <= 1
+)
+UPDATE lexicon_entries
+SET occurrences = occurrences - 1, updated_at = CURRENT_TIMESTAMP
+WHERE language_code = $1 AND surface_form = $2 AND lexical_form = $3 AND occurrences > 1;
+
+-- name: GetVocabSuggestionRejections :many
+SELECT story_id, line_number, position_start, position_end, word, lexical_form, rejected_at
+FROM vocab_suggestion_rejections
+WHERE story_id = $1;
+
+-- name: RejectVocabSuggestion :exec
+INSERT INTO vocab_suggestion_rejections (story_id, line_number, position_start, position_end, word, lexical_form)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (story_id, line_number, position_start, position_end, lexical_form)
+DO UPDATE SET word = EXCLUDED.word, rejected_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,93 @@
+-- LTI 1.3 platform registration and launch mapping queries
+
+-- name: UpsertLTIPlatform :one
+INSERT INTO lti_platforms (name, issuer, client_id, deployment_id, auth_login_url, auth_token_url, jwks_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (issuer, client_id) DO UPDATE
+SET name = EXCLUDED.name,
+    deployment_id = EXCLUDED.deployment_id,
+    auth_login_url = EXCLUDED.auth_login_url,
+    auth_token_url = EXCLUDED.auth_token_url,
+    jwks_url = EXCLUDED.jwks_url
+RETURNING id, name, issuer, client_id, deployment_id, auth_login_url, auth_token_url, jwks_url, created_at;
+
+-- name: GetLTIPlatform :one
+SELECT id, name, issuer, client_id, deployment_id, auth_login_url, auth_token_url, jwks_url, created_at
+FROM lti_platforms
+WHERE id = $1;
+
+-- name: GetLTIPlatformsByIssuer :many
+SELECT id, name, issuer, client_id, deployment_id, auth_login_url, auth_token_url, jwks_url, created_at
+FROM lti_platforms
+WHERE issuer = $1
+ORDER BY id;
+
+-- name: ListLTIPlatforms :many
+SELECT id, name, issuer, client_id, deployment_id, auth_login_url, auth_token_url, jwks_url, created_at
+FROM lti_platforms
+ORDER BY name, id;
+
+-- name: DeleteLTIPlatform :exec
+DELETE FROM lti_platforms
+WHERE id = $1;
+
+-- name: GetLTIUser :one
+SELECT platform_id, subject, user_id, created_at
+FROM lti_users
+WHERE platform_id = $1 AND subject = $2;
+
+-- name: CreateLTIUser :one
+INSERT INTO lti_users (platform_id, subject, user_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (platform_id, subject) DO UPDATE
+SET user_id = EXCLUDED.user_id
+RETURNING platform_id, subject, user_id, created_at;
+
+-- name: AddLTIUserContext :exec
+INSERT INTO lti_user_contexts (platform_id, subject, lti_context_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (platform_id, subject, lti_context_id) DO UPDATE
+SET launched_at = CURRENT_TIMESTAMP;
+
+-- name: RelinkLTIUser :execrows
+UPDATE lti_users
+SET user_id = sqlc.arg(to_user_id)
+WHERE user_id = sqlc.arg(from_user_id);
+
+-- name: GetLTIContext :one
+SELECT id, platform_id, context_id, course_id, title, lineitems_url, updated_at
+FROM lti_contexts
+WHERE platform_id = $1 AND context_id = $2;
+
+-- name: CreateLTIContext :one
+INSERT INTO lti_contexts (platform_id, context_id, course_id, title, lineitems_url)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, platform_id, context_id, course_id, title, lineitems_url, updated_at;
+
+-- name: UpdateLTIContext :one
+UPDATE lti_contexts
+SET title = $2,
+    lineitems_url = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, platform_id, context_id, course_id, title, lineitems_url, updated_at;
+
+-- name: GetLTILineItem :one
+SELECT lti_context_id, story_id, line_item_url
+FROM lti_line_items
+WHERE lti_context_id = $1 AND story_id = $2;
+
+-- name: UpsertLTILineItem :exec
+INSERT INTO lti_line_items (lti_context_id, story_id, line_item_url)
+VALUES ($1, $2, $3)
+ON CONFLICT (lti_context_id, story_id) DO UPDATE
+SET line_item_url = EXCLUDED.line_item_url;
+
+-- name: GetLTIScoreTargets :many
+SELECT lu.platform_id, lu.subject, lc.id AS lti_context_id, lc.course_id, lc.lineitems_url
+FROM lti_user_contexts luc
+JOIN lti_users lu ON lu.platform_id = luc.platform_id AND lu.subject = luc.subject
+JOIN lti_contexts lc ON lc.id = luc.lti_context_id AND lc.platform_id = luc.platform_id
+JOIN stories s ON s.course_id = lc.course_id
+WHERE lu.user_id = $1 AND s.story_id = $2 AND lc.lineitems_url <> ''
+ORDER BY lc.id;
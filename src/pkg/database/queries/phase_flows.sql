@@ -0,0 +1,33 @@
+-- Phase flow queries
+
+-- name: GetCoursePhaseFlow :one
+SELECT course_id, phases, updated_at
+FROM course_phase_flows
+WHERE course_id = $1;
+
+-- name: UpsertCoursePhaseFlow :one
+INSERT INTO course_phase_flows (course_id, phases)
+VALUES ($1, $2)
+ON CONFLICT (course_id) DO UPDATE
+SET phases = EXCLUDED.phases, updated_at = CURRENT_TIMESTAMP
+RETURNING course_id, phases, updated_at;
+
+-- name: DeleteCoursePhaseFlow :exec
+DELETE FROM course_phase_flows
+WHERE course_id = $1;
+
+-- name: GetStoryPhaseFlow :one
+SELECT story_id, phases, updated_at
+FROM story_phase_flows
+WHERE story_id = $1;
+
+-- name: UpsertStoryPhaseFlow :one
+INSERT INTO story_phase_flows (story_id, phases)
+VALUES ($1, $2)
+ON CONFLICT (story_id) DO UPDATE
+SET phases = EXCLUDED.phases, updated_at = CURRENT_TIMESTAMP
+RETURNING story_id, phases, updated_at;
+
+-- name: DeleteStoryPhaseFlow :exec
+DELETE FROM story_phase_flows
+WHERE story_id = $1;
@@ -0,0 +1,91 @@
+-- Produce phase queries
+
+-- name: CreateProduceSegment :one
+INSERT INTO produce_segments (story_id, start_line, end_line, english_prompt, reference_text, grammar_point_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, story_id, start_line, end_line, english_prompt, reference_text, grammar_point_id, created_at;
+
+-- name: GetProduceSegment :one
+SELECT id, story_id, start_line, end_line, english_prompt, reference_text, grammar_point_id, created_at
+FROM produce_segments
+WHERE id = $1;
+
+-- name: GetStoryProduceSegments :many
+SELECT id, story_id, start_line, end_line, english_prompt, reference_text, grammar_point_id, created_at
+FROM produce_segments
+WHERE story_id = $1
+ORDER BY start_line, id;
+
+-- name: CountStoryProduceSegments :one
+SELECT COUNT(*) FROM produce_segments
+WHERE story_id = $1;
+
+-- name: UpdateProduceSegment :one
+UPDATE produce_segments
+SET start_line = $3, end_line = $4, english_prompt = $5, reference_text = $6, grammar_point_id = $7
+WHERE id = $1 AND story_id = $2
+RETURNING id, story_id, start_line, end_line, english_prompt, reference_text, grammar_point_id, created_at;
+
+-- name: DeleteProduceSegment :exec
+DELETE FROM produce_segments
+WHERE id = $1 AND story_id = $2;
+
+-- name: DeleteStoryProduceSegments :exec
+DELETE FROM produce_segments
+WHERE story_id = $1;
+
+-- name: GetProduceExplanation :one
+SELECT story_id, explanation, updated_at
+FROM produce_explanations
+WHERE story_id = $1;
+
+-- name: UpsertProduceExplanation :one
+INSERT INTO produce_explanations (story_id, explanation)
+VALUES ($1, $2)
+ON CONFLICT (story_id) DO UPDATE
+SET explanation = EXCLUDED.explanation, updated_at = CURRENT_TIMESTAMP
+RETURNING story_id, explanation, updated_at;
+
+-- name: DeleteProduceExplanation :exec
+DELETE FROM produce_explanations
+WHERE story_id = $1;
+
+-- name: UpsertProduceSubmission :one
+INSERT INTO produce_submissions (user_id, story_id, segment_id, student_text)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, segment_id) DO UPDATE
+SET student_text = EXCLUDED.student_text,
+    updated_at = CURRENT_TIMESTAMP,
+    ai_score = CASE WHEN produce_submissions.student_text = EXCLUDED.student_text THEN produce_submissions.ai_score END,
+    ai_feedback = CASE WHEN produce_submissions.student_text = EXCLUDED.student_text THEN produce_submissions.ai_feedback END,
+    graded_at = CASE WHEN produce_submissions.student_text = EXCLUDED.student_text THEN produce_submissions.graded_at END,
+    grading_status = CASE WHEN produce_submissions.student_text = EXCLUDED.student_text THEN produce_submissions.grading_status ELSE 'pending' END,
+    grading_attempts = CASE WHEN produce_submissions.student_text = EXCLUDED.student_text THEN produce_submissions.grading_attempts ELSE 0 END
+RETURNING id, user_id, story_id, segment_id, student_text, submitted_at, updated_at, ai_score, ai_feedback, grading_status, grading_attempts, graded_at;
+
+-- name: GetUserProduceSubmissions :many
+SELECT id, user_id, story_id, segment_id, student_text, submitted_at, updated_at, ai_score, ai_feedback, grading_status, grading_attempts, graded_at
+FROM produce_submissions
+WHERE user_id = $1 AND story_id = $2
+ORDER BY segment_id;
+
+-- name: SetProduceSubmissionGrade :execrows
+-- Only stores the grade while the submission still holds the graded answer
+UPDATE produce_submissions
+SET ai_score = $2, ai_feedback = $3, grading_status = 'graded', graded_at = CURRENT_TIMESTAMP,
+    grading_attempts = grading_attempts + 1
+WHERE id = $1 AND student_text = $4;
+
+-- name: MarkProduceSubmissionPending :exec
+UPDATE produce_submissions
+SET ai_score = NULL, ai_feedback = NULL, grading_status = 'pending', graded_at = NULL,
+    grading_attempts = grading_attempts + 1
+WHERE id = $1 AND student_text = $2;
+
+-- name: GetPendingProduceSubmissions :many
+SELECT ps.id, ps.student_text, seg.reference_text, seg.grammar_point_id
+FROM produce_submissions ps
+JOIN produce_segments seg ON ps.segment_id = seg.id
+WHERE ps.grading_status = 'pending' AND ps.grading_attempts < $1
+ORDER BY ps.updated_at
+LIMIT $2;
@@ -0,0 +1,54 @@
+-- Recall phase queries
+
+-- name: CreateRecallSentence :one
+INSERT INTO recall_sentences (story_id, sequence_order, sentence_text, target_vocab_id, image_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, story_id, sequence_order, sentence_text, target_vocab_id, image_id, created_at;
+
+-- name: GetRecallSentence :one
+SELECT id, story_id, sequence_order, sentence_text, target_vocab_id, image_id, created_at
+FROM recall_sentences
+WHERE id = $1;
+
+-- name: GetStoryRecallSentences :many
+SELECT id, story_id, sequence_order, sentence_text, target_vocab_id, image_id, created_at
+FROM recall_sentences
+WHERE story_id = $1
+ORDER BY sequence_order;
+
+-- name: CountStoryRecallSentences :one
+SELECT COUNT(*) FROM recall_sentences
+WHERE story_id = $1;
+
+-- name: UpdateRecallSentence :one
+UPDATE recall_sentences
+SET sentence_text = $3, target_vocab_id = $4, image_id = $5
+WHERE id = $1 AND story_id = $2
+RETURNING id, story_id, sequence_order, sentence_text, target_vocab_id, image_id, created_at;
+
+-- name: SetRecallSentenceOrder :exec
+UPDATE recall_sentences
+SET sequence_order = $3
+WHERE id = $1 AND story_id = $2;
+
+-- name: DeleteRecallSentence :exec
+DELETE FROM recall_sentences
+WHERE id = $1 AND story_id = $2;
+
+-- name: DeleteStoryRecallSentences :exec
+DELETE FROM recall_sentences
+WHERE story_id = $1;
+
+-- name: SaveRecallScore :exec
+INSERT INTO recall_correct_answers (user_id, story_id, recall_sentence_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, recall_sentence_id) DO NOTHING;
+
+-- name: SaveRecallIncorrectAnswer :exec
+INSERT INTO recall_incorrect_answers (user_id, story_id, recall_sentence_id, submitted_position)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetUserStoryRecallSummary :one
+SELECT
+    (SELECT COUNT(*) FROM recall_correct_answers rca WHERE rca.user_id = $1 AND rca.story_id = $2) as correct_count,
+    (SELECT COUNT(*) FROM recall_incorrect_answers ria WHERE ria.user_id = $1 AND ria.story_id = $2) as incorrect_count;
@@ -8,6 +8,12 @@ VALUES ($1, $2, $3, $4);
 INSERT INTO grammar_incorrect_answers (user_id, story_id, line_number, grammar_point_id, selected_line, selected_positions)
 VALUES ($1, $2, $3, $4, $5, $6);
 
+-- name: RemapGrammarIncorrectSelectedLines :exec
+UPDATE grammar_incorrect_answers gia
+SET selected_line = m.new_line
+FROM unnest(sqlc.arg(old_lines)::int[], sqlc.arg(new_lines)::int[]) AS m(old_line, new_line)
+WHERE gia.story_id = sqlc.arg(story_id) AND gia.selected_line = m.old_line;
+
 -- name: GetUserVocabScores :many
 SELECT vs.line_number, vs.vocab_item_id, vs.attempted_at, vi.word, vi.lexical_form
 FROM vocab_correct_answers vs
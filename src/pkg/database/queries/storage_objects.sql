@@ -0,0 +1,15 @@
+-- Shared storage object queries
+-- Cloned stories reuse the files of the original, so a file may only be removed
+-- from storage once no other story refers to it
+
+-- name: CountStorageObjectUses :one
+SELECT (
+    (SELECT COUNT(*) FROM line_audio_files la
+     WHERE la.file_bucket = sqlc.arg(file_bucket) AND la.file_path = sqlc.arg(file_path) AND la.story_id <> sqlc.arg(story_id)::int)
+  + (SELECT COUNT(*) FROM story_images si
+     WHERE si.file_bucket = sqlc.arg(file_bucket) AND si.file_path = sqlc.arg(file_path) AND si.story_id <> sqlc.arg(story_id)::int)
+  + (SELECT COUNT(*) FROM target_vocabulary tv
+     WHERE tv.audio_bucket = sqlc.arg(file_bucket) AND tv.audio_path = sqlc.arg(file_path) AND tv.story_id <> sqlc.arg(story_id)::int)
+  + (SELECT COUNT(*) FROM target_vocabulary tv
+     WHERE tv.image_bucket = sqlc.arg(file_bucket) AND tv.image_path = sqlc.arg(file_path) AND tv.story_id <> sqlc.arg(story_id)::int)
+)::bigint AS uses;
@@ -1,13 +1,14 @@
 -- Core story operations
 
 -- name: GetStory :one
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
-WHERE s.story_id = $1;
+WHERE s.story_id = $1 AND s.deleted_at IS NULL;
 
 -- name: GetAllStories :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
+WHERE s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
 
 -- name: CreateStory :one
@@ -15,6 +16,15 @@ INSERT INTO stories (week_number, day_letter, video_url, author_id, author_name,
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING story_id, last_revision;
 
+-- name: CreateStoryWithID :exec
+-- Creates a story under an ID taken from ReserveStoryID
+INSERT INTO stories (story_id, week_number, day_letter, video_url, author_id, author_name, course_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ReserveStoryID :one
+-- Takes a story ID before the story exists, e.g. to store its files first
+SELECT nextval(pg_get_serial_sequence('stories', 'story_id'))::integer AS story_id;
+
 -- name: UpdateStory :exec
 UPDATE stories
 SET week_number = $2, day_letter = $3, video_url = $4, author_id = $5, author_name = $6, course_id = $7, last_revision = CURRENT_TIMESTAMP
@@ -27,7 +37,7 @@ DELETE FROM stories WHERE story_id = $1;
 SELECT DISTINCT s.story_id, s.week_number, s.day_letter, st.title, s.course_id
 FROM stories s
 JOIN story_titles st ON s.story_id = st.story_id
-WHERE st.language_code = $1 OR $1 = ''
+WHERE (st.language_code = $1 OR $1 = '') AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
 
 -- name: GetAllStoriesForUser :many
@@ -37,6 +47,7 @@ JOIN story_titles st ON s.story_id = st.story_id
 LEFT JOIN course_users cu ON s.course_id = cu.course_id AND cu.user_id = $2
 LEFT JOIN course_admins ca ON s.course_id = ca.course_id AND ca.user_id = $2
 WHERE (st.language_code = $1 OR $1 = '')
+  AND s.deleted_at IS NULL
   AND (s.course_id IS NULL OR cu.user_id IS NOT NULL OR ca.user_id IS NOT NULL)
   AND (cu.status = 'active' OR cu.status IS NULL OR ca.user_id IS NOT NULL)
 ORDER BY s.week_number, s.day_letter;
@@ -45,6 +56,7 @@ ORDER BY s.week_number, s.day_letter;
 SELECT DISTINCT s.story_id, s.week_number, s.day_letter, st.title, st.language_code
 FROM stories s
 JOIN story_titles st ON s.story_id = st.story_id
+WHERE s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
 
 -- name: GetStoryWithDescription :one
@@ -52,27 +64,27 @@ SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.
        sd.language_code, sd.description_text
 FROM stories s
 LEFT JOIN story_descriptions sd ON s.story_id = sd.story_id
-WHERE s.story_id = $1;
+WHERE s.story_id = $1 AND s.deleted_at IS NULL;
 
 -- name: GetStoriesByCourse :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
-WHERE s.course_id = $1
+WHERE s.course_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
 
 -- name: GetCourseIdForStory :one
-SELECT course_id FROM stories WHERE story_id = $1;
+SELECT course_id FROM stories WHERE story_id = $1 AND deleted_at IS NULL;
 
 -- name: GetStoriesForUserCourses :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
 JOIN course_admins ca ON s.course_id = ca.course_id
-WHERE ca.user_id = $1
+WHERE ca.user_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
 
 -- name: GetCourseStoriesWithTitles :many
 SELECT s.story_id, s.week_number, s.day_letter, st.title
 FROM stories s
 JOIN story_titles st ON s.story_id = st.story_id AND st.language_code = $2
-WHERE s.course_id = $1
+WHERE s.course_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter;
@@ -24,6 +24,12 @@ SELECT description_text
 FROM story_descriptions
 WHERE story_id = $1 AND language_code = $2;
 
+-- name: GetStoryDescriptions :many
+SELECT story_id, language_code, description_text
+FROM story_descriptions
+WHERE story_id = $1
+ORDER BY language_code;
+
 -- name: UpsertStoryDescription :exec
 INSERT INTO story_descriptions (story_id, language_code, description_text)
 VALUES ($1, $2, $3)
@@ -53,6 +59,14 @@ WHERE story_id = $1 AND line_number = $2;
 INSERT INTO story_lines (story_id, line_number, text)
 VALUES ($1, $2, $3);
 
+-- name: UpdateStoryLineText :exec
+UPDATE story_lines SET text = $3
+WHERE story_id = $1 AND line_number = $2;
+
+-- name: RenumberStoryLine :exec
+UPDATE story_lines SET line_number = sqlc.arg(new_line_number)
+WHERE story_id = sqlc.arg(story_id) AND line_number = sqlc.arg(line_number);
+
 -- name: DeleteStoryLine :exec
 DELETE FROM story_lines WHERE story_id = $1 AND line_number = $2;
 
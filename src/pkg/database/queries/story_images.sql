@@ -0,0 +1,25 @@
+-- Story image management queries
+
+-- name: CreateStoryImage :one
+INSERT INTO story_images (story_id, file_path, file_bucket, label, content_type, size_bytes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING image_id, story_id, file_path, file_bucket, label, content_type, size_bytes, created_at;
+
+-- name: GetStoryImage :one
+SELECT image_id, story_id, file_path, file_bucket, label, content_type, size_bytes, created_at
+FROM story_images
+WHERE image_id = $1;
+
+-- name: GetStoryImages :many
+SELECT image_id, story_id, file_path, file_bucket, label, content_type, size_bytes, created_at
+FROM story_images
+WHERE story_id = $1
+ORDER BY label, created_at;
+
+-- name: DeleteStoryImage :exec
+DELETE FROM story_images
+WHERE image_id = $1;
+
+-- name: DeleteStoryImages :exec
+DELETE FROM story_images
+WHERE story_id = $1;
@@ -0,0 +1,31 @@
+-- Story revision queries
+-- Every admin write stores a full Story JSON snapshot, numbered per story
+
+-- name: CreateStoryRevision :exec
+INSERT INTO story_revisions (story_id, revision_number, action, snapshot, created_by)
+SELECT sqlc.arg(story_id), COALESCE(MAX(revision_number), 0) + 1, sqlc.arg(action), sqlc.arg(snapshot), sqlc.arg(created_by)
+FROM story_revisions
+WHERE story_id = sqlc.arg(story_id);
+
+-- name: LockStoryForRevision :exec
+-- Serializes revision numbering: CreateStoryRevision runs while the story row is locked
+SELECT story_id FROM stories
+WHERE story_id = $1
+FOR UPDATE;
+
+-- name: ListStoryRevisions :many
+SELECT revision_id, story_id, revision_number, action, created_by, created_at
+FROM story_revisions
+WHERE story_id = $1
+ORDER BY revision_number DESC;
+
+-- name: GetStoryRevision :one
+SELECT revision_id, story_id, revision_number, action, snapshot, created_by, created_at
+FROM story_revisions
+WHERE story_id = $1 AND revision_number = $2;
+
+-- name: CountVocabItemAnswers :one
+SELECT (
+    (SELECT COUNT(*) FROM vocab_correct_answers vca WHERE vca.vocab_item_id = ANY(sqlc.arg(vocab_item_ids)::int[]))
+  + (SELECT COUNT(*) FROM vocab_incorrect_answers via WHERE via.vocab_item_id = ANY(sqlc.arg(vocab_item_ids)::int[]))
+)::bigint AS answers;
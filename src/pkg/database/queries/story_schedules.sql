@@ -0,0 +1,31 @@
+-- Story release schedule queries
+
+-- name: GetStorySchedule :one
+SELECT story_id, available_from, due_at, late_until, updated_at
+FROM story_schedules
+WHERE story_id = $1;
+
+-- name: GetStorySchedules :many
+SELECT story_id, available_from, due_at, late_until, updated_at
+FROM story_schedules
+WHERE story_id = ANY(sqlc.arg(story_ids)::int[]);
+
+-- name: UpsertStorySchedule :one
+INSERT INTO story_schedules (story_id, available_from, due_at, late_until)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (story_id) DO UPDATE
+SET available_from = EXCLUDED.available_from,
+    due_at = EXCLUDED.due_at,
+    late_until = EXCLUDED.late_until,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING story_id, available_from, due_at, late_until, updated_at;
+
+-- name: ShiftCourseSchedule :many
+UPDATE story_schedules ss
+SET available_from = ss.available_from + sqlc.arg(days)::int * INTERVAL '1 day',
+    due_at = ss.due_at + sqlc.arg(days)::int * INTERVAL '1 day',
+    late_until = ss.late_until + sqlc.arg(days)::int * INTERVAL '1 day',
+    updated_at = CURRENT_TIMESTAMP
+FROM stories s
+WHERE s.story_id = ss.story_id AND s.course_id = sqlc.arg(course_id) AND s.deleted_at IS NULL
+RETURNING ss.story_id;
@@ -0,0 +1,58 @@
+-- Story score snapshot queries
+
+-- name: CreateStoryScore :one
+INSERT INTO story_scores (
+    user_id, story_id, formula_version, reason, vocab_correct, vocab_incorrect, vocab_total,
+    vocab_accuracy, grammar_correct, grammar_incorrect, grammar_total, grammar_accuracy,
+    recall_correct, recall_incorrect, recall_total, recall_accuracy, translation_completed,
+    vocab_time_seconds, grammar_time_seconds, translation_time_seconds, video_time_seconds,
+    total_time_seconds, overall_accuracy, late
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+    $20, $21, $22, $23, $24
+)
+RETURNING id, user_id, story_id, formula_version, reason, vocab_correct, vocab_incorrect,
+       vocab_total, vocab_accuracy, grammar_correct, grammar_incorrect, grammar_total,
+       grammar_accuracy, recall_correct, recall_incorrect, recall_total, recall_accuracy,
+       translation_completed, vocab_time_seconds, grammar_time_seconds,
+       translation_time_seconds, video_time_seconds, total_time_seconds, overall_accuracy,
+       computed_at, late;
+
+-- name: GetLatestStoryScore :one
+SELECT id, user_id, story_id, formula_version, reason, vocab_correct, vocab_incorrect,
+       vocab_total, vocab_accuracy, grammar_correct, grammar_incorrect, grammar_total,
+       grammar_accuracy, recall_correct, recall_incorrect, recall_total, recall_accuracy,
+       translation_completed, vocab_time_seconds, grammar_time_seconds,
+       translation_time_seconds, video_time_seconds, total_time_seconds, overall_accuracy,
+       computed_at, late
+FROM story_scores
+WHERE user_id = $1 AND story_id = $2
+ORDER BY computed_at DESC, id DESC
+LIMIT 1;
+
+-- name: GetStoryScoreHistory :many
+SELECT id, user_id, story_id, formula_version, reason, vocab_correct, vocab_incorrect,
+       vocab_total, vocab_accuracy, grammar_correct, grammar_incorrect, grammar_total,
+       grammar_accuracy, recall_correct, recall_incorrect, recall_total, recall_accuracy,
+       translation_completed, vocab_time_seconds, grammar_time_seconds,
+       translation_time_seconds, video_time_seconds, total_time_seconds, overall_accuracy,
+       computed_at, late
+FROM story_scores
+WHERE user_id = $1 AND story_id = $2
+ORDER BY computed_at DESC, id DESC;
+
+-- name: GetStoryLatestScores :many
+SELECT DISTINCT ON (user_id) id, user_id, story_id, formula_version, reason, vocab_correct, vocab_incorrect,
+       vocab_total, vocab_accuracy, grammar_correct, grammar_incorrect, grammar_total,
+       grammar_accuracy, recall_correct, recall_incorrect, recall_total, recall_accuracy,
+       translation_completed, vocab_time_seconds, grammar_time_seconds,
+       translation_time_seconds, video_time_seconds, total_time_seconds, overall_accuracy,
+       computed_at, late
+FROM story_scores
+WHERE story_id = $1
+ORDER BY user_id, computed_at DESC, id DESC;
+
+-- name: GetScoredUserStories :many
+SELECT DISTINCT user_id, story_id
+FROM story_scores
+ORDER BY story_id, user_id;
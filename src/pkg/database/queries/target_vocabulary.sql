@@ -0,0 +1,54 @@
+-- Target vocabulary queries
+
+-- name: CreateTargetVocab :one
+INSERT INTO target_vocabulary (story_id, lexical_form)
+VALUES ($1, $2)
+RETURNING id, story_id, lexical_form, audio_path, audio_bucket, image_path, image_bucket, created_at;
+
+-- name: GetTargetVocab :one
+SELECT id, story_id, lexical_form, audio_path, audio_bucket, image_path, image_bucket, created_at
+FROM target_vocabulary
+WHERE id = $1;
+
+-- name: GetStoryTargetVocab :many
+SELECT tv.id, tv.story_id, tv.lexical_form, tv.audio_path, tv.audio_bucket, tv.image_path, tv.image_bucket, tv.created_at,
+       COUNT(vi.id) AS occurrences
+FROM target_vocabulary tv
+LEFT JOIN vocabulary_items vi ON vi.story_id = tv.story_id AND vi.lexical_form = tv.lexical_form
+WHERE tv.story_id = $1
+GROUP BY tv.id
+ORDER BY tv.id;
+
+-- name: CountStoryTargetVocab :one
+SELECT COUNT(*) FROM target_vocabulary
+WHERE story_id = $1;
+
+-- name: CountLexicalFormOccurrences :one
+SELECT COUNT(*) FROM vocabulary_items
+WHERE story_id = $1 AND lexical_form = $2;
+
+-- name: UpdateTargetVocabLexicalForm :one
+UPDATE target_vocabulary
+SET lexical_form = $3
+WHERE id = $1 AND story_id = $2
+RETURNING id, story_id, lexical_form, audio_path, audio_bucket, image_path, image_bucket, created_at;
+
+-- name: SetTargetVocabAudio :one
+UPDATE target_vocabulary
+SET audio_path = $3, audio_bucket = $4
+WHERE id = $1 AND story_id = $2
+RETURNING id, story_id, lexical_form, audio_path, audio_bucket, image_path, image_bucket, created_at;
+
+-- name: SetTargetVocabImage :one
+UPDATE target_vocabulary
+SET image_path = $3, image_bucket = $4
+WHERE id = $1 AND story_id = $2
+RETURNING id, story_id, lexical_form, audio_path, audio_bucket, image_path, image_bucket, created_at;
+
+-- name: DeleteTargetVocab :exec
+DELETE FROM target_vocabulary
+WHERE id = $1;
+
+-- name: DeleteStoryTargetVocab :exec
+DELETE FROM target_vocabulary
+WHERE story_id = $1;
@@ -42,6 +42,17 @@ SELECT
     EXISTS(SELECT 1 FROM translation_requests tr WHERE tr.user_id = $1 AND tr.story_id = $2) as completed,
     COALESCE((SELECT tr2.requested_lines FROM translation_requests tr2 WHERE tr2.user_id = $1 AND tr2.story_id = $2), ARRAY[]::INTEGER[]) as requested_lines;
 
+-- name: RemapTranslationRequestLines :exec
+UPDATE translation_requests tr
+SET requested_lines = ARRAY(
+    SELECT m.new_line
+    FROM unnest(tr.requested_lines) WITH ORDINALITY AS r(line_number, ord)
+    JOIN unnest(sqlc.arg(old_lines)::int[], sqlc.arg(new_lines)::int[]) AS m(old_line, new_line) ON m.old_line = r.line_number
+    WHERE m.new_line > 0
+    ORDER BY r.ord
+)
+WHERE tr.story_id = sqlc.arg(story_id);
+
 -- name: UpdateTranslationRequest :exec
 UPDATE translation_requests 
 SET requested_lines = $3
@@ -0,0 +1,74 @@
+-- Trash queries
+-- Deleted stories and courses keep their rows with deleted_at set until restored or purged.
+-- A course's stories are trashed with it and share its deleted_at, which is how they are restored together.
+
+-- name: TrashStory :execrows
+UPDATE stories SET deleted_at = $2
+WHERE story_id = $1 AND deleted_at IS NULL;
+
+-- name: TrashCourse :execrows
+UPDATE courses SET deleted_at = $2
+WHERE course_id = $1 AND deleted_at IS NULL;
+
+-- name: TrashCourseStories :many
+UPDATE stories SET deleted_at = $2
+WHERE course_id = $1 AND deleted_at IS NULL
+RETURNING story_id;
+
+-- name: GetTrashedStory :one
+SELECT s.story_id, s.course_id, s.deleted_at, (c.deleted_at IS NOT NULL)::bool AS course_trashed
+FROM stories s
+LEFT JOIN courses c ON c.course_id = s.course_id
+WHERE s.story_id = $1 AND s.deleted_at IS NOT NULL;
+
+-- name: RestoreStory :exec
+UPDATE stories SET deleted_at = NULL
+WHERE story_id = $1;
+
+-- name: RestoreCourseStories :exec
+UPDATE stories s SET deleted_at = NULL
+FROM courses c
+WHERE c.course_id = $1 AND s.course_id = c.course_id AND s.deleted_at = c.deleted_at;
+
+-- name: IsTrashedCourseNumberTaken :one
+-- Whether a course outside the trash now has the number of a trashed course
+SELECT EXISTS (
+    SELECT 1 FROM courses c
+    JOIN courses t ON t.course_number = c.course_number
+    WHERE t.course_id = $1 AND c.course_id <> t.course_id AND c.deleted_at IS NULL
+)::bool AS taken;
+
+-- name: RestoreCourse :execrows
+UPDATE courses SET deleted_at = NULL
+WHERE course_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: ListTrashedStories :many
+SELECT s.story_id, s.week_number, s.day_letter, s.course_id, COALESCE(st.title, '') AS title, s.deleted_at,
+       (c.deleted_at IS NOT NULL)::bool AS course_trashed
+FROM stories s
+LEFT JOIN story_titles st ON st.story_id = s.story_id AND st.language_code = 'en'
+LEFT JOIN courses c ON c.course_id = s.course_id
+WHERE s.deleted_at IS NOT NULL
+ORDER BY s.deleted_at DESC, s.story_id;
+
+-- name: ListTrashedCourses :many
+SELECT c.course_id, c.course_number, c.name, c.deleted_at,
+       (SELECT COUNT(*) FROM stories s WHERE s.course_id = c.course_id AND s.deleted_at = c.deleted_at) AS story_count
+FROM courses c
+WHERE c.deleted_at IS NOT NULL
+ORDER BY c.deleted_at DESC, c.course_id;
+
+-- name: GetExpiredTrashedStoryIDs :many
+SELECT story_id FROM stories
+WHERE deleted_at < sqlc.arg(cutoff)::timestamp
+ORDER BY story_id;
+
+-- name: GetExpiredTrashedCourseIDs :many
+SELECT course_id FROM courses
+WHERE deleted_at < sqlc.arg(cutoff)::timestamp
+ORDER BY course_id;
+
+-- name: GetCourseStoryIDs :many
+SELECT story_id FROM stories
+WHERE course_id = $1
+ORDER BY story_id;
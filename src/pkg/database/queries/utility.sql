@@ -1,5 +1,5 @@
 -- name: StoryExists :one
-SELECT EXISTS(SELECT 1 FROM stories WHERE story_id = $1);
+SELECT EXISTS(SELECT 1 FROM stories WHERE story_id = $1 AND deleted_at IS NULL);
 
 -- name: LineExists :one
 SELECT EXISTS(SELECT 1 FROM story_lines WHERE story_id = $1 AND line_number = $2);
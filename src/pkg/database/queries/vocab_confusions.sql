@@ -0,0 +1,56 @@
+-- Vocabulary confusion queries
+
+-- name: GetStoryVocabConfusions :many
+SELECT vi.lexical_form, via.incorrect_answer, COUNT(*) AS times, COUNT(DISTINCT via.user_id) AS students
+FROM vocab_incorrect_answers via
+JOIN vocabulary_items vi ON vi.id = via.vocab_item_id
+WHERE via.story_id = $1
+GROUP BY vi.lexical_form, via.incorrect_answer
+ORDER BY times DESC, vi.lexical_form, via.incorrect_answer;
+
+-- name: GetCourseVocabConfusions :many
+SELECT vi.lexical_form, via.incorrect_answer, COUNT(*) AS times, COUNT(DISTINCT via.user_id) AS students
+FROM vocab_incorrect_answers via
+JOIN vocabulary_items vi ON vi.id = via.vocab_item_id
+JOIN stories s ON s.story_id = via.story_id
+JOIN course_users cu ON cu.course_id = s.course_id AND cu.user_id = via.user_id
+WHERE s.course_id = sqlc.arg(course_id)
+  AND (sqlc.arg(status)::text = '' OR cu.status = sqlc.arg(status)::text)
+GROUP BY vi.lexical_form, via.incorrect_answer
+ORDER BY times DESC, vi.lexical_form, via.incorrect_answer;
+
+-- name: GetStoryLexicalForms :many
+SELECT DISTINCT lexical_form
+FROM vocabulary_items
+WHERE story_id = $1
+ORDER BY lexical_form;
+
+-- name: GetCourseLexicalForms :many
+SELECT DISTINCT vi.lexical_form
+FROM vocabulary_items vi
+JOIN stories s ON s.story_id = vi.story_id
+WHERE s.course_id = $1
+ORDER BY vi.lexical_form;
+
+-- name: GetStoryVocabContrasts :many
+SELECT id, story_id, form_a, form_b, created_at
+FROM vocab_contrast_pairs
+WHERE story_id = $1
+ORDER BY form_a, form_b;
+
+-- name: GetCourseVocabContrasts :many
+SELECT vcp.id, vcp.story_id, vcp.form_a, vcp.form_b, vcp.created_at
+FROM vocab_contrast_pairs vcp
+JOIN stories s ON s.story_id = vcp.story_id
+WHERE s.course_id = $1
+ORDER BY vcp.form_a, vcp.form_b, vcp.story_id;
+
+-- name: CreateVocabContrast :one
+INSERT INTO vocab_contrast_pairs (story_id, form_a, form_b)
+VALUES ($1, $2, $3)
+ON CONFLICT (story_id, form_a, form_b) DO UPDATE SET form_a = EXCLUDED.form_a
+RETURNING id, story_id, form_a, form_b, created_at;
+
+-- name: DeleteVocabContrast :execrows
+DELETE FROM vocab_contrast_pairs
+WHERE id = $1 AND story_id = $2;
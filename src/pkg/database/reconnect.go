@@ -37,16 +37,14 @@ func IsConnectionError(err error) bool {
 // ReconnectableDBTX wraps pgxpool.Pool with SQLC's DBTX interface and automatic reconnection
 // This ensures SQLC-generated queries benefit from reconnection logic
 type ReconnectableDBTX struct {
-	pool      *pgxpool.Pool
-	connStr   string
-	schemaSQL string
+	pool    *pgxpool.Pool
+	connStr string
 }
 
 // NewReconnectableDBTX creates a new DBTX wrapper with reconnection support
-func NewReconnectableDBTX(connStr string, schemaSQL string) (*ReconnectableDBTX, error) {
+func NewReconnectableDBTX(connStr string) (*ReconnectableDBTX, error) {
 	dbtx := &ReconnectableDBTX{
-		connStr:   connStr,
-		schemaSQL: schemaSQL,
+		connStr: connStr,
 	}
 
 	if err := dbtx.reconnect(); err != nil {
@@ -77,13 +75,6 @@ func (d *ReconnectableDBTX) reconnect() error {
 		return fmt.Errorf("failed to ping: %w", err)
 	}
 
-	if d.schemaSQL != "" {
-		if _, err := pool.Exec(context.Background(), d.schemaSQL); err != nil {
-			pool.Close()
-			return fmt.Errorf("failed to execute schema: %w", err)
-		}
-	}
-
 	// Close old pool if exists
 	if d.pool != nil {
 		d.pool.Close()
@@ -203,15 +194,14 @@ func InitDBWithReconnect(dbPath string) (Store, error) {
 		return &mockStore{}, nil
 	}
 
-	// Read schema
-	schema, err := schemaFS.ReadFile("schema.sql")
-	if err != nil {
-		return nil, err
-	}
-
 	if usePool {
+		// Migrate once here; reconnects only rebuild the pool
+		if err := migrateOnStartup(connStr); err != nil {
+			return nil, err
+		}
+
 		// Create reconnectable DBTX wrapper for SQLC compatibility
-		dbtx, err := NewReconnectableDBTX(connStr, string(schema))
+		dbtx, err := NewReconnectableDBTX(connStr)
 		if err != nil {
 			return nil, err
 		}
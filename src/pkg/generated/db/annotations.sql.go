@@ -478,6 +478,76 @@ func (q *Queries) GetStoryFootnotesWithReferences(ctx context.Context, storyID p
 	return items, nil
 }
 
+const getStoryGrammarItems = `-- name: GetStoryGrammarItems :many
+SELECT id, story_id, line_number, grammar_point_id, text, position_start, position_end
+FROM grammar_items
+WHERE story_id = $1
+ORDER BY line_number, position_start
+`
+
+func (q *Queries) GetStoryGrammarItems(ctx context.Context, storyID pgtype.Int4) ([]GrammarItem, error) {
+	rows, err := q.db.Query(ctx, getStoryGrammarItems, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GrammarItem{}
+	for rows.Next() {
+		var i GrammarItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.StoryID,
+			&i.LineNumber,
+			&i.GrammarPointID,
+			&i.Text,
+			&i.PositionStart,
+			&i.PositionEnd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStoryVocabularyItems = `-- name: GetStoryVocabularyItems :many
+SELECT id, story_id, line_number, word, lexical_form, position_start, position_end
+FROM vocabulary_items
+WHERE story_id = $1
+ORDER BY line_number, position_start
+`
+
+func (q *Queries) GetStoryVocabularyItems(ctx context.Context, storyID pgtype.Int4) ([]VocabularyItem, error) {
+	rows, err := q.db.Query(ctx, getStoryVocabularyItems, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []VocabularyItem{}
+	for rows.Next() {
+		var i VocabularyItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.StoryID,
+			&i.LineNumber,
+			&i.Word,
+			&i.LexicalForm,
+			&i.PositionStart,
+			&i.PositionEnd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getVocabularyItems = `-- name: GetVocabularyItems :many
 SELECT id, story_id, line_number, word, lexical_form, position_start, position_end
 FROM vocabulary_items
@@ -566,6 +636,23 @@ func (q *Queries) UpdateGrammarByPosition(ctx context.Context, arg UpdateGrammar
 	return err
 }
 
+const updateGrammarItemPosition = `-- name: UpdateGrammarItemPosition :exec
+UPDATE grammar_items
+SET position_start = $2, position_end = $3
+WHERE id = $1
+`
+
+type UpdateGrammarItemPositionParams struct {
+	ID            int32 `json:"id"`
+	PositionStart int32 `json:"position_start"`
+	PositionEnd   int32 `json:"position_end"`
+}
+
+func (q *Queries) UpdateGrammarItemPosition(ctx context.Context, arg UpdateGrammarItemPositionParams) error {
+	_, err := q.db.Exec(ctx, updateGrammarItemPosition, arg.ID, arg.PositionStart, arg.PositionEnd)
+	return err
+}
+
 const updateVocabularyByPosition = `-- name: UpdateVocabularyByPosition :exec
 UPDATE vocabulary_items
 SET word = $5, lexical_form = $6, position_start = $7, position_end = $8
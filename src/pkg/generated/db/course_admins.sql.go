@@ -77,7 +77,7 @@ const getUserCourseAdminRights = `-- name: GetUserCourseAdminRights :many
 SELECT ca.course_id, ca.assigned_at, c.course_number, c.name as course_name
 FROM course_admins ca
 JOIN courses c ON ca.course_id = c.course_id
-WHERE ca.user_id = $1
+WHERE ca.user_id = $1 AND c.deleted_at IS NULL
 ORDER BY c.course_number
 `
 
@@ -129,8 +129,9 @@ func (q *Queries) IsUserAdminOfAnyCourse(ctx context.Context, userID string) (bo
 
 const isUserCourseAdmin = `-- name: IsUserCourseAdmin :one
 SELECT EXISTS(
-    SELECT 1 FROM course_admins
-    WHERE course_id = $1 AND user_id = $2
+    SELECT 1 FROM course_admins ca
+    JOIN courses c ON c.course_id = ca.course_id
+    WHERE ca.course_id = $1 AND ca.user_id = $2 AND c.deleted_at IS NULL
 ) as is_admin
 `
 
@@ -15,7 +15,7 @@ const createCourse = `-- name: CreateCourse :one
 
 INSERT INTO courses (course_number, name, description)
 VALUES ($1, $2, $3)
-RETURNING course_id, course_number, name, description, created_at, updated_at
+RETURNING course_id, course_number, name, description, created_at, updated_at, deleted_at
 `
 
 type CreateCourseParams struct {
@@ -35,6 +35,7 @@ func (q *Queries) CreateCourse(ctx context.Context, arg CreateCourseParams) (Cou
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -49,10 +50,10 @@ func (q *Queries) DeleteCourse(ctx context.Context, courseID int32) error {
 }
 
 const getAdminCoursesForUser = `-- name: GetAdminCoursesForUser :many
-SELECT c.course_id, c.course_number, c.name, c.description, c.created_at, c.updated_at
+SELECT c.course_id, c.course_number, c.name, c.description, c.created_at, c.updated_at, c.deleted_at
 FROM courses c
 JOIN course_admins ca ON c.course_id = ca.course_id
-WHERE ca.user_id = $1
+WHERE ca.user_id = $1 AND c.deleted_at IS NULL
 ORDER BY c.course_number
 `
 
@@ -72,6 +73,7 @@ func (q *Queries) GetAdminCoursesForUser(ctx context.Context, userID string) ([]
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -84,9 +86,9 @@ func (q *Queries) GetAdminCoursesForUser(ctx context.Context, userID string) ([]
 }
 
 const getCourse = `-- name: GetCourse :one
-SELECT course_id, course_number, name, description, created_at, updated_at
+SELECT course_id, course_number, name, description, created_at, updated_at, deleted_at
 FROM courses
-WHERE course_id = $1
+WHERE course_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetCourse(ctx context.Context, courseID int32) (Course, error) {
@@ -99,14 +101,15 @@ func (q *Queries) GetCourse(ctx context.Context, courseID int32) (Course, error)
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getCourseByNumber = `-- name: GetCourseByNumber :one
-SELECT course_id, course_number, name, description, created_at, updated_at
+SELECT course_id, course_number, name, description, created_at, updated_at, deleted_at
 FROM courses
-WHERE course_number = $1
+WHERE course_number = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetCourseByNumber(ctx context.Context, courseNumber string) (Course, error) {
@@ -119,13 +122,15 @@ func (q *Queries) GetCourseByNumber(ctx context.Context, courseNumber string) (C
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listCourses = `-- name: ListCourses :many
-SELECT course_id, course_number, name, description, created_at, updated_at
+SELECT course_id, course_number, name, description, created_at, updated_at, deleted_at
 FROM courses
+WHERE deleted_at IS NULL
 ORDER BY course_number
 `
 
@@ -145,6 +150,7 @@ func (q *Queries) ListCourses(ctx context.Context) ([]Course, error) {
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -159,8 +165,8 @@ func (q *Queries) ListCourses(ctx context.Context) ([]Course, error) {
 const updateCourse = `-- name: UpdateCourse :one
 UPDATE courses
 SET course_number = $2, name = $3, description = $4, updated_at = CURRENT_TIMESTAMP
-WHERE course_id = $1
-RETURNING course_id, course_number, name, description, created_at, updated_at
+WHERE course_id = $1 AND deleted_at IS NULL
+RETURNING course_id, course_number, name, description, created_at, updated_at, deleted_at
 `
 
 type UpdateCourseParams struct {
@@ -185,6 +191,7 @@ func (q *Queries) UpdateCourse(ctx context.Context, arg UpdateCourseParams) (Cou
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -100,10 +100,10 @@ func (q *Queries) GetGrammarPointByName(ctx context.Context, arg GetGrammarPoint
 }
 
 const getStoriesWithGrammarPoint = `-- name: GetStoriesWithGrammarPoint :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
 JOIN grammar_points gp ON s.story_id = gp.story_id
-WHERE gp.grammar_point_id = $1
+WHERE gp.grammar_point_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -125,6 +125,7 @@ func (q *Queries) GetStoriesWithGrammarPoint(ctx context.Context, grammarPointID
 			&i.AuthorID,
 			&i.AuthorName,
 			&i.CourseID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -19,6 +19,20 @@ type AnonymousTimeTracking struct {
 	CreatedAt        pgtype.Timestamp `json:"created_at"`
 }
 
+type AuditEvent struct {
+	EventID     int64            `json:"event_id"`
+	RequestID   string           `json:"request_id"`
+	ActorID     string           `json:"actor_id"`
+	Action      string           `json:"action"`
+	TargetType  string           `json:"target_type"`
+	TargetID    string           `json:"target_id"`
+	Status      int32            `json:"status"`
+	RequestBody []byte           `json:"request_body"`
+	BeforeState []byte           `json:"before_state"`
+	AfterState  []byte           `json:"after_state"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
 type Course struct {
 	CourseID     int32            `json:"course_id"`
 	CourseNumber string           `json:"course_number"`
@@ -26,6 +40,7 @@ type Course struct {
 	Description  pgtype.Text      `json:"description"`
 	CreatedAt    pgtype.Timestamp `json:"created_at"`
 	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	DeletedAt    pgtype.Timestamp `json:"deleted_at"`
 }
 
 type CourseAdmin struct {
@@ -34,6 +49,23 @@ type CourseAdmin struct {
 	AssignedAt pgtype.Timestamp `json:"assigned_at"`
 }
 
+type CoursePhaseFlow struct {
+	CourseID  int32            `json:"course_id"`
+	Phases    []string         `json:"phases"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type CourseRiskSetting struct {
+	CourseID          int32            `json:"course_id"`
+	StartsOn          pgtype.Date      `json:"starts_on"`
+	GraceDays         int32            `json:"grace_days"`
+	MaxIncorrectRatio float64          `json:"max_incorrect_ratio"`
+	MinAnswers        int32            `json:"min_answers"`
+	MinPhaseSeconds   int32            `json:"min_phase_seconds"`
+	DigestEnabled     bool             `json:"digest_enabled"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
 type CourseUser struct {
 	CourseID   int32            `json:"course_id"`
 	UserID     string           `json:"user_id"`
@@ -91,6 +123,41 @@ type GrammarPoint struct {
 	CreatedAt      pgtype.Timestamp `json:"created_at"`
 }
 
+type IdentifyCorrectAnswer struct {
+	ScoreID       int32            `json:"score_id"`
+	UserID        string           `json:"user_id"`
+	StoryID       int32            `json:"story_id"`
+	LineNumber    int32            `json:"line_number"`
+	TargetVocabID int32            `json:"target_vocab_id"`
+	AttemptedAt   pgtype.Timestamp `json:"attempted_at"`
+}
+
+type IdentifyIncorrectAnswer struct {
+	ID                    int32            `json:"id"`
+	UserID                string           `json:"user_id"`
+	StoryID               int32            `json:"story_id"`
+	LineNumber            int32            `json:"line_number"`
+	TargetVocabID         int32            `json:"target_vocab_id"`
+	SelectedTargetVocabID int32            `json:"selected_target_vocab_id"`
+	AttemptedAt           pgtype.Timestamp `json:"attempted_at"`
+}
+
+type IdentifyOption struct {
+	OptionID      string           `json:"option_id"`
+	UserID        string           `json:"user_id"`
+	StoryID       int32            `json:"story_id"`
+	TargetVocabID int32            `json:"target_vocab_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type LexiconEntry struct {
+	LanguageCode string           `json:"language_code"`
+	SurfaceForm  string           `json:"surface_form"`
+	LexicalForm  string           `json:"lexical_form"`
+	Occurrences  int32            `json:"occurrences"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
 type LineAudioFile struct {
 	AudioFileID int32            `json:"audio_file_id"`
 	StoryID     pgtype.Int4      `json:"story_id"`
@@ -108,6 +175,100 @@ type LineTranslation struct {
 	TranslationText string `json:"translation_text"`
 }
 
+type LtiContext struct {
+	ID           int32            `json:"id"`
+	PlatformID   int32            `json:"platform_id"`
+	ContextID    string           `json:"context_id"`
+	CourseID     int32            `json:"course_id"`
+	Title        string           `json:"title"`
+	LineitemsUrl string           `json:"lineitems_url"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type LtiLineItem struct {
+	LtiContextID int32  `json:"lti_context_id"`
+	StoryID      int32  `json:"story_id"`
+	LineItemUrl  string `json:"line_item_url"`
+}
+
+type LtiPlatform struct {
+	ID           int32            `json:"id"`
+	Name         string           `json:"name"`
+	Issuer       string           `json:"issuer"`
+	ClientID     string           `json:"client_id"`
+	DeploymentID string           `json:"deployment_id"`
+	AuthLoginUrl string           `json:"auth_login_url"`
+	AuthTokenUrl string           `json:"auth_token_url"`
+	JwksUrl      string           `json:"jwks_url"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type LtiUser struct {
+	PlatformID int32            `json:"platform_id"`
+	Subject    string           `json:"subject"`
+	UserID     string           `json:"user_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type ProduceExplanation struct {
+	StoryID     int32            `json:"story_id"`
+	Explanation string           `json:"explanation"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type ProduceSegment struct {
+	ID             int32            `json:"id"`
+	StoryID        int32            `json:"story_id"`
+	StartLine      int32            `json:"start_line"`
+	EndLine        int32            `json:"end_line"`
+	EnglishPrompt  string           `json:"english_prompt"`
+	ReferenceText  string           `json:"reference_text"`
+	GrammarPointID pgtype.Int4      `json:"grammar_point_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type ProduceSubmission struct {
+	ID              int32            `json:"id"`
+	UserID          string           `json:"user_id"`
+	StoryID         int32            `json:"story_id"`
+	SegmentID       int32            `json:"segment_id"`
+	StudentText     string           `json:"student_text"`
+	SubmittedAt     pgtype.Timestamp `json:"submitted_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+	AiScore         pgtype.Int4      `json:"ai_score"`
+	AiFeedback      pgtype.Text      `json:"ai_feedback"`
+	GradingStatus   string           `json:"grading_status"`
+	GradingAttempts int32            `json:"grading_attempts"`
+	GradedAt        pgtype.Timestamp `json:"graded_at"`
+}
+
+type RecallCorrectAnswer struct {
+	ScoreID          int32            `json:"score_id"`
+	UserID           string           `json:"user_id"`
+	StoryID          int32            `json:"story_id"`
+	RecallSentenceID int32            `json:"recall_sentence_id"`
+	AttemptedAt      pgtype.Timestamp `json:"attempted_at"`
+}
+
+type RecallIncorrectAnswer struct {
+	ID                int32            `json:"id"`
+	UserID            string           `json:"user_id"`
+	StoryID           int32            `json:"story_id"`
+	RecallSentenceID  int32            `json:"recall_sentence_id"`
+	SubmittedPosition int32            `json:"submitted_position"`
+	AttemptedAt       pgtype.Timestamp `json:"attempted_at"`
+}
+
+type RecallSentence struct {
+	ID            int32            `json:"id"`
+	StoryID       int32            `json:"story_id"`
+	SequenceOrder int32            `json:"sequence_order"`
+	SentenceText  string           `json:"sentence_text"`
+	TargetVocabID pgtype.Int4      `json:"target_vocab_id"`
+	ImageID       pgtype.Int4      `json:"image_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
 type Story struct {
 	StoryID      int32            `json:"story_id"`
 	WeekNumber   int32            `json:"week_number"`
@@ -117,6 +278,7 @@ type Story struct {
 	AuthorID     string           `json:"author_id"`
 	AuthorName   string           `json:"author_name"`
 	CourseID     pgtype.Int4      `json:"course_id"`
+	DeletedAt    pgtype.Timestamp `json:"deleted_at"`
 }
 
 type StoryDescription struct {
@@ -125,18 +287,93 @@ type StoryDescription struct {
 	DescriptionText string `json:"description_text"`
 }
 
+type StoryImage struct {
+	ImageID     int32            `json:"image_id"`
+	StoryID     int32            `json:"story_id"`
+	FilePath    string           `json:"file_path"`
+	FileBucket  string           `json:"file_bucket"`
+	Label       string           `json:"label"`
+	ContentType string           `json:"content_type"`
+	SizeBytes   int64            `json:"size_bytes"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
 type StoryLine struct {
 	StoryID    int32  `json:"story_id"`
 	LineNumber int32  `json:"line_number"`
 	Text       string `json:"text"`
 }
 
+type StoryPhaseFlow struct {
+	StoryID   int32            `json:"story_id"`
+	Phases    []string         `json:"phases"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type StoryRevision struct {
+	RevisionID     int32            `json:"revision_id"`
+	StoryID        int32            `json:"story_id"`
+	RevisionNumber int32            `json:"revision_number"`
+	Action         string           `json:"action"`
+	Snapshot       []byte           `json:"snapshot"`
+	CreatedBy      pgtype.Text      `json:"created_by"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type StorySchedule struct {
+	StoryID       int32            `json:"story_id"`
+	AvailableFrom pgtype.Timestamp `json:"available_from"`
+	DueAt         pgtype.Timestamp `json:"due_at"`
+	LateUntil     pgtype.Timestamp `json:"late_until"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type StoryScore struct {
+	ID                     int32            `json:"id"`
+	UserID                 string           `json:"user_id"`
+	StoryID                int32            `json:"story_id"`
+	FormulaVersion         int32            `json:"formula_version"`
+	Reason                 string           `json:"reason"`
+	VocabCorrect           int32            `json:"vocab_correct"`
+	VocabIncorrect         int32            `json:"vocab_incorrect"`
+	VocabTotal             int32            `json:"vocab_total"`
+	VocabAccuracy          float64          `json:"vocab_accuracy"`
+	GrammarCorrect         int32            `json:"grammar_correct"`
+	GrammarIncorrect       int32            `json:"grammar_incorrect"`
+	GrammarTotal           int32            `json:"grammar_total"`
+	GrammarAccuracy        float64          `json:"grammar_accuracy"`
+	RecallCorrect          int32            `json:"recall_correct"`
+	RecallIncorrect        int32            `json:"recall_incorrect"`
+	RecallTotal            int32            `json:"recall_total"`
+	RecallAccuracy         float64          `json:"recall_accuracy"`
+	TranslationCompleted   bool             `json:"translation_completed"`
+	VocabTimeSeconds       int32            `json:"vocab_time_seconds"`
+	GrammarTimeSeconds     int32            `json:"grammar_time_seconds"`
+	TranslationTimeSeconds int32            `json:"translation_time_seconds"`
+	VideoTimeSeconds       int32            `json:"video_time_seconds"`
+	TotalTimeSeconds       int32            `json:"total_time_seconds"`
+	OverallAccuracy        float64          `json:"overall_accuracy"`
+	ComputedAt             pgtype.Timestamp `json:"computed_at"`
+	Late                   bool             `json:"late"`
+}
+
 type StoryTitle struct {
 	StoryID      int32  `json:"story_id"`
 	LanguageCode string `json:"language_code"`
 	Title        string `json:"title"`
 }
 
+type TargetVocabulary struct {
+	ID          int32            `json:"id"`
+	StoryID     int32            `json:"story_id"`
+	LexicalForm string           `json:"lexical_form"`
+	AudioPath   pgtype.Text      `json:"audio_path"`
+	AudioBucket pgtype.Text      `json:"audio_bucket"`
+	ImagePath   pgtype.Text      `json:"image_path"`
+	ImageBucket pgtype.Text      `json:"image_bucket"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
 type TranslationRequest struct {
 	RequestID      int32            `json:"request_id"`
 	UserID         string           `json:"user_id"`
@@ -165,6 +402,14 @@ type UserTimeTracking struct {
 	CreatedAt        pgtype.Timestamp `json:"created_at"`
 }
 
+type VocabContrastPair struct {
+	ID        int32            `json:"id"`
+	StoryID   int32            `json:"story_id"`
+	FormA     string           `json:"form_a"`
+	FormB     string           `json:"form_b"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type VocabCorrectAnswer struct {
 	ScoreID     int32            `json:"score_id"`
 	UserID      string           `json:"user_id"`
@@ -184,6 +429,16 @@ type VocabIncorrectAnswer struct {
 	AttemptedAt     pgtype.Timestamp `json:"attempted_at"`
 }
 
+type VocabSuggestionRejection struct {
+	StoryID       int32            `json:"story_id"`
+	LineNumber    int32            `json:"line_number"`
+	PositionStart int32            `json:"position_start"`
+	PositionEnd   int32            `json:"position_end"`
+	Word          string           `json:"word"`
+	LexicalForm   string           `json:"lexical_form"`
+	RejectedAt    pgtype.Timestamp `json:"rejected_at"`
+}
+
 type VocabularyItem struct {
 	ID            int32       `json:"id"`
 	StoryID       pgtype.Int4 `json:"story_id"`
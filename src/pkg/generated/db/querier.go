@@ -14,6 +14,7 @@ type Querier interface {
 	AccumulateTimeEntry(ctx context.Context, arg AccumulateTimeEntryParams) error
 	// Course admin management queries
 	AddCourseAdmin(ctx context.Context, arg AddCourseAdminParams) (CourseAdmin, error)
+	AddLTIUserContext(ctx context.Context, arg AddLTIUserContextParams) error
 	AddMultiUsersToCourse(ctx context.Context, arg AddMultiUsersToCourseParams) error
 	AddUserToCourse(ctx context.Context, arg AddUserToCourseParams) error
 	BulkCreateAudioFiles(ctx context.Context, arg []BulkCreateAudioFilesParams) (int64, error)
@@ -31,12 +32,23 @@ type Querier interface {
 	ClearStoryGrammarPoints(ctx context.Context, storyID int32) error
 	CloseAnonymousTimeEntry(ctx context.Context, arg CloseAnonymousTimeEntryParams) error
 	CloseTimeEntry(ctx context.Context, arg CloseTimeEntryParams) error
+	// Course analytics queries
+	CountCourseStudents(ctx context.Context, arg CountCourseStudentsParams) (int64, error)
+	CountLexicalFormOccurrences(ctx context.Context, arg CountLexicalFormOccurrencesParams) (int64, error)
+	// Shared storage object queries
+	CountStorageObjectUses(ctx context.Context, arg CountStorageObjectUsesParams) (int64, error)
 	CountStoryGrammarItems(ctx context.Context, storyID pgtype.Int4) (int64, error)
+	CountStoryProduceSegments(ctx context.Context, storyID int32) (int64, error)
+	CountStoryRecallSentences(ctx context.Context, storyID int32) (int64, error)
+	CountStoryTargetVocab(ctx context.Context, storyID int32) (int64, error)
 	CountStoryVocabItems(ctx context.Context, storyID pgtype.Int4) (int64, error)
+	CountVocabItemAnswers(ctx context.Context, vocabItemIds []int32) (int64, error)
 	// Anonymous time tracking queries
 	CreateAnonymousTimeEntry(ctx context.Context, arg CreateAnonymousTimeEntryParams) (AnonymousTimeTracking, error)
 	// Audio files management queries
 	CreateAudioFile(ctx context.Context, arg CreateAudioFileParams) (LineAudioFile, error)
+	// Audit log queries
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
 	CreateCompleteTimeEntry(ctx context.Context, arg CreateCompleteTimeEntryParams) (UserTimeTracking, error)
 	// Course management queries
 	CreateCourse(ctx context.Context, arg CreateCourseParams) (Course, error)
@@ -45,13 +57,31 @@ type Querier interface {
 	CreateGrammarItem(ctx context.Context, arg CreateGrammarItemParams) (int32, error)
 	// Grammar points management queries
 	CreateGrammarPoint(ctx context.Context, arg CreateGrammarPointParams) (GrammarPoint, error)
+	CreateIdentifyOption(ctx context.Context, arg CreateIdentifyOptionParams) error
+	CreateLTIContext(ctx context.Context, arg CreateLTIContextParams) (LtiContext, error)
+	CreateLTIUser(ctx context.Context, arg CreateLTIUserParams) (LtiUser, error)
+	// Produce phase queries
+	CreateProduceSegment(ctx context.Context, arg CreateProduceSegmentParams) (ProduceSegment, error)
+	// Recall phase queries
+	CreateRecallSentence(ctx context.Context, arg CreateRecallSentenceParams) (RecallSentence, error)
 	CreateStory(ctx context.Context, arg CreateStoryParams) (CreateStoryRow, error)
+	// Story image management queries
+	CreateStoryImage(ctx context.Context, arg CreateStoryImageParams) (StoryImage, error)
+	// Story revision queries
+	CreateStoryRevision(ctx context.Context, arg CreateStoryRevisionParams) error
+	// Story score snapshot queries
+	CreateStoryScore(ctx context.Context, arg CreateStoryScoreParams) (StoryScore, error)
+	// Creates a story under an ID taken from ReserveStoryID
+	CreateStoryWithID(ctx context.Context, arg CreateStoryWithIDParams) error
+	// Target vocabulary queries
+	CreateTargetVocab(ctx context.Context, arg CreateTargetVocabParams) (TargetVocabulary, error)
 	// Time tracking queries
 	CreateTimeEntry(ctx context.Context, arg CreateTimeEntryParams) (UserTimeTracking, error)
 	// Translation requests management queries
 	CreateTranslationRequest(ctx context.Context, arg CreateTranslationRequestParams) (TranslationRequest, error)
 	// User management queries
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVocabContrast(ctx context.Context, arg CreateVocabContrastParams) (VocabContrastPair, error)
 	CreateVocabularyItem(ctx context.Context, arg CreateVocabularyItemParams) (int32, error)
 	DeleteAllGrammarForStory(ctx context.Context, storyID pgtype.Int4) error
 	DeleteAllLineAnnotations(ctx context.Context, arg DeleteAllLineAnnotationsParams) error
@@ -62,12 +92,15 @@ type Querier interface {
 	DeleteAllVocabularyForStory(ctx context.Context, storyID pgtype.Int4) error
 	DeleteAudioFile(ctx context.Context, audioFileID int32) error
 	DeleteCourse(ctx context.Context, courseID int32) error
+	DeleteCoursePhaseFlow(ctx context.Context, courseID int32) error
 	DeleteFootnote(ctx context.Context, id int32) error
 	DeleteFootnoteReferences(ctx context.Context, footnoteID int32) error
 	DeleteFootnoteReferencesByStory(ctx context.Context, storyID pgtype.Int4) error
 	DeleteGrammarItem(ctx context.Context, id int32) error
 	DeleteGrammarItems(ctx context.Context, arg DeleteGrammarItemsParams) error
 	DeleteGrammarPoint(ctx context.Context, grammarPointID int32) error
+	DeleteIdentifyOptions(ctx context.Context, arg DeleteIdentifyOptionsParams) error
+	DeleteLTIPlatform(ctx context.Context, id int32) error
 	DeleteLineAudioFiles(ctx context.Context, arg DeleteLineAudioFilesParams) error
 	DeleteLineFootnoteReferences(ctx context.Context, arg DeleteLineFootnoteReferencesParams) error
 	DeleteLineGrammar(ctx context.Context, arg DeleteLineGrammarParams) error
@@ -75,14 +108,25 @@ type Querier interface {
 	DeleteLineTranslations(ctx context.Context, arg DeleteLineTranslationsParams) error
 	DeleteLineVocabulary(ctx context.Context, arg DeleteLineVocabularyParams) error
 	DeleteOldAnonymousEntries(ctx context.Context, createdAt pgtype.Timestamp) error
+	DeleteProduceExplanation(ctx context.Context, storyID int32) error
+	DeleteProduceSegment(ctx context.Context, arg DeleteProduceSegmentParams) error
+	DeleteRecallSentence(ctx context.Context, arg DeleteRecallSentenceParams) error
 	DeleteStory(ctx context.Context, storyID int32) error
 	DeleteStoryAudioFiles(ctx context.Context, storyID pgtype.Int4) error
 	DeleteStoryAudioFilesByLabel(ctx context.Context, arg DeleteStoryAudioFilesByLabelParams) error
 	DeleteStoryDescriptions(ctx context.Context, storyID int32) error
+	DeleteStoryImage(ctx context.Context, imageID int32) error
+	DeleteStoryImages(ctx context.Context, storyID int32) error
 	DeleteStoryLine(ctx context.Context, arg DeleteStoryLineParams) error
+	DeleteStoryPhaseFlow(ctx context.Context, storyID int32) error
+	DeleteStoryProduceSegments(ctx context.Context, storyID int32) error
+	DeleteStoryRecallSentences(ctx context.Context, storyID int32) error
+	DeleteStoryTargetVocab(ctx context.Context, storyID int32) error
 	DeleteStoryTitles(ctx context.Context, storyID int32) error
+	DeleteTargetVocab(ctx context.Context, id int32) error
 	DeleteTranslationRequest(ctx context.Context, arg DeleteTranslationRequestParams) error
 	DeleteUser(ctx context.Context, userID string) error
+	DeleteVocabContrast(ctx context.Context, arg DeleteVocabContrastParams) (int64, error)
 	DeleteVocabularyItem(ctx context.Context, id int32) error
 	DeleteVocabularyItems(ctx context.Context, arg DeleteVocabularyItemsParams) error
 	FindRecentSimilarTimeEntry(ctx context.Context, arg FindRecentSimilarTimeEntryParams) (FindRecentSimilarTimeEntryRow, error)
@@ -108,21 +152,54 @@ type Querier interface {
 	GetCourseAdmins(ctx context.Context, courseID int32) ([]GetCourseAdminsRow, error)
 	GetCourseByNumber(ctx context.Context, courseNumber string) (Course, error)
 	GetCourseIdForStory(ctx context.Context, storyID int32) (pgtype.Int4, error)
+	GetCourseLexicalForms(ctx context.Context, courseID pgtype.Int4) ([]string, error)
+	GetCourseMissedGrammar(ctx context.Context, arg GetCourseMissedGrammarParams) ([]GetCourseMissedGrammarRow, error)
+	GetCourseMissedVocab(ctx context.Context, arg GetCourseMissedVocabParams) ([]GetCourseMissedVocabRow, error)
+	GetCoursePhaseActivity(ctx context.Context, arg GetCoursePhaseActivityParams) ([]GetCoursePhaseActivityRow, error)
+	// Phase flow queries
+	GetCoursePhaseFlow(ctx context.Context, courseID int32) (CoursePhaseFlow, error)
+	// At-risk detection queries
+	GetCourseRiskSettings(ctx context.Context, courseID int32) (CourseRiskSetting, error)
+	GetCourseRouteTimes(ctx context.Context, arg GetCourseRouteTimesParams) ([]GetCourseRouteTimesRow, error)
 	GetCourseStoriesWithTitles(ctx context.Context, arg GetCourseStoriesWithTitlesParams) ([]GetCourseStoriesWithTitlesRow, error)
+	GetCourseStoryIDs(ctx context.Context, courseID pgtype.Int4) ([]int32, error)
+	GetCourseVocabConfusions(ctx context.Context, arg GetCourseVocabConfusionsParams) ([]GetCourseVocabConfusionsRow, error)
+	GetCourseVocabContrasts(ctx context.Context, courseID pgtype.Int4) ([]VocabContrastPair, error)
+	GetCourseVocabWrongAnswers(ctx context.Context, arg GetCourseVocabWrongAnswersParams) ([]GetCourseVocabWrongAnswersRow, error)
 	GetCoursesForUser(ctx context.Context, userID string) ([]GetCoursesForUserRow, error)
 	GetCoursesForUserByStatus(ctx context.Context, arg GetCoursesForUserByStatusParams) ([]GetCoursesForUserByStatusRow, error)
+	GetExpiredTrashedCourseIDs(ctx context.Context, cutoff pgtype.Timestamp) ([]int32, error)
+	GetExpiredTrashedStoryIDs(ctx context.Context, cutoff pgtype.Timestamp) ([]int32, error)
 	GetFootnoteReferences(ctx context.Context, footnoteID int32) ([]string, error)
 	GetFootnotes(ctx context.Context, arg GetFootnotesParams) ([]Footnote, error)
 	GetGrammarItems(ctx context.Context, arg GetGrammarItemsParams) ([]GrammarItem, error)
 	GetGrammarPoint(ctx context.Context, grammarPointID int32) (GrammarPoint, error)
 	GetGrammarPointByName(ctx context.Context, arg GetGrammarPointByNameParams) (GrammarPoint, error)
+	// Grammar click heatmap queries
+	GetGrammarPointCorrectClicks(ctx context.Context, arg GetGrammarPointCorrectClicksParams) ([]GetGrammarPointCorrectClicksRow, error)
+	GetGrammarPointIncorrectClicks(ctx context.Context, arg GetGrammarPointIncorrectClicksParams) ([]GetGrammarPointIncorrectClicksRow, error)
+	GetIdentifyOptionTarget(ctx context.Context, arg GetIdentifyOptionTargetParams) (int32, error)
 	GetIncompleteVocabForUser(ctx context.Context, arg GetIncompleteVocabForUserParams) ([]GetIncompleteVocabForUserRow, error)
+	GetLTIContext(ctx context.Context, arg GetLTIContextParams) (LtiContext, error)
+	GetLTILineItem(ctx context.Context, arg GetLTILineItemParams) (LtiLineItem, error)
+	GetLTIPlatform(ctx context.Context, id int32) (LtiPlatform, error)
+	GetLTIPlatformsByIssuer(ctx context.Context, issuer string) ([]LtiPlatform, error)
+	GetLTIScoreTargets(ctx context.Context, arg GetLTIScoreTargetsParams) ([]GetLTIScoreTargetsRow, error)
+	GetLTIUser(ctx context.Context, arg GetLTIUserParams) (LtiUser, error)
+	GetLatestStoryScore(ctx context.Context, arg GetLatestStoryScoreParams) (StoryScore, error)
+	GetLexiconEntries(ctx context.Context, arg GetLexiconEntriesParams) ([]LexiconEntry, error)
 	GetLineAudioFiles(ctx context.Context, arg GetLineAudioFilesParams) ([]LineAudioFile, error)
 	GetLineText(ctx context.Context, arg GetLineTextParams) (string, error)
 	GetLineTranslation(ctx context.Context, arg GetLineTranslationParams) (string, error)
 	// Line translations management queries
 	GetLineTranslations(ctx context.Context, arg GetLineTranslationsParams) ([]LineTranslation, error)
+	GetPendingProduceSubmissions(ctx context.Context, arg GetPendingProduceSubmissionsParams) ([]GetPendingProduceSubmissionsRow, error)
+	GetProduceExplanation(ctx context.Context, storyID int32) (ProduceExplanation, error)
+	GetProduceSegment(ctx context.Context, id int32) (ProduceSegment, error)
+	GetRecallSentence(ctx context.Context, id int32) (RecallSentence, error)
 	GetRecentTimeEntriesForUser(ctx context.Context, arg GetRecentTimeEntriesForUserParams) ([]UserTimeTracking, error)
+	GetRiskDigestCourseIDs(ctx context.Context) ([]int32, error)
+	GetScoredUserStories(ctx context.Context) ([]GetScoredUserStoriesRow, error)
 	GetStoriesByCourse(ctx context.Context, courseID pgtype.Int4) ([]Story, error)
 	GetStoriesForUserCourses(ctx context.Context, userID string) ([]Story, error)
 	GetStoriesWithGrammarPoint(ctx context.Context, grammarPointID int32) ([]Story, error)
@@ -131,34 +208,60 @@ type Querier interface {
 	GetStoryAudioFilesByLabel(ctx context.Context, arg GetStoryAudioFilesByLabelParams) ([]LineAudioFile, error)
 	// Story descriptions
 	GetStoryDescription(ctx context.Context, arg GetStoryDescriptionParams) (string, error)
+	GetStoryDescriptions(ctx context.Context, storyID int32) ([]StoryDescription, error)
 	GetStoryFootnotesWithReferences(ctx context.Context, storyID pgtype.Int4) ([]GetStoryFootnotesWithReferencesRow, error)
+	GetStoryGrammarItems(ctx context.Context, storyID pgtype.Int4) ([]GrammarItem, error)
 	GetStoryGrammarPoints(ctx context.Context, storyID int32) ([]GetStoryGrammarPointsRow, error)
 	GetStoryGrammarScores(ctx context.Context, storyID int32) ([]GetStoryGrammarScoresRow, error)
+	GetStoryImage(ctx context.Context, imageID int32) (StoryImage, error)
+	GetStoryImages(ctx context.Context, storyID int32) ([]StoryImage, error)
+	// Lexicon queries
+	GetStoryLanguages(ctx context.Context, storyID int32) ([]string, error)
+	GetStoryLatestScores(ctx context.Context, storyID int32) ([]StoryScore, error)
+	GetStoryLexicalForms(ctx context.Context, storyID pgtype.Int4) ([]string, error)
 	GetStoryLine(ctx context.Context, arg GetStoryLineParams) (StoryLine, error)
 	// Story lines
 	GetStoryLines(ctx context.Context, storyID int32) ([]StoryLine, error)
+	GetStoryPhaseFlow(ctx context.Context, storyID int32) (StoryPhaseFlow, error)
+	GetStoryProduceSegments(ctx context.Context, storyID int32) ([]ProduceSegment, error)
+	GetStoryRecallSentences(ctx context.Context, storyID int32) ([]RecallSentence, error)
+	GetStoryRevision(ctx context.Context, arg GetStoryRevisionParams) (StoryRevision, error)
+	// Story release schedule queries
+	GetStorySchedule(ctx context.Context, storyID int32) (StorySchedule, error)
+	GetStorySchedules(ctx context.Context, storyIds []int32) ([]StorySchedule, error)
+	GetStoryScoreHistory(ctx context.Context, arg GetStoryScoreHistoryParams) ([]StoryScore, error)
 	GetStoryStudentPerformance(ctx context.Context, arg GetStoryStudentPerformanceParams) ([]GetStoryStudentPerformanceRow, error)
+	GetStoryTargetVocab(ctx context.Context, storyID int32) ([]GetStoryTargetVocabRow, error)
 	GetStoryTitle(ctx context.Context, arg GetStoryTitleParams) (string, error)
 	// Story titles
 	GetStoryTitles(ctx context.Context, storyID int32) ([]StoryTitle, error)
 	GetStoryTranslationRequests(ctx context.Context, storyID int32) ([]TranslationRequest, error)
+	// Vocabulary confusion queries
+	GetStoryVocabConfusions(ctx context.Context, storyID int32) ([]GetStoryVocabConfusionsRow, error)
+	GetStoryVocabContrasts(ctx context.Context, storyID int32) ([]VocabContrastPair, error)
 	GetStoryVocabScores(ctx context.Context, storyID int32) ([]GetStoryVocabScoresRow, error)
+	GetStoryVocabularyItems(ctx context.Context, storyID pgtype.Int4) ([]VocabularyItem, error)
 	GetStoryWithDescription(ctx context.Context, storyID int32) (GetStoryWithDescriptionRow, error)
+	GetTargetVocab(ctx context.Context, id int32) (TargetVocabulary, error)
 	GetTimeEntriesForStory(ctx context.Context, storyID pgtype.Int4) ([]UserTimeTracking, error)
 	GetTimeEntriesForUser(ctx context.Context, userID string) ([]UserTimeTracking, error)
 	GetTimeEntryByID(ctx context.Context, trackingID int32) (UserTimeTracking, error)
 	GetTranslationRequest(ctx context.Context, arg GetTranslationRequestParams) (TranslationRequest, error)
 	GetTranslationRequestByID(ctx context.Context, requestID int32) (TranslationRequest, error)
 	GetTranslationsByLanguage(ctx context.Context, arg GetTranslationsByLanguageParams) ([]GetTranslationsByLanguageRow, error)
+	GetTrashedStory(ctx context.Context, storyID int32) (GetTrashedStoryRow, error)
 	GetUser(ctx context.Context, userID string) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserCourseAdminRights(ctx context.Context, userID string) ([]GetUserCourseAdminRightsRow, error)
 	GetUserGrammarIncorrectAnswers(ctx context.Context, arg GetUserGrammarIncorrectAnswersParams) ([]GetUserGrammarIncorrectAnswersRow, error)
 	GetUserGrammarScores(ctx context.Context, arg GetUserGrammarScoresParams) ([]GetUserGrammarScoresRow, error)
 	GetUserGrammarScoresByGrammarPoint(ctx context.Context, arg GetUserGrammarScoresByGrammarPointParams) ([]GetUserGrammarScoresByGrammarPointRow, error)
+	GetUserIdentifyScores(ctx context.Context, arg GetUserIdentifyScoresParams) ([]GetUserIdentifyScoresRow, error)
 	GetUserLatestGrammarScoresByLine(ctx context.Context, arg GetUserLatestGrammarScoresByLineParams) ([]GetUserLatestGrammarScoresByLineRow, error)
 	GetUserLatestVocabScoresByLine(ctx context.Context, arg GetUserLatestVocabScoresByLineParams) ([]GetUserLatestVocabScoresByLineRow, error)
+	GetUserProduceSubmissions(ctx context.Context, arg GetUserProduceSubmissionsParams) ([]ProduceSubmission, error)
 	GetUserStoryGrammarSummary(ctx context.Context, arg GetUserStoryGrammarSummaryParams) (GetUserStoryGrammarSummaryRow, error)
+	GetUserStoryRecallSummary(ctx context.Context, arg GetUserStoryRecallSummaryParams) (GetUserStoryRecallSummaryRow, error)
 	GetUserStoryTimeTracking(ctx context.Context, arg GetUserStoryTimeTrackingParams) (GetUserStoryTimeTrackingRow, error)
 	GetUserStoryVocabSummary(ctx context.Context, arg GetUserStoryVocabSummaryParams) (GetUserStoryVocabSummaryRow, error)
 	GetUserTranslationRequests(ctx context.Context, userID string) ([]TranslationRequest, error)
@@ -166,32 +269,79 @@ type Querier interface {
 	GetUserVocabScores(ctx context.Context, arg GetUserVocabScoresParams) ([]GetUserVocabScoresRow, error)
 	GetUsersByEmails(ctx context.Context, dollar_1 []string) ([]User, error)
 	GetUsersForCourse(ctx context.Context, courseID int32) ([]GetUsersForCourseRow, error)
+	GetVocabSuggestionRejections(ctx context.Context, storyID int32) ([]VocabSuggestionRejection, error)
 	GetVocabularyItems(ctx context.Context, arg GetVocabularyItemsParams) ([]VocabularyItem, error)
+	// Whether a course outside the trash now has the number of a trashed course
+	IsTrashedCourseNumberTaken(ctx context.Context, courseID int32) (bool, error)
 	IsUserAdminOfAnyCourse(ctx context.Context, userID string) (bool, error)
 	IsUserCourseAdmin(ctx context.Context, arg IsUserCourseAdminParams) (bool, error)
+	LearnLexiconEntry(ctx context.Context, arg LearnLexiconEntryParams) error
 	LineExists(ctx context.Context, arg LineExistsParams) (bool, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
 	ListCourses(ctx context.Context) ([]Course, error)
 	ListGrammarPoints(ctx context.Context) ([]GrammarPoint, error)
+	ListLTIPlatforms(ctx context.Context) ([]LtiPlatform, error)
+	ListStoryRevisions(ctx context.Context, storyID int32) ([]ListStoryRevisionsRow, error)
 	ListSuperAdmins(ctx context.Context) ([]User, error)
+	ListTrashedCourses(ctx context.Context) ([]ListTrashedCoursesRow, error)
+	ListTrashedStories(ctx context.Context) ([]ListTrashedStoriesRow, error)
 	ListUsers(ctx context.Context) ([]User, error)
+	// Serializes revision numbering: CreateStoryRevision runs while the story row is locked
+	LockStoryForRevision(ctx context.Context, storyID int32) error
+	MarkProduceSubmissionPending(ctx context.Context, arg MarkProduceSubmissionPendingParams) error
+	RejectVocabSuggestion(ctx context.Context, arg RejectVocabSuggestionParams) error
+	RelinkLTIUser(ctx context.Context, arg RelinkLTIUserParams) (int64, error)
+	RemapGrammarIncorrectSelectedLines(ctx context.Context, arg RemapGrammarIncorrectSelectedLinesParams) error
+	RemapTranslationRequestLines(ctx context.Context, arg RemapTranslationRequestLinesParams) error
 	RemoveCourseAdmin(ctx context.Context, arg RemoveCourseAdminParams) error
 	RemoveUserFromCourse(ctx context.Context, arg RemoveUserFromCourseParams) error
+	RenumberStoryLine(ctx context.Context, arg RenumberStoryLineParams) error
+	// Takes a story ID before the story exists, e.g. to store its files first
+	ReserveStoryID(ctx context.Context) (int32, error)
+	RestoreCourse(ctx context.Context, courseID int32) (int64, error)
+	RestoreCourseStories(ctx context.Context, courseID int32) error
+	RestoreStory(ctx context.Context, storyID int32) error
 	SaveGrammarIncorrectAnswer(ctx context.Context, arg SaveGrammarIncorrectAnswerParams) error
 	// Score management queries
 	SaveGrammarScore(ctx context.Context, arg SaveGrammarScoreParams) error
+	SaveIdentifyIncorrectAnswer(ctx context.Context, arg SaveIdentifyIncorrectAnswerParams) error
+	// Identify phase answer queries
+	SaveIdentifyScore(ctx context.Context, arg SaveIdentifyScoreParams) error
+	SaveRecallIncorrectAnswer(ctx context.Context, arg SaveRecallIncorrectAnswerParams) error
+	SaveRecallScore(ctx context.Context, arg SaveRecallScoreParams) error
 	SaveVocabIncorrectAnswer(ctx context.Context, arg SaveVocabIncorrectAnswerParams) error
 	SaveVocabScore(ctx context.Context, arg SaveVocabScoreParams) error
+	// Only stores the grade while the submission still holds the graded answer
+	SetProduceSubmissionGrade(ctx context.Context, arg SetProduceSubmissionGradeParams) (int64, error)
+	SetRecallSentenceOrder(ctx context.Context, arg SetRecallSentenceOrderParams) error
+	SetTargetVocabAudio(ctx context.Context, arg SetTargetVocabAudioParams) (TargetVocabulary, error)
+	SetTargetVocabImage(ctx context.Context, arg SetTargetVocabImageParams) (TargetVocabulary, error)
+	// Moves the term start along with ShiftCourseSchedule
+	ShiftCourseRiskStart(ctx context.Context, arg ShiftCourseRiskStartParams) error
+	ShiftCourseSchedule(ctx context.Context, arg ShiftCourseScheduleParams) ([]int32, error)
 	StoryExists(ctx context.Context, storyID int32) (bool, error)
 	TranslationRequestExists(ctx context.Context, arg TranslationRequestExistsParams) (bool, error)
+	TrashCourse(ctx context.Context, arg TrashCourseParams) (int64, error)
+	TrashCourseStories(ctx context.Context, arg TrashCourseStoriesParams) ([]int32, error)
+	// Trash queries
+	TrashStory(ctx context.Context, arg TrashStoryParams) (int64, error)
+	// Takes back one annotation of a mapping, dropping it once none are left
+	UnlearnLexiconEntry(ctx context.Context, arg UnlearnLexiconEntryParams) error
 	UpdateAnonymousTimeEntry(ctx context.Context, arg UpdateAnonymousTimeEntryParams) (AnonymousTimeTracking, error)
 	UpdateAudioFile(ctx context.Context, arg UpdateAudioFileParams) (LineAudioFile, error)
 	UpdateCourse(ctx context.Context, arg UpdateCourseParams) (Course, error)
 	UpdateCourseUserStatus(ctx context.Context, arg UpdateCourseUserStatusParams) error
 	UpdateFootnote(ctx context.Context, arg UpdateFootnoteParams) error
 	UpdateGrammarByPosition(ctx context.Context, arg UpdateGrammarByPositionParams) error
+	UpdateGrammarItemPosition(ctx context.Context, arg UpdateGrammarItemPositionParams) error
 	UpdateGrammarPoint(ctx context.Context, arg UpdateGrammarPointParams) (GrammarPoint, error)
+	UpdateLTIContext(ctx context.Context, arg UpdateLTIContextParams) (LtiContext, error)
+	UpdateProduceSegment(ctx context.Context, arg UpdateProduceSegmentParams) (ProduceSegment, error)
+	UpdateRecallSentence(ctx context.Context, arg UpdateRecallSentenceParams) (RecallSentence, error)
 	UpdateStory(ctx context.Context, arg UpdateStoryParams) error
+	UpdateStoryLineText(ctx context.Context, arg UpdateStoryLineTextParams) error
 	UpdateStoryRevision(ctx context.Context, storyID int32) error
+	UpdateTargetVocabLexicalForm(ctx context.Context, arg UpdateTargetVocabLexicalFormParams) (TargetVocabulary, error)
 	UpdateTimeEntry(ctx context.Context, arg UpdateTimeEntryParams) (UserTimeTracking, error)
 	UpdateTimeEntryIfBigger(ctx context.Context, arg UpdateTimeEntryIfBiggerParams) error
 	UpdateTranslationRequest(ctx context.Context, arg UpdateTranslationRequestParams) error
@@ -199,9 +349,18 @@ type Querier interface {
 	UpdateVocabularyByPosition(ctx context.Context, arg UpdateVocabularyByPositionParams) error
 	UpdateVocabularyByWord(ctx context.Context, arg UpdateVocabularyByWordParams) error
 	UpdateVocabularyItem(ctx context.Context, arg UpdateVocabularyItemParams) error
+	UpsertCoursePhaseFlow(ctx context.Context, arg UpsertCoursePhaseFlowParams) (CoursePhaseFlow, error)
+	UpsertCourseRiskSettings(ctx context.Context, arg UpsertCourseRiskSettingsParams) (CourseRiskSetting, error)
+	UpsertLTILineItem(ctx context.Context, arg UpsertLTILineItemParams) error
+	// LTI 1.3 platform registration and launch mapping queries
+	UpsertLTIPlatform(ctx context.Context, arg UpsertLTIPlatformParams) (LtiPlatform, error)
 	UpsertLineTranslation(ctx context.Context, arg UpsertLineTranslationParams) error
+	UpsertProduceExplanation(ctx context.Context, arg UpsertProduceExplanationParams) (ProduceExplanation, error)
+	UpsertProduceSubmission(ctx context.Context, arg UpsertProduceSubmissionParams) (ProduceSubmission, error)
 	UpsertStoryDescription(ctx context.Context, arg UpsertStoryDescriptionParams) error
 	UpsertStoryLine(ctx context.Context, arg UpsertStoryLineParams) error
+	UpsertStoryPhaseFlow(ctx context.Context, arg UpsertStoryPhaseFlowParams) (StoryPhaseFlow, error)
+	UpsertStorySchedule(ctx context.Context, arg UpsertStoryScheduleParams) (StorySchedule, error)
 	UpsertStoryTitle(ctx context.Context, arg UpsertStoryTitleParams) error
 	UpsertTimeEntry(ctx context.Context, arg UpsertTimeEntryParams) (UserTimeTracking, error)
 	UpsertUser(ctx context.Context, arg UpsertUserParams) (User, error)
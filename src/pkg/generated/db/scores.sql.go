@@ -680,6 +680,24 @@ func (q *Queries) GetUserVocabScores(ctx context.Context, arg GetUserVocabScores
 	return items, nil
 }
 
+const remapGrammarIncorrectSelectedLines = `-- name: RemapGrammarIncorrectSelectedLines :exec
+UPDATE grammar_incorrect_answers gia
+SET selected_line = m.new_line
+FROM unnest($1::int[], $2::int[]) AS m(old_line, new_line)
+WHERE gia.story_id = $3 AND gia.selected_line = m.old_line
+`
+
+type RemapGrammarIncorrectSelectedLinesParams struct {
+	OldLines []int32 `json:"old_lines"`
+	NewLines []int32 `json:"new_lines"`
+	StoryID  int32   `json:"story_id"`
+}
+
+func (q *Queries) RemapGrammarIncorrectSelectedLines(ctx context.Context, arg RemapGrammarIncorrectSelectedLinesParams) error {
+	_, err := q.db.Exec(ctx, remapGrammarIncorrectSelectedLines, arg.OldLines, arg.NewLines, arg.StoryID)
+	return err
+}
+
 const saveGrammarIncorrectAnswer = `-- name: SaveGrammarIncorrectAnswer :exec
 INSERT INTO grammar_incorrect_answers (user_id, story_id, line_number, grammar_point_id, selected_line, selected_positions)
 VALUES ($1, $2, $3, $4, $5, $6)
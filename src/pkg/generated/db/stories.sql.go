@@ -45,6 +45,36 @@ func (q *Queries) CreateStory(ctx context.Context, arg CreateStoryParams) (Creat
 	return i, err
 }
 
+const createStoryWithID = `-- name: CreateStoryWithID :exec
+
+INSERT INTO stories (story_id, week_number, day_letter, video_url, author_id, author_name, course_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateStoryWithIDParams struct {
+	StoryID    int32       `json:"story_id"`
+	WeekNumber int32       `json:"week_number"`
+	DayLetter  string      `json:"day_letter"`
+	VideoUrl   pgtype.Text `json:"video_url"`
+	AuthorID   string      `json:"author_id"`
+	AuthorName string      `json:"author_name"`
+	CourseID   pgtype.Int4 `json:"course_id"`
+}
+
+// Creates a story under an ID taken from ReserveStoryID
+func (q *Queries) CreateStoryWithID(ctx context.Context, arg CreateStoryWithIDParams) error {
+	_, err := q.db.Exec(ctx, createStoryWithID,
+		arg.StoryID,
+		arg.WeekNumber,
+		arg.DayLetter,
+		arg.VideoUrl,
+		arg.AuthorID,
+		arg.AuthorName,
+		arg.CourseID,
+	)
+	return err
+}
+
 const deleteStory = `-- name: DeleteStory :exec
 DELETE FROM stories WHERE story_id = $1
 `
@@ -55,8 +85,9 @@ func (q *Queries) DeleteStory(ctx context.Context, storyID int32) error {
 }
 
 const getAllStories = `-- name: GetAllStories :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
+WHERE s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -78,6 +109,7 @@ func (q *Queries) GetAllStories(ctx context.Context) ([]Story, error) {
 			&i.AuthorID,
 			&i.AuthorName,
 			&i.CourseID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -93,7 +125,7 @@ const getAllStoriesBasic = `-- name: GetAllStoriesBasic :many
 SELECT DISTINCT s.story_id, s.week_number, s.day_letter, st.title, s.course_id
 FROM stories s
 JOIN story_titles st ON s.story_id = st.story_id
-WHERE st.language_code = $1 OR $1 = ''
+WHERE (st.language_code = $1 OR $1 = '') AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -138,6 +170,7 @@ JOIN story_titles st ON s.story_id = st.story_id
 LEFT JOIN course_users cu ON s.course_id = cu.course_id AND cu.user_id = $2
 LEFT JOIN course_admins ca ON s.course_id = ca.course_id AND ca.user_id = $2
 WHERE (st.language_code = $1 OR $1 = '')
+  AND s.deleted_at IS NULL
   AND (s.course_id IS NULL OR cu.user_id IS NOT NULL OR ca.user_id IS NOT NULL)
   AND (cu.status = 'active' OR cu.status IS NULL OR ca.user_id IS NOT NULL)
 ORDER BY s.week_number, s.day_letter
@@ -186,6 +219,7 @@ const getAllStoriesWithTitles = `-- name: GetAllStoriesWithTitles :many
 SELECT DISTINCT s.story_id, s.week_number, s.day_letter, st.title, st.language_code
 FROM stories s
 JOIN story_titles st ON s.story_id = st.story_id
+WHERE s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -224,7 +258,7 @@ func (q *Queries) GetAllStoriesWithTitles(ctx context.Context) ([]GetAllStoriesW
 }
 
 const getCourseIdForStory = `-- name: GetCourseIdForStory :one
-SELECT course_id FROM stories WHERE story_id = $1
+SELECT course_id FROM stories WHERE story_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetCourseIdForStory(ctx context.Context, storyID int32) (pgtype.Int4, error) {
@@ -238,7 +272,7 @@ const getCourseStoriesWithTitles = `-- name: GetCourseStoriesWithTitles :many
 SELECT s.story_id, s.week_number, s.day_letter, st.title
 FROM stories s
 JOIN story_titles st ON s.story_id = st.story_id AND st.language_code = $2
-WHERE s.course_id = $1
+WHERE s.course_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -280,9 +314,9 @@ func (q *Queries) GetCourseStoriesWithTitles(ctx context.Context, arg GetCourseS
 }
 
 const getStoriesByCourse = `-- name: GetStoriesByCourse :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
-WHERE s.course_id = $1
+WHERE s.course_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -304,6 +338,7 @@ func (q *Queries) GetStoriesByCourse(ctx context.Context, courseID pgtype.Int4)
 			&i.AuthorID,
 			&i.AuthorName,
 			&i.CourseID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -316,10 +351,10 @@ func (q *Queries) GetStoriesByCourse(ctx context.Context, courseID pgtype.Int4)
 }
 
 const getStoriesForUserCourses = `-- name: GetStoriesForUserCourses :many
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
 JOIN course_admins ca ON s.course_id = ca.course_id
-WHERE ca.user_id = $1
+WHERE ca.user_id = $1 AND s.deleted_at IS NULL
 ORDER BY s.week_number, s.day_letter
 `
 
@@ -341,6 +376,7 @@ func (q *Queries) GetStoriesForUserCourses(ctx context.Context, userID string) (
 			&i.AuthorID,
 			&i.AuthorName,
 			&i.CourseID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -354,9 +390,9 @@ func (q *Queries) GetStoriesForUserCourses(ctx context.Context, userID string) (
 
 const getStory = `-- name: GetStory :one
 
-SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id
+SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.author_id, s.author_name, s.course_id, s.deleted_at
 FROM stories s
-WHERE s.story_id = $1
+WHERE s.story_id = $1 AND s.deleted_at IS NULL
 `
 
 // Core story operations
@@ -372,6 +408,7 @@ func (q *Queries) GetStory(ctx context.Context, storyID int32) (Story, error) {
 		&i.AuthorID,
 		&i.AuthorName,
 		&i.CourseID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -381,7 +418,7 @@ SELECT s.story_id, s.week_number, s.day_letter, s.video_url, s.last_revision, s.
        sd.language_code, sd.description_text
 FROM stories s
 LEFT JOIN story_descriptions sd ON s.story_id = sd.story_id
-WHERE s.story_id = $1
+WHERE s.story_id = $1 AND s.deleted_at IS NULL
 `
 
 type GetStoryWithDescriptionRow struct {
@@ -415,6 +452,19 @@ func (q *Queries) GetStoryWithDescription(ctx context.Context, storyID int32) (G
 	return i, err
 }
 
+const reserveStoryID = `-- name: ReserveStoryID :one
+
+SELECT nextval(pg_get_serial_sequence('stories', 'story_id'))::integer AS story_id
+`
+
+// Takes a story ID before the story exists, e.g. to store its files first
+func (q *Queries) ReserveStoryID(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, reserveStoryID)
+	var story_id int32
+	err := row.Scan(&story_id)
+	return story_id, err
+}
+
 const updateStory = `-- name: UpdateStory :exec
 UPDATE stories
 SET week_number = $2, day_letter = $3, video_url = $4, author_id = $5, author_name = $6, course_id = $7, last_revision = CURRENT_TIMESTAMP
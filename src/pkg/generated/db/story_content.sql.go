@@ -86,6 +86,37 @@ func (q *Queries) GetStoryDescription(ctx context.Context, arg GetStoryDescripti
 	return description_text, err
 }
 
+const getStoryDescriptions = `-- name: GetStoryDescriptions :many
+SELECT story_id, language_code, description_text
+FROM story_descriptions
+WHERE story_id = $1
+ORDER BY language_code
+`
+
+func (q *Queries) GetStoryDescriptions(ctx context.Context, storyID int32) ([]StoryDescription, error) {
+	rows, err := q.db.Query(ctx, getStoryDescriptions, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []StoryDescription{}
+	for rows.Next() {
+		var i StoryDescription
+		if err := rows.Scan(
+			&i.StoryID,
+			&i.LanguageCode,
+			&i.DescriptionText,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStoryLine = `-- name: GetStoryLine :one
 SELECT story_id, line_number, text
 FROM story_lines
@@ -177,6 +208,38 @@ func (q *Queries) GetStoryTitles(ctx context.Context, storyID int32) ([]StoryTit
 	return items, nil
 }
 
+const renumberStoryLine = `-- name: RenumberStoryLine :exec
+UPDATE story_lines SET line_number = $3
+WHERE story_id = $1 AND line_number = $2
+`
+
+type RenumberStoryLineParams struct {
+	StoryID       int32 `json:"story_id"`
+	LineNumber    int32 `json:"line_number"`
+	NewLineNumber int32 `json:"new_line_number"`
+}
+
+func (q *Queries) RenumberStoryLine(ctx context.Context, arg RenumberStoryLineParams) error {
+	_, err := q.db.Exec(ctx, renumberStoryLine, arg.StoryID, arg.LineNumber, arg.NewLineNumber)
+	return err
+}
+
+const updateStoryLineText = `-- name: UpdateStoryLineText :exec
+UPDATE story_lines SET text = $3
+WHERE story_id = $1 AND line_number = $2
+`
+
+type UpdateStoryLineTextParams struct {
+	StoryID    int32  `json:"story_id"`
+	LineNumber int32  `json:"line_number"`
+	Text       string `json:"text"`
+}
+
+func (q *Queries) UpdateStoryLineText(ctx context.Context, arg UpdateStoryLineTextParams) error {
+	_, err := q.db.Exec(ctx, updateStoryLineText, arg.StoryID, arg.LineNumber, arg.Text)
+	return err
+}
+
 const upsertStoryDescription = `-- name: UpsertStoryDescription :exec
 INSERT INTO story_descriptions (story_id, language_code, description_text)
 VALUES ($1, $2, $3)
@@ -183,6 +183,29 @@ func (q *Queries) GetUserTranslationStatusForStory(ctx context.Context, arg GetU
 	return i, err
 }
 
+const remapTranslationRequestLines = `-- name: RemapTranslationRequestLines :exec
+UPDATE translation_requests tr
+SET requested_lines = ARRAY(
+    SELECT m.new_line
+    FROM unnest(tr.requested_lines) WITH ORDINALITY AS r(line_number, ord)
+    JOIN unnest($1::int[], $2::int[]) AS m(old_line, new_line) ON m.old_line = r.line_number
+    WHERE m.new_line > 0
+    ORDER BY r.ord
+)
+WHERE tr.story_id = $3
+`
+
+type RemapTranslationRequestLinesParams struct {
+	OldLines []int32 `json:"old_lines"`
+	NewLines []int32 `json:"new_lines"`
+	StoryID  int32   `json:"story_id"`
+}
+
+func (q *Queries) RemapTranslationRequestLines(ctx context.Context, arg RemapTranslationRequestLinesParams) error {
+	_, err := q.db.Exec(ctx, remapTranslationRequestLines, arg.OldLines, arg.NewLines, arg.StoryID)
+	return err
+}
+
 const translationRequestExists = `-- name: TranslationRequestExists :one
 SELECT EXISTS(
     SELECT 1 FROM translation_requests
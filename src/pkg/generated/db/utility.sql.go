@@ -228,7 +228,7 @@ func (q *Queries) LineExists(ctx context.Context, arg LineExistsParams) (bool, e
 }
 
 const storyExists = `-- name: StoryExists :one
-SELECT EXISTS(SELECT 1 FROM stories WHERE story_id = $1)
+SELECT EXISTS(SELECT 1 FROM stories WHERE story_id = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) StoryExists(ctx context.Context, storyID int32) (bool, error) {
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of RiskSignal
+const (
+	RiskNotStarted     = "not_started"      // A story was not started by the end of its week
+	RiskHighIncorrect  = "high_incorrect"   // Most vocab and grammar answers are wrong
+	RiskShortPhaseTime = "short_phase_time" // A phase was finished suspiciously quickly
+)
+
+// DefaultRiskThresholds apply to courses that have not set their own
+var DefaultRiskThresholds = RiskThresholds{
+	GraceDays:         0,
+	MaxIncorrectRatio: 0.5,
+	MinAnswers:        10,
+	MinPhaseSeconds:   30,
+}
+
+var ErrInvalidRiskThresholds = errors.New("risk thresholds need a start date as YYYY-MM-DD (or none), an incorrect ratio from 0 to 1 and no negative counts")
+
+// RiskThresholds configure at-risk detection for a course
+type RiskThresholds struct {
+	StartsOn          string  `json:"starts_on"`           // First day of week 1 as YYYY-MM-DD; empty disables not-started signals
+	GraceDays         int     `json:"grace_days"`          // Days after a story's week before it counts as not started
+	MaxIncorrectRatio float64 `json:"max_incorrect_ratio"` // 0-1
+	MinAnswers        int     `json:"min_answers"`         // Answers needed before the incorrect ratio is judged
+	MinPhaseSeconds   int     `json:"min_phase_seconds"`   // 0 disables short-time signals
+	DigestEnabled     bool    `json:"digest_enabled"`      // Include the course in the daily digest
+}
+
+// RiskSignal is one reason a student may be falling behind
+type RiskSignal struct {
+	Kind       string  `json:"kind"`
+	StoryID    int     `json:"story_id,omitempty"`
+	StoryTitle string  `json:"story_title,omitempty"`
+	Phase      string  `json:"phase,omitempty"`
+	Value      float64 `json:"value"` // Days overdue, incorrect ratio or seconds spent
+	Threshold  float64 `json:"threshold"`
+}
+
+// String describes the signal for instructors
+func (r RiskSignal) String() string {
+	switch r.Kind {
+	case RiskNotStarted:
+		return fmt.Sprintf("has not started %q (%.0f days overdue)", r.StoryTitle, r.Value)
+	case RiskHighIncorrect:
+		return fmt.Sprintf("answered %.0f%% of vocab and grammar questions wrong", r.Value*100)
+	case RiskShortPhaseTime:
+		return fmt.Sprintf("spent only %.0fs on the %s phase of %q", r.Value, r.Phase, r.StoryTitle)
+	}
+	return r.Kind
+}
+
+// AtRiskStudent is a student with at least one risk signal
+type AtRiskStudent struct {
+	UserID   string       `json:"user_id"`
+	UserName string       `json:"user_name"`
+	Email    string       `json:"email"`
+	Signals  []RiskSignal `json:"signals"`
+}
+
+// CourseAtRisk lists the active students of a course who show risk signals
+type CourseAtRisk struct {
+	CourseID    int             `json:"course_id"`
+	CurrentWeek int             `json:"current_week"` // 0 before the course starts or without a start date
+	Thresholds  RiskThresholds  `json:"thresholds"`
+	Students    []AtRiskStudent `json:"students"` // Most signals first
+	ComputedAt  time.Time       `json:"computed_at"`
+}
+
+// GetCourseRiskThresholds returns the risk thresholds of a course, or the defaults if it has none
+func (s *Service) GetCourseRiskThresholds(ctx context.Context, courseID int32) (*RiskThresholds, error) {
+	result, err := s.queries.GetCourseRiskSettings(ctx, courseID)
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		thresholds := DefaultRiskThresholds
+		return &thresholds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return riskThresholdsFromDB(result), nil
+}
+
+// SetCourseRiskThresholds replaces the risk thresholds of a course
+func (s *Service) SetCourseRiskThresholds(ctx context.Context, courseID int32, thresholds RiskThresholds) (*RiskThresholds, error) {
+	startsOn, err := parseRiskStartDate(thresholds.StartsOn)
+	if err != nil || thresholds.GraceDays < 0 || thresholds.MinAnswers < 0 || thresholds.MinPhaseSeconds < 0 ||
+		thresholds.MaxIncorrectRatio < 0 || thresholds.MaxIncorrectRatio > 1 {
+		return nil, ErrInvalidRiskThresholds
+	}
+
+	date := pgtype.Date{}
+	if startsOn != nil {
+		date = pgtype.Date{Time: *startsOn, Valid: true}
+	}
+	result, err := s.queries.UpsertCourseRiskSettings(ctx, db.UpsertCourseRiskSettingsParams{
+		CourseID:          courseID,
+		StartsOn:          date,
+		GraceDays:         int32(thresholds.GraceDays),
+		MaxIncorrectRatio: thresholds.MaxIncorrectRatio,
+		MinAnswers:        int32(thresholds.MinAnswers),
+		MinPhaseSeconds:   int32(thresholds.MinPhaseSeconds),
+		DigestEnabled:     thresholds.DigestEnabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return riskThresholdsFromDB(result), nil
+}
+
+// GetCourseAtRisk checks every active student of a course against the course's risk
+// thresholds, using the gradebook, the answer logs and tracked time
+func (s *Service) GetCourseAtRisk(ctx context.Context, courseID int32) (*CourseAtRisk, error) {
+	thresholds, err := s.GetCourseRiskThresholds(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	gradebook, err := s.GetCourseGradebook(ctx, courseID, "active")
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.queries.GetCoursePhaseActivity(ctx, db.GetCoursePhaseActivityParams{CourseID: courseID, Status: "active"})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	return &CourseAtRisk{
+		CourseID:    int(courseID),
+		CurrentWeek: courseWeek(*thresholds, now),
+		Thresholds:  *thresholds,
+		Students:    detectAtRisk(gradebook, activity, *thresholds, now),
+		ComputedAt:  now,
+	}, nil
+}
+
+// detectAtRisk returns the gradebook's students that show risk signals, most signals first
+func detectAtRisk(gradebook *Gradebook, activity []db.GetCoursePhaseActivityRow, thresholds RiskThresholds, now time.Time) []AtRiskStudent {
+	type userStory struct {
+		userID  string
+		storyID int32
+	}
+	started := make(map[userStory]bool, len(activity))
+	for _, row := range activity {
+		started[userStory{row.UserID, row.StoryID}] = true
+	}
+	startsOn, _ := parseRiskStartDate(thresholds.StartsOn)
+
+	students := []AtRiskStudent{}
+	for _, student := range gradebook.Students {
+		var signals []RiskSignal
+		var correct, incorrect int64
+
+		for i, story := range gradebook.Stories {
+			storyID := story.Metadata.StoryID
+			title := story.Metadata.Title["en"]
+
+			// A story of week N is due by the end of week N; week 0 counts as week 1
+			if startsOn != nil && !started[userStory{student.UserID, int32(storyID)}] {
+				week := max(story.Metadata.WeekNumber, 1)
+				due := startsOn.AddDate(0, 0, 7*week+thresholds.GraceDays)
+				if !now.Before(due) {
+					signals = append(signals, RiskSignal{
+						Kind:       RiskNotStarted,
+						StoryID:    storyID,
+						StoryTitle: title,
+						Value:      math.Floor(now.Sub(due).Hours() / 24),
+						Threshold:  float64(thresholds.GraceDays),
+					})
+				}
+			}
+
+			performance := student.Stories[i]
+			correct += performance.VocabCorrect + performance.GrammarCorrect
+			incorrect += performance.VocabIncorrect + performance.GrammarIncorrect
+
+			// Phases without tracked time are skipped, the tracker may simply have missed them
+			phases := []struct {
+				phase   string
+				done    bool
+				seconds int32
+			}{
+				{PhaseVocab, performance.VocabCorrect+performance.VocabIncorrect > 0, performance.VocabTimeSeconds},
+				{PhaseTranslate, performance.TranslationCompleted, performance.TranslationTimeSeconds},
+				{PhaseGrammar, performance.GrammarCorrect+performance.GrammarIncorrect > 0, performance.GrammarTimeSeconds},
+			}
+			for _, p := range phases {
+				if p.done && p.seconds > 0 && int(p.seconds) < thresholds.MinPhaseSeconds {
+					signals = append(signals, RiskSignal{
+						Kind:       RiskShortPhaseTime,
+						StoryID:    storyID,
+						StoryTitle: title,
+						Phase:      p.phase,
+						Value:      float64(p.seconds),
+						Threshold:  float64(thresholds.MinPhaseSeconds),
+					})
+				}
+			}
+		}
+
+		if answers := correct + incorrect; answers > 0 && answers >= int64(thresholds.MinAnswers) {
+			if ratio := float64(incorrect) / float64(answers); ratio > thresholds.MaxIncorrectRatio {
+				signals = append(signals, RiskSignal{
+					Kind:      RiskHighIncorrect,
+					Value:     ratio,
+					Threshold: thresholds.MaxIncorrectRatio,
+				})
+			}
+		}
+
+		if len(signals) > 0 {
+			students = append(students, AtRiskStudent{
+				UserID:   student.UserID,
+				UserName: student.UserName,
+				Email:    student.Email,
+				Signals:  signals,
+			})
+		}
+	}
+
+	// Stable, so students with as many signals keep the gradebook's name order
+	slices.SortStableFunc(students, func(a, b AtRiskStudent) int {
+		return len(b.Signals) - len(a.Signals)
+	})
+	return students
+}
+
+// courseWeek returns the 1-indexed week of the course now falls in
+func courseWeek(thresholds RiskThresholds, now time.Time) int {
+	startsOn, _ := parseRiskStartDate(thresholds.StartsOn)
+	if startsOn == nil || now.Before(*startsOn) {
+		return 0
+	}
+	return int(now.Sub(*startsOn).Hours()/24)/7 + 1
+}
+
+func parseRiskStartDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+func riskThresholdsFromDB(result db.CourseRiskSetting) *RiskThresholds {
+	thresholds := &RiskThresholds{
+		GraceDays:         int(result.GraceDays),
+		MaxIncorrectRatio: result.MaxIncorrectRatio,
+		MinAnswers:        int(result.MinAnswers),
+		MinPhaseSeconds:   int(result.MinPhaseSeconds),
+		DigestEnabled:     result.DigestEnabled,
+	}
+	if result.StartsOn.Valid {
+		thresholds.StartsOn = result.StartsOn.Time.Format(time.DateOnly)
+	}
+	return thresholds
+}
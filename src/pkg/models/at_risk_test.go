@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/database"
+	"glossias/src/pkg/generated/db"
+	"strings"
+	"testing"
+	"time"
+)
+
+func riskTestGradebook() *Gradebook {
+	story := func(id, week int, title string) Story {
+		var s Story
+		s.Metadata.StoryID = id
+		s.Metadata.WeekNumber = week
+		s.Metadata.Title = map[string]string{"en": title}
+		return s
+	}
+	return &Gradebook{
+		CourseID: 1,
+		Stories:  []Story{story(10, 1, "First"), story(20, 2, "Second"), story(30, 3, "Third")},
+		Students: []GradebookStudent{
+			{UserID: "ada", UserName: "Ada", Stories: []CourseStudentPerformance{
+				{VocabCorrect: 8, VocabIncorrect: 2, VocabTimeSeconds: 300, GrammarCorrect: 4, GrammarTimeSeconds: 12},
+				{VocabCorrect: 5, VocabTimeSeconds: 200, TranslationCompleted: true},
+				{},
+			}},
+			{UserID: "bo", UserName: "Bo", Stories: []CourseStudentPerformance{
+				{VocabCorrect: 2, VocabIncorrect: 9, VocabTimeSeconds: 100},
+				{},
+				{},
+			}},
+			{UserID: "cy", UserName: "Cy", Stories: []CourseStudentPerformance{
+				{VocabCorrect: 1, VocabIncorrect: 3, VocabTimeSeconds: 100}, // Too few answers to judge
+				{VocabCorrect: 3, VocabTimeSeconds: 90},
+				{},
+			}},
+		},
+	}
+}
+
+func TestDetectAtRisk(t *testing.T) {
+	activity := []db.GetCoursePhaseActivityRow{
+		{UserID: "ada", StoryID: 10, Phase: PhaseVocab},
+		{UserID: "ada", StoryID: 20, Phase: PhaseVocab},
+		{UserID: "bo", StoryID: 10, Phase: PhaseVocab},
+		{UserID: "cy", StoryID: 10, Phase: PhaseVocab},
+		{UserID: "cy", StoryID: 20, Phase: PhaseVocab},
+	}
+	thresholds := RiskThresholds{StartsOn: "2026-09-07", GraceDays: 1, MaxIncorrectRatio: 0.5, MinAnswers: 10, MinPhaseSeconds: 30}
+	// Week 2 is over and its grace day passed three days ago; week 3 is still running
+	now := time.Date(2026, 9, 25, 12, 0, 0, 0, time.UTC)
+
+	students := detectAtRisk(riskTestGradebook(), activity, thresholds, now)
+
+	if len(students) != 2 {
+		t.Fatalf("Expected Bo and Ada at risk, got %+v", students)
+	}
+	bo := students[0]
+	if bo.UserID != "bo" || len(bo.Signals) != 2 {
+		t.Fatalf("Expected Bo first with 2 signals, got %+v", bo)
+	}
+	if signal := bo.Signals[0]; signal.Kind != RiskNotStarted || signal.StoryID != 20 || signal.Value != 3 {
+		t.Errorf("Expected Second 3 days overdue, got %+v", signal)
+	}
+	if signal := bo.Signals[1]; signal.Kind != RiskHighIncorrect || signal.Value <= 0.8 {
+		t.Errorf("Expected a high incorrect ratio, got %+v", signal)
+	}
+
+	ada := students[1]
+	if ada.UserID != "ada" || len(ada.Signals) != 1 {
+		t.Fatalf("Expected Ada with 1 signal, got %+v", ada)
+	}
+	if signal := ada.Signals[0]; signal.Kind != RiskShortPhaseTime || signal.Phase != PhaseGrammar || signal.StoryID != 10 || signal.Value != 12 {
+		t.Errorf("Expected 12 seconds on grammar of First, got %+v", signal)
+	}
+	if !strings.Contains(ada.Signals[0].String(), `grammar phase of "First"`) {
+		t.Errorf("Unexpected description %q", ada.Signals[0].String())
+	}
+
+	// Without a start date nothing is overdue
+	thresholds.StartsOn = ""
+	for _, student := range detectAtRisk(riskTestGradebook(), activity, thresholds, now) {
+		for _, signal := range student.Signals {
+			if signal.Kind == RiskNotStarted {
+				t.Errorf("Expected no not-started signals without a start date, got %+v", signal)
+			}
+		}
+	}
+}
+
+func TestCourseWeek(t *testing.T) {
+	thresholds := RiskThresholds{StartsOn: "2026-09-07"}
+	tests := map[time.Time]int{
+		time.Date(2026, 9, 6, 23, 0, 0, 0, time.UTC):  0,
+		time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC):   1,
+		time.Date(2026, 9, 13, 23, 0, 0, 0, time.UTC): 1,
+		time.Date(2026, 9, 14, 0, 0, 0, 0, time.UTC):  2,
+	}
+	for now, expected := range tests {
+		if got := courseWeek(thresholds, now); got != expected {
+			t.Errorf("courseWeek(%v) = %d, expected %d", now, got, expected)
+		}
+	}
+	if got := courseWeek(RiskThresholds{}, time.Now()); got != 0 {
+		t.Errorf("Expected week 0 without a start date, got %d", got)
+	}
+}
+
+func TestNextRiskDigest(t *testing.T) {
+	before := time.Date(2026, 10, 16, 6, 30, 0, 0, time.UTC)
+	if got := nextRiskDigest(before, 7); !got.Equal(time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected later today, got %v", got)
+	}
+	at := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	if got := nextRiskDigest(at, 7); !got.Equal(time.Date(2026, 10, 17, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected tomorrow, got %v", got)
+	}
+}
+
+func TestSetCourseRiskThresholdsValidation(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	invalid := []RiskThresholds{
+		{StartsOn: "07/09/2026", MaxIncorrectRatio: 0.5},
+		{MaxIncorrectRatio: 1.5},
+		{MaxIncorrectRatio: 0.5, MinAnswers: -1},
+		{MaxIncorrectRatio: 0.5, GraceDays: -2},
+	}
+	for _, thresholds := range invalid {
+		if _, err := svc.SetCourseRiskThresholds(context.Background(), 1, thresholds); err != ErrInvalidRiskThresholds {
+			t.Errorf("Expected ErrInvalidRiskThresholds for %+v, got %v", thresholds, err)
+		}
+	}
+}
+
+func TestGetCourseRiskThresholdsDefaults(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	thresholds, err := svc.GetCourseRiskThresholds(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *thresholds != DefaultRiskThresholds {
+		t.Errorf("Expected the defaults, got %+v", thresholds)
+	}
+}
+
+func TestFormatRiskDigest(t *testing.T) {
+	digest := RiskDigest{
+		Course:     Course{CourseNumber: "HEB101", Name: "Biblical Hebrew"},
+		Recipients: []CourseAdmin{{Email: "prof@example.edu"}},
+		AtRisk: CourseAtRisk{CurrentWeek: 3, Students: []AtRiskStudent{{
+			UserName: "Bo",
+			Email:    "bo@example.edu",
+			Signals:  []RiskSignal{{Kind: RiskHighIncorrect, Value: 0.82}},
+		}}},
+	}
+
+	text := formatRiskDigest(digest)
+
+	for _, want := range []string{"HEB101 Biblical Hebrew (week 3), to prof@example.edu", "- Bo <bo@example.edu>", "answered 82% of vocab and grammar questions wrong"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected digest to contain %q, got:\n%s", want, text)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 
 	"glossias/src/pkg/generated/db"
 
@@ -15,9 +14,9 @@ import (
 var ErrAudioFileExists = errors.New("audio file with this label already exists for this line")
 
 // CreateAudioFile creates a new audio file record
-func CreateAudioFile(ctx context.Context, storyID, lineNumber int, filePath, fileBucket, label string) (*AudioFile, error) {
+func (s *Service) CreateAudioFile(ctx context.Context, storyID, lineNumber int, filePath, fileBucket, label string) (*AudioFile, error) {
 	// Check if audio file already exists for this line and label
-	existingAudioFiles, err := GetLineAudioFiles(ctx, storyID, lineNumber)
+	existingAudioFiles, err := s.GetLineAudioFiles(ctx, storyID, lineNumber)
 	if err != nil {
 		return nil, err
 	}
@@ -27,12 +26,17 @@ func CreateAudioFile(ctx context.Context, storyID, lineNumber int, filePath, fil
 		}
 	}
 
-	result, err := queries.CreateAudioFile(ctx, db.CreateAudioFileParams{
-		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
-		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
-		FilePath:   filePath,
-		FileBucket: fileBucket,
-		Label:      label,
+	var result db.LineAudioFile
+	err = s.withStoryRevision(ctx, storyID, "add audio", func(txCtx context.Context) error {
+		var err error
+		result, err = s.queries.CreateAudioFile(txCtx, db.CreateAudioFileParams{
+			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
+			LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
+			FilePath:   filePath,
+			FileBucket: fileBucket,
+			Label:      label,
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -49,8 +53,8 @@ func CreateAudioFile(ctx context.Context, storyID, lineNumber int, filePath, fil
 }
 
 // GetAudioFile retrieves an audio file by ID
-func GetAudioFile(ctx context.Context, audioFileID int) (*AudioFile, error) {
-	result, err := queries.GetAudioFile(ctx, int32(audioFileID))
+func (s *Service) GetAudioFile(ctx context.Context, audioFileID int) (*AudioFile, error) {
+	result, err := s.queries.GetAudioFile(ctx, int32(audioFileID))
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -69,8 +73,8 @@ func GetAudioFile(ctx context.Context, audioFileID int) (*AudioFile, error) {
 }
 
 // GetLineAudioFiles retrieves all audio files for a specific line
-func GetLineAudioFiles(ctx context.Context, storyID, lineNumber int) ([]AudioFile, error) {
-	results, err := queries.GetLineAudioFiles(ctx, db.GetLineAudioFilesParams{
+func (s *Service) GetLineAudioFiles(ctx context.Context, storyID, lineNumber int) ([]AudioFile, error) {
+	results, err := s.queries.GetLineAudioFiles(ctx, db.GetLineAudioFilesParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -94,8 +98,8 @@ func GetLineAudioFiles(ctx context.Context, storyID, lineNumber int) ([]AudioFil
 }
 
 // GetStoryAudioFilesByLabel retrieves all audio files for a story with a specific label
-func GetStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) ([]AudioFile, error) {
-	results, err := queries.GetStoryAudioFilesByLabel(ctx, db.GetStoryAudioFilesByLabelParams{
+func (s *Service) GetStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) ([]AudioFile, error) {
+	results, err := s.queries.GetStoryAudioFilesByLabel(ctx, db.GetStoryAudioFilesByLabelParams{
 		StoryID: pgtype.Int4{Int32: int32(storyID), Valid: true},
 		Label:   label,
 	})
@@ -119,8 +123,8 @@ func GetStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) (
 }
 
 // GetAllStoryAudioFiles retrieves all audio files for a story
-func GetAllStoryAudioFiles(ctx context.Context, storyID int) ([]AudioFile, error) {
-	results, err := queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+func (s *Service) GetAllStoryAudioFiles(ctx context.Context, storyID int) ([]AudioFile, error) {
+	results, err := s.queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -141,13 +145,18 @@ func GetAllStoryAudioFiles(ctx context.Context, storyID int) ([]AudioFile, error
 }
 
 // UpdateAudioFile updates an existing audio file
-func UpdateAudioFile(ctx context.Context, audioFileID int, storyID int, filePath, fileBucket, label string) (*AudioFile, error) {
-	result, err := queries.UpdateAudioFile(ctx, db.UpdateAudioFileParams{
-		AudioFileID: int32(audioFileID),
-		StoryID:     pgtype.Int4{Int32: int32(storyID), Valid: true},
-		FilePath:    filePath,
-		FileBucket:  fileBucket,
-		Label:       label,
+func (s *Service) UpdateAudioFile(ctx context.Context, audioFileID int, storyID int, filePath, fileBucket, label string) (*AudioFile, error) {
+	var result db.LineAudioFile
+	err := s.withStoryRevision(ctx, storyID, "update audio", func(txCtx context.Context) error {
+		var err error
+		result, err = s.queries.UpdateAudioFile(txCtx, db.UpdateAudioFileParams{
+			AudioFileID: int32(audioFileID),
+			StoryID:     pgtype.Int4{Int32: int32(storyID), Valid: true},
+			FilePath:    filePath,
+			FileBucket:  fileBucket,
+			Label:       label,
+		})
+		return err
 	})
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return nil, ErrNotFound
@@ -166,39 +175,34 @@ func UpdateAudioFile(ctx context.Context, audioFileID int, storyID int, filePath
 	}, nil
 }
 
-// deleteAudioFilesFromStorage deletes audio files from Supabase storage
-func deleteAudioFilesFromStorage(audioFiles []AudioFile) error {
-	if storageClient == nil {
-		return errors.New("storage client not initialized")
-	}
-
+// deleteAudioFilesFromStorage deletes audio files from object storage, keeping
+// files that other stories still use
+func (s *Service) deleteAudioFilesFromStorage(ctx context.Context, audioFiles []AudioFile) error {
 	for _, audioFile := range audioFiles {
-		err := storageRetry(func() error {
-			_, removeErr := storageClient.RemoveFile(audioFile.FileBucket, []string{audioFile.FilePath})
-			return removeErr
-		})
-		if err != nil {
-			return fmt.Errorf("failed to delete file from storage: %w", err)
+		if err := s.removeStorageObject(ctx, audioFile.StoryID, audioFile.FileBucket, audioFile.FilePath); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // DeleteAudioFile deletes an audio file
-func DeleteAudioFile(ctx context.Context, audioFileID int) error {
+func (s *Service) DeleteAudioFile(ctx context.Context, audioFileID int) error {
 	// Get audio file details before deletion
-	audioFile, err := GetAudioFile(ctx, audioFileID)
+	audioFile, err := s.GetAudioFile(ctx, audioFileID)
 	if err != nil {
 		return err
 	}
 
-	// Delete from Supabase storage first
-	if err := deleteAudioFilesFromStorage([]AudioFile{*audioFile}); err != nil {
+	// Delete from storage first
+	if err := s.deleteAudioFilesFromStorage(ctx, []AudioFile{*audioFile}); err != nil {
 		return err
 	}
 
 	// Delete from database
-	err = queries.DeleteAudioFile(ctx, int32(audioFileID))
+	err = s.withStoryRevision(ctx, audioFile.StoryID, "delete audio", func(txCtx context.Context) error {
+		return s.queries.DeleteAudioFile(txCtx, int32(audioFileID))
+	})
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return ErrNotFound
 	}
@@ -206,65 +210,67 @@ func DeleteAudioFile(ctx context.Context, audioFileID int) error {
 }
 
 // DeleteLineAudioFiles deletes all audio files for a specific line
-func DeleteLineAudioFiles(ctx context.Context, storyID, lineNumber int) error {
+func (s *Service) DeleteLineAudioFiles(ctx context.Context, storyID, lineNumber int) error {
 	// Get all audio files for the line before deletion
-	audioFiles, err := GetLineAudioFiles(ctx, storyID, lineNumber)
+	audioFiles, err := s.GetLineAudioFiles(ctx, storyID, lineNumber)
 	if err != nil {
 		return err
 	}
 
-	// Delete from Supabase storage first
-	if err := deleteAudioFilesFromStorage(audioFiles); err != nil {
+	// Delete from storage first
+	if err := s.deleteAudioFilesFromStorage(ctx, audioFiles); err != nil {
 		return err
 	}
 
 	// Delete from database
-	return queries.DeleteLineAudioFiles(ctx, db.DeleteLineAudioFilesParams{
-		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
-		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
+	return s.withStoryRevision(ctx, storyID, "delete audio", func(txCtx context.Context) error {
+		return s.queries.DeleteLineAudioFiles(txCtx, db.DeleteLineAudioFilesParams{
+			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
+			LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
+		})
 	})
 }
 
 // DeleteStoryAudioFiles deletes all audio files for a story
-func DeleteStoryAudioFiles(ctx context.Context, storyID int) error {
+func (s *Service) DeleteStoryAudioFiles(ctx context.Context, storyID int) error {
 	// Get all audio files for the story before deletion
-	audioFiles, err := GetAllStoryAudioFiles(ctx, storyID)
+	audioFiles, err := s.GetAllStoryAudioFiles(ctx, storyID)
 	if err != nil {
 		return err
 	}
 
-	// Delete from Supabase storage first
-	if err := deleteAudioFilesFromStorage(audioFiles); err != nil {
+	// Delete from storage first
+	if err := s.deleteAudioFilesFromStorage(ctx, audioFiles); err != nil {
 		return err
 	}
 
 	// Delete from database
-	return queries.DeleteStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	return s.queries.DeleteStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 }
 
 // DeleteStoryAudioFilesByLabel deletes all audio files for a story with a specific label
-func DeleteStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) error {
+func (s *Service) DeleteStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) error {
 	// Get all audio files for the story with the label before deletion
-	audioFiles, err := GetStoryAudioFilesByLabel(ctx, storyID, label)
+	audioFiles, err := s.GetStoryAudioFilesByLabel(ctx, storyID, label)
 	if err != nil {
 		return err
 	}
 
-	// Delete from Supabase storage first
-	if err := deleteAudioFilesFromStorage(audioFiles); err != nil {
+	// Delete from storage first
+	if err := s.deleteAudioFilesFromStorage(ctx, audioFiles); err != nil {
 		return err
 	}
 
 	// Delete from database
-	return queries.DeleteStoryAudioFilesByLabel(ctx, db.DeleteStoryAudioFilesByLabelParams{
+	return s.queries.DeleteStoryAudioFilesByLabel(ctx, db.DeleteStoryAudioFilesByLabelParams{
 		StoryID: pgtype.Int4{Int32: int32(storyID), Valid: true},
 		Label:   label,
 	})
 }
 
 // GetAudioFilesByLabel returns all audio files with a specific label across all stories
-func GetAudioFilesByLabel(ctx context.Context, label string) ([]AudioFile, error) {
-	results, err := queries.GetAudioFilesByLabel(ctx, label)
+func (s *Service) GetAudioFilesByLabel(ctx context.Context, label string) ([]AudioFile, error) {
+	results, err := s.queries.GetAudioFilesByLabel(ctx, label)
 	if err != nil {
 		return nil, err
 	}
@@ -285,39 +291,32 @@ func GetAudioFilesByLabel(ctx context.Context, label string) ([]AudioFile, error
 }
 
 // GetSignedAudioURL generates a signed URL for a specific audio file
-func GetSignedAudioURL(ctx context.Context, audioFileID int, userID string, expiresInSeconds int) (string, error) {
-	if storageClient == nil {
+func (s *Service) GetSignedAudioURL(ctx context.Context, audioFileID int, userID string, expiresInSeconds int) (string, error) {
+	if s.storage == nil {
 		return "", errors.New("storage client not initialized")
 	}
 
 	// Get audio file record
-	audioFile, err := GetAudioFile(ctx, audioFileID)
+	audioFile, err := s.GetAudioFile(ctx, audioFileID)
 	if err != nil {
 		return "", err
 	}
 
 	// Check user can access this story's course
-	story, err := queries.GetStory(ctx, int32(audioFile.StoryID))
+	story, err := s.queries.GetStory(ctx, int32(audioFile.StoryID))
 	if err != nil {
 		return "", err
 	}
 
 	if story.CourseID.Valid {
-		canAccess := CanUserAccessCourse(ctx, userID, story.CourseID.Int32)
+		canAccess := s.CanUserAccessCourse(ctx, userID, story.CourseID.Int32)
 		if !canAccess {
 			return "", errors.New("access denied")
 		}
 	}
 
-	// Generate signed URL from Supabase with retry
-	var signedURL string
-	err = storageRetry(func() error {
-		result, signErr := storageClient.CreateSignedUrl(audioFile.FileBucket, audioFile.FilePath, expiresInSeconds)
-		if signErr == nil {
-			signedURL = result.SignedURL
-		}
-		return signErr
-	})
+	// Generate signed URL from storage
+	signedURL, err := s.storage.SignReadURL(audioFile.FileBucket, audioFile.FilePath, expiresInSeconds)
 	if err != nil {
 		return "", err
 	}
@@ -326,13 +325,13 @@ func GetSignedAudioURL(ctx context.Context, audioFileID int, userID string, expi
 }
 
 // GetSignedAudioURLsForStory generates signed URLs for all audio files in a story with optional label filter
-func GetSignedAudioURLsForStory(ctx context.Context, storyID int, userID string, label string, expiresInSeconds int) (map[int]string, error) {
-	if storageClient == nil {
+func (s *Service) GetSignedAudioURLsForStory(ctx context.Context, storyID int, userID string, label string, expiresInSeconds int) (map[int]string, error) {
+	if s.storage == nil {
 		return nil, errors.New("storage client not initialized")
 	}
 
 	// Check user can access this story's course
-	story, err := queries.GetStory(ctx, int32(storyID))
+	story, err := s.queries.GetStory(ctx, int32(storyID))
 	if err != nil {
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 			return nil, ErrNotFound
@@ -341,7 +340,7 @@ func GetSignedAudioURLsForStory(ctx context.Context, storyID int, userID string,
 	}
 
 	if story.CourseID.Valid {
-		canAccess := CanUserAccessCourse(ctx, userID, story.CourseID.Int32)
+		canAccess := s.CanUserAccessCourse(ctx, userID, story.CourseID.Int32)
 		if !canAccess {
 			return nil, errors.New("access denied")
 		}
@@ -350,25 +349,18 @@ func GetSignedAudioURLsForStory(ctx context.Context, storyID int, userID string,
 	// Get audio files
 	var audioFiles []AudioFile
 	if label != "" {
-		audioFiles, err = GetStoryAudioFilesByLabel(ctx, storyID, label)
+		audioFiles, err = s.GetStoryAudioFilesByLabel(ctx, storyID, label)
 	} else {
-		audioFiles, err = GetAllStoryAudioFiles(ctx, storyID)
+		audioFiles, err = s.GetAllStoryAudioFiles(ctx, storyID)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate signed URLs with retry
+	// Generate signed URLs
 	signedURLs := make(map[int]string)
 	for _, audioFile := range audioFiles {
-		var signedURL string
-		err := storageRetry(func() error {
-			result, signErr := storageClient.CreateSignedUrl(audioFile.FileBucket, audioFile.FilePath, expiresInSeconds)
-			if signErr == nil {
-				signedURL = result.SignedURL
-			}
-			return signErr
-		})
+		signedURL, err := s.storage.SignReadURL(audioFile.FileBucket, audioFile.FilePath, expiresInSeconds)
 		if err != nil {
 			return nil, err
 		}
@@ -379,41 +371,34 @@ func GetSignedAudioURLsForStory(ctx context.Context, storyID int, userID string,
 }
 
 // GetSignedAudioURLsForLine generates signed URLs for all audio files on a specific line
-func GetSignedAudioURLsForLine(ctx context.Context, storyID, lineNumber int, userID string, expiresInSeconds int) (map[int]string, error) {
-	if storageClient == nil {
+func (s *Service) GetSignedAudioURLsForLine(ctx context.Context, storyID, lineNumber int, userID string, expiresInSeconds int) (map[int]string, error) {
+	if s.storage == nil {
 		return nil, errors.New("storage client not initialized")
 	}
 
 	// Check user can access this story's course
-	story, err := queries.GetStory(ctx, int32(storyID))
+	story, err := s.queries.GetStory(ctx, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
 
 	if story.CourseID.Valid {
-		canAccess := CanUserAccessCourse(ctx, userID, story.CourseID.Int32)
+		canAccess := s.CanUserAccessCourse(ctx, userID, story.CourseID.Int32)
 		if !canAccess {
 			return nil, errors.New("access denied")
 		}
 	}
 
 	// Get audio files for the line
-	audioFiles, err := GetLineAudioFiles(ctx, storyID, lineNumber)
+	audioFiles, err := s.GetLineAudioFiles(ctx, storyID, lineNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate signed URLs with retry
+	// Generate signed URLs
 	signedURLs := make(map[int]string)
 	for _, audioFile := range audioFiles {
-		var signedURL string
-		err := storageRetry(func() error {
-			result, signErr := storageClient.CreateSignedUrl(audioFile.FileBucket, audioFile.FilePath, expiresInSeconds)
-			if signErr == nil {
-				signedURL = result.SignedURL
-			}
-			return signErr
-		})
+		signedURL, err := s.storage.SignReadURL(audioFile.FileBucket, audioFile.FilePath, expiresInSeconds)
 		if err != nil {
 			return nil, err
 		}
@@ -424,36 +409,23 @@ func GetSignedAudioURLsForLine(ctx context.Context, storyID, lineNumber int, use
 }
 
 // StoryExists checks if a story exists in the database
-func StoryExists(ctx context.Context, storyID int32) (bool, error) {
-	return queries.StoryExists(ctx, storyID)
+func (s *Service) StoryExists(ctx context.Context, storyID int32) (bool, error) {
+	return s.queries.StoryExists(ctx, storyID)
 }
 
 // LineExists checks if a specific line exists in a story
-func LineExists(ctx context.Context, storyID, lineNumber int) (bool, error) {
-	return queries.LineExists(ctx, db.LineExistsParams{
+func (s *Service) LineExists(ctx context.Context, storyID, lineNumber int) (bool, error) {
+	return s.queries.LineExists(ctx, db.LineExistsParams{
 		StoryID:    int32(storyID),
 		LineNumber: int32(lineNumber),
 	})
 }
 
-// GenerateSignedUploadURL creates a signed URL for uploading files to Supabase storage
-func GenerateSignedUploadURL(ctx context.Context, bucket, filePath string) (string, error) {
-	if storageClient == nil {
+// GenerateSignedUploadURL creates a signed URL for uploading files to object storage
+func (s *Service) GenerateSignedUploadURL(ctx context.Context, bucket, filePath string) (string, error) {
+	if s.storage == nil {
 		return "", errors.New("storage client not initialized")
 	}
 
-	// Generate signed upload URL with retry
-	var uploadURL string
-	err := storageRetry(func() error {
-		result, signErr := storageClient.CreateSignedUploadUrl(bucket, filePath)
-		if signErr == nil {
-			uploadURL = storageBaseURL + result.Url
-		}
-		return signErr
-	})
-	if err != nil {
-		return "", err
-	}
-
-	return uploadURL, nil
+	return s.storage.SignUploadURL(bucket, filePath)
 }
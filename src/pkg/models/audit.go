@@ -0,0 +1,176 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of audit target with a snapshot of their state
+const (
+	AuditTargetStory  = "story"
+	AuditTargetCourse = "course"
+)
+
+// Limits on how many audit events ListAuditEvents returns
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+// AuditEvent is one admin request that changed something. Before and After hold the
+// target's state around the request when it has a snapshot (see AuditSnapshot), and
+// otherwise After is the JSON response.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	RequestID  string          `json:"request_id"`
+	ActorID    string          `json:"actor_id"`
+	Action     string          `json:"action"` // Method and route, e.g. "PUT /stories/{id}/metadata"
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Status     int             `json:"status"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows ListAuditEvents; zero fields match everything. Action matches any
+// part of the action, case-insensitively. BeforeID pages back from an earlier result.
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	Since      *time.Time
+	Until      *time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+// courseAuditSnapshot is the state of a course recorded in the audit log, including who
+// administers it and who is enrolled
+type courseAuditSnapshot struct {
+	Course *Course       `json:"course"`
+	Admins []CourseAdmin `json:"admins"`
+	Users  []CourseUser  `json:"users"`
+}
+
+// HasAuditSnapshot reports whether AuditSnapshot knows the state of targets of the type
+func HasAuditSnapshot(targetType string) bool {
+	return targetType == AuditTargetStory || targetType == AuditTargetCourse
+}
+
+// AuditSnapshot returns the current state of an audit target as JSON: the whole story,
+// or a course with its admins and enrolled users. It is nil when the target does not
+// exist (yet, or any more) or its type has no snapshot.
+func (s *Service) AuditSnapshot(ctx context.Context, targetType, targetID string) (json.RawMessage, error) {
+	id, err := strconv.Atoi(targetID)
+	if err != nil || !HasAuditSnapshot(targetType) {
+		return nil, nil
+	}
+
+	var snapshot any
+	switch targetType {
+	case AuditTargetStory:
+		story, err := s.loadStory(ctx, id)
+		if err == ErrNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		snapshot = story
+	case AuditTargetCourse:
+		course, err := s.GetCourse(ctx, int32(id))
+		if err == ErrNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		admins, err := s.GetCourseAdmins(ctx, int32(id))
+		if err != nil {
+			return nil, err
+		}
+		users, err := s.GetUsersForCourse(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshot = courseAuditSnapshot{Course: course, Admins: admins, Users: users}
+	}
+	return json.Marshal(snapshot)
+}
+
+// RecordAuditEvent stores an audit event; ID and CreatedAt are set by the database
+func (s *Service) RecordAuditEvent(ctx context.Context, event AuditEvent) error {
+	return s.queries.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		RequestID:   event.RequestID,
+		ActorID:     event.ActorID,
+		Action:      event.Action,
+		TargetType:  event.TargetType,
+		TargetID:    event.TargetID,
+		Status:      int32(event.Status),
+		RequestBody: event.Request,
+		BeforeState: event.Before,
+		AfterState:  event.After,
+	})
+}
+
+// ListAuditEvents returns the events matching filter, newest first, at most filter.Limit
+// (DefaultAuditLimit when 0, never more than MaxAuditLimit)
+func (s *Service) ListAuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	text := func(value string) pgtype.Text {
+		return pgtype.Text{String: value, Valid: value != ""}
+	}
+	timestamp := func(t *time.Time) pgtype.Timestamp {
+		if t == nil {
+			return pgtype.Timestamp{}
+		}
+		return pgtype.Timestamp{Time: t.UTC(), Valid: true}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	limit = min(limit, MaxAuditLimit)
+
+	results, err := s.queries.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		ActorID:    text(filter.ActorID),
+		Action:     text(filter.Action),
+		TargetType: text(filter.TargetType),
+		TargetID:   text(filter.TargetID),
+		RequestID:  text(filter.RequestID),
+		Since:      timestamp(filter.Since),
+		Until:      timestamp(filter.Until),
+		BeforeID:   pgtype.Int8{Int64: filter.BeforeID, Valid: filter.BeforeID > 0},
+		MaxEvents:  int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]AuditEvent, len(results))
+	for i, result := range results {
+		events[i] = AuditEvent{
+			ID:         result.EventID,
+			RequestID:  result.RequestID,
+			ActorID:    result.ActorID,
+			Action:     result.Action,
+			TargetType: result.TargetType,
+			TargetID:   result.TargetID,
+			Status:     int(result.Status),
+			Request:    result.RequestBody,
+			Before:     result.BeforeState,
+			After:      result.AfterState,
+			CreatedAt:  result.CreatedAt.Time,
+		}
+	}
+	return events, nil
+}
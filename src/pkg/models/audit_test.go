@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/database"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestListAuditEvents(t *testing.T) {
+	mock := database.NewMockDBTX()
+	created := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	mock.StubQuery("-- name: ListAuditEvents", [][]interface{}{
+		{int64(42), "req-1", "user-1", "DELETE /stories/{id}", "story", "7", int32(200),
+			[]byte(nil), []byte(`{"metadata":{"storyId":7}}`), []byte(nil), pgtype.Timestamp{Time: created, Valid: true}},
+	}, nil)
+	svc := NewService(mock, nil, nil, nil)
+
+	events, err := svc.ListAuditEvents(context.Background(), AuditFilter{TargetType: "story", Limit: 5000})
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.ID != 42 || event.Action != "DELETE /stories/{id}" || event.TargetID != "7" || event.Status != 200 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if string(event.Before) != `{"metadata":{"storyId":7}}` || event.After != nil {
+		t.Errorf("Expected the story before the delete and nothing after, got %s and %s", event.Before, event.After)
+	}
+	if !event.CreatedAt.Equal(created) {
+		t.Errorf("Expected created at %v, got %v", created, event.CreatedAt)
+	}
+}
+
+func TestAuditSnapshotWithoutTarget(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	ctx := context.Background()
+
+	for _, tc := range []struct{ targetType, targetID string }{
+		{AuditTargetStory, "7"},      // Not stored
+		{AuditTargetCourse, "3"},     // Not stored
+		{AuditTargetStory, "import"}, // Not an ID
+		{"lti_platform", "1"},        // No snapshot for the type
+	} {
+		snapshot, err := svc.AuditSnapshot(ctx, tc.targetType, tc.targetID)
+		if err != nil || snapshot != nil {
+			t.Errorf("AuditSnapshot(%q, %q) = %s, %v; expected nothing", tc.targetType, tc.targetID, snapshot, err)
+		}
+	}
+}
@@ -1,82 +1,179 @@
 package models
 
 import (
+	"context"
 	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // InvalidateStoryCache removes cached data for a specific story
-func InvalidateStoryCache(storyID int, userID string) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) InvalidateStoryCache(storyID int, userID string) {
+	if s.cache == nil || s.keys == nil {
 		return
 	}
 
 	// Invalidate story data (no user ID in key now)
-	cacheKey := keyBuilder.StoryData(storyID)
-	_ = cacheInstance.Delete(cacheKey)
+	cacheKey := s.keys.StoryData(storyID)
+	_ = s.cache.Delete(cacheKey)
 
 	// Invalidate story metadata
-	metadataKey := keyBuilder.StoryMetadata(storyID)
-	_ = cacheInstance.Delete(metadataKey)
+	metadataKey := s.keys.StoryMetadata(storyID)
+	_ = s.cache.Delete(metadataKey)
 
 	// Invalidate story annotations
-	annotationsKey := keyBuilder.StoryAnnotations(storyID)
-	_ = cacheInstance.Delete(annotationsKey)
+	annotationsKey := s.keys.StoryAnnotations(storyID)
+	_ = s.cache.Delete(annotationsKey)
 
 	// Invalidate story vocab count
-	vocabCountKey := keyBuilder.StoryVocabCount(storyID)
-	_ = cacheInstance.Delete(vocabCountKey)
+	vocabCountKey := s.keys.StoryVocabCount(storyID)
+	_ = s.cache.Delete(vocabCountKey)
 
 	// Invalidate user access cache
-	accessKey := keyBuilder.UserAccess(userID, storyID)
-	_ = cacheInstance.Delete(accessKey)
+	accessKey := s.keys.UserAccess(userID, storyID)
+	_ = s.cache.Delete(accessKey)
 
 	fmt.Printf("Invalidated cache for story %d, user %s\n", storyID, userID)
 }
 
 // InvalidateStoryMetadata removes cached metadata for a specific story (affects all users)
-func InvalidateStoryMetadata(storyID int) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) InvalidateStoryMetadata(storyID int) {
+	if s.cache == nil || s.keys == nil {
 		return
 	}
 
 	// Invalidate story data
-	storyKey := keyBuilder.StoryData(storyID)
-	_ = cacheInstance.Delete(storyKey)
+	storyKey := s.keys.StoryData(storyID)
+	_ = s.cache.Delete(storyKey)
 
 	// Invalidate story metadata
-	metadataKey := keyBuilder.StoryMetadata(storyID)
-	_ = cacheInstance.Delete(metadataKey)
+	metadataKey := s.keys.StoryMetadata(storyID)
+	_ = s.cache.Delete(metadataKey)
 
 	// Invalidate story annotations
-	annotationsKey := keyBuilder.StoryAnnotations(storyID)
-	_ = cacheInstance.Delete(annotationsKey)
+	annotationsKey := s.keys.StoryAnnotations(storyID)
+	_ = s.cache.Delete(annotationsKey)
 
 	// Invalidate story vocab count
-	vocabCountKey := keyBuilder.StoryVocabCount(storyID)
-	_ = cacheInstance.Delete(vocabCountKey)
+	vocabCountKey := s.keys.StoryVocabCount(storyID)
+	_ = s.cache.Delete(vocabCountKey)
+
+	// Invalidate the story's course, which metadata edits may change
+	_ = s.cache.Delete(s.keys.StoryCourse(storyID))
 
 	fmt.Printf("Invalidated metadata cache for story %d\n", storyID)
 }
 
+// InvalidateVocabBankOrder removes the cached vocab bank order of a story
+func (s *Service) InvalidateVocabBankOrder(storyID int) {
+	if s.cache == nil || s.keys == nil {
+		return
+	}
+	_ = s.cache.Delete(s.keys.VocabBankOrder(storyID))
+}
+
 // InvalidateUserStoryCache removes all cached data for a user's story interactions
-func InvalidateUserStoryCache(userID string, storyID int) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) InvalidateUserStoryCache(userID string, storyID int) {
+	if s.cache == nil || s.keys == nil {
 		return
 	}
 
 	// Invalidate user-specific story data
-	InvalidateStoryCache(storyID, userID)
+	s.InvalidateStoryCache(storyID, userID)
 
 	// Invalidate user scores
-	vocabKey := keyBuilder.UserVocabScores(userID, storyID)
-	_ = cacheInstance.Delete(vocabKey)
+	vocabKey := s.keys.UserVocabScores(userID, storyID)
+	_ = s.cache.Delete(vocabKey)
 
-	grammarKey := keyBuilder.UserGrammarScores(userID, storyID)
-	_ = cacheInstance.Delete(grammarKey)
+	grammarKey := s.keys.UserGrammarScores(userID, storyID)
+	_ = s.cache.Delete(grammarKey)
 
 	fmt.Printf("Invalidated all user cache for user %s, story %d\n", userID, storyID)
 }
 
+// InvalidateCourseAnalytics removes a course's cached analytics under every status filter
+func (s *Service) InvalidateCourseAnalytics(courseID int) {
+	if s.cache == nil || s.keys == nil {
+		return
+	}
+
+	for _, status := range courseAnalyticsStatuses {
+		_ = s.cache.Delete(s.keys.CourseAnalytics(courseID, status))
+	}
+}
+
+// InvalidateStoryCourseAnalytics removes the cached analytics of the course a story belongs to
+func (s *Service) InvalidateStoryCourseAnalytics(ctx context.Context, storyID int) {
+	if s.cache == nil || s.keys == nil {
+		return
+	}
+
+	courseID, err := s.storyCourseID(ctx, storyID)
+	if err != nil {
+		fmt.Printf("Failed to look up course of story %d for analytics invalidation: %v\n", storyID, err)
+		return
+	}
+	if courseID != 0 {
+		s.InvalidateCourseAnalytics(int(courseID))
+	}
+}
+
+// storyCourseID returns the course of a story, or 0 if it has none. The access checks that
+// run before every answer save remember it, so saves rarely need the database.
+func (s *Service) storyCourseID(ctx context.Context, storyID int) (int32, error) {
+	var courseID int32
+	if err := s.cache.GetJSON(s.keys.StoryCourse(storyID), &courseID); err == nil {
+		return courseID, nil
+	}
+
+	story, err := s.queries.GetStory(ctx, int32(storyID))
+	if err != nil {
+		return 0, err
+	}
+	s.rememberStoryCourse(storyID, story.CourseID)
+	return story.CourseID.Int32, nil
+}
+
+// rememberStoryCourse caches the course of a story that an access check has loaded
+func (s *Service) rememberStoryCourse(storyID int, courseID pgtype.Int4) {
+	if s.cache == nil || s.keys == nil {
+		return
+	}
+	_ = s.cache.SetJSON(s.keys.StoryCourse(storyID), courseID.Int32)
+}
+
+// storyCourseChanged invalidates the analytics of the course a story left and the course it
+// joined. The cached course itself goes with InvalidateStoryMetadata.
+func (s *Service) storyCourseChanged(previous, current pgtype.Int4) {
+	if previous == current {
+		return
+	}
+	if previous.Valid {
+		s.InvalidateCourseAnalytics(int(previous.Int32))
+	}
+	if current.Valid {
+		s.InvalidateCourseAnalytics(int(current.Int32))
+	}
+}
+
+// enrollmentChanged invalidates a course's analytics when err reports that its enrollment
+// changed, and returns err so enrollment paths can wrap their result
+func (s *Service) enrollmentChanged(courseID int, err error) error {
+	if err == nil {
+		s.InvalidateCourseAnalytics(courseID)
+	}
+	return err
+}
+
+// answersSaved invalidates course analytics when err reports that answers to a story were
+// saved, and returns err so save paths can wrap their result
+func (s *Service) answersSaved(ctx context.Context, storyID int, err error) error {
+	if err == nil {
+		s.InvalidateStoryCourseAnalytics(ctx, storyID)
+	}
+	return err
+}
+
 // InvalidateAllStoriesCache - No longer needed since we don't cache story lists
 // Story lists are user-specific due to access controls, so caching would be a security risk
 func InvalidateAllStoriesCache(language string) {
@@ -85,12 +182,12 @@ func InvalidateAllStoriesCache(language string) {
 }
 
 // ClearAllCache removes all cached data
-func ClearAllCache() error {
-	if cacheInstance == nil {
+func (s *Service) ClearAllCache() error {
+	if s.cache == nil {
 		return nil
 	}
 
-	err := cacheInstance.Clear()
+	err := s.cache.Clear()
 	if err != nil {
 		return fmt.Errorf("failed to clear cache: %w", err)
 	}
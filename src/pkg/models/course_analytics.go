@@ -0,0 +1,357 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+)
+
+const (
+	analyticsTopMissed     = 20 // Most-missed vocabulary and grammar points listed
+	analyticsWrongAnswers  = 3  // Most common wrong answers listed per lexical form
+	accuracyBucketWidth    = 10 // Percentage points per histogram bucket
+	accuracyBucketMaxValue = 100
+)
+
+// courseAnalyticsStatuses are the enrollment status filters course analytics are cached under
+var courseAnalyticsStatuses = []string{"", "active", "future", "past"}
+
+// CourseAnalytics aggregates student activity across every story of a course.
+// Students are enrollments matching Status; "" counts every enrollment.
+type CourseAnalytics struct {
+	CourseID      int                   `json:"course_id"`
+	Status        string                `json:"status"`
+	Students      int                   `json:"students"`
+	Stories       int                   `json:"stories"`
+	Funnel        []PhaseFunnelStep     `json:"funnel"`
+	PhaseTimes    []PhaseTime           `json:"phase_times"`
+	Accuracy      AccuracyDistributions `json:"accuracy"`
+	MissedVocab   []MissedVocab         `json:"missed_vocab"`
+	MissedGrammar []MissedGrammarPoint  `json:"missed_grammar"`
+	ComputedAt    time.Time             `json:"computed_at"`
+}
+
+// PhaseFunnelStep counts how far students got through one phase, in phase flow order
+type PhaseFunnelStep struct {
+	Phase    string  `json:"phase"`
+	Students int     `json:"students"` // Students with activity in the phase of at least one story
+	Reached  int     `json:"reached"`  // Student/story pairs with activity in the phase
+	Rate     float64 `json:"rate"`     // Reached as a percentage of students times stories
+}
+
+// PhaseTime is the median time a student spends in a phase of one story
+type PhaseTime struct {
+	Phase         string  `json:"phase"`
+	MedianSeconds float64 `json:"median_seconds"`
+	Samples       int     `json:"samples"` // Student/story pairs with tracked time in the phase
+}
+
+// AccuracyDistributions holds histograms of per-story accuracy
+type AccuracyDistributions struct {
+	Vocab   AccuracyHistogram `json:"vocab"`
+	Grammar AccuracyHistogram `json:"grammar"`
+}
+
+// AccuracyHistogram buckets the accuracy of every student/story pair that has answered at least once
+type AccuracyHistogram struct {
+	Buckets []AccuracyBucket `json:"buckets"`
+	Samples int              `json:"samples"`
+}
+
+// AccuracyBucket counts accuracies from From (inclusive) to To (exclusive; the last bucket includes 100)
+type AccuracyBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+// MissedVocab is a lexical form students answered wrong, with the answers they gave most often
+type MissedVocab struct {
+	LexicalForm  string        `json:"lexical_form"`
+	Misses       int           `json:"misses"`
+	Students     int           `json:"students"`
+	WrongAnswers []WrongAnswer `json:"wrong_answers"`
+}
+
+// WrongAnswer is an incorrect answer and how often it was given
+type WrongAnswer struct {
+	Answer string `json:"answer"`
+	Count  int    `json:"count"`
+}
+
+// MissedGrammarPoint is a grammar point students selected wrong instances of
+type MissedGrammarPoint struct {
+	GrammarPointID int    `json:"grammar_point_id"`
+	Name           string `json:"name"`
+	StoryID        int    `json:"story_id"`
+	StoryTitle     string `json:"story_title"`
+	Misses         int    `json:"misses"`
+	Students       int    `json:"students"`
+}
+
+// GetCourseAnalytics returns the analytics of a course. Results are cached until answers
+// are saved to one of the course's stories, so tracked time alone may lag behind.
+func (s *Service) GetCourseAnalytics(ctx context.Context, courseID int32, status string) (*CourseAnalytics, error) {
+	if s.cache == nil || s.keys == nil {
+		return s.computeCourseAnalytics(ctx, courseID, status)
+	}
+
+	var analytics CourseAnalytics
+	cacheKey := s.keys.CourseAnalytics(int(courseID), status)
+	err := s.cache.GetOrSetJSON(cacheKey, &analytics, func() (any, error) {
+		return s.computeCourseAnalytics(ctx, courseID, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &analytics, nil
+}
+
+func (s *Service) computeCourseAnalytics(ctx context.Context, courseID int32, status string) (*CourseAnalytics, error) {
+	// The gradebook already holds per-story accuracy for every student
+	gradebook, err := s.GetCourseGradebook(ctx, courseID, status)
+	if err != nil {
+		return nil, err
+	}
+	students, err := s.queries.CountCourseStudents(ctx, db.CountCourseStudentsParams{CourseID: courseID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+	flow, err := s.GetCoursePhaseFlow(ctx, int(courseID))
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.queries.GetCoursePhaseActivity(ctx, db.GetCoursePhaseActivityParams{CourseID: courseID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+	routeTimes, err := s.queries.GetCourseRouteTimes(ctx, db.GetCourseRouteTimesParams{CourseID: courseID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+	missedVocab, err := s.queries.GetCourseMissedVocab(ctx, db.GetCourseMissedVocabParams{
+		CourseID: courseID,
+		Status:   status,
+		MaxRows:  analyticsTopMissed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	wrongAnswers, err := s.queries.GetCourseVocabWrongAnswers(ctx, db.GetCourseVocabWrongAnswersParams{CourseID: courseID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+	missedGrammar, err := s.queries.GetCourseMissedGrammar(ctx, db.GetCourseMissedGrammarParams{
+		CourseID: courseID,
+		Status:   status,
+		MaxRows:  analyticsTopMissed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	storyTitles := make(map[int]string, len(gradebook.Stories))
+	for _, story := range gradebook.Stories {
+		storyTitles[story.Metadata.StoryID] = story.Metadata.Title["en"]
+	}
+
+	analytics := &CourseAnalytics{
+		CourseID:      int(courseID),
+		Status:        status,
+		Students:      int(students),
+		Stories:       len(gradebook.Stories),
+		Funnel:        buildPhaseFunnel(flow.Phases, activity, int(students), len(gradebook.Stories)),
+		PhaseTimes:    buildPhaseTimes(flow.Phases, routeTimes),
+		Accuracy:      buildAccuracyDistributions(gradebook),
+		MissedVocab:   buildMissedVocab(missedVocab, wrongAnswers),
+		MissedGrammar: make([]MissedGrammarPoint, len(missedGrammar)),
+		ComputedAt:    s.clock.Now(),
+	}
+	for i, row := range missedGrammar {
+		analytics.MissedGrammar[i] = MissedGrammarPoint{
+			GrammarPointID: int(row.GrammarPointID),
+			Name:           row.Name,
+			StoryID:        int(row.StoryID),
+			StoryTitle:     storyTitles[int(row.StoryID)],
+			Misses:         int(row.Misses),
+			Students:       int(row.Students),
+		}
+	}
+	return analytics, nil
+}
+
+// analyticsPhaseOrder lists the course flow's phases followed by any other phase that
+// shows up in used, since stories may override the course flow
+func analyticsPhaseOrder(flow []string, used map[string]bool) []string {
+	phases := slices.Clone(flow)
+	for _, phase := range KnownPhases {
+		if used[phase] && !slices.Contains(phases, phase) {
+			phases = append(phases, phase)
+		}
+	}
+	return phases
+}
+
+// buildPhaseFunnel counts the students and student/story pairs with activity in each phase
+func buildPhaseFunnel(flow []string, activity []db.GetCoursePhaseActivityRow, students, stories int) []PhaseFunnelStep {
+	reached := make(map[string]int)
+	studentSets := make(map[string]map[string]bool)
+	for _, row := range activity {
+		reached[row.Phase]++
+		if studentSets[row.Phase] == nil {
+			studentSets[row.Phase] = make(map[string]bool)
+		}
+		studentSets[row.Phase][row.UserID] = true
+	}
+
+	used := make(map[string]bool, len(reached))
+	for phase := range reached {
+		used[phase] = true
+	}
+	phases := analyticsPhaseOrder(flow, used)
+
+	funnel := make([]PhaseFunnelStep, len(phases))
+	for i, phase := range phases {
+		funnel[i] = PhaseFunnelStep{
+			Phase:    phase,
+			Students: len(studentSets[phase]),
+			Reached:  reached[phase],
+		}
+		if students > 0 && stories > 0 {
+			funnel[i].Rate = float64(reached[phase]) / float64(students*stories) * 100
+		}
+	}
+	return funnel
+}
+
+// phaseForRoute maps a tracked page route to its phase, or "" for pages outside the phases.
+// Audio pages count as video, like in GetStoryStudentPerformance.
+func phaseForRoute(route string) string {
+	if strings.Contains(route, "audio") {
+		return PhaseVideo
+	}
+	for _, phase := range KnownPhases {
+		if strings.Contains(route, phase) {
+			return phase
+		}
+	}
+	return ""
+}
+
+// buildPhaseTimes computes the median time per phase over student/story pairs that spent time in it
+func buildPhaseTimes(flow []string, routeTimes []db.GetCourseRouteTimesRow) []PhaseTime {
+	type pairPhase struct {
+		userID  string
+		storyID int32
+		phase   string
+	}
+	totals := make(map[pairPhase]int64)
+	for _, row := range routeTimes {
+		phase := phaseForRoute(row.Route)
+		if phase == "" || row.TotalSeconds <= 0 {
+			continue
+		}
+		totals[pairPhase{userID: row.UserID, storyID: row.StoryID, phase: phase}] += row.TotalSeconds
+	}
+
+	samples := make(map[string][]int64)
+	used := make(map[string]bool)
+	for key, seconds := range totals {
+		samples[key.phase] = append(samples[key.phase], seconds)
+		used[key.phase] = true
+	}
+
+	var times []PhaseTime
+	for _, phase := range analyticsPhaseOrder(flow, used) {
+		if len(samples[phase]) == 0 {
+			continue
+		}
+		times = append(times, PhaseTime{
+			Phase:         phase,
+			MedianSeconds: median(samples[phase]),
+			Samples:       len(samples[phase]),
+		})
+	}
+	if times == nil {
+		times = []PhaseTime{}
+	}
+	return times
+}
+
+// median returns the median of values, averaging the middle two of an even count. It sorts values.
+func median(values []int64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return float64(values[mid])
+	}
+	return float64(values[mid-1]+values[mid]) / 2
+}
+
+// buildAccuracyDistributions histograms the vocab and grammar accuracy of every gradebook
+// cell where the student answered at least once
+func buildAccuracyDistributions(gradebook *Gradebook) AccuracyDistributions {
+	distributions := AccuracyDistributions{
+		Vocab:   newAccuracyHistogram(),
+		Grammar: newAccuracyHistogram(),
+	}
+	for _, student := range gradebook.Students {
+		for _, cell := range student.Stories {
+			if cell.VocabCorrect+cell.VocabIncorrect > 0 {
+				distributions.Vocab.add(cell.VocabAccuracy)
+			}
+			if cell.GrammarCorrect+cell.GrammarIncorrect > 0 {
+				distributions.Grammar.add(cell.GrammarAccuracy)
+			}
+		}
+	}
+	return distributions
+}
+
+func newAccuracyHistogram() AccuracyHistogram {
+	buckets := make([]AccuracyBucket, 0, accuracyBucketMaxValue/accuracyBucketWidth)
+	for from := 0; from < accuracyBucketMaxValue; from += accuracyBucketWidth {
+		buckets = append(buckets, AccuracyBucket{From: from, To: from + accuracyBucketWidth})
+	}
+	return AccuracyHistogram{Buckets: buckets}
+}
+
+// add counts an accuracy percentage in its bucket
+func (h *AccuracyHistogram) add(accuracy float64) {
+	index := int(accuracy) / accuracyBucketWidth
+	index = min(max(index, 0), len(h.Buckets)-1)
+	h.Buckets[index].Count++
+	h.Samples++
+}
+
+// buildMissedVocab attaches the most common wrong answers to each most-missed lexical form
+func buildMissedVocab(missed []db.GetCourseMissedVocabRow, answers []db.GetCourseVocabWrongAnswersRow) []MissedVocab {
+	// Answers come ordered by lexical form, then most given first
+	byForm := make(map[string][]WrongAnswer)
+	for _, row := range answers {
+		if len(byForm[row.LexicalForm]) < analyticsWrongAnswers {
+			byForm[row.LexicalForm] = append(byForm[row.LexicalForm], WrongAnswer{Answer: row.IncorrectAnswer, Count: int(row.Times)})
+		}
+	}
+
+	result := make([]MissedVocab, len(missed))
+	for i, row := range missed {
+		wrong := byForm[row.LexicalForm]
+		if wrong == nil {
+			wrong = []WrongAnswer{}
+		}
+		result[i] = MissedVocab{
+			LexicalForm:  row.LexicalForm,
+			Misses:       int(row.Misses),
+			Students:     int(row.Students),
+			WrongAnswers: wrong,
+		}
+	}
+	return result
+}
@@ -0,0 +1,255 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"glossias/src/pkg/cache"
+	"glossias/src/pkg/database"
+	"glossias/src/pkg/generated/db"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestPhaseForRoute(t *testing.T) {
+	tests := map[string]string{
+		"/stories/12/vocab":     PhaseVocab,
+		"/stories/12/grammar":   PhaseGrammar,
+		"/stories/12/translate": PhaseTranslate,
+		"/stories/12/audio":     PhaseVideo,
+		"/stories/12/video":     PhaseVideo,
+		"/stories/12/score":     PhaseScore,
+		"/stories/12":           "",
+		"/":                     "",
+	}
+	for route, expected := range tests {
+		if got := phaseForRoute(route); got != expected {
+			t.Errorf("phaseForRoute(%q) = %q, expected %q", route, got, expected)
+		}
+	}
+}
+
+func TestBuildPhaseFunnel(t *testing.T) {
+	activity := []db.GetCoursePhaseActivityRow{
+		{UserID: "a", StoryID: 1, Phase: PhaseVideo},
+		{UserID: "a", StoryID: 2, Phase: PhaseVideo},
+		{UserID: "b", StoryID: 1, Phase: PhaseVideo},
+		{UserID: "a", StoryID: 1, Phase: PhaseVocab},
+		{UserID: "a", StoryID: 1, Phase: PhaseRecall}, // From a story overriding the course flow
+	}
+
+	funnel := buildPhaseFunnel(DefaultPhaseFlow, activity, 2, 2)
+
+	expected := []PhaseFunnelStep{
+		{Phase: PhaseVideo, Students: 2, Reached: 3, Rate: 75},
+		{Phase: PhaseVocab, Students: 1, Reached: 1, Rate: 25},
+		{Phase: PhaseTranslate},
+		{Phase: PhaseGrammar},
+		{Phase: PhaseScore},
+		{Phase: PhaseRecall, Students: 1, Reached: 1, Rate: 25},
+	}
+	if len(funnel) != len(expected) {
+		t.Fatalf("Expected %d steps, got %+v", len(expected), funnel)
+	}
+	for i := range expected {
+		if funnel[i] != expected[i] {
+			t.Errorf("Step %d: expected %+v, got %+v", i, expected[i], funnel[i])
+		}
+	}
+
+	// An empty course must not divide by zero
+	for _, step := range buildPhaseFunnel(DefaultPhaseFlow, nil, 0, 0) {
+		if step.Rate != 0 {
+			t.Errorf("Expected rate 0 without students, got %+v", step)
+		}
+	}
+}
+
+func TestBuildPhaseTimes(t *testing.T) {
+	routeTimes := []db.GetCourseRouteTimesRow{
+		// Audio and video of the same story add up
+		{UserID: "a", StoryID: 1, Route: "/stories/1/audio", TotalSeconds: 30},
+		{UserID: "a", StoryID: 1, Route: "/stories/1/video", TotalSeconds: 30},
+		{UserID: "b", StoryID: 1, Route: "/stories/1/video", TotalSeconds: 100},
+		{UserID: "c", StoryID: 1, Route: "/stories/1/video", TotalSeconds: 10},
+		{UserID: "a", StoryID: 1, Route: "/stories/1/vocab", TotalSeconds: 40},
+		{UserID: "b", StoryID: 1, Route: "/stories/1/vocab", TotalSeconds: 20},
+		{UserID: "a", StoryID: 1, Route: "/stories/1", TotalSeconds: 500},
+	}
+
+	times := buildPhaseTimes(DefaultPhaseFlow, routeTimes)
+
+	expected := []PhaseTime{
+		{Phase: PhaseVideo, MedianSeconds: 60, Samples: 3},
+		{Phase: PhaseVocab, MedianSeconds: 30, Samples: 2},
+	}
+	if len(times) != len(expected) {
+		t.Fatalf("Expected %d phases, got %+v", len(expected), times)
+	}
+	for i := range expected {
+		if times[i] != expected[i] {
+			t.Errorf("Phase %d: expected %+v, got %+v", i, expected[i], times[i])
+		}
+	}
+}
+
+func TestBuildAccuracyDistributions(t *testing.T) {
+	gradebook := &Gradebook{Students: []GradebookStudent{
+		{Stories: []CourseStudentPerformance{
+			{VocabCorrect: 4, VocabAccuracy: 100, GrammarCorrect: 1, GrammarIncorrect: 1, GrammarAccuracy: 45},
+			{}, // Not started, left out of both histograms
+		}},
+		{Stories: []CourseStudentPerformance{
+			{VocabIncorrect: 3, VocabAccuracy: 0},
+			{VocabCorrect: 1, VocabAccuracy: 99.9},
+		}},
+	}}
+
+	distributions := buildAccuracyDistributions(gradebook)
+
+	if distributions.Vocab.Samples != 3 || distributions.Grammar.Samples != 1 {
+		t.Fatalf("Expected 3 vocab and 1 grammar samples, got %d and %d",
+			distributions.Vocab.Samples, distributions.Grammar.Samples)
+	}
+	if len(distributions.Vocab.Buckets) != 10 {
+		t.Fatalf("Expected 10 buckets, got %d", len(distributions.Vocab.Buckets))
+	}
+	last := distributions.Vocab.Buckets[9]
+	if last.From != 90 || last.To != 100 || last.Count != 2 {
+		t.Errorf("Expected 100 and 99.9 in the last bucket, got %+v", last)
+	}
+	if distributions.Vocab.Buckets[0].Count != 1 {
+		t.Errorf("Expected 0 in the first bucket, got %+v", distributions.Vocab.Buckets[0])
+	}
+	if distributions.Grammar.Buckets[4].Count != 1 {
+		t.Errorf("Expected 45 in the 40-50 bucket, got %+v", distributions.Grammar.Buckets[4])
+	}
+}
+
+func TestBuildMissedVocab(t *testing.T) {
+	missed := []db.GetCourseMissedVocabRow{
+		{LexicalForm: "ir", Misses: 9, Students: 3},
+		{LexicalForm: "ser", Misses: 2, Students: 2},
+	}
+	answers := []db.GetCourseVocabWrongAnswersRow{
+		{LexicalForm: "estar", IncorrectAnswer: "ser", Times: 7}, // Not among the most missed
+		{LexicalForm: "ir", IncorrectAnswer: "venir", Times: 4},
+		{LexicalForm: "ir", IncorrectAnswer: "ser", Times: 3},
+		{LexicalForm: "ir", IncorrectAnswer: "dar", Times: 1},
+		{LexicalForm: "ir", IncorrectAnswer: "estar", Times: 1},
+	}
+
+	result := buildMissedVocab(missed, answers)
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 lexical forms, got %+v", result)
+	}
+	if result[0].LexicalForm != "ir" || result[0].Misses != 9 || result[0].Students != 3 {
+		t.Errorf("Unexpected first form %+v", result[0])
+	}
+	if len(result[0].WrongAnswers) != analyticsWrongAnswers || result[0].WrongAnswers[0] != (WrongAnswer{Answer: "venir", Count: 4}) {
+		t.Errorf("Expected the 3 most common wrong answers, got %+v", result[0].WrongAnswers)
+	}
+	if result[1].WrongAnswers == nil || len(result[1].WrongAnswers) != 0 {
+		t.Errorf("Expected an empty answer list, got %#v", result[1].WrongAnswers)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if got := median([]int64{5, 1, 3}); got != 3 {
+		t.Errorf("Expected 3, got %v", got)
+	}
+	if got := median([]int64{4, 1, 3, 2}); got != 2.5 {
+		t.Errorf("Expected 2.5, got %v", got)
+	}
+	if got := median(nil); got != 0 {
+		t.Errorf("Expected 0, got %v", got)
+	}
+}
+
+func TestSavingAnswersInvalidatesCourseAnalytics(t *testing.T) {
+	c, err := cache.New(cache.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to initialize cache: %v", err)
+	}
+	mockDBTX := database.NewMockDBTX()
+	// GetStory scans: StoryID, WeekNumber, DayLetter, VideoUrl, LastRevision, AuthorID, AuthorName, CourseID, DeletedAt
+	mockDBTX.StubQuery("SELECT s.story_id", [][]interface{}{{
+		int32(42), int32(1), "A", pgtype.Text{}, pgtype.Timestamp{}, "author-123", "Jane Doe",
+		pgtype.Int4{Int32: 101, Valid: true}, pgtype.Timestamp{},
+	}}, nil)
+	svc := NewService(mockDBTX, nil, c, nil)
+	keys := cache.NewKeyBuilder()
+
+	fill := func() {
+		for _, status := range courseAnalyticsStatuses {
+			_ = c.Set(keys.CourseAnalytics(101, status), []byte("{}"))
+		}
+		_ = c.Set(keys.CourseAnalytics(102, ""), []byte("{}"))
+	}
+	cached := func(courseID int, status string) bool {
+		_, err := c.Get(keys.CourseAnalytics(courseID, status))
+		return err == nil
+	}
+
+	// A failed save leaves the cache alone
+	fill()
+	mockDBTX.StubExec("SaveIdentifyIncorrectAnswer", errors.New("insert failed"))
+	if err := svc.SaveIdentifyScore(context.Background(), "user-1", 42, 1, 5, false, 6); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+	if !cached(101, "active") {
+		t.Error("Expected analytics to stay cached after a failed save")
+	}
+
+	if err := svc.SaveIdentifyScore(context.Background(), "user-1", 42, 1, 5, true, 5); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, status := range courseAnalyticsStatuses {
+		if cached(101, status) {
+			t.Errorf("Expected analytics of course 101 with status %q to be invalidated", status)
+		}
+	}
+	if !cached(102, "") {
+		t.Error("Expected analytics of other courses to stay cached")
+	}
+}
+
+func TestCourseAnalyticsFollowEnrollmentAndStoryCourse(t *testing.T) {
+	c, err := cache.New(cache.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to initialize cache: %v", err)
+	}
+	// Without a GetStory stub, any course lookup in the database fails
+	svc := NewService(database.NewMockDBTX(), nil, c, nil)
+	keys := cache.NewKeyBuilder()
+	cached := func(courseID int) bool {
+		_, err := c.Get(keys.CourseAnalytics(courseID, "active"))
+		return err == nil
+	}
+
+	// An answer save reuses the course remembered by the access check
+	_ = c.Set(keys.CourseAnalytics(101, "active"), []byte("{}"))
+	svc.rememberStoryCourse(42, pgtype.Int4{Int32: 101, Valid: true})
+	if err := svc.SaveIdentifyScore(context.Background(), "user-1", 42, 1, 5, true, 5); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cached(101) {
+		t.Error("Expected analytics of course 101 to be invalidated without a database lookup")
+	}
+
+	_ = c.Set(keys.CourseAnalytics(101, "active"), []byte("{}"))
+	if err := svc.RemoveUserFromCourse(context.Background(), 101, "user-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cached(101) {
+		t.Error("Expected an enrollment change to invalidate the course's analytics")
+	}
+
+	_ = c.Set(keys.CourseAnalytics(101, "active"), []byte("{}"))
+	_ = c.Set(keys.CourseAnalytics(102, "active"), []byte("{}"))
+	svc.storyCourseChanged(pgtype.Int4{Int32: 101, Valid: true}, pgtype.Int4{Int32: 102, Valid: true})
+	if cached(101) || cached(102) {
+		t.Error("Expected a story moving courses to invalidate the analytics of both courses")
+	}
+}
@@ -0,0 +1,526 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrMissingCourseNumber = errors.New("course number is required")
+	ErrCourseNumberTaken   = errors.New("course number already in use")
+)
+
+// CourseCloneOptions says how the copy of a course differs from the original
+type CourseCloneOptions struct {
+	CourseNumber string  // Required, must not be in use
+	Name         string  // Empty keeps the original name
+	Description  *string // Nil keeps the original description
+	CopyAdmins   bool    // Make the course admins of the original admins of the copy
+}
+
+// ClonedStory pairs a story of the original course with its copy
+type ClonedStory struct {
+	SourceStoryID int `json:"source_story_id"`
+	StoryID       int `json:"story_id"`
+}
+
+// CourseClone is a newly cloned course and the stories copied into it
+type CourseClone struct {
+	Course  Course        `json:"course"`
+	Stories []ClonedStory `json:"stories"`
+	Admins  int           `json:"admins"` // Number of course admins copied
+}
+
+// CloneCourse deep-copies a course and all of its stories' content into a new course.
+// Audio and image records of the copy point at the same stored files as the original,
+// which are only removed from storage once no story uses them. Students, their answers
+// and scores are not copied.
+func (s *Service) CloneCourse(ctx context.Context, courseID int32, opts CourseCloneOptions) (*CourseClone, error) {
+	if opts.CourseNumber == "" {
+		return nil, ErrMissingCourseNumber
+	}
+	source, err := s.GetCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetCourseByNumber(ctx, opts.CourseNumber); err == nil {
+		return nil, ErrCourseNumberTaken
+	} else if err != ErrNotFound {
+		return nil, err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = source.Name
+	}
+	description := source.Description
+	if opts.Description != nil {
+		description = *opts.Description
+	}
+
+	var clone CourseClone
+	err = s.withTransaction(ctx, func(txCtx context.Context) error {
+		course, err := s.queries.CreateCourse(txCtx, db.CreateCourseParams{
+			CourseNumber: opts.CourseNumber,
+			Name:         name,
+			Description:  pgtype.Text{String: description, Valid: description != ""},
+		})
+		if err != nil {
+			return err
+		}
+		clone.Course = Course{
+			CourseID:     course.CourseID,
+			CourseNumber: course.CourseNumber,
+			Name:         course.Name,
+			Description:  course.Description.String,
+			CreatedAt:    course.CreatedAt.Time,
+			UpdatedAt:    course.UpdatedAt.Time,
+		}
+
+		flow, err := s.queries.GetCoursePhaseFlow(txCtx, courseID)
+		if err == nil {
+			if _, err := s.queries.UpsertCoursePhaseFlow(txCtx, db.UpsertCoursePhaseFlowParams{
+				CourseID: course.CourseID,
+				Phases:   flow.Phases,
+			}); err != nil {
+				return err
+			}
+		} else if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+			return err
+		}
+
+		if opts.CopyAdmins {
+			admins, err := s.queries.GetCourseAdmins(txCtx, courseID)
+			if err != nil {
+				return err
+			}
+			for _, admin := range admins {
+				if _, err := s.queries.AddCourseAdmin(txCtx, db.AddCourseAdminParams{
+					CourseID: course.CourseID,
+					UserID:   admin.UserID,
+				}); err != nil {
+					return err
+				}
+			}
+			clone.Admins = len(admins)
+		}
+
+		stories, err := s.queries.GetStoriesByCourse(txCtx, pgtype.Int4{Int32: courseID, Valid: true})
+		if err != nil {
+			return err
+		}
+		clone.Stories = make([]ClonedStory, 0, len(stories))
+		for _, story := range stories {
+			storyID, err := s.cloneStory(txCtx, story, course.CourseID)
+			if err != nil {
+				return fmt.Errorf("failed to clone story %d: %w", story.StoryID, err)
+			}
+			if err := s.recordStoryRevision(txCtx, int(storyID), fmt.Sprintf("clone of story %d", story.StoryID)); err != nil {
+				return err
+			}
+			clone.Stories = append(clone.Stories, ClonedStory{
+				SourceStoryID: int(story.StoryID),
+				StoryID:       int(storyID),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Cloned course %d into course %d with %d stories\n", courseID, clone.Course.CourseID, len(clone.Stories))
+	return &clone, nil
+}
+
+// cloneStory copies a story and its content into a course, returning the new story ID.
+// It must run inside a transaction.
+func (s *Service) cloneStory(ctx context.Context, source db.Story, courseID int32) (int32, error) {
+	created, err := s.queries.CreateStory(ctx, db.CreateStoryParams{
+		WeekNumber: source.WeekNumber,
+		DayLetter:  source.DayLetter,
+		VideoUrl:   source.VideoUrl,
+		AuthorID:   source.AuthorID,
+		AuthorName: source.AuthorName,
+		CourseID:   pgtype.Int4{Int32: courseID, Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	from, to := source.StoryID, created.StoryID
+
+	if err := s.cloneStoryText(ctx, from, to); err != nil {
+		return 0, err
+	}
+	grammarPointIDs, err := s.cloneStoryGrammarPoints(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.cloneStoryAnnotations(ctx, from, to, grammarPointIDs); err != nil {
+		return 0, err
+	}
+	imageIDs, targetVocabIDs, err := s.cloneStoryAssets(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.cloneStoryActivities(ctx, from, to, grammarPointIDs, imageIDs, targetVocabIDs); err != nil {
+		return 0, err
+	}
+	if err := s.cloneStorySettings(ctx, from, to); err != nil {
+		return 0, err
+	}
+	return to, nil
+}
+
+// cloneStoryText copies titles, descriptions, lines and line translations
+func (s *Service) cloneStoryText(ctx context.Context, from, to int32) error {
+	titles, err := s.queries.GetStoryTitles(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, title := range titles {
+		if err := s.queries.UpsertStoryTitle(ctx, db.UpsertStoryTitleParams{
+			StoryID:      to,
+			LanguageCode: title.LanguageCode,
+			Title:        title.Title,
+		}); err != nil {
+			return err
+		}
+	}
+
+	descriptions, err := s.queries.GetStoryDescriptions(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, description := range descriptions {
+		if err := s.queries.UpsertStoryDescription(ctx, db.UpsertStoryDescriptionParams{
+			StoryID:         to,
+			LanguageCode:    description.LanguageCode,
+			DescriptionText: description.DescriptionText,
+		}); err != nil {
+			return err
+		}
+	}
+
+	lines, err := s.queries.GetStoryLines(ctx, from)
+	if err != nil {
+		return err
+	}
+	if len(lines) > 0 {
+		params := make([]db.BulkCreateStoryLinesParams, len(lines))
+		for i, line := range lines {
+			params[i] = db.BulkCreateStoryLinesParams{StoryID: to, LineNumber: line.LineNumber, Text: line.Text}
+		}
+		if _, err := s.queries.BulkCreateStoryLines(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	translations, err := s.queries.GetAllTranslationsForStory(ctx, from)
+	if err != nil {
+		return err
+	}
+	if len(translations) > 0 {
+		params := make([]db.BulkCreateLineTranslationsParams, len(translations))
+		for i, translation := range translations {
+			params[i] = db.BulkCreateLineTranslationsParams{
+				StoryID:         to,
+				LineNumber:      translation.LineNumber,
+				LanguageCode:    translation.LanguageCode,
+				TranslationText: translation.TranslationText,
+			}
+		}
+		if _, err := s.queries.BulkCreateLineTranslations(ctx, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneStoryGrammarPoints copies the grammar points of a story, returning new IDs by old ID
+func (s *Service) cloneStoryGrammarPoints(ctx context.Context, from, to int32) (map[int32]int32, error) {
+	points, err := s.queries.GetStoryGrammarPoints(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int32]int32, len(points))
+	for _, point := range points {
+		created, err := s.queries.CreateGrammarPoint(ctx, db.CreateGrammarPointParams{
+			StoryID:     to,
+			Name:        point.Name,
+			Description: point.Description,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ids[point.GrammarPointID] = created.GrammarPointID
+	}
+	return ids, nil
+}
+
+// cloneStoryAnnotations copies vocabulary and grammar items and footnotes
+func (s *Service) cloneStoryAnnotations(ctx context.Context, from, to int32, grammarPointIDs map[int32]int32) error {
+	fromID := pgtype.Int4{Int32: from, Valid: true}
+	toID := pgtype.Int4{Int32: to, Valid: true}
+
+	vocab, err := s.queries.GetAllVocabularyForStory(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	if len(vocab) > 0 {
+		params := make([]db.BulkCreateVocabularyItemsParams, len(vocab))
+		for i, item := range vocab {
+			params[i] = db.BulkCreateVocabularyItemsParams{
+				StoryID:       toID,
+				LineNumber:    item.LineNumber,
+				Word:          item.Word,
+				LexicalForm:   item.LexicalForm,
+				PositionStart: item.PositionStart,
+				PositionEnd:   item.PositionEnd,
+			}
+		}
+		if _, err := s.queries.BulkCreateVocabularyItems(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	grammar, err := s.queries.GetAllGrammarForStory(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	if len(grammar) > 0 {
+		params := make([]db.BulkCreateGrammarItemsParams, len(grammar))
+		for i, item := range grammar {
+			params[i] = db.BulkCreateGrammarItemsParams{
+				StoryID:        toID,
+				LineNumber:     item.LineNumber,
+				GrammarPointID: remapID(item.GrammarPointID, grammarPointIDs),
+				Text:           item.Text,
+				PositionStart:  item.PositionStart,
+				PositionEnd:    item.PositionEnd,
+			}
+		}
+		if _, err := s.queries.BulkCreateGrammarItems(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	footnotes, err := s.queries.GetAllFootnotesForStory(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	for _, footnote := range footnotes {
+		references, err := s.queries.GetFootnoteReferences(ctx, footnote.ID)
+		if err != nil {
+			return err
+		}
+		footnoteID, err := s.queries.CreateFootnote(ctx, db.CreateFootnoteParams{
+			StoryID:      toID,
+			LineNumber:   footnote.LineNumber,
+			FootnoteText: footnote.FootnoteText,
+		})
+		if err != nil {
+			return err
+		}
+		for _, reference := range references {
+			if err := s.queries.CreateFootnoteReference(ctx, db.CreateFootnoteReferenceParams{
+				FootnoteID: footnoteID,
+				Reference:  reference,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cloneStoryAssets copies line audio, image and target vocabulary records, keeping their
+// storage paths. It returns the new image and target vocabulary IDs by old ID.
+func (s *Service) cloneStoryAssets(ctx context.Context, from, to int32) (map[int32]int32, map[int32]int32, error) {
+	audioFiles, err := s.queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: from, Valid: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(audioFiles) > 0 {
+		params := make([]db.BulkCreateAudioFilesParams, len(audioFiles))
+		for i, audioFile := range audioFiles {
+			params[i] = db.BulkCreateAudioFilesParams{
+				StoryID:    pgtype.Int4{Int32: to, Valid: true},
+				LineNumber: audioFile.LineNumber,
+				FilePath:   audioFile.FilePath,
+				FileBucket: audioFile.FileBucket,
+				Label:      audioFile.Label,
+			}
+		}
+		if _, err := s.queries.BulkCreateAudioFiles(ctx, params); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	images, err := s.queries.GetStoryImages(ctx, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	imageIDs := make(map[int32]int32, len(images))
+	for _, image := range images {
+		created, err := s.queries.CreateStoryImage(ctx, db.CreateStoryImageParams{
+			StoryID:     to,
+			FilePath:    image.FilePath,
+			FileBucket:  image.FileBucket,
+			Label:       image.Label,
+			ContentType: image.ContentType,
+			SizeBytes:   image.SizeBytes,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		imageIDs[image.ImageID] = created.ImageID
+	}
+
+	words, err := s.queries.GetStoryTargetVocab(ctx, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetVocabIDs := make(map[int32]int32, len(words))
+	for _, word := range words {
+		created, err := s.queries.CreateTargetVocab(ctx, db.CreateTargetVocabParams{
+			StoryID:     to,
+			LexicalForm: word.LexicalForm,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if word.AudioPath.Valid {
+			if _, err := s.queries.SetTargetVocabAudio(ctx, db.SetTargetVocabAudioParams{
+				ID:          created.ID,
+				StoryID:     to,
+				AudioPath:   word.AudioPath,
+				AudioBucket: word.AudioBucket,
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
+		if word.ImagePath.Valid {
+			if _, err := s.queries.SetTargetVocabImage(ctx, db.SetTargetVocabImageParams{
+				ID:          created.ID,
+				StoryID:     to,
+				ImagePath:   word.ImagePath,
+				ImageBucket: word.ImageBucket,
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
+		targetVocabIDs[word.ID] = created.ID
+	}
+	return imageIDs, targetVocabIDs, nil
+}
+
+// cloneStoryActivities copies recall sentences, produce segments and the produce explanation
+func (s *Service) cloneStoryActivities(ctx context.Context, from, to int32, grammarPointIDs, imageIDs, targetVocabIDs map[int32]int32) error {
+	sentences, err := s.queries.GetStoryRecallSentences(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, sentence := range sentences {
+		if _, err := s.queries.CreateRecallSentence(ctx, db.CreateRecallSentenceParams{
+			StoryID:       to,
+			SequenceOrder: sentence.SequenceOrder,
+			SentenceText:  sentence.SentenceText,
+			TargetVocabID: remapID(sentence.TargetVocabID, targetVocabIDs),
+			ImageID:       remapID(sentence.ImageID, imageIDs),
+		}); err != nil {
+			return err
+		}
+	}
+
+	segments, err := s.queries.GetStoryProduceSegments(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if _, err := s.queries.CreateProduceSegment(ctx, db.CreateProduceSegmentParams{
+			StoryID:        to,
+			StartLine:      segment.StartLine,
+			EndLine:        segment.EndLine,
+			EnglishPrompt:  segment.EnglishPrompt,
+			ReferenceText:  segment.ReferenceText,
+			GrammarPointID: remapID(segment.GrammarPointID, grammarPointIDs),
+		}); err != nil {
+			return err
+		}
+	}
+
+	explanation, err := s.queries.GetProduceExplanation(ctx, from)
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.queries.UpsertProduceExplanation(ctx, db.UpsertProduceExplanationParams{
+		StoryID:     to,
+		Explanation: explanation.Explanation,
+	})
+	return err
+}
+
+// cloneStorySettings copies the story's phase flow, vocab contrast pairs and release schedule
+func (s *Service) cloneStorySettings(ctx context.Context, from, to int32) error {
+	flow, err := s.queries.GetStoryPhaseFlow(ctx, from)
+	if err == nil {
+		if _, err := s.queries.UpsertStoryPhaseFlow(ctx, db.UpsertStoryPhaseFlowParams{
+			StoryID: to,
+			Phases:  flow.Phases,
+		}); err != nil {
+			return err
+		}
+	} else if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return err
+	}
+
+	pairs, err := s.queries.GetStoryVocabContrasts(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		if _, err := s.queries.CreateVocabContrast(ctx, db.CreateVocabContrastParams{
+			StoryID: to,
+			FormA:   pair.FormA,
+			FormB:   pair.FormB,
+		}); err != nil {
+			return err
+		}
+	}
+
+	schedule, err := s.queries.GetStorySchedule(ctx, from)
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.queries.UpsertStorySchedule(ctx, db.UpsertStoryScheduleParams{
+		StoryID:       to,
+		AvailableFrom: schedule.AvailableFrom,
+		DueAt:         schedule.DueAt,
+		LateUntil:     schedule.LateUntil,
+	})
+	return err
+}
+
+// remapID maps a reference to a copied record onto the copy's ID. References to
+// records that were deleted, or that were not copied, stay unset.
+func remapID(id pgtype.Int4, ids map[int32]int32) pgtype.Int4 {
+	if !id.Valid {
+		return id
+	}
+	mapped, ok := ids[id.Int32]
+	return pgtype.Int4{Int32: mapped, Valid: ok}
+}
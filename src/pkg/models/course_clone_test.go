@@ -0,0 +1,73 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/database"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCloneCourseValidation(t *testing.T) {
+	ctx := context.Background()
+	course := []interface{}{int32(3), "HEB101", "Biblical Hebrew", pgtype.Text{}, pgtype.Timestamp{}, pgtype.Timestamp{}, pgtype.Timestamp{}}
+
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	if _, err := svc.CloneCourse(ctx, 3, CourseCloneOptions{}); err != ErrMissingCourseNumber {
+		t.Errorf("Expected ErrMissingCourseNumber without a course number, got %v", err)
+	}
+	if _, err := svc.CloneCourse(ctx, 3, CourseCloneOptions{CourseNumber: "HEB101-F26"}); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a missing course, got %v", err)
+	}
+
+	mock := database.NewMockDBTX()
+	mock.StubQuery("WHERE course_id = $1", [][]interface{}{course}, nil)
+	mock.StubQuery("WHERE course_number = $1", [][]interface{}{course}, nil)
+	svc = NewService(mock, nil, nil, nil)
+	if _, err := svc.CloneCourse(ctx, 3, CourseCloneOptions{CourseNumber: "HEB101"}); err != ErrCourseNumberTaken {
+		t.Errorf("Expected ErrCourseNumberTaken for a used course number, got %v", err)
+	}
+}
+
+func TestRemapID(t *testing.T) {
+	ids := map[int32]int32{4: 40}
+	tests := []struct {
+		id, expected pgtype.Int4
+	}{
+		{pgtype.Int4{Int32: 4, Valid: true}, pgtype.Int4{Int32: 40, Valid: true}},
+		{pgtype.Int4{Int32: 5, Valid: true}, pgtype.Int4{}}, // Not copied
+		{pgtype.Int4{}, pgtype.Int4{}},
+	}
+	for _, test := range tests {
+		if got := remapID(test.id, ids); got != test.expected {
+			t.Errorf("remapID(%v) = %v, expected %v", test.id, got, test.expected)
+		}
+	}
+}
+
+func TestStoryStorageObjectsSkipsSharedFiles(t *testing.T) {
+	audio := []interface{}{
+		int32(1), pgtype.Int4{Int32: 7, Valid: true}, pgtype.Int4{Int32: 1, Valid: true},
+		"7/line1.mp3", "audio-files", "complete", pgtype.Timestamp{},
+	}
+	for _, test := range []struct {
+		uses     int64
+		expected int
+	}{
+		{0, 1},
+		{1, 0}, // Still used by a clone
+	} {
+		mock := database.NewMockDBTX()
+		mock.StubQuery("ORDER BY line_number, label, created_at", [][]interface{}{audio}, nil)
+		mock.StubQuery("AS uses", [][]interface{}{{test.uses}}, nil)
+		svc := NewService(mock, nil, nil, nil)
+
+		objects, err := svc.storyStorageObjects(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("storyStorageObjects failed: %v", err)
+		}
+		if got := len(objects["audio-files"]); got != test.expected {
+			t.Errorf("With %d other uses expected %d files to remove, got %d", test.uses, test.expected, got)
+		}
+	}
+}
@@ -11,8 +11,8 @@ import (
 )
 
 // GetStoryCourseID retrieves the course ID for a given story
-func GetStoryCourseID(ctx context.Context, storyID int32) (int32, error) {
-	story, err := queries.GetStory(ctx, storyID)
+func (s *Service) GetStoryCourseID(ctx context.Context, storyID int32) (int32, error) {
+	story, err := s.queries.GetStory(ctx, storyID)
 	if err != nil {
 		return 0, err
 	}
@@ -90,18 +90,18 @@ func CalculateScoreWithRetriesAllowed(correctCount, incorrectCount, totalPossibl
 
 // GetStoryStudentPerformance retrieves performance data for all students in a specific story
 // status parameter filters by course status: "active", "future", "past", or "" for all
-func GetStoryStudentPerformance(ctx context.Context, storyID int32, status string) ([]CourseStudentPerformance, error) {
+func (s *Service) GetStoryStudentPerformance(ctx context.Context, storyID int32, status string) ([]CourseStudentPerformance, error) {
 	// Get total vocab and grammar items for this story
-	totalVocab, err := queries.CountStoryVocabItems(ctx, pgtype.Int4{Int32: storyID, Valid: true})
+	totalVocab, err := s.queries.CountStoryVocabItems(ctx, pgtype.Int4{Int32: storyID, Valid: true})
 	if err != nil {
 		return nil, err
 	}
-	totalGrammar, err := queries.CountStoryGrammarItems(ctx, pgtype.Int4{Int32: storyID, Valid: true})
+	totalGrammar, err := s.queries.CountStoryGrammarItems(ctx, pgtype.Int4{Int32: storyID, Valid: true})
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := queries.GetStoryStudentPerformance(ctx, db.GetStoryStudentPerformanceParams{
+	rows, err := s.queries.GetStoryStudentPerformance(ctx, db.GetStoryStudentPerformanceParams{
 		StoryID: storyID,
 		Column2: status,
 	})
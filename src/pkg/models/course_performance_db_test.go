@@ -26,6 +26,7 @@ func TestGetStoryCourseID_Success(t *testing.T) {
 		"author-123",                          // AuthorID
 		"Jane Doe",                            // AuthorName
 		pgtype.Int4{Int32: expectedCourseID, Valid: true}, // CourseID
+		pgtype.Timestamp{Valid: false},                    // DeletedAt
 	}
 	mockDBTX.StubQuery("SELECT s.story_id", [][]interface{}{mockRow}, nil)
 
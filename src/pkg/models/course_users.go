@@ -39,14 +39,14 @@ type UserCourse struct {
 }
 
 // AddUserToCourseByEmail adds a user to a course by email address
-func AddUserToCourseByEmail(ctx context.Context, email string, courseID int) error {
-	return AddUserToCourseByEmailWithStatus(ctx, email, courseID, "active")
+func (s *Service) AddUserToCourseByEmail(ctx context.Context, email string, courseID int) error {
+	return s.AddUserToCourseByEmailWithStatus(ctx, email, courseID, "active")
 }
 
 // AddUserToCourseByEmailWithStatus adds a user to a course with a specific status
-func AddUserToCourseByEmailWithStatus(ctx context.Context, email string, courseID int, status string) error {
+func (s *Service) AddUserToCourseByEmailWithStatus(ctx context.Context, email string, courseID int, status string) error {
 	// First get the user by email
-	user, err := queries.GetUserByEmail(ctx, email)
+	user, err := s.queries.GetUserByEmail(ctx, email)
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return ErrNotFound
 	}
@@ -63,49 +63,49 @@ func AddUserToCourseByEmailWithStatus(ctx context.Context, email string, courseI
 		status = "active"
 	}
 
-	return queries.AddUserToCourse(ctx, db.AddUserToCourseParams{
+	return s.enrollmentChanged(courseID, s.queries.AddUserToCourse(ctx, db.AddUserToCourseParams{
 		CourseID: int32(courseID),
 		UserID:   user.UserID,
 		Column3:  status,
-	})
+	}))
 }
 
 // RemoveUserFromCourse removes a user from a course
-func RemoveUserFromCourse(ctx context.Context, courseID int, userID string) error {
-	return queries.RemoveUserFromCourse(ctx, db.RemoveUserFromCourseParams{
+func (s *Service) RemoveUserFromCourse(ctx context.Context, courseID int, userID string) error {
+	return s.enrollmentChanged(courseID, s.queries.RemoveUserFromCourse(ctx, db.RemoveUserFromCourseParams{
 		CourseID: int32(courseID),
 		UserID:   userID,
-	})
+	}))
 }
 
 // UpdateCourseUserStatus updates the status of a user's enrollment in a course
-func UpdateCourseUserStatus(ctx context.Context, courseID int, userID string, status string) error {
-	return queries.UpdateCourseUserStatus(ctx, db.UpdateCourseUserStatusParams{
+func (s *Service) UpdateCourseUserStatus(ctx context.Context, courseID int, userID string, status string) error {
+	return s.enrollmentChanged(courseID, s.queries.UpdateCourseUserStatus(ctx, db.UpdateCourseUserStatusParams{
 		CourseID: int32(courseID),
 		UserID:   userID,
 		Status:   pgtype.Text{String: status, Valid: status != ""},
-	})
+	}))
 }
 
-func BulkUpdateUserStatusInCourse(ctx context.Context, courseID int, userIDs []string, status string) error {
+func (s *Service) BulkUpdateUserStatusInCourse(ctx context.Context, courseID int, userIDs []string, status string) error {
 	if status != "active" && status != "past" && status != "future" {
 		return ErrInvalidStatus
 	}
-	return queries.BulkUpdateCourseUserStatus(ctx, db.BulkUpdateCourseUserStatusParams{
+	return s.enrollmentChanged(courseID, s.queries.BulkUpdateCourseUserStatus(ctx, db.BulkUpdateCourseUserStatusParams{
 		CourseID: int32(courseID),
 		Status:   pgtype.Text{String: status, Valid: true},
 		Column2:  userIDs,
-	})
+	}))
 }
 
 // DeleteAllUsersFromCourse removes all users from a course
-func DeleteAllUsersFromCourse(ctx context.Context, courseID int) error {
-	return queries.DeleteAllUsersFromCourse(ctx, int32(courseID))
+func (s *Service) DeleteAllUsersFromCourse(ctx context.Context, courseID int) error {
+	return s.enrollmentChanged(courseID, s.queries.DeleteAllUsersFromCourse(ctx, int32(courseID)))
 }
 
 // GetCoursesForUser returns all courses a user is enrolled in
-func GetCoursesForUser(ctx context.Context, userID string) ([]UserCourse, error) {
-	results, err := queries.GetCoursesForUser(ctx, userID)
+func (s *Service) GetCoursesForUser(ctx context.Context, userID string) ([]UserCourse, error) {
+	results, err := s.queries.GetCoursesForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -133,8 +133,8 @@ func GetCoursesForUser(ctx context.Context, userID string) ([]UserCourse, error)
 }
 
 // GetCoursesForUserByStatus returns courses for a user filtered by status
-func GetCoursesForUserByStatus(ctx context.Context, userID string, status string) ([]UserCourse, error) {
-	results, err := queries.GetCoursesForUserByStatus(ctx, db.GetCoursesForUserByStatusParams{
+func (s *Service) GetCoursesForUserByStatus(ctx context.Context, userID string, status string) ([]UserCourse, error) {
+	results, err := s.queries.GetCoursesForUserByStatus(ctx, db.GetCoursesForUserByStatusParams{
 		UserID: userID,
 		Status: pgtype.Text{String: status, Valid: status != ""},
 	})
@@ -161,8 +161,8 @@ func GetCoursesForUserByStatus(ctx context.Context, userID string, status string
 }
 
 // GetUsersForCourse returns all users enrolled in a course
-func GetUsersForCourse(ctx context.Context, courseID int) ([]CourseUser, error) {
-	results, err := queries.GetUsersForCourse(ctx, int32(courseID))
+func (s *Service) GetUsersForCourse(ctx context.Context, courseID int) ([]CourseUser, error) {
+	results, err := s.queries.GetUsersForCourse(ctx, int32(courseID))
 	if err != nil {
 		return nil, err
 	}
@@ -187,10 +187,10 @@ func GetUsersForCourse(ctx context.Context, courseID int) ([]CourseUser, error)
 }
 
 // MassImportUsersToCourse enrolls a list of users in a course
-func MassImportUsersToCourse(ctx context.Context, courseID int, userEmails []string) ([]string, error) {
+func (s *Service) MassImportUsersToCourse(ctx context.Context, courseID int, userEmails []string) ([]string, error) {
 	// First get user IDs for the emails
 	// One mass query to get all users by email
-	users, err := queries.GetUsersByEmails(ctx, userEmails)
+	users, err := s.queries.GetUsersByEmails(ctx, userEmails)
 	if err != nil {
 		return nil, err
 	}
@@ -221,8 +221,8 @@ func MassImportUsersToCourse(ctx context.Context, courseID int, userEmails []str
 	}
 
 	// Attempt to enroll all users; the SQL query uses ON CONFLICT DO NOTHING to skip users already enrolled.
-	return nil, queries.AddMultiUsersToCourse(ctx, db.AddMultiUsersToCourseParams{
+	return nil, s.enrollmentChanged(courseID, s.queries.AddMultiUsersToCourse(ctx, db.AddMultiUsersToCourseParams{
 		CourseID: int32(courseID),
 		Column2:  userIDs,
-	})
+	}))
 }
@@ -31,8 +31,8 @@ type CourseAdmin struct {
 }
 
 // CreateCourse creates a new course
-func CreateCourse(ctx context.Context, courseNumber, name, description string) (*Course, error) {
-	result, err := queries.CreateCourse(ctx, db.CreateCourseParams{
+func (s *Service) CreateCourse(ctx context.Context, courseNumber, name, description string) (*Course, error) {
+	result, err := s.queries.CreateCourse(ctx, db.CreateCourseParams{
 		CourseNumber: courseNumber,
 		Name:         name,
 		Description:  pgtype.Text{String: description, Valid: description != ""},
@@ -52,8 +52,8 @@ func CreateCourse(ctx context.Context, courseNumber, name, description string) (
 }
 
 // GetCourse retrieves a course by ID
-func GetCourse(ctx context.Context, courseID int32) (*Course, error) {
-	result, err := queries.GetCourse(ctx, courseID)
+func (s *Service) GetCourse(ctx context.Context, courseID int32) (*Course, error) {
+	result, err := s.queries.GetCourse(ctx, courseID)
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -71,9 +71,10 @@ func GetCourse(ctx context.Context, courseID int32) (*Course, error) {
 	}, nil
 }
 
-// GetCourseByNumber retrieves a course by course number
-func GetCourseByNumber(ctx context.Context, courseNumber string) (*Course, error) {
-	result, err := queries.GetCourseByNumber(ctx, courseNumber)
+// GetCourseByNumber retrieves a course by course number. Courses in the trash are
+// left out: they give up their numbers, which a restore needs back.
+func (s *Service) GetCourseByNumber(ctx context.Context, courseNumber string) (*Course, error) {
+	result, err := s.queries.GetCourseByNumber(ctx, courseNumber)
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -92,8 +93,8 @@ func GetCourseByNumber(ctx context.Context, courseNumber string) (*Course, error
 }
 
 // ListAllCourses returns all courses
-func ListAllCourses(ctx context.Context) ([]Course, error) {
-	results, err := queries.ListCourses(ctx)
+func (s *Service) ListAllCourses(ctx context.Context) ([]Course, error) {
+	results, err := s.queries.ListCourses(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -114,8 +115,8 @@ func ListAllCourses(ctx context.Context) ([]Course, error) {
 }
 
 // UpdateCourse updates an existing course
-func UpdateCourse(ctx context.Context, courseID int32, courseNumber, name, description string) (*Course, error) {
-	result, err := queries.UpdateCourse(ctx, db.UpdateCourseParams{
+func (s *Service) UpdateCourse(ctx context.Context, courseID int32, courseNumber, name, description string) (*Course, error) {
+	result, err := s.queries.UpdateCourse(ctx, db.UpdateCourseParams{
 		CourseID:     courseID,
 		CourseNumber: courseNumber,
 		Name:         name,
@@ -138,18 +139,39 @@ func UpdateCourse(ctx context.Context, courseID int32, courseNumber, name, descr
 	}, nil
 }
 
-// DeleteCourse deletes a course
-func DeleteCourse(ctx context.Context, courseID int32) error {
-	err := queries.DeleteCourse(ctx, courseID)
-	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
-		return ErrNotFound
+// DeleteCourse moves a course and its stories to the trash. RestoreCourse brings them
+// back together; otherwise they are purged once the trash retention period is over.
+func (s *Service) DeleteCourse(ctx context.Context, courseID int32) error {
+	deletedAt := pgtype.Timestamp{Time: s.clock.Now().UTC(), Valid: true}
+	var storyIDs []int32
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		trashed, err := s.queries.TrashCourse(txCtx, db.TrashCourseParams{CourseID: courseID, DeletedAt: deletedAt})
+		if err != nil {
+			return err
+		}
+		if trashed == 0 {
+			return ErrNotFound
+		}
+		storyIDs, err = s.queries.TrashCourseStories(txCtx, db.TrashCourseStoriesParams{
+			CourseID:  pgtype.Int4{Int32: courseID, Valid: true},
+			DeletedAt: deletedAt,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, storyID := range storyIDs {
+		s.InvalidateStoryMetadata(int(storyID))
 	}
-	return err
+	s.InvalidateCourseAnalytics(int(courseID))
+	return nil
 }
 
 // GetCourseAdmins returns all admins for a specific course
-func GetCourseAdmins(ctx context.Context, courseID int32) ([]CourseAdmin, error) {
-	results, err := queries.GetCourseAdmins(ctx, courseID)
+func (s *Service) GetCourseAdmins(ctx context.Context, courseID int32) ([]CourseAdmin, error) {
+	results, err := s.queries.GetCourseAdmins(ctx, courseID)
 	if err != nil {
 		return nil, err
 	}
@@ -169,8 +191,8 @@ func GetCourseAdmins(ctx context.Context, courseID int32) ([]CourseAdmin, error)
 }
 
 // AddCourseAdmin adds a user as admin to a course
-func AddCourseAdmin(ctx context.Context, courseID int32, userID string) (*CourseAdmin, error) {
-	result, err := queries.AddCourseAdmin(ctx, db.AddCourseAdminParams{
+func (s *Service) AddCourseAdmin(ctx context.Context, courseID int32, userID string) (*CourseAdmin, error) {
+	result, err := s.queries.AddCourseAdmin(ctx, db.AddCourseAdminParams{
 		CourseID: courseID,
 		UserID:   userID,
 	})
@@ -179,7 +201,7 @@ func AddCourseAdmin(ctx context.Context, courseID int32, userID string) (*Course
 	}
 
 	// Get user details for the response
-	user, err := GetUser(ctx, userID)
+	user, err := s.GetUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -194,22 +216,22 @@ func AddCourseAdmin(ctx context.Context, courseID int32, userID string) (*Course
 }
 
 // RemoveCourseAdmin removes a user as admin from a course
-func RemoveCourseAdmin(ctx context.Context, courseID int32, userID string) error {
-	return queries.RemoveCourseAdmin(ctx, db.RemoveCourseAdminParams{
+func (s *Service) RemoveCourseAdmin(ctx context.Context, courseID int32, userID string) error {
+	return s.queries.RemoveCourseAdmin(ctx, db.RemoveCourseAdminParams{
 		CourseID: courseID,
 		UserID:   userID,
 	})
 }
 
 // IsUserSuperAdmin checks if a user is a super admin
-func IsUserSuperAdmin(ctx context.Context, userID string) bool {
-	user, err := queries.GetUser(ctx, userID)
+func (s *Service) IsUserSuperAdmin(ctx context.Context, userID string) bool {
+	user, err := s.queries.GetUser(ctx, userID)
 	return err == nil && user.IsSuperAdmin.Bool
 }
 
 // GetAdminCoursesForUser returns all courses a user is admin of
-func GetAdminCoursesForUser(ctx context.Context, userID string) ([]Course, error) {
-	results, err := queries.GetAdminCoursesForUser(ctx, userID)
+func (s *Service) GetAdminCoursesForUser(ctx context.Context, userID string) ([]Course, error) {
+	results, err := s.queries.GetAdminCoursesForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
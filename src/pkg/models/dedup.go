@@ -30,13 +30,13 @@ func SetDedupConfig(config DedupConfig) {
 }
 
 // dedupVocabularyInsert checks for existing vocabulary and returns existing ID or inserts new
-func dedupVocabularyInsert(ctx context.Context, storyID, lineNumber int, vocab VocabularyItem) error {
+func (s *Service) dedupVocabularyInsert(ctx context.Context, storyID, lineNumber int, vocab VocabularyItem) error {
 	if !dedupConfig.EnableVocabulary {
-		return insertVocabulary(ctx, storyID, lineNumber, vocab)
+		return s.insertVocabulary(ctx, storyID, lineNumber, vocab)
 	}
 
 	// Check if vocabulary item exists using SQLC
-	exists, err := queries.CheckVocabularyExists(ctx, db.CheckVocabularyExistsParams{
+	exists, err := s.queries.CheckVocabularyExists(ctx, db.CheckVocabularyExistsParams{
 		StoryID:       pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber:    pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		Word:          vocab.Word,
@@ -52,17 +52,17 @@ func dedupVocabularyInsert(ctx context.Context, storyID, lineNumber int, vocab V
 	if exists {
 		return errExists
 	}
-	return insertVocabulary(ctx, storyID, lineNumber, vocab)
+	return s.insertVocabulary(ctx, storyID, lineNumber, vocab)
 }
 
 // dedupGrammarInsert checks for existing grammar and returns existing ID or inserts new
-func dedupGrammarInsert(ctx context.Context, storyID, lineNumber int, grammar GrammarItem) error {
+func (s *Service) dedupGrammarInsert(ctx context.Context, storyID, lineNumber int, grammar GrammarItem) error {
 	if !dedupConfig.EnableGrammar {
-		return insertGrammar(ctx, storyID, lineNumber, grammar)
+		return s.insertGrammar(ctx, storyID, lineNumber, grammar)
 	}
 
 	// Check if grammar item exists using SQLC
-	exists, err := queries.CheckGrammarExists(ctx, db.CheckGrammarExistsParams{
+	exists, err := s.queries.CheckGrammarExists(ctx, db.CheckGrammarExistsParams{
 		StoryID:       pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber:    pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		Text:          grammar.Text,
@@ -77,23 +77,23 @@ func dedupGrammarInsert(ctx context.Context, storyID, lineNumber int, grammar Gr
 	if exists {
 		return errExists
 	}
-	return insertGrammar(ctx, storyID, lineNumber, grammar)
+	return s.insertGrammar(ctx, storyID, lineNumber, grammar)
 }
 
 // dedupFootnoteInsert checks for existing footnote and returns existing ID or inserts new
-func dedupFootnoteInsert(ctx context.Context, storyID, lineNumber int, footnote Footnote) error {
+func (s *Service) dedupFootnoteInsert(ctx context.Context, storyID, lineNumber int, footnote Footnote) error {
 	if !dedupConfig.EnableFootnotes {
-		return insertFootnote(ctx, storyID, lineNumber, footnote)
+		return s.insertFootnote(ctx, storyID, lineNumber, footnote)
 	}
 
-	existingID, err := queries.CheckFootnoteExists(ctx, db.CheckFootnoteExistsParams{
+	existingID, err := s.queries.CheckFootnoteExists(ctx, db.CheckFootnoteExistsParams{
 		StoryID:      pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber:   pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		FootnoteText: footnote.Text,
 	})
 
 	if existingID == 0 {
-		return insertFootnote(ctx, storyID, lineNumber, footnote)
+		return s.insertFootnote(ctx, storyID, lineNumber, footnote)
 	}
 	if err != nil {
 		return err
@@ -102,8 +102,8 @@ func dedupFootnoteInsert(ctx context.Context, storyID, lineNumber int, footnote
 }
 
 // Original insert functions using SQLC
-func insertVocabulary(ctx context.Context, storyID, lineNumber int, vocab VocabularyItem) error {
-	_, err := queries.CreateVocabularyItem(ctx, db.CreateVocabularyItemParams{
+func (s *Service) insertVocabulary(ctx context.Context, storyID, lineNumber int, vocab VocabularyItem) error {
+	_, err := s.queries.CreateVocabularyItem(ctx, db.CreateVocabularyItemParams{
 		StoryID:       pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber:    pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		Word:          vocab.Word,
@@ -114,12 +114,12 @@ func insertVocabulary(ctx context.Context, storyID, lineNumber int, vocab Vocabu
 	return err
 }
 
-func insertGrammar(ctx context.Context, storyID, lineNumber int, grammar GrammarItem) error {
+func (s *Service) insertGrammar(ctx context.Context, storyID, lineNumber int, grammar GrammarItem) error {
 	grammarPointID := pgtype.Int4{Valid: false}
 	if grammar.GrammarPointID != nil {
 		grammarPointID = pgtype.Int4{Int32: int32(*grammar.GrammarPointID), Valid: true}
 	}
-	_, err := queries.CreateGrammarItem(ctx, db.CreateGrammarItemParams{
+	_, err := s.queries.CreateGrammarItem(ctx, db.CreateGrammarItemParams{
 		StoryID:        pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber:     pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		GrammarPointID: grammarPointID,
@@ -130,8 +130,8 @@ func insertGrammar(ctx context.Context, storyID, lineNumber int, grammar Grammar
 	return err
 }
 
-func insertFootnote(ctx context.Context, storyID, lineNumber int, footnote Footnote) error {
-	result, err := queries.CreateFootnote(ctx, db.CreateFootnoteParams{
+func (s *Service) insertFootnote(ctx context.Context, storyID, lineNumber int, footnote Footnote) error {
+	result, err := s.queries.CreateFootnote(ctx, db.CreateFootnoteParams{
 		StoryID:      pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber:   pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		FootnoteText: footnote.Text,
@@ -141,7 +141,7 @@ func insertFootnote(ctx context.Context, storyID, lineNumber int, footnote Footn
 	}
 
 	for _, ref := range footnote.References {
-		err := queries.CreateFootnoteReference(ctx, db.CreateFootnoteReferenceParams{
+		err := s.queries.CreateFootnoteReference(ctx, db.CreateFootnoteReferenceParams{
 			FootnoteID: result,
 			Reference:  ref,
 		})
@@ -22,7 +22,7 @@ func TestDedupVocabularyInsert_Exists(t *testing.T) {
 		LexicalForm: "form",
 		Position:    [2]int{0, 4},
 	}
-	err := dedupVocabularyInsert(context.Background(), 1, 1, vocab)
+	err := defaultService.dedupVocabularyInsert(context.Background(), 1, 1, vocab)
 
 	if !errors.Is(err, errExists) {
 		t.Errorf("expected errExists, got %v", err)
@@ -46,7 +46,7 @@ func TestDedupVocabularyInsert_New(t *testing.T) {
 		LexicalForm: "form",
 		Position:    [2]int{0, 4},
 	}
-	err := dedupVocabularyInsert(context.Background(), 1, 1, vocab)
+	err := defaultService.dedupVocabularyInsert(context.Background(), 1, 1, vocab)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -67,7 +67,7 @@ func TestDedupGrammarInsert_Exists(t *testing.T) {
 		Text:     "grammar",
 		Position: [2]int{0, 7},
 	}
-	err := dedupGrammarInsert(context.Background(), 1, 1, grammar)
+	err := defaultService.dedupGrammarInsert(context.Background(), 1, 1, grammar)
 
 	if !errors.Is(err, errExists) {
 		t.Errorf("expected errExists, got %v", err)
@@ -90,7 +90,7 @@ func TestDedupGrammarInsert_New(t *testing.T) {
 		Text:     "grammar",
 		Position: [2]int{0, 7},
 	}
-	err := dedupGrammarInsert(context.Background(), 1, 1, grammar)
+	err := defaultService.dedupGrammarInsert(context.Background(), 1, 1, grammar)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -110,7 +110,7 @@ func TestDedupFootnoteInsert_Exists(t *testing.T) {
 	fn := Footnote{
 		Text: "footnote text",
 	}
-	err := dedupFootnoteInsert(context.Background(), 1, 1, fn)
+	err := defaultService.dedupFootnoteInsert(context.Background(), 1, 1, fn)
 
 	if !errors.Is(err, errExists) {
 		t.Errorf("expected errExists, got %v", err)
@@ -135,7 +135,7 @@ func TestDedupFootnoteInsert_New(t *testing.T) {
 		Text:       "footnote text",
 		References: []string{"ref1"},
 	}
-	err := dedupFootnoteInsert(context.Background(), 1, 1, fn)
+	err := defaultService.dedupFootnoteInsert(context.Background(), 1, 1, fn)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -3,90 +3,222 @@ package models
 
 import (
 	"context"
+	"fmt"
+
+	"glossias/src/pkg/generated/db"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// Delete removes a story and all its associated data from the database
-func Delete(ctx context.Context, storyID int) error {
-	// Verify story exists first
-	exists, err := queries.StoryExists(ctx, int32(storyID))
+// Delete moves a story to the trash. It is hidden from everyone until it is restored
+// with RestoreStory or purged once the trash retention period is over.
+func (s *Service) Delete(ctx context.Context, storyID int) error {
+	trashed, err := s.queries.TrashStory(ctx, db.TrashStoryParams{
+		StoryID:   int32(storyID),
+		DeletedAt: pgtype.Timestamp{Time: s.clock.Now().UTC(), Valid: true},
+	})
 	if err != nil {
 		return err
 	}
-	if !exists {
+	if trashed == 0 {
 		return ErrNotFound
 	}
 
-	return withTransaction(ctx, func(txCtx context.Context) error {
+	s.InvalidateStoryMetadata(storyID)
+	s.InvalidateStoryCourseAnalytics(ctx, storyID)
+	return nil
+}
+
+// purgeStory removes a story and all its associated data from the database, then its
+// files from storage
+func (s *Service) purgeStory(ctx context.Context, storyID int) error {
+	// Collect storage objects before their records are gone
+	objects, err := s.storyStorageObjects(ctx, storyID)
+	if err != nil {
+		return err
+	}
+
+	err = s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Delete in proper order to respect foreign key relationships
 		// Though CASCADE would handle this, we're explicit for control
-		if err := deleteFootnoteData(txCtx, storyID); err != nil {
+		if err := s.deleteFootnoteData(txCtx, storyID); err != nil {
+			return err
+		}
+
+		if err := s.deleteAnnotations(txCtx, storyID); err != nil {
+			return err
+		}
+
+		if err := s.deleteAudioFiles(txCtx, storyID); err != nil {
+			return err
+		}
+
+		if err := s.deleteStoryImages(txCtx, storyID); err != nil {
+			return err
+		}
+
+		if err := s.deleteProduceData(txCtx, storyID); err != nil {
 			return err
 		}
 
-		if err := deleteAnnotations(txCtx, storyID); err != nil {
+		if err := s.deleteRecallSentences(txCtx, storyID); err != nil {
 			return err
 		}
 
-		if err := deleteAudioFiles(txCtx, storyID); err != nil {
+		if err := s.deleteTargetVocab(txCtx, storyID); err != nil {
 			return err
 		}
 
-		if err := deleteStoryContent(txCtx, storyID); err != nil {
+		if err := s.deleteStoryContent(txCtx, storyID); err != nil {
 			return err
 		}
 
-		if err := deleteMetadata(txCtx, storyID); err != nil {
+		if err := s.deleteMetadata(txCtx, storyID); err != nil {
 			return err
 		}
 
-		if err := deleteStoryGrammarPoints(txCtx, storyID); err != nil {
+		if err := s.deleteStoryGrammarPoints(txCtx, storyID); err != nil {
 			return err
 		}
 
 		// Finally delete the story itself using SQLC
-		if err := queries.DeleteStory(txCtx, int32(storyID)); err != nil {
+		if err := s.queries.DeleteStory(txCtx, int32(storyID)); err != nil {
 			return err
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Only remove files once the records are committed, so a failed delete never
+	// leaves a story pointing at missing objects
+	s.removeStorageObjects(objects)
+	return nil
+}
+
+// storyStorageObjects lists every stored file a story owns, grouped by bucket.
+// Files another story also uses, e.g. after a course was cloned, are left out.
+func (s *Service) storyStorageObjects(ctx context.Context, storyID int) (map[string][]string, error) {
+	type object struct{ bucket, path string }
+	var candidates []object
+
+	audioFiles, err := s.GetAllStoryAudioFiles(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, audioFile := range audioFiles {
+		candidates = append(candidates, object{audioFile.FileBucket, audioFile.FilePath})
+	}
+
+	images, err := s.GetStoryImages(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		candidates = append(candidates, object{image.FileBucket, image.FilePath})
+	}
+
+	words, err := s.queries.GetStoryTargetVocab(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		if word.AudioPath.Valid {
+			candidates = append(candidates, object{word.AudioBucket.String, word.AudioPath.String})
+		}
+		if word.ImagePath.Valid {
+			candidates = append(candidates, object{word.ImageBucket.String, word.ImagePath.String})
+		}
+	}
+
+	objects := make(map[string][]string)
+	for _, candidate := range candidates {
+		shared, err := s.storageObjectShared(ctx, storyID, candidate.bucket, candidate.path)
+		if err != nil {
+			return nil, err
+		}
+		if !shared {
+			objects[candidate.bucket] = append(objects[candidate.bucket], candidate.path)
+		}
+	}
+	return objects, nil
+}
+
+// removeStorageObjects deletes files from object storage. Failures are logged and
+// skipped because the database records are already gone.
+func (s *Service) removeStorageObjects(objects map[string][]string) {
+	if len(objects) == 0 {
+		return
+	}
+	if s.storage == nil {
+		fmt.Println("Storage client not initialized - story files were not removed")
+		return
+	}
+	for bucket, paths := range objects {
+		if err := s.storage.Remove(bucket, paths); err != nil {
+			fmt.Printf("Failed to remove %d files from bucket %s: %v\n", len(paths), bucket, err)
+		}
+	}
 }
 
 // deleteFootnoteData removes footnotes and their references
-func deleteFootnoteData(ctx context.Context, storyID int) error {
+func (s *Service) deleteFootnoteData(ctx context.Context, storyID int) error {
 	// Due to CASCADE, we only need to delete footnotes
-	return queries.DeleteAllStoryAnnotations(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	return s.queries.DeleteAllStoryAnnotations(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 }
 
 // deleteAnnotations removes vocabulary and grammar items
-func deleteAnnotations(ctx context.Context, storyID int) error {
-	if err := queries.DeleteAllVocabularyForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
+func (s *Service) deleteAnnotations(ctx context.Context, storyID int) error {
+	if err := s.queries.DeleteAllVocabularyForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
 		return err
 	}
-	return queries.DeleteAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	return s.queries.DeleteAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 }
 
 // deleteStoryContent removes the story lines using SQLC
-func deleteStoryContent(ctx context.Context, storyID int) error {
-	return queries.DeleteAllStoryLines(ctx, int32(storyID))
+func (s *Service) deleteStoryContent(ctx context.Context, storyID int) error {
+	return s.queries.DeleteAllStoryLines(ctx, int32(storyID))
 }
 
 // deleteAudioFiles removes audio files using SQLC
-func deleteAudioFiles(ctx context.Context, storyID int) error {
-	return queries.DeleteStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+func (s *Service) deleteAudioFiles(ctx context.Context, storyID int) error {
+	return s.queries.DeleteStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+}
+
+// deleteStoryImages removes image records using SQLC
+func (s *Service) deleteStoryImages(ctx context.Context, storyID int) error {
+	return s.queries.DeleteStoryImages(ctx, int32(storyID))
+}
+
+// deleteProduceData removes produce segments, their submissions and the explanation using SQLC
+func (s *Service) deleteProduceData(ctx context.Context, storyID int) error {
+	if err := s.queries.DeleteStoryProduceSegments(ctx, int32(storyID)); err != nil {
+		return err
+	}
+	return s.queries.DeleteProduceExplanation(ctx, int32(storyID))
+}
+
+// deleteRecallSentences removes recall sentences and their answers using SQLC
+func (s *Service) deleteRecallSentences(ctx context.Context, storyID int) error {
+	return s.queries.DeleteStoryRecallSentences(ctx, int32(storyID))
+}
+
+// deleteTargetVocab removes target vocabulary records using SQLC
+func (s *Service) deleteTargetVocab(ctx context.Context, storyID int) error {
+	return s.queries.DeleteStoryTargetVocab(ctx, int32(storyID))
 }
 
 // deleteMetadata removes titles and descriptions using SQLC
-func deleteMetadata(ctx context.Context, storyID int) error {
-	if err := queries.DeleteStoryTitles(ctx, int32(storyID)); err != nil {
+func (s *Service) deleteMetadata(ctx context.Context, storyID int) error {
+	if err := s.queries.DeleteStoryTitles(ctx, int32(storyID)); err != nil {
 		return err
 	}
-	return queries.DeleteStoryDescriptions(ctx, int32(storyID))
+	return s.queries.DeleteStoryDescriptions(ctx, int32(storyID))
 }
 
 // deleteStoryGrammarPoints removes story grammar point associations
-func deleteStoryGrammarPoints(ctx context.Context, storyID int) error {
-	return queries.ClearStoryGrammarPoints(ctx, int32(storyID))
+func (s *Service) deleteStoryGrammarPoints(ctx context.Context, storyID int) error {
+	return s.queries.ClearStoryGrammarPoints(ctx, int32(storyID))
 }
@@ -4,6 +4,7 @@ package models
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"glossias/src/pkg/generated/db"
 
@@ -15,33 +16,34 @@ var (
 	ErrInvalidLineNumber = errors.New("invalid line number")
 )
 
-// EditStoryText updates only the text content of story lines
-func EditStoryText(ctx context.Context, storyID int, lines []StoryLine) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
-		// Delete existing lines using SQLC
-		if err := queries.DeleteAllStoryLines(txCtx, int32(storyID)); err != nil {
-			return err
+// EditStoryText replaces the text of the story's lines, numbered 1 to n. Lines are matched
+// to the stored ones by similarity, so unchanged and edited lines keep their annotations,
+// audio and answers even when lines are inserted or removed around them. Vocabulary items
+// students have answered are only deleted, with their answers, if dropAnswered is set;
+// otherwise the edit fails with ErrEditOrphansAnswers.
+func (s *Service) EditStoryText(ctx context.Context, storyID int, lines []StoryLine, dropAnswered bool) (*LineEditReport, error) {
+	for i, line := range lines {
+		if line.LineNumber != i+1 {
+			return nil, ErrInvalidLineNumber
 		}
+	}
 
-		// Insert updated lines using SQLC
-		for _, line := range lines {
-			err := queries.UpsertStoryLine(txCtx, db.UpsertStoryLineParams{
-				StoryID:    int32(storyID),
-				LineNumber: int32(line.LineNumber),
-				Text:       line.Text,
-			})
-			if err != nil {
-				return err
-			}
+	var report *LineEditReport
+	var removedAudio []AudioFile
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		report, removedAudio, err = s.applyLineEdit(txCtx, storyID, lines, dropAnswered)
+		if err != nil {
+			return err
 		}
 
 		// Fetch existing story metadata
-		existingStory, err := queries.GetStory(txCtx, int32(storyID))
+		existingStory, err := s.queries.GetStory(txCtx, int32(storyID))
 		if err != nil {
 			return err
 		}
 		// Update last revision timestamp using SQLC, preserving existing metadata
-		err = queries.UpdateStory(txCtx, db.UpdateStoryParams{
+		err = s.queries.UpdateStory(txCtx, db.UpdateStoryParams{
 			StoryID:    int32(storyID),
 			WeekNumber: existingStory.WeekNumber,
 			DayLetter:  existingStory.DayLetter,
@@ -54,29 +56,39 @@ func EditStoryText(ctx context.Context, storyID int, lines []StoryLine) error {
 			return err
 		}
 
-		return nil
+		return s.recordStoryRevision(txCtx, storyID, "edit text")
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Invalidate cache after successful edit
-	if err == nil {
-		// Get all users who might have this story cached - for now, invalidate for all users
-		// In a production system, you might want to track which users have accessed this story
-		InvalidateStoryMetadata(storyID)
-	}
+	s.InvalidateStoryMetadata(storyID)
 
-	return err
+	// Audio of removed lines is gone from the database; its files go now unless shared
+	if err := s.deleteAudioFilesFromStorage(ctx, removedAudio); err != nil {
+		fmt.Printf("Failed to delete audio of removed lines of story %d: %v\n", storyID, err)
+	}
+	return report, nil
 }
 
 // EditStoryMetadata updates the story's metadata fields
-func EditStoryMetadata(ctx context.Context, storyID int, metadata StoryMetadata) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) EditStoryMetadata(ctx context.Context, storyID int, metadata StoryMetadata) error {
+	var previousCourseID, courseID pgtype.Int4
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		previous, err := s.queries.GetStory(txCtx, int32(storyID))
+		if err != nil {
+			return err
+		}
+		previousCourseID = previous.CourseID
+
 		// Update main story table using SQLC
-		courseID := pgtype.Int4{Valid: false}
+		courseID = pgtype.Int4{Valid: false}
 		if metadata.CourseID != nil {
 			courseID = pgtype.Int4{Int32: int32(*metadata.CourseID), Valid: true}
 		}
 
-		err := queries.UpdateStory(txCtx, db.UpdateStoryParams{
+		err = s.queries.UpdateStory(txCtx, db.UpdateStoryParams{
 			StoryID:    int32(storyID),
 			WeekNumber: int32(metadata.WeekNumber),
 			DayLetter:  metadata.DayLetter,
@@ -89,64 +101,66 @@ func EditStoryMetadata(ctx context.Context, storyID int, metadata StoryMetadata)
 			return err
 		}
 
-		// Update titles using SQLC
-		if err := queries.DeleteStoryTitles(txCtx, int32(storyID)); err != nil {
-			return err
-		}
-		for lang, title := range metadata.Title {
-			err := queries.UpsertStoryTitle(txCtx, db.UpsertStoryTitleParams{
-				StoryID:      int32(storyID),
-				LanguageCode: lang,
-				Title:        title,
-			})
-			if err != nil {
-				return err
-			}
-		}
-
-		// Update description using SQLC
-		if err := queries.DeleteStoryDescriptions(txCtx, int32(storyID)); err != nil {
+		if err := s.replaceTitlesAndDescription(txCtx, storyID, metadata); err != nil {
 			return err
 		}
 
-		if metadata.Description.Text != "" || metadata.Language != "" {
-			err := queries.UpsertStoryDescription(txCtx, db.UpsertStoryDescriptionParams{
-				StoryID:         int32(storyID),
-				LanguageCode:    metadata.Language,
-				DescriptionText: metadata.Description.Text,
-			})
-			if err != nil {
-				return err
-			}
-		}
-
-		// Update grammar points
-		if err := ClearStoryGrammarPoints(txCtx, storyID); err != nil {
+		if err := s.replaceStoryGrammarPoints(txCtx, storyID, metadata.GrammarPoints); err != nil {
 			return err
 		}
-		for _, gp := range metadata.GrammarPoints {
-			// Create grammar point for this story
-			if _, err := CreateGrammarPoint(txCtx, storyID, gp.Name, gp.Description); err != nil {
-				return err
-			}
-		}
 
-		return nil
+		return s.recordStoryRevision(txCtx, storyID, "edit metadata")
 	})
 
 	// Invalidate cache after successful edit
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
+		s.storyCourseChanged(previousCourseID, courseID)
 	}
 
 	return err
 }
 
+// replaceTitlesAndDescription swaps the story's titles and description for those in metadata
+func (s *Service) replaceTitlesAndDescription(ctx context.Context, storyID int, metadata StoryMetadata) error {
+	// Update titles using SQLC
+	if err := s.queries.DeleteStoryTitles(ctx, int32(storyID)); err != nil {
+		return err
+	}
+	for lang, title := range metadata.Title {
+		err := s.queries.UpsertStoryTitle(ctx, db.UpsertStoryTitleParams{
+			StoryID:      int32(storyID),
+			LanguageCode: lang,
+			Title:        title,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update description using SQLC
+	if err := s.queries.DeleteStoryDescriptions(ctx, int32(storyID)); err != nil {
+		return err
+	}
+
+	if metadata.Description.Text != "" || metadata.Language != "" {
+		err := s.queries.UpsertStoryDescription(ctx, db.UpsertStoryDescriptionParams{
+			StoryID:         int32(storyID),
+			LanguageCode:    metadata.Language,
+			DescriptionText: metadata.Description.Text,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddLineAnnotations updates grammar points, vocabulary, and footnotes for a specific line
-func AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line StoryLine) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line StoryLine) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Verify line exists
-		exists, err := queries.LineExists(txCtx, db.LineExistsParams{
+		exists, err := s.queries.LineExists(txCtx, db.LineExistsParams{
 			StoryID:    int32(storyID),
 			LineNumber: int32(lineNumber),
 		})
@@ -157,16 +171,25 @@ func AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line S
 			return ErrInvalidLineNumber
 		}
 
-		// Insert vocabulary items
+		// Insert vocabulary items, teaching the lexicon those that are new
+		var inserted []VocabularyItem
 		for _, v := range line.Vocabulary {
-			if err := dedupVocabularyInsert(txCtx, storyID, lineNumber, v); err != nil {
+			err := s.dedupVocabularyInsert(txCtx, storyID, lineNumber, v)
+			if err == errExists {
+				continue
+			}
+			if err != nil {
 				return err
 			}
+			inserted = append(inserted, v)
+		}
+		if err := s.learnVocabulary(txCtx, storyID, inserted); err != nil {
+			return err
 		}
 
 		// Insert grammar items
 		for _, g := range line.Grammar {
-			if err := dedupGrammarInsert(txCtx, storyID, lineNumber, g); err != nil {
+			if err := s.dedupGrammarInsert(txCtx, storyID, lineNumber, g); err != nil {
 				return err
 			}
 		}
@@ -174,18 +197,18 @@ func AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line S
 		// Insert footnotes and their references
 		// Insert footnotes
 		for _, f := range line.Footnotes {
-			if err := dedupFootnoteInsert(txCtx, storyID, lineNumber, f); err != nil {
+			if err := s.dedupFootnoteInsert(txCtx, storyID, lineNumber, f); err != nil {
 				return err
 			}
 		}
 
 		// Update last revision timestamp - get existing values first
-		story, err := queries.GetStory(txCtx, int32(storyID))
+		story, err := s.queries.GetStory(txCtx, int32(storyID))
 		if err != nil {
 			return err
 		}
 
-		err = queries.UpdateStory(txCtx, db.UpdateStoryParams{
+		err = s.queries.UpdateStory(txCtx, db.UpdateStoryParams{
 			StoryID:    story.StoryID,
 			WeekNumber: story.WeekNumber,
 			DayLetter:  story.DayLetter,
@@ -198,16 +221,16 @@ func AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line S
 			return err
 		}
 
-		return nil
+		return s.recordStoryRevision(txCtx, storyID, "add annotations")
 	})
 
 	// Invalidate cache after successful edit
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 		// Also invalidate line-specific cache
-		if cacheInstance != nil && keyBuilder != nil {
-			lineKey := keyBuilder.LineAnnotations(storyID, lineNumber)
-			_ = cacheInstance.Delete(lineKey)
+		if s.cache != nil && s.keys != nil {
+			lineKey := s.keys.LineAnnotations(storyID, lineNumber)
+			_ = s.cache.Delete(lineKey)
 		}
 	}
 
@@ -215,9 +238,9 @@ func AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line S
 }
 
 // ClearStoryAnnotations removes all annotations from a story while preserving the text and metadata
-func ClearStoryAnnotations(ctx context.Context, storyID int) error {
+func (s *Service) ClearStoryAnnotations(ctx context.Context, storyID int) error {
 	// Verify story exists first
-	exists, err := queries.StoryExists(ctx, int32(storyID))
+	exists, err := s.queries.StoryExists(ctx, int32(storyID))
 	if err != nil {
 		return err
 	}
@@ -225,35 +248,39 @@ func ClearStoryAnnotations(ctx context.Context, storyID int) error {
 		return ErrNotFound
 	}
 
-	err = withTransaction(ctx, func(txCtx context.Context) error {
+	err = s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Delete all annotations
-		if err := queries.DeleteAllStoryAnnotations(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
+		if err := s.queries.DeleteAllStoryAnnotations(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
 			return err
 		}
-		if err := queries.DeleteAllVocabularyForStory(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
+		if err := s.queries.DeleteAllVocabularyForStory(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
 			return err
 		}
-		if err := queries.DeleteAllGrammarForStory(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
+		if err := s.queries.DeleteAllGrammarForStory(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true}); err != nil {
 			return err
 		}
 
 		// Update last revision timestamp
-		return queries.UpdateStoryRevision(txCtx, int32(storyID))
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, "clear annotations")
 	})
 
 	// Invalidate cache after successful clear
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 	}
 
 	return err
 }
 
 // ClearLineAnnotations removes all annotations from a specific line
-func ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Verify line exists
-		exists, err := queries.LineExists(txCtx, db.LineExistsParams{
+		exists, err := s.queries.LineExists(txCtx, db.LineExistsParams{
 			StoryID:    int32(storyID),
 			LineNumber: int32(lineNumber),
 		})
@@ -265,7 +292,7 @@ func ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) erro
 		}
 
 		// Delete footnote references first, then footnotes
-		if err := queries.DeleteLineFootnoteReferences(txCtx, db.DeleteLineFootnoteReferencesParams{
+		if err := s.queries.DeleteLineFootnoteReferences(txCtx, db.DeleteLineFootnoteReferencesParams{
 			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		}); err != nil {
@@ -273,19 +300,19 @@ func ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) erro
 		}
 
 		// Delete line-specific annotations
-		if err := queries.DeleteAllLineAnnotations(txCtx, db.DeleteAllLineAnnotationsParams{
+		if err := s.queries.DeleteAllLineAnnotations(txCtx, db.DeleteAllLineAnnotationsParams{
 			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		}); err != nil {
 			return err
 		}
-		if err := queries.DeleteLineVocabulary(txCtx, db.DeleteLineVocabularyParams{
+		if err := s.queries.DeleteLineVocabulary(txCtx, db.DeleteLineVocabularyParams{
 			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		}); err != nil {
 			return err
 		}
-		if err := queries.DeleteLineGrammar(txCtx, db.DeleteLineGrammarParams{
+		if err := s.queries.DeleteLineGrammar(txCtx, db.DeleteLineGrammarParams{
 			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 		}); err != nil {
@@ -293,16 +320,20 @@ func ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) erro
 		}
 
 		// Update last revision timestamp
-		return queries.UpdateStoryRevision(txCtx, int32(storyID))
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, "clear line annotations")
 	})
 
 	// Invalidate cache after successful clear
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 		// Also invalidate line-specific cache
-		if cacheInstance != nil && keyBuilder != nil {
-			lineKey := keyBuilder.LineAnnotations(storyID, lineNumber)
-			_ = cacheInstance.Delete(lineKey)
+		if s.cache != nil && s.keys != nil {
+			lineKey := s.keys.LineAnnotations(storyID, lineNumber)
+			_ = s.cache.Delete(lineKey)
 		}
 	}
 
@@ -310,10 +341,17 @@ func ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) erro
 }
 
 // UpdateVocabularyAnnotation updates a vocabulary annotation at a specific position
-func UpdateVocabularyAnnotation(ctx context.Context, storyID int, lineNumber int, position [2]int, vocab VocabularyItem) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) UpdateVocabularyAnnotation(ctx context.Context, storyID int, lineNumber int, position [2]int, vocab VocabularyItem) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		previous, err := s.lineVocabulary(txCtx, storyID, lineNumber, func(item db.VocabularyItem) bool {
+			return item.PositionStart == int32(position[0]) && item.PositionEnd == int32(position[1])
+		})
+		if err != nil {
+			return err
+		}
+
 		// Update the vocabulary item using SQLC
-		err := queries.UpdateVocabularyByPosition(txCtx, db.UpdateVocabularyByPositionParams{
+		err = s.queries.UpdateVocabularyByPosition(txCtx, db.UpdateVocabularyByPositionParams{
 			StoryID:       pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber:    pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 			PositionStart: int32(position[0]),
@@ -325,18 +363,25 @@ func UpdateVocabularyAnnotation(ctx context.Context, storyID int, lineNumber int
 		if err != nil {
 			return err
 		}
+		if err := s.relearnVocabulary(txCtx, storyID, previous, func(VocabularyItem) VocabularyItem { return vocab }); err != nil {
+			return err
+		}
 
 		// Update last revision timestamp
-		return queries.UpdateStoryRevision(txCtx, int32(storyID))
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, "update vocabulary")
 	})
 
 	// Invalidate cache after successful update
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 		// Also invalidate line-specific cache
-		if cacheInstance != nil && keyBuilder != nil {
-			lineKey := keyBuilder.LineAnnotations(storyID, lineNumber)
-			_ = cacheInstance.Delete(lineKey)
+		if s.cache != nil && s.keys != nil {
+			lineKey := s.keys.LineAnnotations(storyID, lineNumber)
+			_ = s.cache.Delete(lineKey)
 		}
 	}
 
@@ -344,14 +389,14 @@ func UpdateVocabularyAnnotation(ctx context.Context, storyID int, lineNumber int
 }
 
 // UpdateGrammarAnnotation updates a grammar annotation at a specific position
-func UpdateGrammarAnnotation(ctx context.Context, storyID int, lineNumber int, position [2]int, grammar GrammarItem) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) UpdateGrammarAnnotation(ctx context.Context, storyID int, lineNumber int, position [2]int, grammar GrammarItem) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Update the grammar item using SQLC
 		grammarPointID := pgtype.Int4{Valid: false}
 		if grammar.GrammarPointID != nil {
 			grammarPointID = pgtype.Int4{Int32: int32(*grammar.GrammarPointID), Valid: true}
 		}
-		err := queries.UpdateGrammarByPosition(txCtx, db.UpdateGrammarByPositionParams{
+		err := s.queries.UpdateGrammarByPosition(txCtx, db.UpdateGrammarByPositionParams{
 			StoryID:        pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber:     pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 			PositionStart:  int32(position[0]),
@@ -365,16 +410,20 @@ func UpdateGrammarAnnotation(ctx context.Context, storyID int, lineNumber int, p
 		}
 
 		// Update last revision timestamp
-		return queries.UpdateStoryRevision(txCtx, int32(storyID))
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, "update grammar")
 	})
 
 	// Invalidate cache after successful update
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 		// Also invalidate line-specific cache
-		if cacheInstance != nil && keyBuilder != nil {
-			lineKey := keyBuilder.LineAnnotations(storyID, lineNumber)
-			_ = cacheInstance.Delete(lineKey)
+		if s.cache != nil && s.keys != nil {
+			lineKey := s.keys.LineAnnotations(storyID, lineNumber)
+			_ = s.cache.Delete(lineKey)
 		}
 	}
 
@@ -382,10 +431,17 @@ func UpdateGrammarAnnotation(ctx context.Context, storyID int, lineNumber int, p
 }
 
 // UpdateVocabularyByWord updates the lexical form of all vocabulary items with a specific word
-func UpdateVocabularyByWord(ctx context.Context, storyID int, lineNumber int, word string, newLexicalForm string) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) UpdateVocabularyByWord(ctx context.Context, storyID int, lineNumber int, word string, newLexicalForm string) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		previous, err := s.lineVocabulary(txCtx, storyID, lineNumber, func(item db.VocabularyItem) bool {
+			return item.Word == word
+		})
+		if err != nil {
+			return err
+		}
+
 		// Update the vocabulary item by word using SQLC
-		err := queries.UpdateVocabularyByWord(txCtx, db.UpdateVocabularyByWordParams{
+		err = s.queries.UpdateVocabularyByWord(txCtx, db.UpdateVocabularyByWordParams{
 			StoryID:     pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber:  pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 			Word:        word,
@@ -394,18 +450,29 @@ func UpdateVocabularyByWord(ctx context.Context, storyID int, lineNumber int, wo
 		if err != nil {
 			return err
 		}
+		err = s.relearnVocabulary(txCtx, storyID, previous, func(item VocabularyItem) VocabularyItem {
+			item.LexicalForm = newLexicalForm
+			return item
+		})
+		if err != nil {
+			return err
+		}
 
 		// Update last revision timestamp
-		return queries.UpdateStoryRevision(txCtx, int32(storyID))
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, "update vocabulary")
 	})
 
 	// Invalidate cache after successful update
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 		// Also invalidate line-specific cache
-		if cacheInstance != nil && keyBuilder != nil {
-			lineKey := keyBuilder.LineAnnotations(storyID, lineNumber)
-			_ = cacheInstance.Delete(lineKey)
+		if s.cache != nil && s.keys != nil {
+			lineKey := s.keys.LineAnnotations(storyID, lineNumber)
+			_ = s.cache.Delete(lineKey)
 		}
 	}
 
@@ -413,10 +480,10 @@ func UpdateVocabularyByWord(ctx context.Context, storyID int, lineNumber int, wo
 }
 
 // UpdateFootnoteAnnotation updates a footnote and its references
-func UpdateFootnoteAnnotation(ctx context.Context, storyID int, footnoteID int, footnote Footnote) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) UpdateFootnoteAnnotation(ctx context.Context, storyID int, footnoteID int, footnote Footnote) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Update the footnote text using SQLC
-		err := queries.UpdateFootnote(txCtx, db.UpdateFootnoteParams{
+		err := s.queries.UpdateFootnote(txCtx, db.UpdateFootnoteParams{
 			ID:           int32(footnoteID),
 			StoryID:      pgtype.Int4{Int32: int32(storyID), Valid: true},
 			FootnoteText: footnote.Text,
@@ -426,13 +493,13 @@ func UpdateFootnoteAnnotation(ctx context.Context, storyID int, footnoteID int,
 		}
 
 		// Delete existing references using SQLC
-		if err := queries.DeleteFootnoteReferences(txCtx, int32(footnoteID)); err != nil {
+		if err := s.queries.DeleteFootnoteReferences(txCtx, int32(footnoteID)); err != nil {
 			return err
 		}
 
 		// Insert new references using SQLC
 		for _, ref := range footnote.References {
-			err := queries.CreateFootnoteReference(txCtx, db.CreateFootnoteReferenceParams{
+			err := s.queries.CreateFootnoteReference(txCtx, db.CreateFootnoteReferenceParams{
 				FootnoteID: int32(footnoteID),
 				Reference:  ref,
 			})
@@ -442,12 +509,16 @@ func UpdateFootnoteAnnotation(ctx context.Context, storyID int, footnoteID int,
 		}
 
 		// Update last revision timestamp
-		return queries.UpdateStoryRevision(txCtx, int32(storyID))
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, "update footnote")
 	})
 
 	// Invalidate cache after successful update
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
 	}
 
 	return err
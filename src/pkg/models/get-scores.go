@@ -6,8 +6,8 @@ import (
 )
 
 // GetUserGrammarScores retrieves grammar scores for a user and story
-func GetUserGrammarScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) {
-	summary, err := GetUserStoryGrammarSummary(ctx, userID, int32(storyID))
+func (s *Service) GetUserGrammarScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) {
+	summary, err := s.GetUserStoryGrammarSummary(ctx, userID, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
@@ -23,8 +23,8 @@ func GetUserGrammarScores(ctx context.Context, userID string, storyID int) (map[
 }
 
 // GetUserVocabScores retrieves vocabulary scores for a user and story
-func GetUserVocabScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) {
-	summary, err := GetUserStoryVocabSummary(ctx, userID, int32(storyID))
+func (s *Service) GetUserVocabScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) {
+	summary, err := s.GetUserStoryVocabSummary(ctx, userID, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
@@ -39,9 +39,9 @@ func GetUserVocabScores(ctx context.Context, userID string, storyID int) (map[in
 	return result, nil
 }
 
-func CheckAllVocabCompleteForLine(ctx context.Context, userID string, storyID, lineNumber int) (bool, error) {
+func (s *Service) CheckAllVocabCompleteForLine(ctx context.Context, userID string, storyID, lineNumber int) (bool, error) {
 	lineNumber = lineNumber + 1 // Convert to 1-based index for DB
-	return queries.CheckAllVocabCompleteForLineForUser(ctx, db.CheckAllVocabCompleteForLineForUserParams{
+	return s.queries.CheckAllVocabCompleteForLineForUser(ctx, db.CheckAllVocabCompleteForLineForUserParams{
 		UserID:     userID,
 		StoryID:    convertToPGInt(storyID),
 		LineNumber: convertToPGInt(lineNumber),
@@ -55,8 +55,8 @@ type UserStoryVocabSummary struct {
 }
 
 // GetUserStoryVocabSummary retrieves vocabulary summary for a user and story
-func GetUserStoryVocabSummary(ctx context.Context, userID string, storyID int32) (*UserStoryVocabSummary, error) {
-	result, err := queries.GetUserStoryVocabSummary(ctx, db.GetUserStoryVocabSummaryParams{
+func (s *Service) GetUserStoryVocabSummary(ctx context.Context, userID string, storyID int32) (*UserStoryVocabSummary, error) {
+	result, err := s.queries.GetUserStoryVocabSummary(ctx, db.GetUserStoryVocabSummaryParams{
 		UserID:  userID,
 		StoryID: storyID,
 	})
@@ -77,8 +77,8 @@ type UserStoryGrammarSummary struct {
 }
 
 // GetUserStoryGrammarSummary retrieves grammar summary for a user and story
-func GetUserStoryGrammarSummary(ctx context.Context, userID string, storyID int32) (*UserStoryGrammarSummary, error) {
-	result, err := queries.GetUserStoryGrammarSummary(ctx, db.GetUserStoryGrammarSummaryParams{
+func (s *Service) GetUserStoryGrammarSummary(ctx context.Context, userID string, storyID int32) (*UserStoryGrammarSummary, error) {
+	result, err := s.queries.GetUserStoryGrammarSummary(ctx, db.GetUserStoryGrammarSummaryParams{
 		UserID:  userID,
 		StoryID: storyID,
 	})
@@ -92,6 +92,28 @@ func GetUserStoryGrammarSummary(ctx context.Context, userID string, storyID int3
 	}, nil
 }
 
+// UserStoryRecallSummary represents recall summary data
+type UserStoryRecallSummary struct {
+	CorrectCount   int64
+	IncorrectCount int64
+}
+
+// GetUserStoryRecallSummary retrieves recall summary for a user and story
+func (s *Service) GetUserStoryRecallSummary(ctx context.Context, userID string, storyID int32) (*UserStoryRecallSummary, error) {
+	result, err := s.queries.GetUserStoryRecallSummary(ctx, db.GetUserStoryRecallSummaryParams{
+		UserID:  userID,
+		StoryID: storyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserStoryRecallSummary{
+		CorrectCount:   result.CorrectCount,
+		IncorrectCount: result.IncorrectCount,
+	}, nil
+}
+
 // UserStoryTimeTracking represents time tracking data
 type UserStoryTimeTracking struct {
 	VocabTimeSeconds       int
@@ -101,8 +123,8 @@ type UserStoryTimeTracking struct {
 }
 
 // GetUserStoryTimeTracking retrieves time tracking summary for a user and story
-func GetUserStoryTimeTracking(ctx context.Context, userID string, storyID int32) (*UserStoryTimeTracking, error) {
-	result, err := queries.GetUserStoryTimeTracking(ctx, db.GetUserStoryTimeTrackingParams{
+func (s *Service) GetUserStoryTimeTracking(ctx context.Context, userID string, storyID int32) (*UserStoryTimeTracking, error) {
+	result, err := s.queries.GetUserStoryTimeTracking(ctx, db.GetUserStoryTimeTrackingParams{
 		UserID:  userID,
 		StoryID: convertToPGInt(storyID),
 	})
@@ -137,17 +159,17 @@ func GetUserStoryTimeTracking(ctx context.Context, userID string, storyID int32)
 }
 
 // CountStoryVocabItems returns the total number of vocabulary items for a story (cached)
-func CountStoryVocabItems(ctx context.Context, storyID int32) (int64, error) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) CountStoryVocabItems(ctx context.Context, storyID int32) (int64, error) {
+	if s.cache == nil || s.keys == nil {
 		// No cache available, query directly
-		return queries.CountStoryVocabItems(ctx, convertToPGInt(storyID))
+		return s.queries.CountStoryVocabItems(ctx, convertToPGInt(storyID))
 	}
 
-	cacheKey := keyBuilder.StoryVocabCount(int(storyID))
+	cacheKey := s.keys.StoryVocabCount(int(storyID))
 
 	var count int64
-	err := cacheInstance.GetOrSetJSON(cacheKey, &count, func() (any, error) {
-		return queries.CountStoryVocabItems(ctx, convertToPGInt(storyID))
+	err := s.cache.GetOrSetJSON(cacheKey, &count, func() (any, error) {
+		return s.queries.CountStoryVocabItems(ctx, convertToPGInt(storyID))
 	})
 
 	return count, err
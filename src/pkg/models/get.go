@@ -15,16 +15,16 @@ import (
 )
 
 // checkUserAccessWithCache checks if a user has access to a story with caching
-func checkUserAccessWithCache(userID string, storyID int, checkFunc func() (bool, error)) (bool, error) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) checkUserAccessWithCache(userID string, storyID int, checkFunc func() (bool, error)) (bool, error) {
+	if s.cache == nil || s.keys == nil {
 		// Fallback to direct check if cache not available
 		return checkFunc()
 	}
 
-	cacheKey := keyBuilder.UserAccess(userID, storyID)
+	cacheKey := s.keys.UserAccess(userID, storyID)
 
 	// Try to get from cache first
-	if data, err := cacheInstance.Get(cacheKey); err == nil {
+	if data, err := s.cache.Get(cacheKey); err == nil {
 		// Cache hit - return cached access result
 		return string(data) == "true", nil
 	}
@@ -40,28 +40,29 @@ func checkUserAccessWithCache(userID string, storyID int, checkFunc func() (bool
 	if hasAccess {
 		accessStr = "true"
 	}
-	_ = cacheInstance.Set(cacheKey, []byte(accessStr))
+	_ = s.cache.Set(cacheKey, []byte(accessStr))
 
 	return hasAccess, nil
 }
 
-func GetStoryData(ctx context.Context, id int, userID string) (*Story, error) {
+func (s *Service) GetStoryData(ctx context.Context, id int, userID string) (*Story, error) {
 	// Check user access first (with caching)
-	if cacheInstance != nil && keyBuilder != nil {
-		hasAccess, err := checkUserAccessWithCache(userID, id, func() (bool, error) {
+	if s.cache != nil && s.keys != nil {
+		hasAccess, err := s.checkUserAccessWithCache(userID, id, func() (bool, error) {
 			// Check if user has access to this story
-			dbStory, err := queries.GetStory(ctx, int32(id))
+			dbStory, err := s.queries.GetStory(ctx, int32(id))
 			if err != nil {
 				if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 					return false, ErrNotFound
 				}
 				return false, err
 			}
+			s.rememberStoryCourse(id, dbStory.CourseID)
 
 			// Check course access if story has course
 			if dbStory.CourseID.Valid {
 				courseID := int32(dbStory.CourseID.Int32)
-				return CanUserAccessCourse(ctx, userID, courseID), nil
+				return s.CanUserAccessCourse(ctx, userID, courseID), nil
 			}
 			return true, nil // No course restriction
 		})
@@ -73,7 +74,7 @@ func GetStoryData(ctx context.Context, id int, userID string) (*Story, error) {
 		}
 	} else {
 		// Fallback to direct access check
-		dbStory, err := queries.GetStory(ctx, int32(id))
+		dbStory, err := s.queries.GetStory(ctx, int32(id))
 		if err != nil {
 			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 				return nil, ErrNotFound
@@ -84,35 +85,46 @@ func GetStoryData(ctx context.Context, id int, userID string) (*Story, error) {
 		// Check if user has access to this story
 		if dbStory.CourseID.Valid {
 			courseID := int32(dbStory.CourseID.Int32)
-			if !CanUserAccessCourse(ctx, userID, courseID) {
+			if !s.CanUserAccessCourse(ctx, userID, courseID) {
 				return nil, ErrNotFound
 			}
 		}
 	}
 
+	// Checked outside the cached access result, which does not expire when a story opens
+	schedule, err := s.GetStorySchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Available(s.clock.Now()) && !s.CanUserEditStory(ctx, userID, int32(id)) {
+		return nil, ErrNotFound
+	}
+
 	// Try cache for story data (no user ID in key)
-	if cacheInstance != nil && keyBuilder != nil {
-		cacheKey := keyBuilder.StoryData(id)
-		var story Story
-		err := cacheInstance.GetOrSetJSON(cacheKey, &story, func() (any, error) {
-			return getStoryDataFromDB(ctx, id, userID)
+	var story *Story
+	if s.cache != nil && s.keys != nil {
+		cacheKey := s.keys.StoryData(id)
+		story = &Story{}
+		err = s.cache.GetOrSetJSON(cacheKey, story, func() (any, error) {
+			return s.getStoryDataFromDB(ctx, id, userID)
 		})
-		if err != nil {
-			return nil, err
-		}
-		return &story, nil
+	} else {
+		// Fallback to direct DB access
+		story, err = s.getStoryDataFromDB(ctx, id, userID)
 	}
-
-	// Fallback to direct DB access
-	return getStoryDataFromDB(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.AvailableFrom != nil || schedule.DueAt != nil || schedule.LateUntil != nil {
+		story.Metadata.Schedule = schedule
+	}
+	return story, nil
 }
 
 // getStoryDataFromDB performs the actual database operations for GetStoryData
-func getStoryDataFromDB(ctx context.Context, id int, userID string) (*Story, error) {
-	story := NewStory()
-
+func (s *Service) getStoryDataFromDB(ctx context.Context, id int, userID string) (*Story, error) {
 	// Get main story data
-	dbStory, err := queries.GetStory(ctx, int32(id))
+	dbStory, err := s.queries.GetStory(ctx, int32(id))
 	if err != nil {
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 			return nil, ErrNotFound
@@ -123,10 +135,31 @@ func getStoryDataFromDB(ctx context.Context, id int, userID string) (*Story, err
 	// Check if user has access to this story
 	if dbStory.CourseID.Valid {
 		courseID := int32(dbStory.CourseID.Int32)
-		if !CanUserAccessCourse(ctx, userID, courseID) {
+		if !s.CanUserAccessCourse(ctx, userID, courseID) {
+			return nil, ErrNotFound
+		}
+	}
+
+	return s.storyFromDB(ctx, dbStory)
+}
+
+// loadStory reads a full story without access checks or the cache, for snapshots
+// taken inside a write transaction
+func (s *Service) loadStory(ctx context.Context, id int) (*Story, error) {
+	dbStory, err := s.queries.GetStory(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 			return nil, ErrNotFound
 		}
+		return nil, err
 	}
+	return s.storyFromDB(ctx, dbStory)
+}
+
+// storyFromDB completes a story row with its titles, description, grammar points and lines
+func (s *Service) storyFromDB(ctx context.Context, dbStory db.Story) (*Story, error) {
+	story := NewStory()
+	id := int(dbStory.StoryID)
 
 	// Convert DB story to model story
 	story.Metadata.StoryID = int(dbStory.StoryID)
@@ -146,7 +179,7 @@ func getStoryDataFromDB(ctx context.Context, id int, userID string) (*Story, err
 	}
 
 	// Get titles
-	titles, err := queries.GetStoryTitles(ctx, int32(id))
+	titles, err := s.queries.GetStoryTitles(ctx, int32(id))
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +188,7 @@ func getStoryDataFromDB(ctx context.Context, id int, userID string) (*Story, err
 	}
 
 	// Get description
-	storyWithDesc, err := queries.GetStoryWithDescription(ctx, int32(id))
+	storyWithDesc, err := s.queries.GetStoryWithDescription(ctx, int32(id))
 	if err == nil {
 		if storyWithDesc.LanguageCode.Valid && storyWithDesc.DescriptionText.Valid {
 			story.Metadata.Language = storyWithDesc.LanguageCode.String
@@ -164,14 +197,14 @@ func getStoryDataFromDB(ctx context.Context, id int, userID string) (*Story, err
 	}
 
 	// Get grammar points
-	grammarPoints, err := GetStoryGrammarPoints(ctx, id)
+	grammarPoints, err := s.GetStoryGrammarPoints(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	story.Metadata.GrammarPoints = grammarPoints
 
 	// Get lines with their components
-	lines, err := getStoryLines(ctx, id)
+	lines, err := s.getStoryLines(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -199,15 +232,15 @@ func convertReferences(ref interface{}) []string {
 	return nil
 }
 
-func getStoryLines(ctx context.Context, storyID int) ([]StoryLine, error) {
-	dbLines, err := queries.GetStoryLines(ctx, int32(storyID))
+func (s *Service) getStoryLines(ctx context.Context, storyID int) ([]StoryLine, error) {
+	dbLines, err := s.queries.GetStoryLines(ctx, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
 
 	// Map for Vocabulary items grouped by line number
 	vocabMap := make(map[int][]VocabularyItem)
-	vocabRows, err := queries.GetAllVocabularyForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	vocabRows, err := s.queries.GetAllVocabularyForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +255,7 @@ func getStoryLines(ctx context.Context, storyID int) ([]StoryLine, error) {
 
 	// Map for Grammar items grouped by line number
 	grammarMap := make(map[int][]GrammarItem)
-	grammarRows, err := queries.GetAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	grammarRows, err := s.queries.GetAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +274,7 @@ func getStoryLines(ctx context.Context, storyID int) ([]StoryLine, error) {
 
 	// Map for Audio files grouped by line number
 	audioMap := make(map[int][]AudioFile)
-	audioRows, err := queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	audioRows, err := s.queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -257,7 +290,7 @@ func getStoryLines(ctx context.Context, storyID int) ([]StoryLine, error) {
 
 	// Map for Footnotes grouped by line number
 	footnoteMap := make(map[int][]Footnote)
-	footnoteRows, err := queries.GetStoryFootnotesWithReferences(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	footnoteRows, err := s.queries.GetStoryFootnotesWithReferences(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -308,13 +341,13 @@ func getStoryLines(ctx context.Context, storyID int) ([]StoryLine, error) {
 }
 
 // GetLineAnnotations retrieves all annotations for a specific line
-func GetLineAnnotations(ctx context.Context, storyID int, lineNumber int) (*StoryLine, error) {
+func (s *Service) GetLineAnnotations(ctx context.Context, storyID int, lineNumber int) (*StoryLine, error) {
 	// Try cache first if available
-	if cacheInstance != nil && keyBuilder != nil {
-		cacheKey := keyBuilder.LineAnnotations(storyID, lineNumber)
+	if s.cache != nil && s.keys != nil {
+		cacheKey := s.keys.LineAnnotations(storyID, lineNumber)
 		var line StoryLine
-		err := cacheInstance.GetOrSetJSON(cacheKey, &line, func() (any, error) {
-			return getLineAnnotationsFromDB(ctx, storyID, lineNumber)
+		err := s.cache.GetOrSetJSON(cacheKey, &line, func() (any, error) {
+			return s.getLineAnnotationsFromDB(ctx, storyID, lineNumber)
 		})
 		if err != nil {
 			return nil, err
@@ -323,11 +356,11 @@ func GetLineAnnotations(ctx context.Context, storyID int, lineNumber int) (*Stor
 	}
 
 	// Fallback to direct DB access
-	return getLineAnnotationsFromDB(ctx, storyID, lineNumber)
+	return s.getLineAnnotationsFromDB(ctx, storyID, lineNumber)
 }
 
 // getLineAnnotationsFromDB performs the actual database operations for GetLineAnnotations
-func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int) (*StoryLine, error) {
+func (s *Service) getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int) (*StoryLine, error) {
 	line := &StoryLine{
 		LineNumber: lineNumber,
 		Vocabulary: []VocabularyItem{}, // init as empty arrays
@@ -337,7 +370,7 @@ func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int)
 	}
 
 	// Get vocabulary items for this line
-	vocabItems, err := queries.GetVocabularyItems(ctx, db.GetVocabularyItemsParams{
+	vocabItems, err := s.queries.GetVocabularyItems(ctx, db.GetVocabularyItemsParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -353,7 +386,7 @@ func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int)
 	}
 
 	// Get grammar items for this line
-	grammarItems, err := queries.GetGrammarItems(ctx, db.GetGrammarItemsParams{
+	grammarItems, err := s.queries.GetGrammarItems(ctx, db.GetGrammarItemsParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -373,7 +406,7 @@ func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int)
 	}
 
 	// Get audio files for this line
-	audioFiles, err := queries.GetLineAudioFiles(ctx, db.GetLineAudioFilesParams{
+	audioFiles, err := s.queries.GetLineAudioFiles(ctx, db.GetLineAudioFilesParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -390,7 +423,7 @@ func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int)
 	}
 
 	// Get footnotes for this line
-	footnotes, err := queries.GetFootnotes(ctx, db.GetFootnotesParams{
+	footnotes, err := s.queries.GetFootnotes(ctx, db.GetFootnotesParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -398,7 +431,7 @@ func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int)
 		return nil, err
 	}
 	for _, fn := range footnotes {
-		refs, err := queries.GetFootnoteReferences(ctx, fn.ID)
+		refs, err := s.queries.GetFootnoteReferences(ctx, fn.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -413,13 +446,13 @@ func getLineAnnotationsFromDB(ctx context.Context, storyID int, lineNumber int)
 }
 
 // GetStoryAnnotations retrieves all annotations for a story grouped by line
-func GetStoryAnnotations(ctx context.Context, storyID int) (map[int]*StoryLine, error) {
+func (s *Service) GetStoryAnnotations(ctx context.Context, storyID int) (map[int]*StoryLine, error) {
 	// Try cache first if available
-	if cacheInstance != nil && keyBuilder != nil {
-		cacheKey := keyBuilder.StoryAnnotations(storyID)
+	if s.cache != nil && s.keys != nil {
+		cacheKey := s.keys.StoryAnnotations(storyID)
 		var annotations map[int]*StoryLine
-		err := cacheInstance.GetOrSetJSON(cacheKey, &annotations, func() (any, error) {
-			return getStoryAnnotationsFromDB(ctx, storyID)
+		err := s.cache.GetOrSetJSON(cacheKey, &annotations, func() (any, error) {
+			return s.getStoryAnnotationsFromDB(ctx, storyID)
 		})
 		if err != nil {
 			return nil, err
@@ -428,13 +461,13 @@ func GetStoryAnnotations(ctx context.Context, storyID int) (map[int]*StoryLine,
 	}
 
 	// Fallback to direct DB access
-	return getStoryAnnotationsFromDB(ctx, storyID)
+	return s.getStoryAnnotationsFromDB(ctx, storyID)
 }
 
 // getStoryAnnotationsFromDB performs the actual database operations for GetStoryAnnotations
-func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*StoryLine, error) {
+func (s *Service) getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*StoryLine, error) {
 	// Verify story exists
-	exists, err := queries.StoryExists(ctx, int32(storyID))
+	exists, err := s.queries.StoryExists(ctx, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
@@ -445,7 +478,7 @@ func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*Story
 	lines := make(map[int]*StoryLine)
 
 	// Get all vocabulary items
-	vocabItems, err := queries.GetAllVocabularyForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	vocabItems, err := s.queries.GetAllVocabularyForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -468,7 +501,7 @@ func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*Story
 	}
 
 	// Get all grammar items
-	grammarItems, err := queries.GetAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	grammarItems, err := s.queries.GetAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -495,7 +528,7 @@ func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*Story
 	}
 
 	// Get all footnotes
-	footnotes, err := queries.GetAllFootnotesForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	footnotes, err := s.queries.GetAllFootnotesForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -510,7 +543,7 @@ func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*Story
 				Footnotes:  []Footnote{},
 			}
 		}
-		refs, err := queries.GetFootnoteReferences(ctx, fn.ID)
+		refs, err := s.queries.GetFootnoteReferences(ctx, fn.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -522,7 +555,7 @@ func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*Story
 	}
 
 	// Get all audio files for the story and organize by line
-	allAudioFiles, err := queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	allAudioFiles, err := s.queries.GetAllStoryAudioFiles(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
 	if err != nil {
 		return nil, err
 	}
@@ -549,9 +582,9 @@ func getStoryAnnotationsFromDB(ctx context.Context, storyID int) (map[int]*Story
 }
 
 // GetLineText retrieves the text content of a specific line
-func GetLineText(ctx context.Context, storyID int, lineNumber int) (string, error) {
+func (s *Service) GetLineText(ctx context.Context, storyID int, lineNumber int) (string, error) {
 
-	text, err := queries.GetLineText(ctx, db.GetLineTextParams{
+	text, err := s.queries.GetLineText(ctx, db.GetLineTextParams{
 		StoryID:    int32(storyID),
 		LineNumber: int32(lineNumber),
 	})
@@ -565,11 +598,11 @@ func GetLineText(ctx context.Context, storyID int, lineNumber int) (string, erro
 }
 
 // withTransaction executes a function within a database transaction
-func withTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+func (s *Service) withTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
 	var pool *pgxpool.Pool
-	if p, ok := rawConn.(*pgxpool.Pool); ok {
+	if p, ok := s.rawConn.(*pgxpool.Pool); ok {
 		pool = p
-	} else if r, ok := rawConn.(*database.ReconnectableDBTX); ok {
+	} else if r, ok := s.rawConn.(*database.ReconnectableDBTX); ok {
 		pool = r.Pool()
 	}
 
@@ -595,15 +628,15 @@ func withTransaction(ctx context.Context, fn func(ctx context.Context) error) er
 	return fn(ctx)
 }
 
-func GetAllStories(ctx context.Context, language string, userID string) ([]Story, error) {
+func (s *Service) GetAllStories(ctx context.Context, language string, userID string) ([]Story, error) {
 	// Don't cache "all stories" - this is user-specific due to access controls
 	// Individual stories are cached separately in GetStoryData
-	return getAllStoriesFromDB(ctx, language, userID)
+	return s.getAllStoriesFromDB(ctx, language, userID)
 }
 
 // getAllStoriesFromDB performs the actual database operations for GetAllStories
-func getAllStoriesFromDB(ctx context.Context, language string, userID string) ([]Story, error) {
-	basicStories, err := queries.GetAllStoriesForUser(ctx, db.GetAllStoriesForUserParams{
+func (s *Service) getAllStoriesFromDB(ctx context.Context, language string, userID string) ([]Story, error) {
+	basicStories, err := s.queries.GetAllStoriesForUser(ctx, db.GetAllStoriesForUserParams{
 		LanguageCode: language,
 		UserID:       userID,
 	})
@@ -627,13 +660,16 @@ func getAllStoriesFromDB(ctx context.Context, language string, userID string) ([
 		}
 		stories = append(stories, story)
 	}
-	return stories, nil
+	if err := s.attachStorySchedules(ctx, stories); err != nil {
+		return nil, err
+	}
+	return s.availableStories(ctx, userID, stories), nil
 }
 
-// Get stories for course doesn't use cache, but returns all available stories for a course
-// It returns just basic information
-func GetStoriesForCourse(ctx context.Context, courseID int) ([]Story, error) {
-	stories, err := queries.GetCourseStoriesWithTitles(ctx, db.GetCourseStoriesWithTitlesParams{
+// Get stories for course doesn't use cache, but returns all stories for a course, released or not
+// It returns just basic information and schedules
+func (s *Service) GetStoriesForCourse(ctx context.Context, courseID int) ([]Story, error) {
+	stories, err := s.queries.GetCourseStoriesWithTitles(ctx, db.GetCourseStoriesWithTitlesParams{
 		CourseID:     pgtype.Int4{Int32: int32(courseID), Valid: true},
 		LanguageCode: "en",
 	})
@@ -649,9 +685,23 @@ func GetStoriesForCourse(ctx context.Context, courseID int) ([]Story, error) {
 				WeekNumber: int(story.WeekNumber),
 				DayLetter:  story.DayLetter,
 				Title:      map[string]string{"en": story.Title},
+				CourseID:   &courseID,
 			},
 		}
 	}
+	if err := s.attachStorySchedules(ctx, result); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
+
+// GetAvailableStoriesForCourse is GetStoriesForCourse without the stories that are not
+// available to the user yet
+func (s *Service) GetAvailableStoriesForCourse(ctx context.Context, courseID int, userID string) ([]Story, error) {
+	stories, err := s.GetStoriesForCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.availableStories(ctx, userID, stories), nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import "context"
+
+// Gradebook crosses every enrolled student of a course with every story of the course
+type Gradebook struct {
+	CourseID int                `json:"course_id"`
+	Stories  []Story            `json:"stories"` // Basic story information, in course order
+	Students []GradebookStudent `json:"students"`
+}
+
+// GradebookStudent holds one student's performance on each story of a gradebook
+type GradebookStudent struct {
+	UserID   string                     `json:"user_id"`
+	UserName string                     `json:"user_name"`
+	Email    string                     `json:"email"`
+	Stories  []CourseStudentPerformance `json:"stories"` // Same order as Gradebook.Stories
+	Late     []bool                     `json:"late"`    // Whether the latest score on each story was late
+}
+
+// GetCourseGradebook builds the gradebook of a course from GetStoryStudentPerformance,
+// with lateness from the latest score snapshots. status filters students by course
+// status like GetStoryStudentPerformance does.
+func (s *Service) GetCourseGradebook(ctx context.Context, courseID int32, status string) (*Gradebook, error) {
+	stories, err := s.GetStoriesForCourse(ctx, int(courseID))
+	if err != nil {
+		return nil, err
+	}
+
+	gradebook := &Gradebook{
+		CourseID: int(courseID),
+		Stories:  stories,
+		Students: []GradebookStudent{},
+	}
+	studentIndex := make(map[string]int)
+
+	for i, story := range stories {
+		rows, err := s.GetStoryStudentPerformance(ctx, int32(story.Metadata.StoryID), status)
+		if err != nil {
+			return nil, err
+		}
+		scores, err := s.GetStoryLatestScores(ctx, story.Metadata.StoryID)
+		if err != nil {
+			return nil, err
+		}
+		late := make(map[string]bool, len(scores))
+		for _, score := range scores {
+			late[score.UserID] = score.Late
+		}
+
+		// Rows are ordered by name, so students keep that order
+		for _, row := range rows {
+			index, ok := studentIndex[row.UserID]
+			if !ok {
+				index = len(gradebook.Students)
+				studentIndex[row.UserID] = index
+				gradebook.Students = append(gradebook.Students, GradebookStudent{
+					UserID:   row.UserID,
+					UserName: row.UserName,
+					Email:    row.Email,
+					Stories:  make([]CourseStudentPerformance, len(stories)),
+					Late:     make([]bool, len(stories)),
+				})
+			}
+			gradebook.Students[index].Stories[i] = row
+			gradebook.Students[index].Late[i] = late[row.UserID]
+		}
+	}
+
+	return gradebook, nil
+}
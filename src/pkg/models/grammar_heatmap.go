@@ -0,0 +1,205 @@
+package models
+
+import (
+	"context"
+	"unicode"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// HeatmapClickCap caps GrammarHeatmap.MaxClicks so a few heavily clicked characters
+// do not wash out the rest of the story
+const HeatmapClickCap = 10
+
+// GrammarHeatmap shows where students clicked when looking for a grammar point.
+// Correct answers count on every character of the point's instances on the line they
+// were given for; wrong selections count on the exact characters selected.
+type GrammarHeatmap struct {
+	StoryID          int           `json:"storyId"`
+	GrammarPointID   int           `json:"grammarPointId"`
+	GrammarPointName string        `json:"grammarPointName"`
+	MaxClicks        int           `json:"maxClicks"`       // Most clicks on one character, capped at HeatmapClickCap
+	CorrectClicks    int           `json:"correctClicks"`   // Correct answers recorded
+	IncorrectClicks  int           `json:"incorrectClicks"` // Wrong characters selected
+	Lines            []HeatmapLine `json:"lines"`
+}
+
+// HeatmapLine is one story line of a heatmap, one entry per character (rune) of its text
+type HeatmapLine struct {
+	LineNumber int                `json:"lineNumber"`
+	Text       string             `json:"text"`
+	RTL        bool               `json:"rtl"`
+	Characters []HeatmapCharacter `json:"characters"`
+}
+
+// HeatmapCharacter counts the clicks on one character. Intensity is the larger of the two
+// counts relative to the heatmap's MaxClicks, from 0 to 1.
+type HeatmapCharacter struct {
+	Char      string  `json:"char"`
+	Correct   int     `json:"correct"`
+	Incorrect int     `json:"incorrect"`
+	Intensity float64 `json:"intensity"`
+}
+
+// GetGrammarHeatmap builds the click heatmap of one grammar point of a story.
+// It returns ErrNotFound when the grammar point belongs to another story.
+func (s *Service) GetGrammarHeatmap(ctx context.Context, storyID, grammarPointID int) (*GrammarHeatmap, error) {
+	point, err := s.GetGrammarPoint(ctx, grammarPointID)
+	if err != nil {
+		return nil, err
+	}
+	if point.StoryID != storyID {
+		return nil, ErrNotFound
+	}
+
+	lines, items, err := s.heatmapStory(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.grammarHeatmap(ctx, *point, lines, items)
+}
+
+// GetStoryGrammarHeatmaps builds the click heatmap of every grammar point of a story
+func (s *Service) GetStoryGrammarHeatmaps(ctx context.Context, storyID int) ([]GrammarHeatmap, error) {
+	points, err := s.GetStoryGrammarPoints(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	lines, items, err := s.heatmapStory(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmaps := make([]GrammarHeatmap, 0, len(points))
+	for _, point := range points {
+		heatmap, err := s.grammarHeatmap(ctx, point, lines, items)
+		if err != nil {
+			return nil, err
+		}
+		heatmaps = append(heatmaps, *heatmap)
+	}
+	return heatmaps, nil
+}
+
+// heatmapStory loads the lines and grammar items heatmaps are laid over
+func (s *Service) heatmapStory(ctx context.Context, storyID int) ([]db.StoryLine, []db.GetAllGrammarForStoryRow, error) {
+	lines, err := s.queries.GetStoryLines(ctx, int32(storyID))
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.queries.GetAllGrammarForStory(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return lines, items, nil
+}
+
+func (s *Service) grammarHeatmap(ctx context.Context, point GrammarPoint, lines []db.StoryLine, items []db.GetAllGrammarForStoryRow) (*GrammarHeatmap, error) {
+	correct, err := s.queries.GetGrammarPointCorrectClicks(ctx, db.GetGrammarPointCorrectClicksParams{
+		StoryID:        int32(point.StoryID),
+		GrammarPointID: int32(point.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	incorrect, err := s.queries.GetGrammarPointIncorrectClicks(ctx, db.GetGrammarPointIncorrectClicksParams{
+		StoryID:        int32(point.StoryID),
+		GrammarPointID: int32(point.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildGrammarHeatmap(point, lines, items, correct, incorrect), nil
+}
+
+// buildGrammarHeatmap lays the click counts of a grammar point over the story text.
+// Positions are rune offsets into the line text; those outside the line are ignored.
+func buildGrammarHeatmap(point GrammarPoint, lines []db.StoryLine, items []db.GetAllGrammarForStoryRow,
+	correct []db.GetGrammarPointCorrectClicksRow, incorrect []db.GetGrammarPointIncorrectClicksRow) *GrammarHeatmap {
+	heatmap := &GrammarHeatmap{
+		StoryID:          point.StoryID,
+		GrammarPointID:   point.ID,
+		GrammarPointName: point.Name,
+		Lines:            make([]HeatmapLine, len(lines)),
+	}
+
+	lineIndex := make(map[int32]int, len(lines))
+	for i, line := range lines {
+		lineIndex[line.LineNumber] = i
+		runes := []rune(line.Text)
+		heatmapLine := HeatmapLine{
+			LineNumber: int(line.LineNumber),
+			Text:       line.Text,
+			RTL:        isRTL(runes),
+			Characters: make([]HeatmapCharacter, len(runes)),
+		}
+		for j, r := range runes {
+			heatmapLine.Characters[j].Char = string(r)
+		}
+		heatmap.Lines[i] = heatmapLine
+	}
+
+	correctByLine := make(map[int32]int, len(correct))
+	for _, row := range correct {
+		correctByLine[row.LineNumber] = int(row.Clicks)
+		heatmap.CorrectClicks += int(row.Clicks)
+	}
+	for _, item := range items {
+		if !item.GrammarPointID.Valid || int(item.GrammarPointID.Int32) != point.ID {
+			continue
+		}
+		index, ok := lineIndex[item.LineNumber.Int32]
+		clicks := correctByLine[item.LineNumber.Int32]
+		if !ok || clicks == 0 {
+			continue
+		}
+		characters := heatmap.Lines[index].Characters
+		for pos := max(int(item.PositionStart), 0); pos < min(int(item.PositionEnd), len(characters)); pos++ {
+			characters[pos].Correct += clicks
+		}
+	}
+
+	for _, row := range incorrect {
+		heatmap.IncorrectClicks += int(row.Clicks)
+		index, ok := lineIndex[row.LineNumber]
+		if !ok {
+			continue
+		}
+		characters := heatmap.Lines[index].Characters
+		if row.Position >= 0 && int(row.Position) < len(characters) {
+			characters[row.Position].Incorrect += int(row.Clicks)
+		}
+	}
+
+	for _, line := range heatmap.Lines {
+		for _, character := range line.Characters {
+			heatmap.MaxClicks = max(heatmap.MaxClicks, character.Correct+character.Incorrect)
+		}
+	}
+	heatmap.MaxClicks = min(heatmap.MaxClicks, HeatmapClickCap)
+
+	if heatmap.MaxClicks > 0 {
+		for _, line := range heatmap.Lines {
+			for j := range line.Characters {
+				character := &line.Characters[j]
+				character.Intensity = min(float64(max(character.Correct, character.Incorrect))/float64(heatmap.MaxClicks), 1)
+			}
+		}
+	}
+	return heatmap
+}
+
+// isRTL reports whether text is written right to left, judged by its first strong letter
+func isRTL(text []rune) bool {
+	for _, r := range text {
+		if unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana) {
+			return true
+		}
+		if unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"glossias/src/pkg/generated/db"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestBuildGrammarHeatmap(t *testing.T) {
+	point := GrammarPoint{ID: 3, StoryID: 42, Name: "Past tense"}
+	lines := []db.StoryLine{
+		{StoryID: 42, LineNumber: 1, Text: "él fue"},
+		{StoryID: 42, LineNumber: 2, Text: "ya"},
+	}
+	items := []db.GetAllGrammarForStoryRow{
+		{LineNumber: pgtype.Int4{Int32: 1, Valid: true}, GrammarPointID: pgtype.Int4{Int32: 3, Valid: true}, PositionStart: 3, PositionEnd: 6},
+		{LineNumber: pgtype.Int4{Int32: 2, Valid: true}, GrammarPointID: pgtype.Int4{Int32: 4, Valid: true}, PositionStart: 0, PositionEnd: 2}, // Other grammar point
+		{LineNumber: pgtype.Int4{Int32: 2, Valid: true}, GrammarPointID: pgtype.Int4{Int32: 3, Valid: true}, PositionStart: 1, PositionEnd: 9}, // Runs past the line
+	}
+	correct := []db.GetGrammarPointCorrectClicksRow{
+		{LineNumber: 1, Clicks: 4},
+		{LineNumber: 2, Clicks: 1},
+	}
+	incorrect := []db.GetGrammarPointIncorrectClicksRow{
+		{LineNumber: 1, Position: 0, Clicks: 2}, // The accented é, one rune
+		{LineNumber: 1, Position: 4, Clicks: 4},
+		{LineNumber: 1, Position: 50, Clicks: 1}, // Outside the line
+		{LineNumber: 9, Position: 0, Clicks: 1},  // Not a story line
+	}
+
+	heatmap := buildGrammarHeatmap(point, lines, items, correct, incorrect)
+
+	if heatmap.CorrectClicks != 5 || heatmap.IncorrectClicks != 8 {
+		t.Errorf("Expected 5 correct and 8 incorrect clicks, got %d and %d", heatmap.CorrectClicks, heatmap.IncorrectClicks)
+	}
+	if heatmap.MaxClicks != 8 {
+		t.Errorf("Expected max clicks 8, got %d", heatmap.MaxClicks)
+	}
+
+	first := heatmap.Lines[0].Characters
+	if len(first) != 6 || first[0].Char != "é" {
+		t.Fatalf("Expected one character per rune, got %+v", first)
+	}
+	if first[0].Incorrect != 2 || first[0].Correct != 0 || first[0].Intensity != 0.25 {
+		t.Errorf("Unexpected counts on é: %+v", first[0])
+	}
+	if first[4].Correct != 4 || first[4].Incorrect != 4 || first[4].Intensity != 0.5 {
+		t.Errorf("Unexpected counts on u: %+v", first[4])
+	}
+	if first[2].Correct != 0 || first[3].Correct != 4 || first[5].Correct != 4 {
+		t.Errorf("Expected correct clicks on the grammar point only, got %+v", first)
+	}
+
+	second := heatmap.Lines[1].Characters
+	if second[0].Correct != 0 || second[1].Correct != 1 {
+		t.Errorf("Expected only this grammar point's span to count, got %+v", second)
+	}
+}
+
+func TestBuildGrammarHeatmapCapsClicks(t *testing.T) {
+	point := GrammarPoint{ID: 1, StoryID: 1}
+	lines := []db.StoryLine{{LineNumber: 1, Text: "ab"}}
+	incorrect := []db.GetGrammarPointIncorrectClicksRow{
+		{LineNumber: 1, Position: 0, Clicks: 40},
+		{LineNumber: 1, Position: 1, Clicks: 5},
+	}
+
+	heatmap := buildGrammarHeatmap(point, lines, nil, nil, incorrect)
+
+	if heatmap.MaxClicks != HeatmapClickCap {
+		t.Errorf("Expected max clicks capped at %d, got %d", HeatmapClickCap, heatmap.MaxClicks)
+	}
+	characters := heatmap.Lines[0].Characters
+	if characters[0].Intensity != 1 || characters[1].Intensity != 0.5 {
+		t.Errorf("Unexpected intensities %+v", characters)
+	}
+
+	// No clicks at all must not divide by zero
+	empty := buildGrammarHeatmap(point, lines, nil, nil, nil)
+	if empty.MaxClicks != 0 || empty.Lines[0].Characters[0].Intensity != 0 {
+		t.Errorf("Expected an empty heatmap, got %+v", empty)
+	}
+}
+
+func TestIsRTL(t *testing.T) {
+	tests := map[string]bool{
+		"בְּרֵאשִׁית בָּרָא": true,
+		"1. שָׁלוֹם":         true,
+		"السلام عليكم":       true,
+		"Hello שָׁלוֹם":      false,
+		"¿Dónde está?":       false,
+		"":                   false,
+		"123 456":            false,
+	}
+	for text, expected := range tests {
+		if got := isRTL([]rune(text)); got != expected {
+			t.Errorf("isRTL(%q) = %v, expected %v", text, got, expected)
+		}
+	}
+}
@@ -11,8 +11,23 @@ import (
 )
 
 // CreateGrammarPoint creates a new grammar point for a specific story
-func CreateGrammarPoint(ctx context.Context, storyID int, name, description string) (*GrammarPoint, error) {
-	result, err := queries.CreateGrammarPoint(ctx, db.CreateGrammarPointParams{
+func (s *Service) CreateGrammarPoint(ctx context.Context, storyID int, name, description string) (*GrammarPoint, error) {
+	var grammarPoint *GrammarPoint
+	err := s.withStoryRevision(ctx, storyID, "add grammar point", func(txCtx context.Context) error {
+		var err error
+		grammarPoint, err = s.createGrammarPoint(txCtx, storyID, name, description)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grammarPoint, nil
+}
+
+// createGrammarPoint creates a grammar point without recording a revision, for writes
+// that record their own
+func (s *Service) createGrammarPoint(ctx context.Context, storyID int, name, description string) (*GrammarPoint, error) {
+	result, err := s.queries.CreateGrammarPoint(ctx, db.CreateGrammarPointParams{
 		StoryID:     int32(storyID),
 		Name:        name,
 		Description: pgtype.Text{String: description, Valid: description != ""},
@@ -30,8 +45,8 @@ func CreateGrammarPoint(ctx context.Context, storyID int, name, description stri
 }
 
 // GetGrammarPoint retrieves a grammar point by ID
-func GetGrammarPoint(ctx context.Context, grammarPointID int) (*GrammarPoint, error) {
-	result, err := queries.GetGrammarPoint(ctx, int32(grammarPointID))
+func (s *Service) GetGrammarPoint(ctx context.Context, grammarPointID int) (*GrammarPoint, error) {
+	result, err := s.queries.GetGrammarPoint(ctx, int32(grammarPointID))
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -48,8 +63,8 @@ func GetGrammarPoint(ctx context.Context, grammarPointID int) (*GrammarPoint, er
 }
 
 // GetGrammarPointByName retrieves a grammar point by name and story ID
-func GetGrammarPointByName(ctx context.Context, name string, storyID int) (*GrammarPoint, error) {
-	result, err := queries.GetGrammarPointByName(ctx, db.GetGrammarPointByNameParams{
+func (s *Service) GetGrammarPointByName(ctx context.Context, name string, storyID int) (*GrammarPoint, error) {
+	result, err := s.queries.GetGrammarPointByName(ctx, db.GetGrammarPointByNameParams{
 		Name:    name,
 		StoryID: int32(storyID),
 	})
@@ -69,8 +84,8 @@ func GetGrammarPointByName(ctx context.Context, name string, storyID int) (*Gram
 }
 
 // ListGrammarPoints returns all grammar points
-func ListGrammarPoints(ctx context.Context) ([]GrammarPoint, error) {
-	results, err := queries.ListGrammarPoints(ctx)
+func (s *Service) ListGrammarPoints(ctx context.Context) ([]GrammarPoint, error) {
+	results, err := s.queries.ListGrammarPoints(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -89,8 +104,25 @@ func ListGrammarPoints(ctx context.Context) ([]GrammarPoint, error) {
 }
 
 // UpdateGrammarPoint updates an existing grammar point
-func UpdateGrammarPoint(ctx context.Context, grammarPointID int, name, description string) (*GrammarPoint, error) {
-	result, err := queries.UpdateGrammarPoint(ctx, db.UpdateGrammarPointParams{
+func (s *Service) UpdateGrammarPoint(ctx context.Context, grammarPointID int, name, description string) (*GrammarPoint, error) {
+	var grammarPoint *GrammarPoint
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		grammarPoint, err = s.updateGrammarPoint(txCtx, grammarPointID, name, description)
+		if err != nil {
+			return err
+		}
+		return s.recordStoryRevision(txCtx, grammarPoint.StoryID, "update grammar point")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grammarPoint, nil
+}
+
+// updateGrammarPoint updates a grammar point without recording a revision
+func (s *Service) updateGrammarPoint(ctx context.Context, grammarPointID int, name, description string) (*GrammarPoint, error) {
+	result, err := s.queries.UpdateGrammarPoint(ctx, db.UpdateGrammarPointParams{
 		GrammarPointID: int32(grammarPointID),
 		Name:           name,
 		Description:    pgtype.Text{String: description, Valid: description != ""},
@@ -111,17 +143,19 @@ func UpdateGrammarPoint(ctx context.Context, grammarPointID int, name, descripti
 }
 
 // DeleteGrammarPoint deletes a grammar point
-func DeleteGrammarPoint(ctx context.Context, grammarPointID int) error {
-	err := queries.DeleteGrammarPoint(ctx, int32(grammarPointID))
-	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
-		return ErrNotFound
+func (s *Service) DeleteGrammarPoint(ctx context.Context, grammarPointID int) error {
+	grammarPoint, err := s.GetGrammarPoint(ctx, grammarPointID)
+	if err != nil {
+		return err
 	}
-	return err
+	return s.withStoryRevision(ctx, grammarPoint.StoryID, "delete grammar point", func(txCtx context.Context) error {
+		return s.queries.DeleteGrammarPoint(txCtx, int32(grammarPointID))
+	})
 }
 
 // GetStoryGrammarPoints returns all grammar points for a story
-func GetStoryGrammarPoints(ctx context.Context, storyID int) ([]GrammarPoint, error) {
-	results, err := queries.GetStoryGrammarPoints(ctx, int32(storyID))
+func (s *Service) GetStoryGrammarPoints(ctx context.Context, storyID int) ([]GrammarPoint, error) {
+	results, err := s.queries.GetStoryGrammarPoints(ctx, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
@@ -140,8 +174,8 @@ func GetStoryGrammarPoints(ctx context.Context, storyID int) ([]GrammarPoint, er
 }
 
 // GetStoriesWithGrammarPoint returns all stories that use a specific grammar point
-func GetStoriesWithGrammarPoint(ctx context.Context, grammarPointID int) ([]Story, error) {
-	results, err := queries.GetStoriesWithGrammarPoint(ctx, int32(grammarPointID))
+func (s *Service) GetStoriesWithGrammarPoint(ctx context.Context, grammarPointID int) ([]Story, error) {
+	results, err := s.queries.GetStoriesWithGrammarPoint(ctx, int32(grammarPointID))
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +208,49 @@ func GetStoriesWithGrammarPoint(ctx context.Context, grammarPointID int) ([]Stor
 }
 
 // ClearStoryGrammarPoints removes all grammar points from a story
-func ClearStoryGrammarPoints(ctx context.Context, storyID int) error {
-	return queries.ClearStoryGrammarPoints(ctx, int32(storyID))
+func (s *Service) ClearStoryGrammarPoints(ctx context.Context, storyID int) error {
+	return s.queries.ClearStoryGrammarPoints(ctx, int32(storyID))
+}
+
+// replaceStoryGrammarPoints makes the story's grammar points those given, without recording
+// a revision. Points with the ID of an existing point update it in place, so the grammar
+// items, answers and produce segments linking to it are kept; points with any other ID are
+// created and existing points that are not given are deleted.
+func (s *Service) replaceStoryGrammarPoints(ctx context.Context, storyID int, grammarPoints []GrammarPoint) error {
+	current, err := s.GetStoryGrammarPoints(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]GrammarPoint, len(current))
+	for _, gp := range current {
+		byID[gp.ID] = gp
+	}
+
+	kept := make(map[int]bool, len(grammarPoints))
+	for _, gp := range grammarPoints {
+		existing, ok := byID[gp.ID]
+		if !ok || kept[gp.ID] {
+			if _, err := s.createGrammarPoint(ctx, storyID, gp.Name, gp.Description); err != nil {
+				return err
+			}
+			continue
+		}
+		kept[gp.ID] = true
+		if existing.Name == gp.Name && existing.Description == gp.Description {
+			continue
+		}
+		if _, err := s.updateGrammarPoint(ctx, gp.ID, gp.Name, gp.Description); err != nil {
+			return err
+		}
+	}
+
+	for _, gp := range current {
+		if kept[gp.ID] {
+			continue
+		}
+		if err := s.queries.DeleteGrammarPoint(ctx, int32(gp.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"glossias/src/pkg/database"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestReplaceStoryGrammarPointsKeepsIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []GrammarPoint
+		wantErr string
+	}{
+		{"renamed in place", []GrammarPoint{{ID: 1, Name: "Past tense"}, {ID: 2, Name: "Plural nouns"}}, ""},
+		{"removed", []GrammarPoint{{ID: 1, Name: "Past tense"}}, "deleted"},
+		{"added", []GrammarPoint{{ID: 1, Name: "Past tense"}, {ID: 2, Name: "Plural"}, {ID: 1760000000000, Name: "Imperative"}}, "created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := database.NewMockDBTX()
+			mock.StubQuery("-- name: GetStoryGrammarPoints", [][]interface{}{
+				{int32(1), "Past tense", pgtype.Text{}},
+				{int32(2), "Plural", pgtype.Text{}},
+			}, nil)
+			mock.StubQuery("-- name: UpdateGrammarPoint", [][]interface{}{
+				{int32(2), int32(7), "Plural nouns", pgtype.Text{}, pgtype.Timestamp{}},
+			}, nil)
+			// Failing writes show which ones ran
+			mock.StubQuery("-- name: CreateGrammarPoint", nil, errors.New("created"))
+			mock.StubExec("-- name: DeleteGrammarPoint", errors.New("deleted"))
+			mock.StubExec("-- name: ClearStoryGrammarPoints", errors.New("cleared"))
+			svc := NewService(mock, nil, nil, nil)
+
+			err := svc.replaceStoryGrammarPoints(context.Background(), 7, tt.points)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
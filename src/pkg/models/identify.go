@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrIdentifyOptionExpired is returned for a picture that is not part of the user's
+// current Identify attempt, usually because the page was opened again elsewhere
+var ErrIdentifyOptionExpired = errors.New("identify option is not part of the current attempt")
+
+// SaveIdentifyScore records one Identify picture pick. lineNumber is 1-indexed.
+// A wrong pick is logged with the target word the student chose instead.
+func (s *Service) SaveIdentifyScore(ctx context.Context, userID string, storyID, lineNumber, targetVocabID int, correct bool, selectedTargetVocabID int) error {
+	if correct {
+		return s.answersSaved(ctx, storyID, s.queries.SaveIdentifyScore(ctx, db.SaveIdentifyScoreParams{
+			UserID:        userID,
+			StoryID:       int32(storyID),
+			LineNumber:    int32(lineNumber),
+			TargetVocabID: int32(targetVocabID),
+		}))
+	}
+
+	return s.answersSaved(ctx, storyID, s.queries.SaveIdentifyIncorrectAnswer(ctx, db.SaveIdentifyIncorrectAnswerParams{
+		UserID:                userID,
+		StoryID:               int32(storyID),
+		LineNumber:            int32(lineNumber),
+		TargetVocabID:         int32(targetVocabID),
+		SelectedTargetVocabID: int32(selectedTargetVocabID),
+	}))
+}
+
+// GetUserIdentifyScores returns the target words a user has correctly identified,
+// as map[lineNumber]map[targetVocabID]true
+func (s *Service) GetUserIdentifyScores(ctx context.Context, userID string, storyID int) (map[int]map[int]bool, error) {
+	results, err := s.queries.GetUserIdentifyScores(ctx, db.GetUserIdentifyScoresParams{
+		UserID:  userID,
+		StoryID: int32(storyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int]map[int]bool)
+	for _, result := range results {
+		lineNumber := int(result.LineNumber)
+		if scores[lineNumber] == nil {
+			scores[lineNumber] = make(map[int]bool)
+		}
+		scores[lineNumber][int(result.TargetVocabID)] = true
+	}
+	return scores, nil
+}
+
+// StartIdentifyAttempt replaces the user's Identify options for a story with one option per
+// target word, each under a new opaque ID. Options are returned in the order of targets;
+// the mapping back to target words stays in identify_options.
+func (s *Service) StartIdentifyAttempt(ctx context.Context, userID string, storyID int, targets []SignedTargetVocab) ([]IdentifyOption, error) {
+	var options []IdentifyOption
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		options = make([]IdentifyOption, 0, len(targets))
+		if err := s.queries.DeleteIdentifyOptions(txCtx, db.DeleteIdentifyOptionsParams{
+			UserID:  userID,
+			StoryID: int32(storyID),
+		}); err != nil {
+			return err
+		}
+		for _, target := range targets {
+			optionID := rand.Text()
+			if err := s.queries.CreateIdentifyOption(txCtx, db.CreateIdentifyOptionParams{
+				OptionID:      optionID,
+				UserID:        userID,
+				StoryID:       int32(storyID),
+				TargetVocabID: int32(target.ID),
+			}); err != nil {
+				return err
+			}
+			options = append(options, IdentifyOption{ID: optionID, ImageURL: target.ImageURL})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// ResolveIdentifyOption returns the target vocabulary ID behind an option of the user's
+// current Identify attempt for a story
+func (s *Service) ResolveIdentifyOption(ctx context.Context, userID string, storyID int, optionID string) (int, error) {
+	targetID, err := s.queries.GetIdentifyOptionTarget(ctx, db.GetIdentifyOptionTargetParams{
+		OptionID: optionID,
+		UserID:   userID,
+		StoryID:  int32(storyID),
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return 0, ErrIdentifyOptionExpired
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(targetID), nil
+}
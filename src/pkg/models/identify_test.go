@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/database"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStartIdentifyAttempt(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	targets := []SignedTargetVocab{
+		{TargetVocab: TargetVocab{ID: 11, LexicalForm: "כלב"}, ImageURL: "https://example.com/dog.png"},
+		{TargetVocab: TargetVocab{ID: 12, LexicalForm: "חתול"}, ImageURL: "https://example.com/cat.png"},
+	}
+
+	options, err := svc.StartIdentifyAttempt(context.Background(), "user-1", 42, targets)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(options) != len(targets) {
+		t.Fatalf("Expected %d options, got %d", len(targets), len(options))
+	}
+	seen := map[string]bool{}
+	for i, option := range options {
+		if option.ImageURL != targets[i].ImageURL {
+			t.Errorf("Expected option %d to show %s, got %s", i, targets[i].ImageURL, option.ImageURL)
+		}
+		if option.ID == "" || seen[option.ID] {
+			t.Errorf("Expected a unique option ID, got %q", option.ID)
+		}
+		if strings.Contains(option.ID, strconv.Itoa(targets[i].ID)) {
+			t.Errorf("Expected option ID %q not to reveal target %d", option.ID, targets[i].ID)
+		}
+		seen[option.ID] = true
+	}
+
+	again, err := svc.StartIdentifyAttempt(context.Background(), "user-1", 42, targets)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again[0].ID == options[0].ID {
+		t.Error("Expected a new attempt to get new option IDs")
+	}
+}
+
+func TestResolveIdentifyOption(t *testing.T) {
+	mock := database.NewMockDBTX()
+	svc := NewService(mock, nil, nil, nil)
+
+	if _, err := svc.ResolveIdentifyOption(context.Background(), "user-1", 42, "stale"); err != ErrIdentifyOptionExpired {
+		t.Errorf("Expected ErrIdentifyOptionExpired for an unknown option, got %v", err)
+	}
+
+	mock.StubQuery("-- name: GetIdentifyOptionTarget", [][]interface{}{{int32(12)}}, nil)
+	targetID, err := svc.ResolveIdentifyOption(context.Background(), "user-1", 42, "current")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if targetID != 12 {
+		t.Errorf("Expected target 12, got %d", targetID)
+	}
+}
@@ -0,0 +1,253 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"glossias/src/pkg/generated/db"
+	"glossias/src/pkg/storage"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// ImageBucket is the storage bucket for all image assets
+	ImageBucket = "images"
+	// MaxImageBytes is the largest image accepted for upload
+	MaxImageBytes = 5 << 20
+)
+
+// allowedImageTypes maps accepted content types to their file extensions
+var allowedImageTypes = map[string][]string{
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/png":  {".png"},
+	"image/webp": {".webp"},
+	"image/gif":  {".gif"},
+}
+
+var (
+	ErrInvalidImageType  = errors.New("image must be a JPEG, PNG, WebP or GIF file")
+	ErrImageTooLarge     = fmt.Errorf("image must be at most %d MB", MaxImageBytes>>20)
+	ErrImageNotUploaded  = errors.New("image was not found in storage")
+	ErrInvalidImageLabel = errors.New("image label is required and may not contain slashes")
+)
+
+// ValidateImageLabel checks that a label can be part of an image's storage path
+func ValidateImageLabel(label string) error {
+	if label == "" || strings.ContainsAny(label, `/\`) {
+		return ErrInvalidImageLabel
+	}
+	return nil
+}
+
+// ValidateImageUpload checks the declared content type, file extension and size of an image
+// before an upload URL is issued. The size is checked again against storage on confirm.
+func ValidateImageUpload(fileName, contentType string, size int64) error {
+	extensions, ok := allowedImageTypes[strings.ToLower(contentType)]
+	if !ok {
+		return ErrInvalidImageType
+	}
+	ext := strings.ToLower(path.Ext(fileName))
+	validExt := false
+	for _, allowed := range extensions {
+		if ext == allowed {
+			validExt = true
+			break
+		}
+	}
+	if !validExt {
+		return ErrInvalidImageType
+	}
+	if size <= 0 || size > MaxImageBytes {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// VerifyUploadedImage checks that an uploaded image exists in storage and is within the size limit,
+// removing it if it is too large. Returns the stored size.
+func (s *Service) VerifyUploadedImage(fileBucket, filePath string) (int64, error) {
+	if s.storage == nil {
+		return 0, errors.New("storage client not initialized")
+	}
+
+	object, err := s.storage.Stat(fileBucket, filePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, ErrImageNotUploaded
+	}
+	if err != nil {
+		return 0, err
+	}
+	if object.Size > MaxImageBytes {
+		if err := s.storage.Remove(fileBucket, []string{filePath}); err != nil {
+			fmt.Printf("Failed to remove oversized image %s: %v\n", filePath, err)
+		}
+		return 0, ErrImageTooLarge
+	}
+	return object.Size, nil
+}
+
+// CreateStoryImage records an image that has been uploaded to storage
+func (s *Service) CreateStoryImage(ctx context.Context, storyID int, filePath, fileBucket, label, contentType string) (*StoryImage, error) {
+	if err := ValidateImageLabel(label); err != nil {
+		return nil, err
+	}
+	size, err := s.VerifyUploadedImage(fileBucket, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var result db.StoryImage
+	err = s.withStoryRevision(ctx, storyID, "add image", func(txCtx context.Context) error {
+		var err error
+		result, err = s.queries.CreateStoryImage(txCtx, db.CreateStoryImageParams{
+			StoryID:     int32(storyID),
+			FilePath:    filePath,
+			FileBucket:  fileBucket,
+			Label:       label,
+			ContentType: strings.ToLower(contentType),
+			SizeBytes:   size,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	image := storyImageFromDB(result)
+	return &image, nil
+}
+
+// GetStoryImage retrieves an image by ID
+func (s *Service) GetStoryImage(ctx context.Context, imageID int) (*StoryImage, error) {
+	result, err := s.queries.GetStoryImage(ctx, int32(imageID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	image := storyImageFromDB(result)
+	return &image, nil
+}
+
+// GetStoryImages retrieves all images for a story
+func (s *Service) GetStoryImages(ctx context.Context, storyID int) ([]StoryImage, error) {
+	results, err := s.queries.GetStoryImages(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]StoryImage, 0, len(results))
+	for _, result := range results {
+		images = append(images, storyImageFromDB(result))
+	}
+	return images, nil
+}
+
+// DeleteStoryImage deletes an image from storage and the database
+func (s *Service) DeleteStoryImage(ctx context.Context, imageID int) error {
+	image, err := s.GetStoryImage(ctx, imageID)
+	if err != nil {
+		return err
+	}
+
+	// Delete from storage first
+	if err := s.removeStorageObject(ctx, image.StoryID, image.FileBucket, image.FilePath); err != nil {
+		return err
+	}
+
+	return s.withStoryRevision(ctx, image.StoryID, "delete image", func(txCtx context.Context) error {
+		return s.queries.DeleteStoryImage(txCtx, int32(imageID))
+	})
+}
+
+// GetSignedImageURL generates a signed read URL for an image
+func (s *Service) GetSignedImageURL(ctx context.Context, imageID int, userID string, expiresInSeconds int) (string, error) {
+	if s.storage == nil {
+		return "", errors.New("storage client not initialized")
+	}
+
+	image, err := s.GetStoryImage(ctx, imageID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkStoryAccess(ctx, image.StoryID, userID); err != nil {
+		return "", err
+	}
+
+	return s.storage.SignReadURL(image.FileBucket, image.FilePath, expiresInSeconds)
+}
+
+// GetSignedImageURLsForStory generates signed read URLs for all images in a story, keyed by image ID
+func (s *Service) GetSignedImageURLsForStory(ctx context.Context, storyID int, userID string, expiresInSeconds int) (map[int]string, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage client not initialized")
+	}
+
+	if err := s.checkStoryAccess(ctx, storyID, userID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.GetStoryImages(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	signedURLs := make(map[int]string, len(images))
+	for _, image := range images {
+		signedURL, err := s.storage.SignReadURL(image.FileBucket, image.FilePath, expiresInSeconds)
+		if err != nil {
+			return nil, err
+		}
+		signedURLs[image.ID] = signedURL
+	}
+	return signedURLs, nil
+}
+
+// SignAssetURL generates a signed read URL for an object referenced by another record,
+// such as a target vocabulary picture. Callers are responsible for access checks.
+func (s *Service) SignAssetURL(fileBucket, filePath string, expiresInSeconds int) (string, error) {
+	if s.storage == nil {
+		return "", errors.New("storage client not initialized")
+	}
+	if filePath == "" {
+		return "", nil
+	}
+	return s.storage.SignReadURL(fileBucket, filePath, expiresInSeconds)
+}
+
+// checkStoryAccess returns an error unless the user can access the story's course
+func (s *Service) checkStoryAccess(ctx context.Context, storyID int, userID string) error {
+	story, err := s.queries.GetStory(ctx, int32(storyID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.rememberStoryCourse(storyID, story.CourseID)
+
+	if story.CourseID.Valid && !s.CanUserAccessCourse(ctx, userID, story.CourseID.Int32) {
+		return errors.New("access denied")
+	}
+	return nil
+}
+
+func storyImageFromDB(result db.StoryImage) StoryImage {
+	return StoryImage{
+		ID:          int(result.ImageID),
+		StoryID:     int(result.StoryID),
+		FilePath:    result.FilePath,
+		FileBucket:  result.FileBucket,
+		Label:       result.Label,
+		ContentType: result.ContentType,
+		SizeBytes:   result.SizeBytes,
+	}
+}
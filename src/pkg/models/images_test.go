@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"glossias/src/pkg/storage"
+)
+
+// memStorage is a storage.Storage that tracks object sizes, and bytes of uploaded objects
+type memStorage struct {
+	objects map[string]int64  // bucket/path -> size
+	data    map[string][]byte // bucket/path -> bytes, for objects written with Upload
+	removed []string
+}
+
+func (m *memStorage) SignUploadURL(bucket, path string) (string, error) {
+	return "upload://" + bucket + "/" + path, nil
+}
+
+func (m *memStorage) SignReadURL(bucket, path string, expiresInSeconds int) (string, error) {
+	return "read://" + bucket + "/" + path, nil
+}
+
+func (m *memStorage) Remove(bucket string, paths []string) error {
+	for _, p := range paths {
+		delete(m.objects, bucket+"/"+p)
+		m.removed = append(m.removed, bucket+"/"+p)
+	}
+	return nil
+}
+
+func (m *memStorage) Download(bucket, path string) ([]byte, error) {
+	data, ok := m.data[bucket+"/"+path]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return data, nil
+}
+
+func (m *memStorage) Upload(bucket, path string, data []byte, contentType string) error {
+	if m.data == nil {
+		m.data = make(map[string][]byte)
+	}
+	m.objects[bucket+"/"+path] = int64(len(data))
+	m.data[bucket+"/"+path] = data
+	return nil
+}
+
+func (m *memStorage) Stat(bucket, path string) (storage.Object, error) {
+	size, ok := m.objects[bucket+"/"+path]
+	if !ok {
+		return storage.Object{}, storage.ErrNotFound
+	}
+	return storage.Object{Name: path[strings.LastIndex(path, "/")+1:], Size: size}, nil
+}
+
+func (m *memStorage) List(bucket, prefix string) ([]storage.Object, error) {
+	var objects []storage.Object
+	for key, size := range m.objects {
+		dir := bucket + "/" + prefix + "/"
+		if len(key) > len(dir) && key[:len(dir)] == dir {
+			objects = append(objects, storage.Object{Name: key[len(dir):], Size: size})
+		}
+	}
+	return objects, nil
+}
+
+func TestValidateImageUpload(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		contentType string
+		size        int64
+		wantErr     error
+	}{
+		{"png", "dog.png", "image/png", 1024, nil},
+		{"uppercase jpeg", "DOG.JPG", "IMAGE/JPEG", 1024, nil},
+		{"svg rejected", "dog.svg", "image/svg+xml", 1024, ErrInvalidImageType},
+		{"extension mismatch", "dog.png", "image/jpeg", 1024, ErrInvalidImageType},
+		{"empty", "dog.png", "image/png", 0, ErrImageTooLarge},
+		{"too large", "dog.png", "image/png", MaxImageBytes + 1, ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateImageUpload(tt.fileName, tt.contentType, tt.size); err != tt.wantErr {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyUploadedImage(t *testing.T) {
+	store := &memStorage{objects: map[string]int64{
+		"images/stories/1/image_recall_1_ok.png":  2048,
+		"images/stories/1/image_recall_2_big.png": MaxImageBytes + 1,
+	}}
+	svc := NewService(nil, store, nil, nil)
+
+	size, err := svc.VerifyUploadedImage(ImageBucket, "stories/1/image_recall_1_ok.png")
+	if err != nil || size != 2048 {
+		t.Errorf("Expected size 2048, got %d (%v)", size, err)
+	}
+
+	if _, err := svc.VerifyUploadedImage(ImageBucket, "stories/1/image_recall_2_big.png"); err != ErrImageTooLarge {
+		t.Errorf("Expected ErrImageTooLarge, got %v", err)
+	}
+	if len(store.removed) != 1 {
+		t.Errorf("Expected the oversized image to be removed, removed %v", store.removed)
+	}
+
+	if _, err := svc.VerifyUploadedImage(ImageBucket, "stories/1/missing.png"); err != ErrImageNotUploaded {
+		t.Errorf("Expected ErrImageNotUploaded, got %v", err)
+	}
+}
+
+func TestCreateStoryImageRejectsNestedLabels(t *testing.T) {
+	store := &memStorage{objects: map[string]int64{
+		"images/stories/1/image_a/b_1_ok.png": 2048,
+	}}
+	svc := NewService(nil, store, nil, nil)
+
+	if _, err := svc.CreateStoryImage(context.Background(), 1, "stories/1/image_a/b_1_ok.png", ImageBucket, "a/b", "image/png"); err != ErrInvalidImageLabel {
+		t.Errorf("Expected ErrInvalidImageLabel, got %v", err)
+	}
+}
+
+func TestValidateImageLabel(t *testing.T) {
+	for label, want := range map[string]error{
+		"recall":      nil,
+		"dog picture": nil,
+		"":            ErrInvalidImageLabel,
+		"a/b":         ErrInvalidImageLabel,
+		`a\b`:         ErrInvalidImageLabel,
+	} {
+		if err := ValidateImageLabel(label); err != want {
+			t.Errorf("ValidateImageLabel(%q): expected %v, got %v", label, want, err)
+		}
+	}
+}
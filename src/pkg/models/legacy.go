@@ -0,0 +1,511 @@
+package models
+
+// Package-level wrappers around the default Service, kept so existing callers
+// keep working while they move to an injected *Service. New code should take
+// a *Service instead of calling these.
+
+import (
+	"context"
+
+	"glossias/src/pkg/generated/db"
+)
+
+// audio_files.go
+
+func CreateAudioFile(ctx context.Context, storyID, lineNumber int, filePath, fileBucket, label string) (*AudioFile, error) {
+	return defaultService.CreateAudioFile(ctx, storyID, lineNumber, filePath, fileBucket, label)
+}
+
+func GetAudioFile(ctx context.Context, audioFileID int) (*AudioFile, error) {
+	return defaultService.GetAudioFile(ctx, audioFileID)
+}
+
+func GetLineAudioFiles(ctx context.Context, storyID, lineNumber int) ([]AudioFile, error) {
+	return defaultService.GetLineAudioFiles(ctx, storyID, lineNumber)
+}
+
+func GetStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) ([]AudioFile, error) {
+	return defaultService.GetStoryAudioFilesByLabel(ctx, storyID, label)
+}
+
+func GetAllStoryAudioFiles(ctx context.Context, storyID int) ([]AudioFile, error) {
+	return defaultService.GetAllStoryAudioFiles(ctx, storyID)
+}
+
+func UpdateAudioFile(ctx context.Context, audioFileID int, storyID int, filePath, fileBucket, label string) (*AudioFile, error) {
+	return defaultService.UpdateAudioFile(ctx, audioFileID, storyID, filePath, fileBucket, label)
+}
+
+func DeleteAudioFile(ctx context.Context, audioFileID int) error {
+	return defaultService.DeleteAudioFile(ctx, audioFileID)
+}
+
+func DeleteLineAudioFiles(ctx context.Context, storyID, lineNumber int) error {
+	return defaultService.DeleteLineAudioFiles(ctx, storyID, lineNumber)
+}
+
+func DeleteStoryAudioFiles(ctx context.Context, storyID int) error {
+	return defaultService.DeleteStoryAudioFiles(ctx, storyID)
+}
+
+func DeleteStoryAudioFilesByLabel(ctx context.Context, storyID int, label string) error {
+	return defaultService.DeleteStoryAudioFilesByLabel(ctx, storyID, label)
+}
+
+func GetAudioFilesByLabel(ctx context.Context, label string) ([]AudioFile, error) {
+	return defaultService.GetAudioFilesByLabel(ctx, label)
+}
+
+func GetSignedAudioURL(ctx context.Context, audioFileID int, userID string, expiresInSeconds int) (string, error) {
+	return defaultService.GetSignedAudioURL(ctx, audioFileID, userID, expiresInSeconds)
+}
+
+func GetSignedAudioURLsForStory(ctx context.Context, storyID int, userID string, label string, expiresInSeconds int) (map[int]string, error) {
+	return defaultService.GetSignedAudioURLsForStory(ctx, storyID, userID, label, expiresInSeconds)
+}
+
+func GetSignedAudioURLsForLine(ctx context.Context, storyID, lineNumber int, userID string, expiresInSeconds int) (map[int]string, error) {
+	return defaultService.GetSignedAudioURLsForLine(ctx, storyID, lineNumber, userID, expiresInSeconds)
+}
+
+func StoryExists(ctx context.Context, storyID int32) (bool, error) {
+	return defaultService.StoryExists(ctx, storyID)
+}
+
+func LineExists(ctx context.Context, storyID, lineNumber int) (bool, error) {
+	return defaultService.LineExists(ctx, storyID, lineNumber)
+}
+
+func GenerateSignedUploadURL(ctx context.Context, bucket, filePath string) (string, error) {
+	return defaultService.GenerateSignedUploadURL(ctx, bucket, filePath)
+}
+
+// cache_invalidation.go
+
+func InvalidateStoryCache(storyID int, userID string) {
+	defaultService.InvalidateStoryCache(storyID, userID)
+}
+
+func InvalidateStoryMetadata(storyID int) {
+	defaultService.InvalidateStoryMetadata(storyID)
+}
+
+func InvalidateUserStoryCache(userID string, storyID int) {
+	defaultService.InvalidateUserStoryCache(userID, storyID)
+}
+
+func ClearAllCache() error {
+	return defaultService.ClearAllCache()
+}
+
+// course_performance.go
+
+func GetStoryCourseID(ctx context.Context, storyID int32) (int32, error) {
+	return defaultService.GetStoryCourseID(ctx, storyID)
+}
+
+func GetStoryStudentPerformance(ctx context.Context, storyID int32, status string) ([]CourseStudentPerformance, error) {
+	return defaultService.GetStoryStudentPerformance(ctx, storyID, status)
+}
+
+// course_users.go
+
+func AddUserToCourseByEmail(ctx context.Context, email string, courseID int) error {
+	return defaultService.AddUserToCourseByEmail(ctx, email, courseID)
+}
+
+func AddUserToCourseByEmailWithStatus(ctx context.Context, email string, courseID int, status string) error {
+	return defaultService.AddUserToCourseByEmailWithStatus(ctx, email, courseID, status)
+}
+
+func RemoveUserFromCourse(ctx context.Context, courseID int, userID string) error {
+	return defaultService.RemoveUserFromCourse(ctx, courseID, userID)
+}
+
+func UpdateCourseUserStatus(ctx context.Context, courseID int, userID string, status string) error {
+	return defaultService.UpdateCourseUserStatus(ctx, courseID, userID, status)
+}
+
+func BulkUpdateUserStatusInCourse(ctx context.Context, courseID int, userIDs []string, status string) error {
+	return defaultService.BulkUpdateUserStatusInCourse(ctx, courseID, userIDs, status)
+}
+
+func DeleteAllUsersFromCourse(ctx context.Context, courseID int) error {
+	return defaultService.DeleteAllUsersFromCourse(ctx, courseID)
+}
+
+func GetCoursesForUser(ctx context.Context, userID string) ([]UserCourse, error) {
+	return defaultService.GetCoursesForUser(ctx, userID)
+}
+
+func GetCoursesForUserByStatus(ctx context.Context, userID string, status string) ([]UserCourse, error) {
+	return defaultService.GetCoursesForUserByStatus(ctx, userID, status)
+}
+
+func GetUsersForCourse(ctx context.Context, courseID int) ([]CourseUser, error) {
+	return defaultService.GetUsersForCourse(ctx, courseID)
+}
+
+func MassImportUsersToCourse(ctx context.Context, courseID int, userEmails []string) ([]string, error) {
+	return defaultService.MassImportUsersToCourse(ctx, courseID, userEmails)
+}
+
+// courses.go
+
+func CreateCourse(ctx context.Context, courseNumber, name, description string) (*Course, error) {
+	return defaultService.CreateCourse(ctx, courseNumber, name, description)
+}
+
+func GetCourse(ctx context.Context, courseID int32) (*Course, error) {
+	return defaultService.GetCourse(ctx, courseID)
+}
+
+func GetCourseByNumber(ctx context.Context, courseNumber string) (*Course, error) {
+	return defaultService.GetCourseByNumber(ctx, courseNumber)
+}
+
+func ListAllCourses(ctx context.Context) ([]Course, error) {
+	return defaultService.ListAllCourses(ctx)
+}
+
+func UpdateCourse(ctx context.Context, courseID int32, courseNumber, name, description string) (*Course, error) {
+	return defaultService.UpdateCourse(ctx, courseID, courseNumber, name, description)
+}
+
+func DeleteCourse(ctx context.Context, courseID int32) error {
+	return defaultService.DeleteCourse(ctx, courseID)
+}
+
+func GetCourseAdmins(ctx context.Context, courseID int32) ([]CourseAdmin, error) {
+	return defaultService.GetCourseAdmins(ctx, courseID)
+}
+
+func AddCourseAdmin(ctx context.Context, courseID int32, userID string) (*CourseAdmin, error) {
+	return defaultService.AddCourseAdmin(ctx, courseID, userID)
+}
+
+func RemoveCourseAdmin(ctx context.Context, courseID int32, userID string) error {
+	return defaultService.RemoveCourseAdmin(ctx, courseID, userID)
+}
+
+func IsUserSuperAdmin(ctx context.Context, userID string) bool {
+	return defaultService.IsUserSuperAdmin(ctx, userID)
+}
+
+func GetAdminCoursesForUser(ctx context.Context, userID string) ([]Course, error) {
+	return defaultService.GetAdminCoursesForUser(ctx, userID)
+}
+
+// delete.go
+
+func Delete(ctx context.Context, storyID int) error {
+	return defaultService.Delete(ctx, storyID)
+}
+
+// edit.go
+
+func EditStoryText(ctx context.Context, storyID int, lines []StoryLine, dropAnswered bool) (*LineEditReport, error) {
+	return defaultService.EditStoryText(ctx, storyID, lines, dropAnswered)
+}
+
+func EditStoryMetadata(ctx context.Context, storyID int, metadata StoryMetadata) error {
+	return defaultService.EditStoryMetadata(ctx, storyID, metadata)
+}
+
+func AddLineAnnotations(ctx context.Context, storyID int, lineNumber int, line StoryLine) error {
+	return defaultService.AddLineAnnotations(ctx, storyID, lineNumber, line)
+}
+
+func ClearStoryAnnotations(ctx context.Context, storyID int) error {
+	return defaultService.ClearStoryAnnotations(ctx, storyID)
+}
+
+func ClearLineAnnotations(ctx context.Context, storyID int, lineNumber int) error {
+	return defaultService.ClearLineAnnotations(ctx, storyID, lineNumber)
+}
+
+func UpdateVocabularyAnnotation(ctx context.Context, storyID int, lineNumber int, position [2]int, vocab VocabularyItem) error {
+	return defaultService.UpdateVocabularyAnnotation(ctx, storyID, lineNumber, position, vocab)
+}
+
+func UpdateGrammarAnnotation(ctx context.Context, storyID int, lineNumber int, position [2]int, grammar GrammarItem) error {
+	return defaultService.UpdateGrammarAnnotation(ctx, storyID, lineNumber, position, grammar)
+}
+
+func UpdateVocabularyByWord(ctx context.Context, storyID int, lineNumber int, word string, newLexicalForm string) error {
+	return defaultService.UpdateVocabularyByWord(ctx, storyID, lineNumber, word, newLexicalForm)
+}
+
+func UpdateFootnoteAnnotation(ctx context.Context, storyID int, footnoteID int, footnote Footnote) error {
+	return defaultService.UpdateFootnoteAnnotation(ctx, storyID, footnoteID, footnote)
+}
+
+// get-scores.go
+
+func GetUserGrammarScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) {
+	return defaultService.GetUserGrammarScores(ctx, userID, storyID)
+}
+
+func GetUserVocabScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) {
+	return defaultService.GetUserVocabScores(ctx, userID, storyID)
+}
+
+func CheckAllVocabCompleteForLine(ctx context.Context, userID string, storyID, lineNumber int) (bool, error) {
+	return defaultService.CheckAllVocabCompleteForLine(ctx, userID, storyID, lineNumber)
+}
+
+func GetUserStoryVocabSummary(ctx context.Context, userID string, storyID int32) (*UserStoryVocabSummary, error) {
+	return defaultService.GetUserStoryVocabSummary(ctx, userID, storyID)
+}
+
+func GetUserStoryGrammarSummary(ctx context.Context, userID string, storyID int32) (*UserStoryGrammarSummary, error) {
+	return defaultService.GetUserStoryGrammarSummary(ctx, userID, storyID)
+}
+
+func GetUserStoryTimeTracking(ctx context.Context, userID string, storyID int32) (*UserStoryTimeTracking, error) {
+	return defaultService.GetUserStoryTimeTracking(ctx, userID, storyID)
+}
+
+func CountStoryVocabItems(ctx context.Context, storyID int32) (int64, error) {
+	return defaultService.CountStoryVocabItems(ctx, storyID)
+}
+
+// get.go
+
+func GetStoryData(ctx context.Context, id int, userID string) (*Story, error) {
+	return defaultService.GetStoryData(ctx, id, userID)
+}
+
+func GetLineAnnotations(ctx context.Context, storyID int, lineNumber int) (*StoryLine, error) {
+	return defaultService.GetLineAnnotations(ctx, storyID, lineNumber)
+}
+
+func GetStoryAnnotations(ctx context.Context, storyID int) (map[int]*StoryLine, error) {
+	return defaultService.GetStoryAnnotations(ctx, storyID)
+}
+
+func GetLineText(ctx context.Context, storyID int, lineNumber int) (string, error) {
+	return defaultService.GetLineText(ctx, storyID, lineNumber)
+}
+
+func GetAllStories(ctx context.Context, language string, userID string) ([]Story, error) {
+	return defaultService.GetAllStories(ctx, language, userID)
+}
+
+func GetStoriesForCourse(ctx context.Context, courseID int) ([]Story, error) {
+	return defaultService.GetStoriesForCourse(ctx, courseID)
+}
+
+// grammar_points.go
+
+func CreateGrammarPoint(ctx context.Context, storyID int, name, description string) (*GrammarPoint, error) {
+	return defaultService.CreateGrammarPoint(ctx, storyID, name, description)
+}
+
+func GetGrammarPoint(ctx context.Context, grammarPointID int) (*GrammarPoint, error) {
+	return defaultService.GetGrammarPoint(ctx, grammarPointID)
+}
+
+func GetGrammarPointByName(ctx context.Context, name string, storyID int) (*GrammarPoint, error) {
+	return defaultService.GetGrammarPointByName(ctx, name, storyID)
+}
+
+func ListGrammarPoints(ctx context.Context) ([]GrammarPoint, error) {
+	return defaultService.ListGrammarPoints(ctx)
+}
+
+func UpdateGrammarPoint(ctx context.Context, grammarPointID int, name, description string) (*GrammarPoint, error) {
+	return defaultService.UpdateGrammarPoint(ctx, grammarPointID, name, description)
+}
+
+func DeleteGrammarPoint(ctx context.Context, grammarPointID int) error {
+	return defaultService.DeleteGrammarPoint(ctx, grammarPointID)
+}
+
+func GetStoryGrammarPoints(ctx context.Context, storyID int) ([]GrammarPoint, error) {
+	return defaultService.GetStoryGrammarPoints(ctx, storyID)
+}
+
+func GetStoriesWithGrammarPoint(ctx context.Context, grammarPointID int) ([]Story, error) {
+	return defaultService.GetStoriesWithGrammarPoint(ctx, grammarPointID)
+}
+
+func ClearStoryGrammarPoints(ctx context.Context, storyID int) error {
+	return defaultService.ClearStoryGrammarPoints(ctx, storyID)
+}
+
+// save-scores.go
+
+func SaveVocabScore(ctx context.Context, userID string, storyID, lineNumber, position int, correct bool, incorrectAnswer string) error {
+	return defaultService.SaveVocabScore(ctx, userID, storyID, lineNumber, position, correct, incorrectAnswer)
+}
+
+func SaveGrammarScore(ctx context.Context, userID string, storyID int, lineNumber int, correct bool, selectedLine int, selectedPositions []int) error {
+	return defaultService.SaveGrammarScore(ctx, userID, storyID, lineNumber, correct, selectedLine, selectedPositions)
+}
+
+func GetUserGrammarScoresByGrammarPoint(ctx context.Context, userID string, storyID int, grammarPointID int) ([]db.GetUserGrammarScoresByGrammarPointRow, error) {
+	return defaultService.GetUserGrammarScoresByGrammarPoint(ctx, userID, storyID, grammarPointID)
+}
+
+func GetUserGrammarIncorrectAnswers(ctx context.Context, userID string, storyID int, grammarPointID int) ([]db.GetUserGrammarIncorrectAnswersRow, error) {
+	return defaultService.GetUserGrammarIncorrectAnswers(ctx, userID, storyID, grammarPointID)
+}
+
+func SaveGrammarScoresForPoint(ctx context.Context, userID string, storyID int, grammarPointID int, lineScores map[int]bool, incorrectAnswers map[int]struct {
+	SelectedLine      int
+	SelectedPositions []int
+}) error {
+	return defaultService.SaveGrammarScoresForPoint(ctx, userID, storyID, grammarPointID, lineScores, incorrectAnswers)
+}
+
+func SaveCorrectGrammarItems(ctx context.Context, userID string, storyID, grammarPointID int, grammarItemsMap map[int][]GrammarItem, correctItems map[int][]int) error {
+	return defaultService.SaveCorrectGrammarItems(ctx, userID, storyID, grammarPointID, grammarItemsMap, correctItems)
+}
+
+func SaveCorrectAnswers(ctx context.Context, userID string, storyID, grammarPointID int, correctAnswers []struct {
+	LineNumber int
+	Position   [2]int
+	Text       string
+}) error {
+	return defaultService.SaveCorrectAnswers(ctx, userID, storyID, grammarPointID, correctAnswers)
+}
+
+func SaveIncorrectAnswers(ctx context.Context, userID string, storyID, grammarPointID int, incorrectAnswers []struct {
+	LineNumber int
+	Position   int
+}) error {
+	return defaultService.SaveIncorrectAnswers(ctx, userID, storyID, grammarPointID, incorrectAnswers)
+}
+
+func SaveSingleGrammarSelection(ctx context.Context, userID string, storyID int, grammarPointID int, lineNumber int, position int, correct bool) error {
+	return defaultService.SaveSingleGrammarSelection(ctx, userID, storyID, grammarPointID, lineNumber, position, correct)
+}
+
+func CountFoundGrammarInstances(ctx context.Context, userID string, storyID int, grammarPointID int) (int, error) {
+	return defaultService.CountFoundGrammarInstances(ctx, userID, storyID, grammarPointID)
+}
+
+// save.go
+
+func SaveNewStory(ctx context.Context, story *Story) error {
+	return defaultService.SaveNewStory(ctx, story)
+}
+
+func SaveStoryData(ctx context.Context, storyID int, story *Story) error {
+	return defaultService.SaveStoryData(ctx, storyID, story)
+}
+
+// story.go
+
+func TestDBConnection(ctx context.Context) error {
+	return defaultService.TestDBConnection(ctx)
+}
+
+// time-tracking.go
+
+func MakeTimeTrackingSession(ctx context.Context, userID, route string, storyID *int32) (string, error) {
+	return defaultService.MakeTimeTrackingSession(ctx, userID, route, storyID)
+}
+
+func GetTimeTrackingBySessionID(ctx context.Context, sessionID string) (*TimeTrackingSession, error) {
+	return defaultService.GetTimeTrackingBySessionID(ctx, sessionID)
+}
+
+func InvalidateTimeTrackingSession(ctx context.Context, sessionID string) {
+	defaultService.InvalidateTimeTrackingSession(ctx, sessionID)
+}
+
+func GetTimeEntriesForUser(ctx context.Context, userID string) ([]db.UserTimeTracking, error) {
+	return defaultService.GetTimeEntriesForUser(ctx, userID)
+}
+
+func RecordTimeTracking(ctx context.Context, userID, route string, storyID *int32, elapsedMs int32) error {
+	return defaultService.RecordTimeTracking(ctx, userID, route, storyID, elapsedMs)
+}
+
+func GetTimeEntriesForStory(ctx context.Context, storyID int32) ([]db.UserTimeTracking, error) {
+	return defaultService.GetTimeEntriesForStory(ctx, storyID)
+}
+
+// translations.go
+
+func GetLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode string) (string, error) {
+	return defaultService.GetLineTranslation(ctx, storyID, lineNumber, languageCode)
+}
+
+func UpsertLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode, translationText string) error {
+	return defaultService.UpsertLineTranslation(ctx, storyID, lineNumber, languageCode, translationText)
+}
+
+func GetAllTranslationsForStory(ctx context.Context, storyID int) ([]LineTranslation, error) {
+	return defaultService.GetAllTranslationsForStory(ctx, storyID)
+}
+
+func GetTranslationsByLanguage(ctx context.Context, storyID int, languageCode string) ([]LineTranslation, error) {
+	return defaultService.GetTranslationsByLanguage(ctx, storyID, languageCode)
+}
+
+func DeleteLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode string) error {
+	return defaultService.DeleteLineTranslation(ctx, storyID, lineNumber, languageCode)
+}
+
+func DeleteStoryTranslations(ctx context.Context, storyID int) error {
+	return defaultService.DeleteStoryTranslations(ctx, storyID)
+}
+
+func GetTranslationRequest(ctx context.Context, userID string, storyID int) (*TranslationRequest, error) {
+	return defaultService.GetTranslationRequest(ctx, userID, storyID)
+}
+
+func CreateTranslationRequest(ctx context.Context, userID string, storyID int, requestedLines []int) (*TranslationRequest, error) {
+	return defaultService.CreateTranslationRequest(ctx, userID, storyID, requestedLines)
+}
+
+func UpdateTranslationRequest(ctx context.Context, userID string, storyID int, combinedLines []int32) error {
+	return defaultService.UpdateTranslationRequest(ctx, userID, storyID, combinedLines)
+}
+
+func TranslationRequestExists(ctx context.Context, userID string, storyID int) (bool, error) {
+	return defaultService.TranslationRequestExists(ctx, userID, storyID)
+}
+
+// users.go
+
+func UpsertUser(ctx context.Context, userID, email, name string) (*User, error) {
+	return defaultService.UpsertUser(ctx, userID, email, name)
+}
+
+func GetUser(ctx context.Context, userID string) (*User, error) {
+	return defaultService.GetUser(ctx, userID)
+}
+
+func CanUserAccessCourse(ctx context.Context, userID string, courseID int32) bool {
+	return defaultService.CanUserAccessCourse(ctx, userID, courseID)
+}
+
+func IsUserAnyAdmin(ctx context.Context, userID string) bool {
+	return defaultService.IsUserAnyAdmin(ctx, userID)
+}
+
+func IsUserOnlyCourseAdmin(ctx context.Context, userID string, courseID int32) bool {
+	return defaultService.IsUserOnlyCourseAdmin(ctx, userID, courseID)
+}
+
+func IsUserCourseOrSuperAdmin(ctx context.Context, userID string, courseID int32) bool {
+	return defaultService.IsUserCourseOrSuperAdmin(ctx, userID, courseID)
+}
+
+func CanUserEditStory(ctx context.Context, userID string, storyID int32) bool {
+	return defaultService.CanUserEditStory(ctx, userID, storyID)
+}
+
+func GetUserCourseAdminRights(ctx context.Context, userID string) ([]CourseAdminRight, error) {
+	return defaultService.GetUserCourseAdminRights(ctx, userID)
+}
+
+// vocab.go
+
+func GetLinesWithoutVocabForUser(ctx context.Context, userID string, storyID int) ([]VocabItem, error) {
+	return defaultService.GetLinesWithoutVocabForUser(ctx, userID, storyID)
+}
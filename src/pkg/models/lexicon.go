@@ -0,0 +1,384 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrSuggestionMismatch is returned when an accepted or rejected suggestion does not
+	// match the story text, e.g. because the line was edited since it was proposed
+	ErrSuggestionMismatch = errors.New("vocabulary suggestion does not match the story text")
+	// ErrAmbiguousStoryLanguage is returned for suggestions on a story whose descriptions
+	// are in more than one language, as it is unclear which lexicon applies
+	ErrAmbiguousStoryLanguage = errors.New("story has descriptions in more than one language")
+)
+
+// VocabSuggestion is a vocabulary annotation proposed from the lexicon of the story's
+// language. Position is in runes, end exclusive, like every other annotation.
+type VocabSuggestion struct {
+	LineNumber   int      `json:"lineNumber"`
+	Word         string   `json:"word"`
+	LexicalForm  string   `json:"lexicalForm"`
+	Position     [2]int   `json:"position"`
+	Occurrences  int      `json:"occurrences"`            // Accepted annotations of the word with LexicalForm
+	Alternatives []string `json:"alternatives,omitempty"` // Other lexical forms of the word, most common first
+}
+
+// lineToken is a word of a story line with its rune offsets, end exclusive
+type lineToken struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// SuggestVocabulary tokenizes a story's lines and proposes a vocabulary annotation for
+// every word the lexicon knows, with its most common lexical form. Words that already
+// have an annotation, and suggestions rejected before, are left out. A story without a
+// description language gets no suggestions.
+func (s *Service) SuggestVocabulary(ctx context.Context, storyID int) ([]VocabSuggestion, error) {
+	if _, err := s.queries.GetStory(ctx, int32(storyID)); err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	language, err := s.storyLanguage(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if language == "" {
+		return []VocabSuggestion{}, nil
+	}
+	lines, err := s.queries.GetStoryLines(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	vocab, err := s.queries.GetStoryVocabularyItems(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	rejections, err := s.queries.GetVocabSuggestionRejections(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	annotated := make(map[int32][][2]int32)
+	for _, item := range vocab {
+		annotated[item.LineNumber.Int32] = append(annotated[item.LineNumber.Int32], [2]int32{item.PositionStart, item.PositionEnd})
+	}
+	type rejection struct {
+		line, start, end int32
+		word, lexical    string
+	}
+	rejected := make(map[rejection]bool, len(rejections))
+	for _, r := range rejections {
+		rejected[rejection{r.LineNumber, r.PositionStart, r.PositionEnd, r.Word, r.LexicalForm}] = true
+	}
+
+	tokens := make([][]lineToken, len(lines))
+	var forms []string
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		tokens[i] = tokenizeLine(line.Text)
+		for _, token := range tokens[i] {
+			form := surfaceForm(token.Text)
+			if !seen[form] {
+				seen[form] = true
+				forms = append(forms, form)
+			}
+		}
+	}
+	if len(forms) == 0 {
+		return []VocabSuggestion{}, nil
+	}
+	entries, err := s.queries.GetLexiconEntries(ctx, db.GetLexiconEntriesParams{LanguageCode: language, SurfaceForms: forms})
+	if err != nil {
+		return nil, err
+	}
+	// Most common lexical form first
+	lexicon := make(map[string][]db.LexiconEntry)
+	for _, entry := range entries {
+		lexicon[entry.SurfaceForm] = append(lexicon[entry.SurfaceForm], entry)
+	}
+
+	suggestions := []VocabSuggestion{}
+	for i, line := range lines {
+	tokens:
+		for _, token := range tokens[i] {
+			for _, span := range annotated[line.LineNumber] {
+				if int32(token.Start) < span[1] && span[0] < int32(token.End) {
+					continue tokens
+				}
+			}
+			var suggestion *VocabSuggestion
+			for _, entry := range lexicon[surfaceForm(token.Text)] {
+				if rejected[rejection{line.LineNumber, int32(token.Start), int32(token.End), token.Text, entry.LexicalForm}] {
+					continue
+				}
+				if suggestion == nil {
+					suggestion = &VocabSuggestion{
+						LineNumber:  int(line.LineNumber),
+						Word:        token.Text,
+						LexicalForm: entry.LexicalForm,
+						Position:    [2]int{token.Start, token.End},
+						Occurrences: int(entry.Occurrences),
+					}
+					continue
+				}
+				suggestion.Alternatives = append(suggestion.Alternatives, entry.LexicalForm)
+			}
+			if suggestion != nil {
+				suggestions = append(suggestions, *suggestion)
+			}
+		}
+	}
+	return suggestions, nil
+}
+
+// ReviewVocabSuggestions adds the accepted suggestions as vocabulary annotations, teaching
+// the lexicon each one, and records the rejected ones so they are not proposed again. An
+// accepted suggestion may use any lexical form, not only the proposed one. It returns
+// how many annotations were added; those already on the story are skipped.
+func (s *Service) ReviewVocabSuggestions(ctx context.Context, storyID int, accepted, rejected []VocabSuggestion) (int, error) {
+	added := 0
+	changedLines := make(map[int]bool)
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := s.queries.GetStory(txCtx, int32(storyID)); err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return ErrNotFound
+			}
+			return err
+		}
+		if _, err := s.storyLanguage(txCtx, storyID); err != nil {
+			return err
+		}
+		lines, err := s.queries.GetStoryLines(txCtx, int32(storyID))
+		if err != nil {
+			return err
+		}
+		texts := make(map[int][]rune, len(lines))
+		for _, line := range lines {
+			texts[int(line.LineNumber)] = []rune(line.Text)
+		}
+		matches := func(suggestion VocabSuggestion) bool {
+			text, ok := texts[suggestion.LineNumber]
+			start, end := suggestion.Position[0], suggestion.Position[1]
+			return ok && 0 <= start && start < end && end <= len(text) &&
+				string(text[start:end]) == suggestion.Word && strings.TrimSpace(suggestion.LexicalForm) != ""
+		}
+
+		var learned []VocabularyItem
+		for _, suggestion := range accepted {
+			if !matches(suggestion) {
+				return ErrSuggestionMismatch
+			}
+			item := VocabularyItem{
+				Word:        suggestion.Word,
+				LexicalForm: strings.TrimSpace(suggestion.LexicalForm),
+				Position:    suggestion.Position,
+			}
+			err := s.dedupVocabularyInsert(txCtx, storyID, suggestion.LineNumber, item)
+			if err == errExists {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			added++
+			changedLines[suggestion.LineNumber] = true
+			learned = append(learned, item)
+		}
+		if err := s.learnVocabulary(txCtx, storyID, learned); err != nil {
+			return err
+		}
+
+		for _, suggestion := range rejected {
+			if !matches(suggestion) {
+				return ErrSuggestionMismatch
+			}
+			err := s.queries.RejectVocabSuggestion(txCtx, db.RejectVocabSuggestionParams{
+				StoryID:       int32(storyID),
+				LineNumber:    int32(suggestion.LineNumber),
+				PositionStart: int32(suggestion.Position[0]),
+				PositionEnd:   int32(suggestion.Position[1]),
+				Word:          suggestion.Word,
+				LexicalForm:   strings.TrimSpace(suggestion.LexicalForm),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if added == 0 {
+			return nil
+		}
+		if err := s.queries.UpdateStoryRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+		return s.recordStoryRevision(txCtx, storyID, "accept vocabulary suggestions")
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if added > 0 {
+		s.InvalidateStoryMetadata(storyID)
+		if s.cache != nil && s.keys != nil {
+			for lineNumber := range changedLines {
+				_ = s.cache.Delete(s.keys.LineAnnotations(storyID, lineNumber))
+			}
+		}
+	}
+	return added, nil
+}
+
+// learnVocabulary adds vocabulary annotations to the lexicon of the story's language.
+// Annotations of more than one word are not learned, as suggestions are single words.
+func (s *Service) learnVocabulary(ctx context.Context, storyID int, items []VocabularyItem) error {
+	return s.updateLexicon(ctx, storyID, items, func(entry db.LearnLexiconEntryParams) error {
+		return s.queries.LearnLexiconEntry(ctx, entry)
+	})
+}
+
+// unlearnVocabulary takes vocabulary annotations that were changed back out of the
+// lexicon, so a corrected mistake stops being suggested
+func (s *Service) unlearnVocabulary(ctx context.Context, storyID int, items []VocabularyItem) error {
+	return s.updateLexicon(ctx, storyID, items, func(entry db.LearnLexiconEntryParams) error {
+		return s.queries.UnlearnLexiconEntry(ctx, db.UnlearnLexiconEntryParams(entry))
+	})
+}
+
+// relearnVocabulary moves the lexicon from annotations as they were to the same
+// annotations after change, for edits that rewrite existing vocabulary items
+func (s *Service) relearnVocabulary(ctx context.Context, storyID int, previous []VocabularyItem, change func(VocabularyItem) VocabularyItem) error {
+	var forgotten, learned []VocabularyItem
+	for _, item := range previous {
+		changed := change(item)
+		if surfaceForm(strings.TrimSpace(changed.Word)) == surfaceForm(strings.TrimSpace(item.Word)) &&
+			strings.TrimSpace(changed.LexicalForm) == strings.TrimSpace(item.LexicalForm) {
+			continue
+		}
+		forgotten = append(forgotten, item)
+		learned = append(learned, changed)
+	}
+	if err := s.unlearnVocabulary(ctx, storyID, forgotten); err != nil {
+		return err
+	}
+	return s.learnVocabulary(ctx, storyID, learned)
+}
+
+// lineVocabulary returns the vocabulary items of a line that match
+func (s *Service) lineVocabulary(ctx context.Context, storyID, lineNumber int, match func(db.VocabularyItem) bool) ([]VocabularyItem, error) {
+	results, err := s.queries.GetVocabularyItems(ctx, db.GetVocabularyItemsParams{
+		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
+		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var items []VocabularyItem
+	for _, result := range results {
+		if match(result) {
+			items = append(items, VocabularyItem{
+				Word:        result.Word,
+				LexicalForm: result.LexicalForm,
+				Position:    [2]int{int(result.PositionStart), int(result.PositionEnd)},
+			})
+		}
+	}
+	return items, nil
+}
+
+// updateLexicon applies update to the lexicon entry of each single-word annotation.
+// Stories without a single description language are left out of the lexicon rather
+// than failing the edit.
+func (s *Service) updateLexicon(ctx context.Context, storyID int, items []VocabularyItem, update func(db.LearnLexiconEntryParams) error) error {
+	if len(items) == 0 {
+		return nil
+	}
+	language, err := s.storyLanguage(ctx, storyID)
+	if err == ErrAmbiguousStoryLanguage {
+		fmt.Printf("Not updating the lexicon from story %d: %v\n", storyID, err)
+		return nil
+	}
+	if err != nil || language == "" {
+		return err
+	}
+	for _, item := range items {
+		word, lexicalForm := strings.TrimSpace(item.Word), strings.TrimSpace(item.LexicalForm)
+		if word == "" || lexicalForm == "" || strings.ContainsFunc(word, unicode.IsSpace) {
+			continue
+		}
+		err := update(db.LearnLexiconEntryParams{
+			LanguageCode: language,
+			SurfaceForm:  surfaceForm(word),
+			LexicalForm:  lexicalForm,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storyLanguage returns the language code of the story's description, "" if it has
+// none, or ErrAmbiguousStoryLanguage if its descriptions are in several languages
+func (s *Service) storyLanguage(ctx context.Context, storyID int) (string, error) {
+	languages, err := s.queries.GetStoryLanguages(ctx, int32(storyID))
+	if err != nil {
+		return "", err
+	}
+	switch len(languages) {
+	case 0:
+		return "", nil
+	case 1:
+		return languages[0], nil
+	}
+	return "", ErrAmbiguousStoryLanguage
+}
+
+// surfaceForm is the lexicon key of a word as written in a story
+func surfaceForm(word string) string {
+	return strings.ToLower(word)
+}
+
+// tokenizeLine splits a line into words: runs of letters, combining marks (niqqud,
+// accents) and digits, held together by an apostrophe or geresh between letters.
+// Everything else, including the Hebrew maqaf, separates words.
+func tokenizeLine(text string) []lineToken {
+	runes := []rune(text)
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+	}
+	isJoiner := func(i int) bool {
+		switch runes[i] {
+		case '\'', '’', '׳', '״':
+			return i > 0 && i+1 < len(runes) && isWord(runes[i-1]) && isWord(runes[i+1])
+		}
+		return false
+	}
+
+	var tokens []lineToken
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		inWord := i < len(runes) && (isWord(runes[i]) || start >= 0 && isJoiner(i))
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, lineToken{Text: string(runes[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+	return tokens
+}
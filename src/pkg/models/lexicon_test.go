@@ -0,0 +1,145 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"glossias/src/pkg/database"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestTokenizeLine(t *testing.T) {
+	tests := []struct {
+		text string
+		want []lineToken
+	}{
+		{"The king's city.", []lineToken{{"The", 0, 3}, {"king's", 4, 10}, {"city", 11, 15}}},
+		// Niqqud stays with its letter, and positions count runes, not bytes
+		{"וַיֹּאמֶר הַמֶּלֶךְ:", []lineToken{{"וַיֹּאמֶר", 0, 9}, {"הַמֶּלֶךְ", 10, 19}}},
+		// The maqaf separates words; a geresh between letters does not
+		{"אֶל־הָעִיר ג׳ון", []lineToken{{"אֶל", 0, 3}, {"הָעִיר", 4, 10}, {"ג׳ון", 11, 15}}},
+		{"  'λόγος' — 12 ", []lineToken{{"λόγος", 3, 8}, {"12", 12, 14}}},
+		{"...", nil},
+	}
+	for _, tc := range tests {
+		if got := tokenizeLine(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("tokenizeLine(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestSuggestVocabulary(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetStory :one", [][]interface{}{{
+		int32(7), int32(1), "a", pgtype.Text{}, pgtype.Timestamp{}, "", "", pgtype.Int4{}, pgtype.Timestamp{},
+	}}, nil)
+	mock.StubQuery("-- name: GetStoryLines", [][]interface{}{
+		{int32(7), int32(1), "The King saw the city."},
+		{int32(7), int32(2), "The city slept."},
+	}, nil)
+	mock.StubQuery("-- name: GetStoryLanguages", [][]interface{}{{"en"}}, nil)
+	// "city" on line 1 is already annotated
+	mock.StubQuery("-- name: GetStoryVocabularyItems", [][]interface{}{{
+		int32(10), pgtype.Int4{Int32: 7, Valid: true}, pgtype.Int4{Int32: 1, Valid: true}, "city", "city", int32(17), int32(21),
+	}}, nil)
+	mock.StubQuery("-- name: GetVocabSuggestionRejections", [][]interface{}{
+		{int32(7), int32(2), int32(9), int32(14), "slept", "sleep", pgtype.Timestamp{}},
+	}, nil)
+	mock.StubQuery("-- name: GetLexiconEntries", [][]interface{}{
+		{"en", "city", "city", int32(4), pgtype.Timestamp{}},
+		{"en", "king", "king", int32(9), pgtype.Timestamp{}},
+		{"en", "saw", "see", int32(5), pgtype.Timestamp{}},
+		{"en", "saw", "saw", int32(2), pgtype.Timestamp{}},
+		{"en", "slept", "sleep", int32(3), pgtype.Timestamp{}},
+	}, nil)
+	svc := NewService(mock, nil, nil, nil)
+
+	suggestions, err := svc.SuggestVocabulary(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("SuggestVocabulary failed: %v", err)
+	}
+	want := []VocabSuggestion{
+		{LineNumber: 1, Word: "King", LexicalForm: "king", Position: [2]int{4, 8}, Occurrences: 9},
+		{LineNumber: 1, Word: "saw", LexicalForm: "see", Position: [2]int{9, 12}, Occurrences: 5, Alternatives: []string{"saw"}},
+		{LineNumber: 2, Word: "city", LexicalForm: "city", Position: [2]int{4, 8}, Occurrences: 4},
+	}
+	if !reflect.DeepEqual(suggestions, want) {
+		t.Errorf("Unexpected suggestions\n got %+v\nwant %+v", suggestions, want)
+	}
+}
+
+func TestReviewVocabSuggestionsChecksText(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetStory :one", [][]interface{}{{
+		int32(7), int32(1), "a", pgtype.Text{}, pgtype.Timestamp{}, "", "", pgtype.Int4{}, pgtype.Timestamp{},
+	}}, nil)
+	mock.StubQuery("-- name: GetStoryLines", [][]interface{}{{int32(7), int32(1), "הַמֶּלֶךְ יָשַׁב"}}, nil)
+	svc := NewService(mock, nil, nil, nil)
+	ctx := context.Background()
+
+	// Positions in bytes rather than runes no longer match the word
+	stale := VocabSuggestion{LineNumber: 1, Word: "יָשַׁב", LexicalForm: "ישב", Position: [2]int{19, 31}}
+	if _, err := svc.ReviewVocabSuggestions(ctx, 7, nil, []VocabSuggestion{stale}); err != ErrSuggestionMismatch {
+		t.Errorf("Expected ErrSuggestionMismatch, got %v", err)
+	}
+
+	rejected := VocabSuggestion{LineNumber: 1, Word: "יָשַׁב", LexicalForm: "ישב", Position: [2]int{10, 16}}
+	added, err := svc.ReviewVocabSuggestions(ctx, 7, nil, []VocabSuggestion{rejected})
+	if err != nil || added != 0 {
+		t.Errorf("Expected the rejection to be recorded, got %d, %v", added, err)
+	}
+}
+
+func TestUpdateVocabularyByWordUnlearnsTheOldForm(t *testing.T) {
+	forgotten := errors.New("unlearned")
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetStoryLanguages", [][]interface{}{{"en"}}, nil)
+	mock.StubQuery("-- name: GetVocabularyItems", [][]interface{}{{
+		int32(10), pgtype.Int4{Int32: 7, Valid: true}, pgtype.Int4{Int32: 1, Valid: true}, "saw", "saw", int32(9), int32(12),
+	}}, nil)
+	mock.StubExec("-- name: UnlearnLexiconEntry", forgotten)
+	svc := NewService(mock, nil, nil, nil)
+
+	if err := svc.UpdateVocabularyByWord(context.Background(), 7, 1, "saw", "see"); !errors.Is(err, forgotten) {
+		t.Errorf("Expected the old lexical form to be unlearned, got %v", err)
+	}
+	// Setting the form it already has leaves the lexicon alone
+	if err := svc.UpdateVocabularyByWord(context.Background(), 7, 1, "saw", "saw"); errors.Is(err, forgotten) {
+		t.Error("Expected an unchanged lexical form not to be unlearned")
+	}
+}
+
+func TestStoryLanguage(t *testing.T) {
+	tests := []struct {
+		languages [][]interface{}
+		want      string
+		wantErr   error
+	}{
+		{nil, "", nil},
+		{[][]interface{}{{"he"}}, "he", nil},
+		{[][]interface{}{{"en"}, {"he"}}, "", ErrAmbiguousStoryLanguage},
+	}
+	for _, tc := range tests {
+		mock := database.NewMockDBTX()
+		mock.StubQuery("-- name: GetStoryLanguages", tc.languages, nil)
+		svc := NewService(mock, nil, nil, nil)
+		got, err := svc.storyLanguage(context.Background(), 7)
+		if got != tc.want || err != tc.wantErr {
+			t.Errorf("storyLanguage with %v = %q, %v; want %q, %v", tc.languages, got, err, tc.want, tc.wantErr)
+		}
+	}
+
+	// Without a description language there is nothing to suggest from
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetStory :one", [][]interface{}{{
+		int32(7), int32(1), "a", pgtype.Text{}, pgtype.Timestamp{}, "", "", pgtype.Int4{}, pgtype.Timestamp{},
+	}}, nil)
+	mock.StubQuery("-- name: GetStoryLines", [][]interface{}{{int32(7), int32(1), "The king."}}, nil)
+	mock.StubQuery("-- name: GetLexiconEntries", [][]interface{}{{"", "king", "king", int32(3), pgtype.Timestamp{}}}, nil)
+	suggestions, err := NewService(mock, nil, nil, nil).SuggestVocabulary(context.Background(), 7)
+	if err != nil || len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions for a story without a description, got %v, %v", suggestions, err)
+	}
+}
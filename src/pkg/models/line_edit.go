@@ -0,0 +1,567 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// lineMatchThreshold is how similar an old and a new line must be to count as the
+// same line edited rather than one removed and another added
+const lineMatchThreshold = 0.5
+
+// lineMatchWindow is how many lines apart, counted from either end of a stretch of
+// changed lines, an old and a new line may be to be compared for similarity
+const lineMatchWindow = 10
+
+// ErrEditOrphansAnswers is returned when a text edit would delete vocabulary items that
+// students have answered, along with the answers, and the caller did not allow it
+var ErrEditOrphansAnswers = errors.New("edit would delete vocabulary items students have answered")
+
+// Kinds of annotation in a DroppedAnnotation
+const (
+	AnnotationVocabulary = "vocabulary"
+	AnnotationGrammar    = "grammar"
+)
+
+// DroppedAnnotation is an annotation of an edited line whose text is no longer in the line.
+// Position is in the old text.
+type DroppedAnnotation struct {
+	Kind     string `json:"kind"`
+	OldLine  int    `json:"oldLine"`
+	NewLine  int    `json:"newLine"`
+	Text     string `json:"text"`
+	Position [2]int `json:"position"`
+}
+
+// LineEditReport describes how EditStoryText carried the old lines over. Edited and
+// Added hold new line numbers, Removed old ones. Remapped counts annotations that moved
+// within their line.
+type LineEditReport struct {
+	Edited   []int               `json:"edited"`
+	Added    []int               `json:"added"`
+	Removed  []int               `json:"removed"`
+	Moved    int                 `json:"moved"`
+	Remapped int                 `json:"remapped"`
+	Dropped  []DroppedAnnotation `json:"dropped"`
+}
+
+// applyLineEdit turns the stored lines into the given ones. Matched lines keep their rows,
+// so annotations, audio, translations and answers follow them to their new numbers;
+// annotations of edited lines are moved to where their text now is. Line numbers stored
+// without a foreign key (produce segments, translation requests, selected grammar lines)
+// are remapped too. Unless dropAnswered is set, ErrEditOrphansAnswers is returned before
+// anything changes if a vocabulary item with answers would be deleted.
+// It returns the audio files of removed lines, whose storage objects are left to the caller.
+func (s *Service) applyLineEdit(ctx context.Context, storyID int, lines []StoryLine, dropAnswered bool) (*LineEditReport, []AudioFile, error) {
+	current, err := s.queries.GetStoryLines(ctx, int32(storyID))
+	if err != nil {
+		return nil, nil, err
+	}
+	story := pgtype.Int4{Int32: int32(storyID), Valid: true}
+	vocab, err := s.queries.GetStoryVocabularyItems(ctx, story)
+	if err != nil {
+		return nil, nil, err
+	}
+	grammar, err := s.queries.GetStoryGrammarItems(ctx, story)
+	if err != nil {
+		return nil, nil, err
+	}
+	audioFiles, err := s.GetAllStoryAudioFiles(ctx, storyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldTexts := make([]string, len(current))
+	for i, line := range current {
+		oldTexts[i] = line.Text
+	}
+	newTexts := make([]string, len(lines))
+	for i, line := range lines {
+		newTexts[i] = line.Text
+	}
+	matches := matchLines(oldTexts, newTexts)
+
+	report := &LineEditReport{Edited: []int{}, Added: []int{}, Removed: []int{}, Dropped: []DroppedAnnotation{}}
+	newLineOf := make(map[int]int) // Old line number -> new line number, for kept lines
+	matchedNew := make(map[int]bool)
+	for i, j := range matches {
+		if j < 0 {
+			report.Removed = append(report.Removed, int(current[i].LineNumber))
+			continue
+		}
+		newLineOf[int(current[i].LineNumber)] = lines[j].LineNumber
+		matchedNew[j] = true
+	}
+	for j, line := range lines {
+		if !matchedNew[j] {
+			report.Added = append(report.Added, line.LineNumber)
+		}
+	}
+
+	if !dropAnswered {
+		if deleted := deletedVocabulary(current, lines, matches, vocab); len(deleted) > 0 {
+			answers, err := s.queries.CountVocabItemAnswers(ctx, deleted)
+			if err != nil {
+				return nil, nil, err
+			}
+			if answers > 0 {
+				return nil, nil, ErrEditOrphansAnswers
+			}
+		}
+	}
+
+	var removedAudio []AudioFile
+	for _, audioFile := range audioFiles {
+		if _, kept := newLineOf[audioFile.LineNumber]; !kept {
+			removedAudio = append(removedAudio, audioFile)
+		}
+	}
+	for _, lineNumber := range report.Removed {
+		if err := s.queries.DeleteStoryLine(ctx, db.DeleteStoryLineParams{
+			StoryID:    int32(storyID),
+			LineNumber: int32(lineNumber),
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Lines move through negative numbers first so no two ever share a number
+	renumber := func(from, to int) error {
+		return s.queries.RenumberStoryLine(ctx, db.RenumberStoryLineParams{
+			StoryID:       int32(storyID),
+			LineNumber:    int32(from),
+			NewLineNumber: int32(to),
+		})
+	}
+	for oldLine, newLine := range newLineOf {
+		if oldLine == newLine {
+			continue
+		}
+		report.Moved++
+		if err := renumber(oldLine, -newLine); err != nil {
+			return nil, nil, err
+		}
+	}
+	for oldLine, newLine := range newLineOf {
+		if oldLine == newLine {
+			continue
+		}
+		if err := renumber(-newLine, newLine); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if report.Moved > 0 || len(report.Removed) > 0 {
+		if err := s.remapLineReferences(ctx, storyID, current, newLineOf, len(lines)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i, j := range matches {
+		if j < 0 || current[i].Text == lines[j].Text {
+			continue
+		}
+		report.Edited = append(report.Edited, lines[j].LineNumber)
+		if err := s.queries.UpdateStoryLineText(ctx, db.UpdateStoryLineTextParams{
+			StoryID:    int32(storyID),
+			LineNumber: int32(lines[j].LineNumber),
+			Text:       lines[j].Text,
+		}); err != nil {
+			return nil, nil, err
+		}
+		if err := s.remapLineAnnotations(ctx, report, int(current[i].LineNumber), lines[j], current[i].Text, vocab, grammar); err != nil {
+			return nil, nil, err
+		}
+	}
+	slices.Sort(report.Edited)
+
+	for _, lineNumber := range report.Added {
+		if err := s.queries.UpsertStoryLine(ctx, db.UpsertStoryLineParams{
+			StoryID:    int32(storyID),
+			LineNumber: int32(lineNumber),
+			Text:       newTexts[lineNumber-1],
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	return report, removedAudio, nil
+}
+
+// deletedVocabulary returns the IDs of the vocabulary items the edit deletes: those of
+// removed lines and those whose text is gone from their edited line
+func deletedVocabulary(current []db.StoryLine, lines []StoryLine, matches []int, vocab []db.VocabularyItem) []int32 {
+	var deleted []int32
+	for i, j := range matches {
+		for _, item := range vocab {
+			if item.LineNumber.Int32 != current[i].LineNumber {
+				continue
+			}
+			if j < 0 {
+				deleted = append(deleted, item.ID)
+				continue
+			}
+			if current[i].Text == lines[j].Text {
+				continue
+			}
+			mapping := sequenceMapping([]rune(current[i].Text), []rune(lines[j].Text))
+			if _, ok := remapPosition(mapping, []rune(lines[j].Text), [2]int{int(item.PositionStart), int(item.PositionEnd)}, item.Word); !ok {
+				deleted = append(deleted, item.ID)
+			}
+		}
+	}
+	return deleted
+}
+
+// remapLineReferences renumbers the line numbers that are not foreign keys to story_lines.
+// References to removed lines are dropped from translation requests and set to 0, no line,
+// for selected grammar lines. A produce segment spans the kept lines of its old range, or
+// the line after it if all of them were removed.
+func (s *Service) remapLineReferences(ctx context.Context, storyID int, current []db.StoryLine, newLineOf map[int]int, lineCount int) error {
+	oldLines := make([]int32, len(current))
+	newLines := make([]int32, len(current))
+	for i, line := range current {
+		oldLines[i] = line.LineNumber
+		newLines[i] = int32(newLineOf[int(line.LineNumber)]) // 0 for removed lines
+	}
+	if err := s.queries.RemapTranslationRequestLines(ctx, db.RemapTranslationRequestLinesParams{
+		OldLines: oldLines,
+		NewLines: newLines,
+		StoryID:  int32(storyID),
+	}); err != nil {
+		return err
+	}
+	if err := s.queries.RemapGrammarIncorrectSelectedLines(ctx, db.RemapGrammarIncorrectSelectedLinesParams{
+		OldLines: oldLines,
+		NewLines: newLines,
+		StoryID:  int32(storyID),
+	}); err != nil {
+		return err
+	}
+
+	segments, err := s.queries.GetStoryProduceSegments(ctx, int32(storyID))
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		start, end := int32(0), int32(0)
+		for i, oldLine := range oldLines {
+			if newLines[i] == 0 {
+				continue
+			}
+			if oldLine >= segment.StartLine && oldLine <= segment.EndLine {
+				if start == 0 {
+					start = newLines[i]
+				}
+				end = newLines[i]
+			} else if oldLine > segment.EndLine && start == 0 {
+				start, end = newLines[i], newLines[i]
+				break
+			}
+		}
+		if start == 0 {
+			// Nothing kept in or after the segment: it moves to the last line
+			start, end = int32(lineCount), int32(lineCount)
+		}
+		if start < 1 || start == segment.StartLine && end == segment.EndLine {
+			continue
+		}
+		if _, err := s.queries.UpdateProduceSegment(ctx, db.UpdateProduceSegmentParams{
+			ID:             segment.ID,
+			StoryID:        segment.StoryID,
+			StartLine:      start,
+			EndLine:        end,
+			EnglishPrompt:  segment.EnglishPrompt,
+			ReferenceText:  segment.ReferenceText,
+			GrammarPointID: segment.GrammarPointID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remapLineAnnotations moves the vocabulary and grammar items of an edited line to the
+// new positions of their text, and deletes and reports those whose text is gone
+func (s *Service) remapLineAnnotations(ctx context.Context, report *LineEditReport, oldLine int, line StoryLine, oldText string, vocab []db.VocabularyItem, grammar []db.GrammarItem) error {
+	oldRunes, newRunes := []rune(oldText), []rune(line.Text)
+	mapping := sequenceMapping(oldRunes, newRunes)
+	dropped := func(kind, text string, start, end int32) {
+		report.Dropped = append(report.Dropped, DroppedAnnotation{
+			Kind:     kind,
+			OldLine:  oldLine,
+			NewLine:  line.LineNumber,
+			Text:     text,
+			Position: [2]int{int(start), int(end)},
+		})
+	}
+
+	for _, item := range vocab {
+		if int(item.LineNumber.Int32) != oldLine {
+			continue
+		}
+		position, ok := remapPosition(mapping, newRunes, [2]int{int(item.PositionStart), int(item.PositionEnd)}, item.Word)
+		if !ok {
+			dropped(AnnotationVocabulary, item.Word, item.PositionStart, item.PositionEnd)
+			if err := s.queries.DeleteVocabularyItem(ctx, item.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if position == [2]int{int(item.PositionStart), int(item.PositionEnd)} {
+			continue
+		}
+		report.Remapped++
+		if err := s.queries.UpdateVocabularyItem(ctx, db.UpdateVocabularyItemParams{
+			ID:            item.ID,
+			Word:          item.Word,
+			LexicalForm:   item.LexicalForm,
+			PositionStart: int32(position[0]),
+			PositionEnd:   int32(position[1]),
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range grammar {
+		if int(item.LineNumber.Int32) != oldLine {
+			continue
+		}
+		position, ok := remapPosition(mapping, newRunes, [2]int{int(item.PositionStart), int(item.PositionEnd)}, item.Text)
+		if !ok {
+			dropped(AnnotationGrammar, item.Text, item.PositionStart, item.PositionEnd)
+			if err := s.queries.DeleteGrammarItem(ctx, item.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if position == [2]int{int(item.PositionStart), int(item.PositionEnd)} {
+			continue
+		}
+		report.Remapped++
+		if err := s.queries.UpdateGrammarItemPosition(ctx, db.UpdateGrammarItemPositionParams{
+			ID:            item.ID,
+			PositionStart: int32(position[0]),
+			PositionEnd:   int32(position[1]),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchLines pairs old and new lines in order. Identical lines are paired first; in each
+// stretch of changed lines between them, lines are paired to maximize the total similarity
+// of the pairs. It returns the index of the new line for each old line, or -1 if it was removed.
+func matchLines(older, newer []string) []int {
+	// Lines are compared by number so pairing identical lines costs no text comparisons
+	ids := make(map[string]int)
+	lineIDs := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, text := range lines {
+			id, ok := ids[text]
+			if !ok {
+				id = len(ids)
+				ids[text] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+	identical := sequenceMapping(lineIDs(older), lineIDs(newer))
+
+	matches := make([]int, len(older))
+	i0, j0 := 0, 0
+	for i := 0; i <= len(older); i++ {
+		if i < len(older) && identical[i] < 0 {
+			continue
+		}
+		j := len(newer)
+		if i < len(older) {
+			j = identical[i]
+		}
+		matchChangedLines(older[i0:i], newer[j0:j], matches[i0:i], j0)
+		if i < len(older) {
+			matches[i] = j
+			i0, j0 = i+1, j+1
+		}
+	}
+	return matches
+}
+
+// matchChangedLines pairs a stretch of changed lines the way matchLines does, comparing
+// only lines within lineMatchWindow of each other. It stores offset plus the index of
+// the new line for each old line in matches, or -1 if it was removed.
+func matchChangedLines(older, newer []string, matches []int, offset int) {
+	for i := range matches {
+		matches[i] = -1
+	}
+	if len(older) == 0 || len(newer) == 0 {
+		return
+	}
+
+	oldRunes := make([][]rune, len(older))
+	for i, text := range older {
+		oldRunes[i] = []rune(text)
+	}
+	newRunes := make([][]rune, len(newer))
+	for j, text := range newer {
+		newRunes[j] = []rune(text)
+	}
+	score := func(i, j int) float64 {
+		fromStart := abs(i - j)
+		fromEnd := abs((len(older) - i) - (len(newer) - j))
+		if min(fromStart, fromEnd) > lineMatchWindow {
+			return 0
+		}
+		if older[i] == newer[j] {
+			return 1
+		}
+		similarity := lineSimilarity(oldRunes[i], newRunes[j])
+		if similarity < lineMatchThreshold {
+			return 0
+		}
+		return similarity
+	}
+
+	// best[i][j] is the highest total similarity for older[i:] and newer[j:]
+	best := make([][]float64, len(older)+1)
+	scores := make([][]float64, len(older))
+	for i := range best {
+		best[i] = make([]float64, len(newer)+1)
+	}
+	for i := range scores {
+		scores[i] = make([]float64, len(newer))
+	}
+	for i := len(older) - 1; i >= 0; i-- {
+		for j := len(newer) - 1; j >= 0; j-- {
+			scores[i][j] = score(i, j)
+			best[i][j] = max(best[i+1][j], best[i][j+1])
+			if scores[i][j] > 0 {
+				best[i][j] = max(best[i][j], best[i+1][j+1]+scores[i][j])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(older) && j < len(newer) {
+		switch {
+		case scores[i][j] > 0 && best[i][j] == best[i+1][j+1]+scores[i][j]:
+			matches[i] = offset + j
+			i++
+			j++
+		case best[i][j] == best[i+1][j]:
+			i++
+		default:
+			j++
+		}
+	}
+}
+
+// lineSimilarity is 2 * the longest common subsequence of runes over the total length,
+// 1 for identical lines and 0 for lines without a rune in common
+func lineSimilarity(a, b []rune) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	// The common subsequence is at most as long as the shorter line
+	if 2*float64(min(len(a), len(b)))/float64(len(a)+len(b)) < lineMatchThreshold {
+		return 0
+	}
+	common := 0
+	for _, j := range sequenceMapping(a, b) {
+		if j >= 0 {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+// sequenceMapping aligns two sequences, such as the runes of two texts, along their longest
+// common subsequence and returns the index in newer of each item of older, or -1 for items
+// that were removed
+func sequenceMapping[T comparable](older, newer []T) []int {
+	common := make([][]int, len(older)+1)
+	for i := range common {
+		common[i] = make([]int, len(newer)+1)
+	}
+	for i := len(older) - 1; i >= 0; i-- {
+		for j := len(newer) - 1; j >= 0; j-- {
+			if older[i] == newer[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	mapping := make([]int, len(older))
+	i, j := 0, 0
+	for i < len(older) {
+		switch {
+		case j < len(newer) && older[i] == newer[j] && common[i][j] == common[i+1][j+1]+1:
+			mapping[i] = j
+			i++
+			j++
+		case j == len(newer) || common[i+1][j] >= common[i][j+1]:
+			mapping[i] = -1
+			i++
+		default:
+			j++
+		}
+	}
+	return mapping
+}
+
+// remapPosition finds the rune range [start, end) of an old line in the new line. The range
+// moves along when none of its runes changed; otherwise text is looked up in the new line,
+// taking the occurrence nearest to where the range would be.
+func remapPosition(mapping []int, newRunes []rune, position [2]int, text string) ([2]int, bool) {
+	start, end := position[0], position[1]
+	if start >= 0 && start < end && end <= len(mapping) {
+		intact := mapping[start] >= 0
+		for k := start + 1; k < end && intact; k++ {
+			intact = mapping[k] == mapping[k-1]+1
+		}
+		if intact {
+			return [2]int{mapping[start], mapping[end-1] + 1}, true
+		}
+	}
+
+	target := []rune(text)
+	if len(target) == 0 || len(target) > len(newRunes) {
+		return position, false
+	}
+	// Where the range would start: just after the last kept rune before it
+	expected := 0
+	for k := min(start, len(mapping)) - 1; k >= 0; k-- {
+		if mapping[k] >= 0 {
+			expected = mapping[k] + 1
+			break
+		}
+	}
+	found := -1
+	for k := 0; k+len(target) <= len(newRunes); k++ {
+		if !slices.Equal(newRunes[k:k+len(target)], target) {
+			continue
+		}
+		if found < 0 || abs(k-expected) < abs(found-expected) {
+			found = k
+		}
+	}
+	if found < 0 {
+		return position, false
+	}
+	return [2]int{found, found + len(target)}, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
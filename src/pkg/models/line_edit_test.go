@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMatchLines(t *testing.T) {
+	older := []string{
+		"וַיֹּאמֶר אֱלֹהִים יְהִי אוֹר",
+		"וַיְהִי אוֹר",
+		"וַיַּרְא אֱלֹהִים אֶת הָאוֹר כִּי טוֹב",
+	}
+	newer := []string{
+		"וַיֹּאמֶר אֱלֹהִים יְהִי אוֹר",
+		"a new line",
+		"וַיְהִי אוֹר!",
+		"וַיַּרְא אֱלֹהִים אֶת הָאוֹר כִּי טוֹב",
+	}
+
+	expected := []int{0, 2, 3}
+	if matches := matchLines(older, newer); !slices.Equal(matches, expected) {
+		t.Errorf("Expected matches %v, got %v", expected, matches)
+	}
+
+	// A rewritten line is removed and added rather than matched
+	if matches := matchLines([]string{"שָׁלוֹם"}, []string{"goodbye"}); !slices.Equal(matches, []int{-1}) {
+		t.Errorf("Expected the rewritten line to be unmatched, got %v", matches)
+	}
+}
+
+func TestMatchLinesComparesNearbyChangedLines(t *testing.T) {
+	var older, newer []string
+	for i := range 30 {
+		older = append(older, fmt.Sprintf("old line %d", i))
+		newer = append(newer, fmt.Sprintf("new paragraph %d", i))
+	}
+	// The edited lines sit after 30 added lines, near the end of the changed stretch
+	older = append(older, "וַיְהִי עֶרֶב", "סוֹף")
+	newer = append(newer, "וַיְהִי עֶרֶב!", "סוֹף")
+
+	matches := matchLines(older, newer)
+	if matches[30] != 30 || matches[31] != 31 {
+		t.Errorf("Expected the edited and the identical line to be matched, got %v", matches[30:])
+	}
+	for i, j := range matches[:30] {
+		if j != -1 {
+			t.Errorf("Expected rewritten line %d to be unmatched, got %d", i, j)
+		}
+	}
+
+	// An edited line in the middle of a long rewritten stretch is too far from either end
+	older = []string{"ראשון", "old a", "old b", "old c", "בַּיּוֹם הַהוּא", "אחרון"}
+	newer = []string{"ראשון"}
+	for i := range 2 * lineMatchWindow {
+		newer = append(newer, fmt.Sprintf("added %d", i))
+	}
+	newer = append(newer, "בַּיּוֹם הַהוּא!")
+	for i := range 2 * lineMatchWindow {
+		newer = append(newer, fmt.Sprintf("added again %d", i))
+	}
+	newer = append(newer, "אחרון")
+	matches = matchLines(older, newer)
+	if matches[0] != 0 || matches[5] != len(newer)-1 {
+		t.Errorf("Expected identical lines to be matched, got %v", matches)
+	}
+	if matches[4] != -1 {
+		t.Errorf("Expected the distant edited line to be unmatched, got %d", matches[4])
+	}
+}
+
+func TestRemapPosition(t *testing.T) {
+	older := []rune("הַמֶּלֶךְ הָלַךְ")
+	newer := []rune("וְהַמֶּלֶךְ הַזָּקֵן הָלַךְ")
+	mapping := sequenceMapping(older, newer)
+
+	// הָלַךְ moves along with the inserted text
+	position, ok := remapPosition(mapping, newer, [2]int{10, 16}, "הָלַךְ")
+	if !ok || string(newer[position[0]:position[1]]) != "הָלַךְ" {
+		t.Errorf("Expected הָלַךְ to be remapped, got %v %v", position, ok)
+	}
+
+	// A prefix before the word shifts it by two runes
+	position, ok = remapPosition(mapping, newer, [2]int{0, 9}, "הַמֶּלֶךְ")
+	if !ok || position != [2]int{2, 11} {
+		t.Errorf("Expected הַמֶּלֶךְ at [2 11], got %v %v", position, ok)
+	}
+
+	// An annotated word that was itself edited is dropped
+	edited := []rune("הַמֶּלֶךְ הָלְכָה")
+	if _, ok := remapPosition(sequenceMapping(older, edited), edited, [2]int{10, 16}, "הָלַךְ"); ok {
+		t.Error("Expected the edited word to be dropped")
+	}
+}
+
+func TestDeletedVocabulary(t *testing.T) {
+	current := []db.StoryLine{
+		{StoryID: 1, LineNumber: 1, Text: "הַמֶּלֶךְ הָלַךְ"},
+		{StoryID: 1, LineNumber: 2, Text: "וַיְהִי אוֹר"},
+	}
+	lines := []StoryLine{{LineNumber: 1, Text: "הַמֶּלֶךְ הָלְכָה"}}
+	vocab := []db.VocabularyItem{
+		{ID: 10, LineNumber: pgtype.Int4{Int32: 1, Valid: true}, Word: "הַמֶּלֶךְ", PositionStart: 0, PositionEnd: 9},
+		{ID: 11, LineNumber: pgtype.Int4{Int32: 1, Valid: true}, Word: "הָלַךְ", PositionStart: 10, PositionEnd: 16},
+		{ID: 12, LineNumber: pgtype.Int4{Int32: 2, Valid: true}, Word: "אוֹר", PositionStart: 7, PositionEnd: 11},
+	}
+
+	// The edited word and everything on the removed line go; the kept word stays
+	deleted := deletedVocabulary(current, lines, []int{0, -1}, vocab)
+	if !slices.Equal(deleted, []int32{11, 12}) {
+		t.Errorf("Expected items [11 12] to be deleted, got %v", deleted)
+	}
+}
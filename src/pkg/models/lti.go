@@ -0,0 +1,380 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrAmbiguousLTIPlatform = errors.New("several platforms share this issuer, client_id is required")
+
+// LTIPlatform is an LMS registered with Glossias as an LTI 1.3 platform
+type LTIPlatform struct {
+	ID           int32     `json:"id"`
+	Name         string    `json:"name"`
+	Issuer       string    `json:"issuer"`
+	ClientID     string    `json:"client_id"`
+	DeploymentID string    `json:"deployment_id"` // Empty accepts any deployment
+	AuthLoginURL string    `json:"auth_login_url"`
+	AuthTokenURL string    `json:"auth_token_url"`
+	JWKSURL      string    `json:"jwks_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// LTIContext maps an LMS course, an LTI context, to a course
+type LTIContext struct {
+	ID           int32  `json:"id"`
+	PlatformID   int32  `json:"platform_id"`
+	ContextID    string `json:"context_id"`
+	CourseID     int32  `json:"course_id"`
+	Title        string `json:"title"`
+	LineItemsURL string `json:"lineitems_url"` // AGS line items service, empty if the platform offers none
+}
+
+// LTIScoreTarget is a line items service that a user's scores on a story are posted to
+type LTIScoreTarget struct {
+	PlatformID   int32
+	Subject      string // The user's id on the platform
+	LTIContextID int32
+	CourseID     int32
+	LineItemsURL string
+}
+
+// ScorePublisher sends new story score snapshots to an external gradebook
+type ScorePublisher interface {
+	PublishScore(ctx context.Context, score StoryScore) error
+}
+
+// SetScorePublisher makes every new story score snapshot be sent to p. A nil p stops publishing.
+func (s *Service) SetScorePublisher(p ScorePublisher) {
+	s.publisher = p
+}
+
+// publishScore hands score to the score publisher in the background. Failures are only
+// logged: the snapshot is already saved and is sent again when the score next changes.
+func (s *Service) publishScore(score StoryScore) {
+	if s.publisher == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), scorePublishTimeout)
+		defer cancel()
+		if err := s.publisher.PublishScore(ctx, score); err != nil {
+			fmt.Printf("Failed to publish score of user %s on story %d: %v\n", score.UserID, score.StoryID, err)
+		}
+	}()
+}
+
+const scorePublishTimeout = 30 * time.Second
+
+// SaveLTIPlatform registers a platform, or updates the registration with the same issuer and client id
+func (s *Service) SaveLTIPlatform(ctx context.Context, platform LTIPlatform) (*LTIPlatform, error) {
+	result, err := s.queries.UpsertLTIPlatform(ctx, db.UpsertLTIPlatformParams{
+		Name:         platform.Name,
+		Issuer:       platform.Issuer,
+		ClientID:     platform.ClientID,
+		DeploymentID: platform.DeploymentID,
+		AuthLoginUrl: platform.AuthLoginURL,
+		AuthTokenUrl: platform.AuthTokenURL,
+		JwksUrl:      platform.JWKSURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	saved := ltiPlatformFromDB(result)
+	return &saved, nil
+}
+
+// GetLTIPlatform retrieves a platform by ID
+func (s *Service) GetLTIPlatform(ctx context.Context, id int32) (*LTIPlatform, error) {
+	result, err := s.queries.GetLTIPlatform(ctx, id)
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	platform := ltiPlatformFromDB(result)
+	return &platform, nil
+}
+
+// FindLTIPlatform finds the platform registered with issuer and clientID. Platforms may
+// leave out the client id when logging in, which only works while the issuer has one registration.
+func (s *Service) FindLTIPlatform(ctx context.Context, issuer, clientID string) (*LTIPlatform, error) {
+	results, err := s.queries.GetLTIPlatformsByIssuer(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *LTIPlatform
+	for _, result := range results {
+		if clientID != "" && result.ClientID != clientID {
+			continue
+		}
+		if found != nil {
+			return nil, ErrAmbiguousLTIPlatform
+		}
+		platform := ltiPlatformFromDB(result)
+		found = &platform
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+// ListLTIPlatforms returns every registered platform
+func (s *Service) ListLTIPlatforms(ctx context.Context) ([]LTIPlatform, error) {
+	results, err := s.queries.ListLTIPlatforms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := make([]LTIPlatform, len(results))
+	for i, result := range results {
+		platforms[i] = ltiPlatformFromDB(result)
+	}
+	return platforms, nil
+}
+
+// DeleteLTIPlatform removes a platform along with its context, user and line item mappings.
+// Courses and users created by its launches are kept.
+func (s *Service) DeleteLTIPlatform(ctx context.Context, id int32) error {
+	return s.queries.DeleteLTIPlatform(ctx, id)
+}
+
+// LinkLTIUser returns the user behind a platform user, creating the mapping on first launch.
+// A new mapping always gets a new user whose id is derived from the platform and subject:
+// platforms vouch for their own users only, so a launch is never matched to an existing
+// user by email. LinkLTIAccount moves the mapping to an existing user.
+func (s *Service) LinkLTIUser(ctx context.Context, platformID int32, subject, email, name string) (*User, error) {
+	link, err := s.queries.GetLTIUser(ctx, db.GetLTIUserParams{PlatformID: platformID, Subject: subject})
+	if err == nil {
+		if link.UserID == ltiUserID(platformID, subject) {
+			// Users that only exist through the LMS follow its profile
+			return s.upsertLTIUser(ctx, link.UserID, email, name)
+		}
+		return s.GetUser(ctx, link.UserID)
+	}
+	if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	user, err := s.upsertLTIUser(ctx, ltiUserID(platformID, subject), email, name)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.queries.CreateLTIUser(ctx, db.CreateLTIUserParams{
+		PlatformID: platformID,
+		Subject:    subject,
+		UserID:     user.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// upsertLTIUser saves an LTI-created user. An email that belongs to another user is not
+// taken over; the LTI user gets a placeholder address instead, as emails are unique.
+func (s *Service) upsertLTIUser(ctx context.Context, userID, email, name string) (*User, error) {
+	existing, err := s.queries.GetUserByEmail(ctx, email)
+	if err == nil && existing.UserID != userID || email == "" {
+		email = userID + "@lti.invalid"
+	} else if err != nil && err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return nil, err
+	}
+	return s.UpsertUser(ctx, userID, email, name)
+}
+
+// LinkLTIAccount points the platform users behind an LTI-created user at userID, so later
+// launches sign in as userID. The caller must have authenticated as both. It returns
+// ErrNotFound if ltiUser is not a user created by a launch.
+func (s *Service) LinkLTIAccount(ctx context.Context, ltiUser, userID string) error {
+	if !strings.HasPrefix(ltiUser, ltiUserPrefix) || strings.HasPrefix(userID, ltiUserPrefix) {
+		return ErrNotFound
+	}
+	linked, err := s.queries.RelinkLTIUser(ctx, db.RelinkLTIUserParams{ToUserID: userID, FromUserID: ltiUser})
+	if err != nil {
+		return err
+	}
+	if linked == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordLTIUserContext notes that a platform user launched from a context, which makes
+// the context's gradebook receive the user's scores
+func (s *Service) RecordLTIUserContext(ctx context.Context, platformID int32, subject string, ltiContextID int32) error {
+	return s.queries.AddLTIUserContext(ctx, db.AddLTIUserContextParams{
+		PlatformID:   platformID,
+		Subject:      subject,
+		LtiContextID: ltiContextID,
+	})
+}
+
+// LinkLTIContext returns the mapping of a platform context and records its latest title and
+// line items service. The first instructor launch creates a course for the context; before
+// that the context returns ErrNotFound.
+func (s *Service) LinkLTIContext(ctx context.Context, platformID int32, contextID, title, lineItemsURL string, instructor bool) (*LTIContext, error) {
+	existing, err := s.queries.GetLTIContext(ctx, db.GetLTIContextParams{PlatformID: platformID, ContextID: contextID})
+	if err == nil {
+		if lineItemsURL == "" {
+			lineItemsURL = existing.LineitemsUrl
+		}
+		if title == "" {
+			title = existing.Title
+		}
+		result, err := s.queries.UpdateLTIContext(ctx, db.UpdateLTIContextParams{
+			ID:           existing.ID,
+			Title:        title,
+			LineitemsUrl: lineItemsURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		link := ltiContextFromDB(result)
+		return &link, nil
+	}
+	if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return nil, err
+	}
+	if !instructor {
+		return nil, ErrNotFound
+	}
+
+	var link LTIContext
+	err = s.withTransaction(ctx, func(ctx context.Context) error {
+		name := title
+		if name == "" {
+			name = contextID
+		}
+		course, err := s.CreateCourse(ctx, fmt.Sprintf("LTI-%d-%s", platformID, contextID), name, "")
+		if err != nil {
+			return err
+		}
+		result, err := s.queries.CreateLTIContext(ctx, db.CreateLTIContextParams{
+			PlatformID:   platformID,
+			ContextID:    contextID,
+			CourseID:     course.CourseID,
+			Title:        title,
+			LineitemsUrl: lineItemsURL,
+		})
+		if err != nil {
+			return err
+		}
+		link = ltiContextFromDB(result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// EnrollLTIUser gives a launching user access to the course of a context: instructors become
+// course admins and everyone else is enrolled. Repeated launches leave access as it is.
+func (s *Service) EnrollLTIUser(ctx context.Context, courseID int32, userID string, instructor bool) error {
+	if instructor {
+		if s.IsUserOnlyCourseAdmin(ctx, userID, courseID) {
+			return nil
+		}
+		_, err := s.AddCourseAdmin(ctx, courseID, userID)
+		return err
+	}
+
+	// Enrolling skips users that are already enrolled
+	return s.enrollmentChanged(int(courseID), s.queries.AddMultiUsersToCourse(ctx, db.AddMultiUsersToCourseParams{
+		CourseID: courseID,
+		Column2:  []string{userID},
+	}))
+}
+
+// GetLTILineItem returns the line item that scores of a story are posted to in a context
+func (s *Service) GetLTILineItem(ctx context.Context, ltiContextID int32, storyID int) (string, error) {
+	result, err := s.queries.GetLTILineItem(ctx, db.GetLTILineItemParams{
+		LtiContextID: ltiContextID,
+		StoryID:      int32(storyID),
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return result.LineItemUrl, nil
+}
+
+// SaveLTILineItem records the line item that scores of a story are posted to in a context
+func (s *Service) SaveLTILineItem(ctx context.Context, ltiContextID int32, storyID int, lineItemURL string) error {
+	return s.queries.UpsertLTILineItem(ctx, db.UpsertLTILineItemParams{
+		LtiContextID: ltiContextID,
+		StoryID:      int32(storyID),
+		LineItemUrl:  lineItemURL,
+	})
+}
+
+// GetLTIScoreTargets returns the line items services a user's scores on a story go to:
+// one per context of the story's course that the user has launched from (see RecordLTIUserContext)
+func (s *Service) GetLTIScoreTargets(ctx context.Context, userID string, storyID int) ([]LTIScoreTarget, error) {
+	results, err := s.queries.GetLTIScoreTargets(ctx, db.GetLTIScoreTargetsParams{
+		UserID:  userID,
+		StoryID: int32(storyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]LTIScoreTarget, len(results))
+	for i, result := range results {
+		targets[i] = LTIScoreTarget{
+			PlatformID:   result.PlatformID,
+			Subject:      result.Subject,
+			LTIContextID: result.LtiContextID,
+			CourseID:     result.CourseID,
+			LineItemsURL: result.LineitemsUrl,
+		}
+	}
+	return targets, nil
+}
+
+// ltiUserPrefix starts the id of every user created by an LTI launch
+const ltiUserPrefix = "lti_"
+
+// ltiUserID is the id of a user created by an LTI launch
+func ltiUserID(platformID int32, subject string) string {
+	return fmt.Sprintf("%s%d_%s", ltiUserPrefix, platformID, subject)
+}
+
+func ltiPlatformFromDB(result db.LtiPlatform) LTIPlatform {
+	return LTIPlatform{
+		ID:           result.ID,
+		Name:         result.Name,
+		Issuer:       result.Issuer,
+		ClientID:     result.ClientID,
+		DeploymentID: result.DeploymentID,
+		AuthLoginURL: result.AuthLoginUrl,
+		AuthTokenURL: result.AuthTokenUrl,
+		JWKSURL:      result.JwksUrl,
+		CreatedAt:    result.CreatedAt.Time,
+	}
+}
+
+func ltiContextFromDB(result db.LtiContext) LTIContext {
+	return LTIContext{
+		ID:           result.ID,
+		PlatformID:   result.PlatformID,
+		ContextID:    result.ContextID,
+		CourseID:     result.CourseID,
+		Title:        result.Title,
+		LineItemsURL: result.LineitemsUrl,
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/database"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestLinkLTIUserIgnoresEmailMatch(t *testing.T) {
+	mock := database.NewMockDBTX()
+	victim := []interface{}{"user_admin", "admin@school.test", "Admin", pgtype.Bool{}, pgtype.Timestamp{}, pgtype.Timestamp{}}
+	mock.StubQuery("-- name: GetUserByEmail", [][]interface{}{victim}, nil)
+	mock.StubQuery("-- name: GetUser :one", [][]interface{}{victim}, nil)
+	mock.StubQuery("-- name: UpsertUser", [][]interface{}{
+		{"lti_1_sub-1", "lti_1_sub-1@lti.invalid", "Mallory", pgtype.Bool{}, pgtype.Timestamp{}, pgtype.Timestamp{}},
+	}, nil)
+	mock.StubQuery("-- name: CreateLTIUser", [][]interface{}{
+		{int32(1), "sub-1", "lti_1_sub-1", pgtype.Timestamp{}},
+	}, nil)
+	svc := NewService(mock, nil, nil, nil)
+
+	user, err := svc.LinkLTIUser(context.Background(), 1, "sub-1", "admin@school.test", "Mallory")
+	if err != nil {
+		t.Fatalf("LinkLTIUser failed: %v", err)
+	}
+	if user.UserID != "lti_1_sub-1" {
+		t.Errorf("Expected a new LTI user, got %q", user.UserID)
+	}
+}
+
+func TestLinkLTIAccountOnlyMovesLTIUsers(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	ctx := context.Background()
+	if err := svc.LinkLTIAccount(ctx, "user_admin", "user_other"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a user not created by a launch, got %v", err)
+	}
+	if err := svc.LinkLTIAccount(ctx, "lti_1_sub-1", "lti_2_sub-2"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound when linking to another LTI user, got %v", err)
+	}
+}
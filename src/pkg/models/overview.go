@@ -5,7 +5,7 @@ package models
 /*
 Core Types:
 - Story: {Metadata: StoryMetadata, Content: StoryContent}
-- StoryMetadata: {StoryID, WeekNumber, DayLetter, Title(map[lang]string), Author, VideoURL, Description, LastRevision}
+- StoryMetadata: {StoryID, WeekNumber, DayLetter, Title(map[lang]string), Author, VideoURL, Description, LastRevision, Schedule *StorySchedule}
 - StoryLine: {LineNumber, Text, Translations[lang]string, Vocabulary[], Grammar[], AudioFiles[], Footnotes[]}
 - LineTranslation: {StoryID, LineNumber, LanguageCode, TranslationText}
 - VocabularyItem: {Word, LexicalForm, Position[2]int}
@@ -13,14 +13,21 @@ Core Types:
 - Footnote: {ID, Text, References[]string}
 - AudioFile: {ID, FilePath, FileBucket, Label}
 - GrammarPoint: {ID, Name, Description}
+- StoryImage: {ID, StoryID, FilePath, FileBucket, Label, ContentType, SizeBytes}
+- TargetVocab: {ID, StoryID, LexicalForm, AudioPath, AudioBucket, ImagePath, ImageBucket, Occurrences}
+- SignedTargetVocab: {TargetVocab, AudioURL, ImageURL}
+- RecallSentence: {ID, StoryID, SequenceOrder, Text, TargetVocabID, ImageID}
+- ProduceSegment: {ID, StoryID, StartLine, EndLine, EnglishPrompt, ReferenceText, GrammarPointID}
+- ProduceSubmission: {ID, UserID, StoryID, SegmentID, StudentText, SubmittedAt, UpdatedAt, AIScore *int, AIFeedback, GradingStatus}
 
 Database Functions (SQLC-based):
-GetStoryData(id int, userID string) (*Story, error) // Full story with all components (cached)
-GetAllStories(language string, userID string) ([]Story, error) // Basic story list (NOT cached - user-specific access controls)
+GetStoryData(id int, userID string) (*Story, error) // Full story with all components (cached); ErrNotFound before the story is available unless the user can edit it
+GetAllStories(language string, userID string) ([]Story, error) // Basic story list (NOT cached - user-specific access controls); hides unreleased stories from non-admins
 GetLineAnnotations(storyID, lineNumber int) (*StoryLine, error)
 GetStoryAnnotations(storyID int) (map[int]*StoryLine, error)
 GetLineText(storyID, lineNumber int) (string, error)
-GetStoriesForCourse(courseID int) ([]Stories, err) // Returns just the basic metadata
+GetStoriesForCourse(courseID int) ([]Stories, err) // Returns just the basic metadata and schedules, released or not
+GetAvailableStoriesForCourse(courseID int, userID string) ([]Story, error) // GetStoriesForCourse without stories not yet available to the user
 
 Translation Operations:
 GetLineTranslation(storyID, lineNumber int, languageCode string) (string, error)
@@ -29,6 +36,7 @@ GetAllTranslationsForStory(storyID int) ([]LineTranslation, error)
 GetTranslationsByLanguage(storyID int, languageCode string) ([]LineTranslation, error)
 DeleteLineTranslation(storyID, lineNumber int, languageCode string) error
 DeleteStoryTranslations(storyID int) error
+UpsertLineTranslations(ctx, storyID int, languageCode string, translations map[int]string) error // One transaction and one revision
 
 Translation Request Operations:
 GetTranslationRequest(userID string, storyID int) (*TranslationRequest, error)
@@ -50,24 +58,103 @@ GetStoryAudioFilesByLabel(storyID int, label string) ([]AudioFile, error)
 GetAllStoryAudioFiles(storyID int) ([]AudioFile, error)
 DeleteLineAudioFiles(storyID, lineNumber int) error
 
+Image Operations (bucket ImageBucket, max MaxImageBytes):
+ValidateImageUpload(fileName, contentType string, size int64) error // Pure check before issuing an upload URL
+ValidateImageLabel(label string) error // Labels are part of the storage path, so they may not contain slashes
+VerifyUploadedImage(fileBucket, filePath string) (int64, error) // Stats the exact object; confirms it is within the limit, removes it if not
+CreateStoryImage(storyID int, filePath, fileBucket, label, contentType string) (*StoryImage, error) // Size is read from storage
+GetStoryImage(imageID int) (*StoryImage, error)
+GetStoryImages(storyID int) ([]StoryImage, error)
+DeleteStoryImage(imageID int) error // Removes from storage, then the record
+GetSignedImageURL(imageID int, userID string, expiresInSeconds int) (string, error) // Checks course access
+GetSignedImageURLsForStory(storyID int, userID string, expiresInSeconds int) (map[int]string, error) // Keyed by image ID
+SignAssetURL(fileBucket, filePath string, expiresInSeconds int) (string, error) // No access check; for assets referenced by other records
+
+Target Vocabulary Operations (max MaxTargetVocab per story, each lexical form annotated >= MinTargetVocabOccurrences times):
+GetStoryTargetVocab(storyID int) ([]TargetVocab, error) // Includes occurrence counts from vocabulary_items, in one query
+GetTargetVocab(storyID, targetVocabID int) (*TargetVocab, error) // ErrNotFound if the word belongs to another story
+CreateTargetVocab(storyID int, lexicalForm string) (*TargetVocab, error) // ErrTargetVocabLimit, ErrTargetVocabTooFewOccurrences, ErrTargetVocabExists; checked with the story row locked
+UpdateTargetVocabLexicalForm(storyID, targetVocabID int, lexicalForm string) (*TargetVocab, error) // Same validation, keeps assets
+SetTargetVocabAudio(storyID, targetVocabID int, filePath, fileBucket string) (*TargetVocab, error) // Removes the replaced file from storage
+SetTargetVocabImage(storyID, targetVocabID int, filePath, fileBucket string) (*TargetVocab, error) // Removes the replaced file from storage
+DeleteTargetVocab(storyID, targetVocabID int) error // Removes assets from storage, then the record
+DeleteStoryTargetVocab(storyID int) error
+GetSignedStoryTargetVocab(storyID int, userID string, expiresInSeconds int) ([]SignedTargetVocab, error) // Checks story access; empty URL when an asset is missing
+
+Recall Operations (max MaxRecallSentences per story, each linked to a target word and image of the story):
+GetStoryRecallSentences(storyID int) ([]RecallSentence, error) // In correct order
+GetRecallSentence(storyID, sentenceID int) (*RecallSentence, error) // ErrNotFound if the sentence belongs to another story
+CreateRecallSentence(storyID int, text string, targetVocabID, imageID int) (*RecallSentence, error) // Appends; ErrRecallSentenceLimit, ErrInvalidRecallTarget, ErrInvalidRecallImage
+UpdateRecallSentence(storyID, sentenceID int, text string, targetVocabID, imageID int) (*RecallSentence, error) // Keeps position
+ReorderRecallSentences(storyID int, sentenceIDs []int) ([]RecallSentence, error) // Transaction; ErrInvalidRecallOrder unless every sentence is listed once
+DeleteRecallSentence(storyID, sentenceID int) error // Renumbers the remaining sentences
+CheckRecallOrder(sentences []RecallSentence, submittedIDs []int) ([]bool, error) // Per-position correctness, no database access
+SaveRecallAttempt(ctx, userID string, storyID int, submittedIDs []int, results []bool) error // Correct placements logged once per sentence, every misplacement logged
+
+Produce Operations (max MaxProduceSegments per story, each linked to a grammar point of the story):
+GetStoryProduceSegments(storyID int) ([]ProduceSegment, error) // Ordered by start line
+GetProduceSegment(storyID, segmentID int) (*ProduceSegment, error) // ErrNotFound if the segment belongs to another story
+CreateProduceSegment(storyID int, segment ProduceSegment) (*ProduceSegment, error) // ErrProduceSegmentLimit, ErrInvalidProduceLines, ErrInvalidProduceGrammarPoint
+UpdateProduceSegment(storyID, segmentID int, segment ProduceSegment) (*ProduceSegment, error)
+DeleteProduceSegment(storyID, segmentID int) error // Submissions cascade
+GetProduceExplanation(storyID int) (string, error) // "" when unset
+SetProduceExplanation(storyID int, explanation string) (string, error) // Empty text removes it
+SaveProduceSubmission(ctx, userID string, storyID, segmentID int, studentText string) (*ProduceSubmission, error) // Upsert per user/segment, graded on save; unchanged text keeps its grade
+GetUserProduceSubmissions(ctx, userID string, storyID int) (map[int]ProduceSubmission, error) // Keyed by segment ID
+RegradePendingProduceSubmissions(ctx, limit int) (int, error) // Retries failed grading, up to MaxGradingAttempts per submission
+StartProduceRegrader(ctx, interval time.Duration, batchSize int) // Background RegradePendingProduceSubmissions loop
+
+Produce Grading (fails open: a grader error leaves AIScore nil and GradingStatus GradingPending):
+- Grader interface: Grade(ctx, referenceText, studentText string, grammarPoint *GrammarPoint) (score 0-100, feedback string, error)
+- LocalGrader: normalized edit distance ignoring diacritics/niqqud, punctuation and case; deterministic, offline
+- NewLLMGrader(baseURL, apiKey, model) (*LLMGrader, error) // OpenAI-compatible chat completions API
+
 Save Operations (SQLC-based):
 SaveNewStory(*Story) error // Uses CreateStory, UpsertStoryTitle, UpsertStoryDescription, UpsertStoryLine
 SaveStoryData(storyID int, story *Story) error // Uses UpdateStory and component upserts
 
 Edit Operations (SQLC-based):
-EditStoryText(storyID int, lines []StoryLine) error // Uses DeleteAllStoryLines, UpsertStoryLine
-EditStoryMetadata(storyID int, metadata StoryMetadata) error // Uses UpdateStory, DeleteStoryTitles/Descriptions, Upserts
-AddLineAnnotations(storyID, lineNumber int, line StoryLine) error // Uses dedup insert functions
+EditStoryText(storyID int, lines []StoryLine, dropAnswered bool) (*LineEditReport, error) // Pairs identical lines, then nearby changed lines by similarity; kept lines are renumbered in place (ON UPDATE CASCADE) along with produce segments, translation requests and selected grammar lines, annotations of edited lines remapped or dropped and reported; ErrEditOrphansAnswers unless dropAnswered when answered vocabulary would be deleted
+EditStoryMetadata(storyID int, metadata StoryMetadata) error // Uses UpdateStory, DeleteStoryTitles/Descriptions, Upserts; grammar points with an existing ID are updated in place, others created, missing ones deleted
+AddLineAnnotations(storyID, lineNumber int, line StoryLine) error // Uses dedup insert functions; newly inserted single-word vocabulary is learned by the lexicon
 UpdateVocabularyAnnotation(storyID, lineNumber int, position [2]int, vocab VocabularyItem) error // Uses UpdateVocabularyByPosition
 UpdateVocabularyByWord(storyID, lineNumber int, word string, newLexicalForm string) error // Uses UpdateVocabularyByWord
 UpdateGrammarAnnotation(storyID, lineNumber int, position [2]int, grammar GrammarItem) error // Uses UpdateGrammarByPosition
 UpdateFootnoteAnnotation(storyID, footnoteID int, footnote Footnote) error // Uses UpdateFootnote, DeleteFootnoteReferences, CreateFootnoteReference
 ClearStoryAnnotations(storyID int) error // Uses StoryExists and raw SQL for complex deletes
 ClearLineAnnotations(storyID, lineNumber int) error // Uses raw SQL for complex deletes
+// Every save and edit above, imports and course clones record a story revision in the same transaction, as do
+// translation, audio, image, target vocabulary, grammar point and schedule writes
+
+Story Revision Types:
+- StoryRevision: {Number, StoryID, Action, CreatedBy, CreatedAt, Story *Story, Translations} // Story and translations only for a single revision
+- StoryRevisionDiff: {From, To, MetadataChanges []string, Lines []LineDiff{Op, OldLine, NewLine, Text, AnnotationsChanged}} // Op LineUnchanged, LineAdded or LineRemoved
+
+Story Revision Operations (story_revisions: full Story JSON with schedule and translations after every admin write, numbered per story under a lock on the story row):
+WithEditor(ctx, userID string) context.Context // Names the admin in revisions written with ctx; set for every /api/admin/stories request
+GetStoryRevisions(ctx, storyID int) ([]StoryRevision, error) // Newest first, without snapshots
+GetStoryRevision(ctx, storyID, number int) (*StoryRevision, error) // ErrNotFound
+DiffStoryRevisions(ctx, storyID, from, to int) (*StoryRevisionDiff, error) // Lines matched by text (longest common subsequence); an edited line is removed and added
+RollbackStory(ctx, storyID, number int) error // Restores metadata, lines, vocab, grammar, footnotes and translations, then records a "rollback to revision N" revision
+// Surviving vocabulary items keep their IDs and answers; ErrRollbackOrphansAnswers if a deleted item has answers.
+// The story stays in its course, grammar points added since are kept and audio is not restored (files of removed lines are deleted).
 
 
-Delete Operations (SQLC-based):
-Delete(storyID int) error // Uses StoryExists, DeleteStory, and component delete functions
+Delete Operations (SQLC-based; stories and courses go to the trash, deleted_at set, and are hidden from every listing and access check):
+Delete(storyID int) error // Uses TrashStory; ErrNotFound if missing or already trashed
+DeleteCourse(courseID int32) error // Trashes the course and its stories with one deleted_at
+GetTrash(ctx, userID string) (*Trash, error) // Everything for super admins, stories of their courses for course admins
+RestoreStory(ctx, storyID int) error // ErrNotFound, ErrCourseInTrash
+CanUserRestoreStory(ctx, userID string, storyID int32) bool // Uses GetTrashedStory; CanUserEditStory and IsUserCourseOrSuperAdmin refuse trashed stories and courses
+RestoreCourse(ctx, courseID int32) error // Restores the stories trashed with the course, not those deleted earlier; ErrCourseNumberTaken if a live course has its number (course_number is only unique outside the trash)
+PurgeExpiredTrash(ctx, retention time.Duration) (stories, courses int, error) // Hard delete: DeleteStory and component delete functions, then files not shared with another story
+StartTrashPurge(ctx, interval, retention time.Duration) // Background PurgeExpiredTrash loop
+
+Audit Operations (audit_events, one row per admin request that changes something):
+HasAuditSnapshot(targetType string) bool // AuditTargetStory and AuditTargetCourse
+AuditSnapshot(ctx, targetType, targetID string) (json.RawMessage, error) // Whole story, or course with admins and users; nil if missing
+RecordAuditEvent(ctx, event AuditEvent) error
+ListAuditEvents(ctx, filter AuditFilter) ([]AuditEvent, error) // Newest first; DefaultAuditLimit, at most MaxAuditLimit
 
 Score Operations:
 SaveVocabScore(ctx context.Context, userID string, storyID int, lineNumber int, correct bool, incorrectAnswer string) error
@@ -80,6 +167,22 @@ GetUserGrammarIncorrectAnswers(ctx context.Context, userID string, storyID int,
 GetUserVocabScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) // Returns map[lineNumber]correct
 GetUserGrammarScores(ctx context.Context, userID string, storyID int) (map[int]bool, error) // Returns map[lineNumber]correct
 GetLinesWithoutVocabForUser(ctx context.Context, userID string, storyID int) ([]VocabItem, error) // Returns line/position pairs not yet correctly answered by user
+SaveIdentifyScore(ctx, userID string, storyID, lineNumber, targetVocabID int, correct bool, selectedTargetVocabID int) error // Identify picture pick; wrong picks go to identify_incorrect_answers
+GetUserStoryRecallSummary(ctx context.Context, userID string, storyID int32) (*UserStoryRecallSummary, error) // Correct/incorrect counts for CalculateScoreWithRetriesAllowed
+GetUserIdentifyScores(ctx, userID string, storyID int) (map[int]map[int]bool, error) // Returns map[lineNumber]map[targetVocabID]identified
+StartIdentifyAttempt(ctx, userID string, storyID int, targets []SignedTargetVocab) ([]IdentifyOption, error) // New opaque option IDs per picture; replaces the user's identify_options for the story
+ResolveIdentifyOption(ctx, userID string, storyID int, optionID string) (int, error) // Target vocabulary ID behind an option; ErrIdentifyOptionExpired for older attempts
+
+Score Snapshots (story_scores is append-only; the newest row per user/story is the current score):
+- StoryScore: {ID, UserID, StoryID, FormulaVersion, Reason, Vocab/Grammar/Recall Correct, Incorrect, Total, Accuracy, TranslationCompleted, *TimeSeconds, OverallAccuracy, Late, ComputedAt}
+- ScoreFormulaVersion: bump when CalculateScoreWithRetriesAllowed or ComputeStoryScore change
+ComputeStoryScore(ctx, userID string, storyID int) (*StoryScore, error) // Live score from the answer logs, not saved
+SaveStoryScoreSnapshot(ctx, score *StoryScore, reason string) (*StoryScore, bool, error) // Skips unchanged scores; reason ScoreReasonScorePage or ScoreReasonRecompute; Late if past due (recomputes keep the previous Late); new snapshots go to the ScorePublisher
+GetLatestStoryScore(ctx, userID string, storyID int) (*StoryScore, error) // ErrNotFound without snapshots
+GetStoryScoreHistory(ctx, userID string, storyID int) ([]StoryScore, error) // Newest first
+GetStoryLatestScores(ctx, storyID int) ([]StoryScore, error) // Newest snapshot per student
+StartScoreRecompute() (ScoreRecomputeStatus, bool) // Background recompute of every scored user/story; false if already running
+GetScoreRecomputeStatus() ScoreRecomputeStatus
 
 User Operations (SQLC-based):
 UpsertUser(userID, email, name string) (*User, error) // Uses UpsertUser
@@ -100,6 +203,89 @@ GetCoursesForUser(userID string) ([]UserCourse, error) // Uses GetCoursesForUser
 GetCoursesForUserByStatus(userID string, status string) ([]UserCourse, error) // Gets courses filtered by status
 GetUsersForCourse(courseID int) ([]CourseUser, error) // Uses GetUsersForCourse
 GetStoryStudentPerformance(ctx context.Context, storyID int32, status string) ([]CourseStudentPerformance, error) // Gets performance data for students filtered by course status
+GetCourseGradebook(ctx context.Context, courseID int32, status string) (*Gradebook, error) // Every student x every story of a course, built from GetStoryStudentPerformance; Late from the latest snapshots
+GetCourseAnalytics(ctx context.Context, courseID int32, status string) (*CourseAnalytics, error) // Phase funnel, median phase times, accuracy histograms, most-missed vocab and grammar; cached per course and status
+InvalidateCourseAnalytics(courseID int) // Drops cached analytics for every status filter; called on enrollment changes and when a story moves between courses
+InvalidateVocabBankOrder(storyID int) // Called by CreateVocabContrast and DeleteVocabContrast
+InvalidateStoryCourseAnalytics(ctx, storyID int) // Called by every answer save path (vocab, grammar, identify, translate, recall, produce, score snapshots); the story's course comes from the StoryCourse key the access checks fill
+
+Grammar Heatmap Types:
+- GrammarHeatmap: {StoryID, GrammarPointID, GrammarPointName, MaxClicks, CorrectClicks, IncorrectClicks, Lines []HeatmapLine}
+- HeatmapLine: {LineNumber, Text, RTL, Characters []HeatmapCharacter} // One character per rune
+- HeatmapCharacter: {Char, Correct, Incorrect, Intensity} // Intensity 0-1 relative to MaxClicks (capped at HeatmapClickCap)
+
+Grammar Heatmap Operations:
+GetGrammarHeatmap(ctx, storyID, grammarPointID int) (*GrammarHeatmap, error) // Correct answers spread over the point's instances, wrong selections on exact positions; ErrNotFound if the point is in another story
+GetStoryGrammarHeatmaps(ctx, storyID int) ([]GrammarHeatmap, error) // One heatmap per grammar point
+
+Vocab Confusion Types:
+- VocabConfusion: {LexicalForm, Answer, Count, Students, InBank, Contrast} // Answer given where LexicalForm was expected
+- VocabConfusionMatrix: {Forms, Answers, Counts [][]int, Confusions, Contrasts} // Counts[form][answer]
+- VocabContrastPair: {ID, StoryID, FormA, FormB} // FormA < FormB
+
+Vocab Confusion Operations:
+GetStoryVocabConfusions(ctx, storyID int) (*VocabConfusionMatrix, error)
+GetCourseVocabConfusions(ctx, courseID int32, status string) (*VocabConfusionMatrix, error) // status filters course_users like GetCourseAnalytics
+GetStoryVocabContrasts(ctx, storyID int) ([]VocabContrastPair, error)
+CreateVocabContrast(ctx, storyID int, form, other string) (*VocabContrastPair, error) // Idempotent; one form must be in the story (ErrInvalidVocabContrast, ErrVocabContrastNotInStory)
+DeleteVocabContrast(ctx, storyID, pairID int) error // ErrNotFound if the pair is in another story
+OrderVocabBank(ctx, storyID int, forms []string) ([]string, error) // Contrast pairs first (adding missing partners as distractors), then most mixed-up forms, then alphabetical
+// Cached per story (VocabBankOrder key) for 10 minutes; contrast changes and a different set of forms rebuild it
+
+Lexicon Types (lexicon_entries: language_code, lower-case surface_form -> lexical_form with occurrences; a story's language is its description's):
+- VocabSuggestion: {LineNumber, Word, LexicalForm, Position, Occurrences, Alternatives} // Position in runes, end exclusive
+
+Lexicon Operations:
+SuggestVocabulary(ctx, storyID int) ([]VocabSuggestion, error) // Tokenizes lines; skips annotated words and rejected suggestions; none without a description
+// SuggestVocabulary and ReviewVocabSuggestions return ErrAmbiguousStoryLanguage for descriptions in several languages; edits then skip the lexicon
+ReviewVocabSuggestions(ctx, storyID int, accepted, rejected []VocabSuggestion) (int, error) // Adds and learns accepted, records rejected (vocab_suggestion_rejections); ErrSuggestionMismatch
+// Story saves and AddLineAnnotations learn the vocabulary they insert; UpdateVocabularyAnnotation and UpdateVocabularyByWord
+// unlearn the old mapping (UnlearnLexiconEntry, dropped at zero) and learn the new one
+
+Story Schedule Types (story_schedules; no row means always open):
+- StorySchedule: {AvailableFrom, DueAt, LateUntil *time.Time} // Available(now), PastDue(now), Closed(now)
+
+Story Schedule Operations:
+GetStorySchedule(ctx, storyID int) (*StorySchedule, error) // Not cached; empty schedule without a row
+SetStorySchedule(ctx, storyID int, schedule StorySchedule) (*StorySchedule, error) // ErrInvalidStorySchedule unless times are in order
+ShiftCourseSchedule(ctx, courseID int32, days int) (int, error) // Moves every time of the course's untrashed schedules and its at-risk starts_on in one transaction; returns schedules moved
+CheckStoryOpen(ctx, userID string, storyID int) error // ErrStoryClosed after LateUntil unless the user can edit the story; checked by every answer save handler
+
+Story Bundle Types (.glossias zip: manifest.json, story.json from Story.ToJSON, files/{bucket}/{path}):
+- StoryBundleManifest: {Format, Version, ExportedAt, Descriptions, Translations, Images, TargetVocab, RecallSentences, ProduceSegments, ProduceExplanation, VocabContrasts, PhaseFlow} // StoryBundleFormat, StoryBundleVersion 2; version 1 still imports
+
+Story Bundle Operations:
+ExportStoryBundle(ctx, storyID int, userID string) ([]byte, error) // Story, descriptions, translations, images, target vocab, recall, produce, contrasts, phase flow and every file; not the schedule
+ImportStoryBundle(ctx, courseID int, userID string, data []byte) (int, error) // New story authored by userID with files under stories/{newID}/; ErrInvalidStoryBundle, ErrUnsupportedBundleVersion
+// Positions are checked in runes against line text and links between parts are remapped; the story ID is reserved and files
+// uploaded before the transaction, and removed if saving fails
+
+Course Clone Types:
+- CourseCloneOptions: {CourseNumber, Name, Description *string, CopyAdmins} // Empty Name and nil Description keep the original's
+- CourseClone: {Course, Stories []ClonedStory{SourceStoryID, StoryID}, Admins}
+
+Course Clone Operations:
+CloneCourse(ctx, courseID int32, opts CourseCloneOptions) (*CourseClone, error) // One transaction; ErrMissingCourseNumber, ErrCourseNumberTaken
+// Copies stories with titles, descriptions, lines, translations, annotations, grammar points, target vocab,
+// recall, produce, phase flows, contrast pairs and schedules. Audio and image rows share the original's
+// storage objects; every delete path skips objects another story still uses (CountStorageObjectUses).
+// Enrollment, answers and scores are not copied.
+
+At-Risk Types:
+- RiskThresholds: {StartsOn "YYYY-MM-DD", GraceDays, MaxIncorrectRatio, MinAnswers, MinPhaseSeconds, DigestEnabled} // DefaultRiskThresholds without a course_risk_settings row
+- RiskSignal: {Kind, StoryID, StoryTitle, Phase, Value, Threshold} // Kind RiskNotStarted, RiskHighIncorrect or RiskShortPhaseTime
+- AtRiskStudent: {UserID, UserName, Email, Signals}
+- CourseAtRisk: {CourseID, CurrentWeek, Thresholds, Students, ComputedAt}
+- RiskNotifier interface: SendRiskDigest(ctx, digest RiskDigest) error // LogRiskNotifier prints digests
+- RiskDigest: {Course, Recipients []CourseAdmin, AtRisk}
+
+At-Risk Operations:
+GetCourseRiskThresholds(ctx, courseID int32) (*RiskThresholds, error)
+SetCourseRiskThresholds(ctx, courseID int32, thresholds RiskThresholds) (*RiskThresholds, error) // ErrInvalidRiskThresholds
+GetCourseAtRisk(ctx, courseID int32) (*CourseAtRisk, error) // Active students only, from the gradebook, phase activity and tracked time
+SetRiskNotifier(n RiskNotifier)
+SendRiskDigests(ctx) (int, error) // One digest per course with DigestEnabled and students at risk
+StartRiskDigest(ctx, hour int) // Runs SendRiskDigests daily at hour
 
 Time Tracking Types:
 - TimeTrackingSession: {SessionID, UserID, Route, StoryID}
@@ -113,19 +299,56 @@ GetTimeEntriesForUser(ctx, userID) ([]db.UserTimeTracking, error) // Gets user's
 GetTimeEntriesForStory(ctx, storyID) ([]db.UserTimeTracking, error) // Gets story's time entries
 
 Navigation Types:
-- PageType: string constants for "video", "vocab", "translate", "grammar", "score"
+- PageType: string constants for "video", "vocab", "identify", "translate", "grammar", "recall", "produce", "score"
 - NavigationGuidanceRequest: {UserId, CurrentPage, StoryId}
 - NavigationGuidanceResponse: {NextPage}
+- PhaseFlow: {Phases []string, Source} // Source is "story", "course" or "default"
+- KnownPhases, DefaultPhaseFlow (video, vocab, translate, grammar, score)
 
 Navigation Operations:
-Navigate(storyID, currentPage, userID) (*NavigationGuidanceResponse, error) // Determines next page in learning flow
+Navigate(storyID, currentPage, userID) (*NavigationGuidanceResponse, error) // Next page of the story's phase flow, skipping pages without content
+GetCoursePhaseFlow(ctx, courseID int) (*PhaseFlow, error) // Default flow when the course has none
+SetCoursePhaseFlow(ctx, courseID int, phases []string) (*PhaseFlow, error) // Empty restores the default; ErrInvalidPhaseFlow
+GetStoryPhaseFlow(ctx, storyID int) (*PhaseFlow, error) // Story override, else course flow, else default
+SetStoryPhaseFlow(ctx, storyID int, phases []string) (*PhaseFlow, error) // Empty removes the override
+ValidatePhaseFlow(phases []string) error // Known phases, no duplicates, ends with score
+
+LTI Types:
+- LTIPlatform: {ID, Name, Issuer, ClientID, DeploymentID, AuthLoginURL, AuthTokenURL, JWKSURL, CreatedAt}
+- LTIContext: {ID, PlatformID, ContextID, CourseID, Title, LineItemsURL} // An LMS course mapped to a course
+- LTIScoreTarget: {PlatformID, Subject, LTIContextID, CourseID, LineItemsURL}
+- ScorePublisher interface: PublishScore(ctx, score StoryScore) error // Implemented by src/auth/lti.Tool
+
+LTI Operations (launches and grade passback live in src/auth/lti):
+SaveLTIPlatform(ctx, platform LTIPlatform) (*LTIPlatform, error) // Upsert on issuer + client id
+GetLTIPlatform(ctx, id int32) (*LTIPlatform, error)
+FindLTIPlatform(ctx, issuer, clientID string) (*LTIPlatform, error) // Empty clientID only if the issuer has one registration; ErrAmbiguousLTIPlatform
+ListLTIPlatforms(ctx) ([]LTIPlatform, error)
+DeleteLTIPlatform(ctx, id int32) error // Keeps the courses and users it created
+LinkLTIUser(ctx, platformID int32, subject, email, name string) (*User, error) // Existing mapping, else new "lti_<platform>_<subject>" user; never matched by email
+LinkLTIAccount(ctx, ltiUser, userID string) error // Moves an LTI-created user's platform mappings to userID (both authenticated); ErrNotFound
+RecordLTIUserContext(ctx, platformID int32, subject string, ltiContextID int32) error // Contexts a platform user launched from
+LinkLTIContext(ctx, platformID int32, contextID, title, lineItemsURL string, instructor bool) (*LTIContext, error) // First instructor launch creates the course; ErrNotFound before that
+EnrollLTIUser(ctx, courseID int32, userID string, instructor bool) error // Instructors become course admins, others are enrolled
+GetLTILineItem(ctx, ltiContextID int32, storyID int) (string, error) // ErrNotFound if none recorded
+SaveLTILineItem(ctx, ltiContextID int32, storyID int, lineItemURL string) error
+GetLTIScoreTargets(ctx, userID string, storyID int) ([]LTIScoreTarget, error) // Contexts of the story's course the user launched from that have AGS
 
 Error Types:
 ErrNotFound, ErrInvalidStoryID, ErrInvalidLineNumber, ErrMissingStoryID,
 ErrInvalidWeekNumber, ErrMissingDayLetter, ErrTitleTooShort, ErrMissingAuthorID
 
+Service:
+- Service: {queries, rawConn, storage storage.Storage, cache, keys, clock Clock, grader Grader, publisher ScorePublisher}
+- NewService(conn, store, cache, clock) *Service // nil store/cache disable storage/caching, nil clock = SystemClock, grader starts as LocalGrader
+- (s *Service) SetGrader(g Grader)
+- (s *Service) SetScorePublisher(p ScorePublisher) // New score snapshots are published in the background; nil disables
+- Every operation listed above is a method on *Service; handlers get the service via NewHandler
+- legacy.go keeps package-level wrappers that call the default service (Default/SetDefault)
+- SetDB, SetStorageClient, SetStorage, SetCache configure the default service (used by older tests)
+
 Database: Uses SQLC-generated queries with PostgreSQL
-- Global variable: queries *db.Queries (initialized via SetDB)
+- Service field: queries *db.Queries (built from the connection passed to NewService or SetDB)
 - All functions accept context.Context parameter for proper request lifecycle management
 - Type conversions: int ↔ int32, pgtype.* for nullable fields
 - Transaction wrapper: withTransaction(func(*sql.Tx) error) error (works with SQLC)
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Learning phases, named after their page routes
+const (
+	PhaseVideo     = "video"
+	PhaseVocab     = "vocab"
+	PhaseIdentify  = "identify"
+	PhaseTranslate = "translate"
+	PhaseGrammar   = "grammar"
+	PhaseRecall    = "recall"
+	PhaseProduce   = "produce"
+	PhaseScore     = "score"
+)
+
+// Phase flow sources, from most to least specific
+const (
+	PhaseFlowSourceStory   = "story"
+	PhaseFlowSourceCourse  = "course"
+	PhaseFlowSourceDefault = "default"
+)
+
+// KnownPhases lists every phase a flow may contain
+var KnownPhases = []string{
+	PhaseVideo, PhaseVocab, PhaseIdentify, PhaseTranslate,
+	PhaseGrammar, PhaseRecall, PhaseProduce, PhaseScore,
+}
+
+// DefaultPhaseFlow is used by stories whose course has no flow of its own
+var DefaultPhaseFlow = []string{PhaseVideo, PhaseVocab, PhaseTranslate, PhaseGrammar, PhaseScore}
+
+var ErrInvalidPhaseFlow = errors.New("phase flow must list known phases at most once and end with score")
+
+// PhaseFlow is the order of phases a student goes through and where that order is set
+type PhaseFlow struct {
+	Phases []string `json:"phases"`
+	Source string   `json:"source"` // PhaseFlowSourceStory, PhaseFlowSourceCourse or PhaseFlowSourceDefault
+}
+
+// GetCoursePhaseFlow returns the phase flow of a course, or the default flow if it has none
+func (s *Service) GetCoursePhaseFlow(ctx context.Context, courseID int) (*PhaseFlow, error) {
+	result, err := s.queries.GetCoursePhaseFlow(ctx, int32(courseID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return defaultPhaseFlow(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PhaseFlow{Phases: result.Phases, Source: PhaseFlowSourceCourse}, nil
+}
+
+// SetCoursePhaseFlow sets the phase flow of a course. An empty flow restores the default.
+func (s *Service) SetCoursePhaseFlow(ctx context.Context, courseID int, phases []string) (*PhaseFlow, error) {
+	if len(phases) == 0 {
+		if err := s.queries.DeleteCoursePhaseFlow(ctx, int32(courseID)); err != nil {
+			return nil, err
+		}
+		return defaultPhaseFlow(), nil
+	}
+	if err := ValidatePhaseFlow(phases); err != nil {
+		return nil, err
+	}
+
+	result, err := s.queries.UpsertCoursePhaseFlow(ctx, db.UpsertCoursePhaseFlowParams{
+		CourseID: int32(courseID),
+		Phases:   phases,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PhaseFlow{Phases: result.Phases, Source: PhaseFlowSourceCourse}, nil
+}
+
+// GetStoryPhaseFlow returns the phase flow that applies to a story: its own flow,
+// else its course's flow, else the default flow
+func (s *Service) GetStoryPhaseFlow(ctx context.Context, storyID int) (*PhaseFlow, error) {
+	result, err := s.queries.GetStoryPhaseFlow(ctx, int32(storyID))
+	if err == nil {
+		return &PhaseFlow{Phases: result.Phases, Source: PhaseFlowSourceStory}, nil
+	}
+	if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	story, err := s.queries.GetStory(ctx, int32(storyID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !story.CourseID.Valid {
+		return defaultPhaseFlow(), nil
+	}
+	return s.GetCoursePhaseFlow(ctx, int(story.CourseID.Int32))
+}
+
+// SetStoryPhaseFlow overrides the phase flow of a story. An empty flow removes the
+// override so the story follows its course again.
+func (s *Service) SetStoryPhaseFlow(ctx context.Context, storyID int, phases []string) (*PhaseFlow, error) {
+	if len(phases) == 0 {
+		if err := s.queries.DeleteStoryPhaseFlow(ctx, int32(storyID)); err != nil {
+			return nil, err
+		}
+		return s.GetStoryPhaseFlow(ctx, storyID)
+	}
+	if err := ValidatePhaseFlow(phases); err != nil {
+		return nil, err
+	}
+
+	result, err := s.queries.UpsertStoryPhaseFlow(ctx, db.UpsertStoryPhaseFlowParams{
+		StoryID: int32(storyID),
+		Phases:  phases,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PhaseFlow{Phases: result.Phases, Source: PhaseFlowSourceStory}, nil
+}
+
+// ValidatePhaseFlow checks phases are known, appear once each and end with the score page
+func ValidatePhaseFlow(phases []string) error {
+	if len(phases) == 0 || phases[len(phases)-1] != PhaseScore {
+		return ErrInvalidPhaseFlow
+	}
+	seen := make(map[string]bool, len(phases))
+	for _, phase := range phases {
+		if seen[phase] || !slices.Contains(KnownPhases, phase) {
+			return ErrInvalidPhaseFlow
+		}
+		seen[phase] = true
+	}
+	return nil
+}
+
+func defaultPhaseFlow() *PhaseFlow {
+	return &PhaseFlow{Phases: slices.Clone(DefaultPhaseFlow), Source: PhaseFlowSourceDefault}
+}
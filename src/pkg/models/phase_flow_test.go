@@ -0,0 +1,31 @@
+package models
+
+import "testing"
+
+func TestValidatePhaseFlow(t *testing.T) {
+	tests := []struct {
+		name   string
+		phases []string
+		valid  bool
+	}{
+		{"default", DefaultPhaseFlow, true},
+		{"new phases", []string{"video", "vocab", "identify", "recall", "produce", "score"}, true},
+		{"score only", []string{"score"}, true},
+		{"empty", nil, false},
+		{"unknown phase", []string{"video", "quiz", "score"}, false},
+		{"duplicate phase", []string{"vocab", "vocab", "score"}, false},
+		{"score not last", []string{"score", "vocab"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePhaseFlow(tt.phases)
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid flow, got %v", err)
+			}
+			if !tt.valid && err != ErrInvalidPhaseFlow {
+				t.Errorf("Expected ErrInvalidPhaseFlow, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxProduceSegments is how many segments the Produce phase of a story may have
+const MaxProduceSegments = 2
+
+// Grading status of a Produce submission
+const (
+	GradingPending = "pending"
+	GradingGraded  = "graded"
+)
+
+// MaxGradingAttempts is how often a submission is graded before the re-grader gives up on it
+const MaxGradingAttempts = 10
+
+// produceGradeTimeout bounds a single grading call, which the student may be waiting for
+const produceGradeTimeout = 10 * time.Second
+
+var (
+	ErrProduceSegmentLimit        = fmt.Errorf("a story can have at most %d produce segments", MaxProduceSegments)
+	ErrEmptyProduceSegment        = errors.New("english prompt and reference text are required")
+	ErrInvalidProduceLines        = errors.New("produce segment lines must be within the story")
+	ErrInvalidProduceGrammarPoint = errors.New("produce segment must link to a grammar point of this story")
+	ErrEmptyProduceSubmission     = errors.New("student text is required")
+	ErrProduceSegmentWrongStory   = errors.New("produce segment does not belong to this story")
+)
+
+// GetStoryProduceSegments returns the produce segments of a story in story order
+func (s *Service) GetStoryProduceSegments(ctx context.Context, storyID int) ([]ProduceSegment, error) {
+	results, err := s.queries.GetStoryProduceSegments(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]ProduceSegment, 0, len(results))
+	for _, result := range results {
+		segments = append(segments, produceSegmentFromDB(result))
+	}
+	return segments, nil
+}
+
+// GetProduceSegment returns a single segment, or ErrNotFound if it does not belong to the story
+func (s *Service) GetProduceSegment(ctx context.Context, storyID, segmentID int) (*ProduceSegment, error) {
+	result, err := s.queries.GetProduceSegment(ctx, int32(segmentID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int(result.StoryID) != storyID {
+		return nil, ErrNotFound
+	}
+
+	segment := produceSegmentFromDB(result)
+	return &segment, nil
+}
+
+// CreateProduceSegment adds a produce segment to a story. ID and StoryID of segment are ignored.
+func (s *Service) CreateProduceSegment(ctx context.Context, storyID int, segment ProduceSegment) (*ProduceSegment, error) {
+	segment = trimProduceSegment(segment)
+	if err := s.validateProduceSegment(ctx, storyID, segment); err != nil {
+		return nil, err
+	}
+
+	var result db.ProduceSegment
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		// Locked like CreateRecallSentence, so concurrent adds cannot pass the limit
+		if err := s.queries.LockStoryForRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+		count, err := s.queries.CountStoryProduceSegments(txCtx, int32(storyID))
+		if err != nil {
+			return err
+		}
+		if count >= MaxProduceSegments {
+			return ErrProduceSegmentLimit
+		}
+
+		result, err = s.queries.CreateProduceSegment(txCtx, db.CreateProduceSegmentParams{
+			StoryID:        int32(storyID),
+			StartLine:      int32(segment.StartLine),
+			EndLine:        int32(segment.EndLine),
+			EnglishPrompt:  segment.EnglishPrompt,
+			ReferenceText:  segment.ReferenceText,
+			GrammarPointID: pgtype.Int4{Int32: int32(segment.GrammarPointID), Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := produceSegmentFromDB(result)
+	return &created, nil
+}
+
+// UpdateProduceSegment replaces the fields of a produce segment
+func (s *Service) UpdateProduceSegment(ctx context.Context, storyID, segmentID int, segment ProduceSegment) (*ProduceSegment, error) {
+	segment = trimProduceSegment(segment)
+	if err := s.validateProduceSegment(ctx, storyID, segment); err != nil {
+		return nil, err
+	}
+
+	result, err := s.queries.UpdateProduceSegment(ctx, db.UpdateProduceSegmentParams{
+		ID:             int32(segmentID),
+		StoryID:        int32(storyID),
+		StartLine:      int32(segment.StartLine),
+		EndLine:        int32(segment.EndLine),
+		EnglishPrompt:  segment.EnglishPrompt,
+		ReferenceText:  segment.ReferenceText,
+		GrammarPointID: pgtype.Int4{Int32: int32(segment.GrammarPointID), Valid: true},
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated := produceSegmentFromDB(result)
+	return &updated, nil
+}
+
+// DeleteProduceSegment removes a segment along with its submissions
+func (s *Service) DeleteProduceSegment(ctx context.Context, storyID, segmentID int) error {
+	if _, err := s.GetProduceSegment(ctx, storyID, segmentID); err != nil {
+		return err
+	}
+	return s.queries.DeleteProduceSegment(ctx, db.DeleteProduceSegmentParams{
+		ID:      int32(segmentID),
+		StoryID: int32(storyID),
+	})
+}
+
+// GetProduceExplanation returns the contrastive explanation of a story, or "" if it has none
+func (s *Service) GetProduceExplanation(ctx context.Context, storyID int) (string, error) {
+	result, err := s.queries.GetProduceExplanation(ctx, int32(storyID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return result.Explanation, nil
+}
+
+// SetProduceExplanation sets the contrastive explanation of a story. Empty text removes it.
+func (s *Service) SetProduceExplanation(ctx context.Context, storyID int, explanation string) (string, error) {
+	explanation = strings.TrimSpace(explanation)
+	if explanation == "" {
+		return "", s.queries.DeleteProduceExplanation(ctx, int32(storyID))
+	}
+
+	result, err := s.queries.UpsertProduceExplanation(ctx, db.UpsertProduceExplanationParams{
+		StoryID:     int32(storyID),
+		Explanation: explanation,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.Explanation, nil
+}
+
+// SaveProduceSubmission stores a student's answer to a segment. Resubmitting the
+// same segment replaces the previous answer instead of adding a row.
+func (s *Service) SaveProduceSubmission(ctx context.Context, userID string, storyID, segmentID int, studentText string) (*ProduceSubmission, error) {
+	studentText = strings.TrimSpace(studentText)
+	if studentText == "" {
+		return nil, ErrEmptyProduceSubmission
+	}
+	segment, err := s.GetProduceSegment(ctx, storyID, segmentID)
+	if err == ErrNotFound {
+		return nil, ErrProduceSegmentWrongStory
+	} else if err != nil {
+		return nil, err
+	}
+
+	result, err := s.queries.UpsertProduceSubmission(ctx, db.UpsertProduceSubmissionParams{
+		UserID:      userID,
+		StoryID:     int32(storyID),
+		SegmentID:   int32(segmentID),
+		StudentText: studentText,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	submission := produceSubmissionFromDB(result)
+	s.InvalidateStoryCourseAnalytics(ctx, storyID)
+	// Unchanged resubmissions keep their grade
+	if submission.GradingStatus == GradingPending {
+		s.gradeProduceSubmission(ctx, &submission, segment.ReferenceText, segment.GrammarPointID)
+	}
+	return &submission, nil
+}
+
+// RegradePendingProduceSubmissions grades up to limit submissions whose earlier grading
+// failed, oldest first, and returns how many were graded
+func (s *Service) RegradePendingProduceSubmissions(ctx context.Context, limit int) (int, error) {
+	pending, err := s.queries.GetPendingProduceSubmissions(ctx, db.GetPendingProduceSubmissionsParams{
+		GradingAttempts: MaxGradingAttempts,
+		Limit:           int32(limit),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	graded := 0
+	for _, row := range pending {
+		submission := ProduceSubmission{
+			ID:            int(row.ID),
+			StudentText:   row.StudentText,
+			GradingStatus: GradingPending,
+		}
+		s.gradeProduceSubmission(ctx, &submission, row.ReferenceText, int(row.GrammarPointID.Int32))
+		if submission.GradingStatus == GradingGraded {
+			graded++
+		}
+	}
+	return graded, nil
+}
+
+// StartProduceRegrader re-grades pending submissions every interval until ctx is cancelled
+func (s *Service) StartProduceRegrader(ctx context.Context, interval time.Duration, batchSize int) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				graded, err := s.RegradePendingProduceSubmissions(ctx, batchSize)
+				if err != nil {
+					fmt.Printf("Failed to re-grade produce submissions: %v\n", err)
+				} else if graded > 0 {
+					fmt.Printf("Re-graded %d produce submissions\n", graded)
+				}
+			}
+		}
+	}()
+}
+
+// gradeProduceSubmission grades submission and stores the result. Grading fails open:
+// on error the score stays empty and the submission is left pending for the re-grader.
+// Only the grader call is bounded by produceGradeTimeout; the result is stored with ctx,
+// so a grader that times out still leaves the submission marked pending. Results are only
+// stored while the submission still holds the graded answer.
+func (s *Service) gradeProduceSubmission(ctx context.Context, submission *ProduceSubmission, referenceText string, grammarPointID int) {
+	gradeCtx, cancel := context.WithTimeout(ctx, produceGradeTimeout)
+	score, feedback, err := s.grade(gradeCtx, referenceText, submission.StudentText, grammarPointID)
+	cancel()
+	if err != nil {
+		fmt.Printf("Failed to grade produce submission %d: %v\n", submission.ID, err)
+		if err := s.queries.MarkProduceSubmissionPending(ctx, db.MarkProduceSubmissionPendingParams{
+			ID:          int32(submission.ID),
+			StudentText: submission.StudentText,
+		}); err != nil {
+			fmt.Printf("Failed to mark produce submission %d pending: %v\n", submission.ID, err)
+		}
+		return
+	}
+
+	updated, err := s.queries.SetProduceSubmissionGrade(ctx, db.SetProduceSubmissionGradeParams{
+		ID:          int32(submission.ID),
+		AiScore:     pgtype.Int4{Int32: int32(score), Valid: true},
+		AiFeedback:  pgtype.Text{String: feedback, Valid: true},
+		StudentText: submission.StudentText,
+	})
+	if err != nil {
+		fmt.Printf("Failed to save grade of produce submission %d: %v\n", submission.ID, err)
+		return
+	}
+	// The student answered again while this answer was graded; the new answer stays pending
+	if updated == 0 {
+		return
+	}
+	submission.AIScore = &score
+	submission.AIFeedback = feedback
+	submission.GradingStatus = GradingGraded
+}
+
+func (s *Service) grade(ctx context.Context, referenceText, studentText string, grammarPointID int) (int, string, error) {
+	if s.grader == nil {
+		return 0, "", errors.New("no grader configured")
+	}
+	var grammarPoint *GrammarPoint
+	if grammarPointID != 0 {
+		gp, err := s.GetGrammarPoint(ctx, grammarPointID)
+		if err != nil && err != ErrNotFound {
+			return 0, "", err
+		}
+		grammarPoint = gp
+	}
+	return s.grader.Grade(ctx, referenceText, studentText, grammarPoint)
+}
+
+// GetUserProduceSubmissions returns a user's answers for a story, keyed by segment ID
+func (s *Service) GetUserProduceSubmissions(ctx context.Context, userID string, storyID int) (map[int]ProduceSubmission, error) {
+	results, err := s.queries.GetUserProduceSubmissions(ctx, db.GetUserProduceSubmissionsParams{
+		UserID:  userID,
+		StoryID: int32(storyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := make(map[int]ProduceSubmission, len(results))
+	for _, result := range results {
+		submissions[int(result.SegmentID)] = produceSubmissionFromDB(result)
+	}
+	return submissions, nil
+}
+
+// validateProduceSegment checks a segment has its text, lies within the story and
+// links to one of the story's grammar points
+func (s *Service) validateProduceSegment(ctx context.Context, storyID int, segment ProduceSegment) error {
+	if segment.EnglishPrompt == "" || segment.ReferenceText == "" {
+		return ErrEmptyProduceSegment
+	}
+
+	lines, err := s.queries.GetStoryLines(ctx, int32(storyID))
+	if err != nil {
+		return err
+	}
+	if segment.StartLine < 1 || segment.EndLine < segment.StartLine || segment.EndLine > len(lines) {
+		return ErrInvalidProduceLines
+	}
+
+	grammarPoint, err := s.GetGrammarPoint(ctx, segment.GrammarPointID)
+	if err == ErrNotFound || (err == nil && grammarPoint.StoryID != storyID) {
+		return ErrInvalidProduceGrammarPoint
+	}
+	return err
+}
+
+func trimProduceSegment(segment ProduceSegment) ProduceSegment {
+	segment.EnglishPrompt = strings.TrimSpace(segment.EnglishPrompt)
+	segment.ReferenceText = strings.TrimSpace(segment.ReferenceText)
+	return segment
+}
+
+func produceSegmentFromDB(result db.ProduceSegment) ProduceSegment {
+	return ProduceSegment{
+		ID:             int(result.ID),
+		StoryID:        int(result.StoryID),
+		StartLine:      int(result.StartLine),
+		EndLine:        int(result.EndLine),
+		EnglishPrompt:  result.EnglishPrompt,
+		ReferenceText:  result.ReferenceText,
+		GrammarPointID: int(result.GrammarPointID.Int32),
+	}
+}
+
+func produceSubmissionFromDB(result db.ProduceSubmission) ProduceSubmission {
+	submission := ProduceSubmission{
+		ID:          int(result.ID),
+		UserID:      result.UserID,
+		StoryID:     int(result.StoryID),
+		SegmentID:   int(result.SegmentID),
+		StudentText: result.StudentText,
+		SubmittedAt: result.SubmittedAt.Time,
+		UpdatedAt:   result.UpdatedAt.Time,
+	}
+	if result.AiScore.Valid {
+		score := int(result.AiScore.Int32)
+		submission.AIScore = &score
+	}
+	submission.AIFeedback = result.AiFeedback.String
+	submission.GradingStatus = result.GradingStatus
+	return submission
+}
@@ -0,0 +1,277 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxRecallSentences is how many sentences the Recall phase of a story may have
+const MaxRecallSentences = 5
+
+var (
+	ErrRecallSentenceLimit = fmt.Errorf("a story can have at most %d recall sentences", MaxRecallSentences)
+	ErrEmptyRecallSentence = errors.New("recall sentence text is required")
+	ErrInvalidRecallTarget = errors.New("recall sentence must link to a target word of this story")
+	ErrInvalidRecallImage  = errors.New("recall sentence must link to an image of this story")
+	ErrInvalidRecallOrder  = errors.New("order must list every recall sentence of the story exactly once")
+)
+
+// GetStoryRecallSentences returns the recall sentences of a story in their correct order
+func (s *Service) GetStoryRecallSentences(ctx context.Context, storyID int) ([]RecallSentence, error) {
+	results, err := s.queries.GetStoryRecallSentences(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	sentences := make([]RecallSentence, 0, len(results))
+	for _, result := range results {
+		sentences = append(sentences, recallSentenceFromDB(result))
+	}
+	return sentences, nil
+}
+
+// GetRecallSentence returns a single recall sentence, or ErrNotFound if it does not belong to the story
+func (s *Service) GetRecallSentence(ctx context.Context, storyID, sentenceID int) (*RecallSentence, error) {
+	result, err := s.queries.GetRecallSentence(ctx, int32(sentenceID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int(result.StoryID) != storyID {
+		return nil, ErrNotFound
+	}
+
+	sentence := recallSentenceFromDB(result)
+	return &sentence, nil
+}
+
+// CreateRecallSentence appends a sentence to the end of the story's recall sequence
+func (s *Service) CreateRecallSentence(ctx context.Context, storyID int, text string, targetVocabID, imageID int) (*RecallSentence, error) {
+	text = strings.TrimSpace(text)
+	if err := s.validateRecallSentence(ctx, storyID, text, targetVocabID, imageID); err != nil {
+		return nil, err
+	}
+
+	var result db.RecallSentence
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		// The story row is locked so concurrent adds cannot pass the limit or share a position
+		if err := s.queries.LockStoryForRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+		count, err := s.queries.CountStoryRecallSentences(txCtx, int32(storyID))
+		if err != nil {
+			return err
+		}
+		if count >= MaxRecallSentences {
+			return ErrRecallSentenceLimit
+		}
+
+		result, err = s.queries.CreateRecallSentence(txCtx, db.CreateRecallSentenceParams{
+			StoryID:       int32(storyID),
+			SequenceOrder: int32(count + 1),
+			SentenceText:  text,
+			TargetVocabID: pgtype.Int4{Int32: int32(targetVocabID), Valid: true},
+			ImageID:       pgtype.Int4{Int32: int32(imageID), Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sentence := recallSentenceFromDB(result)
+	return &sentence, nil
+}
+
+// UpdateRecallSentence changes a sentence's text and links, keeping its position
+func (s *Service) UpdateRecallSentence(ctx context.Context, storyID, sentenceID int, text string, targetVocabID, imageID int) (*RecallSentence, error) {
+	text = strings.TrimSpace(text)
+	if err := s.validateRecallSentence(ctx, storyID, text, targetVocabID, imageID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.queries.UpdateRecallSentence(ctx, db.UpdateRecallSentenceParams{
+		ID:            int32(sentenceID),
+		StoryID:       int32(storyID),
+		SentenceText:  text,
+		TargetVocabID: pgtype.Int4{Int32: int32(targetVocabID), Valid: true},
+		ImageID:       pgtype.Int4{Int32: int32(imageID), Valid: true},
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sentence := recallSentenceFromDB(result)
+	return &sentence, nil
+}
+
+// ReorderRecallSentences sets the correct order of a story's recall sentences.
+// sentenceIDs must list every sentence of the story exactly once.
+func (s *Service) ReorderRecallSentences(ctx context.Context, storyID int, sentenceIDs []int) ([]RecallSentence, error) {
+	sentences, err := s.GetStoryRecallSentences(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if !isRecallPermutation(sentences, sentenceIDs) {
+		return nil, ErrInvalidRecallOrder
+	}
+
+	err = s.withTransaction(ctx, func(txCtx context.Context) error {
+		return s.setRecallOrder(txCtx, storyID, sentenceIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetStoryRecallSentences(ctx, storyID)
+}
+
+// DeleteRecallSentence removes a sentence and closes the gap it leaves in the sequence
+func (s *Service) DeleteRecallSentence(ctx context.Context, storyID, sentenceID int) error {
+	sentences, err := s.GetStoryRecallSentences(ctx, storyID)
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]int, 0, len(sentences))
+	found := false
+	for _, sentence := range sentences {
+		if sentence.ID == sentenceID {
+			found = true
+			continue
+		}
+		remaining = append(remaining, sentence.ID)
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	return s.withTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.queries.DeleteRecallSentence(txCtx, db.DeleteRecallSentenceParams{
+			ID:      int32(sentenceID),
+			StoryID: int32(storyID),
+		}); err != nil {
+			return err
+		}
+		return s.setRecallOrder(txCtx, storyID, remaining)
+	})
+}
+
+// CheckRecallOrder compares a submitted ordering against the correct one and
+// reports, per submitted position, whether the sentence there belongs there.
+// sentences must be in their correct order, as returned by GetStoryRecallSentences.
+func CheckRecallOrder(sentences []RecallSentence, submittedIDs []int) ([]bool, error) {
+	if !isRecallPermutation(sentences, submittedIDs) {
+		return nil, ErrInvalidRecallOrder
+	}
+
+	results := make([]bool, len(submittedIDs))
+	for i, id := range submittedIDs {
+		results[i] = sentences[i].ID == id
+	}
+	return results, nil
+}
+
+// SaveRecallAttempt logs a checked Recall ordering. A sentence placed correctly is
+// recorded once per user; every misplacement is recorded with the position it was put in.
+func (s *Service) SaveRecallAttempt(ctx context.Context, userID string, storyID int, submittedIDs []int, results []bool) error {
+	return s.answersSaved(ctx, storyID, s.withTransaction(ctx, func(txCtx context.Context) error {
+		for i, id := range submittedIDs {
+			if results[i] {
+				if err := s.queries.SaveRecallScore(txCtx, db.SaveRecallScoreParams{
+					UserID:           userID,
+					StoryID:          int32(storyID),
+					RecallSentenceID: int32(id),
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := s.queries.SaveRecallIncorrectAnswer(txCtx, db.SaveRecallIncorrectAnswerParams{
+				UserID:            userID,
+				StoryID:           int32(storyID),
+				RecallSentenceID:  int32(id),
+				SubmittedPosition: int32(i + 1),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// validateRecallSentence checks a sentence has text and links to a target word and image of the story
+func (s *Service) validateRecallSentence(ctx context.Context, storyID int, text string, targetVocabID, imageID int) error {
+	if text == "" {
+		return ErrEmptyRecallSentence
+	}
+
+	if _, err := s.GetTargetVocab(ctx, storyID, targetVocabID); err == ErrNotFound {
+		return ErrInvalidRecallTarget
+	} else if err != nil {
+		return err
+	}
+
+	image, err := s.GetStoryImage(ctx, imageID)
+	if err == ErrNotFound || (err == nil && image.StoryID != storyID) {
+		return ErrInvalidRecallImage
+	}
+	return err
+}
+
+// setRecallOrder numbers sentences 1..n in the given order. Run it inside a
+// transaction: the (story_id, sequence_order) constraint is only checked on commit.
+func (s *Service) setRecallOrder(ctx context.Context, storyID int, sentenceIDs []int) error {
+	for i, id := range sentenceIDs {
+		if err := s.queries.SetRecallSentenceOrder(ctx, db.SetRecallSentenceOrderParams{
+			ID:            int32(id),
+			StoryID:       int32(storyID),
+			SequenceOrder: int32(i + 1),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isRecallPermutation reports whether ids lists every sentence exactly once
+func isRecallPermutation(sentences []RecallSentence, ids []int) bool {
+	if len(ids) != len(sentences) {
+		return false
+	}
+	remaining := make(map[int]bool, len(sentences))
+	for _, sentence := range sentences {
+		remaining[sentence.ID] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
+
+func recallSentenceFromDB(result db.RecallSentence) RecallSentence {
+	return RecallSentence{
+		ID:            int(result.ID),
+		StoryID:       int(result.StoryID),
+		SequenceOrder: int(result.SequenceOrder),
+		Text:          result.SentenceText,
+		TargetVocabID: int(result.TargetVocabID.Int32),
+		ImageID:       int(result.ImageID.Int32),
+	}
+}
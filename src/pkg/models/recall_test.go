@@ -0,0 +1,35 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckRecallOrder(t *testing.T) {
+	sentences := []RecallSentence{{ID: 11}, {ID: 12}, {ID: 13}}
+
+	tests := []struct {
+		name      string
+		submitted []int
+		want      []bool
+		wantErr   error
+	}{
+		{"correct", []int{11, 12, 13}, []bool{true, true, true}, nil},
+		{"swapped", []int{12, 11, 13}, []bool{false, false, true}, nil},
+		{"missing sentence", []int{11, 12}, nil, ErrInvalidRecallOrder},
+		{"duplicate sentence", []int{11, 11, 13}, nil, ErrInvalidRecallOrder},
+		{"unknown sentence", []int{11, 12, 99}, nil, ErrInvalidRecallOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckRecallOrder(sentences, tt.submitted)
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
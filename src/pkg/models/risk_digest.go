@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RiskNotifier delivers the daily at-risk digest of a course to its instructors
+type RiskNotifier interface {
+	SendRiskDigest(ctx context.Context, digest RiskDigest) error
+}
+
+// RiskDigest is what a course's admins are told about its at-risk students
+type RiskDigest struct {
+	Course     Course
+	Recipients []CourseAdmin
+	AtRisk     CourseAtRisk
+}
+
+// LogRiskNotifier prints digests instead of delivering them, for local runs
+type LogRiskNotifier struct{}
+
+func (LogRiskNotifier) SendRiskDigest(_ context.Context, digest RiskDigest) error {
+	fmt.Print(formatRiskDigest(digest))
+	return nil
+}
+
+// formatRiskDigest renders a digest as plain text
+func formatRiskDigest(digest RiskDigest) string {
+	recipients := make([]string, len(digest.Recipients))
+	for i, admin := range digest.Recipients {
+		recipients[i] = admin.Email
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "At-risk digest for %s %s (week %d), to %s\n", digest.Course.CourseNumber, digest.Course.Name,
+		digest.AtRisk.CurrentWeek, strings.Join(recipients, ", "))
+	for _, student := range digest.AtRisk.Students {
+		fmt.Fprintf(&b, "- %s <%s>\n", student.UserName, student.Email)
+		for _, signal := range student.Signals {
+			fmt.Fprintf(&b, "    %s\n", signal)
+		}
+	}
+	return b.String()
+}
+
+// SetRiskNotifier sets where SendRiskDigests delivers digests. A nil n stops digests.
+func (s *Service) SetRiskNotifier(n RiskNotifier) {
+	s.notifier = n
+}
+
+// SendRiskDigests sends the at-risk digest of every course that enabled it and has
+// students at risk. A failing course does not stop the others; it returns how many
+// digests were sent and the errors joined.
+func (s *Service) SendRiskDigests(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+	courseIDs, err := s.queries.GetRiskDigestCourseIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for _, courseID := range courseIDs {
+		digest, err := s.riskDigest(ctx, courseID)
+		if err == nil && digest != nil {
+			err = s.notifier.SendRiskDigest(ctx, *digest)
+			if err == nil {
+				sent++
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("course %d: %w", courseID, err))
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// riskDigest builds the digest of a course, or returns nil when nobody is at risk
+func (s *Service) riskDigest(ctx context.Context, courseID int32) (*RiskDigest, error) {
+	atRisk, err := s.GetCourseAtRisk(ctx, courseID)
+	if err != nil || len(atRisk.Students) == 0 {
+		return nil, err
+	}
+	course, err := s.GetCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	admins, err := s.GetCourseAdmins(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return &RiskDigest{Course: *course, Recipients: admins, AtRisk: *atRisk}, nil
+}
+
+// StartRiskDigest sends the at-risk digests every day at the given hour (0-23, in the
+// clock's time zone) until ctx is cancelled
+func (s *Service) StartRiskDigest(ctx context.Context, hour int) {
+	go func() {
+		for {
+			timer := time.NewTimer(nextRiskDigest(s.clock.Now(), hour).Sub(s.clock.Now()))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				sent, err := s.SendRiskDigests(ctx)
+				if err != nil {
+					fmt.Printf("Failed to send at-risk digests: %v\n", err)
+				}
+				if sent > 0 {
+					fmt.Printf("Sent %d at-risk digests\n", sent)
+				}
+			}
+		}
+	}()
+}
+
+// nextRiskDigest returns the first time after now at the given hour
+func nextRiskDigest(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
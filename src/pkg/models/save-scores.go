@@ -9,10 +9,10 @@ import (
 )
 
 // SaveVocabScore saves a vocabulary score for a user
-func SaveVocabScore(ctx context.Context, userID string, storyID, lineNumber, position int, correct bool, incorrectAnswer string) error {
+func (s *Service) SaveVocabScore(ctx context.Context, userID string, storyID, lineNumber, position int, correct bool, incorrectAnswer string) error {
 	lineNumber = lineNumber + 1 // Convert 0-indexed to 1-indexed
 	// Get all vocabulary items for this line to save individual scores
-	vocabItems, err := queries.GetVocabularyItems(ctx, db.GetVocabularyItemsParams{
+	vocabItems, err := s.queries.GetVocabularyItems(ctx, db.GetVocabularyItemsParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -28,7 +28,7 @@ func SaveVocabScore(ctx context.Context, userID string, storyID, lineNumber, pos
 	item := vocabItems[position]
 	// Save score only for the specific vocab item at this position
 	if correct {
-		err := queries.SaveVocabScore(ctx, db.SaveVocabScoreParams{
+		err := s.queries.SaveVocabScore(ctx, db.SaveVocabScoreParams{
 			UserID:      userID,
 			StoryID:     int32(storyID),
 			LineNumber:  int32(lineNumber),
@@ -38,7 +38,7 @@ func SaveVocabScore(ctx context.Context, userID string, storyID, lineNumber, pos
 			return err
 		}
 	} else if incorrectAnswer != "" {
-		err := queries.SaveVocabIncorrectAnswer(ctx, db.SaveVocabIncorrectAnswerParams{
+		err := s.queries.SaveVocabIncorrectAnswer(ctx, db.SaveVocabIncorrectAnswerParams{
 			UserID:          userID,
 			StoryID:         int32(storyID),
 			LineNumber:      int32(lineNumber),
@@ -50,13 +50,14 @@ func SaveVocabScore(ctx context.Context, userID string, storyID, lineNumber, pos
 		}
 	}
 
+	s.InvalidateStoryCourseAnalytics(ctx, storyID)
 	return nil
 }
 
 // SaveGrammarScore saves a grammar score for a user (single line)
-func SaveGrammarScore(ctx context.Context, userID string, storyID int, lineNumber int, correct bool, selectedLine int, selectedPositions []int) error {
+func (s *Service) SaveGrammarScore(ctx context.Context, userID string, storyID int, lineNumber int, correct bool, selectedLine int, selectedPositions []int) error {
 	// Get all grammar items for this line
-	grammarItems, err := queries.GetGrammarItems(ctx, db.GetGrammarItemsParams{
+	grammarItems, err := s.queries.GetGrammarItems(ctx, db.GetGrammarItemsParams{
 		StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 		LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 	})
@@ -67,7 +68,7 @@ func SaveGrammarScore(ctx context.Context, userID string, storyID int, lineNumbe
 	// Save score for each grammar item on this line
 	for _, item := range grammarItems {
 		if correct {
-			err := queries.SaveGrammarScore(ctx, db.SaveGrammarScoreParams{
+			err := s.queries.SaveGrammarScore(ctx, db.SaveGrammarScoreParams{
 				UserID:         userID,
 				StoryID:        int32(storyID),
 				LineNumber:     int32(lineNumber),
@@ -81,7 +82,7 @@ func SaveGrammarScore(ctx context.Context, userID string, storyID int, lineNumbe
 			for i, pos := range selectedPositions {
 				positions[i] = int32(pos)
 			}
-			err := queries.SaveGrammarIncorrectAnswer(ctx, db.SaveGrammarIncorrectAnswerParams{
+			err := s.queries.SaveGrammarIncorrectAnswer(ctx, db.SaveGrammarIncorrectAnswerParams{
 				UserID:            userID,
 				StoryID:           int32(storyID),
 				LineNumber:        int32(lineNumber),
@@ -95,12 +96,13 @@ func SaveGrammarScore(ctx context.Context, userID string, storyID int, lineNumbe
 		}
 	}
 
+	s.InvalidateStoryCourseAnalytics(ctx, storyID)
 	return nil
 }
 
 // GetUserGrammarScoresByGrammarPoint retrieves user's correct grammar answers for a specific grammar point
-func GetUserGrammarScoresByGrammarPoint(ctx context.Context, userID string, storyID int, grammarPointID int) ([]db.GetUserGrammarScoresByGrammarPointRow, error) {
-	return queries.GetUserGrammarScoresByGrammarPoint(ctx, db.GetUserGrammarScoresByGrammarPointParams{
+func (s *Service) GetUserGrammarScoresByGrammarPoint(ctx context.Context, userID string, storyID int, grammarPointID int) ([]db.GetUserGrammarScoresByGrammarPointRow, error) {
+	return s.queries.GetUserGrammarScoresByGrammarPoint(ctx, db.GetUserGrammarScoresByGrammarPointParams{
 		UserID:         userID,
 		StoryID:        int32(storyID),
 		GrammarPointID: int32(grammarPointID),
@@ -108,8 +110,8 @@ func GetUserGrammarScoresByGrammarPoint(ctx context.Context, userID string, stor
 }
 
 // GetUserGrammarIncorrectAnswers retrieves user's incorrect grammar answers for a specific grammar point
-func GetUserGrammarIncorrectAnswers(ctx context.Context, userID string, storyID int, grammarPointID int) ([]db.GetUserGrammarIncorrectAnswersRow, error) {
-	return queries.GetUserGrammarIncorrectAnswers(ctx, db.GetUserGrammarIncorrectAnswersParams{
+func (s *Service) GetUserGrammarIncorrectAnswers(ctx context.Context, userID string, storyID int, grammarPointID int) ([]db.GetUserGrammarIncorrectAnswersRow, error) {
+	return s.queries.GetUserGrammarIncorrectAnswers(ctx, db.GetUserGrammarIncorrectAnswersParams{
 		UserID:         userID,
 		StoryID:        int32(storyID),
 		GrammarPointID: int32(grammarPointID),
@@ -117,15 +119,15 @@ func GetUserGrammarIncorrectAnswers(ctx context.Context, userID string, storyID
 }
 
 // SaveGrammarScoresForPoint saves grammar scores for multiple lines of the same grammar point
-func SaveGrammarScoresForPoint(ctx context.Context, userID string, storyID int, grammarPointID int, lineScores map[int]bool, incorrectAnswers map[int]struct {
+func (s *Service) SaveGrammarScoresForPoint(ctx context.Context, userID string, storyID int, grammarPointID int, lineScores map[int]bool, incorrectAnswers map[int]struct {
 	SelectedLine      int
 	SelectedPositions []int
 }) error {
 	// Use transaction to save all grammar scores atomically
-	return withTransaction(ctx, func(txCtx context.Context) error {
+	return s.answersSaved(ctx, storyID, s.withTransaction(ctx, func(txCtx context.Context) error {
 		for lineNumber, correct := range lineScores {
 			// Get grammar items for this line that match the grammar point
-			grammarItems, err := queries.GetGrammarItems(txCtx, db.GetGrammarItemsParams{
+			grammarItems, err := s.queries.GetGrammarItems(txCtx, db.GetGrammarItemsParams{
 				StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 				LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 			})
@@ -137,7 +139,7 @@ func SaveGrammarScoresForPoint(ctx context.Context, userID string, storyID int,
 			for _, item := range grammarItems {
 				if item.GrammarPointID.Valid && int(item.GrammarPointID.Int32) == grammarPointID {
 					if correct {
-						err := queries.SaveGrammarScore(txCtx, db.SaveGrammarScoreParams{
+						err := s.queries.SaveGrammarScore(txCtx, db.SaveGrammarScoreParams{
 							UserID:         userID,
 							StoryID:        int32(storyID),
 							LineNumber:     int32(lineNumber),
@@ -152,7 +154,7 @@ func SaveGrammarScoresForPoint(ctx context.Context, userID string, storyID int,
 							for i, pos := range incorrectAnswer.SelectedPositions {
 								positions[i] = int32(pos)
 							}
-							err := queries.SaveGrammarIncorrectAnswer(txCtx, db.SaveGrammarIncorrectAnswerParams{
+							err := s.queries.SaveGrammarIncorrectAnswer(txCtx, db.SaveGrammarIncorrectAnswerParams{
 								UserID:            userID,
 								StoryID:           int32(storyID),
 								LineNumber:        int32(lineNumber),
@@ -169,14 +171,14 @@ func SaveGrammarScoresForPoint(ctx context.Context, userID string, storyID int,
 			}
 		}
 		return nil
-	})
+	}))
 }
 
 // SaveCorrectGrammarItems saves correct grammar scores
-func SaveCorrectGrammarItems(ctx context.Context, userID string, storyID, grammarPointID int, grammarItemsMap map[int][]GrammarItem, correctItems map[int][]int) error {
-	return withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) SaveCorrectGrammarItems(ctx context.Context, userID string, storyID, grammarPointID int, grammarItemsMap map[int][]GrammarItem, correctItems map[int][]int) error {
+	return s.answersSaved(ctx, storyID, s.withTransaction(ctx, func(txCtx context.Context) error {
 		for lineNumber, itemIndices := range correctItems {
-			grammarItems, err := queries.GetGrammarItems(txCtx, db.GetGrammarItemsParams{
+			grammarItems, err := s.queries.GetGrammarItems(txCtx, db.GetGrammarItemsParams{
 				StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 				LineNumber: pgtype.Int4{Int32: int32(lineNumber), Valid: true},
 			})
@@ -190,7 +192,7 @@ func SaveCorrectGrammarItems(ctx context.Context, userID string, storyID, gramma
 				if dbItem.GrammarPointID.Valid && int(dbItem.GrammarPointID.Int32) == grammarPointID {
 					// Check if this item index is in the correct list
 					if slices.Contains(itemIndices, itemIndex) {
-						err := queries.SaveGrammarScore(txCtx, db.SaveGrammarScoreParams{
+						err := s.queries.SaveGrammarScore(txCtx, db.SaveGrammarScoreParams{
 							UserID:         userID,
 							StoryID:        int32(storyID),
 							LineNumber:     int32(lineNumber),
@@ -205,18 +207,18 @@ func SaveCorrectGrammarItems(ctx context.Context, userID string, storyID, gramma
 			}
 		}
 		return nil
-	})
+	}))
 }
 
 // SaveCorrectAnswers saves correct answer data
-func SaveCorrectAnswers(ctx context.Context, userID string, storyID, grammarPointID int, correctAnswers []struct {
+func (s *Service) SaveCorrectAnswers(ctx context.Context, userID string, storyID, grammarPointID int, correctAnswers []struct {
 	LineNumber int
 	Position   [2]int
 	Text       string
 }) error {
-	return withTransaction(ctx, func(txCtx context.Context) error {
+	return s.answersSaved(ctx, storyID, s.withTransaction(ctx, func(txCtx context.Context) error {
 		for _, correctAnswer := range correctAnswers {
-			err := queries.SaveGrammarScore(txCtx, db.SaveGrammarScoreParams{
+			err := s.queries.SaveGrammarScore(txCtx, db.SaveGrammarScoreParams{
 				UserID:         userID,
 				StoryID:        int32(storyID),
 				LineNumber:     int32(correctAnswer.LineNumber),
@@ -227,17 +229,17 @@ func SaveCorrectAnswers(ctx context.Context, userID string, storyID, grammarPoin
 			}
 		}
 		return nil
-	})
+	}))
 }
 
 // SaveIncorrectAnswers saves incorrect answer data for wrong user clicks
-func SaveIncorrectAnswers(ctx context.Context, userID string, storyID, grammarPointID int, incorrectAnswers []struct {
+func (s *Service) SaveIncorrectAnswers(ctx context.Context, userID string, storyID, grammarPointID int, incorrectAnswers []struct {
 	LineNumber int
 	Position   int
 }) error {
-	return withTransaction(ctx, func(txCtx context.Context) error {
+	return s.answersSaved(ctx, storyID, s.withTransaction(ctx, func(txCtx context.Context) error {
 		for _, incorrectAnswer := range incorrectAnswers {
-			err := queries.SaveGrammarIncorrectAnswer(txCtx, db.SaveGrammarIncorrectAnswerParams{
+			err := s.queries.SaveGrammarIncorrectAnswer(txCtx, db.SaveGrammarIncorrectAnswerParams{
 				UserID:            userID,
 				StoryID:           int32(storyID),
 				LineNumber:        int32(incorrectAnswer.LineNumber),
@@ -250,33 +252,33 @@ func SaveIncorrectAnswers(ctx context.Context, userID string, storyID, grammarPo
 			}
 		}
 		return nil
-	})
+	}))
 }
 
 // SaveSingleGrammarSelection saves a single grammar selection (correct or incorrect)
-func SaveSingleGrammarSelection(ctx context.Context, userID string, storyID int, grammarPointID int, lineNumber int, position int, correct bool) error {
+func (s *Service) SaveSingleGrammarSelection(ctx context.Context, userID string, storyID int, grammarPointID int, lineNumber int, position int, correct bool) error {
 	if correct {
-		return queries.SaveGrammarScore(ctx, db.SaveGrammarScoreParams{
+		return s.answersSaved(ctx, storyID, s.queries.SaveGrammarScore(ctx, db.SaveGrammarScoreParams{
 			UserID:         userID,
 			StoryID:        int32(storyID),
 			LineNumber:     int32(lineNumber),
 			GrammarPointID: int32(grammarPointID),
-		})
+		}))
 	}
 
-	return queries.SaveGrammarIncorrectAnswer(ctx, db.SaveGrammarIncorrectAnswerParams{
+	return s.answersSaved(ctx, storyID, s.queries.SaveGrammarIncorrectAnswer(ctx, db.SaveGrammarIncorrectAnswerParams{
 		UserID:            userID,
 		StoryID:           int32(storyID),
 		LineNumber:        int32(lineNumber),
 		GrammarPointID:    int32(grammarPointID),
 		SelectedLine:      int32(lineNumber),
 		SelectedPositions: []int32{int32(position)},
-	})
+	}))
 }
 
 // CountFoundGrammarInstances counts how many instances of a grammar point a user has already found correctly
-func CountFoundGrammarInstances(ctx context.Context, userID string, storyID int, grammarPointID int) (int, error) {
-	scores, err := queries.GetUserGrammarScores(ctx, db.GetUserGrammarScoresParams{
+func (s *Service) CountFoundGrammarInstances(ctx context.Context, userID string, storyID int, grammarPointID int) (int, error) {
+	scores, err := s.queries.GetUserGrammarScores(ctx, db.GetUserGrammarScoresParams{
 		UserID:  userID,
 		StoryID: int32(storyID),
 	})
@@ -10,15 +10,15 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func SaveNewStory(ctx context.Context, story *Story) error {
-	err := withTransaction(ctx, func(txCtx context.Context) error {
+func (s *Service) SaveNewStory(ctx context.Context, story *Story) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
 		// Create story using SQLC
 		courseID := pgtype.Int4{Valid: false}
 		if story.Metadata.CourseID != nil {
 			courseID = pgtype.Int4{Int32: int32(*story.Metadata.CourseID), Valid: true}
 		}
 
-		result, err := queries.CreateStory(txCtx, db.CreateStoryParams{
+		result, err := s.queries.CreateStory(txCtx, db.CreateStoryParams{
 			WeekNumber: int32(story.Metadata.WeekNumber),
 			DayLetter:  story.Metadata.DayLetter,
 			VideoUrl:   pgtype.Text{String: story.Metadata.VideoURL, Valid: story.Metadata.VideoURL != ""},
@@ -31,19 +31,22 @@ func SaveNewStory(ctx context.Context, story *Story) error {
 		}
 
 		story.Metadata.StoryID = int(result.StoryID)
-		return saveStoryComponents(txCtx, story)
+		if err := s.saveStoryComponents(txCtx, story); err != nil {
+			return err
+		}
+		return s.recordStoryRevision(txCtx, story.Metadata.StoryID, "create")
 	})
 
 	// Invalidate cache after successful save
 	if err == nil {
-		InvalidateStoryMetadata(story.Metadata.StoryID)
+		s.InvalidateStoryMetadata(story.Metadata.StoryID)
 	}
 
 	return err
 }
 
-func SaveStoryData(ctx context.Context, storyID int, story *Story) error {
-	exists, err := queries.StoryExists(ctx, int32(storyID))
+func (s *Service) SaveStoryData(ctx context.Context, storyID int, story *Story) error {
+	exists, err := s.queries.StoryExists(ctx, int32(storyID))
 	if err != nil {
 		return err
 	}
@@ -51,14 +54,21 @@ func SaveStoryData(ctx context.Context, storyID int, story *Story) error {
 		return ErrNotFound
 	}
 
-	err = withTransaction(ctx, func(txCtx context.Context) error {
+	var previousCourseID, courseID pgtype.Int4
+	err = s.withTransaction(ctx, func(txCtx context.Context) error {
+		previous, err := s.queries.GetStory(txCtx, int32(storyID))
+		if err != nil {
+			return err
+		}
+		previousCourseID = previous.CourseID
+
 		// Update story using SQLC
-		courseID := pgtype.Int4{Valid: false}
+		courseID = pgtype.Int4{Valid: false}
 		if story.Metadata.CourseID != nil {
 			courseID = pgtype.Int4{Int32: int32(*story.Metadata.CourseID), Valid: true}
 		}
 
-		err := queries.UpdateStory(txCtx, db.UpdateStoryParams{
+		err = s.queries.UpdateStory(txCtx, db.UpdateStoryParams{
 			StoryID:    int32(storyID),
 			WeekNumber: int32(story.Metadata.WeekNumber),
 			DayLetter:  story.Metadata.DayLetter,
@@ -71,21 +81,25 @@ func SaveStoryData(ctx context.Context, storyID int, story *Story) error {
 			return err
 		}
 
-		return saveStoryComponents(txCtx, story)
+		if err := s.saveStoryComponents(txCtx, story); err != nil {
+			return err
+		}
+		return s.recordStoryRevision(txCtx, storyID, "edit")
 	})
 
 	// Invalidate cache after successful save
 	if err == nil {
-		InvalidateStoryMetadata(storyID)
+		s.InvalidateStoryMetadata(storyID)
+		s.storyCourseChanged(previousCourseID, courseID)
 	}
 
 	return err
 }
 
-func saveStoryComponents(ctx context.Context, story *Story) error {
+func (s *Service) saveStoryComponents(ctx context.Context, story *Story) error {
 	// Save titles using SQLC
 	for lang, title := range story.Metadata.Title {
-		if err := queries.UpsertStoryTitle(ctx, db.UpsertStoryTitleParams{
+		if err := s.queries.UpsertStoryTitle(ctx, db.UpsertStoryTitleParams{
 			StoryID:      int32(story.Metadata.StoryID),
 			LanguageCode: lang,
 			Title:        title,
@@ -96,7 +110,7 @@ func saveStoryComponents(ctx context.Context, story *Story) error {
 
 	// Save description using SQLC
 	if story.Metadata.Description.Text != "" || story.Metadata.Language != "" {
-		if err := queries.UpsertStoryDescription(ctx, db.UpsertStoryDescriptionParams{
+		if err := s.queries.UpsertStoryDescription(ctx, db.UpsertStoryDescriptionParams{
 			StoryID:         int32(story.Metadata.StoryID),
 			LanguageCode:    story.Metadata.Language,
 			DescriptionText: story.Metadata.Description.Text,
@@ -105,21 +119,21 @@ func saveStoryComponents(ctx context.Context, story *Story) error {
 		}
 	}
 
-	return saveLines(ctx, story.Metadata.StoryID, story.Content.Lines)
+	return s.saveLines(ctx, story.Metadata.StoryID, story.Content.Lines)
 }
 
-func saveLines(ctx context.Context, storyID int, lines []StoryLine) error {
+func (s *Service) saveLines(ctx context.Context, storyID int, lines []StoryLine) error {
 	for _, line := range lines {
-		if err := saveLine(ctx, storyID, &line); err != nil {
+		if err := s.saveLine(ctx, storyID, &line); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func saveLine(ctx context.Context, storyID int, line *StoryLine) error {
+func (s *Service) saveLine(ctx context.Context, storyID int, line *StoryLine) error {
 	// Save line using SQLC
-	err := queries.UpsertStoryLine(ctx, db.UpsertStoryLineParams{
+	err := s.queries.UpsertStoryLine(ctx, db.UpsertStoryLineParams{
 		StoryID:    int32(storyID),
 		LineNumber: int32(line.LineNumber),
 		Text:       line.Text,
@@ -128,30 +142,39 @@ func saveLine(ctx context.Context, storyID int, line *StoryLine) error {
 		return err
 	}
 
-	// Save vocabulary
+	// Save vocabulary, teaching the lexicon what is new
+	var inserted []VocabularyItem
 	for _, v := range line.Vocabulary {
-		if err := dedupVocabularyInsert(ctx, storyID, line.LineNumber, v); err != nil {
+		err := s.dedupVocabularyInsert(ctx, storyID, line.LineNumber, v)
+		if err == errExists {
+			continue
+		}
+		if err != nil {
 			return err
 		}
+		inserted = append(inserted, v)
+	}
+	if err := s.learnVocabulary(ctx, storyID, inserted); err != nil {
+		return err
 	}
 
 	// Save grammar items
 	for _, g := range line.Grammar {
-		if err := dedupGrammarInsert(ctx, storyID, line.LineNumber, g); err != nil {
+		if err := s.dedupGrammarInsert(ctx, storyID, line.LineNumber, g); err != nil {
 			return err
 		}
 	}
 
 	// Save footnotes
 	for _, f := range line.Footnotes {
-		if err := dedupFootnoteInsert(ctx, storyID, line.LineNumber, f); err != nil {
+		if err := s.dedupFootnoteInsert(ctx, storyID, line.LineNumber, f); err != nil {
 			return err
 		}
 	}
 
 	// Save audio files
 	for _, a := range line.AudioFiles {
-		_, err := queries.CreateAudioFile(ctx, db.CreateAudioFileParams{
+		_, err := s.queries.CreateAudioFile(ctx, db.CreateAudioFileParams{
 			StoryID:    pgtype.Int4{Int32: int32(storyID), Valid: true},
 			LineNumber: pgtype.Int4{Int32: int32(line.LineNumber), Valid: true},
 			FilePath:   a.FilePath,
@@ -4,9 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"glossias/src/pkg/cache"
-	"glossias/src/pkg/database"
 	"glossias/src/pkg/generated/db"
 	"time"
 
@@ -14,7 +11,6 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	_ "github.com/lib/pq"
-	storage_go "github.com/supabase-community/storage-go"
 )
 
 const (
@@ -32,14 +28,6 @@ var (
 	ErrNotFound             = errors.New("story not found")
 )
 
-var queries *db.Queries
-var rawConn any
-var storageClient *storage_go.Client
-var storageBaseURL string
-var storageAPIKey string
-var cacheInstance *cache.Cache
-var keyBuilder *cache.KeyBuilder
-
 type TxContextKey struct{}
 
 type ContextTxRouter struct {
@@ -74,68 +62,14 @@ func (r *ContextTxRouter) CopyFrom(ctx context.Context, tableName pgx.Identifier
 	return r.Base.CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
-func SetDB(d any) {
-	if d == nil {
-		panic("database connection is nil")
-	}
-	rawConn = d
-	if conn, ok := d.(db.DBTX); ok {
-		queries = db.New(&ContextTxRouter{Base: conn})
-	} else if mockConn, ok := d.(*database.MockDBTX); ok {
-		queries = db.New(mockConn)
-	} else if reconnectConn, ok := d.(*database.ReconnectableDBTX); ok {
-		// ReconnectableDBTX implements DBTX with reconnection logic
-		queries = db.New(&ContextTxRouter{Base: reconnectConn})
-	} else {
-		// For testing - allow nil queries when no real DB connection
-		queries = nil
-	}
-}
-
-// SetStorageClient initializes the Supabase storage client
-func SetStorageClient(url, apiKey string) {
-	if url == "" || apiKey == "" {
-		fmt.Println("Storage credentials missing - operations will fail")
-		storageClient = nil
-		storageBaseURL = ""
-		storageAPIKey = ""
-		return
-	}
-
-	storageClient = storage_go.NewClient(url, apiKey, nil)
-	storageBaseURL = url
-	storageAPIKey = apiKey
-	// Test the connection by listing buckets with retry on 'tx closed'
-	const maxRetries = 3
-	var err error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		_, err = storageClient.ListBuckets()
-		if err == nil {
-			break
-		}
-		if database.IsConnectionError(err) {
-			fmt.Printf("Attempt %d: error received, reconnecting...\n", attempt)
-			fmt.Printf("-- %v\n", err)
-			storageClient = storage_go.NewClient(url, apiKey, nil)
-			time.Sleep(1 * time.Second)
-		} else {
-			break
-		}
-	}
-	if err != nil {
-		panic(fmt.Sprintf("API keys provided, but failed to connect to storage: %v\n", err))
-	}
-	fmt.Printf("Storage client initialized with URL: %s\n", url)
-}
-
 // TestDBConnection tests the database connection with minimal query
-func TestDBConnection(ctx context.Context) error {
-	if rawConn == nil {
+func (s *Service) TestDBConnection(ctx context.Context) error {
+	if s.rawConn == nil {
 		return errors.New("database not initialized")
 	}
 
 	// Use the simplest possible query to test connection
-	if conn, ok := rawConn.(db.DBTX); ok {
+	if conn, ok := s.rawConn.(db.DBTX); ok {
 		var result int
 		err := conn.QueryRow(ctx, "SELECT 1").Scan(&result)
 		return err
@@ -144,51 +78,6 @@ func TestDBConnection(ctx context.Context) error {
 	return errors.New("unable to test database connection")
 }
 
-// SetCache initializes the cache instance
-func SetCache() error {
-	cacheConfig := cache.DefaultConfig()
-	var err error
-	cacheInstance, err = cache.New(cacheConfig)
-	if err != nil {
-		return fmt.Errorf("failed to initialize cache: %w", err)
-	}
-	keyBuilder = cache.NewKeyBuilder()
-	fmt.Println("Cache initialized successfully")
-	return nil
-}
-
-// storageRetry executes a storage operation with retry on connection errors
-func storageRetry(operation func() error) error {
-	const maxRetries = 3
-	var err error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err = operation()
-		if err == nil {
-			return nil
-		}
-
-		// Check if it's a connection error
-		if database.IsConnectionError(err) {
-
-			fmt.Printf("Storage connection error (attempt %d/%d): %v\n", attempt, maxRetries, err)
-
-			if attempt < maxRetries {
-				time.Sleep(1 * time.Second)
-				// Reinitialize storage client
-				if storageBaseURL != "" && storageAPIKey != "" {
-					storageClient = storage_go.NewClient(storageBaseURL, storageAPIKey, nil)
-				}
-			}
-		} else {
-			// Non-connection error, don't retry
-			return err
-		}
-	}
-
-	return fmt.Errorf("storage operation failed after %d attempts: %w", maxRetries, err)
-}
-
 type Story struct {
 	Metadata StoryMetadata `json:"metadata"`
 	Content  StoryContent  `json:"content"`
@@ -206,6 +95,7 @@ type StoryMetadata struct {
 	LastRevision  *time.Time        `json:"lastRevision,omitempty"`
 	GrammarPoints []GrammarPoint    `json:"grammarPoints"`
 	Language      string            `json:"languageCode,omitempty"`
+	Schedule      *StorySchedule    `json:"schedule,omitempty"` // Nil when the story has none
 }
 
 type Author struct {
@@ -258,6 +148,81 @@ type AudioFile struct {
 	Label      string `json:"label"`
 }
 
+// StoryImage represents an image asset attached to a story
+type StoryImage struct {
+	ID          int    `json:"id"`
+	StoryID     int    `json:"storyId"`
+	FilePath    string `json:"filePath"`
+	FileBucket  string `json:"fileBucket"`
+	Label       string `json:"label"`
+	ContentType string `json:"contentType"`
+	SizeBytes   int64  `json:"sizeBytes"`
+}
+
+// TargetVocab is one of the target lexical forms of a story, with its
+// pronunciation audio and picture assets. Asset fields are empty until uploaded.
+type TargetVocab struct {
+	ID          int    `json:"id"`
+	StoryID     int    `json:"storyId"`
+	LexicalForm string `json:"lexicalForm"`
+	AudioPath   string `json:"audioPath,omitempty"`
+	AudioBucket string `json:"audioBucket,omitempty"`
+	ImagePath   string `json:"imagePath,omitempty"`
+	ImageBucket string `json:"imageBucket,omitempty"`
+	Occurrences int    `json:"occurrences"` // Matching vocabulary_items in the story
+}
+
+// SignedTargetVocab is a target word with signed read URLs for its assets.
+// A URL is empty when the asset has not been uploaded.
+type SignedTargetVocab struct {
+	TargetVocab
+	AudioURL string `json:"audioUrl,omitempty"`
+	ImageURL string `json:"imageUrl,omitempty"`
+}
+
+// IdentifyOption is a picture offered in an Identify attempt. ID is opaque and only
+// resolved on the server, so it does not reveal which target word the picture shows.
+type IdentifyOption struct {
+	ID       string `json:"id"`
+	ImageURL string `json:"imageUrl,omitempty"`
+}
+
+// RecallSentence is one of the ordered sentences of the Recall phase.
+// TargetVocabID and ImageID are 0 when the linked record has been deleted.
+type RecallSentence struct {
+	ID            int    `json:"id"`
+	StoryID       int    `json:"storyId"`
+	SequenceOrder int    `json:"sequenceOrder"` // 1-indexed
+	Text          string `json:"text"`
+	TargetVocabID int    `json:"targetVocabId"`
+	ImageID       int    `json:"imageId"`
+}
+
+// ProduceSegment is a stretch of story lines students retell from an English prompt
+type ProduceSegment struct {
+	ID             int    `json:"id"`
+	StoryID        int    `json:"storyId"`
+	StartLine      int    `json:"startLine"` // 1-indexed, inclusive
+	EndLine        int    `json:"endLine"`
+	EnglishPrompt  string `json:"englishPrompt"`
+	ReferenceText  string `json:"referenceText"`
+	GrammarPointID int    `json:"grammarPointId"` // 0 when the grammar point has been deleted
+}
+
+// ProduceSubmission is a student's latest answer to a produce segment
+type ProduceSubmission struct {
+	ID            int       `json:"id"`
+	UserID        string    `json:"userId"`
+	StoryID       int       `json:"storyId"`
+	SegmentID     int       `json:"segmentId"`
+	StudentText   string    `json:"studentText"`
+	SubmittedAt   time.Time `json:"submittedAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	AIScore       *int      `json:"aiScore"` // 0-100, nil until graded
+	AIFeedback    string    `json:"aiFeedback,omitempty"`
+	GradingStatus string    `json:"gradingStatus"` // GradingPending or GradingGraded
+}
+
 // GrammarPoint represents a grammar point definition
 type GrammarPoint struct {
 	ID          int    `json:"id"`
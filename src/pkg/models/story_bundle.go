@@ -0,0 +1,841 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// A story bundle is a zip file with the .glossias extension holding
+//
+//	manifest.json          StoryBundleManifest
+//	story.json             the story as written by Story.ToJSON
+//	files/{bucket}/{path}  every audio and image file the story refers to
+//
+// Bundles move stories between environments and instructors. IDs inside a bundle
+// only link its parts together; importing creates new ones. Version 1 bundles lack
+// the activities added to the manifest in version 2 and import without them.
+const (
+	StoryBundleFormat    = "glossias-story"
+	StoryBundleVersion   = 2
+	StoryBundleExtension = ".glossias"
+	// MaxStoryBundleBytes bounds the size of an uploaded bundle
+	MaxStoryBundleBytes = 200 << 20
+	// maxBundleFileBytes bounds a single file unpacked from a bundle
+	maxBundleFileBytes = 50 << 20
+
+	bundleManifestName = "manifest.json"
+	bundleStoryName    = "story.json"
+	bundleFilesDir     = "files/"
+	bundleAudioBucket  = "audio-files"
+)
+
+var (
+	ErrInvalidStoryBundle       = errors.New("invalid story bundle")
+	ErrUnsupportedBundleVersion = errors.New("story bundle was made by a newer version of glossias")
+)
+
+// StoryBundleManifest is the manifest.json of a story bundle: the format version
+// and the parts of a story that Story does not carry
+type StoryBundleManifest struct {
+	Format             string              `json:"format"`
+	Version            int                 `json:"version"`
+	ExportedAt         time.Time           `json:"exportedAt"`
+	Descriptions       map[string]string   `json:"descriptions,omitempty"` // By language; story.json holds only one
+	Translations       []LineTranslation   `json:"translations"`
+	Images             []StoryImage        `json:"images"`
+	TargetVocab        []TargetVocab       `json:"targetVocab,omitempty"`
+	RecallSentences    []RecallSentence    `json:"recallSentences,omitempty"`
+	ProduceSegments    []ProduceSegment    `json:"produceSegments,omitempty"`
+	ProduceExplanation string              `json:"produceExplanation,omitempty"`
+	VocabContrasts     []VocabContrastPair `json:"vocabContrasts,omitempty"`
+	PhaseFlow          []string            `json:"phaseFlow,omitempty"` // The story's own flow; empty when it follows its course
+}
+
+// ExportStoryBundle packs a story with its translations, activities and files into a
+// .glossias bundle. Student work and the schedule are not exported.
+func (s *Service) ExportStoryBundle(ctx context.Context, storyID int, userID string) ([]byte, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage client not initialized")
+	}
+
+	story, err := s.GetStoryData(ctx, storyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := s.storyBundleManifest(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	storyJSON, err := story.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeBundleEntry(zw, bundleManifestName, manifestJSON); err != nil {
+		return nil, err
+	}
+	if err := writeBundleEntry(zw, bundleStoryName, storyJSON); err != nil {
+		return nil, err
+	}
+
+	written := make(map[string]bool)
+	addFile := func(bucket, filePath string) error {
+		name := bundleFileName(bucket, filePath)
+		if written[name] {
+			return nil
+		}
+		data, err := s.storage.Download(bucket, filePath)
+		if err != nil {
+			return fmt.Errorf("failed to download %s/%s: %w", bucket, filePath, err)
+		}
+		written[name] = true
+		return writeBundleEntry(zw, name, data)
+	}
+	for _, line := range story.Content.Lines {
+		for _, audioFile := range line.AudioFiles {
+			if err := addFile(audioFile.FileBucket, audioFile.FilePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, image := range manifest.Images {
+		if err := addFile(image.FileBucket, image.FilePath); err != nil {
+			return nil, err
+		}
+	}
+	for _, word := range manifest.TargetVocab {
+		if word.AudioPath != "" {
+			if err := addFile(word.AudioBucket, word.AudioPath); err != nil {
+				return nil, err
+			}
+		}
+		if word.ImagePath != "" {
+			if err := addFile(word.ImageBucket, word.ImagePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// storyBundleManifest collects the parts of a story that story.json does not carry
+func (s *Service) storyBundleManifest(ctx context.Context, storyID int) (*StoryBundleManifest, error) {
+	manifest := &StoryBundleManifest{
+		Format:       StoryBundleFormat,
+		Version:      StoryBundleVersion,
+		ExportedAt:   s.clock.Now().UTC(),
+		Descriptions: make(map[string]string),
+	}
+	descriptions, err := s.queries.GetStoryDescriptions(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	for _, description := range descriptions {
+		manifest.Descriptions[description.LanguageCode] = description.DescriptionText
+	}
+	if manifest.Translations, err = s.GetAllTranslationsForStory(ctx, storyID); err != nil {
+		return nil, err
+	}
+	if manifest.Images, err = s.GetStoryImages(ctx, storyID); err != nil {
+		return nil, err
+	}
+	if manifest.TargetVocab, err = s.GetStoryTargetVocab(ctx, storyID); err != nil {
+		return nil, err
+	}
+	if manifest.RecallSentences, err = s.GetStoryRecallSentences(ctx, storyID); err != nil {
+		return nil, err
+	}
+	if manifest.ProduceSegments, err = s.GetStoryProduceSegments(ctx, storyID); err != nil {
+		return nil, err
+	}
+	if manifest.ProduceExplanation, err = s.GetProduceExplanation(ctx, storyID); err != nil {
+		return nil, err
+	}
+	if manifest.VocabContrasts, err = s.GetStoryVocabContrasts(ctx, storyID); err != nil {
+		return nil, err
+	}
+	flow, err := s.queries.GetStoryPhaseFlow(ctx, int32(storyID))
+	if err == nil {
+		manifest.PhaseFlow = flow.Phases
+	} else if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ImportStoryBundle creates a new story in a course from a .glossias bundle, authored by
+// the importing user, and returns its ID. Annotation positions are checked against the
+// line text and the links between the bundle's parts are remapped to the new records.
+// Files are uploaded under the new story before anything is saved, and removed again
+// if saving fails.
+func (s *Service) ImportStoryBundle(ctx context.Context, courseID int, userID string, data []byte) (int, error) {
+	if s.storage == nil {
+		return 0, errors.New("storage client not initialized")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("%w: not a zip file", ErrInvalidStoryBundle)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var manifest StoryBundleManifest
+	if err := readBundleJSON(files, bundleManifestName, &manifest); err != nil {
+		return 0, err
+	}
+	if manifest.Format != StoryBundleFormat {
+		return 0, fmt.Errorf("%w: unknown format %q", ErrInvalidStoryBundle, manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > StoryBundleVersion {
+		return 0, ErrUnsupportedBundleVersion
+	}
+	story := NewStory()
+	if err := readBundleJSON(files, bundleStoryName, story); err != nil {
+		return 0, err
+	}
+	if err := validateStoryBundle(story, &manifest, files); err != nil {
+		return 0, err
+	}
+
+	author, err := s.queries.GetUser(ctx, userID)
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	storyID, err := s.queries.ReserveStoryID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	paths, uploaded, err := s.uploadBundleFiles(storyID, story, &manifest, files)
+	if err == nil {
+		err = s.withTransaction(ctx, func(txCtx context.Context) error {
+			if err := s.queries.CreateStoryWithID(txCtx, db.CreateStoryWithIDParams{
+				StoryID:    storyID,
+				WeekNumber: int32(story.Metadata.WeekNumber),
+				DayLetter:  story.Metadata.DayLetter,
+				VideoUrl:   pgtype.Text{String: story.Metadata.VideoURL, Valid: story.Metadata.VideoURL != ""},
+				AuthorID:   author.UserID,
+				AuthorName: author.Name,
+				CourseID:   pgtype.Int4{Int32: int32(courseID), Valid: true},
+			}); err != nil {
+				return err
+			}
+
+			grammarPointIDs, err := s.importBundleText(txCtx, storyID, story, &manifest)
+			if err != nil {
+				return err
+			}
+			if err := s.importBundleAnnotations(txCtx, storyID, story, grammarPointIDs); err != nil {
+				return err
+			}
+			imageIDs, targetVocabIDs, err := s.importBundleAssets(txCtx, storyID, story, &manifest, files, paths)
+			if err != nil {
+				return err
+			}
+			if err := s.importBundleActivities(txCtx, storyID, &manifest, grammarPointIDs, imageIDs, targetVocabIDs); err != nil {
+				return err
+			}
+			return s.recordStoryRevision(txCtx, int(storyID), "import")
+		})
+	}
+	if err != nil {
+		// Nothing refers to the uploaded files once the transaction is rolled back
+		for _, object := range uploaded {
+			if removeErr := s.storage.Remove(object.bucket, []string{object.path}); removeErr != nil {
+				fmt.Printf("Failed to remove %s/%s after a failed import: %v\n", object.bucket, object.path, removeErr)
+			}
+		}
+		return 0, err
+	}
+
+	fmt.Printf("Imported story bundle into course %d as story %d with %d files\n", courseID, storyID, len(uploaded))
+	return int(storyID), nil
+}
+
+// uploadBundleFiles stores every file of a bundle under stories/{storyID}/, returning the
+// new storage path by bundle file name and what was uploaded, even when it fails
+func (s *Service) uploadBundleFiles(storyID int32, story *Story, manifest *StoryBundleManifest, files map[string]*zip.File) (map[string]string, []storageObject, error) {
+	paths := make(map[string]string) // Bundle file name -> new storage path
+	used := make(map[string]bool)
+	var uploaded []storageObject
+	upload := func(bucket, filePath, contentType string) error {
+		name := bundleFileName(bucket, filePath)
+		if _, ok := paths[name]; ok {
+			return nil
+		}
+		content, err := readBundleFile(files[name])
+		if err != nil {
+			return err
+		}
+		newPath := fmt.Sprintf("stories/%d/%s", storyID, path.Base(filePath))
+		if used[newPath] {
+			newPath = fmt.Sprintf("stories/%d/%d_%s", storyID, len(used), path.Base(filePath))
+		}
+		used[newPath] = true
+		if err := s.storage.Upload(bucket, newPath, content, contentType); err != nil {
+			return fmt.Errorf("failed to upload %s/%s: %w", bucket, newPath, err)
+		}
+		uploaded = append(uploaded, storageObject{bucket, newPath})
+		paths[name] = newPath
+		return nil
+	}
+
+	for _, line := range story.Content.Lines {
+		for _, audioFile := range line.AudioFiles {
+			if err := upload(audioFile.FileBucket, audioFile.FilePath, fileContentType(audioFile.FilePath)); err != nil {
+				return nil, uploaded, err
+			}
+		}
+	}
+	for _, image := range manifest.Images {
+		if err := upload(image.FileBucket, image.FilePath, image.ContentType); err != nil {
+			return nil, uploaded, err
+		}
+	}
+	for _, word := range manifest.TargetVocab {
+		if word.AudioPath != "" {
+			if err := upload(word.AudioBucket, word.AudioPath, fileContentType(word.AudioPath)); err != nil {
+				return nil, uploaded, err
+			}
+		}
+		if word.ImagePath != "" {
+			if err := upload(word.ImageBucket, word.ImagePath, fileContentType(word.ImagePath)); err != nil {
+				return nil, uploaded, err
+			}
+		}
+	}
+	return paths, uploaded, nil
+}
+
+// storageObject is a file in object storage
+type storageObject struct {
+	bucket, path string
+}
+
+// importBundleText saves the titles, descriptions, grammar points, lines and translations
+// of an imported story, returning new grammar point IDs by their ID in the bundle
+func (s *Service) importBundleText(ctx context.Context, storyID int32, story *Story, manifest *StoryBundleManifest) (map[int]int32, error) {
+	for lang, title := range story.Metadata.Title {
+		if err := s.queries.UpsertStoryTitle(ctx, db.UpsertStoryTitleParams{
+			StoryID:      storyID,
+			LanguageCode: lang,
+			Title:        title,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	descriptions := manifest.Descriptions
+	if len(descriptions) == 0 && (story.Metadata.Description.Text != "" || story.Metadata.Language != "") {
+		// Version 1 bundles only carry the description in story.json
+		descriptions = map[string]string{story.Metadata.Language: story.Metadata.Description.Text}
+	}
+	for lang, text := range descriptions {
+		if err := s.queries.UpsertStoryDescription(ctx, db.UpsertStoryDescriptionParams{
+			StoryID:         storyID,
+			LanguageCode:    lang,
+			DescriptionText: text,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	grammarPointIDs := make(map[int]int32, len(story.Metadata.GrammarPoints))
+	for _, point := range story.Metadata.GrammarPoints {
+		created, err := s.queries.CreateGrammarPoint(ctx, db.CreateGrammarPointParams{
+			StoryID:     storyID,
+			Name:        point.Name,
+			Description: pgtype.Text{String: point.Description, Valid: point.Description != ""},
+		})
+		if err != nil {
+			return nil, err
+		}
+		grammarPointIDs[point.ID] = created.GrammarPointID
+	}
+
+	lines := make([]db.BulkCreateStoryLinesParams, len(story.Content.Lines))
+	for i, line := range story.Content.Lines {
+		lines[i] = db.BulkCreateStoryLinesParams{StoryID: storyID, LineNumber: int32(line.LineNumber), Text: line.Text}
+	}
+	if _, err := s.queries.BulkCreateStoryLines(ctx, lines); err != nil {
+		return nil, err
+	}
+
+	if len(manifest.Translations) > 0 {
+		params := make([]db.BulkCreateLineTranslationsParams, len(manifest.Translations))
+		for i, translation := range manifest.Translations {
+			params[i] = db.BulkCreateLineTranslationsParams{
+				StoryID:         storyID,
+				LineNumber:      translation.LineNumber,
+				LanguageCode:    translation.LanguageCode,
+				TranslationText: translation.TranslationText,
+			}
+		}
+		if _, err := s.queries.BulkCreateLineTranslations(ctx, params); err != nil {
+			return nil, err
+		}
+	}
+	return grammarPointIDs, nil
+}
+
+// importBundleAnnotations saves the vocabulary, grammar items and footnotes of an imported story
+func (s *Service) importBundleAnnotations(ctx context.Context, storyID int32, story *Story, grammarPointIDs map[int]int32) error {
+	id := pgtype.Int4{Int32: storyID, Valid: true}
+	var vocab []db.BulkCreateVocabularyItemsParams
+	var grammar []db.BulkCreateGrammarItemsParams
+	for _, line := range story.Content.Lines {
+		lineNumber := pgtype.Int4{Int32: int32(line.LineNumber), Valid: true}
+		for _, item := range line.Vocabulary {
+			vocab = append(vocab, db.BulkCreateVocabularyItemsParams{
+				StoryID:       id,
+				LineNumber:    lineNumber,
+				Word:          item.Word,
+				LexicalForm:   item.LexicalForm,
+				PositionStart: int32(item.Position[0]),
+				PositionEnd:   int32(item.Position[1]),
+			})
+		}
+		for _, item := range line.Grammar {
+			grammarPointID := pgtype.Int4{}
+			if item.GrammarPointID != nil {
+				grammarPointID = pgtype.Int4{Int32: grammarPointIDs[*item.GrammarPointID], Valid: true}
+			}
+			grammar = append(grammar, db.BulkCreateGrammarItemsParams{
+				StoryID:        id,
+				LineNumber:     lineNumber,
+				GrammarPointID: grammarPointID,
+				Text:           item.Text,
+				PositionStart:  int32(item.Position[0]),
+				PositionEnd:    int32(item.Position[1]),
+			})
+		}
+		for _, footnote := range line.Footnotes {
+			footnoteID, err := s.queries.CreateFootnote(ctx, db.CreateFootnoteParams{
+				StoryID:      id,
+				LineNumber:   lineNumber,
+				FootnoteText: footnote.Text,
+			})
+			if err != nil {
+				return err
+			}
+			for _, reference := range footnote.References {
+				if err := s.queries.CreateFootnoteReference(ctx, db.CreateFootnoteReferenceParams{
+					FootnoteID: footnoteID,
+					Reference:  reference,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if len(vocab) > 0 {
+		if _, err := s.queries.BulkCreateVocabularyItems(ctx, vocab); err != nil {
+			return err
+		}
+	}
+	if len(grammar) > 0 {
+		if _, err := s.queries.BulkCreateGrammarItems(ctx, grammar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importBundleAssets saves the line audio, image and target vocabulary records of an
+// imported story, pointing them at the uploaded files. It returns the new image and
+// target vocabulary IDs by their ID in the bundle.
+func (s *Service) importBundleAssets(ctx context.Context, storyID int32, story *Story, manifest *StoryBundleManifest, files map[string]*zip.File, paths map[string]string) (map[int]int32, map[int]int32, error) {
+	newPath := func(bucket, filePath string) pgtype.Text {
+		if filePath == "" {
+			return pgtype.Text{}
+		}
+		return pgtype.Text{String: paths[bundleFileName(bucket, filePath)], Valid: true}
+	}
+
+	var audioFiles []db.BulkCreateAudioFilesParams
+	for _, line := range story.Content.Lines {
+		for _, audioFile := range line.AudioFiles {
+			audioFiles = append(audioFiles, db.BulkCreateAudioFilesParams{
+				StoryID:    pgtype.Int4{Int32: storyID, Valid: true},
+				LineNumber: pgtype.Int4{Int32: int32(line.LineNumber), Valid: true},
+				FilePath:   newPath(audioFile.FileBucket, audioFile.FilePath).String,
+				FileBucket: audioFile.FileBucket,
+				Label:      audioFile.Label,
+			})
+		}
+	}
+	if len(audioFiles) > 0 {
+		if _, err := s.queries.BulkCreateAudioFiles(ctx, audioFiles); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	imageIDs := make(map[int]int32, len(manifest.Images))
+	for _, image := range manifest.Images {
+		created, err := s.queries.CreateStoryImage(ctx, db.CreateStoryImageParams{
+			StoryID:     storyID,
+			FilePath:    newPath(image.FileBucket, image.FilePath).String,
+			FileBucket:  image.FileBucket,
+			Label:       image.Label,
+			ContentType: image.ContentType,
+			SizeBytes:   int64(files[bundleFileName(image.FileBucket, image.FilePath)].UncompressedSize64),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		imageIDs[image.ID] = created.ImageID
+	}
+
+	targetVocabIDs := make(map[int]int32, len(manifest.TargetVocab))
+	for _, word := range manifest.TargetVocab {
+		created, err := s.queries.CreateTargetVocab(ctx, db.CreateTargetVocabParams{
+			StoryID:     storyID,
+			LexicalForm: word.LexicalForm,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if word.AudioPath != "" {
+			if _, err := s.queries.SetTargetVocabAudio(ctx, db.SetTargetVocabAudioParams{
+				ID:          created.ID,
+				StoryID:     storyID,
+				AudioPath:   newPath(word.AudioBucket, word.AudioPath),
+				AudioBucket: pgtype.Text{String: word.AudioBucket, Valid: true},
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
+		if word.ImagePath != "" {
+			if _, err := s.queries.SetTargetVocabImage(ctx, db.SetTargetVocabImageParams{
+				ID:          created.ID,
+				StoryID:     storyID,
+				ImagePath:   newPath(word.ImageBucket, word.ImagePath),
+				ImageBucket: pgtype.Text{String: word.ImageBucket, Valid: true},
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
+		targetVocabIDs[word.ID] = created.ID
+	}
+	return imageIDs, targetVocabIDs, nil
+}
+
+// importBundleActivities saves the recall sentences, produce segments and explanation,
+// vocab contrast pairs and phase flow of an imported story
+func (s *Service) importBundleActivities(ctx context.Context, storyID int32, manifest *StoryBundleManifest, grammarPointIDs, imageIDs, targetVocabIDs map[int]int32) error {
+	link := func(id int, ids map[int]int32) pgtype.Int4 {
+		mapped, ok := ids[id]
+		return pgtype.Int4{Int32: mapped, Valid: ok}
+	}
+
+	for i, sentence := range manifest.RecallSentences {
+		if _, err := s.queries.CreateRecallSentence(ctx, db.CreateRecallSentenceParams{
+			StoryID:       storyID,
+			SequenceOrder: int32(i + 1),
+			SentenceText:  sentence.Text,
+			TargetVocabID: link(sentence.TargetVocabID, targetVocabIDs),
+			ImageID:       link(sentence.ImageID, imageIDs),
+		}); err != nil {
+			return err
+		}
+	}
+	for _, segment := range manifest.ProduceSegments {
+		if _, err := s.queries.CreateProduceSegment(ctx, db.CreateProduceSegmentParams{
+			StoryID:        storyID,
+			StartLine:      int32(segment.StartLine),
+			EndLine:        int32(segment.EndLine),
+			EnglishPrompt:  segment.EnglishPrompt,
+			ReferenceText:  segment.ReferenceText,
+			GrammarPointID: link(segment.GrammarPointID, grammarPointIDs),
+		}); err != nil {
+			return err
+		}
+	}
+	if manifest.ProduceExplanation != "" {
+		if _, err := s.queries.UpsertProduceExplanation(ctx, db.UpsertProduceExplanationParams{
+			StoryID:     storyID,
+			Explanation: manifest.ProduceExplanation,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, pair := range manifest.VocabContrasts {
+		formA, formB := contrastForms(pair.FormA, pair.FormB)
+		if _, err := s.queries.CreateVocabContrast(ctx, db.CreateVocabContrastParams{
+			StoryID: storyID,
+			FormA:   formA,
+			FormB:   formB,
+		}); err != nil {
+			return err
+		}
+	}
+	if len(manifest.PhaseFlow) > 0 {
+		if _, err := s.queries.UpsertStoryPhaseFlow(ctx, db.UpsertStoryPhaseFlowParams{
+			StoryID: storyID,
+			Phases:  manifest.PhaseFlow,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStoryBundle checks an unpacked bundle before anything is saved: lines are
+// numbered uniquely, annotations and activities lie within the story, links refer to
+// parts of the bundle, limits hold and every referenced file is present and allowed
+func validateStoryBundle(story *Story, manifest *StoryBundleManifest, files map[string]*zip.File) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidStoryBundle, fmt.Sprintf(format, args...))
+	}
+	if story.Metadata.DayLetter == "" {
+		return invalid("story has no day letter")
+	}
+	if len(story.Content.Lines) == 0 {
+		return invalid("story has no lines")
+	}
+
+	grammarPoints := make(map[int]bool, len(story.Metadata.GrammarPoints))
+	for _, point := range story.Metadata.GrammarPoints {
+		if point.Name == "" {
+			return invalid("grammar point %d has no name", point.ID)
+		}
+		grammarPoints[point.ID] = true
+	}
+
+	lines := make(map[int]bool, len(story.Content.Lines))
+	for _, line := range story.Content.Lines {
+		if line.LineNumber < 1 || lines[line.LineNumber] {
+			return invalid("line number %d is invalid or repeated", line.LineNumber)
+		}
+		lines[line.LineNumber] = true
+
+		length := utf8.RuneCountInString(line.Text)
+		for _, item := range line.Vocabulary {
+			if !validPosition(item.Position, length) {
+				return invalid("vocabulary %q on line %d is outside the line", item.Word, line.LineNumber)
+			}
+		}
+		for _, item := range line.Grammar {
+			if !validPosition(item.Position, length) {
+				return invalid("grammar %q on line %d is outside the line", item.Text, line.LineNumber)
+			}
+			if item.GrammarPointID != nil && !grammarPoints[*item.GrammarPointID] {
+				return invalid("grammar %q on line %d refers to unknown grammar point %d", item.Text, line.LineNumber, *item.GrammarPointID)
+			}
+		}
+		for _, audioFile := range line.AudioFiles {
+			if audioFile.FileBucket != bundleAudioBucket || audioFile.Label == "" {
+				return invalid("audio file %q on line %d is invalid", audioFile.FilePath, line.LineNumber)
+			}
+			if err := checkBundleFile(files, audioFile.FileBucket, audioFile.FilePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	for lang := range manifest.Descriptions {
+		if lang == "" {
+			return invalid("description has no language")
+		}
+	}
+	for _, translation := range manifest.Translations {
+		if !lines[int(translation.LineNumber)] || translation.LanguageCode == "" {
+			return invalid("translation of line %d is invalid", translation.LineNumber)
+		}
+	}
+	checkImage := func(bucket, filePath, contentType string) error {
+		if err := checkBundleFile(files, bucket, filePath); err != nil {
+			return err
+		}
+		size := int64(files[bundleFileName(bucket, filePath)].UncompressedSize64)
+		if err := ValidateImageUpload(filePath, contentType, size); err != nil {
+			return fmt.Errorf("%w: image %q: %v", ErrInvalidStoryBundle, filePath, err)
+		}
+		return nil
+	}
+
+	images := make(map[int]bool, len(manifest.Images))
+	for _, image := range manifest.Images {
+		if image.FileBucket != ImageBucket || ValidateImageLabel(image.Label) != nil || images[image.ID] {
+			return invalid("image %q is invalid", image.FilePath)
+		}
+		images[image.ID] = true
+		if err := checkImage(image.FileBucket, image.FilePath, image.ContentType); err != nil {
+			return err
+		}
+	}
+
+	if len(manifest.TargetVocab) > MaxTargetVocab {
+		return invalid("%v", ErrTargetVocabLimit)
+	}
+	targetVocab := make(map[int]bool, len(manifest.TargetVocab))
+	lexicalForms := make(map[string]bool, len(manifest.TargetVocab))
+	for _, word := range manifest.TargetVocab {
+		if word.LexicalForm == "" || targetVocab[word.ID] || lexicalForms[word.LexicalForm] {
+			return invalid("target vocabulary %q is invalid or repeated", word.LexicalForm)
+		}
+		targetVocab[word.ID] = true
+		lexicalForms[word.LexicalForm] = true
+		if word.AudioPath != "" {
+			if word.AudioBucket != bundleAudioBucket {
+				return invalid("audio of target vocabulary %q is invalid", word.LexicalForm)
+			}
+			if err := checkBundleFile(files, word.AudioBucket, word.AudioPath); err != nil {
+				return err
+			}
+		}
+		if word.ImagePath != "" {
+			if word.ImageBucket != ImageBucket {
+				return invalid("image of target vocabulary %q is invalid", word.LexicalForm)
+			}
+			if err := checkImage(word.ImageBucket, word.ImagePath, fileContentType(word.ImagePath)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(manifest.RecallSentences) > MaxRecallSentences {
+		return invalid("%v", ErrRecallSentenceLimit)
+	}
+	for _, sentence := range manifest.RecallSentences {
+		if sentence.Text == "" {
+			return invalid("recall sentence has no text")
+		}
+		if sentence.TargetVocabID != 0 && !targetVocab[sentence.TargetVocabID] ||
+			sentence.ImageID != 0 && !images[sentence.ImageID] {
+			return invalid("recall sentence %q refers to a target word or image outside the bundle", sentence.Text)
+		}
+	}
+
+	if len(manifest.ProduceSegments) > MaxProduceSegments {
+		return invalid("%v", ErrProduceSegmentLimit)
+	}
+	for _, segment := range manifest.ProduceSegments {
+		if segment.EnglishPrompt == "" || segment.ReferenceText == "" {
+			return invalid("produce segment has no prompt or reference text")
+		}
+		if !lines[segment.StartLine] || !lines[segment.EndLine] || segment.EndLine < segment.StartLine {
+			return invalid("produce segment of lines %d-%d is outside the story", segment.StartLine, segment.EndLine)
+		}
+		if segment.GrammarPointID != 0 && !grammarPoints[segment.GrammarPointID] {
+			return invalid("produce segment refers to unknown grammar point %d", segment.GrammarPointID)
+		}
+	}
+
+	for _, pair := range manifest.VocabContrasts {
+		if pair.FormA == "" || pair.FormB == "" || pair.FormA == pair.FormB {
+			return invalid("vocab contrast %q/%q is invalid", pair.FormA, pair.FormB)
+		}
+	}
+	if len(manifest.PhaseFlow) > 0 {
+		if err := ValidatePhaseFlow(manifest.PhaseFlow); err != nil {
+			return invalid("%v", err)
+		}
+	}
+	return nil
+}
+
+// validPosition reports whether a [start, end) rune range is a non-empty part of a line
+func validPosition(position [2]int, length int) bool {
+	return position[0] >= 0 && position[0] < position[1] && position[1] <= length
+}
+
+// checkBundleFile checks a file referenced by a bundle is present and small enough
+func checkBundleFile(files map[string]*zip.File, bucket, filePath string) error {
+	name := bundleFileName(bucket, filePath)
+	f, ok := files[name]
+	if !ok || path.Base(filePath) == "." || strings.Contains(filePath, "..") {
+		return fmt.Errorf("%w: missing file %s", ErrInvalidStoryBundle, name)
+	}
+	if f.UncompressedSize64 > maxBundleFileBytes {
+		return fmt.Errorf("%w: file %s is too large", ErrInvalidStoryBundle, name)
+	}
+	return nil
+}
+
+// fileContentType guesses a file's content type from its extension
+func fileContentType(filePath string) string {
+	if contentType := mime.TypeByExtension(path.Ext(filePath)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+func bundleFileName(bucket, filePath string) string {
+	return bundleFilesDir + bucket + "/" + filePath
+}
+
+func writeBundleEntry(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func readBundleJSON(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrInvalidStoryBundle, name)
+	}
+	data, err := readBundleFile(f)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidStoryBundle, name, err)
+	}
+	return nil
+}
+
+// readBundleFile unpacks a file, refusing more than maxBundleFileBytes whatever the
+// zip header claims
+func readBundleFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidStoryBundle, f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxBundleFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidStoryBundle, f.Name, err)
+	}
+	if len(data) > maxBundleFileBytes {
+		return nil, fmt.Errorf("%w: file %s is too large", ErrInvalidStoryBundle, f.Name)
+	}
+	return data, nil
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"glossias/src/pkg/database"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// bundleStory is a one-line Hebrew story whose grammar item refers to grammar point 4
+func bundleStory() *Story {
+	point := 4
+	story := NewStory()
+	story.Metadata.WeekNumber = 2
+	story.Metadata.DayLetter = "a"
+	story.Metadata.Title["en"] = "The king"
+	story.Metadata.GrammarPoints = []GrammarPoint{{ID: point, Name: "Construct state"}}
+	story.Content.Lines = []StoryLine{{
+		LineNumber: 1,
+		Text:       "מֶלֶךְ הָעִיר",
+		Vocabulary: []VocabularyItem{{Word: "מֶלֶךְ", LexicalForm: "מֶלֶךְ", Position: [2]int{0, 6}}},
+		Grammar:    []GrammarItem{{GrammarPointID: &point, Text: "מֶלֶךְ הָעִיר", Position: [2]int{0, 13}}},
+		AudioFiles: []AudioFile{{FilePath: "stories/9/line_1_complete_1_king.mp3", FileBucket: "audio-files", Label: "complete"}},
+	}}
+	return story
+}
+
+// writeTestBundle zips a manifest, story and files into a bundle
+func writeTestBundle(t *testing.T, manifest StoryBundleManifest, story *Story, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifestJSON, _ := json.Marshal(manifest)
+	storyJSON, _ := story.ToJSON()
+	entries := map[string][]byte{bundleManifestName: manifestJSON, bundleStoryName: storyJSON}
+	for name, content := range files {
+		entries[name] = []byte(content)
+	}
+	for name, content := range entries {
+		if err := writeBundleEntry(zw, name, content); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestValidateStoryBundle(t *testing.T) {
+	manifest := StoryBundleManifest{Format: StoryBundleFormat, Version: StoryBundleVersion}
+	audio := map[string]string{"files/audio-files/stories/9/line_1_complete_1_king.mp3": "mp3"}
+	zipFiles := func(contents map[string]string) map[string]*zip.File {
+		data := writeTestBundle(t, manifest, bundleStory(), contents)
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("Failed to read bundle: %v", err)
+		}
+		files := make(map[string]*zip.File)
+		for _, f := range zr.File {
+			files[f.Name] = f
+		}
+		return files
+	}
+
+	if err := validateStoryBundle(bundleStory(), &manifest, zipFiles(audio)); err != nil {
+		t.Fatalf("Expected the bundle to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Story)
+		files  map[string]string
+	}{
+		{"position past the line, counted in runes", func(s *Story) { s.Content.Lines[0].Vocabulary[0].Position = [2]int{7, 14} }, audio},
+		{"empty position", func(s *Story) { s.Content.Lines[0].Grammar[0].Position = [2]int{3, 3} }, audio},
+		{"unknown grammar point", func(s *Story) { s.Metadata.GrammarPoints[0].ID = 5 }, audio},
+		{"repeated line number", func(s *Story) { s.Content.Lines = append(s.Content.Lines, StoryLine{LineNumber: 1}) }, audio},
+		{"missing audio file", func(s *Story) {}, nil},
+	}
+	for _, test := range tests {
+		story := bundleStory()
+		test.modify(story)
+		if err := validateStoryBundle(story, &manifest, zipFiles(test.files)); !errors.Is(err, ErrInvalidStoryBundle) {
+			t.Errorf("%s: expected ErrInvalidStoryBundle, got %v", test.name, err)
+		}
+	}
+}
+
+func TestImportStoryBundleVersion(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), &memStorage{objects: map[string]int64{}}, nil, nil)
+
+	if _, err := svc.ImportStoryBundle(context.Background(), 1, "admin-1", []byte("not a zip")); !errors.Is(err, ErrInvalidStoryBundle) {
+		t.Errorf("Expected ErrInvalidStoryBundle for a non-zip body, got %v", err)
+	}
+	newer := writeTestBundle(t, StoryBundleManifest{Format: StoryBundleFormat, Version: StoryBundleVersion + 1}, bundleStory(), nil)
+	if _, err := svc.ImportStoryBundle(context.Background(), 1, "admin-1", newer); err != ErrUnsupportedBundleVersion {
+		t.Errorf("Expected ErrUnsupportedBundleVersion, got %v", err)
+	}
+}
+
+func TestImportStoryBundleUploadsFilesUnderNewStory(t *testing.T) {
+	mock := bundleImportMock()
+	mock.StubQuery("INSERT INTO grammar_points", [][]interface{}{{
+		int32(40), int32(12), "Construct state", pgtype.Text{}, pgtype.Timestamp{},
+	}}, nil)
+	mock.StubQuery("-- name: GetStory :one", [][]interface{}{{
+		int32(12), int32(2), "a", pgtype.Text{}, pgtype.Timestamp{}, "", "", pgtype.Int4{Int32: 3, Valid: true}, pgtype.Timestamp{},
+	}}, nil) // Read back for the first revision
+	store := &memStorage{objects: map[string]int64{}}
+	svc := NewService(mock, store, nil, nil)
+
+	bundle := writeTestBundle(t, StoryBundleManifest{Format: StoryBundleFormat, Version: StoryBundleVersion}, bundleStory(),
+		map[string]string{"files/audio-files/stories/9/line_1_complete_1_king.mp3": "mp3-bytes"})
+	storyID, err := svc.ImportStoryBundle(context.Background(), 3, "admin-1", bundle)
+	if err != nil {
+		t.Fatalf("ImportStoryBundle failed: %v", err)
+	}
+	if storyID != 12 {
+		t.Errorf("Expected story 12, got %d", storyID)
+	}
+
+	data, err := store.Download("audio-files", "stories/12/line_1_complete_1_king.mp3")
+	if err != nil || string(data) != "mp3-bytes" {
+		t.Errorf("Expected the audio file under the new story, got %q, %v", data, err)
+	}
+	for key := range store.objects {
+		if strings.Contains(key, "stories/9/") {
+			t.Errorf("Expected nothing stored under the original story, found %s", key)
+		}
+	}
+}
+
+func TestImportStoryBundleRemovesFilesWhenSavingFails(t *testing.T) {
+	mock := bundleImportMock()
+	mock.StubExec("INSERT INTO stories", errors.New("connection lost"))
+	store := &memStorage{objects: map[string]int64{}}
+	svc := NewService(mock, store, nil, nil)
+
+	bundle := writeTestBundle(t, StoryBundleManifest{Format: StoryBundleFormat, Version: StoryBundleVersion}, bundleStory(),
+		map[string]string{"files/audio-files/stories/9/line_1_complete_1_king.mp3": "mp3-bytes"})
+	if _, err := svc.ImportStoryBundle(context.Background(), 3, "admin-1", bundle); err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Fatalf("Expected saving the story to fail, got %v", err)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("Expected the uploaded files to be removed, found %v", store.objects)
+	}
+}
+
+func TestValidateStoryBundleActivities(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*StoryBundleManifest)
+	}{
+		{"recall sentence links a target word outside the bundle", func(m *StoryBundleManifest) {
+			m.RecallSentences = []RecallSentence{{Text: "The king", TargetVocabID: 3}}
+		}},
+		{"produce segment past the last line", func(m *StoryBundleManifest) {
+			m.ProduceSegments = []ProduceSegment{{StartLine: 1, EndLine: 2, EnglishPrompt: "Retell", ReferenceText: "מֶלֶךְ"}}
+		}},
+		{"produce segment links an unknown grammar point", func(m *StoryBundleManifest) {
+			m.ProduceSegments = []ProduceSegment{{StartLine: 1, EndLine: 1, EnglishPrompt: "Retell", ReferenceText: "מֶלֶךְ", GrammarPointID: 5}}
+		}},
+		{"repeated target word", func(m *StoryBundleManifest) {
+			m.TargetVocab = []TargetVocab{{ID: 1, LexicalForm: "מֶלֶךְ"}, {ID: 2, LexicalForm: "מֶלֶךְ"}}
+		}},
+		{"unknown phase", func(m *StoryBundleManifest) { m.PhaseFlow = []string{"dance", PhaseScore} }},
+		{"description without a language", func(m *StoryBundleManifest) { m.Descriptions = map[string]string{"": "A king"} }},
+	}
+	audio := "files/audio-files/stories/9/line_1_complete_1_king.mp3"
+	for _, test := range tests {
+		manifest := StoryBundleManifest{Format: StoryBundleFormat, Version: StoryBundleVersion}
+		test.modify(&manifest)
+		data := writeTestBundle(t, manifest, bundleStory(), map[string]string{audio: "mp3"})
+		zr, _ := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		files := make(map[string]*zip.File)
+		for _, f := range zr.File {
+			files[f.Name] = f
+		}
+		if err := validateStoryBundle(bundleStory(), &manifest, files); !errors.Is(err, ErrInvalidStoryBundle) {
+			t.Errorf("%s: expected ErrInvalidStoryBundle, got %v", test.name, err)
+		}
+	}
+}
+
+// bundleImportMock reserves story 12 for an import by admin-1
+func bundleImportMock() *database.MockDBTX {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: ReserveStoryID", [][]interface{}{{int32(12)}}, nil)
+	mock.StubQuery("-- name: GetUser :one", [][]interface{}{{
+		"admin-1", "admin@example.com", "Ada Admin", pgtype.Bool{}, pgtype.Timestamp{}, pgtype.Timestamp{},
+	}}, nil)
+	return mock
+}
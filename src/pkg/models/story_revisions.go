@@ -0,0 +1,573 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrRollbackOrphansAnswers = errors.New("rollback would delete vocabulary items students have answered")
+
+// Line diff operations. An edited line shows as removed and added.
+const (
+	LineUnchanged = "equal"
+	LineAdded     = "added"
+	LineRemoved   = "removed"
+)
+
+// StoryRevision is a numbered snapshot of a story taken after an admin write.
+// Story and Translations are only filled in when a single revision is requested;
+// Translations is nil for revisions recorded before translations were kept.
+type StoryRevision struct {
+	Number       int               `json:"number"`
+	StoryID      int               `json:"storyId"`
+	Action       string            `json:"action"`
+	CreatedBy    string            `json:"createdBy,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	Story        *Story            `json:"story,omitempty"`
+	Translations []LineTranslation `json:"translations,omitempty"`
+}
+
+// revisionSnapshot is the JSON stored with a revision: the story with its line translations
+type revisionSnapshot struct {
+	*Story
+	Translations []LineTranslation `json:"translations"`
+}
+
+// LineDiff is one line of a revision diff. OldLine and NewLine are the line numbers
+// in each revision, 0 on the side the line is missing from.
+type LineDiff struct {
+	Op                 string `json:"op"`
+	OldLine            int    `json:"oldLine,omitempty"`
+	NewLine            int    `json:"newLine,omitempty"`
+	Text               string `json:"text"`
+	AnnotationsChanged bool   `json:"annotationsChanged,omitempty"`
+}
+
+// StoryRevisionDiff compares two revisions of a story. MetadataChanges names the
+// metadata fields that differ, e.g. "title" or "grammarPoints".
+type StoryRevisionDiff struct {
+	From            int        `json:"from"`
+	To              int        `json:"to"`
+	MetadataChanges []string   `json:"metadataChanges"`
+	Lines           []LineDiff `json:"lines"`
+}
+
+type editorKey struct{}
+
+// WithEditor records the admin making changes, so revisions written with ctx name them
+func WithEditor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, editorKey{}, userID)
+}
+
+// recordStoryRevision stores the story as it is now. It is called at the end of
+// every write transaction, so ctx should be the transaction context; the story row
+// stays locked until it commits, so concurrent writes cannot take the same number.
+func (s *Service) recordStoryRevision(ctx context.Context, storyID int, action string) error {
+	if err := s.queries.LockStoryForRevision(ctx, int32(storyID)); err != nil {
+		return err
+	}
+	story, err := s.loadStory(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	stories := []Story{*story}
+	if err := s.attachStorySchedules(ctx, stories); err != nil {
+		return err
+	}
+	story = &stories[0]
+	translations, err := s.GetAllTranslationsForStory(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(revisionSnapshot{Story: story, Translations: translations})
+	if err != nil {
+		return err
+	}
+	editor, _ := ctx.Value(editorKey{}).(string)
+	return s.queries.CreateStoryRevision(ctx, db.CreateStoryRevisionParams{
+		StoryID:   int32(storyID),
+		Action:    action,
+		Snapshot:  snapshot,
+		CreatedBy: pgtype.Text{String: editor, Valid: editor != ""},
+	})
+}
+
+// withStoryRevision runs fn in a transaction and records a revision of the story at its end
+func (s *Service) withStoryRevision(ctx context.Context, storyID int, action string, fn func(ctx context.Context) error) error {
+	return s.withTransaction(ctx, func(txCtx context.Context) error {
+		if err := fn(txCtx); err != nil {
+			return err
+		}
+		return s.recordStoryRevision(txCtx, storyID, action)
+	})
+}
+
+// GetStoryRevisions lists the revisions of a story, newest first, without their snapshots
+func (s *Service) GetStoryRevisions(ctx context.Context, storyID int) ([]StoryRevision, error) {
+	rows, err := s.queries.ListStoryRevisions(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]StoryRevision, 0, len(rows))
+	for _, row := range rows {
+		revisions = append(revisions, StoryRevision{
+			Number:    int(row.RevisionNumber),
+			StoryID:   int(row.StoryID),
+			Action:    row.Action,
+			CreatedBy: row.CreatedBy.String,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	return revisions, nil
+}
+
+// GetStoryRevision returns one revision with its story snapshot
+func (s *Service) GetStoryRevision(ctx context.Context, storyID, number int) (*StoryRevision, error) {
+	row, err := s.queries.GetStoryRevision(ctx, db.GetStoryRevisionParams{
+		StoryID:        int32(storyID),
+		RevisionNumber: int32(number),
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := revisionSnapshot{Story: NewStory()}
+	if err := json.Unmarshal(row.Snapshot, &snapshot); err != nil {
+		return nil, fmt.Errorf("revision %d of story %d: %w", number, storyID, err)
+	}
+	return &StoryRevision{
+		Number:       int(row.RevisionNumber),
+		StoryID:      int(row.StoryID),
+		Action:       row.Action,
+		CreatedBy:    row.CreatedBy.String,
+		CreatedAt:    row.CreatedAt.Time,
+		Story:        snapshot.Story,
+		Translations: snapshot.Translations,
+	}, nil
+}
+
+// DiffStoryRevisions compares revision from with revision to line by line
+func (s *Service) DiffStoryRevisions(ctx context.Context, storyID, from, to int) (*StoryRevisionDiff, error) {
+	older, err := s.GetStoryRevision(ctx, storyID, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.GetStoryRevision(ctx, storyID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffStories(older.Story, newer.Story)
+	if older.Translations != nil && newer.Translations != nil && !slices.Equal(older.Translations, newer.Translations) {
+		diff.MetadataChanges = append(diff.MetadataChanges, "translations")
+	}
+	diff.From, diff.To = from, to
+	return diff, nil
+}
+
+// diffStories lists the metadata fields that differ and diffs the lines by their text
+func diffStories(older, newer *Story) *StoryRevisionDiff {
+	a, b := older.Metadata, newer.Metadata
+	changes := []string{}
+	if !maps.Equal(a.Title, b.Title) {
+		changes = append(changes, "title")
+	}
+	if a.Description.Text != b.Description.Text || a.Language != b.Language {
+		changes = append(changes, "description")
+	}
+	if a.WeekNumber != b.WeekNumber || a.DayLetter != b.DayLetter {
+		changes = append(changes, "schedule")
+	}
+	if a.VideoURL != b.VideoURL {
+		changes = append(changes, "videoUrl")
+	}
+	if a.Author != b.Author {
+		changes = append(changes, "author")
+	}
+	if !slices.EqualFunc(a.GrammarPoints, b.GrammarPoints, func(x, y GrammarPoint) bool {
+		return x.Name == y.Name && x.Description == y.Description
+	}) {
+		changes = append(changes, "grammarPoints")
+	}
+
+	return &StoryRevisionDiff{
+		MetadataChanges: changes,
+		Lines:           diffLines(older.Content.Lines, newer.Content.Lines),
+	}
+}
+
+// diffLines matches lines by text along their longest common subsequence, so
+// inserting a line does not mark every later line as changed
+func diffLines(older, newer []StoryLine) []LineDiff {
+	// common[i][j] is the length of the longest common subsequence of older[i:] and newer[j:]
+	common := make([][]int, len(older)+1)
+	for i := range common {
+		common[i] = make([]int, len(newer)+1)
+	}
+	for i := len(older) - 1; i >= 0; i-- {
+		for j := len(newer) - 1; j >= 0; j-- {
+			if older[i].Text == newer[j].Text {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	diff := []LineDiff{}
+	i, j := 0, 0
+	for i < len(older) || j < len(newer) {
+		switch {
+		case i < len(older) && j < len(newer) && older[i].Text == newer[j].Text:
+			diff = append(diff, LineDiff{
+				Op:                 LineUnchanged,
+				OldLine:            older[i].LineNumber,
+				NewLine:            newer[j].LineNumber,
+				Text:               newer[j].Text,
+				AnnotationsChanged: !sameAnnotations(older[i], newer[j]),
+			})
+			i++
+			j++
+		case i < len(older) && (j == len(newer) || common[i+1][j] >= common[i][j+1]):
+			diff = append(diff, LineDiff{Op: LineRemoved, OldLine: older[i].LineNumber, Text: older[i].Text})
+			i++
+		default:
+			diff = append(diff, LineDiff{Op: LineAdded, NewLine: newer[j].LineNumber, Text: newer[j].Text})
+			j++
+		}
+	}
+	return diff
+}
+
+// sameAnnotations compares the vocabulary, grammar and footnotes of two lines,
+// ignoring footnote IDs, which change whenever footnotes are recreated
+func sameAnnotations(a, b StoryLine) bool {
+	sameGrammar := func(x, y GrammarItem) bool {
+		samePoint := (x.GrammarPointID == nil) == (y.GrammarPointID == nil) &&
+			(x.GrammarPointID == nil || *x.GrammarPointID == *y.GrammarPointID)
+		return samePoint && x.Text == y.Text && x.Position == y.Position
+	}
+	sameFootnote := func(x, y Footnote) bool {
+		return x.Text == y.Text && slices.Equal(x.References, y.References)
+	}
+	return slices.Equal(a.Vocabulary, b.Vocabulary) &&
+		slices.EqualFunc(a.Grammar, b.Grammar, sameGrammar) &&
+		slices.EqualFunc(a.Footnotes, b.Footnotes, sameFootnote)
+}
+
+// RollbackStory restores the metadata, lines, annotations and translations of a revision
+// and records the result as a new revision. Vocabulary items that survive keep their IDs, so student
+// answers to them are kept; ErrRollbackOrphansAnswers is returned instead of deleting
+// items that have answers. Grammar points added since the revision are kept because
+// answers refer to them, and audio files are not restored, only removed with their lines.
+func (s *Service) RollbackStory(ctx context.Context, storyID, number int) error {
+	revision, err := s.GetStoryRevision(ctx, storyID, number)
+	if err != nil {
+		return err
+	}
+	target := revision.Story
+
+	var removedAudio []AudioFile
+	err = s.withTransaction(ctx, func(txCtx context.Context) error {
+		current, err := s.loadStory(txCtx, storyID)
+		if err != nil {
+			return err
+		}
+		items, err := s.queries.GetStoryVocabularyItems(txCtx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+		if err != nil {
+			return err
+		}
+
+		removed, added := diffVocabulary(items, target.Content.Lines)
+		if len(removed) > 0 {
+			answers, err := s.queries.CountVocabItemAnswers(txCtx, removed)
+			if err != nil {
+				return err
+			}
+			if answers > 0 {
+				return ErrRollbackOrphansAnswers
+			}
+		}
+
+		if err := s.restoreStoryMetadata(txCtx, storyID, current.Metadata, target.Metadata); err != nil {
+			return err
+		}
+		pointIDs, err := s.restoreGrammarPoints(txCtx, storyID, current.Metadata.GrammarPoints, target.Metadata.GrammarPoints)
+		if err != nil {
+			return err
+		}
+		removedAudio, err = s.restoreStoryLines(txCtx, storyID, current.Content.Lines, target.Content.Lines)
+		if err != nil {
+			return err
+		}
+		if err := s.restoreAnnotations(txCtx, storyID, target.Content.Lines, removed, added, pointIDs); err != nil {
+			return err
+		}
+		if revision.Translations != nil {
+			if err := s.restoreTranslations(txCtx, storyID, revision.Translations); err != nil {
+				return err
+			}
+		}
+
+		return s.recordStoryRevision(txCtx, storyID, fmt.Sprintf("rollback to revision %d", number))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.InvalidateStoryMetadata(storyID)
+	// The records are gone, so a file that cannot be removed is only logged
+	if err := s.deleteAudioFilesFromStorage(ctx, removedAudio); err != nil {
+		fmt.Printf("Failed to remove audio of lines removed by rollback of story %d: %v\n", storyID, err)
+	}
+	return nil
+}
+
+// diffVocabulary compares the stored vocabulary items with the target lines. It returns
+// the IDs of items the target does not have and, by line, the target items not stored yet.
+// Items match on line, position, word and lexical form.
+func diffVocabulary(items []db.VocabularyItem, lines []StoryLine) ([]int32, map[int][]VocabularyItem) {
+	type key struct {
+		line int
+		item VocabularyItem
+	}
+	wanted := make(map[key]int)
+	for _, line := range lines {
+		for _, v := range line.Vocabulary {
+			wanted[key{line.LineNumber, v}]++
+		}
+	}
+
+	removed := []int32{}
+	for _, item := range items {
+		k := key{int(item.LineNumber.Int32), VocabularyItem{
+			Word:        item.Word,
+			LexicalForm: item.LexicalForm,
+			Position:    [2]int{int(item.PositionStart), int(item.PositionEnd)},
+		}}
+		if wanted[k] > 0 {
+			wanted[k]--
+		} else {
+			removed = append(removed, item.ID)
+		}
+	}
+
+	added := make(map[int][]VocabularyItem)
+	for _, line := range lines {
+		for _, v := range line.Vocabulary {
+			k := key{line.LineNumber, v}
+			if wanted[k] > 0 {
+				wanted[k]--
+				added[line.LineNumber] = append(added[line.LineNumber], v)
+			}
+		}
+	}
+	return removed, added
+}
+
+// restoreStoryMetadata puts back the story fields, titles and description of a revision.
+// The story stays in its current course.
+func (s *Service) restoreStoryMetadata(ctx context.Context, storyID int, current, target StoryMetadata) error {
+	courseID := pgtype.Int4{Valid: false}
+	if current.CourseID != nil {
+		courseID = pgtype.Int4{Int32: int32(*current.CourseID), Valid: true}
+	}
+	err := s.queries.UpdateStory(ctx, db.UpdateStoryParams{
+		StoryID:    int32(storyID),
+		WeekNumber: int32(target.WeekNumber),
+		DayLetter:  target.DayLetter,
+		VideoUrl:   pgtype.Text{String: target.VideoURL, Valid: target.VideoURL != ""},
+		AuthorID:   target.Author.ID,
+		AuthorName: target.Author.Name,
+		CourseID:   courseID,
+	})
+	if err != nil {
+		return err
+	}
+	return s.replaceTitlesAndDescription(ctx, storyID, target)
+}
+
+// restoreGrammarPoints makes sure every grammar point of the target exists and returns
+// the current ID of each, keyed by its ID in the target. Points are matched by ID and
+// then by name; missing ones are recreated.
+func (s *Service) restoreGrammarPoints(ctx context.Context, storyID int, current, target []GrammarPoint) (map[int]int, error) {
+	byID := make(map[int]GrammarPoint, len(current))
+	byName := make(map[string]int, len(current))
+	for _, gp := range current {
+		byID[gp.ID] = gp
+		byName[gp.Name] = gp.ID
+	}
+
+	ids := make(map[int]int, len(target))
+	for _, gp := range target {
+		if existing, ok := byID[gp.ID]; ok {
+			if existing.Name != gp.Name || existing.Description != gp.Description {
+				if _, err := s.updateGrammarPoint(ctx, gp.ID, gp.Name, gp.Description); err != nil {
+					return nil, err
+				}
+			}
+			ids[gp.ID] = gp.ID
+			continue
+		}
+		if id, ok := byName[gp.Name]; ok {
+			ids[gp.ID] = id
+			continue
+		}
+		created, err := s.createGrammarPoint(ctx, storyID, gp.Name, gp.Description)
+		if err != nil {
+			return nil, err
+		}
+		ids[gp.ID] = created.ID
+	}
+	return ids, nil
+}
+
+// restoreStoryLines deletes the lines the target does not have, then updates or inserts
+// the rest. It returns the audio files of the deleted lines.
+func (s *Service) restoreStoryLines(ctx context.Context, storyID int, current, target []StoryLine) ([]AudioFile, error) {
+	targetText := make(map[int]string, len(target))
+	for _, line := range target {
+		targetText[line.LineNumber] = line.Text
+	}
+
+	var removedAudio []AudioFile
+	currentText := make(map[int]string, len(current))
+	for _, line := range current {
+		if _, ok := targetText[line.LineNumber]; ok {
+			currentText[line.LineNumber] = line.Text
+			continue
+		}
+		if err := s.queries.DeleteStoryLine(ctx, db.DeleteStoryLineParams{
+			StoryID:    int32(storyID),
+			LineNumber: int32(line.LineNumber),
+		}); err != nil {
+			return nil, err
+		}
+		for _, audioFile := range line.AudioFiles {
+			audioFile.StoryID = storyID
+			removedAudio = append(removedAudio, audioFile)
+		}
+	}
+
+	for _, line := range target {
+		text, exists := currentText[line.LineNumber]
+		var err error
+		switch {
+		case !exists:
+			err = s.queries.UpsertStoryLine(ctx, db.UpsertStoryLineParams{
+				StoryID:    int32(storyID),
+				LineNumber: int32(line.LineNumber),
+				Text:       line.Text,
+			})
+		case text != line.Text:
+			err = s.queries.UpdateStoryLineText(ctx, db.UpdateStoryLineTextParams{
+				StoryID:    int32(storyID),
+				LineNumber: int32(line.LineNumber),
+				Text:       line.Text,
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return removedAudio, nil
+}
+
+// restoreAnnotations applies the vocabulary changes found by diffVocabulary and
+// recreates grammar items and footnotes, which no answers refer to
+func (s *Service) restoreAnnotations(ctx context.Context, storyID int, lines []StoryLine, removed []int32, added map[int][]VocabularyItem, pointIDs map[int]int) error {
+	story := pgtype.Int4{Int32: int32(storyID), Valid: true}
+	for _, id := range removed {
+		if err := s.queries.DeleteVocabularyItem(ctx, id); err != nil {
+			return err
+		}
+	}
+	if err := s.queries.DeleteAllGrammarForStory(ctx, story); err != nil {
+		return err
+	}
+	if err := s.queries.DeleteAllStoryAnnotations(ctx, story); err != nil {
+		return err
+	}
+
+	var vocab []db.BulkCreateVocabularyItemsParams
+	var grammar []db.BulkCreateGrammarItemsParams
+	for _, line := range lines {
+		lineNumber := pgtype.Int4{Int32: int32(line.LineNumber), Valid: true}
+		for _, v := range added[line.LineNumber] {
+			vocab = append(vocab, db.BulkCreateVocabularyItemsParams{
+				StoryID:       story,
+				LineNumber:    lineNumber,
+				Word:          v.Word,
+				LexicalForm:   v.LexicalForm,
+				PositionStart: int32(v.Position[0]),
+				PositionEnd:   int32(v.Position[1]),
+			})
+		}
+		for _, g := range line.Grammar {
+			grammarPointID := pgtype.Int4{Valid: false}
+			if g.GrammarPointID != nil {
+				if id, ok := pointIDs[*g.GrammarPointID]; ok {
+					grammarPointID = pgtype.Int4{Int32: int32(id), Valid: true}
+				}
+			}
+			grammar = append(grammar, db.BulkCreateGrammarItemsParams{
+				StoryID:        story,
+				LineNumber:     lineNumber,
+				GrammarPointID: grammarPointID,
+				Text:           g.Text,
+				PositionStart:  int32(g.Position[0]),
+				PositionEnd:    int32(g.Position[1]),
+			})
+		}
+		for _, f := range line.Footnotes {
+			if err := s.insertFootnote(ctx, storyID, line.LineNumber, f); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(vocab) > 0 {
+		if _, err := s.queries.BulkCreateVocabularyItems(ctx, vocab); err != nil {
+			return err
+		}
+	}
+	if len(grammar) > 0 {
+		if _, err := s.queries.BulkCreateGrammarItems(ctx, grammar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreTranslations replaces the story's line translations with those of a revision
+func (s *Service) restoreTranslations(ctx context.Context, storyID int, translations []LineTranslation) error {
+	if err := s.queries.DeleteAllTranslationsForStory(ctx, int32(storyID)); err != nil {
+		return err
+	}
+	for _, translation := range translations {
+		err := s.queries.UpsertLineTranslation(ctx, db.UpsertLineTranslationParams{
+			StoryID:         int32(storyID),
+			LineNumber:      translation.LineNumber,
+			LanguageCode:    translation.LanguageCode,
+			TranslationText: translation.TranslationText,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
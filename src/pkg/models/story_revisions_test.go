@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/database"
+	"glossias/src/pkg/generated/db"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDiffLines(t *testing.T) {
+	older := []StoryLine{
+		{LineNumber: 1, Text: "בְּרֵאשִׁית"},
+		{LineNumber: 2, Text: "בָּרָא"},
+		{LineNumber: 3, Text: "אֱלֹהִים"},
+	}
+	newer := []StoryLine{
+		{LineNumber: 1, Text: "בְּרֵאשִׁית"},
+		{LineNumber: 2, Text: "inserted"},
+		{LineNumber: 3, Text: "בָּרָא", Vocabulary: []VocabularyItem{{Word: "בָּרָא", LexicalForm: "ברא", Position: [2]int{0, 5}}}},
+		{LineNumber: 4, Text: "אֱלֹהִים!"},
+	}
+
+	expected := []LineDiff{
+		{Op: LineUnchanged, OldLine: 1, NewLine: 1, Text: "בְּרֵאשִׁית"},
+		{Op: LineAdded, NewLine: 2, Text: "inserted"},
+		{Op: LineUnchanged, OldLine: 2, NewLine: 3, Text: "בָּרָא", AnnotationsChanged: true},
+		{Op: LineRemoved, OldLine: 3, Text: "אֱלֹהִים"},
+		{Op: LineAdded, NewLine: 4, Text: "אֱלֹהִים!"},
+	}
+	diff := diffLines(older, newer)
+	if len(diff) != len(expected) {
+		t.Fatalf("Expected %d diff lines, got %d: %+v", len(expected), len(diff), diff)
+	}
+	for i := range expected {
+		if diff[i] != expected[i] {
+			t.Errorf("Line %d: expected %+v, got %+v", i, expected[i], diff[i])
+		}
+	}
+}
+
+func TestDiffVocabulary(t *testing.T) {
+	item := func(id, line int32, word string, start, end int32) db.VocabularyItem {
+		return db.VocabularyItem{
+			ID: id, LineNumber: pgtype.Int4{Int32: line, Valid: true},
+			Word: word, LexicalForm: word, PositionStart: start, PositionEnd: end,
+		}
+	}
+	stored := []db.VocabularyItem{
+		item(10, 1, "מֶלֶךְ", 0, 6),
+		item(11, 1, "עִיר", 9, 13),  // Not in the revision
+		item(12, 2, "בַּיִת", 0, 6), // Line 2 is not in the revision
+	}
+	lines := []StoryLine{{
+		LineNumber: 1,
+		Vocabulary: []VocabularyItem{
+			{Word: "מֶלֶךְ", LexicalForm: "מֶלֶךְ", Position: [2]int{0, 6}},
+			{Word: "הָעִיר", LexicalForm: "עִיר", Position: [2]int{7, 13}},
+		},
+	}}
+
+	removed, added := diffVocabulary(stored, lines)
+	if len(removed) != 2 || removed[0] != 11 || removed[1] != 12 {
+		t.Errorf("Expected items 11 and 12 to be removed, got %v", removed)
+	}
+	if len(added[1]) != 1 || added[1][0].Word != "הָעִיר" {
+		t.Errorf("Expected only הָעִיר to be added on line 1, got %+v", added)
+	}
+}
+
+func TestRollbackStoryRefusesToOrphanAnswers(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("AND revision_number = $2", [][]interface{}{{
+		int32(1), int32(7), int32(1), "create", []byte(`{"metadata":{"storyId":7},"content":{"lines":[]}}`), pgtype.Text{}, pgtype.Timestamp{},
+	}}, nil)
+	mock.StubQuery("-- name: GetStory :one", [][]interface{}{{
+		int32(7), int32(1), "a", pgtype.Text{}, pgtype.Timestamp{}, "", "", pgtype.Int4{}, pgtype.Timestamp{},
+	}}, nil)
+	mock.StubQuery("-- name: GetStoryVocabularyItems", [][]interface{}{{
+		int32(10), pgtype.Int4{Int32: 7, Valid: true}, pgtype.Int4{Int32: 1, Valid: true}, "מֶלֶךְ", "מֶלֶךְ", int32(0), int32(6),
+	}}, nil)
+	mock.StubQuery("AS answers", [][]interface{}{{int64(3)}}, nil)
+	svc := NewService(mock, nil, nil, nil)
+
+	if err := svc.RollbackStory(context.Background(), 7, 1); err != ErrRollbackOrphansAnswers {
+		t.Errorf("Expected ErrRollbackOrphansAnswers, got %v", err)
+	}
+
+	svc = NewService(database.NewMockDBTX(), nil, nil, nil)
+	if err := svc.RollbackStory(context.Background(), 7, 2); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a missing revision, got %v", err)
+	}
+}
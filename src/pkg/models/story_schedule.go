@@ -0,0 +1,217 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalidStorySchedule = errors.New("a story must be available before it is due and due before it closes")
+	ErrStoryClosed          = errors.New("this story no longer accepts answers")
+)
+
+// StorySchedule is the release window of a story in its course. A nil time leaves that
+// end of the window open, so the zero StorySchedule is always open and never late.
+type StorySchedule struct {
+	AvailableFrom *time.Time `json:"availableFrom,omitempty"` // Hidden from students before
+	DueAt         *time.Time `json:"dueAt,omitempty"`         // Scores reached after are late
+	LateUntil     *time.Time `json:"lateUntil,omitempty"`     // No answers accepted after
+}
+
+// Available reports whether students can see the story at now
+func (sc StorySchedule) Available(now time.Time) bool {
+	return sc.AvailableFrom == nil || !now.Before(*sc.AvailableFrom)
+}
+
+// PastDue reports whether work done at now is late
+func (sc StorySchedule) PastDue(now time.Time) bool {
+	return sc.DueAt != nil && now.After(*sc.DueAt)
+}
+
+// Closed reports whether the story stopped accepting answers at now
+func (sc StorySchedule) Closed(now time.Time) bool {
+	return sc.LateUntil != nil && now.After(*sc.LateUntil)
+}
+
+// valid reports whether the set times are in order
+func (sc StorySchedule) valid() bool {
+	times := []*time.Time{sc.AvailableFrom, sc.DueAt, sc.LateUntil}
+	var last *time.Time
+	for _, t := range times {
+		if t == nil {
+			continue
+		}
+		if last != nil && t.Before(*last) {
+			return false
+		}
+		last = t
+	}
+	return true
+}
+
+// GetStorySchedule returns the schedule of a story, or an open one if it has none.
+// Schedules are not cached so edits and course shifts apply immediately.
+func (s *Service) GetStorySchedule(ctx context.Context, storyID int) (*StorySchedule, error) {
+	result, err := s.queries.GetStorySchedule(ctx, int32(storyID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return &StorySchedule{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return storyScheduleFromDB(result), nil
+}
+
+// SetStorySchedule replaces the schedule of a story
+func (s *Service) SetStorySchedule(ctx context.Context, storyID int, schedule StorySchedule) (*StorySchedule, error) {
+	if !schedule.valid() {
+		return nil, ErrInvalidStorySchedule
+	}
+
+	var result db.StorySchedule
+	err := s.withStoryRevision(ctx, storyID, "set schedule", func(txCtx context.Context) error {
+		var err error
+		result, err = s.queries.UpsertStorySchedule(txCtx, db.UpsertStoryScheduleParams{
+			StoryID:       int32(storyID),
+			AvailableFrom: scheduleTimestamp(schedule.AvailableFrom),
+			DueAt:         scheduleTimestamp(schedule.DueAt),
+			LateUntil:     scheduleTimestamp(schedule.LateUntil),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storyScheduleFromDB(result), nil
+}
+
+// ShiftCourseSchedule moves every scheduled time of a course's stories by days, which
+// may be negative, and returns how many story schedules moved. Trashed stories keep
+// their schedules. The term start of the course's at-risk settings moves with them,
+// and each moved story gets a revision.
+func (s *Service) ShiftCourseSchedule(ctx context.Context, courseID int32, days int) (int, error) {
+	var shifted []int32
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		shifted, err = s.queries.ShiftCourseSchedule(txCtx, db.ShiftCourseScheduleParams{
+			Days:     int32(days),
+			CourseID: pgtype.Int4{Int32: courseID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.queries.ShiftCourseRiskStart(txCtx, db.ShiftCourseRiskStartParams{
+			Days:     int32(days),
+			CourseID: courseID,
+		}); err != nil {
+			return err
+		}
+		for _, storyID := range shifted {
+			if err := s.recordStoryRevision(txCtx, int(storyID), fmt.Sprintf("shift schedule by %d days", days)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(shifted), nil
+}
+
+// CheckStoryOpen returns ErrStoryClosed if a story's schedule no longer accepts answers
+// from the user. Admins who can edit the story may always answer.
+func (s *Service) CheckStoryOpen(ctx context.Context, userID string, storyID int) error {
+	schedule, err := s.GetStorySchedule(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	if schedule.Closed(s.clock.Now()) && !s.CanUserEditStory(ctx, userID, int32(storyID)) {
+		return ErrStoryClosed
+	}
+	return nil
+}
+
+// attachStorySchedules sets the schedule of each story
+func (s *Service) attachStorySchedules(ctx context.Context, stories []Story) error {
+	if len(stories) == 0 {
+		return nil
+	}
+	storyIDs := make([]int32, len(stories))
+	for i, story := range stories {
+		storyIDs[i] = int32(story.Metadata.StoryID)
+	}
+	results, err := s.queries.GetStorySchedules(ctx, storyIDs)
+	if err != nil {
+		return err
+	}
+
+	schedules := make(map[int]*StorySchedule, len(results))
+	for _, result := range results {
+		schedules[int(result.StoryID)] = storyScheduleFromDB(result)
+	}
+	for i := range stories {
+		stories[i].Metadata.Schedule = schedules[stories[i].Metadata.StoryID]
+	}
+	return nil
+}
+
+// availableStories drops the stories that are not available to the user yet. Stories
+// must have their schedules attached. Course and super admins see every story of their courses.
+func (s *Service) availableStories(ctx context.Context, userID string, stories []Story) []Story {
+	now := s.clock.Now()
+	admin := make(map[int]bool)
+	available := make([]Story, 0, len(stories))
+	for _, story := range stories {
+		schedule := story.Metadata.Schedule
+		if schedule != nil && !schedule.Available(now) {
+			courseID := 0 // Stories without a course are only for super admins
+			if story.Metadata.CourseID != nil {
+				courseID = *story.Metadata.CourseID
+			}
+			isAdmin, ok := admin[courseID]
+			if !ok {
+				if courseID == 0 {
+					isAdmin = s.IsUserSuperAdmin(ctx, userID)
+				} else {
+					isAdmin = s.IsUserCourseOrSuperAdmin(ctx, userID, int32(courseID))
+				}
+				admin[courseID] = isAdmin
+			}
+			if !isAdmin {
+				continue
+			}
+		}
+		available = append(available, story)
+	}
+	return available
+}
+
+func scheduleTimestamp(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}
+}
+
+func storyScheduleFromDB(result db.StorySchedule) *StorySchedule {
+	schedule := &StorySchedule{}
+	if result.AvailableFrom.Valid {
+		schedule.AvailableFrom = &result.AvailableFrom.Time
+	}
+	if result.DueAt.Valid {
+		schedule.DueAt = &result.DueAt.Time
+	}
+	if result.LateUntil.Valid {
+		schedule.LateUntil = &result.LateUntil.Time
+	}
+	return schedule
+}
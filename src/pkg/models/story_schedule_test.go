@@ -0,0 +1,136 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"glossias/src/pkg/database"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestStoryScheduleWindow(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2026, 9, d, 12, 0, 0, 0, time.UTC)
+		return &at
+	}
+	schedule := StorySchedule{AvailableFrom: day(1), DueAt: day(8), LateUntil: day(15)}
+
+	tests := []struct {
+		now                        *time.Time
+		available, pastDue, closed bool
+	}{
+		{day(0), false, false, false},
+		{day(1), true, false, false},
+		{day(8), true, false, false}, // Due at, not after
+		{day(9), true, true, false},
+		{day(16), true, true, true},
+	}
+	for _, test := range tests {
+		if got := schedule.Available(*test.now); got != test.available {
+			t.Errorf("Available(%v) = %v, expected %v", test.now, got, test.available)
+		}
+		if got := schedule.PastDue(*test.now); got != test.pastDue {
+			t.Errorf("PastDue(%v) = %v, expected %v", test.now, got, test.pastDue)
+		}
+		if got := schedule.Closed(*test.now); got != test.closed {
+			t.Errorf("Closed(%v) = %v, expected %v", test.now, got, test.closed)
+		}
+	}
+
+	var open StorySchedule
+	if !open.Available(*day(1)) || open.PastDue(*day(30)) || open.Closed(*day(30)) {
+		t.Error("Expected a schedule without times to be always open and never late")
+	}
+}
+
+func TestSetStoryScheduleValidation(t *testing.T) {
+	svc := NewService(database.NewMockDBTX(), nil, nil, nil)
+	early := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	late := early.AddDate(0, 0, 7)
+	invalid := []StorySchedule{
+		{AvailableFrom: &late, DueAt: &early},
+		{DueAt: &late, LateUntil: &early},
+		{AvailableFrom: &late, LateUntil: &early}, // Checked across the unset due date
+	}
+	for _, schedule := range invalid {
+		if _, err := svc.SetStorySchedule(context.Background(), 1, schedule); err != ErrInvalidStorySchedule {
+			t.Errorf("Expected ErrInvalidStorySchedule for %+v, got %v", schedule, err)
+		}
+	}
+}
+
+func TestCheckStoryOpen(t *testing.T) {
+	closesAt := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: closesAt.Add(-time.Hour)}
+	mock := database.NewMockDBTX()
+	mock.StubQuery("FROM story_schedules", [][]interface{}{{
+		int32(7), pgtype.Timestamp{}, pgtype.Timestamp{}, pgtype.Timestamp{Time: closesAt, Valid: true}, pgtype.Timestamp{},
+	}}, nil)
+	svc := NewService(mock, nil, nil, clock)
+
+	if err := svc.CheckStoryOpen(context.Background(), "student", 7); err != nil {
+		t.Errorf("Expected the story to be open before it closes, got %v", err)
+	}
+	clock.now = closesAt.Add(time.Hour)
+	if err := svc.CheckStoryOpen(context.Background(), "student", 7); err != ErrStoryClosed {
+		t.Errorf("Expected ErrStoryClosed after the story closes, got %v", err)
+	}
+
+	// Stories without a schedule never close
+	unscheduled := NewService(database.NewMockDBTX(), nil, nil, clock)
+	if err := unscheduled.CheckStoryOpen(context.Background(), "student", 7); err != nil {
+		t.Errorf("Expected an unscheduled story to be open, got %v", err)
+	}
+}
+
+func TestAvailableStories(t *testing.T) {
+	now := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	past, future := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+	courseID := 3
+	story := func(id int, schedule *StorySchedule) Story {
+		var s Story
+		s.Metadata.StoryID = id
+		s.Metadata.CourseID = &courseID
+		s.Metadata.Schedule = schedule
+		return s
+	}
+	stories := []Story{
+		story(1, nil),
+		story(2, &StorySchedule{AvailableFrom: &past}),
+		story(3, &StorySchedule{AvailableFrom: &future}),
+		story(4, &StorySchedule{DueAt: &past}), // Overdue stories stay visible
+	}
+
+	svc := NewService(database.NewMockDBTX(), nil, nil, &fakeClock{now: now})
+	available := svc.availableStories(context.Background(), "student", stories)
+
+	var ids []int
+	for _, s := range available {
+		ids = append(ids, s.Metadata.StoryID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 4 {
+		t.Errorf("Expected stories 1, 2 and 4, got %v", ids)
+	}
+}
+
+func TestSameStoryScoreIgnoresLateness(t *testing.T) {
+	onTime := StoryScore{UserID: "user_1", StoryID: 7, FormulaVersion: ScoreFormulaVersion, VocabCorrect: 3}
+	late := onTime
+	late.Late = true
+	if !sameStoryScore(onTime, late) {
+		t.Error("Expected revisiting an unchanged score after the due date not to count as a new score")
+	}
+}
+
+func TestShiftCourseScheduleMovesTermStart(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: ShiftCourseSchedule", nil, nil)
+	mock.StubExec("-- name: ShiftCourseRiskStart", errors.New("term start not moved"))
+	svc := NewService(mock, nil, nil, nil)
+
+	if _, err := svc.ShiftCourseSchedule(context.Background(), 3, 7); err == nil || err.Error() != "term start not moved" {
+		t.Errorf("Expected the term start to move with the schedule, got %v", err)
+	}
+}
@@ -0,0 +1,379 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ScoreFormulaVersion identifies how StoryScore numbers are computed. Bump it whenever
+// CalculateScoreWithRetriesAllowed or ComputeStoryScore change, then run the recompute job.
+const ScoreFormulaVersion = 1
+
+// Why a score snapshot was taken
+const (
+	ScoreReasonScorePage = "score_page"
+	ScoreReasonRecompute = "recompute"
+)
+
+// StoryScore is a user's score on a story along with the counts it was derived from.
+// Accuracies are percentages (0-100).
+type StoryScore struct {
+	ID                     int       `json:"id,omitempty"` // 0 until saved
+	UserID                 string    `json:"userId"`
+	StoryID                int       `json:"storyId"`
+	FormulaVersion         int       `json:"formulaVersion"`
+	Reason                 string    `json:"reason,omitempty"`
+	VocabCorrect           int       `json:"vocabCorrect"`
+	VocabIncorrect         int       `json:"vocabIncorrect"`
+	VocabTotal             int       `json:"vocabTotal"`
+	VocabAccuracy          float64   `json:"vocabAccuracy"`
+	GrammarCorrect         int       `json:"grammarCorrect"`
+	GrammarIncorrect       int       `json:"grammarIncorrect"`
+	GrammarTotal           int       `json:"grammarTotal"`
+	GrammarAccuracy        float64   `json:"grammarAccuracy"`
+	RecallCorrect          int       `json:"recallCorrect"`
+	RecallIncorrect        int       `json:"recallIncorrect"`
+	RecallTotal            int       `json:"recallTotal"`
+	RecallAccuracy         float64   `json:"recallAccuracy"`
+	TranslationCompleted   bool      `json:"translationCompleted"`
+	VocabTimeSeconds       int       `json:"vocabTimeSeconds"`
+	GrammarTimeSeconds     int       `json:"grammarTimeSeconds"`
+	TranslationTimeSeconds int       `json:"translationTimeSeconds"`
+	VideoTimeSeconds       int       `json:"videoTimeSeconds"`
+	TotalTimeSeconds       int       `json:"totalTimeSeconds"`
+	OverallAccuracy        float64   `json:"overallAccuracy"`
+	Late                   bool      `json:"late"` // Reached after the story was due
+	ComputedAt             time.Time `json:"computedAt"`
+}
+
+// ScoreRecomputeStatus reports on the most recent recompute job
+type ScoreRecomputeStatus struct {
+	Running        bool       `json:"running"`
+	FormulaVersion int        `json:"formulaVersion"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+	Total          int        `json:"total"`     // User/story pairs with a snapshot
+	Processed      int        `json:"processed"` // Pairs recomputed so far
+	Changed        int        `json:"changed"`   // Pairs whose score changed and got a new snapshot
+	Failed         int        `json:"failed"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// scoreRecompute guards the state of the recompute job
+type scoreRecompute struct {
+	mu     sync.Mutex
+	status ScoreRecomputeStatus
+}
+
+// ComputeStoryScore computes a user's current score on a story from the answer logs
+func (s *Service) ComputeStoryScore(ctx context.Context, userID string, storyID int) (*StoryScore, error) {
+	vocabTotal, err := s.CountStoryVocabItems(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	vocab, err := s.GetUserStoryVocabSummary(ctx, userID, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	grammarTotal, err := s.queries.CountStoryGrammarItems(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	grammar, err := s.GetUserStoryGrammarSummary(ctx, userID, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	recallSentences, err := s.GetStoryRecallSentences(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	recallTotal := int64(len(recallSentences))
+	recall, err := s.GetUserStoryRecallSummary(ctx, userID, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	translationCompleted, err := s.TranslationRequestExists(ctx, userID, storyID)
+	if err != nil {
+		return nil, err
+	}
+	timeData, err := s.GetUserStoryTimeTracking(ctx, userID, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	score := &StoryScore{
+		UserID:                 userID,
+		StoryID:                storyID,
+		FormulaVersion:         ScoreFormulaVersion,
+		VocabCorrect:           int(vocab.CorrectCount),
+		VocabIncorrect:         int(vocab.IncorrectCount),
+		VocabTotal:             int(vocabTotal),
+		GrammarCorrect:         int(grammar.CorrectCount),
+		GrammarIncorrect:       int(grammar.IncorrectCount),
+		GrammarTotal:           int(grammarTotal),
+		RecallCorrect:          int(recall.CorrectCount),
+		RecallIncorrect:        int(recall.IncorrectCount),
+		RecallTotal:            int(recallTotal),
+		TranslationCompleted:   translationCompleted,
+		VocabTimeSeconds:       timeData.VocabTimeSeconds,
+		GrammarTimeSeconds:     timeData.GrammarTimeSeconds,
+		TranslationTimeSeconds: timeData.TranslationTimeSeconds,
+		VideoTimeSeconds:       timeData.VideoTimeSeconds,
+		TotalTimeSeconds: timeData.VocabTimeSeconds + timeData.GrammarTimeSeconds +
+			timeData.TranslationTimeSeconds + timeData.VideoTimeSeconds,
+		ComputedAt: s.clock.Now(),
+	}
+	if vocabTotal > 0 {
+		score.VocabAccuracy = CalculateScoreWithRetriesAllowed(vocab.CorrectCount, vocab.IncorrectCount, vocabTotal)
+	}
+	if grammarTotal > 0 {
+		score.GrammarAccuracy = CalculateScoreWithRetriesAllowed(grammar.CorrectCount, grammar.IncorrectCount, grammarTotal)
+	}
+	if recallTotal > 0 {
+		score.RecallAccuracy = CalculateScoreWithRetriesAllowed(recall.CorrectCount, recall.IncorrectCount, recallTotal)
+	}
+	score.OverallAccuracy = CalculateScoreWithRetriesAllowed(
+		vocab.CorrectCount+grammar.CorrectCount+recall.CorrectCount,
+		vocab.IncorrectCount+grammar.IncorrectCount+recall.IncorrectCount,
+		vocabTotal+grammarTotal+recallTotal,
+	)
+	return score, nil
+}
+
+// SaveStoryScoreSnapshot stores score unless it matches the latest snapshot of the same
+// user and story, marking it late if the story is past due. It returns the latest
+// snapshot and whether a new one was written.
+func (s *Service) SaveStoryScoreSnapshot(ctx context.Context, score *StoryScore, reason string) (*StoryScore, bool, error) {
+	latest, err := s.GetLatestStoryScore(ctx, score.UserID, score.StoryID)
+	if err != nil && err != ErrNotFound {
+		return nil, false, err
+	}
+	if latest != nil && sameStoryScore(*latest, *score) {
+		return latest, false, nil
+	}
+
+	// A recompute is not the student's doing, so it keeps the lateness of the score it replaces
+	if reason == ScoreReasonRecompute && latest != nil {
+		score.Late = latest.Late
+	} else {
+		schedule, err := s.GetStorySchedule(ctx, score.StoryID)
+		if err != nil {
+			return nil, false, err
+		}
+		score.Late = schedule.PastDue(s.clock.Now())
+	}
+
+	result, err := s.queries.CreateStoryScore(ctx, db.CreateStoryScoreParams{
+		UserID:                 score.UserID,
+		StoryID:                int32(score.StoryID),
+		FormulaVersion:         int32(score.FormulaVersion),
+		Reason:                 reason,
+		VocabCorrect:           int32(score.VocabCorrect),
+		VocabIncorrect:         int32(score.VocabIncorrect),
+		VocabTotal:             int32(score.VocabTotal),
+		VocabAccuracy:          score.VocabAccuracy,
+		GrammarCorrect:         int32(score.GrammarCorrect),
+		GrammarIncorrect:       int32(score.GrammarIncorrect),
+		GrammarTotal:           int32(score.GrammarTotal),
+		GrammarAccuracy:        score.GrammarAccuracy,
+		RecallCorrect:          int32(score.RecallCorrect),
+		RecallIncorrect:        int32(score.RecallIncorrect),
+		RecallTotal:            int32(score.RecallTotal),
+		RecallAccuracy:         score.RecallAccuracy,
+		TranslationCompleted:   score.TranslationCompleted,
+		VocabTimeSeconds:       int32(score.VocabTimeSeconds),
+		GrammarTimeSeconds:     int32(score.GrammarTimeSeconds),
+		TranslationTimeSeconds: int32(score.TranslationTimeSeconds),
+		VideoTimeSeconds:       int32(score.VideoTimeSeconds),
+		TotalTimeSeconds:       int32(score.TotalTimeSeconds),
+		OverallAccuracy:        score.OverallAccuracy,
+		Late:                   score.Late,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	saved := storyScoreFromDB(result)
+	s.publishScore(saved)
+	s.InvalidateStoryCourseAnalytics(ctx, saved.StoryID)
+	return &saved, true, nil
+}
+
+// GetLatestStoryScore returns the newest snapshot of a user's score on a story
+func (s *Service) GetLatestStoryScore(ctx context.Context, userID string, storyID int) (*StoryScore, error) {
+	result, err := s.queries.GetLatestStoryScore(ctx, db.GetLatestStoryScoreParams{
+		UserID:  userID,
+		StoryID: int32(storyID),
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	score := storyScoreFromDB(result)
+	return &score, nil
+}
+
+// GetStoryScoreHistory returns every snapshot of a user's score on a story, newest first
+func (s *Service) GetStoryScoreHistory(ctx context.Context, userID string, storyID int) ([]StoryScore, error) {
+	results, err := s.queries.GetStoryScoreHistory(ctx, db.GetStoryScoreHistoryParams{
+		UserID:  userID,
+		StoryID: int32(storyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]StoryScore, 0, len(results))
+	for _, result := range results {
+		scores = append(scores, storyScoreFromDB(result))
+	}
+	return scores, nil
+}
+
+// GetStoryLatestScores returns the newest snapshot of every user with a score on a story
+func (s *Service) GetStoryLatestScores(ctx context.Context, storyID int) ([]StoryScore, error) {
+	results, err := s.queries.GetStoryLatestScores(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]StoryScore, 0, len(results))
+	for _, result := range results {
+		scores = append(scores, storyScoreFromDB(result))
+	}
+	return scores, nil
+}
+
+// StartScoreRecompute recomputes every user/story pair that has a snapshot, in the
+// background. It returns false if a recompute is already running.
+func (s *Service) StartScoreRecompute() (ScoreRecomputeStatus, bool) {
+	s.recompute.mu.Lock()
+	defer s.recompute.mu.Unlock()
+	if s.recompute.status.Running {
+		return s.recompute.status, false
+	}
+
+	now := s.clock.Now()
+	s.recompute.status = ScoreRecomputeStatus{
+		Running:        true,
+		FormulaVersion: ScoreFormulaVersion,
+		StartedAt:      &now,
+	}
+	// Not tied to the request that started it
+	go s.runScoreRecompute(context.Background())
+	return s.recompute.status, true
+}
+
+// GetScoreRecomputeStatus returns the progress of the current or last recompute job
+func (s *Service) GetScoreRecomputeStatus() ScoreRecomputeStatus {
+	s.recompute.mu.Lock()
+	defer s.recompute.mu.Unlock()
+	return s.recompute.status
+}
+
+func (s *Service) runScoreRecompute(ctx context.Context) {
+	pairs, err := s.queries.GetScoredUserStories(ctx)
+	if err != nil {
+		fmt.Printf("Failed to list scored stories for recompute: %v\n", err)
+		s.updateScoreRecompute(func(status *ScoreRecomputeStatus) {
+			status.Error = err.Error()
+			s.finishScoreRecompute(status)
+		})
+		return
+	}
+	s.updateScoreRecompute(func(status *ScoreRecomputeStatus) {
+		status.Total = len(pairs)
+	})
+
+	for _, pair := range pairs {
+		changed, err := s.recomputeStoryScore(ctx, pair.UserID, int(pair.StoryID))
+		if err != nil {
+			fmt.Printf("Failed to recompute score of user %s on story %d: %v\n", pair.UserID, pair.StoryID, err)
+		}
+		s.updateScoreRecompute(func(status *ScoreRecomputeStatus) {
+			status.Processed++
+			if err != nil {
+				status.Failed++
+			} else if changed {
+				status.Changed++
+			}
+		})
+	}
+
+	s.updateScoreRecompute(s.finishScoreRecompute)
+	fmt.Printf("Recomputed %d story scores\n", len(pairs))
+}
+
+func (s *Service) recomputeStoryScore(ctx context.Context, userID string, storyID int) (bool, error) {
+	score, err := s.ComputeStoryScore(ctx, userID, storyID)
+	if err != nil {
+		return false, err
+	}
+	_, changed, err := s.SaveStoryScoreSnapshot(ctx, score, ScoreReasonRecompute)
+	return changed, err
+}
+
+func (s *Service) updateScoreRecompute(update func(status *ScoreRecomputeStatus)) {
+	s.recompute.mu.Lock()
+	defer s.recompute.mu.Unlock()
+	update(&s.recompute.status)
+}
+
+// finishScoreRecompute must be called with the recompute lock held
+func (s *Service) finishScoreRecompute(status *ScoreRecomputeStatus) {
+	now := s.clock.Now()
+	status.Running = false
+	status.FinishedAt = &now
+}
+
+// sameStoryScore reports whether two scores have the same formula and numbers
+func sameStoryScore(a, b StoryScore) bool {
+	a.ID, a.Reason, a.Late, a.ComputedAt = 0, "", false, time.Time{}
+	b.ID, b.Reason, b.Late, b.ComputedAt = 0, "", false, time.Time{}
+	return a == b
+}
+
+func storyScoreFromDB(result db.StoryScore) StoryScore {
+	return StoryScore{
+		ID:                     int(result.ID),
+		UserID:                 result.UserID,
+		StoryID:                int(result.StoryID),
+		FormulaVersion:         int(result.FormulaVersion),
+		Reason:                 result.Reason,
+		VocabCorrect:           int(result.VocabCorrect),
+		VocabIncorrect:         int(result.VocabIncorrect),
+		VocabTotal:             int(result.VocabTotal),
+		VocabAccuracy:          result.VocabAccuracy,
+		GrammarCorrect:         int(result.GrammarCorrect),
+		GrammarIncorrect:       int(result.GrammarIncorrect),
+		GrammarTotal:           int(result.GrammarTotal),
+		GrammarAccuracy:        result.GrammarAccuracy,
+		RecallCorrect:          int(result.RecallCorrect),
+		RecallIncorrect:        int(result.RecallIncorrect),
+		RecallTotal:            int(result.RecallTotal),
+		RecallAccuracy:         result.RecallAccuracy,
+		TranslationCompleted:   result.TranslationCompleted,
+		VocabTimeSeconds:       int(result.VocabTimeSeconds),
+		GrammarTimeSeconds:     int(result.GrammarTimeSeconds),
+		TranslationTimeSeconds: int(result.TranslationTimeSeconds),
+		VideoTimeSeconds:       int(result.VideoTimeSeconds),
+		TotalTimeSeconds:       int(result.TotalTimeSeconds),
+		OverallAccuracy:        result.OverallAccuracy,
+		Late:                   result.Late,
+		ComputedAt:             result.ComputedAt.Time,
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSameStoryScore(t *testing.T) {
+	base := StoryScore{
+		UserID:          "user_1",
+		StoryID:         7,
+		FormulaVersion:  ScoreFormulaVersion,
+		VocabCorrect:    4,
+		VocabIncorrect:  2,
+		VocabTotal:      4,
+		VocabAccuracy:   CalculateScoreWithRetriesAllowed(4, 2, 4),
+		OverallAccuracy: CalculateScoreWithRetriesAllowed(4, 2, 4),
+	}
+
+	saved := base
+	saved.ID = 12
+	saved.Reason = ScoreReasonScorePage
+	saved.ComputedAt = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if !sameStoryScore(saved, base) {
+		t.Error("Expected scores differing only in ID, reason and time to match")
+	}
+
+	moreTime := base
+	moreTime.TotalTimeSeconds = 30
+	if sameStoryScore(base, moreTime) {
+		t.Error("Expected scores with different time to differ")
+	}
+
+	newFormula := base
+	newFormula.FormulaVersion = ScoreFormulaVersion + 1
+	if sameStoryScore(base, newFormula) {
+		t.Error("Expected scores from different formula versions to differ")
+	}
+}
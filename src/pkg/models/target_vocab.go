@@ -0,0 +1,381 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MaxTargetVocab is how many target words a story may have
+	MaxTargetVocab = 5
+	// MinTargetVocabOccurrences is how often a target lexical form must be annotated in the story
+	MinTargetVocabOccurrences = 2
+)
+
+var (
+	ErrTargetVocabLimit             = fmt.Errorf("a story can have at most %d target vocabulary words", MaxTargetVocab)
+	ErrTargetVocabTooFewOccurrences = fmt.Errorf("target lexical form must occur at least %d times in the story vocabulary", MinTargetVocabOccurrences)
+	ErrTargetVocabExists            = errors.New("target vocabulary word already exists for this story")
+	ErrEmptyLexicalForm             = errors.New("lexical form is required")
+)
+
+// GetStoryTargetVocab returns the target words of a story with their occurrence counts
+func (s *Service) GetStoryTargetVocab(ctx context.Context, storyID int) ([]TargetVocab, error) {
+	results, err := s.queries.GetStoryTargetVocab(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]TargetVocab, 0, len(results))
+	for _, result := range results {
+		word := targetVocabFromDB(db.TargetVocabulary{
+			ID:          result.ID,
+			StoryID:     result.StoryID,
+			LexicalForm: result.LexicalForm,
+			AudioPath:   result.AudioPath,
+			AudioBucket: result.AudioBucket,
+			ImagePath:   result.ImagePath,
+			ImageBucket: result.ImageBucket,
+			CreatedAt:   result.CreatedAt,
+		})
+		word.Occurrences = int(result.Occurrences)
+		words = append(words, word)
+	}
+	return words, nil
+}
+
+// GetTargetVocab returns a single target word, or ErrNotFound if it does not belong to the story
+func (s *Service) GetTargetVocab(ctx context.Context, storyID, targetVocabID int) (*TargetVocab, error) {
+	result, err := s.queries.GetTargetVocab(ctx, int32(targetVocabID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int(result.StoryID) != storyID {
+		return nil, ErrNotFound
+	}
+
+	word := targetVocabFromDB(result)
+	if word.Occurrences, err = s.countLexicalFormOccurrences(ctx, storyID, word.LexicalForm); err != nil {
+		return nil, err
+	}
+	return &word, nil
+}
+
+// CreateTargetVocab marks a lexical form as a target word of the story. The checks run
+// with the story row locked, so concurrent requests cannot exceed MaxTargetVocab.
+func (s *Service) CreateTargetVocab(ctx context.Context, storyID int, lexicalForm string) (*TargetVocab, error) {
+	lexicalForm = strings.TrimSpace(lexicalForm)
+	var result db.TargetVocabulary
+	var occurrences int
+	err := s.withStoryRevision(ctx, storyID, "add target vocabulary", func(txCtx context.Context) error {
+		if err := s.queries.LockStoryForRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+		var err error
+		occurrences, err = s.validateTargetLexicalForm(txCtx, storyID, 0, lexicalForm)
+		if err != nil {
+			return err
+		}
+
+		count, err := s.queries.CountStoryTargetVocab(txCtx, int32(storyID))
+		if err != nil {
+			return err
+		}
+		if count >= MaxTargetVocab {
+			return ErrTargetVocabLimit
+		}
+
+		result, err = s.queries.CreateTargetVocab(txCtx, db.CreateTargetVocabParams{
+			StoryID:     int32(storyID),
+			LexicalForm: lexicalForm,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	word := targetVocabFromDB(result)
+	word.Occurrences = occurrences
+	return &word, nil
+}
+
+// UpdateTargetVocabLexicalForm changes which lexical form a target word refers to, keeping its assets
+func (s *Service) UpdateTargetVocabLexicalForm(ctx context.Context, storyID, targetVocabID int, lexicalForm string) (*TargetVocab, error) {
+	lexicalForm = strings.TrimSpace(lexicalForm)
+	var result db.TargetVocabulary
+	var occurrences int
+	err := s.withStoryRevision(ctx, storyID, "update target vocabulary", func(txCtx context.Context) error {
+		// Locked like CreateTargetVocab, so two words cannot take the same lexical form
+		if err := s.queries.LockStoryForRevision(txCtx, int32(storyID)); err != nil {
+			return err
+		}
+		var err error
+		occurrences, err = s.validateTargetLexicalForm(txCtx, storyID, targetVocabID, lexicalForm)
+		if err != nil {
+			return err
+		}
+
+		result, err = s.queries.UpdateTargetVocabLexicalForm(txCtx, db.UpdateTargetVocabLexicalFormParams{
+			ID:          int32(targetVocabID),
+			StoryID:     int32(storyID),
+			LexicalForm: lexicalForm,
+		})
+		return err
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	word := targetVocabFromDB(result)
+	word.Occurrences = occurrences
+	return &word, nil
+}
+
+// SetTargetVocabAudio records an uploaded pronunciation file, removing the one it replaces
+func (s *Service) SetTargetVocabAudio(ctx context.Context, storyID, targetVocabID int, filePath, fileBucket string) (*TargetVocab, error) {
+	previous, err := s.GetTargetVocab(ctx, storyID, targetVocabID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result db.TargetVocabulary
+	err = s.withStoryRevision(ctx, storyID, "set target vocabulary audio", func(txCtx context.Context) error {
+		var err error
+		result, err = s.queries.SetTargetVocabAudio(txCtx, db.SetTargetVocabAudioParams{
+			ID:          int32(targetVocabID),
+			StoryID:     int32(storyID),
+			AudioPath:   pgtype.Text{String: filePath, Valid: filePath != ""},
+			AudioBucket: pgtype.Text{String: fileBucket, Valid: fileBucket != ""},
+		})
+		return err
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.AudioPath != "" && previous.AudioPath != filePath {
+		if err := s.removeStorageObject(ctx, storyID, previous.AudioBucket, previous.AudioPath); err != nil {
+			fmt.Printf("Failed to remove replaced target vocab audio %s: %v\n", previous.AudioPath, err)
+		}
+	}
+
+	word := targetVocabFromDB(result)
+	word.Occurrences = previous.Occurrences
+	return &word, nil
+}
+
+// SetTargetVocabImage records an uploaded picture, removing the one it replaces
+func (s *Service) SetTargetVocabImage(ctx context.Context, storyID, targetVocabID int, filePath, fileBucket string) (*TargetVocab, error) {
+	previous, err := s.GetTargetVocab(ctx, storyID, targetVocabID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result db.TargetVocabulary
+	err = s.withStoryRevision(ctx, storyID, "set target vocabulary image", func(txCtx context.Context) error {
+		var err error
+		result, err = s.queries.SetTargetVocabImage(txCtx, db.SetTargetVocabImageParams{
+			ID:          int32(targetVocabID),
+			StoryID:     int32(storyID),
+			ImagePath:   pgtype.Text{String: filePath, Valid: filePath != ""},
+			ImageBucket: pgtype.Text{String: fileBucket, Valid: fileBucket != ""},
+		})
+		return err
+	})
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.ImagePath != "" && previous.ImagePath != filePath {
+		if err := s.removeStorageObject(ctx, storyID, previous.ImageBucket, previous.ImagePath); err != nil {
+			fmt.Printf("Failed to remove replaced target vocab image %s: %v\n", previous.ImagePath, err)
+		}
+	}
+
+	word := targetVocabFromDB(result)
+	word.Occurrences = previous.Occurrences
+	return &word, nil
+}
+
+// DeleteTargetVocab removes a target word and its assets
+func (s *Service) DeleteTargetVocab(ctx context.Context, storyID, targetVocabID int) error {
+	word, err := s.GetTargetVocab(ctx, storyID, targetVocabID)
+	if err != nil {
+		return err
+	}
+
+	// Delete from storage first
+	if err := s.deleteTargetVocabAssets(ctx, []TargetVocab{*word}); err != nil {
+		return err
+	}
+
+	return s.withStoryRevision(ctx, storyID, "delete target vocabulary", func(txCtx context.Context) error {
+		return s.queries.DeleteTargetVocab(txCtx, int32(targetVocabID))
+	})
+}
+
+// DeleteStoryTargetVocab removes all target words of a story and their assets
+func (s *Service) DeleteStoryTargetVocab(ctx context.Context, storyID int) error {
+	words, err := s.GetStoryTargetVocab(ctx, storyID)
+	if err != nil {
+		return err
+	}
+
+	// Delete from storage first
+	if err := s.deleteTargetVocabAssets(ctx, words); err != nil {
+		return err
+	}
+
+	return s.queries.DeleteStoryTargetVocab(ctx, int32(storyID))
+}
+
+// GetSignedStoryTargetVocab returns the target words of a story with signed asset URLs,
+// after checking the user can access the story
+func (s *Service) GetSignedStoryTargetVocab(ctx context.Context, storyID int, userID string, expiresInSeconds int) ([]SignedTargetVocab, error) {
+	if s.storage == nil {
+		return nil, errors.New("storage client not initialized")
+	}
+	if err := s.checkStoryAccess(ctx, storyID, userID); err != nil {
+		return nil, err
+	}
+
+	words, err := s.GetStoryTargetVocab(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := make([]SignedTargetVocab, 0, len(words))
+	for _, word := range words {
+		audioURL, err := s.SignAssetURL(word.AudioBucket, word.AudioPath, expiresInSeconds)
+		if err != nil {
+			return nil, err
+		}
+		imageURL, err := s.SignAssetURL(word.ImageBucket, word.ImagePath, expiresInSeconds)
+		if err != nil {
+			return nil, err
+		}
+		signed = append(signed, SignedTargetVocab{
+			TargetVocab: word,
+			AudioURL:    audioURL,
+			ImageURL:    imageURL,
+		})
+	}
+	return signed, nil
+}
+
+// validateTargetLexicalForm checks that a lexical form can be a target word of the story.
+// excludeID is the target word being edited, or 0 when creating. Returns the occurrence count.
+func (s *Service) validateTargetLexicalForm(ctx context.Context, storyID, excludeID int, lexicalForm string) (int, error) {
+	if lexicalForm == "" {
+		return 0, ErrEmptyLexicalForm
+	}
+
+	existing, err := s.queries.GetStoryTargetVocab(ctx, int32(storyID))
+	if err != nil {
+		return 0, err
+	}
+	for _, word := range existing {
+		if word.LexicalForm == lexicalForm && int(word.ID) != excludeID {
+			return 0, ErrTargetVocabExists
+		}
+	}
+
+	occurrences, err := s.countLexicalFormOccurrences(ctx, storyID, lexicalForm)
+	if err != nil {
+		return 0, err
+	}
+	if occurrences < MinTargetVocabOccurrences {
+		return occurrences, ErrTargetVocabTooFewOccurrences
+	}
+	return occurrences, nil
+}
+
+func (s *Service) countLexicalFormOccurrences(ctx context.Context, storyID int, lexicalForm string) (int, error) {
+	count, err := s.queries.CountLexicalFormOccurrences(ctx, db.CountLexicalFormOccurrencesParams{
+		StoryID:     pgtype.Int4{Int32: int32(storyID), Valid: true},
+		LexicalForm: lexicalForm,
+	})
+	return int(count), err
+}
+
+// deleteTargetVocabAssets deletes the audio and image files of target words from object storage
+func (s *Service) deleteTargetVocabAssets(ctx context.Context, words []TargetVocab) error {
+	for _, word := range words {
+		if word.AudioPath != "" {
+			if err := s.removeStorageObject(ctx, word.StoryID, word.AudioBucket, word.AudioPath); err != nil {
+				return err
+			}
+		}
+		if word.ImagePath != "" {
+			if err := s.removeStorageObject(ctx, word.StoryID, word.ImageBucket, word.ImagePath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeStorageObject deletes a file of a story from object storage, unless another
+// story (e.g. a clone) still refers to it
+func (s *Service) removeStorageObject(ctx context.Context, storyID int, bucket, path string) error {
+	if s.storage == nil {
+		return errors.New("storage client not initialized")
+	}
+	shared, err := s.storageObjectShared(ctx, storyID, bucket, path)
+	if err != nil {
+		return err
+	}
+	if shared {
+		return nil
+	}
+	if err := s.storage.Remove(bucket, []string{path}); err != nil {
+		return fmt.Errorf("failed to delete file from storage: %w", err)
+	}
+	return nil
+}
+
+// storageObjectShared reports whether a stored file is used by a story other than storyID
+func (s *Service) storageObjectShared(ctx context.Context, storyID int, bucket, path string) (bool, error) {
+	uses, err := s.queries.CountStorageObjectUses(ctx, db.CountStorageObjectUsesParams{
+		FileBucket: bucket,
+		FilePath:   path,
+		StoryID:    int32(storyID),
+	})
+	if err != nil {
+		return false, err
+	}
+	return uses > 0, nil
+}
+
+func targetVocabFromDB(result db.TargetVocabulary) TargetVocab {
+	return TargetVocab{
+		ID:          int(result.ID),
+		StoryID:     int(result.StoryID),
+		LexicalForm: result.LexicalForm,
+		AudioPath:   result.AudioPath.String,
+		AudioBucket: result.AudioBucket.String,
+		ImagePath:   result.ImagePath.String,
+		ImageBucket: result.ImageBucket.String,
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"glossias/src/pkg/database"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func targetVocabRow(id int32, lexicalForm string) []interface{} {
+	return []interface{}{
+		id,                             // ID
+		int32(7),                       // StoryID
+		lexicalForm,                    // LexicalForm
+		pgtype.Text{},                  // AudioPath
+		pgtype.Text{},                  // AudioBucket
+		pgtype.Text{},                  // ImagePath
+		pgtype.Text{},                  // ImageBucket
+		pgtype.Timestamp{Valid: false}, // CreatedAt
+	}
+}
+
+// storyTargetVocabRow is a GetStoryTargetVocab row, which adds the occurrence count
+func storyTargetVocabRow(id int32, lexicalForm string, occurrences int64) []interface{} {
+	return append(targetVocabRow(id, lexicalForm), occurrences)
+}
+
+func TestCreateTargetVocabValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		lexicalForm string
+		existing    [][]interface{}
+		occurrences int64
+		storyCount  int64
+		wantErr     error
+	}{
+		{name: "empty lexical form", lexicalForm: "  ", wantErr: ErrEmptyLexicalForm},
+		{name: "too few occurrences", lexicalForm: "כלב", occurrences: 1, wantErr: ErrTargetVocabTooFewOccurrences},
+		{
+			name:        "duplicate",
+			lexicalForm: "כלב",
+			existing:    [][]interface{}{storyTargetVocabRow(1, "כלב", 3)},
+			occurrences: 3,
+			wantErr:     ErrTargetVocabExists,
+		},
+		{name: "story full", lexicalForm: "כלב", occurrences: 2, storyCount: MaxTargetVocab, wantErr: ErrTargetVocabLimit},
+		{name: "valid", lexicalForm: " כלב ", occurrences: 2, storyCount: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := database.NewMockDBTX()
+			mockDB.StubQuery("-- name: GetStoryTargetVocab", tt.existing, nil)
+			mockDB.StubQuery("-- name: CountLexicalFormOccurrences", [][]interface{}{{tt.occurrences}}, nil)
+			mockDB.StubQuery("-- name: CountStoryTargetVocab", [][]interface{}{{tt.storyCount}}, nil)
+			mockDB.StubQuery("-- name: CreateTargetVocab", [][]interface{}{targetVocabRow(9, "כלב")}, nil)
+			// The story is loaded for the revision recorded with the new word
+			mockDB.StubQuery("-- name: GetStory :one", [][]interface{}{{
+				int32(7), int32(1), "a", pgtype.Text{}, pgtype.Timestamp{}, "", "", pgtype.Int4{}, pgtype.Timestamp{},
+			}}, nil)
+			svc := NewService(mockDB, nil, nil, nil)
+
+			word, err := svc.CreateTargetVocab(context.Background(), 7, tt.lexicalForm)
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if word.ID != 9 || word.LexicalForm != "כלב" || word.Occurrences != 2 {
+				t.Errorf("Unexpected target word %+v", word)
+			}
+		})
+	}
+}
+
+func TestGetTargetVocabWrongStory(t *testing.T) {
+	mockDB := database.NewMockDBTX()
+	mockDB.StubQuery("-- name: GetTargetVocab", [][]interface{}{targetVocabRow(1, "כלב")}, nil)
+	svc := NewService(mockDB, nil, nil, nil)
+
+	if _, err := svc.GetTargetVocab(context.Background(), 8, 1); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a word from another story, got %v", err)
+	}
+}
+
+func TestGetStoryTargetVocabOccurrences(t *testing.T) {
+	mockDB := database.NewMockDBTX()
+	// Occurrences come with the words; CountLexicalFormOccurrences is not stubbed and would fail
+	mockDB.StubQuery("-- name: GetStoryTargetVocab", [][]interface{}{
+		storyTargetVocabRow(1, "כלב", 3),
+		storyTargetVocabRow(2, "חתול", 2),
+	}, nil)
+	svc := NewService(mockDB, nil, nil, nil)
+
+	words, err := svc.GetStoryTargetVocab(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(words) != 2 || words[0].Occurrences != 3 || words[1].Occurrences != 2 {
+		t.Errorf("Unexpected target words %+v", words)
+	}
+}
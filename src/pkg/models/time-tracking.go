@@ -24,12 +24,12 @@ type TimeTrackingSession struct {
 
 // MakeTimeTrackingSession creates or returns existing time tracking session
 // Ensures only one active session per user/route/story combination
-func MakeTimeTrackingSession(ctx context.Context, userID, route string, storyID *int32) (string, error) {
+func (s *Service) MakeTimeTrackingSession(ctx context.Context, userID, route string, storyID *int32) (string, error) {
 	// Check for existing active session for this user/route/story combination
-	if cacheInstance != nil && keyBuilder != nil {
-		activeKey := keyBuilder.ActiveTimeTrackingSession(userID, route, storyID)
+	if s.cache != nil && s.keys != nil {
+		activeKey := s.keys.ActiveTimeTrackingSession(userID, route, storyID)
 		var existingSessionID string
-		err := cacheInstance.GetJSON(activeKey, &existingSessionID)
+		err := s.cache.GetJSON(activeKey, &existingSessionID)
 		if err == nil && existingSessionID != "" {
 			// Return existing session ID
 			return existingSessionID, nil
@@ -38,7 +38,7 @@ func MakeTimeTrackingSession(ctx context.Context, userID, route string, storyID
 
 	// Clear any old active sessions for this user on different routes
 	// This handles navigation to a new page
-	if cacheInstance != nil && keyBuilder != nil {
+	if s.cache != nil && s.keys != nil {
 		ClearActiveSessionsForUser(ctx, userID, route, storyID)
 	}
 
@@ -55,38 +55,38 @@ func MakeTimeTrackingSession(ctx context.Context, userID, route string, storyID
 		UserID:    userID,
 		Route:     route,
 		StoryID:   storyID,
-		CreatedAt: time.Now(),
+		CreatedAt: s.clock.Now(),
 	}
 
-	if cacheInstance != nil && keyBuilder != nil {
+	if s.cache != nil && s.keys != nil {
 		// Store session data
-		cacheKey := keyBuilder.TimeTrackingSession(sessionID)
-		_ = cacheInstance.SetJSON(cacheKey, session)
+		cacheKey := s.keys.TimeTrackingSession(sessionID)
+		_ = s.cache.SetJSON(cacheKey, session)
 
 		// Mark this as the active session for this user/route/story
-		activeKey := keyBuilder.ActiveTimeTrackingSession(userID, route, storyID)
-		_ = cacheInstance.SetJSON(activeKey, sessionID)
+		activeKey := s.keys.ActiveTimeTrackingSession(userID, route, storyID)
+		_ = s.cache.SetJSON(activeKey, sessionID)
 	}
 
 	return sessionID, nil
 }
 
 // GetTimeTrackingBySessionID retrieves a time tracking session by its ID
-func GetTimeTrackingBySessionID(ctx context.Context, sessionID string) (*TimeTrackingSession, error) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) GetTimeTrackingBySessionID(ctx context.Context, sessionID string) (*TimeTrackingSession, error) {
+	if s.cache == nil || s.keys == nil {
 		return nil, nil
 	}
 
-	cacheKey := keyBuilder.TimeTrackingSession(sessionID)
+	cacheKey := s.keys.TimeTrackingSession(sessionID)
 	var session TimeTrackingSession
-	err := cacheInstance.GetJSON(cacheKey, &session)
+	err := s.cache.GetJSON(cacheKey, &session)
 	if err != nil {
 		return nil, nil
 	}
 
 	// Check if session is too old (expired)
-	if time.Since(session.CreatedAt) > SESSION_MAX_AGE {
-		_ = cacheInstance.Delete(cacheKey)
+	if s.clock.Now().Sub(session.CreatedAt) > SESSION_MAX_AGE {
+		_ = s.cache.Delete(cacheKey)
 		return nil, nil
 	}
 
@@ -94,13 +94,13 @@ func GetTimeTrackingBySessionID(ctx context.Context, sessionID string) (*TimeTra
 }
 
 // InvalidateTimeTrackingSession removes a session from active tracking
-func InvalidateTimeTrackingSession(ctx context.Context, sessionID string) {
-	if cacheInstance == nil || keyBuilder == nil {
+func (s *Service) InvalidateTimeTrackingSession(ctx context.Context, sessionID string) {
+	if s.cache == nil || s.keys == nil {
 		return
 	}
 
-	cacheKey := keyBuilder.TimeTrackingSession(sessionID)
-	_ = cacheInstance.Delete(cacheKey)
+	cacheKey := s.keys.TimeTrackingSession(sessionID)
+	_ = s.cache.Delete(cacheKey)
 }
 
 // ClearActiveSessionsForUser clears active sessions for a user when navigating to a new route
@@ -114,8 +114,8 @@ func ClearActiveSessionsForUser(ctx context.Context, userID, currentRoute string
 }
 
 // GetTimeEntriesForUser returns time tracking entries for a specific user
-func GetTimeEntriesForUser(ctx context.Context, userID string) ([]db.UserTimeTracking, error) {
-	entries, err := queries.GetTimeEntriesForUser(ctx, userID)
+func (s *Service) GetTimeEntriesForUser(ctx context.Context, userID string) ([]db.UserTimeTracking, error) {
+	entries, err := s.queries.GetTimeEntriesForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -126,18 +126,18 @@ func GetTimeEntriesForUser(ctx context.Context, userID string) ([]db.UserTimeTra
 // RecordTimeTracking creates a complete time tracking entry in one call
 // Uses client-provided elapsed time for accuracy
 // Updates existing entry with larger time value if found within deduplication window
-func RecordTimeTracking(ctx context.Context, userID, route string, storyID *int32, elapsedMs int32) error {
+func (s *Service) RecordTimeTracking(ctx context.Context, userID, route string, storyID *int32, elapsedMs int32) error {
 	var pgStoryID pgtype.Int4
 	if storyID != nil {
 		pgStoryID = pgtype.Int4{Int32: *storyID, Valid: true}
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	totalSeconds := elapsedMs / 1000
 	startTime := now.Add(-time.Duration(elapsedMs) * time.Millisecond)
 
 	// Check for similar recent entry using single query
-	recentEntry, err := queries.FindRecentSimilarTimeEntry(ctx, db.FindRecentSimilarTimeEntryParams{
+	recentEntry, err := s.queries.FindRecentSimilarTimeEntry(ctx, db.FindRecentSimilarTimeEntryParams{
 		UserID:    userID,
 		Route:     route,
 		StoryID:   pgStoryID,
@@ -146,7 +146,7 @@ func RecordTimeTracking(ctx context.Context, userID, route string, storyID *int3
 
 	if err == nil && recentEntry.TotalTimeSeconds.Valid {
 		// Found recent similar entry, accumulate time
-		err = queries.AccumulateTimeEntry(ctx, db.AccumulateTimeEntryParams{
+		err = s.queries.AccumulateTimeEntry(ctx, db.AccumulateTimeEntryParams{
 			TrackingID:       recentEntry.TrackingID,
 			TotalTimeSeconds: pgtype.Int4{Int32: totalSeconds, Valid: true},
 			EndedAt:          pgtype.Timestamp{Time: now, Valid: true},
@@ -159,7 +159,7 @@ func RecordTimeTracking(ctx context.Context, userID, route string, storyID *int3
 	}
 
 	// No recent similar entry found, create new one
-	_, err = queries.CreateCompleteTimeEntry(ctx, db.CreateCompleteTimeEntryParams{
+	_, err = s.queries.CreateCompleteTimeEntry(ctx, db.CreateCompleteTimeEntryParams{
 		UserID:           userID,
 		Route:            route,
 		StoryID:          pgStoryID,
@@ -175,8 +175,8 @@ func RecordTimeTracking(ctx context.Context, userID, route string, storyID *int3
 }
 
 // GetTimeEntriesForStory returns time tracking entries for a specific story
-func GetTimeEntriesForStory(ctx context.Context, storyID int32) ([]db.UserTimeTracking, error) {
-	entries, err := queries.GetTimeEntriesForStory(ctx, pgtype.Int4{Int32: storyID, Valid: true})
+func (s *Service) GetTimeEntriesForStory(ctx context.Context, storyID int32) ([]db.UserTimeTracking, error) {
+	entries, err := s.queries.GetTimeEntriesForStory(ctx, pgtype.Int4{Int32: storyID, Valid: true})
 	if err != nil {
 		return nil, err
 	}
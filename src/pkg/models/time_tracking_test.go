@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"glossias/src/pkg/cache"
 	"glossias/src/pkg/database"
 	"testing"
 	"time"
@@ -80,16 +81,16 @@ func TestTimeTrackingSession_Expiration(t *testing.T) {
 	}
 
 	// Fetch session from cache directly and modify its CreatedAt
-	cacheKey := keyBuilder.TimeTrackingSession(sessID)
+	cacheKey := defaultService.keys.TimeTrackingSession(sessID)
 	var session TimeTrackingSession
-	err = cacheInstance.GetJSON(cacheKey, &session)
+	err = defaultService.cache.GetJSON(cacheKey, &session)
 	if err != nil {
 		t.Fatalf("failed to fetch session from cache directly: %v", err)
 	}
 
 	// Move CreatedAt 3 hours in the past
 	session.CreatedAt = time.Now().Add(-3 * time.Hour)
-	err = cacheInstance.SetJSON(cacheKey, &session)
+	err = defaultService.cache.SetJSON(cacheKey, &session)
 	if err != nil {
 		t.Fatalf("failed to update session back to cache: %v", err)
 	}
@@ -159,3 +160,35 @@ func TestRecordTimeTracking_Create(t *testing.T) {
 		t.Errorf("expected no error, got %v", err)
 	}
 }
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestTimeTrackingSession_ExpirationWithInjectedClock(t *testing.T) {
+	c, err := cache.New(cache.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to initialize cache: %v", err)
+	}
+	clock := &fakeClock{now: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)}
+	svc := NewService(database.NewMockDBTX(), nil, c, clock)
+
+	ctx := context.Background()
+	storyID := int32(3)
+	sessID, err := svc.MakeTimeTrackingSession(ctx, "user-clock", "/stories/3", &storyID)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	clock.now = clock.now.Add(SESSION_MAX_AGE - time.Minute)
+	if session, _ := svc.GetTimeTrackingBySessionID(ctx, sessID); session == nil {
+		t.Fatal("expected session to still be valid just before max age")
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if session, _ := svc.GetTimeTrackingBySessionID(ctx, sessID); session != nil {
+		t.Errorf("expected session to expire after max age, got %+v", session)
+	}
+}
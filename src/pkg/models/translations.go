@@ -17,12 +17,12 @@ type LineTranslation struct {
 }
 
 // GetLineTranslation retrieves a single translation for a specific line and language
-func GetLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode string) (string, error) {
-	if queries == nil {
+func (s *Service) GetLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode string) (string, error) {
+	if s.queries == nil {
 		return "", errors.New("database not initialized")
 	}
 
-	translation, err := queries.GetLineTranslation(ctx, db.GetLineTranslationParams{
+	translation, err := s.queries.GetLineTranslation(ctx, db.GetLineTranslationParams{
 		StoryID:      int32(storyID),
 		LineNumber:   int32(lineNumber),
 		LanguageCode: languageCode,
@@ -39,27 +39,51 @@ func GetLineTranslation(ctx context.Context, storyID, lineNumber int, languageCo
 }
 
 // UpsertLineTranslation creates or updates a translation for a specific line
-func UpsertLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode, translationText string) error {
-	if queries == nil {
+func (s *Service) UpsertLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode, translationText string) error {
+	if s.queries == nil {
 		return errors.New("database not initialized")
 	}
 
-	err := queries.UpsertLineTranslation(ctx, db.UpsertLineTranslationParams{
-		StoryID:         int32(storyID),
-		LineNumber:      int32(lineNumber),
-		LanguageCode:    languageCode,
-		TranslationText: translationText,
+	return s.withStoryRevision(ctx, storyID, "edit translation", func(txCtx context.Context) error {
+		return s.queries.UpsertLineTranslation(txCtx, db.UpsertLineTranslationParams{
+			StoryID:         int32(storyID),
+			LineNumber:      int32(lineNumber),
+			LanguageCode:    languageCode,
+			TranslationText: translationText,
+		})
+	})
+}
+
+// UpsertLineTranslations creates or updates the translations of several lines into one
+// language, as a single revision
+func (s *Service) UpsertLineTranslations(ctx context.Context, storyID int, languageCode string, translations map[int]string) error {
+	if s.queries == nil {
+		return errors.New("database not initialized")
+	}
+
+	return s.withStoryRevision(ctx, storyID, "edit translations", func(txCtx context.Context) error {
+		for lineNumber, translationText := range translations {
+			err := s.queries.UpsertLineTranslation(txCtx, db.UpsertLineTranslationParams{
+				StoryID:         int32(storyID),
+				LineNumber:      int32(lineNumber),
+				LanguageCode:    languageCode,
+				TranslationText: translationText,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
-	return err
 }
 
 // GetAllTranslationsForStory retrieves all translations for a story
-func GetAllTranslationsForStory(ctx context.Context, storyID int) ([]LineTranslation, error) {
-	if queries == nil {
+func (s *Service) GetAllTranslationsForStory(ctx context.Context, storyID int) ([]LineTranslation, error) {
+	if s.queries == nil {
 		return nil, errors.New("database not initialized")
 	}
 
-	rows, err := queries.GetAllTranslationsForStory(ctx, int32(storyID))
+	rows, err := s.queries.GetAllTranslationsForStory(ctx, int32(storyID))
 	if err != nil {
 		return nil, err
 	}
@@ -78,12 +102,12 @@ func GetAllTranslationsForStory(ctx context.Context, storyID int) ([]LineTransla
 }
 
 // GetTranslationsByLanguage retrieves all translations for a story in a specific language
-func GetTranslationsByLanguage(ctx context.Context, storyID int, languageCode string) ([]LineTranslation, error) {
-	if queries == nil {
+func (s *Service) GetTranslationsByLanguage(ctx context.Context, storyID int, languageCode string) ([]LineTranslation, error) {
+	if s.queries == nil {
 		return nil, errors.New("database not initialized")
 	}
 
-	rows, err := queries.GetTranslationsByLanguage(ctx, db.GetTranslationsByLanguageParams{
+	rows, err := s.queries.GetTranslationsByLanguage(ctx, db.GetTranslationsByLanguageParams{
 		StoryID:      int32(storyID),
 		LanguageCode: languageCode,
 	})
@@ -105,25 +129,29 @@ func GetTranslationsByLanguage(ctx context.Context, storyID int, languageCode st
 }
 
 // DeleteLineTranslation removes a specific translation
-func DeleteLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode string) error {
-	if queries == nil {
+func (s *Service) DeleteLineTranslation(ctx context.Context, storyID, lineNumber int, languageCode string) error {
+	if s.queries == nil {
 		return errors.New("database not initialized")
 	}
 
-	return queries.DeleteLineTranslation(ctx, db.DeleteLineTranslationParams{
-		StoryID:      int32(storyID),
-		LineNumber:   int32(lineNumber),
-		LanguageCode: languageCode,
+	return s.withStoryRevision(ctx, storyID, "delete translation", func(txCtx context.Context) error {
+		return s.queries.DeleteLineTranslation(txCtx, db.DeleteLineTranslationParams{
+			StoryID:      int32(storyID),
+			LineNumber:   int32(lineNumber),
+			LanguageCode: languageCode,
+		})
 	})
 }
 
 // DeleteStoryTranslations removes all translations for a story
-func DeleteStoryTranslations(ctx context.Context, storyID int) error {
-	if queries == nil {
+func (s *Service) DeleteStoryTranslations(ctx context.Context, storyID int) error {
+	if s.queries == nil {
 		return errors.New("database not initialized")
 	}
 
-	return queries.DeleteAllTranslationsForStory(ctx, int32(storyID))
+	return s.withStoryRevision(ctx, storyID, "delete translations", func(txCtx context.Context) error {
+		return s.queries.DeleteAllTranslationsForStory(txCtx, int32(storyID))
+	})
 }
 
 // TranslationRequest represents a user's translation request for a story
@@ -136,12 +164,12 @@ type TranslationRequest struct {
 }
 
 // GetTranslationRequest retrieves a user's translation request for a story
-func GetTranslationRequest(ctx context.Context, userID string, storyID int) (*TranslationRequest, error) {
-	if queries == nil {
+func (s *Service) GetTranslationRequest(ctx context.Context, userID string, storyID int) (*TranslationRequest, error) {
+	if s.queries == nil {
 		return nil, errors.New("database not initialized")
 	}
 
-	row, err := queries.GetTranslationRequest(ctx, db.GetTranslationRequestParams{
+	row, err := s.queries.GetTranslationRequest(ctx, db.GetTranslationRequestParams{
 		UserID:  userID,
 		StoryID: int32(storyID),
 	})
@@ -163,8 +191,8 @@ func GetTranslationRequest(ctx context.Context, userID string, storyID int) (*Tr
 }
 
 // CreateTranslationRequest creates a new translation request for a user and story
-func CreateTranslationRequest(ctx context.Context, userID string, storyID int, requestedLines []int) (*TranslationRequest, error) {
-	if queries == nil {
+func (s *Service) CreateTranslationRequest(ctx context.Context, userID string, storyID int, requestedLines []int) (*TranslationRequest, error) {
+	if s.queries == nil {
 		return nil, errors.New("database not initialized")
 	}
 
@@ -174,7 +202,7 @@ func CreateTranslationRequest(ctx context.Context, userID string, storyID int, r
 		lines[i] = int32(line)
 	}
 
-	row, err := queries.CreateTranslationRequest(ctx, db.CreateTranslationRequestParams{
+	row, err := s.queries.CreateTranslationRequest(ctx, db.CreateTranslationRequestParams{
 		UserID:         userID,
 		StoryID:        int32(storyID),
 		RequestedLines: lines,
@@ -183,6 +211,7 @@ func CreateTranslationRequest(ctx context.Context, userID string, storyID int, r
 	if err != nil {
 		return nil, err
 	}
+	s.InvalidateStoryCourseAnalytics(ctx, storyID)
 
 	return &TranslationRequest{
 		RequestID:      row.RequestID,
@@ -193,8 +222,8 @@ func CreateTranslationRequest(ctx context.Context, userID string, storyID int, r
 	}, nil
 }
 
-func UpdateTranslationRequest(ctx context.Context, userID string, storyID int, combinedLines []int32) error {
-	return queries.UpdateTranslationRequest(ctx, db.UpdateTranslationRequestParams{
+func (s *Service) UpdateTranslationRequest(ctx context.Context, userID string, storyID int, combinedLines []int32) error {
+	return s.queries.UpdateTranslationRequest(ctx, db.UpdateTranslationRequestParams{
 		UserID:         userID,
 		StoryID:        int32(storyID),
 		RequestedLines: combinedLines,
@@ -202,12 +231,12 @@ func UpdateTranslationRequest(ctx context.Context, userID string, storyID int, c
 }
 
 // TranslationRequestExists checks if a translation request exists for a user and story
-func TranslationRequestExists(ctx context.Context, userID string, storyID int) (bool, error) {
-	if queries == nil {
+func (s *Service) TranslationRequestExists(ctx context.Context, userID string, storyID int) (bool, error) {
+	if s.queries == nil {
 		return false, errors.New("database not initialized")
 	}
 
-	exists, err := queries.TranslationRequestExists(ctx, db.TranslationRequestExistsParams{
+	exists, err := s.queries.TranslationRequestExists(ctx, db.TranslationRequestExistsParams{
 		UserID:  userID,
 		StoryID: int32(storyID),
 	})
@@ -0,0 +1,229 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrCourseInTrash is returned when restoring a story whose course is in the trash;
+// the course has to be restored first
+var ErrCourseInTrash = errors.New("the story's course is in the trash")
+
+// TrashedStory is a story that was deleted and can still be restored
+type TrashedStory struct {
+	StoryID       int       `json:"storyId"`
+	Title         string    `json:"title"`
+	WeekNumber    int       `json:"weekNumber"`
+	DayLetter     string    `json:"dayLetter"`
+	CourseID      *int      `json:"courseId,omitempty"`
+	CourseTrashed bool      `json:"courseTrashed"`
+	DeletedAt     time.Time `json:"deletedAt"`
+}
+
+// TrashedCourse is a course that was deleted, with the number of stories trashed along with it
+type TrashedCourse struct {
+	CourseID     int32     `json:"courseId"`
+	CourseNumber string    `json:"courseNumber"`
+	Name         string    `json:"name"`
+	StoryCount   int       `json:"storyCount"`
+	DeletedAt    time.Time `json:"deletedAt"`
+}
+
+// Trash lists deleted stories and courses, most recently deleted first
+type Trash struct {
+	Stories []TrashedStory  `json:"stories"`
+	Courses []TrashedCourse `json:"courses"`
+}
+
+// GetTrash returns what the user may restore: everything for super admins, and the
+// trashed stories of the courses they administer for course admins
+func (s *Service) GetTrash(ctx context.Context, userID string) (*Trash, error) {
+	trash := &Trash{Stories: []TrashedStory{}, Courses: []TrashedCourse{}}
+	superAdmin := s.IsUserSuperAdmin(ctx, userID)
+	adminOf := make(map[int32]bool)
+	if !superAdmin {
+		rights, err := s.GetUserCourseAdminRights(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			adminOf[right.CourseID] = true
+		}
+	}
+
+	stories, err := s.queries.ListTrashedStories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, story := range stories {
+		if !superAdmin && !(story.CourseID.Valid && adminOf[story.CourseID.Int32]) {
+			continue
+		}
+		trashed := TrashedStory{
+			StoryID:       int(story.StoryID),
+			Title:         story.Title,
+			WeekNumber:    int(story.WeekNumber),
+			DayLetter:     story.DayLetter,
+			CourseTrashed: story.CourseTrashed,
+			DeletedAt:     story.DeletedAt.Time,
+		}
+		if story.CourseID.Valid {
+			courseID := int(story.CourseID.Int32)
+			trashed.CourseID = &courseID
+		}
+		trash.Stories = append(trash.Stories, trashed)
+	}
+
+	if !superAdmin {
+		return trash, nil
+	}
+	courses, err := s.queries.ListTrashedCourses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, course := range courses {
+		trash.Courses = append(trash.Courses, TrashedCourse{
+			CourseID:     course.CourseID,
+			CourseNumber: course.CourseNumber,
+			Name:         course.Name,
+			StoryCount:   int(course.StoryCount),
+			DeletedAt:    course.DeletedAt.Time,
+		})
+	}
+	return trash, nil
+}
+
+// RestoreStory takes a story out of the trash
+func (s *Service) RestoreStory(ctx context.Context, storyID int) error {
+	trashed, err := s.queries.GetTrashedStory(ctx, int32(storyID))
+	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if trashed.CourseTrashed {
+		return ErrCourseInTrash
+	}
+
+	if err := s.queries.RestoreStory(ctx, int32(storyID)); err != nil {
+		return err
+	}
+	s.InvalidateStoryMetadata(storyID)
+	s.InvalidateStoryCourseAnalytics(ctx, storyID)
+	return nil
+}
+
+// RestoreCourse takes a course out of the trash with the stories that were trashed
+// along with it. Stories deleted on their own before the course stay in the trash.
+// It returns ErrCourseNumberTaken if another course has taken the course's number since.
+func (s *Service) RestoreCourse(ctx context.Context, courseID int32) error {
+	err := s.withTransaction(ctx, func(txCtx context.Context) error {
+		taken, err := s.queries.IsTrashedCourseNumberTaken(txCtx, courseID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrCourseNumberTaken
+		}
+
+		// Stories first, while the course still has the deleted_at they share
+		if err := s.queries.RestoreCourseStories(txCtx, courseID); err != nil {
+			return err
+		}
+		restored, err := s.queries.RestoreCourse(txCtx, courseID)
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.InvalidateCourseAnalytics(int(courseID))
+	return nil
+}
+
+// PurgeExpiredTrash permanently deletes the stories and courses that have been in the
+// trash longer than retention, with their files unless another story still uses them.
+// A failing purge does not stop the others; it returns how many stories and courses
+// were purged and the errors joined.
+func (s *Service) PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int, int, error) {
+	cutoff := pgtype.Timestamp{Time: s.clock.Now().Add(-retention).UTC(), Valid: true}
+	var errs []error
+
+	courseIDs, err := s.queries.GetExpiredTrashedCourseIDs(ctx, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	purgedCourses := 0
+	for _, courseID := range courseIDs {
+		if err := s.purgeCourse(ctx, courseID); err != nil {
+			errs = append(errs, fmt.Errorf("course %d: %w", courseID, err))
+			continue
+		}
+		purgedCourses++
+	}
+
+	// Read after the courses, whose stories are already gone
+	storyIDs, err := s.queries.GetExpiredTrashedStoryIDs(ctx, cutoff)
+	if err != nil {
+		return 0, purgedCourses, errors.Join(append(errs, err)...)
+	}
+	purgedStories := 0
+	for _, storyID := range storyIDs {
+		if err := s.purgeStory(ctx, int(storyID)); err != nil {
+			errs = append(errs, fmt.Errorf("story %d: %w", storyID, err))
+			continue
+		}
+		purgedStories++
+	}
+	return purgedStories, purgedCourses, errors.Join(errs...)
+}
+
+// purgeCourse permanently deletes a course and every story in it
+func (s *Service) purgeCourse(ctx context.Context, courseID int32) error {
+	// Stories are deleted first: the course's own delete would only detach them
+	storyIDs, err := s.queries.GetCourseStoryIDs(ctx, pgtype.Int4{Int32: courseID, Valid: true})
+	if err != nil {
+		return err
+	}
+	for _, storyID := range storyIDs {
+		if err := s.purgeStory(ctx, int(storyID)); err != nil {
+			return fmt.Errorf("story %d: %w", storyID, err)
+		}
+	}
+	return s.queries.DeleteCourse(ctx, courseID)
+}
+
+// StartTrashPurge runs PurgeExpiredTrash every interval until ctx is cancelled
+func (s *Service) StartTrashPurge(ctx context.Context, interval, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stories, courses, err := s.PurgeExpiredTrash(ctx, retention)
+				if err != nil {
+					fmt.Printf("Failed to purge trash: %v\n", err)
+				}
+				if stories > 0 || courses > 0 {
+					fmt.Printf("Purged %d stories and %d courses from the trash\n", stories, courses)
+				}
+			}
+		}
+	}()
+}
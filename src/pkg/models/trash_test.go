@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"glossias/src/pkg/database"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestGetTrashForCourseAdmin(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetUserCourseAdminRights", [][]interface{}{
+		{int32(3), pgtype.Timestamp{}, "HEB101", "Biblical Hebrew"},
+	}, nil)
+	trashed := func(storyID, courseID int32) []interface{} {
+		return []interface{}{storyID, int32(1), "a", pgtype.Int4{Int32: courseID, Valid: courseID != 0}, "Title", pgtype.Timestamp{Valid: true}, false}
+	}
+	mock.StubQuery("-- name: ListTrashedStories", [][]interface{}{trashed(10, 3), trashed(11, 4), trashed(12, 0)}, nil)
+	mock.StubQuery("-- name: ListTrashedCourses", [][]interface{}{
+		{int32(4), "HEB102", "Hebrew Prose", pgtype.Timestamp{Valid: true}, int64(2)},
+	}, nil)
+	svc := NewService(mock, nil, nil, nil)
+
+	trash, err := svc.GetTrash(context.Background(), "admin-of-3")
+	if err != nil {
+		t.Fatalf("GetTrash failed: %v", err)
+	}
+	if len(trash.Stories) != 1 || trash.Stories[0].StoryID != 10 {
+		t.Errorf("Expected only story 10 of course 3, got %+v", trash.Stories)
+	}
+	if len(trash.Courses) != 0 {
+		t.Errorf("Expected course admins not to see trashed courses, got %+v", trash.Courses)
+	}
+}
+
+func TestRestoreStoryOfTrashedCourse(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetTrashedStory", [][]interface{}{
+		{int32(10), pgtype.Int4{Int32: 3, Valid: true}, pgtype.Timestamp{Valid: true}, true},
+	}, nil)
+	svc := NewService(mock, nil, nil, nil)
+	if err := svc.RestoreStory(context.Background(), 10); err != ErrCourseInTrash {
+		t.Errorf("Expected ErrCourseInTrash, got %v", err)
+	}
+
+	svc = NewService(database.NewMockDBTX(), nil, nil, nil)
+	if err := svc.RestoreStory(context.Background(), 10); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a story not in the trash, got %v", err)
+	}
+}
+
+func TestRestoreCourseWithTakenNumber(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: IsTrashedCourseNumberTaken", [][]interface{}{{true}}, nil)
+	mock.StubExec("-- name: RestoreCourseStories", errors.New("stories restored"))
+	svc := NewService(mock, nil, nil, nil)
+	if err := svc.RestoreCourse(context.Background(), 3); err != ErrCourseNumberTaken {
+		t.Errorf("Expected ErrCourseNumberTaken, got %v", err)
+	}
+
+	mock.StubQuery("-- name: IsTrashedCourseNumberTaken", [][]interface{}{{false}}, nil)
+	mock.StubExec("-- name: RestoreCourseStories", nil)
+	if err := svc.RestoreCourse(context.Background(), 3); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a course not in the trash, got %v", err)
+	}
+}
+
+func TestTrashIsOffLimitsToSuperAdmins(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetUser :one", [][]interface{}{
+		{"root", "root@example.com", "Root", pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamp{}, pgtype.Timestamp{}},
+	}, nil)
+	mock.StubQuery("-- name: GetTrashedStory", [][]interface{}{
+		{int32(10), pgtype.Int4{Int32: 3, Valid: true}, pgtype.Timestamp{Valid: true}, false},
+	}, nil)
+	svc := NewService(mock, nil, nil, nil)
+	ctx := context.Background()
+
+	// GetCourseIdForStory and GetCourse skip trashed rows, so nothing is found
+	if svc.CanUserEditStory(ctx, "root", 10) {
+		t.Error("Expected a trashed story not to be editable")
+	}
+	if svc.IsUserCourseOrSuperAdmin(ctx, "root", 3) {
+		t.Error("Expected a trashed course not to be administrable")
+	}
+	if !svc.CanUserRestoreStory(ctx, "root", 10) {
+		t.Error("Expected a super admin to be able to restore the story")
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetExpiredTrashedCourseIDs", [][]interface{}{{int32(3)}}, nil)
+	mock.StubQuery("-- name: GetCourseStoryIDs", [][]interface{}{{int32(10)}}, nil)
+	mock.StubQuery("-- name: GetExpiredTrashedStoryIDs", [][]interface{}{{int32(11)}}, nil)
+	svc := NewService(mock, nil, nil, nil)
+
+	stories, courses, err := svc.PurgeExpiredTrash(context.Background(), 0)
+	if err != nil || stories != 1 || courses != 1 {
+		t.Errorf("Expected 1 story and 1 course purged, got %d, %d, %v", stories, courses, err)
+	}
+
+	// A course that fails to purge does not stop the stories
+	failure := errors.New("connection reset")
+	mock.StubExec("DELETE FROM courses", failure)
+	stories, courses, err = svc.PurgeExpiredTrash(context.Background(), 0)
+	if !errors.Is(err, failure) || stories != 1 || courses != 0 {
+		t.Errorf("Expected 1 story, no course and the course error, got %d, %d, %v", stories, courses, err)
+	}
+}
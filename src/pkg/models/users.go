@@ -30,8 +30,8 @@ type CourseAdminRight struct {
 }
 
 // UpsertUser creates or updates a user record
-func UpsertUser(ctx context.Context, userID, email, name string) (*User, error) {
-	result, err := queries.UpsertUser(ctx, db.UpsertUserParams{
+func (s *Service) UpsertUser(ctx context.Context, userID, email, name string) (*User, error) {
+	result, err := s.queries.UpsertUser(ctx, db.UpsertUserParams{
 		UserID:       userID,
 		Email:        email,
 		Name:         name,
@@ -52,8 +52,8 @@ func UpsertUser(ctx context.Context, userID, email, name string) (*User, error)
 }
 
 // GetUser retrieves a user by ID
-func GetUser(ctx context.Context, userID string) (*User, error) {
-	result, err := queries.GetUser(ctx, userID)
+func (s *Service) GetUser(ctx context.Context, userID string) (*User, error) {
+	result, err := s.queries.GetUser(ctx, userID)
 	if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 		return nil, ErrNotFound
 	}
@@ -72,8 +72,8 @@ func GetUser(ctx context.Context, userID string) (*User, error) {
 }
 
 // CanUserAccessCourse checks if user can access a specific course (non-admin)
-func CanUserAccessCourse(ctx context.Context, userID string, courseID int32) bool {
-	canAccess, err := queries.CanUserAccessCourse(ctx, db.CanUserAccessCourseParams{
+func (s *Service) CanUserAccessCourse(ctx context.Context, userID string, courseID int32) bool {
+	canAccess, err := s.queries.CanUserAccessCourse(ctx, db.CanUserAccessCourseParams{
 		UserID:   userID,
 		CourseID: courseID,
 	})
@@ -81,62 +81,82 @@ func CanUserAccessCourse(ctx context.Context, userID string, courseID int32) boo
 }
 
 // IsUserAdmin checks if user is admin of any course or super admin
-func IsUserAnyAdmin(ctx context.Context, userID string) bool {
+func (s *Service) IsUserAnyAdmin(ctx context.Context, userID string) bool {
 	// Check if super admin
-	user, err := queries.GetUser(ctx, userID)
+	user, err := s.queries.GetUser(ctx, userID)
 	if err == nil && user.IsSuperAdmin.Bool {
 		return true
 	}
 
 	// Check if course admin
-	isAdmin, err := queries.IsUserAdminOfAnyCourse(ctx, userID)
+	isAdmin, err := s.queries.IsUserAdminOfAnyCourse(ctx, userID)
 	return err == nil && isAdmin
 }
 
 // IsUserCourseAdmin checks if user is admin of a specific course
-func IsUserOnlyCourseAdmin(ctx context.Context, userID string, courseID int32) bool {
-	isAdmin, err := queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
+func (s *Service) IsUserOnlyCourseAdmin(ctx context.Context, userID string, courseID int32) bool {
+	isAdmin, err := s.queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
 		CourseID: courseID,
 		UserID:   userID,
 	})
 	return err == nil && isAdmin
 }
 
-// IsUserCourseOrSuperAdmin checks if user is admin of a specific course or super admin
-func IsUserCourseOrSuperAdmin(ctx context.Context, userID string, courseID int32) bool {
+// IsUserCourseOrSuperAdmin checks if user is admin of a specific course or super admin.
+// Courses in the trash are off limits to both; only restore and purge reach them.
+func (s *Service) IsUserCourseOrSuperAdmin(ctx context.Context, userID string, courseID int32) bool {
 	// Check if super admin
-	user, err := queries.GetUser(ctx, userID)
+	user, err := s.queries.GetUser(ctx, userID)
 	if err == nil && user.IsSuperAdmin.Bool {
-		return true
+		_, err := s.queries.GetCourse(ctx, courseID)
+		return err == nil
 	}
 
 	// Check if course admin
-	isAdmin, err := queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
+	isAdmin, err := s.queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
 		CourseID: courseID,
 		UserID:   userID,
 	})
 	return err == nil && isAdmin
 }
 
-// CanUserEditStory checks if user can edit a specific story
-func CanUserEditStory(ctx context.Context, userID string, storyID int32) bool {
-	if IsUserSuperAdmin(ctx, userID) {
+// CanUserEditStory checks if user can edit a specific story. Stories in the trash
+// cannot be edited; CanUserRestoreStory covers them.
+func (s *Service) CanUserEditStory(ctx context.Context, userID string, storyID int32) bool {
+	courseID, err := s.queries.GetCourseIdForStory(ctx, storyID)
+	if err != nil {
+		return false
+	}
+	if s.IsUserSuperAdmin(ctx, userID) {
 		return true
 	}
-	courseID, err := queries.GetCourseIdForStory(ctx, storyID)
+	isAdmin, err := s.queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
+		CourseID: courseID.Int32,
+		UserID:   userID,
+	})
+	return err == nil && isAdmin
+}
+
+// CanUserRestoreStory checks if user can take a story out of the trash: super admins,
+// and admins of the story's course while the course itself is not in the trash
+func (s *Service) CanUserRestoreStory(ctx context.Context, userID string, storyID int32) bool {
+	trashed, err := s.queries.GetTrashedStory(ctx, storyID)
 	if err != nil {
 		return false
 	}
-	isAdmin, err := queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
-		CourseID: courseID.Int32,
+	if s.IsUserSuperAdmin(ctx, userID) {
+		return true
+	}
+	isAdmin, err := s.queries.IsUserCourseAdmin(ctx, db.IsUserCourseAdminParams{
+		CourseID: trashed.CourseID.Int32,
 		UserID:   userID,
 	})
 	return err == nil && isAdmin
 }
 
 // GetUserCourseAdminRights returns all courses a user is admin of
-func GetUserCourseAdminRights(ctx context.Context, userID string) ([]CourseAdminRight, error) {
-	results, err := queries.GetUserCourseAdminRights(ctx, userID)
+func (s *Service) GetUserCourseAdminRights(ctx context.Context, userID string) ([]CourseAdminRight, error) {
+	results, err := s.queries.GetUserCourseAdminRights(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
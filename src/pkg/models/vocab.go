@@ -14,8 +14,8 @@ type VocabItem struct {
 }
 
 // GetLinesWithoutVocabForUser returns line and position pairs that have not been correctly completed by the user
-func GetLinesWithoutVocabForUser(ctx context.Context, userID string, storyID int) ([]VocabItem, error) {
-	rows, err := queries.GetIncompleteVocabForUser(ctx, db.GetIncompleteVocabForUserParams{
+func (s *Service) GetLinesWithoutVocabForUser(ctx context.Context, userID string, storyID int) ([]VocabItem, error) {
+	rows, err := s.queries.GetIncompleteVocabForUser(ctx, db.GetIncompleteVocabForUserParams{
 		StoryID: pgtype.Int4{Int32: int32(storyID), Valid: true},
 		UserID:  userID,
 	})
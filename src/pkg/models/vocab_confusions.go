@@ -0,0 +1,338 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"glossias/src/pkg/generated/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalidVocabContrast    = errors.New("a contrast pair needs two different lexical forms")
+	ErrVocabContrastNotInStory = errors.New("at least one form of a contrast pair must be a lexical form of the story")
+)
+
+// VocabConfusion counts how often students gave Answer where LexicalForm was expected
+type VocabConfusion struct {
+	LexicalForm string `json:"lexicalForm"`
+	Answer      string `json:"answer"`
+	Count       int    `json:"count"`
+	Students    int    `json:"students"`
+	InBank      bool   `json:"inBank"`   // Answer is itself a lexical form of the story (or course), not a typo
+	Contrast    bool   `json:"contrast"` // An admin marked the two forms as an intentional contrast
+}
+
+// VocabConfusionMatrix shows which lexical forms students confuse with which.
+// Counts[i][j] is how often Answers[j] was given where Forms[i] was expected.
+type VocabConfusionMatrix struct {
+	Forms      []string            `json:"forms"`      // Most missed first
+	Answers    []string            `json:"answers"`    // Lexical forms first, then other answers, most given first
+	Counts     [][]int             `json:"counts"`     // One row per form, one column per answer
+	Confusions []VocabConfusion    `json:"confusions"` // The non-zero cells, most frequent first
+	Contrasts  []VocabContrastPair `json:"contrasts"`
+}
+
+// vocabBankOrderTTL is how long a story's vocab bank order is reused before the mix-ups
+// in newer answers are taken into account
+const vocabBankOrderTTL = 10 * time.Minute
+
+// vocabBankOrder is a cached vocab bank order and the forms it was built from
+type vocabBankOrder struct {
+	Forms      []string  `json:"forms"`
+	Order      []string  `json:"order"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// VocabContrastPair is a pair of lexical forms an admin wants students to tell apart.
+// FormA sorts before FormB.
+type VocabContrastPair struct {
+	ID      int    `json:"id"`
+	StoryID int    `json:"storyId"`
+	FormA   string `json:"formA"`
+	FormB   string `json:"formB"`
+}
+
+// GetStoryVocabConfusions builds the confusion matrix of a story's wrong vocab answers
+func (s *Service) GetStoryVocabConfusions(ctx context.Context, storyID int) (*VocabConfusionMatrix, error) {
+	rows, err := s.queries.GetStoryVocabConfusions(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	forms, err := s.queries.GetStoryLexicalForms(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	contrasts, err := s.queries.GetStoryVocabContrasts(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+
+	confusions := make([]VocabConfusion, len(rows))
+	for i, row := range rows {
+		confusions[i] = VocabConfusion{
+			LexicalForm: row.LexicalForm,
+			Answer:      row.IncorrectAnswer,
+			Count:       int(row.Times),
+			Students:    int(row.Students),
+		}
+	}
+	return buildVocabConfusionMatrix(confusions, forms, contrasts), nil
+}
+
+// GetCourseVocabConfusions builds the confusion matrix of wrong vocab answers across a
+// course's stories, counting students with the given course status ("" for all).
+// A pair counts as a contrast if any story of the course marks it.
+func (s *Service) GetCourseVocabConfusions(ctx context.Context, courseID int32, status string) (*VocabConfusionMatrix, error) {
+	rows, err := s.queries.GetCourseVocabConfusions(ctx, db.GetCourseVocabConfusionsParams{CourseID: courseID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+	forms, err := s.queries.GetCourseLexicalForms(ctx, pgtype.Int4{Int32: courseID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	contrasts, err := s.queries.GetCourseVocabContrasts(ctx, pgtype.Int4{Int32: courseID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	confusions := make([]VocabConfusion, len(rows))
+	for i, row := range rows {
+		confusions[i] = VocabConfusion{
+			LexicalForm: row.LexicalForm,
+			Answer:      row.IncorrectAnswer,
+			Count:       int(row.Times),
+			Students:    int(row.Students),
+		}
+	}
+	return buildVocabConfusionMatrix(confusions, forms, contrasts), nil
+}
+
+// GetStoryVocabContrasts returns the contrast pairs of a story
+func (s *Service) GetStoryVocabContrasts(ctx context.Context, storyID int) ([]VocabContrastPair, error) {
+	results, err := s.queries.GetStoryVocabContrasts(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	return vocabContrastsFromDB(results), nil
+}
+
+// CreateVocabContrast marks two lexical forms as an intentional contrast in a story.
+// Marking a pair twice returns the existing pair.
+func (s *Service) CreateVocabContrast(ctx context.Context, storyID int, form, other string) (*VocabContrastPair, error) {
+	formA, formB := contrastForms(strings.TrimSpace(form), strings.TrimSpace(other))
+	if formA == "" || formA == formB {
+		return nil, ErrInvalidVocabContrast
+	}
+
+	forms, err := s.queries.GetStoryLexicalForms(ctx, pgtype.Int4{Int32: int32(storyID), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(forms, formA) && !slices.Contains(forms, formB) {
+		return nil, ErrVocabContrastNotInStory
+	}
+
+	result, err := s.queries.CreateVocabContrast(ctx, db.CreateVocabContrastParams{
+		StoryID: int32(storyID),
+		FormA:   formA,
+		FormB:   formB,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.InvalidateVocabBankOrder(storyID)
+
+	pair := vocabContrastsFromDB([]db.VocabContrastPair{result})[0]
+	return &pair, nil
+}
+
+// DeleteVocabContrast removes a contrast pair, or returns ErrNotFound if it does not belong to the story
+func (s *Service) DeleteVocabContrast(ctx context.Context, storyID, pairID int) error {
+	deleted, err := s.queries.DeleteVocabContrast(ctx, db.DeleteVocabContrastParams{
+		ID:      int32(pairID),
+		StoryID: int32(storyID),
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	s.InvalidateVocabBankOrder(storyID)
+	return nil
+}
+
+// OrderVocabBank orders the lexical forms of a story's vocab bank for students.
+// Forms in a contrast pair come first, with the other form of each pair added as a
+// distractor when the story does not use it, followed by the forms students most often
+// mix up with one another. Ties are alphabetical. The order is cached per story until
+// its contrasts or forms change, and rebuilt after vocabBankOrderTTL.
+func (s *Service) OrderVocabBank(ctx context.Context, storyID int, forms []string) ([]string, error) {
+	cacheKey := ""
+	if s.cache != nil && s.keys != nil {
+		cacheKey = s.keys.VocabBankOrder(storyID)
+		var cached vocabBankOrder
+		if err := s.cache.GetJSON(cacheKey, &cached); err == nil &&
+			slices.Equal(cached.Forms, forms) && s.clock.Now().Sub(cached.ComputedAt) < vocabBankOrderTTL {
+			return cached.Order, nil
+		}
+	}
+
+	confusions, err := s.queries.GetStoryVocabConfusions(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	contrasts, err := s.queries.GetStoryVocabContrasts(ctx, int32(storyID))
+	if err != nil {
+		return nil, err
+	}
+	order := orderVocabBank(forms, confusions, contrasts)
+	if cacheKey != "" {
+		_ = s.cache.SetJSON(cacheKey, vocabBankOrder{Forms: forms, Order: order, ComputedAt: s.clock.Now()})
+	}
+	return order, nil
+}
+
+func orderVocabBank(forms []string, confusions []db.GetStoryVocabConfusionsRow, contrasts []db.VocabContrastPair) []string {
+	bank := slices.Clone(forms)
+	inBank := make(map[string]bool, len(forms))
+	for _, form := range forms {
+		inBank[form] = true
+	}
+
+	contrasted := make(map[string]bool)
+	for _, pair := range contrasts {
+		// Pairs outlive annotation edits; skip those the story no longer uses
+		if !inBank[pair.FormA] && !inBank[pair.FormB] {
+			continue
+		}
+		for _, form := range []string{pair.FormA, pair.FormB} {
+			contrasted[form] = true
+			if !inBank[form] {
+				inBank[form] = true
+				bank = append(bank, form)
+			}
+		}
+	}
+
+	// Only mix-ups between two forms of the bank say anything about its order
+	confused := make(map[string]int)
+	for _, row := range confusions {
+		if inBank[row.LexicalForm] && inBank[row.IncorrectAnswer] {
+			confused[row.LexicalForm] += int(row.Times)
+			confused[row.IncorrectAnswer] += int(row.Times)
+		}
+	}
+
+	slices.SortFunc(bank, func(a, b string) int {
+		if contrasted[a] != contrasted[b] {
+			if contrasted[a] {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(confused[b], confused[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return slices.Compact(bank)
+}
+
+// buildVocabConfusionMatrix lays counted confusions out as a matrix. forms are the lexical
+// forms answers are checked against to tell mix-ups from typos.
+func buildVocabConfusionMatrix(confusions []VocabConfusion, forms []string, contrasts []db.VocabContrastPair) *VocabConfusionMatrix {
+	isForm := make(map[string]bool, len(forms))
+	for _, form := range forms {
+		isForm[form] = true
+	}
+	contrasted := make(map[[2]string]bool, len(contrasts))
+	for _, pair := range contrasts {
+		contrasted[[2]string{pair.FormA, pair.FormB}] = true
+	}
+
+	misses := make(map[string]int)
+	given := make(map[string]int)
+	for i := range confusions {
+		confusion := &confusions[i]
+		confusion.InBank = isForm[confusion.Answer]
+		formA, formB := contrastForms(confusion.LexicalForm, confusion.Answer)
+		confusion.Contrast = contrasted[[2]string{formA, formB}]
+		misses[confusion.LexicalForm] += confusion.Count
+		given[confusion.Answer] += confusion.Count
+	}
+
+	matrix := &VocabConfusionMatrix{
+		Forms:      sortedByCount(misses, nil),
+		Answers:    sortedByCount(given, isForm),
+		Confusions: confusions,
+		Contrasts:  vocabContrastsFromDB(contrasts),
+	}
+
+	row := make(map[string]int, len(matrix.Forms))
+	for i, form := range matrix.Forms {
+		row[form] = i
+	}
+	column := make(map[string]int, len(matrix.Answers))
+	for j, answer := range matrix.Answers {
+		column[answer] = j
+	}
+	matrix.Counts = make([][]int, len(matrix.Forms))
+	for i := range matrix.Counts {
+		matrix.Counts[i] = make([]int, len(matrix.Answers))
+	}
+	for _, confusion := range confusions {
+		matrix.Counts[row[confusion.LexicalForm]][column[confusion.Answer]] += confusion.Count
+	}
+	return matrix
+}
+
+// sortedByCount returns the keys of counts, those in first (if any) before the rest,
+// then most counted first, then alphabetically
+func sortedByCount(counts map[string]int, first map[string]bool) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if first[a] != first[b] {
+			if first[a] {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}
+
+// contrastForms orders the two forms of a pair the way vocab_contrast_pairs stores them
+func contrastForms(a, b string) (string, string) {
+	if b < a {
+		return b, a
+	}
+	return a, b
+}
+
+func vocabContrastsFromDB(results []db.VocabContrastPair) []VocabContrastPair {
+	pairs := make([]VocabContrastPair, len(results))
+	for i, result := range results {
+		pairs[i] = VocabContrastPair{
+			ID:      int(result.ID),
+			StoryID: int(result.StoryID),
+			FormA:   result.FormA,
+			FormB:   result.FormB,
+		}
+	}
+	return pairs
+}
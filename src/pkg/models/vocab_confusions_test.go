@@ -0,0 +1,136 @@
+package models
+
+import (
+	"context"
+	"glossias/src/pkg/cache"
+	"glossias/src/pkg/database"
+	"glossias/src/pkg/generated/db"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestOrderVocabBank(t *testing.T) {
+	forms := []string{"ser", "tener", "estar", "ir", "ser"}
+	confusions := []db.GetStoryVocabConfusionsRow{
+		{LexicalForm: "ir", IncorrectAnswer: "tener", Times: 5},
+		{LexicalForm: "tener", IncorrectAnswer: "ir", Times: 1},
+		{LexicalForm: "estar", IncorrectAnswer: "estra", Times: 40}, // Typo, not a mix-up
+	}
+	contrasts := []db.VocabContrastPair{
+		{FormA: "haber", FormB: "ser"},   // haber joins the bank as a distractor
+		{FormA: "poder", FormB: "saber"}, // Neither form is in the story any more
+	}
+
+	bank := orderVocabBank(forms, confusions, contrasts)
+
+	expected := []string{"haber", "ser", "ir", "tener", "estar"}
+	if !slices.Equal(bank, expected) {
+		t.Errorf("Expected %v, got %v", expected, bank)
+	}
+
+	// Without any answers the bank is alphabetical, as before
+	if bank := orderVocabBank(forms, nil, nil); !slices.Equal(bank, []string{"estar", "ir", "ser", "tener"}) {
+		t.Errorf("Expected an alphabetical bank, got %v", bank)
+	}
+}
+
+func TestBuildVocabConfusionMatrix(t *testing.T) {
+	confusions := []VocabConfusion{
+		{LexicalForm: "ir", Answer: "ser", Count: 6, Students: 3},
+		{LexicalForm: "ser", Answer: "estar", Count: 4, Students: 2},
+		{LexicalForm: "ir", Answer: "irr", Count: 2, Students: 1},
+		{LexicalForm: "ser", Answer: "ir", Count: 1, Students: 1},
+	}
+	forms := []string{"estar", "ir", "ser"}
+	contrasts := []db.VocabContrastPair{{ID: 7, StoryID: 3, FormA: "estar", FormB: "ser"}}
+
+	matrix := buildVocabConfusionMatrix(confusions, forms, contrasts)
+
+	if !slices.Equal(matrix.Forms, []string{"ir", "ser"}) {
+		t.Errorf("Expected the most missed form first, got %v", matrix.Forms)
+	}
+	if !slices.Equal(matrix.Answers, []string{"ser", "estar", "ir", "irr"}) {
+		t.Errorf("Expected lexical forms before other answers, got %v", matrix.Answers)
+	}
+	expected := [][]int{
+		{6, 0, 0, 2},
+		{0, 4, 1, 0},
+	}
+	for i := range expected {
+		if !slices.Equal(matrix.Counts[i], expected[i]) {
+			t.Errorf("Row %d: expected %v, got %v", i, expected[i], matrix.Counts[i])
+		}
+	}
+
+	if !matrix.Confusions[1].Contrast || matrix.Confusions[0].Contrast {
+		t.Errorf("Expected only ser/estar to be a contrast, got %+v", matrix.Confusions)
+	}
+	if !matrix.Confusions[0].InBank || matrix.Confusions[2].InBank {
+		t.Errorf("Expected irr to be told apart from a lexical form, got %+v", matrix.Confusions)
+	}
+	if len(matrix.Contrasts) != 1 || matrix.Contrasts[0] != (VocabContrastPair{ID: 7, StoryID: 3, FormA: "estar", FormB: "ser"}) {
+		t.Errorf("Unexpected contrasts %+v", matrix.Contrasts)
+	}
+}
+
+func TestCreateVocabContrastValidation(t *testing.T) {
+	mockDBTX := database.NewMockDBTX()
+	mockDBTX.StubQuery("SELECT DISTINCT lexical_form", [][]interface{}{{"ser"}, {"ir"}}, nil)
+	mockDBTX.StubQuery("INSERT INTO vocab_contrast_pairs", [][]interface{}{{int32(1), int32(42), "estar", "ser", nil}}, nil)
+	svc := NewService(mockDBTX, nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := svc.CreateVocabContrast(ctx, 42, " ser ", "ser"); err != ErrInvalidVocabContrast {
+		t.Errorf("Expected ErrInvalidVocabContrast for the same form twice, got %v", err)
+	}
+	if _, err := svc.CreateVocabContrast(ctx, 42, "", "ser"); err != ErrInvalidVocabContrast {
+		t.Errorf("Expected ErrInvalidVocabContrast for an empty form, got %v", err)
+	}
+	if _, err := svc.CreateVocabContrast(ctx, 42, "haber", "estar"); err != ErrVocabContrastNotInStory {
+		t.Errorf("Expected ErrVocabContrastNotInStory, got %v", err)
+	}
+
+	pair, err := svc.CreateVocabContrast(ctx, 42, "ser", "estar")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if pair.FormA != "estar" || pair.FormB != "ser" {
+		t.Errorf("Expected the forms in sorted order, got %+v", pair)
+	}
+}
+
+func TestOrderVocabBankIsCached(t *testing.T) {
+	c, err := cache.New(cache.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to initialize cache: %v", err)
+	}
+	mock := database.NewMockDBTX()
+	mock.StubQuery("-- name: GetStoryVocabContrasts", [][]interface{}{
+		{int32(1), int32(7), "ser", "tener", pgtype.Timestamp{}},
+	}, nil)
+	clock := &fakeClock{now: time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)}
+	svc := NewService(mock, nil, c, clock)
+	forms := []string{"estar", "ser"}
+
+	first, err := svc.OrderVocabBank(context.Background(), 7, forms)
+	if err != nil {
+		t.Fatalf("OrderVocabBank failed: %v", err)
+	}
+	// Without the contrast the order would differ, but the cached order is reused
+	mock.StubQuery("-- name: GetStoryVocabContrasts", nil, nil)
+	if cached, _ := svc.OrderVocabBank(context.Background(), 7, forms); !slices.Equal(cached, first) {
+		t.Errorf("Expected the cached order %v, got %v", first, cached)
+	}
+	// A different bank, or the TTL passing, rebuilds it
+	if rebuilt, _ := svc.OrderVocabBank(context.Background(), 7, []string{"ser", "estar"}); slices.Equal(rebuilt, first) {
+		t.Errorf("Expected a new order for new forms, got %v", rebuilt)
+	}
+	svc.OrderVocabBank(context.Background(), 7, forms)
+	clock.now = clock.now.Add(vocabBankOrderTTL)
+	if rebuilt, _ := svc.OrderVocabBank(context.Background(), 7, forms); slices.Equal(rebuilt, first) {
+		t.Errorf("Expected the order to be rebuilt after the TTL, got %v", rebuilt)
+	}
+}
@@ -0,0 +1,85 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testRows = [][]any{
+	{"Name", "Vocab %", "Translation"},
+	{"Dana <admin>", 87.5, true},
+	{"Eli, Jr.", 40, false},
+	{"=HYPERLINK(\"http://x\")", -2, nil},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testRows); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	want := "Name,Vocab %,Translation\nDana <admin>,87.5,true\n\"Eli, Jr.\",40,false\n\"'=HYPERLINK(\"\"http://x\"\")\",-2,\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, "Grades: Spring/Summer", testRows); err != nil {
+		t.Fatalf("WriteXLSX failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Output is not a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Missing %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Grades- Spring-Summer"`) {
+		t.Errorf("Expected sanitized sheet name, got %s", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Dana &lt;admin&gt;</t></is></c>`,
+		`<c r="B2"><v>87.5</v></c>`,
+		`<c r="C2" t="b"><v>1</v></c>`,
+		`<c r="B3"><v>40</v></c>`,
+		`<c r="A4" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://x&#34;)</t></is></c>`,
+		`<c r="B4"><v>-2</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("Expected sheet to contain %s", want)
+		}
+	}
+	if strings.Contains(sheet, `r="C4"`) {
+		t.Errorf("Expected nil cell to be left out, got %s", sheet)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d): expected %s, got %s", index, want, got)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LocalRoutePrefix is where the Go server must mount Local as an http.Handler.
+	// It sits outside /api because signed URLs carry their own authorization.
+	LocalRoutePrefix = "/storage/"
+
+	// Supabase signed upload URLs last two hours; match it
+	localUploadTTL = 2 * time.Hour
+	// Largest object accepted through an upload URL
+	localMaxUploadBytes = 50 << 20
+
+	opRead   = "object"
+	opUpload = "upload"
+)
+
+// Local stores objects on disk under root/<bucket>/<path> and serves them
+// through HMAC-signed, expiring URLs handled by ServeHTTP
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocal creates a disk-backed store. baseURL is the externally reachable
+// origin of this server. An empty secret generates a random one, which means
+// issued URLs stop working when the process restarts.
+func NewLocal(root, baseURL, secret string) (*Local, error) {
+	if root == "" {
+		return nil, errors.New("local storage root is required")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		fmt.Println("LOCAL_STORAGE_SECRET not set - signed storage URLs will not survive a restart")
+	}
+
+	return &Local{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  key,
+		now:     time.Now,
+	}, nil
+}
+
+// SignUploadURL implements Storage
+func (l *Local) SignUploadURL(bucket, objectPath string) (string, error) {
+	return l.signURL(opUpload, bucket, objectPath, localUploadTTL)
+}
+
+// SignReadURL implements Storage
+func (l *Local) SignReadURL(bucket, objectPath string, expiresInSeconds int) (string, error) {
+	return l.signURL(opRead, bucket, objectPath, time.Duration(expiresInSeconds)*time.Second)
+}
+
+// Remove implements Storage
+func (l *Local) Remove(bucket string, paths []string) error {
+	for _, p := range paths {
+		full, err := l.filePath(bucket, p)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(full); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// List implements Storage
+func (l *Local) List(bucket, prefix string) ([]Object, error) {
+	dir, err := l.filePath(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Object{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			UpdatedAt: info.ModTime(),
+		})
+	}
+	return objects, nil
+}
+
+// Stat implements Storage
+func (l *Local) Stat(bucket, objectPath string) (Object, error) {
+	full, err := l.filePath(bucket, objectPath)
+	if err != nil {
+		return Object{}, err
+	}
+
+	info, err := os.Stat(full)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.IsDir()) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{
+		Name:      info.Name(),
+		Size:      info.Size(),
+		UpdatedAt: info.ModTime(),
+	}, nil
+}
+
+// Download implements Storage
+func (l *Local) Download(bucket, objectPath string) ([]byte, error) {
+	full, err := l.filePath(bucket, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(full)
+}
+
+// Upload implements Storage
+func (l *Local) Upload(bucket, objectPath string, data []byte, contentType string) error {
+	full, err := l.filePath(bucket, objectPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(full); err == nil {
+		return fmt.Errorf("object %s/%s already exists", bucket, objectPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, writeErr := tmp.Write(data)
+	if closeErr := tmp.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return os.Rename(tmp.Name(), full)
+}
+
+// ServeHTTP serves signed URLs:
+// GET /storage/object/{bucket}/{path} reads, PUT /storage/upload/{bucket}/{path} writes
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, LocalRoutePrefix), "/", 3)
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+	op, bucket, objectPath := parts[0], parts[1], parts[2]
+
+	if !l.verify(op, bucket, objectPath, r.URL.Query()) {
+		http.Error(w, "Invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	full, err := l.filePath(bucket, objectPath)
+	if err != nil {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case op == opRead && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+		l.serveObject(w, r, full)
+	case op == opUpload && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		l.receiveObject(w, r, full, bucket+"/"+objectPath)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (l *Local) serveObject(w http.ResponseWriter, r *http.Request, full string) {
+	f, err := os.Open(full)
+	if errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to open object", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+func (l *Local) receiveObject(w http.ResponseWriter, r *http.Request, full, key string) {
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		http.Error(w, "Failed to store object", http.StatusInternalServerError)
+		return
+	}
+
+	// Write to a temp file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		http.Error(w, "Failed to store object", http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, copyErr := io.Copy(tmp, http.MaxBytesReader(w, r.Body, localMaxUploadBytes))
+	closeErr := tmp.Close()
+	if copyErr != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(copyErr, &maxErr) {
+			http.Error(w, "Object too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to read upload", http.StatusBadRequest)
+		return
+	}
+	if closeErr != nil || os.Rename(tmp.Name(), full) != nil {
+		http.Error(w, "Failed to store object", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"Key": key})
+}
+
+// signURL builds {baseURL}/storage/{op}/{bucket}/{path}?expires=...&token=...
+func (l *Local) signURL(op, bucket, objectPath string, ttl time.Duration) (string, error) {
+	if _, err := l.filePath(bucket, objectPath); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(l.now().Add(ttl).Unix(), 10)
+	u := url.URL{Path: LocalRoutePrefix + op + "/" + bucket + "/" + objectPath}
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("token", l.signature(op, bucket, objectPath, expires))
+	return l.baseURL + u.EscapedPath() + "?" + q.Encode(), nil
+}
+
+// verify checks the signature and expiry on an incoming request
+func (l *Local) verify(op, bucket, objectPath string, q url.Values) bool {
+	expires := q.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || l.now().Unix() > unix {
+		return false
+	}
+	got, err := hex.DecodeString(q.Get("token"))
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(l.signature(op, bucket, objectPath, expires))
+	return hmac.Equal(got, want)
+}
+
+func (l *Local) signature(op, bucket, objectPath, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(op + "\n" + bucket + "\n" + objectPath + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// filePath maps bucket and object path to a location under root,
+// rejecting anything that would land outside the bucket
+func (l *Local) filePath(bucket, objectPath string) (string, error) {
+	if bucket == "" || strings.ContainsAny(bucket, `/\`) || bucket == "." || bucket == ".." {
+		return "", ErrInvalidPath
+	}
+	if strings.Contains(objectPath, `\`) {
+		return "", ErrInvalidPath
+	}
+	cleaned := path.Clean("/" + objectPath)
+	if cleaned != "/"+strings.TrimSuffix(objectPath, "/") && objectPath != "" {
+		return "", ErrInvalidPath
+	}
+	return filepath.Join(l.root, bucket, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocal(t *testing.T) (*Local, *httptest.Server) {
+	t.Helper()
+	local, err := NewLocal(t.TempDir(), "", "test-secret")
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+	srv := httptest.NewServer(local)
+	t.Cleanup(srv.Close)
+	local.baseURL = srv.URL
+	return local, srv
+}
+
+func TestLocalUploadThenRead(t *testing.T) {
+	local, _ := newTestLocal(t)
+
+	uploadURL, err := local.SignUploadURL("audio-files", "stories/1/line_1_complete_123_a b.mp3")
+	if err != nil {
+		t.Fatalf("SignUploadURL failed: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, uploadURL, strings.NewReader("audio-bytes"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected upload status 200, got %d", resp.StatusCode)
+	}
+
+	readURL, err := local.SignReadURL("audio-files", "stories/1/line_1_complete_123_a b.mp3", 60)
+	if err != nil {
+		t.Fatalf("SignReadURL failed: %v", err)
+	}
+	resp, err = http.Get(readURL)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "audio-bytes" {
+		t.Errorf("Expected stored bytes back, got %d %q", resp.StatusCode, body)
+	}
+
+	objects, err := local.List("audio-files", "stories/1")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Size != int64(len("audio-bytes")) {
+		t.Errorf("Expected one listed object, got %+v", objects)
+	}
+
+	if err := local.Remove("audio-files", []string{"stories/1/line_1_complete_123_a b.mp3", "missing.mp3"}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	objects, _ = local.List("audio-files", "stories/1")
+	if len(objects) != 0 {
+		t.Errorf("Expected no objects after remove, got %+v", objects)
+	}
+}
+
+func TestLocalUploadThenDownload(t *testing.T) {
+	local, _ := newTestLocal(t)
+
+	if err := local.Upload("images", "stories/2/image_recall_1_cat.png", []byte("png-bytes"), "image/png"); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	data, err := local.Download("images", "stories/2/image_recall_1_cat.png")
+	if err != nil || string(data) != "png-bytes" {
+		t.Errorf("Expected stored bytes back, got %q, %v", data, err)
+	}
+	if err := local.Upload("images", "stories/2/image_recall_1_cat.png", []byte("other"), "image/png"); err == nil {
+		t.Error("Expected uploading over an existing object to fail")
+	}
+	if _, err := local.Download("images", "../secret"); err != ErrInvalidPath {
+		t.Errorf("Expected ErrInvalidPath for a traversal path, got %v", err)
+	}
+}
+
+func TestLocalStat(t *testing.T) {
+	local, _ := newTestLocal(t)
+
+	if err := local.Upload("images", "stories/2/image_recall_1_cat.png", []byte("png-bytes"), "image/png"); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	obj, err := local.Stat("images", "stories/2/image_recall_1_cat.png")
+	if err != nil || obj.Name != "image_recall_1_cat.png" || obj.Size != int64(len("png-bytes")) {
+		t.Errorf("Expected the uploaded object, got %+v, %v", obj, err)
+	}
+	if _, err := local.Stat("images", "stories/2/missing.png"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a missing object, got %v", err)
+	}
+	if _, err := local.Stat("images", "stories/2"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a folder, got %v", err)
+	}
+}
+
+func TestLocalRejectsBadSignatures(t *testing.T) {
+	local, _ := newTestLocal(t)
+
+	readURL, _ := local.SignReadURL("audio-files", "stories/1/a.mp3", 60)
+
+	// Signature for one object must not read another
+	tampered := strings.Replace(readURL, "a.mp3", "b.mp3", 1)
+	if status := get(t, tampered); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for tampered path, got %d", status)
+	}
+
+	// A read signature must not allow uploads
+	req, _ := http.NewRequest(http.MethodPut, strings.Replace(readURL, "/object/", "/upload/", 1), strings.NewReader("x"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 when reusing a read signature for upload, got %d", resp.StatusCode)
+	}
+
+	// Expired URLs are refused
+	local.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if status := get(t, readURL); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for expired URL, got %d", status)
+	}
+}
+
+func TestLocalRejectsPathTraversal(t *testing.T) {
+	local, _ := newTestLocal(t)
+
+	for _, p := range []string{"../secret", "stories/../../x", `stories\x`} {
+		if _, err := local.SignReadURL("audio-files", p, 60); err != ErrInvalidPath {
+			t.Errorf("Expected ErrInvalidPath for %q, got %v", p, err)
+		}
+	}
+	if _, err := local.SignReadURL("../etc", "x", 60); err != ErrInvalidPath {
+		t.Errorf("Expected ErrInvalidPath for bad bucket, got %v", err)
+	}
+}
+
+func get(t *testing.T, rawURL string) int {
+	t.Helper()
+	if _, err := url.Parse(rawURL); err != nil {
+		t.Fatalf("Bad URL %q: %v", rawURL, err)
+	}
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
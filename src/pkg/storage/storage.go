@@ -0,0 +1,40 @@
+// Package storage abstracts the object store that holds uploaded assets.
+// Supabase Storage is used in production; Local serves files from disk so
+// everything involving assets also works offline and in tests.
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidPath is returned for bucket or object paths that could escape the store
+var ErrInvalidPath = errors.New("invalid storage path")
+
+// ErrNotFound is returned by Stat for objects that do not exist
+var ErrNotFound = errors.New("object not found")
+
+// Storage is implemented by every object-store backend
+type Storage interface {
+	// SignUploadURL returns a URL the client can PUT the object's bytes to
+	SignUploadURL(bucket, path string) (string, error)
+	// SignReadURL returns a URL that can read the object until it expires
+	SignReadURL(bucket, path string, expiresInSeconds int) (string, error)
+	// Remove deletes objects; paths that do not exist are ignored
+	Remove(bucket string, paths []string) error
+	// List returns the objects directly under prefix
+	List(bucket, prefix string) ([]Object, error)
+	// Stat returns the object at path, or ErrNotFound
+	Stat(bucket, path string) (Object, error)
+	// Download returns the bytes of an object
+	Download(bucket, path string) ([]byte, error)
+	// Upload stores a new object, failing if one already exists at path
+	Upload(bucket, path string, data []byte, contentType string) error
+}
+
+// Object describes a stored object returned by List or Stat
+type Object struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
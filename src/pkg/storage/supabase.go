@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"glossias/src/pkg/database"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+const supabaseMaxRetries = 3
+
+// Supabase is the Supabase Storage backend
+type Supabase struct {
+	url    string
+	apiKey string
+
+	mu     sync.Mutex
+	client *storage_go.Client
+}
+
+// NewSupabase creates the backend and checks the credentials by listing buckets
+func NewSupabase(url, apiKey string) (*Supabase, error) {
+	s := &Supabase{
+		url:    url,
+		apiKey: apiKey,
+		client: storage_go.NewClient(url, apiKey, nil),
+	}
+
+	err := s.retry(func(c *storage_go.Client) error {
+		_, listErr := c.ListBuckets()
+		return listErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SignUploadURL implements Storage
+func (s *Supabase) SignUploadURL(bucket, path string) (string, error) {
+	var uploadURL string
+	err := s.retry(func(c *storage_go.Client) error {
+		result, signErr := c.CreateSignedUploadUrl(bucket, path)
+		if signErr == nil {
+			// Supabase returns a path relative to the storage API root
+			uploadURL = s.url + result.Url
+		}
+		return signErr
+	})
+	if err != nil {
+		return "", err
+	}
+	return uploadURL, nil
+}
+
+// SignReadURL implements Storage
+func (s *Supabase) SignReadURL(bucket, path string, expiresInSeconds int) (string, error) {
+	var signedURL string
+	err := s.retry(func(c *storage_go.Client) error {
+		result, signErr := c.CreateSignedUrl(bucket, path, expiresInSeconds)
+		if signErr == nil {
+			signedURL = result.SignedURL
+		}
+		return signErr
+	})
+	if err != nil {
+		return "", err
+	}
+	return signedURL, nil
+}
+
+// Remove implements Storage
+func (s *Supabase) Remove(bucket string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return s.retry(func(c *storage_go.Client) error {
+		_, removeErr := c.RemoveFile(bucket, paths)
+		return removeErr
+	})
+}
+
+// List implements Storage
+func (s *Supabase) List(bucket, prefix string) ([]Object, error) {
+	var files []storage_go.FileObject
+	err := s.retry(func(c *storage_go.Client) error {
+		var listErr error
+		files, listErr = c.ListFiles(bucket, prefix, storage_go.FileSearchOptions{})
+		return listErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(files))
+	for _, f := range files {
+		obj := Object{Name: f.Name}
+		if updated, err := time.Parse(time.RFC3339, f.UpdatedAt); err == nil {
+			obj.UpdatedAt = updated
+		}
+		if meta, ok := f.Metadata.(map[string]any); ok {
+			if size, ok := meta["size"].(float64); ok {
+				obj.Size = int64(size)
+			}
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// Stat implements Storage. It sends a HEAD request for the object, since the client
+// library can only list folders and the list is paged.
+func (s *Supabase) Stat(bucket, objectPath string) (Object, error) {
+	objectURL, err := url.JoinPath(s.url, "object", "authenticated", bucket, objectPath)
+	if err != nil {
+		return Object{}, err
+	}
+
+	var obj Object
+	err = s.retry(func(c *storage_go.Client) error {
+		req, reqErr := c.NewRequest(http.MethodHead, objectURL)
+		if reqErr != nil {
+			return reqErr
+		}
+		resp, headErr := c.Do(req, nil)
+		if resp != nil {
+			defer resp.Body.Close()
+			// Older Storage API versions answer 400 for missing objects
+			if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+				return ErrNotFound
+			}
+		}
+		if headErr != nil {
+			return headErr
+		}
+
+		obj = Object{Name: path.Base(objectPath), Size: resp.ContentLength}
+		if updated, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			obj.UpdatedAt = updated
+		}
+		return nil
+	})
+	if err != nil {
+		return Object{}, err
+	}
+	return obj, nil
+}
+
+// Download implements Storage
+func (s *Supabase) Download(bucket, path string) ([]byte, error) {
+	var data []byte
+	err := s.retry(func(c *storage_go.Client) error {
+		var downloadErr error
+		data, downloadErr = c.DownloadFile(bucket, path)
+		return downloadErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Upload implements Storage
+func (s *Supabase) Upload(bucket, path string, data []byte, contentType string) error {
+	upsert := false
+	return s.retry(func(c *storage_go.Client) error {
+		_, uploadErr := c.UploadFile(bucket, path, bytes.NewReader(data), storage_go.FileOptions{
+			ContentType: &contentType,
+			Upsert:      &upsert,
+		})
+		return uploadErr
+	})
+}
+
+// retry executes a storage operation with retry on connection errors,
+// recreating the client between attempts
+func (s *Supabase) retry(operation func(c *storage_go.Client) error) error {
+	var err error
+	for attempt := 1; attempt <= supabaseMaxRetries; attempt++ {
+		s.mu.Lock()
+		client := s.client
+		s.mu.Unlock()
+
+		err = operation(client)
+		if err == nil {
+			return nil
+		}
+
+		// Non-connection error, don't retry
+		if !database.IsConnectionError(err) {
+			return err
+		}
+
+		fmt.Printf("Storage connection error (attempt %d/%d): %v\n", attempt, supabaseMaxRetries, err)
+		if attempt < supabaseMaxRetries {
+			time.Sleep(1 * time.Second)
+			s.mu.Lock()
+			s.client = storage_go.NewClient(s.url, s.apiKey, nil)
+			s.mu.Unlock()
+		}
+	}
+
+	return fmt.Errorf("storage operation failed after %d attempts: %w", supabaseMaxRetries, err)
+}